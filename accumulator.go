@@ -0,0 +1,189 @@
+package shamir
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Accumulator collects shares one at a time for a single reconstruction, as
+// when a CLI prompts for shares or a web ceremony receives them as custodians
+// arrive. Each share is checked as it is added, so a duplicate, a share of
+// another split or a mistyped share is reported at once instead of when the
+// whole set is combined, and Add reports when enough shares are present.
+//
+// Enveloped shares record their threshold. Raw shares do not, so an
+// Accumulator for them needs WithThreshold; WithIntegrity selects shares from
+// SplitWithIntegrity. The options are passed on to CombineWithOptions.
+//
+// An Accumulator reconstructs at most once; afterwards it rejects all calls
+// with ErrSessionClosed. It is safe for concurrent use.
+type Accumulator struct {
+	mu        sync.Mutex
+	opts      []Option
+	o         *options
+	shares    [][]byte
+	indices   []byte // x-coordinates of shares
+	size      int    // Payload length of every share
+	envelope  *Share // First enveloped share, nil for raw shares
+	threshold int
+	closed    bool
+}
+
+// NewAccumulator returns an empty Accumulator that combines with opts.
+func NewAccumulator(opts ...Option) *Accumulator {
+	o := newOptions(opts)
+	return &Accumulator{opts: opts, o: o, threshold: o.threshold}
+}
+
+// Add checks share against those already added and keeps a copy of it. It
+// returns whether the Accumulator now holds enough shares to reconstruct.
+// A rejected share is not kept, and the shares added before remain.
+//
+// Add fails with ErrDuplicatePart for a second share at the same
+// x-coordinate, ErrMismatchedShares for a share of another split or format,
+// ErrDifferentLengths for a share of another length, and
+// ErrIntegrityCheckFailed for a share whose checksum does not verify.
+func (a *Accumulator) Add(share []byte) (enough bool, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return false, ErrSessionClosed
+	}
+	var index byte
+	var size int
+	if IsEnvelope(share) {
+		index, size, err = a.checkEnvelope(share)
+	} else {
+		index, size, err = a.checkRaw(share)
+	}
+	if err == nil {
+		err = a.checkNext(index, size)
+	}
+	if err != nil {
+		return false, fmt.Errorf("share %d: %w", len(a.shares), err)
+	}
+
+	a.shares = append(a.shares, append([]byte(nil), share...))
+	a.indices = append(a.indices, index)
+	a.size = size
+	return len(a.shares) >= a.threshold, nil
+}
+
+// checkEnvelope checks an enveloped share against the first one added and
+// returns its x-coordinate and payload length.
+func (a *Accumulator) checkEnvelope(share []byte) (byte, int, error) {
+	s, err := ParseShare(share)
+	if err != nil {
+		return 0, 0, err
+	}
+	secureZeroBytes(s.Payload)
+
+	if len(a.shares) > 0 {
+		if a.envelope == nil {
+			return 0, 0, fmt.Errorf("%w: enveloped share among raw shares", ErrMismatchedShares)
+		}
+		if !sameSplit(a.envelope, s) {
+			return 0, 0, ErrMismatchedShares
+		}
+		return s.Index, len(s.Payload), nil
+	}
+
+	if err := checkTrivialThreshold([]*Share{s}, a.o.engine()); err != nil {
+		return 0, 0, err
+	}
+	if a.o.purpose != "" && s.Purpose != a.o.purpose {
+		return 0, 0, fmt.Errorf("%w: shares are bound to %q, expected %q", ErrPurposeMismatch, s.Purpose, a.o.purpose)
+	}
+	a.envelope, a.threshold = s, s.Threshold
+	return s.Index, len(s.Payload), nil
+}
+
+// checkRaw checks a raw share and returns its x-coordinate and payload
+// length.
+func (a *Accumulator) checkRaw(share []byte) (byte, int, error) {
+	if a.envelope != nil {
+		return 0, 0, fmt.Errorf("%w: raw share among enveloped shares", ErrMismatchedShares)
+	}
+	if a.threshold == 0 {
+		return 0, 0, fmt.Errorf("%w: raw shares record no threshold, use WithThreshold", ErrZeroThreshold)
+	}
+	if err := validateShare(share); err != nil {
+		return 0, 0, err
+	}
+	size := len(share) - ShareOverhead
+	if a.o.integrity {
+		if len(share) < 6 {
+			return 0, 0, ErrTooShort
+		}
+		if !hasIntegrityTrailer(share) {
+			return 0, 0, ErrIntegrityCheckFailed
+		}
+		size -= 4 // CRC32
+	}
+	return share[0], size, nil
+}
+
+// checkNext checks a share's x-coordinate and payload length against the
+// shares already added.
+func (a *Accumulator) checkNext(index byte, size int) error {
+	if len(a.shares) > 0 && size != a.size {
+		return ErrDifferentLengths
+	}
+	for _, x := range a.indices {
+		if x == index {
+			return ErrDuplicatePart
+		}
+	}
+	return nil
+}
+
+// Len returns the number of shares added.
+func (a *Accumulator) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.shares)
+}
+
+// Threshold returns the number of shares needed to reconstruct: the threshold
+// recorded in the first enveloped share, or the one set with WithThreshold.
+// It is 0 while unknown.
+func (a *Accumulator) Threshold() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.threshold
+}
+
+// Reconstruct combines the added shares with CombineWithOptions and closes
+// the Accumulator, wiping the collected shares whether or not reconstruction
+// succeeds. With fewer shares than the threshold it returns an
+// *InsufficientSharesError and stays open.
+func (a *Accumulator) Reconstruct() ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return nil, ErrSessionClosed
+	}
+	if len(a.shares) < a.threshold || len(a.shares) == 0 {
+		return nil, &InsufficientSharesError{Have: len(a.shares), Need: a.threshold}
+	}
+
+	defer a.wipe()
+	return CombineWithOptions(a.shares, a.opts...)
+}
+
+// Close abandons the reconstruction and wipes any collected shares.
+func (a *Accumulator) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.wipe()
+}
+
+func (a *Accumulator) wipe() {
+	for _, share := range a.shares {
+		secureZeroBytes(share)
+	}
+	a.shares, a.indices = nil, nil
+	a.closed = true
+}
@@ -0,0 +1,131 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestAccumulatorEnveloped(t *testing.T) {
+	secret := []byte("ceremony secret")
+	shares, err := SplitWithOptions(secret, WithParts(5), WithThreshold(3), WithPurpose("ceremony"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAccumulator(WithPurpose("ceremony"))
+	if a.Threshold() != 0 {
+		t.Fatalf("threshold = %d before any share, want 0", a.Threshold())
+	}
+	for i, share := range [][]byte{shares[4], shares[0], shares[2]} {
+		enough, err := a.Add(share)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enough != (i == 2) {
+			t.Fatalf("after %d shares enough = %v", i+1, enough)
+		}
+		if i == 0 {
+			if _, err := a.Reconstruct(); !errors.Is(err, ErrInsufficientShares) {
+				t.Fatalf("expected ErrInsufficientShares, got %v", err)
+			}
+		}
+	}
+	if a.Len() != 3 || a.Threshold() != 3 {
+		t.Fatalf("len = %d, threshold = %d", a.Len(), a.Threshold())
+	}
+
+	got, err := a.Reconstruct()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+	if _, err := a.Add(shares[1]); !errors.Is(err, ErrSessionClosed) {
+		t.Fatalf("expected ErrSessionClosed, got %v", err)
+	}
+	if _, err := a.Reconstruct(); !errors.Is(err, ErrSessionClosed) {
+		t.Fatalf("expected ErrSessionClosed, got %v", err)
+	}
+}
+
+func TestAccumulatorRaw(t *testing.T) {
+	secret := []byte("raw secret")
+	shares, err := SplitWithIntegrity(secret, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewAccumulator(WithIntegrity(true)).Add(shares[0]); !errors.Is(err, ErrZeroThreshold) {
+		t.Fatalf("expected ErrZeroThreshold without WithThreshold, got %v", err)
+	}
+
+	a := NewAccumulator(WithThreshold(2), WithIntegrity(true))
+	if enough, err := a.Add(shares[1]); err != nil || enough {
+		t.Fatalf("first share: enough = %v, err = %v", enough, err)
+	}
+	corrupted := bytes.Clone(shares[3])
+	corrupted[2] ^= 1
+	if _, err := a.Add(corrupted); !errors.Is(err, ErrIntegrityCheckFailed) {
+		t.Fatalf("expected ErrIntegrityCheckFailed, got %v", err)
+	}
+	if enough, err := a.Add(shares[3]); err != nil || !enough {
+		t.Fatalf("second share: enough = %v, err = %v", enough, err)
+	}
+	got, err := a.Reconstruct()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+}
+
+func TestAccumulatorRejects(t *testing.T) {
+	shares, _ := SplitWithOptions([]byte("secret"), WithParts(3), WithThreshold(2), WithPurpose("p"))
+	other, _ := SplitWithOptions([]byte("secret"), WithParts(3), WithThreshold(2), WithPurpose("p"))
+	raw, _ := Split([]byte("secret"), 3, 2)
+
+	a := NewAccumulator()
+	if _, err := a.Add(shares[0]); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		share []byte
+		want  error
+	}{
+		{"duplicate", shares[0], ErrDuplicatePart},
+		{"other split", other[1], ErrMismatchedShares},
+		{"raw", raw[1], ErrMismatchedShares},
+		{"corrupted", append(bytes.Clone(shares[1][:len(shares[1])-1]), ^shares[1][len(shares[1])-1]), ErrIntegrityCheckFailed},
+	}
+	for _, tt := range tests {
+		if _, err := a.Add(tt.share); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+	if a.Len() != 1 {
+		t.Fatalf("rejected shares were kept: len = %d", a.Len())
+	}
+
+	if _, err := NewAccumulator(WithPurpose("q")).Add(shares[0]); !errors.Is(err, ErrPurposeMismatch) {
+		t.Fatalf("expected ErrPurposeMismatch, got %v", err)
+	}
+
+	r := NewAccumulator(WithThreshold(2))
+	r.Add(raw[0])
+	if _, err := r.Add(other[1]); !errors.Is(err, ErrMismatchedShares) {
+		t.Fatalf("expected ErrMismatchedShares, got %v", err)
+	}
+	longRaw, _ := Split([]byte("a longer secret"), 3, 2)
+	if _, err := r.Add(longRaw[1]); !errors.Is(err, ErrDifferentLengths) {
+		t.Fatalf("expected ErrDifferentLengths, got %v", err)
+	}
+
+	r.Close()
+	if _, err := r.Add(raw[1]); !errors.Is(err, ErrSessionClosed) {
+		t.Fatalf("expected ErrSessionClosed, got %v", err)
+	}
+}
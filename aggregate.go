@@ -0,0 +1,52 @@
+package shamir
+
+// Multi-dealer aggregation.
+//
+// Several dealers each split their own secret for the same participants, at
+// the same x-coordinates and with the same threshold. Every participant then
+// adds the shares they received locally with AggregateShares and ends up
+// holding a share of the sum (XOR) of all the secrets, which no single dealer
+// knows. With random dealer secrets this yields distributed randomness that is
+// unpredictable as long as one dealer is honest; the aggregate can be combined
+// like any other sharing, or used as input to further share arithmetic.
+
+// Deal splits a secret for a fixed participant set: participants lists the
+// x-coordinate of each participant, and the i-th returned share belongs to
+// participants[i]. Every dealer contributing to an aggregate must use the same
+// participants and threshold.
+func Deal(secret, participants []byte, threshold int) ([][]byte, error) {
+	o := newOptions([]Option{WithThreshold(threshold)})
+	o.xCoords = append([]byte(nil), participants...)
+	return o.split(secret)
+}
+
+// AggregateShares adds the shares one participant received from each dealer,
+// returning that participant's share of the sum of the dealers' secrets. All
+// shares must have the same x-coordinate and length; the inputs are left
+// untouched. The aggregate's threshold is the largest of the dealers'.
+func AggregateShares(shares ...[]byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrNilShares
+	}
+	if err := validateShare(shares[0]); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(nil), shares[0]...)
+	for i, share := range shares[1:] {
+		if err := validateShare(share); err != nil {
+			secureZeroBytes(out)
+			return nil, err
+		}
+		if len(share) != len(out) {
+			secureZeroBytes(out)
+			return nil, ErrDifferentLengths
+		}
+		if share[0] != out[0] {
+			secureZeroBytes(out)
+			return nil, NewValidationError("share", i+1, "shamir: shares must have the same x-coordinate")
+		}
+		gfAddSlice(out[ShareOverhead:], out[ShareOverhead:], share[ShareOverhead:])
+	}
+	return out, nil
+}
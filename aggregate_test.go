@@ -0,0 +1,82 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestMultiDealerAggregation(t *testing.T) {
+	participants := []byte{3, 9, 17, 200}
+	const threshold = 3
+	secrets := [][]byte{
+		[]byte("dealer one random"),
+		[]byte("dealer two random"),
+		[]byte("dealer 3's random"),
+	}
+
+	// received[p] holds the shares participant p got from every dealer.
+	received := make([][][]byte, len(participants))
+	for _, secret := range secrets {
+		shares, err := Deal(secret, participants, threshold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for p, share := range shares {
+			if share[0] != participants[p] {
+				t.Fatalf("share %d has x = %d, want %d", p, share[0], participants[p])
+			}
+			received[p] = append(received[p], share)
+		}
+	}
+
+	aggregates := make([][]byte, len(participants))
+	for p := range participants {
+		var err error
+		if aggregates[p], err = AggregateShares(received[p]...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := make([]byte, len(secrets[0]))
+	for _, secret := range secrets {
+		gfAddSlice(want, want, secret)
+	}
+	got, err := Combine([][]byte{aggregates[3], aggregates[0], aggregates[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("aggregate does not reconstruct the sum of the secrets")
+	}
+
+	t.Run("inputs untouched", func(t *testing.T) {
+		if bytes.Equal(received[0][0], aggregates[0]) {
+			t.Fatal("aggregate aliases its first input")
+		}
+	})
+
+	t.Run("mismatched shares", func(t *testing.T) {
+		if _, err := AggregateShares(); !errors.Is(err, ErrNilShares) {
+			t.Errorf("expected ErrNilShares, got %v", err)
+		}
+		if _, err := AggregateShares(received[0][0], received[1][0]); err == nil {
+			t.Error("expected an error for different x-coordinates")
+		}
+		if _, err := AggregateShares(received[0][0], received[0][1][:5]); !errors.Is(err, ErrDifferentLengths) {
+			t.Errorf("expected ErrDifferentLengths, got %v", err)
+		}
+	})
+
+	t.Run("invalid participants", func(t *testing.T) {
+		for name, xs := range map[string][]byte{
+			"zero":      {0, 1, 2},
+			"duplicate": {1, 2, 2},
+			"too few":   {1, 2},
+		} {
+			if _, err := Deal(secrets[0], xs, threshold); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package shamir
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Long-term archival.
+//
+// Escrow of things like root CA keys can last decades, during which media
+// decay and custodians change. The archival profile strengthens the per-share
+// integrity record with a dealer signature over each share's SHA-256
+// fingerprint, and records in the manifest how often every share should be
+// taken out and re-verified, so overdue checks can be scheduled.
+
+// archiveDomain separates archive share signatures from other Ed25519 uses.
+const archiveDomain = "go-shamir archive share v1"
+
+// DefaultReverifyDays is the re-verification interval used when an
+// ArchivePolicy does not set one.
+const DefaultReverifyDays = 365
+
+// ArchivePolicy describes an archival sharing: an escrow arrangement plus the
+// dealer key that signs the shares and the re-verification interval.
+type ArchivePolicy struct {
+	EscrowPolicy
+	Dealer       ed25519.PrivateKey // Signs every share; keep the public key pinned for verification
+	ReverifyDays int                // Days between re-verifications; 0 means DefaultReverifyDays
+}
+
+// VerificationDue is one entry of a manifest's verification schedule.
+type VerificationDue struct {
+	Custodian    string
+	Index        byte
+	LastVerified time.Time // Creation time until the share is first re-verified
+	Due          time.Time
+}
+
+// Overdue reports whether the verification was due before now.
+func (v VerificationDue) Overdue(now time.Time) bool {
+	return now.After(v.Due)
+}
+
+// SplitArchival splits a secret for long-term escrow. Each share is an
+// enveloped share labelled with its custodian, and its manifest entry carries
+// the dealer's Ed25519 signature over the share's fingerprint. The manifest
+// records the dealer's public key and the re-verification interval; use
+// VerifyArchivedShare to check a share and VerificationSchedule to see when
+// each is next due.
+func SplitArchival(secret []byte, policy ArchivePolicy) (*EscrowSharing, error) {
+	if len(policy.Dealer) != ed25519.PrivateKeySize {
+		return nil, NewValidationError("dealer", len(policy.Dealer), "shamir: invalid dealer signing key")
+	}
+	days := policy.ReverifyDays
+	if days == 0 {
+		days = DefaultReverifyDays
+	}
+	if days < 1 {
+		return nil, NewValidationError("reverify days", days, "shamir: re-verification interval must be positive")
+	}
+
+	sharing, err := splitEscrow(secret, policy.EscrowPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	m := sharing.Manifest
+	m.DealerKey = policy.Dealer.Public().(ed25519.PublicKey)
+	m.ReverifyDays = days
+	for i := range m.Custodians {
+		entry := &m.Custodians[i]
+		copy(entry.Signature[:], ed25519.Sign(policy.Dealer, archiveMessage(m, entry)))
+		entry.LastVerified = m.CreatedAt
+	}
+	return sharing, nil
+}
+
+// VerifyArchivedShare checks an archived share against the manifest: the
+// share must be listed, its envelope must be intact, and the manifest entry
+// must carry a valid signature by dealer, which the caller must have pinned
+// independently of the manifest. On success the entry's LastVerified is set to
+// now, moving its next due date; persist the manifest afterwards.
+func (m *Manifest) VerifyArchivedShare(share []byte, dealer ed25519.PublicKey, now time.Time) error {
+	if len(dealer) != ed25519.PublicKeySize {
+		return NewValidationError("dealer", len(dealer), "shamir: invalid dealer public key")
+	}
+
+	fp := FingerprintShare(share)
+	var entry *ManifestEntry
+	for i := range m.Custodians {
+		if m.Custodians[i].Fingerprint == fp {
+			entry = &m.Custodians[i]
+			break
+		}
+	}
+	if entry == nil {
+		return ErrUnknownShare
+	}
+
+	s, err := ParseShare(share)
+	if err != nil {
+		return err
+	}
+	secureZeroBytes(s.Payload)
+	if s.SetID != m.SetID || s.Index != entry.Index || s.Threshold != m.Threshold {
+		return fmt.Errorf("%w: share metadata does not match the manifest", ErrMismatchedShares)
+	}
+	if !ed25519.Verify(dealer, archiveMessage(m, entry), entry.Signature[:]) {
+		return fmt.Errorf("%w: dealer signature does not verify for %s", ErrAuthenticationFailed, entry.Custodian)
+	}
+
+	entry.LastVerified = now.UTC()
+	return nil
+}
+
+// VerificationSchedule returns when each share of an archival sharing is next
+// due for re-verification, earliest first. It returns nil for manifests that
+// record no re-verification interval.
+func (m *Manifest) VerificationSchedule() []VerificationDue {
+	if m.ReverifyDays <= 0 {
+		return nil
+	}
+
+	interval := time.Duration(m.ReverifyDays) * 24 * time.Hour
+	schedule := make([]VerificationDue, 0, len(m.Custodians))
+	for _, entry := range m.Custodians {
+		last := entry.LastVerified
+		if last.IsZero() {
+			last = m.CreatedAt
+		}
+		schedule = append(schedule, VerificationDue{
+			Custodian:    entry.Custodian,
+			Index:        entry.Index,
+			LastVerified: last,
+			Due:          last.Add(interval),
+		})
+	}
+	sort.SliceStable(schedule, func(i, j int) bool {
+		return schedule[i].Due.Before(schedule[j].Due)
+	})
+	return schedule
+}
+
+// archiveMessage builds the byte string the dealer signs for a manifest entry.
+func archiveMessage(m *Manifest, entry *ManifestEntry) []byte {
+	msg := make([]byte, 0, len(archiveDomain)+len(m.SetID)+3+len(entry.Fingerprint))
+	msg = append(msg, archiveDomain...)
+	msg = append(msg, m.SetID[:]...)
+	msg = binary.BigEndian.AppendUint16(msg, uint16(m.Threshold))
+	msg = append(msg, entry.Index)
+	return append(msg, entry.Fingerprint[:]...)
+}
@@ -0,0 +1,132 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSplitArchival(t *testing.T) {
+	dealerPub, dealer, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("root CA private key")
+
+	sharing, err := SplitArchival(secret, ArchivePolicy{
+		EscrowPolicy: EscrowPolicy{Name: "root-ca", Threshold: 2, Custodians: []string{"vault-a", "vault-b", "vault-c"}},
+		Dealer:       dealer,
+		ReverifyDays: 180,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := sharing.Manifest
+	if !bytes.Equal(m.DealerKey, dealerPub) || m.ReverifyDays != 180 {
+		t.Fatalf("manifest does not record the archive profile: %+v", m)
+	}
+
+	// The manifest survives a JSON round trip with signatures intact.
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored Manifest
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, share := range sharing.Shares {
+		if err := stored.VerifyArchivedShare(share, dealerPub, m.CreatedAt.Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reconstructed, err := CombineEscrow(&stored, sharing.Shares[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+
+	t.Run("wrong dealer", func(t *testing.T) {
+		otherPub, _, _ := ed25519.GenerateKey(nil)
+		if err := stored.VerifyArchivedShare(sharing.Shares[0], otherPub, time.Now()); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf("expected ErrAuthenticationFailed, got %v", err)
+		}
+	})
+
+	t.Run("forged entry", func(t *testing.T) {
+		forged := stored
+		forged.Custodians = append([]ManifestEntry(nil), stored.Custodians...)
+		forged.Threshold = 1
+		if err := forged.VerifyArchivedShare(sharing.Shares[0], dealerPub, time.Now()); err == nil {
+			t.Fatal("manifest with altered threshold verified")
+		}
+	})
+
+	t.Run("unknown share", func(t *testing.T) {
+		tampered := append([]byte(nil), sharing.Shares[0]...)
+		tampered[len(tampered)-5] ^= 1
+		if err := stored.VerifyArchivedShare(tampered, dealerPub, time.Now()); !errors.Is(err, ErrUnknownShare) {
+			t.Fatalf("expected ErrUnknownShare, got %v", err)
+		}
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		policy := ArchivePolicy{EscrowPolicy: EscrowPolicy{Threshold: 2, Custodians: []string{"a", "b"}}}
+		if _, err := SplitArchival(secret, policy); err == nil {
+			t.Error("expected an error without a dealer key")
+		}
+		policy.Dealer, policy.ReverifyDays = dealer, -1
+		if _, err := SplitArchival(secret, policy); err == nil {
+			t.Error("expected an error for a negative interval")
+		}
+	})
+}
+
+func TestVerificationSchedule(t *testing.T) {
+	_, dealer, _ := ed25519.GenerateKey(nil)
+	sharing, err := SplitArchival([]byte("archived"), ArchivePolicy{
+		EscrowPolicy: EscrowPolicy{Threshold: 2, Custodians: []string{"a", "b", "c"}},
+		Dealer:       dealer,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := sharing.Manifest
+	created := m.CreatedAt
+	year := DefaultReverifyDays * 24 * time.Hour
+
+	schedule := m.VerificationSchedule()
+	if len(schedule) != 3 {
+		t.Fatalf("got %d entries, want 3", len(schedule))
+	}
+	for _, due := range schedule {
+		if !due.Due.Equal(created.Add(year)) {
+			t.Errorf("%s due %v, want %v", due.Custodian, due.Due, created.Add(year))
+		}
+		if due.Overdue(created.Add(year-time.Hour)) || !due.Overdue(created.Add(year+time.Hour)) {
+			t.Errorf("%s: Overdue does not follow the due date", due.Custodian)
+		}
+	}
+
+	// Verifying share "b" later pushes it to the end of the schedule.
+	verifiedAt := created.Add(30 * 24 * time.Hour)
+	if err := m.VerifyArchivedShare(sharing.Shares[1], dealer.Public().(ed25519.PublicKey), verifiedAt); err != nil {
+		t.Fatal(err)
+	}
+	schedule = m.VerificationSchedule()
+	last := schedule[len(schedule)-1]
+	if last.Custodian != "b" || !last.LastVerified.Equal(verifiedAt) || !last.Due.Equal(verifiedAt.Add(year)) {
+		t.Fatalf("unexpected schedule after re-verification: %+v", schedule)
+	}
+
+	if (&Manifest{}).VerificationSchedule() != nil {
+		t.Fatal("non-archival manifest should have no schedule")
+	}
+}
@@ -0,0 +1,21 @@
+package shamir
+
+// Secret-material audit instrumentation.
+//
+// Building with the shamirdebug tag (go test -tags shamirdebug ./...) turns on
+// tracking of every temporary buffer that carries secret material. Each tracked
+// buffer must be zeroized before it is released; violations are recorded and
+// can be inspected through AuditLeaks. In normal builds the hooks compile down
+// to no-ops and AuditLeaks always returns nil.
+
+// AuditLeak describes a tracked secret buffer that was not zeroized correctly.
+type AuditLeak struct {
+	Label    string // Identifies where the buffer was allocated (e.g. "split.coefficient")
+	Size     int    // Length of the buffer in bytes
+	Reason   string // Either "not zeroized on release" or "never released"
+	Location string // Allocation call site (file:line)
+}
+
+func (l AuditLeak) String() string {
+	return l.Label + " (" + l.Location + "): " + l.Reason
+}
@@ -0,0 +1,101 @@
+//go:build shamirdebug
+
+package shamir
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// AuditEnabled reports whether the package was built with the shamirdebug tag.
+const AuditEnabled = true
+
+// auditEntry records a live secret buffer and where it was allocated.
+type auditEntry struct {
+	label    string
+	buf      []byte
+	location string
+}
+
+// auditState holds all live tracked buffers and the leaks found so far.
+var auditState = struct {
+	sync.Mutex
+	live  map[*byte]*auditEntry
+	leaks []AuditLeak
+}{live: make(map[*byte]*auditEntry)}
+
+// auditTrack registers b as carrying secret material.
+// The buffer must be passed to auditRelease once zeroized.
+func auditTrack(label string, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+
+	location := "unknown"
+	if _, file, line, ok := runtime.Caller(1); ok {
+		location = fmt.Sprintf("%s:%d", file, line)
+	}
+
+	auditState.Lock()
+	defer auditState.Unlock()
+	auditState.live[unsafe.SliceData(b)] = &auditEntry{label: label, buf: b, location: location}
+}
+
+// auditRelease asserts that a tracked buffer has been zeroized and stops tracking it.
+// Untracked buffers are ignored so release calls can be placed unconditionally.
+func auditRelease(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+
+	auditState.Lock()
+	defer auditState.Unlock()
+
+	key := unsafe.SliceData(b)
+	entry, ok := auditState.live[key]
+	if !ok {
+		return
+	}
+	delete(auditState.live, key)
+
+	for _, v := range entry.buf {
+		if v != 0 {
+			auditState.leaks = append(auditState.leaks, AuditLeak{
+				Label:    entry.label,
+				Size:     len(entry.buf),
+				Reason:   "not zeroized on release",
+				Location: entry.location,
+			})
+			return
+		}
+	}
+}
+
+// AuditLeaks returns every tracked buffer that was released without being zeroized,
+// followed by every tracked buffer that is still live (never released).
+func AuditLeaks() []AuditLeak {
+	auditState.Lock()
+	defer auditState.Unlock()
+
+	leaks := make([]AuditLeak, len(auditState.leaks), len(auditState.leaks)+len(auditState.live))
+	copy(leaks, auditState.leaks)
+	for _, entry := range auditState.live {
+		leaks = append(leaks, AuditLeak{
+			Label:    entry.label,
+			Size:     len(entry.buf),
+			Reason:   "never released",
+			Location: entry.location,
+		})
+	}
+	return leaks
+}
+
+// ResetAudit discards all recorded leaks and stops tracking live buffers.
+func ResetAudit() {
+	auditState.Lock()
+	defer auditState.Unlock()
+	auditState.live = make(map[*byte]*auditEntry)
+	auditState.leaks = nil
+}
@@ -0,0 +1,18 @@
+//go:build !shamirdebug
+
+package shamir
+
+// AuditEnabled reports whether the package was built with the shamirdebug tag.
+const AuditEnabled = false
+
+// auditTrack is a no-op outside of shamirdebug builds.
+func auditTrack(label string, b []byte) {}
+
+// auditRelease is a no-op outside of shamirdebug builds.
+func auditRelease(b []byte) {}
+
+// AuditLeaks returns nil outside of shamirdebug builds.
+func AuditLeaks() []AuditLeak { return nil }
+
+// ResetAudit is a no-op outside of shamirdebug builds.
+func ResetAudit() {}
@@ -0,0 +1,71 @@
+//go:build shamirdebug
+
+package shamir
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain fails the run if any test left secret material behind.
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if leaks := AuditLeaks(); code == 0 && len(leaks) > 0 {
+		for _, leak := range leaks {
+			fmt.Fprintf(os.Stderr, "secret buffer leak: %s\n", leak)
+		}
+		code = 1
+	}
+	os.Exit(code)
+}
+
+func TestAuditSplitCombine(t *testing.T) {
+	ResetAudit()
+	defer ResetAudit()
+
+	secret := []byte("audited secret material")
+
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Combine(shares[:3]); err != nil {
+		t.Fatal(err)
+	}
+
+	integrityShares, err := SplitWithIntegrity(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CombineWithIntegrity(integrityShares[:3]); err != nil {
+		t.Fatal(err)
+	}
+
+	if leaks := AuditLeaks(); len(leaks) != 0 {
+		t.Fatalf("unexpected leaks: %v", leaks)
+	}
+}
+
+func TestAuditDetectsLeaks(t *testing.T) {
+	ResetAudit()
+	defer ResetAudit()
+
+	dirty := []byte{1, 2, 3}
+	auditTrack("test.dirty", dirty)
+	auditRelease(dirty)
+
+	forgotten := []byte{4, 5, 6}
+	auditTrack("test.forgotten", forgotten)
+
+	leaks := AuditLeaks()
+	if len(leaks) != 2 {
+		t.Fatalf("expected 2 leaks, got %d: %v", len(leaks), leaks)
+	}
+	if leaks[0].Label != "test.dirty" || leaks[0].Reason != "not zeroized on release" {
+		t.Errorf("unexpected first leak: %v", leaks[0])
+	}
+	if leaks[1].Label != "test.forgotten" || leaks[1].Reason != "never released" {
+		t.Errorf("unexpected second leak: %v", leaks[1])
+	}
+}
@@ -0,0 +1,128 @@
+package shamir
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+)
+
+// AuthTagSize is the number of bytes appended to each share by SplitAuthenticated.
+const AuthTagSize = sha256.Size
+
+// authKeyInfo is the HKDF context string used when deriving the tag key from the secret.
+const authKeyInfo = "go-shamir share authentication v1"
+
+// SplitAuthenticated splits a secret and appends an HMAC-SHA256 tag to every share.
+// Unlike the CRC32 used by SplitWithIntegrity, the tag cannot be recomputed by
+// someone who tampers with a share, so forged shares are detected.
+//
+// Parameters:
+//   - secret, parts, threshold: As for Split
+//   - key: Explicit authentication key; if nil, a key is derived from the secret
+//
+// With an explicit key every share can be verified on its own before reconstruction.
+// With a secret-derived key the shares are verified after interpolation against
+// the reconstructed secret, so any forged share makes every tag fail.
+// Note that a secret-derived tag lets anyone holding a single share test guesses
+// of the secret offline; only rely on it for high-entropy secrets such as keys.
+//
+// Each share is len(secret)+1+AuthTagSize bytes: [x-coordinate][y-values...][tag]
+func SplitAuthenticated(secret []byte, parts, threshold int, key []byte) ([][]byte, error) {
+	if key != nil && len(key) == 0 {
+		return nil, NewValidationError("key", 0, "shamir: authentication key cannot be empty")
+	}
+
+	shares, err := Split(secret, parts, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	macKey := key
+	if macKey == nil {
+		if macKey, err = deriveAuthKey(secret); err != nil {
+			return nil, err
+		}
+		defer secureZeroBytes(macKey)
+	}
+
+	authShares := make([][]byte, len(shares))
+	for i, share := range shares {
+		authShares[i] = make([]byte, len(share), len(share)+AuthTagSize)
+		copy(authShares[i], share)
+		authShares[i] = append(authShares[i], computeAuthTag(macKey, share)...)
+		secureZeroBytes(share)
+	}
+
+	return authShares, nil
+}
+
+// CombineAuthenticated verifies the HMAC-SHA256 tag of every share and reconstructs
+// the secret. The key must match the one passed to SplitAuthenticated (nil for a
+// secret-derived key). Returns ErrAuthenticationFailed if any share was forged or
+// corrupted; the secret is never returned in that case.
+func CombineAuthenticated(parts [][]byte, key []byte) ([]byte, error) {
+	if parts == nil {
+		return nil, ErrNilShares
+	}
+	if len(parts) < 2 {
+		return nil, ErrTooFewParts
+	}
+	if key != nil && len(key) == 0 {
+		return nil, NewValidationError("key", 0, "shamir: authentication key cannot be empty")
+	}
+
+	stripped := make([][]byte, len(parts))
+	for i, part := range parts {
+		if len(part) < AuthTagSize+2 {
+			return nil, ErrTooShort
+		}
+		stripped[i] = part[:len(part)-AuthTagSize]
+
+		if key != nil && !verifyAuthTag(key, stripped[i], part[len(stripped[i]):]) {
+			return nil, fmt.Errorf("share %d: %w", i, ErrAuthenticationFailed)
+		}
+	}
+
+	secret, err := Combine(stripped)
+	if err != nil || key != nil {
+		return secret, err
+	}
+
+	macKey, err := deriveAuthKey(secret)
+	if err != nil {
+		freeSecret(secret)
+		return nil, err
+	}
+	defer secureZeroBytes(macKey)
+
+	for i, part := range parts {
+		if !verifyAuthTag(macKey, stripped[i], part[len(stripped[i]):]) {
+			freeSecret(secret)
+			return nil, fmt.Errorf("share %d: %w", i, ErrAuthenticationFailed)
+		}
+	}
+
+	return secret, nil
+}
+
+// deriveAuthKey derives the share authentication key from the secret with HKDF-SHA256.
+func deriveAuthKey(secret []byte) ([]byte, error) {
+	key, err := hkdf.Key(sha256.New, secret, nil, authKeyInfo, sha256.Size)
+	if err != nil {
+		return nil, fmt.Errorf("shamir: failed to derive authentication key: %w", err)
+	}
+	return key, nil
+}
+
+// computeAuthTag returns HMAC-SHA256(key, share).
+func computeAuthTag(key, share []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(share)
+	return mac.Sum(nil)
+}
+
+// verifyAuthTag checks the tag in constant time.
+func verifyAuthTag(key, share, tag []byte) bool {
+	return hmac.Equal(computeAuthTag(key, share), tag)
+}
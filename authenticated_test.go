@@ -0,0 +1,75 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestAuthenticatedShares(t *testing.T) {
+	secret := []byte("authenticated secret material")
+	key := []byte("explicit authentication key")
+
+	for _, tc := range []struct {
+		name string
+		key  []byte
+	}{
+		{"secret-derived key", nil},
+		{"explicit key", key},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			shares, err := SplitAuthenticated(secret, 5, 3, tc.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, share := range shares {
+				if len(share) != len(secret)+ShareOverhead+AuthTagSize {
+					t.Fatalf("share %d has length %d", i, len(share))
+				}
+			}
+
+			reconstructed, err := CombineAuthenticated(shares[1:4], tc.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(reconstructed, secret) {
+				t.Fatal("reconstruction failed")
+			}
+
+			// A forged payload byte must be detected even though the forger could
+			// recompute any non-cryptographic checksum.
+			forged := make([][]byte, 3)
+			for i := range forged {
+				forged[i] = append([]byte(nil), shares[i]...)
+			}
+			forged[1][3] ^= 0x01
+
+			if _, err := CombineAuthenticated(forged, tc.key); !errors.Is(err, ErrAuthenticationFailed) {
+				t.Fatalf("expected ErrAuthenticationFailed, got %v", err)
+			}
+		})
+	}
+
+	t.Run("wrong key", func(t *testing.T) {
+		shares, err := SplitAuthenticated(secret, 3, 2, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := CombineAuthenticated(shares[:2], []byte("other key")); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf("expected ErrAuthenticationFailed, got %v", err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := SplitAuthenticated(secret, 3, 2, []byte{}); err == nil {
+			t.Fatal("expected error for empty key")
+		}
+		if _, err := CombineAuthenticated([][]byte{{1, 2}}, nil); err != ErrTooFewParts {
+			t.Fatalf("expected ErrTooFewParts, got %v", err)
+		}
+		if _, err := CombineAuthenticated([][]byte{{1, 2}, {2, 3}}, nil); err != ErrTooShort {
+			t.Fatalf("expected ErrTooShort, got %v", err)
+		}
+	})
+}
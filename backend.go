@@ -0,0 +1,56 @@
+package shamir
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/morizta/go-shamir/gf256"
+)
+
+// Backend describes the GF(256) kernel this process uses for bulk field
+// arithmetic, so operators can confirm the expected acceleration in
+// production and bug reports can include it.
+type Backend struct {
+	// Kernel is the slice arithmetic kernel: "avx2" (amd64 VPSHUFB), "ssse3"
+	// (amd64 PSHUFB), "neon" (arm64 TBL) or "generic" (portable table lookups).
+	Kernel string
+
+	// Reason explains why Kernel was selected, e.g. a missing CPU feature,
+	// the purego build tag or a GODEBUG=shamircpu setting.
+	Reason string
+
+	// MulTable reports whether the 64 KiB multiplication table is used for
+	// the bytes the kernel leaves, see gf256.SetMulTable.
+	MulTable bool
+
+	// Arch is the GOARCH the binary was built for.
+	Arch string
+
+	// Parallelism is the default number of goroutines large splits and
+	// combines use (GOMAXPROCS), see WithParallelism.
+	Parallelism int
+}
+
+// ActiveBackend reports the kernel selected for this build and CPU. The
+// selection is made once at startup and does not change; the multiplication
+// table follows gf256.SetMulTable.
+func ActiveBackend() Backend {
+	kernel, reason := gf256.Kernel()
+	return Backend{
+		Kernel:      kernel,
+		Reason:      reason,
+		MulTable:    gf256.MulTableEnabled(),
+		Arch:        runtime.GOARCH,
+		Parallelism: runtime.GOMAXPROCS(0),
+	}
+}
+
+// String formats the backend on one line, e.g.
+// "avx2 kernel on amd64 (CPU supports AVX2), parallelism 8".
+func (b Backend) String() string {
+	table := ""
+	if b.MulTable {
+		table = ", 64 KiB multiplication table"
+	}
+	return fmt.Sprintf("%s kernel on %s (%s)%s, parallelism %d", b.Kernel, b.Arch, b.Reason, table, b.Parallelism)
+}
@@ -0,0 +1,32 @@
+package shamir
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/morizta/go-shamir/gf256"
+)
+
+func TestActiveBackend(t *testing.T) {
+	b := ActiveBackend()
+	if b.Arch != runtime.GOARCH {
+		t.Errorf("Arch = %q, want %q", b.Arch, runtime.GOARCH)
+	}
+	if b.Reason == "" || b.Parallelism < 1 {
+		t.Errorf("incomplete backend %+v", b)
+	}
+	// The reported kernel must be the one gfMultSlice actually uses.
+	if (b.Kernel != "generic") != gf256.Accelerated() {
+		t.Errorf("Kernel = %q but gf256.Accelerated() = %v", b.Kernel, gf256.Accelerated())
+	}
+	if s := b.String(); !strings.HasPrefix(s, b.Kernel+" kernel on "+b.Arch) {
+		t.Errorf("String() = %q", s)
+	}
+
+	gf256.SetMulTable(true)
+	defer gf256.SetMulTable(false)
+	if b := ActiveBackend(); !b.MulTable || !strings.Contains(b.String(), "multiplication table") {
+		t.Errorf("multiplication table not reported: %v", b)
+	}
+}
@@ -0,0 +1,70 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// SplitBatch splits many secrets at once with the same parts and threshold,
+// as when rotating a data key per user. Each secret gets its own independent
+// polynomial, exactly as if split with Split, but the randomness for the whole
+// batch is read in one go and the polynomials are evaluated together, so the
+// per-call overhead that dominates splits of short secrets is paid only once.
+//
+// The shares are grouped by custodian: shares[i][j] is the share of secrets[j]
+// at x = i+1, a raw share that Combine accepts. The shares of one custodian
+// are carved from a single allocation, so handing shares[i] to custodian i
+// hands over one contiguous block.
+//
+// An empty secret fails the whole batch, with an error naming its position.
+func SplitBatch(secrets [][]byte, parts, threshold int) ([][][]byte, error) {
+	if len(secrets) == 0 {
+		return nil, NewValidationError("secrets", 0, "shamir: no secrets to split")
+	}
+	total := 0
+	for i, secret := range secrets {
+		if err := validateSplitParams(secret, parts, threshold); err != nil {
+			if err == ErrEmptySecret {
+				return nil, fmt.Errorf("%w: secret %d", err, i)
+			}
+			return nil, err
+		}
+		total += len(secret)
+	}
+
+	// One polynomial over the concatenated secrets: its coefficient rows are
+	// the independent polynomials of every secret side by side.
+	joined := allocSecret(total)
+	offset := 0
+	for _, secret := range secrets {
+		offset += copy(joined[offset:], secret)
+	}
+	coeffs, err := randomCoefficients(joined, threshold, rand.Reader, engine{})
+	freeSecret(joined)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeCoefficients(coeffs)
+
+	values := allocSecret(total)
+	defer freeSecret(values)
+
+	shares := make([][][]byte, parts)
+	for i := range shares {
+		x := byte(i + 1)
+		gfPolyEvalSlice(values, coeffs, x)
+
+		block := make([]byte, total+len(secrets)*ShareOverhead)
+		shares[i] = make([][]byte, len(secrets))
+		offset := 0
+		for j, secret := range secrets {
+			share := block[:len(secret)+ShareOverhead : len(secret)+ShareOverhead]
+			block = block[len(share):]
+			share[0] = x
+			copy(share[ShareOverhead:], values[offset:offset+len(secret)])
+			offset += len(secret)
+			shares[i][j] = share
+		}
+	}
+	return shares, nil
+}
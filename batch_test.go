@@ -0,0 +1,104 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSplitBatch(t *testing.T) {
+	secrets := make([][]byte, 100)
+	for i := range secrets {
+		secrets[i] = bytes.Repeat([]byte{byte(i)}, 1+i%40)
+	}
+	shares, err := SplitBatch(secrets, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("expected shares for 5 custodians, got %d", len(shares))
+	}
+	for i, custodian := range shares {
+		if len(custodian) != len(secrets) {
+			t.Fatalf("custodian %d holds %d shares, want %d", i, len(custodian), len(secrets))
+		}
+		for j, share := range custodian {
+			if share[0] != byte(i+1) || len(share) != len(secrets[j])+ShareOverhead {
+				t.Fatalf("share %d of custodian %d: x=%d, %d bytes", j, i, share[0], len(share))
+			}
+		}
+	}
+
+	for j, secret := range secrets {
+		for _, quorum := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}} {
+			parts := make([][]byte, len(quorum))
+			for k, i := range quorum {
+				parts[k] = shares[i][j]
+			}
+			got, err := Combine(parts)
+			if err != nil || !bytes.Equal(got, secret) {
+				t.Fatalf("secret %d from custodians %v: %v", j, quorum, err)
+			}
+		}
+	}
+
+	// Appending to one share must not overwrite its neighbour.
+	next := append([]byte(nil), shares[0][1]...)
+	_ = append(shares[0][0], 0xFF)
+	if !bytes.Equal(shares[0][1], next) {
+		t.Error("shares of one custodian overlap")
+	}
+}
+
+func TestSplitBatchIndependentPolynomials(t *testing.T) {
+	// Equal secrets in one batch still get unrelated shares.
+	secret := []byte("same key for everyone")
+	shares, err := SplitBatch([][]byte{secret, secret}, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(shares[0][0], shares[0][1]) {
+		t.Error("equal secrets produced equal shares")
+	}
+}
+
+func TestSplitBatchErrors(t *testing.T) {
+	var verr *ValidationError
+	if _, err := SplitBatch(nil, 3, 2); !errors.As(err, &verr) || verr.Field != "secrets" {
+		t.Errorf("no secrets: expected a ValidationError, got %v", err)
+	}
+	if _, err := SplitBatch([][]byte{{1}, {}}, 3, 2); !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("empty secret: expected ErrEmptySecret, got %v", err)
+	}
+	if _, err := SplitBatch([][]byte{{1}}, 2, 3); !errors.As(err, &verr) || verr.Field != "threshold" {
+		t.Errorf("threshold above parts: expected a ValidationError, got %v", err)
+	}
+}
+
+func BenchmarkSplitBatch(b *testing.B) {
+	for _, n := range []int{1000, 50000} {
+		secrets := make([][]byte, n)
+		for i := range secrets {
+			secrets[i] = bytes.Repeat([]byte{byte(i)}, 32)
+		}
+		b.Run(fmt.Sprintf("batch/%d", n), func(b *testing.B) {
+			b.SetBytes(int64(32 * n))
+			for i := 0; i < b.N; i++ {
+				if _, err := SplitBatch(secrets, 5, 3); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("loop/%d", n), func(b *testing.B) {
+			b.SetBytes(int64(32 * n))
+			for i := 0; i < b.N; i++ {
+				for _, secret := range secrets {
+					if _, err := Split(secret, 5, 3); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Package bip39 splits cryptocurrency wallet secrets: BIP-0039 mnemonics and
+// BIP-0032 master seeds.
+//
+// Splitting a mnemonic's words, or the UTF-8 text of the phrase, is a common
+// and costly mistake: the shares are larger than necessary and recovery
+// silently depends on reproducing the exact spacing and spelling. Split
+// instead validates the mnemonic, splits the entropy it encodes, and Combine
+// rebuilds the canonical mnemonic from it, checksum included. Shares are
+// enveloped shares of the parent package tagged with the kind of secret they
+// carry, so entropy and seed shares cannot be confused.
+//
+// The optional BIP-0039 passphrase is not part of the entropy and is never
+// split; it must be kept separately and supplied to Seed after recovery.
+//
+// Only the English word list is supported. Mnemonics are matched case-
+// insensitively, words may be separated by any Unicode white space, and each
+// word may be abbreviated to its first four letters.
+package bip39
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+const (
+	seedIterations = 2048
+	seedSize       = 64
+)
+
+var (
+	// ErrUnknownWord indicates that a word is not in the BIP-0039 English word list.
+	ErrUnknownWord = errors.New("bip39: unknown word")
+
+	// ErrInvalidMnemonic indicates that a mnemonic does not have 12, 15, 18, 21 or 24 words.
+	ErrInvalidMnemonic = errors.New("bip39: invalid mnemonic")
+
+	// ErrInvalidChecksum indicates that a mnemonic's checksum does not verify,
+	// usually because a word was mistyped or the words are out of order.
+	ErrInvalidChecksum = errors.New("bip39: invalid mnemonic checksum")
+
+	// ErrInvalidEntropy indicates that entropy is not 16, 20, 24, 28 or 32 bytes.
+	ErrInvalidEntropy = errors.New("bip39: entropy must be 16, 20, 24, 28 or 32 bytes")
+
+	// ErrNotNormalized indicates that a passphrase needs Unicode normalization
+	// but no normalizer was supplied with WithNormalizer.
+	ErrNotNormalized = errors.New("bip39: non-ASCII passphrase requires a NFKD normalizer")
+)
+
+// Option configures Seed.
+type Option func(*options)
+
+type options struct {
+	normalize func(string) string
+}
+
+// WithNormalizer supplies the NFKD normalization BIP-0039 requires for
+// passphrases, typically norm.NFKD.String from golang.org/x/text/unicode/norm.
+// ASCII passphrases are unaffected by NFKD and need no normalizer; without
+// one, Seed refuses other passphrases rather than derive a seed no other
+// wallet would reproduce.
+func WithNormalizer(fn func(string) string) Option {
+	return func(o *options) { o.normalize = fn }
+}
+
+// EntropyToMnemonic encodes entropy as a mnemonic: one word per 11 bits of
+// entropy followed by a SHA-256 checksum.
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	if err := validateEntropy(entropy); err != nil {
+		return "", err
+	}
+
+	checksumBits := len(entropy) / 4
+	hash := sha256.Sum256(entropy)
+	data := append(append(make([]byte, 0, len(entropy)+1), entropy...), hash[0])
+	defer clear(data)
+
+	words := make([]string, (len(entropy)*8+checksumBits)/11)
+	for i := range words {
+		words[i] = wordlist[readBits(data, i*11)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy validates a mnemonic, including its checksum, and returns
+// the entropy it encodes.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	indices, err := parseMnemonic(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	totalBits := len(indices) * 11
+	checksumBits := totalBits / 33
+	data := make([]byte, (totalBits+7)/8)
+	defer clear(data)
+	for i, index := range indices {
+		writeBits(data, i*11, index)
+	}
+
+	entropy := append([]byte(nil), data[:(totalBits-checksumBits)/8]...)
+	hash := sha256.Sum256(entropy)
+	mask := byte(0xff) << (8 - checksumBits)
+	if data[len(entropy)]&mask != hash[0]&mask {
+		clear(entropy)
+		return nil, ErrInvalidChecksum
+	}
+	return entropy, nil
+}
+
+// Seed derives the 64-byte BIP-0032 master seed from a mnemonic and optional
+// passphrase. The mnemonic is validated and its canonical form is used, so an
+// abbreviated or oddly spaced phrase yields the same seed as the original.
+func Seed(mnemonic, passphrase string, opts ...Option) ([]byte, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	entropy, err := MnemonicToEntropy(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := EntropyToMnemonic(entropy)
+	clear(entropy)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isASCII(passphrase) {
+		if o.normalize == nil {
+			return nil, ErrNotNormalized
+		}
+		passphrase = o.normalize(passphrase)
+	}
+	return pbkdf2.Key(sha512.New, canonical, []byte("mnemonic"+passphrase), seedIterations, seedSize)
+}
+
+// Split validates a mnemonic and splits the entropy it encodes into parts
+// shares, threshold of which recover it with Combine.
+func Split(mnemonic string, parts, threshold int) ([][]byte, error) {
+	entropy, err := MnemonicToEntropy(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(entropy)
+	return shamir.SplitKey(entropy, shamir.AlgBIP39Entropy, parts, threshold)
+}
+
+// Combine recovers a mnemonic split with Split. It returns
+// shamir.ErrAlgorithmMismatch for shares of a seed split with SplitSeed.
+func Combine(shares [][]byte) (string, error) {
+	entropy, err := shamir.CombineKey(shares, shamir.AlgBIP39Entropy)
+	if err != nil {
+		return "", err
+	}
+	defer shamir.ReleaseSecret(entropy)
+	return EntropyToMnemonic(entropy)
+}
+
+// SplitSeed splits a BIP-0032 master seed of 16 to 64 bytes, for wallets whose
+// seed did not come from a mnemonic or whose mnemonic is not at hand.
+func SplitSeed(seed []byte, parts, threshold int) ([][]byte, error) {
+	return shamir.SplitKey(seed, shamir.AlgBIP32Seed, parts, threshold)
+}
+
+// CombineSeed recovers a master seed split with SplitSeed. It returns
+// shamir.ErrAlgorithmMismatch for shares of a mnemonic split with Split.
+func CombineSeed(shares [][]byte) ([]byte, error) {
+	return shamir.CombineKey(shares, shamir.AlgBIP32Seed)
+}
+
+// parseMnemonic returns the word list index of every word of mnemonic.
+func parseMnemonic(mnemonic string) ([]int, error) {
+	words := strings.Fields(mnemonic)
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, fmt.Errorf("%w: %d words", ErrInvalidMnemonic, len(words))
+	}
+
+	indices := make([]int, len(words))
+	for i, word := range words {
+		index, ok := wordIndex[strings.ToLower(word)]
+		if !ok {
+			return nil, fmt.Errorf("%w: word %d (%q)", ErrUnknownWord, i+1, word)
+		}
+		indices[i] = index
+	}
+	return indices, nil
+}
+
+// validateEntropy checks that entropy encodes a whole number of words.
+func validateEntropy(entropy []byte) error {
+	switch len(entropy) {
+	case 16, 20, 24, 28, 32:
+		return nil
+	}
+	return ErrInvalidEntropy
+}
+
+// readBits returns the 11-bit big-endian value starting at bit offset.
+func readBits(data []byte, offset int) int {
+	v := 0
+	for i := 0; i < 11; i++ {
+		bit := offset + i
+		v = v<<1 | int(data[bit/8]>>(7-bit%8)&1)
+	}
+	return v
+}
+
+// writeBits stores the 11-bit value v big-endian at bit offset.
+func writeBits(data []byte, offset, v int) {
+	for i := 0; i < 11; i++ {
+		if v>>(10-i)&1 == 1 {
+			bit := offset + i
+			data[bit/8] |= 1 << (7 - bit%8)
+		}
+	}
+}
+
+// isASCII reports whether s is unchanged by NFKD normalization because it is
+// plain ASCII.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+package bip39
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// Test vectors from the Trezor reference implementation (passphrase "TREZOR").
+var vectors = []struct {
+	entropy  string
+	mnemonic string
+	seed     string
+}{
+	{
+		"00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+	},
+	{
+		"80808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		"d71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8",
+	},
+	{
+		"ffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		"ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+	},
+	{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+		"bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+		mnemonic, err := EntropyToMnemonic(entropy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mnemonic != v.mnemonic {
+			t.Fatalf("mnemonic for %s:\n got %s\nwant %s", v.entropy, mnemonic, v.mnemonic)
+		}
+
+		got, err := MnemonicToEntropy(v.mnemonic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, entropy) {
+			t.Fatalf("entropy for %q: got %x", v.mnemonic, got)
+		}
+
+		seed, err := Seed(v.mnemonic, "TREZOR")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(seed) != v.seed {
+			t.Fatalf("seed for %q:\n got %x\nwant %s", v.mnemonic, seed, v.seed)
+		}
+	}
+}
+
+func TestWordlist(t *testing.T) {
+	if !sort.StringsAreSorted(wordlist[:]) {
+		t.Error("word list is not sorted")
+	}
+	prefixes := make(map[string]bool, len(wordlist))
+	for _, word := range wordlist {
+		prefix := word
+		if len(prefix) > 4 {
+			prefix = prefix[:4]
+		}
+		if prefixes[prefix] {
+			t.Errorf("prefix %q is ambiguous", prefix)
+		}
+		prefixes[prefix] = true
+	}
+}
+
+func TestSplitCombine(t *testing.T) {
+	const mnemonic = "legal winner thank year wave sausage worth useful legal winner thank yellow"
+
+	// Abbreviated, upper-case and irregularly spaced input splits the same entropy.
+	shares, err := Split("LEGA winn\tthan year wave saus  worth usef legal winner thank\u3000yell", 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, share := range shares {
+		if len(share) > 64 {
+			t.Fatalf("share of %d bytes; expected the 16-byte entropy plus envelope", len(share))
+		}
+	}
+
+	got, err := Combine([][]byte{shares[4], shares[0], shares[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != mnemonic {
+		t.Fatalf("got %q, want %q", got, mnemonic)
+	}
+
+	seed, err := Seed("lega winn than year wave saus wort usef lega winn than yell", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := Seed(mnemonic, "")
+	if !bytes.Equal(seed, want) {
+		t.Fatal("abbreviated mnemonic derived a different seed")
+	}
+}
+
+func TestSplitCombineSeed(t *testing.T) {
+	seed, err := Seed(vectors[0].mnemonic, "TREZOR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, err := SplitSeed(seed, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CombineSeed(shares[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, seed) {
+		t.Fatal("reconstructed seed mismatch")
+	}
+
+	// Seed and mnemonic shares cannot be mixed up.
+	if _, err := Combine(shares[1:]); !errors.Is(err, shamir.ErrAlgorithmMismatch) {
+		t.Fatalf("expected ErrAlgorithmMismatch, got %v", err)
+	}
+	mnemonicShares, err := Split(vectors[0].mnemonic, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CombineSeed(mnemonicShares[:2]); !errors.Is(err, shamir.ErrAlgorithmMismatch) {
+		t.Fatalf("expected ErrAlgorithmMismatch, got %v", err)
+	}
+
+	if _, err := SplitSeed(make([]byte, 8), 3, 2); !errors.Is(err, shamir.ErrInvalidKeyLength) {
+		t.Fatalf("expected ErrInvalidKeyLength, got %v", err)
+	}
+}
+
+func TestPassphraseNormalization(t *testing.T) {
+	const passphrase = "caf\u00e9" // Precomposed; NFKD is "cafe\u0301"
+
+	if _, err := Seed(vectors[0].mnemonic, passphrase); !errors.Is(err, ErrNotNormalized) {
+		t.Fatalf("expected ErrNotNormalized, got %v", err)
+	}
+
+	decompose := func(s string) string { return strings.ReplaceAll(s, "\u00e9", "e\u0301") }
+	got, err := Seed(vectors[0].mnemonic, passphrase, WithNormalizer(decompose))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Seed(vectors[0].mnemonic, "cafe\u0301", WithNormalizer(func(s string) string { return s }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("normalizer was not applied to the passphrase")
+	}
+}
+
+func TestMnemonicErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		mnemonic string
+		want     error
+	}{
+		{"too short", "abandon abandon abandon", ErrInvalidMnemonic},
+		{"unknown word", strings.Repeat("abandon ", 11) + "bitcoin", ErrUnknownWord},
+		{"bad checksum", strings.Repeat("abandon ", 11) + "abandon", ErrInvalidChecksum},
+		{"swapped words", "legal winner thank year wave sausage worth useful legal winner yellow thank", ErrInvalidChecksum},
+	}
+	for _, tt := range tests {
+		if _, err := MnemonicToEntropy(tt.mnemonic); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+		if _, err := Split(tt.mnemonic, 3, 2); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Split: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	if _, err := EntropyToMnemonic(make([]byte, 17)); !errors.Is(err, ErrInvalidEntropy) {
+		t.Fatalf("expected ErrInvalidEntropy, got %v", err)
+	}
+}
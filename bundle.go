@@ -0,0 +1,110 @@
+package shamir
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Bundle is one custodian's shares of a secret bundle, such as the variables
+// of a .env file or the fields of a JSON secret: the keys of the original
+// bundle, each mapped to that custodian's share of its value. It marshals to
+// JSON as an object of base64 strings, so a custodian's bundle can be stored
+// and handed over like the configuration it came from.
+type Bundle map[string][]byte
+
+// SplitBundle splits every value of bundle with SplitWithOptions and the given
+// options, and returns one Bundle per custodian with the same keys. Each value
+// is split independently; bundles[i] holds share i of every value. Keys must
+// be non-empty and values must not be empty; an error names the offending key.
+//
+// The keys and the length of every value are visible to each custodian.
+func SplitBundle(bundle map[string][]byte, opts ...Option) ([]Bundle, error) {
+	if len(bundle) == 0 {
+		return nil, ErrEmptySecret
+	}
+	keys := make([]string, 0, len(bundle))
+	for key := range bundle {
+		if key == "" {
+			return nil, NewValidationError("key", 0, "shamir: bundle key cannot be empty")
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var bundles []Bundle
+	for _, key := range keys {
+		shares, err := SplitWithOptions(bundle[key], opts...)
+		if err != nil {
+			wipeBundles(bundles)
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		if bundles == nil {
+			bundles = make([]Bundle, len(shares))
+			for i := range bundles {
+				bundles[i] = make(Bundle, len(keys))
+			}
+		}
+		for i, share := range shares {
+			bundles[i][key] = share
+		}
+	}
+	return bundles, nil
+}
+
+// CombineBundles reconstructs a secret bundle from at least threshold
+// custodian bundles produced by SplitBundle, combining each key with
+// CombineWithOptions and the given options. Every bundle must hold the same
+// keys; otherwise CombineBundles returns ErrMismatchedShares naming the key.
+// On any error no values are returned.
+func CombineBundles(bundles []Bundle, opts ...Option) (map[string][]byte, error) {
+	if bundles == nil {
+		return nil, ErrNilShares
+	}
+	if len(bundles) < 2 {
+		return nil, ErrTooFewParts
+	}
+	if len(bundles[0]) == 0 {
+		return nil, fmt.Errorf("%w: bundle 0 is empty", ErrMismatchedShares)
+	}
+	keys := make([]string, 0, len(bundles[0]))
+	for key := range bundles[0] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, b := range bundles[1:] {
+		if len(b) != len(keys) {
+			return nil, fmt.Errorf("%w: bundle %d has %d keys, bundle 0 has %d", ErrMismatchedShares, i+1, len(b), len(keys))
+		}
+		for _, key := range keys {
+			if _, ok := b[key]; !ok {
+				return nil, fmt.Errorf("%w: bundle %d has no key %q", ErrMismatchedShares, i+1, key)
+			}
+		}
+	}
+
+	secrets := make(map[string][]byte, len(keys))
+	parts := make([][]byte, len(bundles))
+	for _, key := range keys {
+		for i, b := range bundles {
+			parts[i] = b[key]
+		}
+		secret, err := CombineWithOptions(parts, opts...)
+		if err != nil {
+			for _, s := range secrets {
+				secureZeroBytes(s)
+			}
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		secrets[key] = secret
+	}
+	return secrets, nil
+}
+
+// wipeBundles zeroes the shares held in bundles.
+func wipeBundles(bundles []Bundle) {
+	for _, b := range bundles {
+		for _, share := range b {
+			secureZeroBytes(share)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSplitCombineBundle(t *testing.T) {
+	env := map[string][]byte{
+		"DATABASE_URL": []byte("postgres://app:hunter2@db/app"),
+		"API_TOKEN":    bytes.Repeat([]byte{0x42}, 40),
+		"PIN":          []byte("0000"),
+	}
+	bundles, err := SplitBundle(env, WithParts(5), WithThreshold(3), WithIntegrity(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundles) != 5 {
+		t.Fatalf("got %d bundles, want 5", len(bundles))
+	}
+	for i, b := range bundles {
+		if len(b) != len(env) {
+			t.Fatalf("bundle %d has %d keys, want %d", i, len(b), len(env))
+		}
+	}
+
+	// Bundles survive a JSON round trip.
+	quorum := make([]Bundle, 3)
+	for i, b := range []Bundle{bundles[4], bundles[0], bundles[2]} {
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &quorum[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := CombineBundles(quorum, WithIntegrity(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(env) {
+		t.Fatalf("got %d keys, want %d", len(got), len(env))
+	}
+	for key, value := range env {
+		if !bytes.Equal(got[key], value) {
+			t.Errorf("%s: reconstructed value mismatch", key)
+		}
+	}
+}
+
+func TestSplitBundleValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		bundle map[string][]byte
+	}{
+		{"no keys", nil},
+		{"empty key", map[string][]byte{"": []byte("x")}},
+		{"empty value", map[string][]byte{"A": []byte("x"), "B": nil}},
+	}
+	for _, tt := range tests {
+		if _, err := SplitBundle(tt.bundle, WithParts(3), WithThreshold(2)); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+	if _, err := SplitBundle(map[string][]byte{"A": nil}, WithParts(3), WithThreshold(2)); !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("expected ErrEmptySecret, got %v", err)
+	}
+}
+
+func TestCombineBundlesErrors(t *testing.T) {
+	bundles, err := SplitBundle(map[string][]byte{"A": []byte("first"), "B": []byte("second")},
+		WithParts(3), WithThreshold(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CombineBundles(nil); !errors.Is(err, ErrNilShares) {
+		t.Fatalf("expected ErrNilShares, got %v", err)
+	}
+	if _, err := CombineBundles(bundles[:1]); !errors.Is(err, ErrTooFewParts) {
+		t.Fatalf("expected ErrTooFewParts, got %v", err)
+	}
+
+	missing := Bundle{"A": bundles[1]["A"]}
+	if _, err := CombineBundles([]Bundle{bundles[0], missing}); !errors.Is(err, ErrMismatchedShares) {
+		t.Fatalf("expected ErrMismatchedShares, got %v", err)
+	}
+	renamed := Bundle{"A": bundles[1]["A"], "C": bundles[1]["B"]}
+	if _, err := CombineBundles([]Bundle{bundles[0], renamed}); !errors.Is(err, ErrMismatchedShares) {
+		t.Fatalf("expected ErrMismatchedShares, got %v", err)
+	}
+
+	short := Bundle{"A": bundles[1]["A"], "B": bundles[1]["B"][:3]}
+	if _, err := CombineBundles([]Bundle{bundles[0], short}); !errors.Is(err, ErrDifferentLengths) {
+		t.Fatalf("expected ErrDifferentLengths, got %v", err)
+	}
+}
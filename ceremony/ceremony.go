@@ -0,0 +1,359 @@
+// Package ceremony runs a recovery ceremony for a split recorded in a
+// shamir.Manifest, the procedure many organisations otherwise script by hand
+// around the low-level API.
+//
+// A ceremony goes through three phases. While registering, each participating
+// custodian registers the Ed25519 key their submissions will be signed with.
+// Start then fixes the participants and returns the session nonce, and
+// custodians submit their shares signed over the nonce with
+// shamir.SignSubmission, so a submission captured from another ceremony is
+// rejected. As soon as the manifest's threshold of shares is accepted, the
+// secret is reconstructed, handed to the release callback and wiped: the
+// secret is never returned to the caller that runs the ceremony.
+//
+//	c, err := ceremony.New(manifest, unseal,
+//		ceremony.WithTimeout(30*time.Minute),
+//		ceremony.WithAuditHook(logEvent))
+//	c.Register("alice", aliceKey)
+//	c.Register("bob", bobKey)
+//	nonce, err := c.Start()
+//	// Send nonce to the participants; each replies with share and signature
+//	err = c.Submit(share, signature)
+//	<-c.Done()
+//	err = c.Err() // nil once the secret was released
+//
+// Every step is reported to the audit hook as an Event. Events name the
+// participants and carry errors, never shares or secrets.
+package ceremony
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+var (
+	// ErrUnknownParticipant indicates a participant the manifest does not list as a custodian.
+	ErrUnknownParticipant = errors.New("ceremony: participant is not a custodian of the split")
+
+	// ErrAlreadyRegistered indicates a participant who registered twice.
+	ErrAlreadyRegistered = errors.New("ceremony: participant already registered")
+
+	// ErrWrongPhase indicates a call the ceremony does not accept in its current state, such as a
+	// registration after Start or a submission before it.
+	ErrWrongPhase = errors.New("ceremony: not allowed in the current state")
+
+	// ErrTimeout indicates a ceremony that did not release the secret before its timeout.
+	ErrTimeout = errors.New("ceremony: timed out")
+
+	// ErrAborted indicates a ceremony ended by Abort.
+	ErrAborted = errors.New("ceremony: aborted")
+
+	// ErrRelease indicates that the release callback failed; its error is wrapped as well.
+	ErrRelease = errors.New("ceremony: release failed")
+)
+
+// State is the phase a ceremony is in.
+type State int
+
+const (
+	Registering State = iota // Participants register their keys
+	Collecting               // Started; shares are being submitted
+	Released                 // The secret was handed to the release callback
+	Failed                   // Timed out, aborted, or reconstruction or release failed
+)
+
+func (s State) String() string {
+	switch s {
+	case Registering:
+		return "registering"
+	case Collecting:
+		return "collecting"
+	case Released:
+		return "released"
+	case Failed:
+		return "failed"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// EventKind classifies audit events.
+type EventKind string
+
+// Audit event kinds.
+const (
+	EventRegistered EventKind = "registered"     // A participant registered
+	EventStarted    EventKind = "started"        // Registration closed and submissions opened
+	EventSubmitted  EventKind = "submitted"      // A share was accepted
+	EventRejected   EventKind = "rejected"       // A submission was refused; see Err
+	EventQuorum     EventKind = "quorum-reached" // Enough shares were accepted to reconstruct
+	EventReleased   EventKind = "released"       // The secret was handed to the release callback
+	EventFailed     EventKind = "failed"         // The ceremony ended without releasing; see Err
+)
+
+// Event is an audit record of one step of a ceremony. It never contains
+// shares or secrets.
+type Event struct {
+	Time        time.Time
+	Kind        EventKind
+	Participant string // Participant concerned, if any and known
+	Submitted   int    // Shares accepted so far
+	Threshold   int    // Shares needed to reconstruct
+	Err         error  // Why a submission was rejected or the ceremony failed
+}
+
+// Progress is a snapshot of a ceremony.
+type Progress struct {
+	State     State
+	Threshold int      // Shares needed to reconstruct
+	Submitted []string // Participants whose shares were accepted, in order
+	Pending   []string // Registered participants yet to submit, sorted
+}
+
+// Option configures a Ceremony.
+type Option func(*Ceremony)
+
+// WithTimeout fails the ceremony with ErrTimeout if the secret has not been
+// released within d of Start.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Ceremony) { c.timeout = d }
+}
+
+// WithAuditHook calls fn with an Event for every step of the ceremony,
+// including rejected submissions. fn is called with the ceremony locked, in
+// order, and must not call its methods.
+func WithAuditHook(fn func(Event)) Option {
+	return func(c *Ceremony) { c.audit = fn }
+}
+
+// Ceremony is one recovery ceremony. It is safe for concurrent use.
+type Ceremony struct {
+	mu       sync.Mutex
+	manifest *shamir.Manifest
+	release  func(secret []byte) error
+	timeout  time.Duration
+	audit    func(Event)
+
+	state     State
+	keys      map[string]ed25519.PublicKey
+	session   *shamir.CombineSession
+	submitted []string
+	timer     *time.Timer
+	err       error
+	done      chan struct{}
+}
+
+// New prepares a ceremony for the split described by manifest. release
+// receives the reconstructed secret once; the secret is wiped when it
+// returns, so release must copy anything it keeps. It runs with the ceremony
+// locked and must not call its methods.
+func New(manifest *shamir.Manifest, release func(secret []byte) error, opts ...Option) (*Ceremony, error) {
+	if manifest == nil {
+		return nil, errors.New("ceremony: manifest required")
+	}
+	if release == nil {
+		return nil, errors.New("ceremony: release callback required")
+	}
+	c := &Ceremony{
+		manifest: manifest,
+		release:  release,
+		keys:     make(map[string]ed25519.PublicKey),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Register records the key a custodian's submissions will be verified with.
+// Only custodians listed in the manifest may register, each once, and only
+// before Start.
+func (c *Ceremony) Register(participant string, key ed25519.PublicKey) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != Registering {
+		return c.reject(participant, fmt.Errorf("%w: registration closed (%s)", ErrWrongPhase, c.state))
+	}
+	if !c.isCustodian(participant) {
+		return c.reject(participant, fmt.Errorf("%w: %q", ErrUnknownParticipant, participant))
+	}
+	if _, ok := c.keys[participant]; ok {
+		return c.reject(participant, fmt.Errorf("%w: %q", ErrAlreadyRegistered, participant))
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return c.reject(participant, fmt.Errorf("ceremony: key for %q must be %d bytes", participant, ed25519.PublicKeySize))
+	}
+
+	c.keys[participant] = key
+	c.emit(EventRegistered, participant, nil)
+	return nil
+}
+
+// Start closes registration, opens submissions and returns the nonce the
+// participants sign their submissions over. It fails if fewer participants
+// registered than the threshold requires.
+func (c *Ceremony) Start() (shamir.SessionNonce, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != Registering {
+		return shamir.SessionNonce{}, fmt.Errorf("%w: already started (%s)", ErrWrongPhase, c.state)
+	}
+	if len(c.keys) < c.manifest.Threshold {
+		return shamir.SessionNonce{}, fmt.Errorf("%w: %d participants registered, threshold is %d",
+			shamir.ErrInsufficientShares, len(c.keys), c.manifest.Threshold)
+	}
+	session, err := shamir.NewCombineSession(c.manifest, c.keys)
+	if err != nil {
+		return shamir.SessionNonce{}, err
+	}
+
+	c.session, c.state = session, Collecting
+	if c.timeout > 0 {
+		c.timer = time.AfterFunc(c.timeout, func() { c.fail(ErrTimeout) })
+	}
+	c.emit(EventStarted, "", nil)
+	return session.Nonce(), nil
+}
+
+// Submit verifies a participant's signed share (see shamir.SignSubmission)
+// and accepts it. The submission that completes the quorum also reconstructs
+// the secret and releases it; its error reports a failed reconstruction or
+// release, which ends the ceremony. A rejected submission leaves the ceremony
+// running, with the errors of shamir.CombineSession.Submit.
+func (c *Ceremony) Submit(share, signature []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	participant, _ := c.manifest.Custodian(share) // Only for auditing; Submit checks it
+	if c.state != Collecting {
+		return c.reject(participant, fmt.Errorf("%w: not collecting shares (%s)", ErrWrongPhase, c.state))
+	}
+	if err := c.session.Submit(share, signature); err != nil {
+		return c.reject(participant, err)
+	}
+	c.submitted = append(c.submitted, participant)
+	c.emit(EventSubmitted, participant, nil)
+	if len(c.submitted) < c.manifest.Threshold {
+		return nil
+	}
+
+	c.emit(EventQuorum, "", nil)
+	secret, err := c.session.Combine()
+	if err != nil {
+		c.finish(err)
+		return err
+	}
+	err = c.release(secret)
+	clear(secret)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrRelease, err)
+	}
+	c.finish(err)
+	return err
+}
+
+// Abort ends a ceremony that has not finished with ErrAborted, wiping any
+// shares submitted so far.
+func (c *Ceremony) Abort() {
+	c.fail(ErrAborted)
+}
+
+// Progress returns a snapshot of the ceremony.
+func (c *Ceremony) Progress() Progress {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := Progress{
+		State:     c.state,
+		Threshold: c.manifest.Threshold,
+		Submitted: slices.Clone(c.submitted),
+	}
+	for participant := range c.keys {
+		if !slices.Contains(c.submitted, participant) {
+			p.Pending = append(p.Pending, participant)
+		}
+	}
+	slices.Sort(p.Pending)
+	return p
+}
+
+// Done returns a channel that is closed when the ceremony has released the
+// secret or failed.
+func (c *Ceremony) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the ceremony failed: ErrTimeout, ErrAborted, an error
+// wrapping ErrRelease, or the reconstruction error. It is nil while the
+// ceremony runs and after the secret was released.
+func (c *Ceremony) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// fail ends a ceremony that has not finished with err.
+func (c *Ceremony) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == Released || c.state == Failed {
+		return
+	}
+	c.finish(err)
+}
+
+// finish ends the ceremony, released if err is nil and failed otherwise.
+func (c *Ceremony) finish(err error) {
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	if c.session != nil {
+		c.session.Close()
+	}
+	c.err = err
+	if err == nil {
+		c.state = Released
+		c.emit(EventReleased, "", nil)
+	} else {
+		c.state = Failed
+		c.emit(EventFailed, "", err)
+	}
+	close(c.done)
+}
+
+// reject audits a refused call and returns err.
+func (c *Ceremony) reject(participant string, err error) error {
+	c.emit(EventRejected, participant, err)
+	return err
+}
+
+func (c *Ceremony) emit(kind EventKind, participant string, err error) {
+	if c.audit == nil {
+		return
+	}
+	c.audit(Event{
+		Time:        time.Now().UTC(),
+		Kind:        kind,
+		Participant: participant,
+		Submitted:   len(c.submitted),
+		Threshold:   c.manifest.Threshold,
+		Err:         err,
+	})
+}
+
+func (c *Ceremony) isCustodian(participant string) bool {
+	for _, e := range c.manifest.Custodians {
+		if e.Custodian == participant && !e.Decoy {
+			return true
+		}
+	}
+	return false
+}
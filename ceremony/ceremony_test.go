@@ -0,0 +1,225 @@
+package ceremony
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+type fixture struct {
+	sharing *shamir.EscrowSharing
+	keys    map[string]ed25519.PrivateKey
+	public  map[string]ed25519.PublicKey
+	shares  map[string][]byte
+
+	mu     sync.Mutex
+	events []Event
+}
+
+var custodians = []string{"alice", "bob", "carol", "dave"}
+
+func newFixture(t *testing.T, secret []byte) *fixture {
+	t.Helper()
+	policy := shamir.EscrowPolicy{Name: "ops", Threshold: 3, Custodians: custodians}
+	sharing, _, err := shamir.SplitDualEscrow(secret, policy, shamir.EscrowPolicy{Name: "legal", Threshold: 2, Custodians: []string{"x", "y"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fixture{
+		sharing: sharing,
+		keys:    make(map[string]ed25519.PrivateKey),
+		public:  make(map[string]ed25519.PublicKey),
+		shares:  make(map[string][]byte),
+	}
+	for i, c := range custodians {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.keys[c], f.public[c] = priv, pub
+		f.shares[c] = sharing.Shares[i]
+	}
+	return f
+}
+
+func (f *fixture) audit(e Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, e)
+}
+
+func (f *fixture) kinds() []EventKind {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var kinds []EventKind
+	for _, e := range f.events {
+		kinds = append(kinds, e.Kind)
+	}
+	return kinds
+}
+
+func (f *fixture) submit(c *Ceremony, nonce shamir.SessionNonce, participant string) error {
+	share := f.shares[participant]
+	return c.Submit(share, shamir.SignSubmission(f.keys[participant], share, f.sharing.Manifest.SetID, nonce))
+}
+
+func TestCeremony(t *testing.T) {
+	secret := []byte("root unseal key")
+	f := newFixture(t, secret)
+
+	var released []byte
+	c, err := New(f.sharing.Manifest, func(s []byte) error {
+		released = bytes.Clone(s)
+		return nil
+	}, WithAuditHook(f.audit), WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{"alice", "bob", "carol"} {
+		if err := c.Register(p, f.public[p]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Register("alice", f.public["alice"]); !errors.Is(err, ErrAlreadyRegistered) {
+		t.Fatalf("expected ErrAlreadyRegistered, got %v", err)
+	}
+	if err := c.Register("mallory", f.public["alice"]); !errors.Is(err, ErrUnknownParticipant) {
+		t.Fatalf("expected ErrUnknownParticipant, got %v", err)
+	}
+	if err := c.Submit(f.shares["alice"], nil); !errors.Is(err, ErrWrongPhase) {
+		t.Fatalf("submission before Start: expected ErrWrongPhase, got %v", err)
+	}
+
+	nonce, err := c.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register("dave", f.public["dave"]); !errors.Is(err, ErrWrongPhase) {
+		t.Fatalf("registration after Start: expected ErrWrongPhase, got %v", err)
+	}
+
+	if err := f.submit(c, nonce, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.submit(c, nonce, "alice"); !errors.Is(err, shamir.ErrDuplicatePart) {
+		t.Fatalf("expected ErrDuplicatePart, got %v", err)
+	}
+	if err := f.submit(c, nonce, "dave"); !errors.Is(err, shamir.ErrAuthenticationFailed) {
+		t.Fatalf("unregistered participant: expected ErrAuthenticationFailed, got %v", err)
+	}
+	if err := f.submit(c, nonce, "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	p := c.Progress()
+	if p.State != Collecting || p.Threshold != 3 || !slices.Equal(p.Submitted, []string{"alice", "bob"}) || !slices.Equal(p.Pending, []string{"carol"}) {
+		t.Fatalf("progress = %+v", p)
+	}
+	if released != nil {
+		t.Fatal("secret released before the quorum")
+	}
+
+	if err := f.submit(c, nonce, "carol"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-c.Done():
+	default:
+		t.Fatal("Done not closed after release")
+	}
+	if c.Err() != nil || c.Progress().State != Released {
+		t.Fatalf("state = %s, err = %v", c.Progress().State, c.Err())
+	}
+	if !bytes.Equal(released, secret) {
+		t.Fatal("released the wrong secret")
+	}
+
+	want := []EventKind{
+		EventRegistered, EventRegistered, EventRegistered, EventRejected, EventRejected, EventRejected,
+		EventStarted, EventRejected, EventSubmitted, EventRejected, EventRejected, EventSubmitted,
+		EventSubmitted, EventQuorum, EventReleased,
+	}
+	if got := f.kinds(); !slices.Equal(got, want) {
+		t.Fatalf("events = %v\nwant %v", got, want)
+	}
+}
+
+func TestCeremonyTimeout(t *testing.T) {
+	f := newFixture(t, []byte("secret"))
+	c, err := New(f.sharing.Manifest, func([]byte) error {
+		t.Error("secret released")
+		return nil
+	}, WithTimeout(10*time.Millisecond), WithAuditHook(f.audit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range custodians {
+		c.Register(p, f.public[p])
+	}
+	nonce, err := c.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.submit(c, nonce, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("ceremony did not time out")
+	}
+	if !errors.Is(c.Err(), ErrTimeout) || c.Progress().State != Failed {
+		t.Fatalf("state = %s, err = %v", c.Progress().State, c.Err())
+	}
+	if err := f.submit(c, nonce, "bob"); !errors.Is(err, ErrWrongPhase) {
+		t.Fatalf("expected ErrWrongPhase after timeout, got %v", err)
+	}
+	if kinds := f.kinds(); !slices.Contains(kinds, EventFailed) {
+		t.Fatalf("no failed event in %v", kinds)
+	}
+}
+
+func TestCeremonyReleaseError(t *testing.T) {
+	f := newFixture(t, []byte("secret"))
+	boom := errors.New("hsm offline")
+	c, _ := New(f.sharing.Manifest, func([]byte) error { return boom })
+	for _, p := range custodians {
+		c.Register(p, f.public[p])
+	}
+	nonce, _ := c.Start()
+	f.submit(c, nonce, "alice")
+	f.submit(c, nonce, "bob")
+	err := f.submit(c, nonce, "dave")
+	if !errors.Is(err, ErrRelease) || !errors.Is(err, boom) {
+		t.Fatalf("expected ErrRelease wrapping the callback error, got %v", err)
+	}
+	if !errors.Is(c.Err(), ErrRelease) || c.Progress().State != Failed {
+		t.Fatalf("state = %s, err = %v", c.Progress().State, c.Err())
+	}
+}
+
+func TestCeremonyAbort(t *testing.T) {
+	f := newFixture(t, []byte("secret"))
+	c, _ := New(f.sharing.Manifest, func([]byte) error { return nil })
+	c.Register("alice", f.public["alice"])
+	if _, err := c.Start(); !errors.Is(err, shamir.ErrInsufficientShares) {
+		t.Fatalf("expected ErrInsufficientShares with too few participants, got %v", err)
+	}
+
+	c.Abort()
+	if !errors.Is(c.Err(), ErrAborted) {
+		t.Fatalf("expected ErrAborted, got %v", c.Err())
+	}
+	if _, err := c.Start(); !errors.Is(err, ErrWrongPhase) {
+		t.Fatalf("expected ErrWrongPhase after Abort, got %v", err)
+	}
+	c.Abort() // No effect once finished
+}
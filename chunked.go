@@ -0,0 +1,119 @@
+package shamir
+
+import (
+	"bytes"
+	"io"
+)
+
+// Chunked splits.
+//
+// A share is as large as the secret, and reconstruction holds the secret and
+// its working buffers in memory at once, so a single interpolation over a
+// multi-gigabyte secret needs several times that much memory. Secrets above a
+// configured chunk size are instead split into framed chunks, in the layout
+// SplitStream writes, and reassembled chunk by chunk.
+
+// MaxSecretSize is the largest secret, in bytes, that Split and
+// SplitWithOptions share in one piece (1 GiB). Larger secrets are refused
+// with a *ValidationError; split them with WithChunkSize or SplitStream.
+const MaxSecretSize = 1 << 30
+
+// maxSecretSize is MaxSecretSize, lowered by tests.
+var maxSecretSize = MaxSecretSize
+
+// checkSecretSize refuses secrets above MaxSecretSize.
+func checkSecretSize(secret []byte) error {
+	if len(secret) > maxSecretSize {
+		return NewValidationError("secret", len(secret), "shamir: secret exceeds MaxSecretSize; use WithChunkSize or SplitStream")
+	}
+	return nil
+}
+
+// WithChunkSize makes SplitWithOptions split a secret longer than size bytes
+// into chunks of size bytes, each shared with fresh randomness, and emit
+// every share as a framed stream (see SplitStream). Combine and
+// CombineWithOptions recognise such shares and reassemble the secret one
+// chunk at a time, as CombineStream does, so memory stays near the size of
+// the secret however large it is. Secrets of at most size bytes are split as
+// usual; larger ones are not subject to MaxSecretSize.
+//
+// size must not exceed 16 MiB; zero or a negative size disables chunking.
+// Frames carry their own CRC32, so WithIntegrity is implied. Chunked shares
+// are not enveloped: WithPurpose, WithLabels, WithValidity and WithCommitment
+// cannot be combined with chunking, and the threshold must be at least 2.
+func WithChunkSize(size int) Option {
+	return func(o *options) { o.chunkSize = size }
+}
+
+// splitChunked implements SplitWithOptions for a secret longer than the
+// chunk size.
+func splitChunked(secret []byte, o *options) ([][]byte, error) {
+	if o.purpose != "" || o.labels != nil || o.hasValidity() || o.commitment {
+		return nil, NewValidationError("chunkSize", o.chunkSize, "shamir: chunked shares cannot be enveloped")
+	}
+	xCoords, err := o.coordinates(secret[:1])
+	if err != nil {
+		return nil, err
+	}
+	if o.threshold < 2 {
+		return nil, NewValidationError("threshold", o.threshold, "shamir: chunked splits need a threshold of at least 2")
+	}
+
+	chunks := (len(secret) + o.chunkSize - 1) / o.chunkSize
+	size := streamHeaderSize + len(secret) + (chunks+1)*(frameHeaderSize+4)
+	bufs := make([]bytes.Buffer, len(xCoords))
+	dsts := make([]io.Writer, len(xCoords))
+	for i := range bufs {
+		bufs[i].Grow(size)
+		dsts[i] = &bufs[i]
+	}
+	if err := splitFramed(bytes.NewReader(secret), dsts, xCoords, o.threshold, o.chunkSize, o.rand, o.engine()); err != nil {
+		return nil, err
+	}
+
+	shares := make([][]byte, len(bufs))
+	for i := range bufs {
+		shares[i] = bufs[i].Bytes()
+	}
+	return shares, nil
+}
+
+// isChunked reports whether every share is a framed stream, as a chunked
+// split produces.
+func isChunked(parts [][]byte) bool {
+	for _, part := range parts {
+		if wireFormat(part) != "framed stream" {
+			return false
+		}
+	}
+	return len(parts) > 0
+}
+
+// combineChunked reassembles the secret from chunked shares.
+func combineChunked(parts [][]byte, eng engine) ([]byte, error) {
+	srcs := make([]io.Reader, len(parts))
+	for i, part := range parts {
+		srcs[i] = bytes.NewReader(part)
+	}
+	// Every share is at least as long as the secret it carries.
+	w := &secretWriter{buf: allocSecret(len(parts[0]))[:0]}
+	if err := combineFramed(w, srcs, eng); err != nil {
+		freeSecret(w.buf)
+		return nil, err
+	}
+	return w.buf, nil
+}
+
+// secretWriter collects a reassembled secret in a buffer allocated up front,
+// so it is never copied while it grows.
+type secretWriter struct {
+	buf []byte
+}
+
+func (w *secretWriter) Write(p []byte) (int, error) {
+	if len(p) > cap(w.buf)-len(w.buf) {
+		return 0, ErrInvalidStream
+	}
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
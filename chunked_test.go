@@ -0,0 +1,106 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestChunkedSplit(t *testing.T) {
+	secret := make([]byte, 10000)
+	rand.Read(secret)
+
+	shares, err := SplitWithOptions(secret, WithParts(5), WithThreshold(3), WithChunkSize(1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, share := range shares {
+		if info, err := InspectShare(share); err != nil || info.Format != "framed stream" {
+			t.Fatalf("share %d: format %q, %v", i, info.Format, err)
+		}
+	}
+
+	if got, err := Combine(shares[:3]); err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("Combine: %v", err)
+	}
+	if got, err := CombineWithOptions([][]byte{shares[4], shares[1], shares[2]}); err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("CombineWithOptions: %v", err)
+	}
+	if _, err := Combine(shares[:2]); !errors.Is(err, ErrInsufficientShares) {
+		t.Errorf("two shares: expected ErrInsufficientShares, got %v", err)
+	}
+
+	// Chunked shares are framed streams, and CombineStream reads them too.
+	var out bytes.Buffer
+	if err := CombineStream(&out, []io.Reader{bytes.NewReader(shares[0]), bytes.NewReader(shares[3]), bytes.NewReader(shares[4])}); err != nil || !bytes.Equal(out.Bytes(), secret) {
+		t.Fatalf("CombineStream: %v", err)
+	}
+
+	truncated := shares[1][:len(shares[1])-frameHeaderSize-4]
+	if _, err := Combine([][]byte{shares[0], truncated, shares[2]}); !errors.Is(err, ErrInvalidStream) {
+		t.Errorf("truncated share: expected ErrInvalidStream, got %v", err)
+	}
+}
+
+func TestChunkedSplitSmallSecret(t *testing.T) {
+	// Secrets no longer than the chunk size are split as usual.
+	shares, err := SplitWithOptions([]byte("short"), WithParts(3), WithThreshold(2), WithChunkSize(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares[0]) != 5+ShareOverhead {
+		t.Fatalf("expected raw shares, got %d bytes", len(shares[0]))
+	}
+}
+
+func TestChunkedSplitOptions(t *testing.T) {
+	secret := bytes.Repeat([]byte{7}, 100)
+	shares, err := SplitWithOptions(secret, WithXCoordinates(9, 4, 200), WithThreshold(2), WithChunkSize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, x := range []byte{9, 4, 200} {
+		if shares[i][5] != x {
+			t.Errorf("share %d: x-coordinate %d, want %d", i, shares[i][5], x)
+		}
+	}
+	if got, err := Combine(shares[1:]); err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("combine: %v", err)
+	}
+
+	var verr *ValidationError
+	for name, opts := range map[string][]Option{
+		"labels":    {WithLabels("a", "b", "c")},
+		"commit":    {WithCommitment(true)},
+		"too large": {WithChunkSize(maxStreamChunkSize + 1)},
+		"trivial":   {WithThreshold(1), WithAllowTrivialThreshold(true)},
+	} {
+		opts = append([]Option{WithParts(3), WithThreshold(2), WithChunkSize(16)}, opts...)
+		if _, err := SplitWithOptions(secret, opts...); !errors.As(err, &verr) {
+			t.Errorf("%s: expected a ValidationError, got %v", name, err)
+		}
+	}
+}
+
+func TestMaxSecretSize(t *testing.T) {
+	defer func(n int) { maxSecretSize = n }(maxSecretSize)
+	maxSecretSize = 64
+	secret := bytes.Repeat([]byte{1}, 65)
+
+	var verr *ValidationError
+	if _, err := Split(secret, 3, 2); !errors.As(err, &verr) || verr.Field != "secret" {
+		t.Errorf("Split: expected a ValidationError, got %v", err)
+	}
+	if _, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2)); !errors.As(err, &verr) || verr.Field != "secret" {
+		t.Errorf("SplitWithOptions: expected a ValidationError, got %v", err)
+	}
+	shares, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithChunkSize(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Combine(shares[:2]); err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("combine: %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// Report is the JSON document run writes and compare reads.
+type Report struct {
+	GoVersion string    `json:"go_version"`
+	Backend   string    `json:"backend"`
+	Date      time.Time `json:"date"`
+	Results   []Result  `json:"results"`
+}
+
+// Result is the measurement of one operation on one secret size and scheme.
+type Result struct {
+	Name       string  `json:"name"` // Unique key, e.g. "combine/3of5/1024"
+	Op         string  `json:"op"`
+	Parts      int     `json:"parts"`
+	Threshold  int     `json:"threshold"`
+	Size       int     `json:"size"` // Secret size in bytes
+	Iterations int     `json:"iterations"`
+	NsPerOp    float64 `json:"ns_per_op"`
+	MBPerSec   float64 `json:"mb_per_s"` // Secret bytes processed, in 10⁶ bytes per second
+}
+
+// scheme is a threshold scheme: threshold shares of parts reconstruct.
+type scheme struct {
+	parts, threshold int
+}
+
+func (s scheme) String() string {
+	return fmt.Sprintf("%dof%d", s.threshold, s.parts)
+}
+
+// operations maps each benchmarked operation to a function returning the
+// closure to time, prepared for one secret and scheme.
+var operations = map[string]func(secret []byte, s scheme) (func() error, error){
+	"split": func(secret []byte, s scheme) (func() error, error) {
+		return func() error {
+			_, err := shamir.Split(secret, s.parts, s.threshold)
+			return err
+		}, nil
+	},
+	"combine": func(secret []byte, s scheme) (func() error, error) {
+		shares, err := shamir.Split(secret, s.parts, s.threshold)
+		if err != nil {
+			return nil, err
+		}
+		quorum := shares[:s.threshold]
+		return func() error {
+			_, err := shamir.Combine(quorum)
+			return err
+		}, nil
+	},
+}
+
+func runBench(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("run", stderr)
+	sizesFlag := fs.String("sizes", "32,1024,65536,1048576", "comma-separated secret sizes in bytes")
+	schemesFlag := fs.String("schemes", "2of3,3of5,5of10", "comma-separated threshold schemes, KofN")
+	opsFlag := fs.String("ops", "split,combine", "comma-separated operations to benchmark")
+	benchtime := fs.Duration("benchtime", time.Second, "minimum run time of each benchmark")
+	out := fs.String("out", "", "write the report to FILE instead of stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "shamir-bench run: unexpected argument %q\n", fs.Arg(0))
+		return errUsage
+	}
+
+	sizes, err := parseSizes(*sizesFlag)
+	if err != nil {
+		return err
+	}
+	schemes, err := parseSchemes(*schemesFlag)
+	if err != nil {
+		return err
+	}
+	ops := strings.Split(*opsFlag, ",")
+	for _, op := range ops {
+		if operations[op] == nil {
+			return fmt.Errorf("unknown operation %q", op)
+		}
+	}
+
+	report := Report{
+		GoVersion: runtime.Version(),
+		Backend:   shamir.ActiveBackend().String(),
+		Date:      time.Now().UTC(),
+	}
+	for _, op := range ops {
+		for _, s := range schemes {
+			for _, size := range sizes {
+				r, err := benchmark(op, s, size, *benchtime)
+				if err != nil {
+					return fmt.Errorf("%s/%s/%d: %w", op, s, size, err)
+				}
+				fmt.Fprintf(stderr, "%-28s %12.0f ns/op %10.2f MB/s\n", r.Name, r.NsPerOp, r.MBPerSec)
+				report.Results = append(report.Results, r)
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *out == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o644)
+}
+
+// benchmark times op on a random secret of size bytes, growing the
+// iteration count as the testing package does until a run lasts benchtime.
+func benchmark(op string, s scheme, size int, benchtime time.Duration) (Result, error) {
+	secret := make([]byte, size)
+	if _, err := rand.Read(secret); err != nil {
+		return Result{}, err
+	}
+	fn, err := operations[op](secret, s)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := fn(); err != nil { // Warm up, and fail early on bad parameters
+		return Result{}, err
+	}
+
+	n := 1
+	var elapsed time.Duration
+	for {
+		runtime.GC()
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			if err := fn(); err != nil {
+				return Result{}, err
+			}
+		}
+		elapsed = time.Since(start)
+		if elapsed >= benchtime || n >= 1e9 {
+			break
+		}
+		// Predict the count that fills benchtime, with 20% headroom, growing
+		// at least by one and at most a hundredfold per round.
+		next := int(float64(n) * 1.2 * float64(benchtime) / float64(max(elapsed, 1)))
+		n = min(max(next, n+1), 100*n)
+	}
+
+	nsPerOp := float64(elapsed.Nanoseconds()) / float64(n)
+	return Result{
+		Name:       fmt.Sprintf("%s/%s/%d", op, s, size),
+		Op:         op,
+		Parts:      s.parts,
+		Threshold:  s.threshold,
+		Size:       size,
+		Iterations: n,
+		NsPerOp:    nsPerOp,
+		MBPerSec:   float64(size) * 1e3 / nsPerOp,
+	}, nil
+}
+
+// parseSizes parses a comma-separated list of positive byte counts.
+func parseSizes(list string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.Split(list, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("invalid size %q", field)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// parseSchemes parses a comma-separated list of KofN threshold schemes.
+func parseSchemes(list string) ([]scheme, error) {
+	var schemes []scheme
+	for _, field := range strings.Split(list, ",") {
+		k, n, ok := strings.Cut(strings.TrimSpace(field), "of")
+		threshold, errK := strconv.Atoi(k)
+		parts, errN := strconv.Atoi(n)
+		if !ok || errK != nil || errN != nil || threshold < 2 || threshold > parts || parts > 255 {
+			return nil, fmt.Errorf("invalid scheme %q, want KofN with 2 <= K <= N <= 255", field)
+		}
+		schemes = append(schemes, scheme{parts: parts, threshold: threshold})
+	}
+	return schemes, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// comparison is the change in one result between two reports.
+type comparison struct {
+	name       string
+	before     float64 // ns/op in the old report
+	after      float64 // ns/op in the new report
+	delta      float64 // Percentage change in ns/op; positive is slower
+	regression bool
+}
+
+func runCompare(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("compare", stderr)
+	threshold := fs.Float64("threshold", 10, "flag results more than this many percent slower")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(stderr, "shamir-bench compare: need exactly two reports, OLD and NEW")
+		return errUsage
+	}
+
+	old, err := readReport(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	cur, err := readReport(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if old.Backend != cur.Backend {
+		fmt.Fprintf(stdout, "note: backends differ:\n  old: %s\n  new: %s\n\n", old.Backend, cur.Backend)
+	}
+
+	comparisons, missing := compareReports(old, cur, *threshold)
+	fmt.Fprintf(stdout, "%-28s %14s %14s %9s\n", "name", "old ns/op", "new ns/op", "delta")
+	regressions := 0
+	for _, c := range comparisons {
+		mark := ""
+		if c.regression {
+			mark = "  REGRESSION"
+			regressions++
+		}
+		fmt.Fprintf(stdout, "%-28s %14.0f %14.0f %+8.1f%%%s\n", c.name, c.before, c.after, c.delta, mark)
+	}
+	for _, name := range missing {
+		fmt.Fprintf(stdout, "%-28s only in one report\n", name)
+	}
+
+	if regressions > 0 {
+		fmt.Fprintf(stdout, "\n%d of %d results regressed by more than %g%%\n", regressions, len(comparisons), *threshold)
+		return errRegression
+	}
+	return nil
+}
+
+// compareReports matches the results of old and cur by name, in the order of
+// cur, flagging those more than threshold percent slower. It also returns the
+// names found in only one of the reports.
+func compareReports(old, cur Report, threshold float64) (comparisons []comparison, missing []string) {
+	previous := make(map[string]Result, len(old.Results))
+	for _, r := range old.Results {
+		previous[r.Name] = r
+	}
+	for _, r := range cur.Results {
+		p, ok := previous[r.Name]
+		if !ok {
+			missing = append(missing, r.Name)
+			continue
+		}
+		delete(previous, r.Name)
+		delta := 0.0
+		if p.NsPerOp > 0 {
+			delta = (r.NsPerOp - p.NsPerOp) / p.NsPerOp * 100
+		}
+		comparisons = append(comparisons, comparison{
+			name:       r.Name,
+			before:     p.NsPerOp,
+			after:      r.NsPerOp,
+			delta:      delta,
+			regression: delta > threshold,
+		})
+	}
+	for _, r := range old.Results {
+		if _, ok := previous[r.Name]; ok {
+			missing = append(missing, r.Name)
+		}
+	}
+	return comparisons, missing
+}
+
+// readReport reads a report written by run.
+func readReport(name string) (Report, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return Report{}, err
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Report{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return r, nil
+}
@@ -0,0 +1,96 @@
+// Command shamir-bench measures Split and Combine throughput and compares
+// runs, so performance claims can be checked for every release.
+//
+// Usage:
+//
+//	shamir-bench run     [-sizes 32,1024,65536,1048576] [-schemes 2of3,3of5,5of10] [-ops split,combine] [-benchtime 1s] [-out FILE]
+//	shamir-bench compare [-threshold 10] OLD.json NEW.json
+//
+// run writes a JSON report with ns/op and MB/s for every operation, secret
+// size and threshold scheme, together with the Go version and the field
+// arithmetic backend. compare matches the results of two reports by name and
+// exits with status 1 if any operation got slower by more than -threshold
+// percent:
+//
+//	shamir-bench run -out base.json
+//	git checkout feature && shamir-bench run -out head.json
+//	shamir-bench compare -threshold 5 base.json head.json
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage: shamir-bench <command> [flags]
+
+Commands:
+  run      benchmark Split and Combine and write a JSON report
+  compare  compare two reports and flag regressions
+
+Run "shamir-bench <command> -h" for the flags of a command.
+`
+
+// errUsage signals a flag error that has already been reported.
+var errUsage = errors.New("usage")
+
+// errRegression signals that compare found a regression; the report has
+// already been printed.
+var errRegression = errors.New("regression")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the process exit code: 0 on
+// success, 1 on failure or regression, 2 on usage errors.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	commands := map[string]func([]string, io.Writer, io.Writer) error{
+		"run":     runBench,
+		"compare": runCompare,
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		if args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+			fmt.Fprint(stdout, usage)
+			return 0
+		}
+		fmt.Fprintf(stderr, "shamir-bench: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	switch err := cmd(args[1:], stdout, stderr); err {
+	case nil:
+		return 0
+	case errUsage:
+		return 2
+	case errRegression:
+		return 1
+	default:
+		fmt.Fprintf(stderr, "shamir-bench %s: %v\n", args[0], err)
+		return 1
+	}
+}
+
+// newFlagSet returns a flag set that reports errors to stderr instead of
+// exiting the process.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("shamir-bench "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage // The flag package has already printed the problem
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCLI(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestRun(t *testing.T) {
+	out, errOut, code := runCLI(t, "run", "-sizes", "32,1024", "-schemes", "2of3,3of5", "-benchtime", "1ms")
+	if code != 0 {
+		t.Fatalf("run exited %d: %s", code, errOut)
+	}
+	var report Report
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("report is not JSON: %v\n%s", err, out)
+	}
+	if report.GoVersion == "" || report.Backend == "" {
+		t.Errorf("report lacks the environment: %+v", report)
+	}
+	if len(report.Results) != 8 {
+		t.Fatalf("got %d results, want 8", len(report.Results))
+	}
+	r := report.Results[len(report.Results)-1]
+	if r.Name != "combine/3of5/1024" || r.Parts != 5 || r.Threshold != 3 || r.Size != 1024 {
+		t.Errorf("unexpected result %+v", r)
+	}
+	if r.Iterations < 1 || r.NsPerOp <= 0 || r.MBPerSec <= 0 {
+		t.Errorf("no measurement in %+v", r)
+	}
+}
+
+func writeReport(t *testing.T, dir, name string, results ...Result) string {
+	t.Helper()
+	data, err := json.Marshal(Report{Backend: "test", Results: results})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCompare(t *testing.T) {
+	dir := t.TempDir()
+	old := writeReport(t, dir, "old.json",
+		Result{Name: "split/3of5/1024", NsPerOp: 1000},
+		Result{Name: "combine/3of5/1024", NsPerOp: 1000},
+		Result{Name: "combine/3of5/32", NsPerOp: 100},
+	)
+	slower := writeReport(t, dir, "new.json",
+		Result{Name: "split/3of5/1024", NsPerOp: 1050},
+		Result{Name: "combine/3of5/1024", NsPerOp: 1250},
+		Result{Name: "split/2of3/1024", NsPerOp: 900},
+	)
+
+	out, errOut, code := runCLI(t, "compare", old, slower)
+	if code != 1 {
+		t.Fatalf("compare exited %d, want 1: %s", code, errOut)
+	}
+	for _, want := range []string{"combine/3of5/1024", "+25.0%  REGRESSION", "1 of 2 results regressed", "split/2of3/1024", "combine/3of5/32"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "+5.0%  REGRESSION") {
+		t.Errorf("a 5%% slowdown was flagged at the default threshold:\n%s", out)
+	}
+
+	if _, errOut, code := runCLI(t, "compare", "-threshold", "30", old, slower); code != 0 {
+		t.Errorf("compare -threshold 30 exited %d: %s", code, errOut)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no command", nil, 2},
+		{"unknown command", []string{"frobnicate"}, 2},
+		{"help", []string{"help"}, 0},
+		{"one report", []string{"compare", "old.json"}, 2},
+		{"bad scheme", []string{"run", "-schemes", "5of3"}, 1},
+		{"bad size", []string{"run", "-sizes", "0"}, 1},
+		{"unknown op", []string{"run", "-ops", "reshare"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, code := runCLI(t, tt.args...); code != tt.code {
+				t.Errorf("exit code = %d, want %d", code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// chunkSplitter splits a secret supplied in chunks, as a browser reads a
+// File. Every secret byte has its own polynomial, so splitting each chunk at
+// the same x-coordinates and concatenating the y-values yields shares in the
+// Split format, the layout shamir.NewSplitter produces: the first piece of
+// each share starts with its x-coordinate, and later pieces are y-values only.
+type chunkSplitter struct {
+	parts, threshold int
+	started          bool
+}
+
+func newChunkSplitter(parts, threshold int) (*chunkSplitter, error) {
+	if parts < 2 || parts > 255 {
+		return nil, shamir.ErrInvalidParts
+	}
+	if threshold < 2 || threshold > parts {
+		return nil, shamir.ErrInvalidThreshold
+	}
+	return &chunkSplitter{parts: parts, threshold: threshold}, nil
+}
+
+// write splits chunk and returns one piece per share.
+func (s *chunkSplitter) write(chunk []byte) ([][]byte, error) {
+	if len(chunk) == 0 {
+		return make([][]byte, s.parts), nil
+	}
+	shares, err := shamir.Split(chunk, s.parts, s.threshold)
+	if err != nil {
+		return nil, err
+	}
+	if s.started {
+		for i, share := range shares {
+			shares[i] = share[1:]
+		}
+	}
+	s.started = true
+	return shares, nil
+}
+
+// chunkJoiner reconstructs a secret from shares supplied in pieces, the
+// counterpart of chunkSplitter. Each write takes one piece of every share, in
+// the same order every time and all of the same length; the first pieces
+// start with the x-coordinates.
+type chunkJoiner struct {
+	xCoords []byte
+}
+
+// write combines one piece of every share and returns that part of the
+// secret.
+func (j *chunkJoiner) write(pieces [][]byte) ([]byte, error) {
+	if j.xCoords == nil {
+		if len(pieces) < 2 {
+			return nil, shamir.ErrTooFewParts
+		}
+		xCoords := make([]byte, len(pieces))
+		for i, piece := range pieces {
+			if len(piece) == 0 {
+				return nil, shamir.ErrTooShort
+			}
+			xCoords[i] = piece[0]
+			pieces[i] = piece[1:]
+		}
+		j.xCoords = xCoords
+	} else if len(pieces) != len(j.xCoords) {
+		return nil, fmt.Errorf("got pieces of %d shares, want %d", len(pieces), len(j.xCoords))
+	}
+
+	for _, piece := range pieces {
+		if len(piece) != len(pieces[0]) {
+			return nil, shamir.ErrDifferentLengths
+		}
+	}
+	if len(pieces[0]) == 0 {
+		return []byte{}, nil
+	}
+	parts := make([][]byte, len(pieces))
+	for i, piece := range pieces {
+		parts[i] = append([]byte{j.xCoords[i]}, piece...)
+	}
+	return shamir.Combine(parts)
+}
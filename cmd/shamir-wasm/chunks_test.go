@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+func TestChunkSplitJoin(t *testing.T) {
+	secret := make([]byte, 1000)
+	rand.Read(secret)
+
+	s, err := newChunkSplitter(5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares := make([][]byte, 5)
+	var pieces [][][]byte // pieces[write][share]
+	for _, chunk := range [][]byte{nil, secret[:1], secret[1:400], {}, secret[400:]} {
+		p, err := s.write(chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range shares {
+			shares[i] = append(shares[i], p[i]...)
+		}
+		pieces = append(pieces, p)
+	}
+
+	// Concatenated pieces are ordinary Split shares.
+	got, err := shamir.Combine([][]byte{shares[4], shares[0], shares[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatal("combined shares do not match the secret")
+	}
+
+	// The joiner takes the same pieces; the empty first write sets nothing.
+	j := &chunkJoiner{}
+	var joined []byte
+	for _, p := range pieces[1:] {
+		part, err := j.write([][]byte{p[1], p[3], p[4]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		joined = append(joined, part...)
+	}
+	if !bytes.Equal(joined, secret) {
+		t.Fatal("joined pieces do not match the secret")
+	}
+}
+
+func TestChunkJoinerErrors(t *testing.T) {
+	if _, err := newChunkSplitter(1, 2); !errors.Is(err, shamir.ErrInvalidParts) {
+		t.Fatalf("expected ErrInvalidParts, got %v", err)
+	}
+	if _, err := newChunkSplitter(3, 4); !errors.Is(err, shamir.ErrInvalidThreshold) {
+		t.Fatalf("expected ErrInvalidThreshold, got %v", err)
+	}
+
+	j := &chunkJoiner{}
+	if _, err := j.write([][]byte{{1, 2}}); !errors.Is(err, shamir.ErrTooFewParts) {
+		t.Fatalf("expected ErrTooFewParts, got %v", err)
+	}
+	if _, err := j.write([][]byte{{1, 2}, {}}); !errors.Is(err, shamir.ErrTooShort) {
+		t.Fatalf("expected ErrTooShort, got %v", err)
+	}
+	if _, err := j.write([][]byte{{1, 2}, {2, 3, 4}}); !errors.Is(err, shamir.ErrDifferentLengths) {
+		t.Fatalf("expected ErrDifferentLengths, got %v", err)
+	}
+	j = &chunkJoiner{}
+	if _, err := j.write([][]byte{{1, 2}, {2, 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.write([][]byte{{1}, {2}, {3}}); err == nil {
+		t.Fatal("expected an error for a different number of shares")
+	}
+}
@@ -0,0 +1,210 @@
+//go:build js && wasm
+
+// Command shamir-wasm exposes the shamir package to JavaScript, so that
+// browser-based recovery tools run exactly the implementation the rest of the
+// organisation uses. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o shamir.wasm ./cmd/shamir-wasm
+//
+// and load it with the wasm_exec.js shipped in $(go env GOROOT)/lib/wasm. It
+// defines a global shamir object:
+//
+//	shamir.split(secret, parts, threshold, options?)  -> Uint8Array[]
+//	shamir.combine(shares, options?)                  -> Uint8Array
+//	shamir.newSplitter(parts, threshold)              -> {write(chunk) -> Uint8Array[]}
+//	shamir.newJoiner()                                -> {write(pieces) -> Uint8Array}
+//	shamir.backend()                                  -> string
+//
+// Secrets and shares are Uint8Arrays. options may set integrity (boolean, see
+// shamir.WithIntegrity) and purpose (string, see shamir.WithPurpose).
+// Functions return an Error instead of throwing, since a Go panic would stop
+// the module:
+//
+//	const shares = shamir.split(secret, 5, 3);
+//	if (shares instanceof Error) throw shares;
+//
+// newSplitter and newJoiner work chunk by chunk, for secrets read with
+// File.stream(). Each splitter write returns one piece per share;
+// concatenating a share's pieces gives a share in the shamir.Split format.
+// Each joiner write takes the next piece of every share, in the same order
+// each time and all of the same length, and returns that part of the secret.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"syscall/js"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+var (
+	uint8Array = js.Global().Get("Uint8Array")
+	jsError    = js.Global().Get("Error")
+)
+
+func main() {
+	js.Global().Set("shamir", js.ValueOf(map[string]any{
+		"split":       export(split),
+		"combine":     export(combine),
+		"newSplitter": export(newSplitter),
+		"newJoiner":   export(newJoiner),
+		"backend": export(func([]js.Value) (any, error) {
+			return shamir.ActiveBackend().String(), nil
+		}),
+	}))
+	select {} // Keep the exported functions alive
+}
+
+// export wraps fn as a JavaScript function that returns fn's error as an
+// Error and recovers from panics on arguments of the wrong type.
+func export(fn func(args []js.Value) (any, error)) js.Func {
+	return js.FuncOf(func(_ js.Value, args []js.Value) (result any) {
+		defer func() {
+			if r := recover(); r != nil {
+				result = jsError.New(fmt.Sprint("shamir: ", r))
+			}
+		}()
+		v, err := fn(args)
+		if err != nil {
+			return jsError.New(err.Error())
+		}
+		return v
+	})
+}
+
+func split(args []js.Value) (any, error) {
+	if len(args) < 3 {
+		return nil, errors.New("shamir: split(secret, parts, threshold, options?)")
+	}
+	secret, err := bytesFromJS(args[0])
+	if err != nil {
+		return nil, err
+	}
+	opts := append(options(arg(args, 3)), shamir.WithParts(args[1].Int()), shamir.WithThreshold(args[2].Int()))
+	shares, err := shamir.SplitWithOptions(secret, opts...)
+	clear(secret)
+	if err != nil {
+		return nil, err
+	}
+	return sliceToJS(shares), nil
+}
+
+func combine(args []js.Value) (any, error) {
+	if len(args) < 1 {
+		return nil, errors.New("shamir: combine(shares, options?)")
+	}
+	shares, err := slicesFromJS(args[0])
+	if err != nil {
+		return nil, err
+	}
+	secret, err := shamir.CombineWithOptions(shares, options(arg(args, 1))...)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(secret)
+	return bytesToJS(secret), nil
+}
+
+func newSplitter(args []js.Value) (any, error) {
+	if len(args) < 2 {
+		return nil, errors.New("shamir: newSplitter(parts, threshold)")
+	}
+	s, err := newChunkSplitter(args[0].Int(), args[1].Int())
+	if err != nil {
+		return nil, err
+	}
+	return js.ValueOf(map[string]any{
+		"write": export(func(args []js.Value) (any, error) {
+			chunk, err := bytesFromJS(arg(args, 0))
+			if err != nil {
+				return nil, err
+			}
+			defer clear(chunk)
+			pieces, err := s.write(chunk)
+			if err != nil {
+				return nil, err
+			}
+			return sliceToJS(pieces), nil
+		}),
+	}), nil
+}
+
+func newJoiner([]js.Value) (any, error) {
+	j := &chunkJoiner{}
+	return js.ValueOf(map[string]any{
+		"write": export(func(args []js.Value) (any, error) {
+			pieces, err := slicesFromJS(arg(args, 0))
+			if err != nil {
+				return nil, err
+			}
+			secret, err := j.write(pieces)
+			if err != nil {
+				return nil, err
+			}
+			defer clear(secret)
+			return bytesToJS(secret), nil
+		}),
+	}), nil
+}
+
+// options converts a JavaScript options object.
+func options(v js.Value) []shamir.Option {
+	var opts []shamir.Option
+	if v.Type() != js.TypeObject {
+		return nil
+	}
+	if integrity := v.Get("integrity"); integrity.Truthy() {
+		opts = append(opts, shamir.WithIntegrity(true))
+	}
+	if purpose := v.Get("purpose"); purpose.Type() == js.TypeString {
+		opts = append(opts, shamir.WithPurpose(purpose.String()))
+	}
+	return opts
+}
+
+// arg returns args[i], or undefined if there are fewer arguments.
+func arg(args []js.Value, i int) js.Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return js.Undefined()
+}
+
+func bytesFromJS(v js.Value) ([]byte, error) {
+	if !v.InstanceOf(uint8Array) {
+		return nil, errors.New("shamir: expected a Uint8Array")
+	}
+	b := make([]byte, v.Length())
+	js.CopyBytesToGo(b, v)
+	return b, nil
+}
+
+func slicesFromJS(v js.Value) ([][]byte, error) {
+	if !js.Global().Get("Array").Call("isArray", v).Bool() {
+		return nil, errors.New("shamir: expected an array of Uint8Arrays")
+	}
+	out := make([][]byte, v.Length())
+	for i := range out {
+		b, err := bytesFromJS(v.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		out[i] = b
+	}
+	return out, nil
+}
+
+func bytesToJS(b []byte) js.Value {
+	v := uint8Array.New(len(b))
+	js.CopyBytesToJS(v, b)
+	return v
+}
+
+func sliceToJS(bs [][]byte) js.Value {
+	out := make([]any, len(bs))
+	for i, b := range bs {
+		out[i] = bytesToJS(b)
+	}
+	return js.ValueOf(out)
+}
@@ -0,0 +1,13 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Fprintln(os.Stderr, "shamir-wasm: build with GOOS=js GOARCH=wasm and load the module in a browser or Node.js")
+	os.Exit(2)
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// errUsage signals a flag error that has already been reported.
+var errUsage = errors.New("usage")
+
+// newFlagSet returns a flag set that reports errors to stderr instead of
+// exiting the process.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("shamir "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage // The flag package has already printed the problem
+	}
+	return nil
+}
+
+// readInput reads a whole file, or stdin if name is empty or "-".
+func readInput(name string, stdin io.Reader) ([]byte, error) {
+	if name == "" || name == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(name)
+}
+
+func runSplit(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("split", stderr)
+	parts := fs.Int("n", 0, "number of shares to create")
+	threshold := fs.Int("k", 0, "number of shares required to reconstruct")
+	in := fs.String("in", "", "file holding the secret (default stdin)")
+	outDir := fs.String("out-dir", "", "write each share to DIR/share-<i>.txt instead of stdout")
+	format := fs.String("format", formatHex, "share encoding: hex, base64 or mnemonic")
+	integrity := fs.Bool("integrity", false, "append a CRC32 checksum to each share")
+	stream := fs.Bool("stream", false, "split in chunks with bounded memory, writing binary DIR/share-<i>.shs files (requires -out-dir)")
+	labels := fs.String("labels", "", "comma-separated label for each share, e.g. the custodian holding it (emits enveloped shares)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "shamir split: unexpected argument %q\n", fs.Arg(0))
+		return errUsage
+	}
+
+	for _, f := range shamir.LintPolicy(*parts, *threshold, shamir.LintOptions{}) {
+		if f.Severity == shamir.LintWarning {
+			fmt.Fprintf(stderr, "shamir split: %s\n", f)
+		}
+	}
+
+	if *stream {
+		if *outDir == "" {
+			fmt.Fprintln(stderr, "shamir split: -stream requires -out-dir")
+			return errUsage
+		}
+		return splitStream(*in, stdin, stdout, *outDir, *parts, *threshold)
+	}
+
+	secret, err := readInput(*in, stdin)
+	if err != nil {
+		return err
+	}
+	defer wipe(secret)
+
+	var shares [][]byte
+	if *labels != "" {
+		shares, err = shamir.SplitWithOptions(secret,
+			shamir.WithParts(*parts),
+			shamir.WithThreshold(*threshold),
+			shamir.WithLabels(strings.Split(*labels, ",")...),
+		)
+	} else if *integrity {
+		shares, err = shamir.SplitWithIntegrity(secret, *parts, *threshold)
+	} else {
+		shares, err = shamir.Split(secret, *parts, *threshold)
+	}
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, len(shares))
+	for i, share := range shares {
+		if lines[i], err = encodeShare(share, *format); err != nil {
+			return err
+		}
+	}
+
+	if *outDir == "" {
+		for _, line := range lines {
+			fmt.Fprintln(stdout, line)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(*outDir, 0o700); err != nil {
+		return err
+	}
+	for i, line := range lines {
+		name := filepath.Join(*outDir, fmt.Sprintf("share-%d.txt", i+1))
+		if err := os.WriteFile(name, []byte(line+"\n"), 0o600); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, name)
+	}
+	return nil
+}
+
+func runCombine(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("combine", stderr)
+	format := fs.String("format", formatAuto, "share encoding: auto, hex, base64 or mnemonic")
+	integrity := fs.Bool("integrity", false, "verify and strip CRC32 checksums")
+	out := fs.String("out", "", "file to write the secret to (default stdout)")
+	stream := fs.Bool("stream", false, "combine share files written by split -stream")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *stream {
+		return combineStream(fs.Args(), stdout, *out)
+	}
+
+	shares, err := readShares(fs.Args(), stdin, *format)
+	if err != nil {
+		return err
+	}
+
+	secret, err := shamir.CombineWithOptions(shares, shamir.WithIntegrity(*integrity))
+	if err != nil {
+		return err
+	}
+	defer wipe(secret)
+
+	if *out == "" || *out == "-" {
+		_, err = stdout.Write(secret)
+		return err
+	}
+	return os.WriteFile(*out, secret, 0o600)
+}
+
+// splitStream implements split -stream: the secret is piped through
+// SplitStream into one file per share, never held in memory as a whole.
+func splitStream(in string, stdin io.Reader, stdout io.Writer, outDir string, parts, threshold int) error {
+	src := stdin
+	if in != "" && in != "-" {
+		f, err := os.Open(in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		src = f
+	}
+
+	if err := os.MkdirAll(outDir, 0o700); err != nil {
+		return err
+	}
+	files := make([]*os.File, 0, parts)
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	dsts := make([]io.Writer, 0, parts)
+	for i := 0; i < parts; i++ {
+		f, err := os.OpenFile(filepath.Join(outDir, fmt.Sprintf("share-%d.shs", i+1)), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+		dsts = append(dsts, f)
+	}
+
+	if err := shamir.SplitStream(src, dsts, threshold, 0); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, f.Name())
+	}
+	files = nil
+	return nil
+}
+
+// combineStream implements combine -stream.
+func combineStream(names []string, stdout io.Writer, out string) error {
+	srcs := make([]io.Reader, len(names))
+	for i, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		srcs[i] = f
+	}
+
+	if out == "" || out == "-" {
+		return shamir.CombineStream(stdout, srcs)
+	}
+	f, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := shamir.CombineStream(f, srcs); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runVerify(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("verify", stderr)
+	format := fs.String("format", formatAuto, "share encoding: auto, hex, base64 or mnemonic")
+	integrity := fs.Bool("integrity", false, "check CRC32 checksums added with split -integrity")
+	threshold := fs.Int("threshold", 0, "require at least this many shares")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	shares, err := readShares(fs.Args(), stdin, *format)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[byte]bool, len(shares))
+	for i, share := range shares {
+		if len(share) < 2 {
+			return fmt.Errorf("share %d: %w", i+1, shamir.ErrTooShort)
+		}
+		if len(share) != len(shares[0]) {
+			return fmt.Errorf("share %d: %w", i+1, shamir.ErrDifferentLengths)
+		}
+
+		index := share[0]
+		switch {
+		case shamir.IsEnvelope(share):
+			s, err := shamir.ParseShare(share)
+			if err != nil {
+				return fmt.Errorf("share %d: %w", i+1, err)
+			}
+			index = s.Index
+			if *threshold == 0 {
+				*threshold = s.Threshold
+			}
+		case *integrity:
+			if err := shamir.VerifyIntegrity(share); err != nil {
+				return fmt.Errorf("share %d: %w", i+1, err)
+			}
+		}
+		if index == 0 {
+			return fmt.Errorf("share %d: x-coordinate is zero", i+1)
+		}
+		if seen[index] {
+			return fmt.Errorf("share %d: %w", i+1, shamir.ErrDuplicatePart)
+		}
+		seen[index] = true
+	}
+
+	if len(shares) < *threshold {
+		return fmt.Errorf("%w: %d shares, threshold is %d", shamir.ErrInsufficientShares, len(shares), *threshold)
+	}
+	fmt.Fprintf(stdout, "ok: %d shares\n", len(shares))
+	return nil
+}
+
+func runInspect(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("inspect", stderr)
+	format := fs.String("format", formatAuto, "share encoding: auto, hex, base64 or mnemonic")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	shares, err := readShares(fs.Args(), stdin, *format)
+	if err != nil {
+		return err
+	}
+
+	for i, share := range shares {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintf(stdout, "share %d\n", i+1)
+		fmt.Fprintf(stdout, "  fingerprint: %s\n", shamir.FingerprintShare(share))
+		if !shamir.IsEnvelope(share) {
+			fmt.Fprintf(stdout, "  format:      raw\n")
+			fmt.Fprintf(stdout, "  length:      %d bytes\n", len(share))
+			if len(share) > 0 {
+				fmt.Fprintf(stdout, "  index:       %d\n", share[0])
+			}
+			continue
+		}
+
+		s, err := shamir.ParseShare(share)
+		if err != nil {
+			return fmt.Errorf("share %d: %w", i+1, err)
+		}
+		fmt.Fprintf(stdout, "  format:      envelope v%d\n", s.Version)
+		fmt.Fprintf(stdout, "  length:      %d bytes (%d byte payload)\n", len(share), len(s.Payload))
+		fmt.Fprintf(stdout, "  index:       %d\n", s.Index)
+		fmt.Fprintf(stdout, "  threshold:   %d\n", s.Threshold)
+		if s.Algorithm != "" {
+			fmt.Fprintf(stdout, "  algorithm:   %s\n", s.Algorithm)
+		}
+		if s.Purpose != "" {
+			fmt.Fprintf(stdout, "  purpose:     %s\n", s.Purpose)
+		}
+		if !s.SetID.IsZero() {
+			fmt.Fprintf(stdout, "  set id:      %s\n", s.SetID)
+		}
+		if s.Label != "" {
+			fmt.Fprintf(stdout, "  label:       %s\n", s.Label)
+		}
+		if !s.CreatedAt.IsZero() {
+			fmt.Fprintf(stdout, "  created:     %s\n", s.CreatedAt.Format(time.RFC3339))
+		}
+		if !s.NotBefore.IsZero() {
+			fmt.Fprintf(stdout, "  not before:  %s\n", s.NotBefore.Format(time.RFC3339))
+		}
+		if !s.NotAfter.IsZero() {
+			fmt.Fprintf(stdout, "  not after:   %s\n", s.NotAfter.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+func runLint(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("lint", stderr)
+	parts := fs.Int("n", 0, "number of shares")
+	threshold := fs.Int("k", 0, "number of shares required to reconstruct")
+	custodians := fs.String("custodians", "", "file listing one custodian per line: NAME [EXPIRY as RFC 3339]")
+	window := fs.Duration("window", 0, "also flag custodians expiring within this duration")
+	strict := fs.Bool("strict", false, "fail on warnings as well as errors")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	opts := shamir.LintOptions{ExpiryWindow: *window}
+	if *custodians != "" {
+		list, err := readCustodians(*custodians)
+		if err != nil {
+			return err
+		}
+		opts.Custodians = list
+	}
+
+	findings := shamir.LintPolicy(*parts, *threshold, opts)
+	failed := 0
+	for _, f := range findings {
+		fmt.Fprintln(stdout, f)
+		if f.Severity == shamir.LintError || *strict {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d finding(s)", failed)
+	}
+	if len(findings) == 0 {
+		fmt.Fprintln(stdout, "ok")
+	}
+	return nil
+}
+
+func runVersion(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("version", stderr)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	version := "(devel)"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		version = info.Main.Version
+	}
+	fmt.Fprintf(stdout, "go-shamir %s, %s\n", version, runtime.Version())
+	fmt.Fprintf(stdout, "backend: %s\n", shamir.ActiveBackend())
+	return nil
+}
+
+// readCustodians parses a custodian list for lint: one custodian per line, the
+// name optionally followed by an RFC 3339 expiry. Blank lines and lines
+// starting with # are skipped.
+func readCustodians(name string) ([]shamir.CustodianStatus, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []shamir.CustodianStatus
+	for i, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		c := shamir.CustodianStatus{Name: fields[0]}
+		switch len(fields) {
+		case 1:
+		case 2:
+			if c.Expires, err = time.Parse(time.RFC3339, fields[1]); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", name, i+1, err)
+			}
+		default:
+			return nil, fmt.Errorf("%s:%d: expected NAME [EXPIRY]", name, i+1)
+		}
+		list = append(list, c)
+	}
+	return list, nil
+}
+
+// wipe overwrites secret material before it is released.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/morizta/go-shamir/mnemonic"
+)
+
+// Share text formats.
+const (
+	formatAuto     = "auto"
+	formatHex      = "hex"
+	formatBase64   = "base64"
+	formatMnemonic = "mnemonic"
+)
+
+var hexPattern = regexp.MustCompile(`^(?:[0-9a-fA-F]{2})+$`)
+
+// encodeShare renders a share as text in the given format.
+func encodeShare(share []byte, format string) (string, error) {
+	switch format {
+	case formatHex:
+		return hex.EncodeToString(share), nil
+	case formatBase64:
+		return base64.StdEncoding.EncodeToString(share), nil
+	case formatMnemonic:
+		return mnemonic.EncodeString(share)
+	default:
+		return "", fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// decodeShare parses a share from text. With formatAuto, text containing
+// spaces is read as a mnemonic, hexadecimal digits as hex, and anything else
+// as base64.
+func decodeShare(text, format string) ([]byte, error) {
+	text = strings.TrimSpace(text)
+	if format == formatAuto {
+		switch {
+		case strings.ContainsAny(text, " \t"):
+			format = formatMnemonic
+		case hexPattern.MatchString(text):
+			format = formatHex
+		default:
+			format = formatBase64
+		}
+	}
+
+	switch format {
+	case formatHex:
+		return hex.DecodeString(text)
+	case formatBase64:
+		return base64.StdEncoding.DecodeString(text)
+	case formatMnemonic:
+		return mnemonic.DecodeString(text)
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+}
+
+// readShares reads one share per non-empty line from each file, or from stdin
+// if no files are given.
+func readShares(files []string, stdin io.Reader, format string) ([][]byte, error) {
+	var shares [][]byte
+	read := func(name string, r io.Reader) error {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 256<<20) // Shares of large secrets are long lines
+		line := 0
+		for scanner.Scan() {
+			line++
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			share, err := decodeShare(scanner.Text(), format)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", name, line, err)
+			}
+			shares = append(shares, share)
+		}
+		return scanner.Err()
+	}
+
+	if len(files) == 0 {
+		if err := read("stdin", stdin); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		err = read(name, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no shares found")
+	}
+	return shares, nil
+}
@@ -0,0 +1,79 @@
+// Command shamir splits and combines secrets with Shamir's Secret Sharing.
+//
+// Usage:
+//
+//	shamir split   -n 5 -k 3 [-in secret.bin] [-out-dir DIR] [-format hex|base64|mnemonic] [-integrity]
+//	shamir combine [-format auto|hex|base64|mnemonic] [-integrity] [-out secret.bin] [share files...]
+//	shamir verify  [-format ...] [-integrity] [-threshold K] [share files...]
+//	shamir inspect [-format ...] [share files...]
+//	shamir lint    -n 5 -k 3 [-custodians FILE] [-window 720h] [-strict]
+//	shamir version
+//
+// Secrets are read from -in or standard input. Shares are written one per line
+// to standard output, or one per file with -out-dir. Commands that read shares
+// take one or more files (one share per line) or read standard input. With
+// -stream, split and combine use the framed binary stream format instead, so
+// inputs of any size can be piped through with bounded memory:
+//
+//	tar c /data | shamir split -stream -n 5 -k 3 -out-dir shares/
+//	shamir combine -stream shares/share-1.shs shares/share-2.shs shares/share-4.shs | tar x
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage: shamir <command> [flags]
+
+Commands:
+  split    split a secret into shares
+  combine  reconstruct a secret from shares
+  verify   check that shares are well-formed and consistent
+  inspect  describe shares without reconstructing anything
+  lint     review a threshold policy for risky configurations
+  version  print the build and field arithmetic backend, for bug reports
+
+Run "shamir <command> -h" for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the process exit code:
+// 0 on success, 1 on failure, 2 on usage errors.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	commands := map[string]func([]string, io.Reader, io.Writer, io.Writer) error{
+		"split":   runSplit,
+		"combine": runCombine,
+		"verify":  runVerify,
+		"inspect": runInspect,
+		"lint":    runLint,
+		"version": runVersion,
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		if args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+			fmt.Fprint(stdout, usage)
+			return 0
+		}
+		fmt.Fprintf(stderr, "shamir: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if err := cmd(args[1:], stdin, stdout, stderr); err != nil {
+		if err == errUsage {
+			return 2
+		}
+		fmt.Fprintf(stderr, "shamir %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+func runCLI(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestSplitCombineFormats(t *testing.T) {
+	secret := "correct horse battery staple"
+	for _, format := range []string{formatHex, formatBase64, formatMnemonic} {
+		for _, integrity := range []bool{false, true} {
+			args := []string{"split", "-n", "5", "-k", "3", "-format", format}
+			if integrity {
+				args = append(args, "-integrity")
+			}
+			out, errOut, code := runCLI(t, secret, args...)
+			if code != 0 {
+				t.Fatalf("%s: split exited %d: %s", format, code, errOut)
+			}
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			if len(lines) != 5 {
+				t.Fatalf("%s: got %d shares, want 5", format, len(lines))
+			}
+
+			args = []string{"combine"}
+			if integrity {
+				args = append(args, "-integrity")
+			}
+			got, errOut, code := runCLI(t, strings.Join(lines[1:4], "\n"), args...)
+			if code != 0 {
+				t.Fatalf("%s: combine exited %d: %s", format, code, errOut)
+			}
+			if got != secret {
+				t.Errorf("%s: combine = %q, want %q", format, got, secret)
+			}
+		}
+	}
+}
+
+func TestSplitCombineFiles(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret.bin")
+	secret := []byte{0x00, 0xff, 0x10, 0x20, 0x7f}
+	if err := os.WriteFile(secretFile, secret, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sharesDir := filepath.Join(dir, "shares")
+	_, errOut, code := runCLI(t, "", "split", "-n", "3", "-k", "2", "-in", secretFile, "-out-dir", sharesDir)
+	if code != 0 {
+		t.Fatalf("split exited %d: %s", code, errOut)
+	}
+	info, err := os.Stat(filepath.Join(sharesDir, "share-1.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("share file mode = %o, want 600", perm)
+	}
+
+	outFile := filepath.Join(dir, "recovered.bin")
+	_, errOut, code = runCLI(t, "", "combine", "-out", outFile,
+		filepath.Join(sharesDir, "share-1.txt"), filepath.Join(sharesDir, "share-3.txt"))
+	if code != 0 {
+		t.Fatalf("combine exited %d: %s", code, errOut)
+	}
+	got, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("recovered %x, want %x", got, secret)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	out, _, _ := runCLI(t, "secret", "split", "-n", "3", "-k", "2", "-integrity")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+
+	if _, errOut, code := runCLI(t, out, "verify", "-integrity"); code != 0 {
+		t.Fatalf("verify exited %d: %s", code, errOut)
+	}
+
+	// Flip a payload bit in the first share
+	tampered := []byte(lines[0])
+	if tampered[3] == '0' {
+		tampered[3] = '1'
+	} else {
+		tampered[3] = '0'
+	}
+	input := string(tampered) + "\n" + lines[1]
+	if _, errOut, code := runCLI(t, input, "verify", "-integrity"); code != 1 {
+		t.Errorf("verify of tampered share exited %d, want 1", code)
+	} else if !strings.Contains(errOut, "integrity") {
+		t.Errorf("unexpected error output: %s", errOut)
+	}
+
+	duplicate := lines[0] + "\n" + lines[0]
+	if _, _, code := runCLI(t, duplicate, "verify"); code != 1 {
+		t.Errorf("verify of duplicate shares exited %d, want 1", code)
+	}
+
+	if _, _, code := runCLI(t, lines[0], "verify", "-threshold", "2"); code != 1 {
+		t.Errorf("verify below threshold exited %d, want 1", code)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	out, _, _ := runCLI(t, "secret", "split", "-n", "2", "-k", "2", "-format", formatBase64)
+	info, errOut, code := runCLI(t, out, "inspect")
+	if code != 0 {
+		t.Fatalf("inspect exited %d: %s", code, errOut)
+	}
+	for _, want := range []string{"share 1", "share 2", "format:      raw", "length:      7 bytes", "fingerprint:"} {
+		if !strings.Contains(info, want) {
+			t.Errorf("inspect output missing %q:\n%s", want, info)
+		}
+	}
+}
+
+func TestSplitLabels(t *testing.T) {
+	out, errOut, code := runCLI(t, "secret", "split", "-n", "2", "-k", "2", "-labels", "alice@ops,HSM-2")
+	if code != 0 {
+		t.Fatalf("split exited %d: %s", code, errOut)
+	}
+	info, errOut, code := runCLI(t, out, "inspect")
+	if code != 0 {
+		t.Fatalf("inspect exited %d: %s", code, errOut)
+	}
+	for _, want := range []string{"label:       alice@ops", "label:       HSM-2", "created:"} {
+		if !strings.Contains(info, want) {
+			t.Errorf("inspect output missing %q:\n%s", want, info)
+		}
+	}
+	if secret, errOut, code := runCLI(t, out, "combine"); code != 0 || secret != "secret" {
+		t.Fatalf("combine exited %d with %q: %s", code, secret, errOut)
+	}
+
+	if _, _, code := runCLI(t, "secret", "split", "-n", "3", "-k", "2", "-labels", "alice,bob"); code != 1 {
+		t.Errorf("split with too few labels exited %d, want 1", code)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no command", nil, 2},
+		{"unknown command", []string{"frobnicate"}, 2},
+		{"bad flag", []string{"split", "-bogus"}, 2},
+		{"help", []string{"help"}, 0},
+		{"invalid threshold", []string{"split", "-n", "2", "-k", "3"}, 1},
+		{"unknown format", []string{"split", "-n", "2", "-k", "2", "-format", "rot13"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, code := runCLI(t, "secret", tt.args...); code != tt.code {
+				t.Errorf("exit code = %d, want %d", code, tt.code)
+			}
+		})
+	}
+}
+
+func TestDecodeShareAuto(t *testing.T) {
+	share := []byte{0x01, 0xde, 0xad, 0xbe, 0xef}
+	for _, format := range []string{formatHex, formatBase64, formatMnemonic} {
+		text, err := encodeShare(share, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := decodeShare(text, formatAuto)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if !bytes.Equal(got, share) {
+			t.Errorf("%s: decoded %x, want %x", format, got, share)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	out, _, code := runCLI(t, "", "lint", "-n", "5", "-k", "3")
+	if code != 0 || strings.TrimSpace(out) != "ok" {
+		t.Errorf("sound policy: exit %d, output %q", code, out)
+	}
+
+	out, _, code = runCLI(t, "", "lint", "-n", "3", "-k", "3")
+	if code != 0 || !strings.Contains(out, "no-redundancy") {
+		t.Errorf("warning: exit %d, output %q", code, out)
+	}
+	if _, _, code := runCLI(t, "", "lint", "-n", "3", "-k", "3", "-strict"); code != 1 {
+		t.Errorf("strict warning: exit %d, want 1", code)
+	}
+
+	list := filepath.Join(t.TempDir(), "custodians.txt")
+	content := "# name expiry\nalice 2000-01-01T00:00:00Z\nbob 2000-01-01T00:00:00Z\ncarol\n"
+	if err := os.WriteFile(list, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out, _, code = runCLI(t, "", "lint", "-n", "3", "-k", "2", "-custodians", list)
+	if code != 1 || !strings.Contains(out, "expired-custodians") {
+		t.Errorf("expired custodians: exit %d, output %q", code, out)
+	}
+
+	_, errOut, code := runCLI(t, "secret", "split", "-n", "2", "-k", "2")
+	if code != 0 || !strings.Contains(errOut, "no-redundancy") {
+		t.Errorf("split should warn: exit %d, stderr %q", code, errOut)
+	}
+}
+
+func TestSplitCombineStreamFiles(t *testing.T) {
+	dir := t.TempDir()
+	secret := strings.Repeat("archive contents ", 5000)
+
+	_, errOut, code := runCLI(t, secret, "split", "-stream", "-n", "4", "-k", "3", "-out-dir", dir)
+	if code != 0 {
+		t.Fatalf("split exited %d: %s", code, errOut)
+	}
+
+	got, errOut, code := runCLI(t, "", "combine", "-stream",
+		filepath.Join(dir, "share-4.shs"), filepath.Join(dir, "share-1.shs"), filepath.Join(dir, "share-2.shs"))
+	if code != 0 {
+		t.Fatalf("combine exited %d: %s", code, errOut)
+	}
+	if got != secret {
+		t.Error("streamed combine did not reproduce the input")
+	}
+
+	if _, _, code := runCLI(t, "", "split", "-stream", "-n", "2", "-k", "2"); code != 2 {
+		t.Errorf("split -stream without -out-dir exited %d, want 2", code)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	out, errOut, code := runCLI(t, "", "version")
+	if code != 0 {
+		t.Fatalf("version exited %d: %s", code, errOut)
+	}
+	if !strings.Contains(out, "backend: "+shamir.ActiveBackend().Kernel+" kernel") {
+		t.Errorf("version output lacks the backend: %q", out)
+	}
+}
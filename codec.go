@@ -0,0 +1,154 @@
+package shamir
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Share encoding registry.
+//
+// DecodeShare, ParseShareText and Import recognise the binary envelope, PEM
+// and JSON encodings. Organisations that wrap shares in their own container
+// (a vendor HSM export, an internal ticketing attachment) can register a codec
+// for it, identified by a magic prefix, and every decoder in this package then
+// handles the wrapper transparently.
+
+// ShareCodec is a custom share encoding registered with RegisterShareCodec.
+type ShareCodec struct {
+	Name   string                             // Format name reported by Import, e.g. "acme-hsm"
+	Prefix []byte                             // Magic prefix identifying encoded data; must be non-empty
+	Decode func(data []byte) ([]byte, error)  // Returns the binary share (raw or enveloped)
+	Encode func(share []byte) ([]byte, error) // Optional; used by EncodeShare
+}
+
+var codecs struct {
+	sync.RWMutex
+	byPrefix []ShareCodec // Longest prefix first
+}
+
+// RegisterShareCodec makes a custom share encoding available to DecodeShare,
+// ParseShareText, EncodeShare and Import. Data starting with codec.Prefix is
+// decoded with codec.Decode before the built-in formats are considered; when
+// prefixes overlap, the longest match wins. It is meant to be called from an
+// init function and panics if the codec is incomplete or its name or prefix
+// is already taken.
+func RegisterShareCodec(codec ShareCodec) {
+	if codec.Name == "" || len(codec.Prefix) == 0 || codec.Decode == nil {
+		panic("shamir: share codec needs a name, a prefix and a decoder")
+	}
+	switch codec.Name {
+	case FormatEnvelope, FormatPEM, FormatJSON, FormatRaw:
+		panic("shamir: share codec name " + codec.Name + " is reserved")
+	}
+	codec.Prefix = append([]byte(nil), codec.Prefix...)
+
+	codecs.Lock()
+	defer codecs.Unlock()
+	for _, c := range codecs.byPrefix {
+		if c.Name == codec.Name || bytes.Equal(c.Prefix, codec.Prefix) {
+			panic("shamir: share codec " + codec.Name + " registered twice")
+		}
+	}
+	codecs.byPrefix = append(codecs.byPrefix, codec)
+	sort.SliceStable(codecs.byPrefix, func(i, j int) bool {
+		return len(codecs.byPrefix[i].Prefix) > len(codecs.byPrefix[j].Prefix)
+	})
+}
+
+// lookupCodec returns the registered codec whose prefix data starts with.
+func lookupCodec(data []byte) (ShareCodec, bool) {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	for _, c := range codecs.byPrefix {
+		if bytes.HasPrefix(data, c.Prefix) {
+			return c, true
+		}
+	}
+	return ShareCodec{}, false
+}
+
+// DecodeShare decodes a share in any recognised encoding (a registered codec,
+// a binary envelope, PEM or JSON) and returns the binary share. Data in none
+// of these encodings is taken to be a raw share from Split and returned as a
+// copy if it is well formed. The input is never modified.
+func DecodeShare(data []byte) ([]byte, error) {
+	format, share, err := decodeShare(data)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		if err := validateShare(data); err != nil {
+			return nil, err
+		}
+		share = append([]byte(nil), data...)
+	}
+	return share, nil
+}
+
+// ParseShareText decodes an enveloped share from its text form (PEM, JSON or
+// a registered text codec) and parses the envelope, as ParseShare does for
+// the binary form.
+func ParseShareText(text string) (*Share, error) {
+	share, err := DecodeShare([]byte(text))
+	if err != nil {
+		return nil, err
+	}
+	defer secureZeroBytes(share)
+	return ParseShare(share)
+}
+
+// EncodeShare encodes a binary share with the registered codec called name.
+func EncodeShare(name string, share []byte) ([]byte, error) {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	for _, c := range codecs.byPrefix {
+		if c.Name == name {
+			if c.Encode == nil {
+				return nil, fmt.Errorf("shamir: share codec %s cannot encode", name)
+			}
+			return c.Encode(share)
+		}
+	}
+	return nil, fmt.Errorf("shamir: unknown share codec %q", name)
+}
+
+// decodeShare detects the encoding of data and returns the format name and a
+// private copy of the binary share. The format is "" if data is in no known
+// encoding, in which case it may be a raw share.
+func decodeShare(data []byte) (string, []byte, error) {
+	if c, ok := lookupCodec(data); ok {
+		share, err := c.Decode(data)
+		if err != nil {
+			return "", nil, fmt.Errorf("shamir: %s share: %w", c.Name, err)
+		}
+		return c.Name, append([]byte(nil), share...), nil
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case IsEnvelope(data):
+		return FormatEnvelope, append([]byte(nil), data...), nil
+
+	case bytes.HasPrefix(trimmed, []byte("-----BEGIN ")):
+		share, _, err := DecodeSharePEM(trimmed)
+		if err != nil {
+			return "", nil, err
+		}
+		return FormatPEM, append([]byte(nil), share...), nil
+
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var s Share
+		if err := s.UnmarshalJSON(trimmed); err != nil {
+			return "", nil, err
+		}
+		share, err := s.MarshalBinary()
+		secureZeroBytes(s.Payload)
+		if err != nil {
+			return "", nil, err
+		}
+		return FormatJSON, share, nil
+	}
+	return "", nil, nil
+}
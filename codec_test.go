@@ -0,0 +1,121 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// acmePrefix marks the test codec: "ACME-SHARE:" followed by base64.
+const acmePrefix = "ACME-SHARE:"
+
+func init() {
+	RegisterShareCodec(ShareCodec{
+		Name:   "acme",
+		Prefix: []byte(acmePrefix),
+		Decode: func(data []byte) ([]byte, error) {
+			return base64.StdEncoding.DecodeString(strings.TrimSpace(string(data[len(acmePrefix):])))
+		},
+		Encode: func(share []byte) ([]byte, error) {
+			return []byte(acmePrefix + base64.StdEncoding.EncodeToString(share)), nil
+		},
+	})
+}
+
+func TestShareCodecRegistry(t *testing.T) {
+	shares, err := SplitWithOptions([]byte("org wrapped"), WithParts(3), WithThreshold(2), WithLabels("a", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrapped, err := EncodeShare("acme", shares[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeShare(wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, shares[0]) {
+		t.Fatal("DecodeShare did not unwrap the registered codec")
+	}
+
+	s, err := ParseShareText(string(wrapped) + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Label != "a" {
+		t.Fatalf("ParseShareText: Label = %q", s.Label)
+	}
+
+	q, err := Import(wrapped, DefaultImportLimits())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Format != "acme" || q.Label != "a" {
+		t.Fatalf("Import: format %q, label %q", q.Format, q.Label)
+	}
+
+	t.Run("decode errors are attributed", func(t *testing.T) {
+		if _, err := DecodeShare([]byte(acmePrefix + "!!!")); err == nil || !strings.Contains(err.Error(), "acme") {
+			t.Fatalf("expected an acme decode error, got %v", err)
+		}
+	})
+
+	t.Run("unknown codec", func(t *testing.T) {
+		if _, err := EncodeShare("nope", shares[0]); err == nil {
+			t.Fatal("expected an error for an unknown codec")
+		}
+	})
+
+	t.Run("invalid registrations", func(t *testing.T) {
+		for name, codec := range map[string]ShareCodec{
+			"duplicate": {Name: "acme", Prefix: []byte("X"), Decode: DecodeShare},
+			"prefix":    {Name: "other", Prefix: []byte(acmePrefix), Decode: DecodeShare},
+			"reserved":  {Name: FormatPEM, Prefix: []byte("Y"), Decode: DecodeShare},
+			"no prefix": {Name: "empty", Decode: DecodeShare},
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s: expected a panic", name)
+					}
+				}()
+				RegisterShareCodec(codec)
+			}()
+		}
+	})
+}
+
+func TestDecodeShareBuiltins(t *testing.T) {
+	shares, err := SplitWithOptions([]byte("builtin"), WithParts(2), WithThreshold(2), WithLabels("a", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemShare, _ := EncodeSharePEM(shares[0])
+	parsed, _ := ParseShare(shares[0])
+	jsonShare, _ := parsed.MarshalJSON()
+	raw, _ := Split([]byte("builtin"), 2, 2)
+
+	for name, tt := range map[string]struct{ in, want []byte }{
+		"envelope": {shares[0], shares[0]},
+		"pem":      {pemShare, shares[0]},
+		"json":     {jsonShare, shares[0]},
+		"raw":      {raw[0], raw[0]},
+	} {
+		got, err := DecodeShare(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: decoded share mismatch", name)
+		}
+	}
+
+	if _, err := ParseShareText(string(raw[0])); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("ParseShareText of a raw share: expected ErrInvalidEnvelope, got %v", err)
+	}
+}
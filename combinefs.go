@@ -0,0 +1,99 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+)
+
+// maxShareFileSize bounds the files CombineFS reads, so a glob that matches a
+// disk image or a log on a mounted volume fails instead of exhausting memory.
+const maxShareFileSize = 64 << 20
+
+// CombineFS loads every file in fsys matching the fs.Glob pattern glob as one
+// share and combines them with CombineWithOptions and the given options. It is
+// meant for recovery scripts that read shares from mounted media:
+//
+//	secret, err := shamir.CombineFS(os.DirFS("/media"), "*/share-*.txt")
+//
+// Each file may hold a share in any encoding DecodeShare recognises (a binary
+// envelope, PEM, JSON or a registered codec), a raw share in hexadecimal or
+// base64 text, or a raw binary share. Directories are skipped, and files
+// with identical contents count once, so a share backed up to two sticks does
+// not fail the combine as a duplicate. Errors name the offending file.
+func CombineFS(fsys fs.FS, glob string, opts ...Option) ([]byte, error) {
+	names, err := fs.Glob(fsys, glob)
+	if err != nil {
+		return nil, err
+	}
+
+	var shares [][]byte
+	defer func() {
+		for _, share := range shares {
+			secureZeroBytes(share)
+		}
+	}()
+	for _, name := range names {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		if info.Size() > maxShareFileSize {
+			return nil, fmt.Errorf("%s: %w: %d bytes exceeds limit of %d", name, ErrShareRejected, info.Size(), maxShareFileSize)
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		share, err := decodeShareFile(data)
+		secureZeroBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if containsShare(shares, share) {
+			secureZeroBytes(share)
+			continue
+		}
+		shares = append(shares, share)
+	}
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("%w: no share files match %q", ErrTooFewParts, glob)
+	}
+
+	return CombineWithOptions(shares, opts...)
+}
+
+// decodeShareFile decodes the contents of a share file and returns a private
+// copy of the binary share.
+func decodeShareFile(data []byte) ([]byte, error) {
+	format, share, err := decodeShare(data)
+	if err != nil || format != "" {
+		return share, err
+	}
+
+	// Hex and base64 text wrap a binary share, enveloped or raw.
+	text := string(bytes.TrimSpace(data))
+	decoded, err := hex.DecodeString(text)
+	if err != nil {
+		if decoded, err = base64.StdEncoding.DecodeString(text); err != nil {
+			return DecodeShare(data)
+		}
+	}
+	defer secureZeroBytes(decoded)
+	return DecodeShare(decoded)
+}
+
+// containsShare reports whether shares holds a share equal to share.
+func containsShare(shares [][]byte, share []byte) bool {
+	for _, s := range shares {
+		if bytes.Equal(s, share) {
+			return true
+		}
+	}
+	return false
+}
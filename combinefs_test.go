@@ -0,0 +1,86 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCombineFS(t *testing.T) {
+	secret := []byte("disaster recovery key")
+	shares, err := SplitWithOptions(secret, WithParts(5), WithThreshold(3), WithPurpose("backup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemShare, err := EncodeSharePEM(shares[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := fstest.MapFS{
+		"usb1/share-a.txt": {Data: []byte(hex.EncodeToString(shares[0]) + "\n")},
+		"usb2/share-b.txt": {Data: pemShare},
+		"usb3/share-c.txt": {Data: []byte(base64.StdEncoding.EncodeToString(shares[2]))},
+		"usb4/share-c.txt": {Data: []byte(base64.StdEncoding.EncodeToString(shares[2]))}, // Second copy
+		"usb4/share-d.txt": {Data: shares[3]},
+		"usb4/share-e.txt": {Mode: fs.ModeDir | 0o755}, // Directory matching the glob
+		"usb4/notes.md":    {Data: []byte("not a share")},
+	}
+	got, err := CombineFS(fsys, "*/share-*.txt", WithPurpose("backup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+}
+
+func TestCombineFSRaw(t *testing.T) {
+	secret := []byte("raw shares")
+	shares, err := SplitWithIntegrity(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"a.share": {Data: []byte(hex.EncodeToString(shares[0]))},
+		"b.share": {Data: shares[2]},
+	}
+	got, err := CombineFS(fsys, "*.share", WithIntegrity(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+}
+
+func TestCombineFSErrors(t *testing.T) {
+	first, _ := SplitWithOptions([]byte("first"), WithParts(3), WithThreshold(2), WithPurpose("p"))
+	second, _ := SplitWithOptions([]byte("other"), WithParts(3), WithThreshold(2), WithPurpose("p"))
+
+	if _, err := CombineFS(fstest.MapFS{}, "*.txt"); !errors.Is(err, ErrTooFewParts) {
+		t.Fatalf("no files: expected ErrTooFewParts, got %v", err)
+	}
+	if _, err := CombineFS(fstest.MapFS{}, "[", WithPurpose("p")); err == nil {
+		t.Fatal("bad pattern: expected an error")
+	}
+
+	mixed := fstest.MapFS{"a.txt": {Data: first[0]}, "b.txt": {Data: second[1]}}
+	if _, err := CombineFS(mixed, "*.txt", WithPurpose("p")); !errors.Is(err, ErrMismatchedShares) {
+		t.Fatalf("mixed splits: expected ErrMismatchedShares, got %v", err)
+	}
+
+	short := fstest.MapFS{"a.txt": {Data: first[0]}, "b.txt": {Data: []byte("a")}}
+	if _, err := CombineFS(short, "*.txt", WithPurpose("p")); !errors.Is(err, ErrTooShort) {
+		t.Fatalf("short file: expected ErrTooShort, got %v", err)
+	}
+
+	large := fstest.MapFS{"a.txt": {Data: first[0]}, "b.txt": {Data: make([]byte, maxShareFileSize+1)}}
+	if _, err := CombineFS(large, "*.txt", WithPurpose("p")); !errors.Is(err, ErrShareRejected) {
+		t.Fatalf("large file: expected ErrShareRejected, got %v", err)
+	}
+}
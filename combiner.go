@@ -0,0 +1,61 @@
+package shamir
+
+// Combiner reconstructs secrets from shares taken at one fixed set of
+// x-coordinates. The Lagrange weights, an O(k²) computation, depend only on
+// that set, so a Combiner computes them once in NewCombiner and every Combine
+// call is a single weighted sum over the payloads. Use it when the same
+// custodians restore repeatedly, or to join the chunks of a stream split.
+//
+// A Combiner holds no secret material and is safe for concurrent use.
+type Combiner struct {
+	xCoords []byte
+	weights [256]byte // Lagrange weight at x = 0, indexed by x-coordinate
+	member  [256]bool
+}
+
+// NewCombiner precomputes the Lagrange weights for shares evaluated at
+// xCoords, which must be 2-255 distinct, non-zero x-coordinates (the first
+// bytes of the shares).
+func NewCombiner(xCoords []byte) (*Combiner, error) {
+	sorted, err := normalizeQuorum(xCoords)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Combiner{xCoords: sorted}
+	for i, w := range lagrangeBasis(sorted, 0) {
+		c.weights[sorted[i]] = w
+		c.member[sorted[i]] = true
+	}
+	return c, nil
+}
+
+// XCoordinates returns the x-coordinates the Combiner was built for, ascending.
+func (c *Combiner) XCoordinates() []byte {
+	return append([]byte(nil), c.xCoords...)
+}
+
+// Combine reconstructs the secret from raw shares, in any order, whose
+// x-coordinates are exactly the Combiner's set.
+func (c *Combiner) Combine(parts [][]byte) ([]byte, error) {
+	if err := validateCombineParams(parts); err != nil {
+		return nil, err
+	}
+	if len(parts) != len(c.xCoords) {
+		return nil, NewValidationError("shares", len(parts), "shamir: share count does not match the combiner's x-coordinates")
+	}
+
+	weights := make([]byte, len(parts))
+	for i, part := range parts {
+		if !c.member[part[0]] {
+			return nil, NewValidationError("share", i, "shamir: share x-coordinate is not in the combiner's set")
+		}
+		weights[i] = c.weights[part[0]]
+	}
+
+	secretLen := len(parts[0]) - ShareOverhead
+	secret := allocSecret(secretLen)
+	_, workers := engine{}.resolve(secretLen)
+	interpolateRange(secret, parts, weights, 0, secretLen, workers, gfMulAddSlice)
+	return secret, nil
+}
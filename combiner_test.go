@@ -0,0 +1,106 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCombiner(t *testing.T) {
+	c, err := NewCombiner([]byte{5, 2, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xs := c.XCoordinates(); !bytes.Equal(xs, []byte{2, 4, 5}) {
+		t.Fatalf("XCoordinates = %v, want [2 4 5]", xs)
+	}
+
+	// The same combiner serves many secrets split at the same points.
+	for _, size := range []int{1, 32, 5000} {
+		secret := make([]byte, size)
+		for i := range secret {
+			secret[i] = byte(i*7 + size)
+		}
+		shares, err := Split(secret, 5, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, quorum := range [][][]byte{{shares[1], shares[3], shares[4]}, {shares[4], shares[1], shares[3]}} {
+			got, err := c.Combine(quorum)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, secret) {
+				t.Fatalf("size %d: Combine = %x, want %x", size, got, secret)
+			}
+		}
+	}
+}
+
+func TestCombinerRejectsOtherSets(t *testing.T) {
+	c, err := NewCombiner([]byte{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, err := Split([]byte("secret"), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var verr *ValidationError
+	if _, err := c.Combine(shares[1:4]); !errors.As(err, &verr) {
+		t.Errorf("foreign x-coordinate: got %v, want ValidationError", err)
+	}
+	if _, err := c.Combine(shares[:2]); !errors.As(err, &verr) {
+		t.Errorf("subset: got %v, want ValidationError", err)
+	}
+	if _, err := c.Combine(shares[:4]); !errors.As(err, &verr) {
+		t.Errorf("superset: got %v, want ValidationError", err)
+	}
+	if _, err := c.Combine([][]byte{shares[0], shares[1], shares[1]}); !errors.As(err, &verr) {
+		t.Errorf("duplicate share: got %v, want ValidationError", err)
+	}
+}
+
+func TestNewCombinerValidation(t *testing.T) {
+	for _, xs := range [][]byte{nil, {1}, {0, 1}, {3, 3}} {
+		if _, err := NewCombiner(xs); err == nil {
+			t.Errorf("NewCombiner(%v) succeeded", xs)
+		}
+	}
+}
+
+// BenchmarkCombiner compares a reused Combiner against Combine for a quorum
+// large enough that computing the weights is noticeable.
+func BenchmarkCombiner(b *testing.B) {
+	secret := make([]byte, 64)
+	shares, err := Split(secret, 64, 32)
+	if err != nil {
+		b.Fatal(err)
+	}
+	quorum := shares[:32]
+	xs := make([]byte, len(quorum))
+	for i, s := range quorum {
+		xs[i] = s[0]
+	}
+
+	b.Run("Combine", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := Combine(quorum); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Combiner", func(b *testing.B) {
+		c, err := NewCombiner(xs)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := c.Combine(quorum); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
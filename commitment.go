@@ -0,0 +1,76 @@
+package shamir
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"io"
+)
+
+// Secret commitments.
+//
+// Interpolation returns a value for any set of shares at least as large as
+// the threshold, and a share whose payload was altered together with its
+// checksum, or a mix-up the split metadata does not reveal, reconstructs a
+// wrong secret without any error. A split can record a salted SHA-256
+// commitment to the secret in every share's envelope, and every combine of
+// enveloped shares then checks the reconstructed value against it before
+// returning it.
+//
+// Commitment record (tag 9): a 16-byte salt followed by
+// SHA-256(commitmentPrefix ‖ salt ‖ secret).
+
+const (
+	commitmentPrefix   = "go-shamir secret commitment v1"
+	commitmentSaltSize = 16
+	commitmentSize     = commitmentSaltSize + sha256.Size
+)
+
+// WithCommitment records a salted SHA-256 commitment to the secret in every
+// share's envelope, so that combining fails with ErrReconstructionMismatch
+// instead of returning a wrong secret. SplitWithOptions emits enveloped shares
+// when it is set, and the other option-taking splits that emit envelopes
+// record it too.
+//
+// Like a purpose binding, the commitment lets the holder of a single share
+// test guesses of the secret offline; only commit to high-entropy secrets
+// such as keys.
+func WithCommitment(commit bool) Option {
+	return func(o *options) { o.commitment = commit }
+}
+
+// newCommitment commits to secret under a fresh salt read from rng.
+func newCommitment(secret []byte, rng io.Reader) ([]byte, error) {
+	salt := make([]byte, commitmentSaltSize, commitmentSize)
+	if _, err := io.ReadFull(rng, salt); err != nil {
+		return nil, fmt.Errorf("shamir: failed to generate commitment salt: %w", err)
+	}
+	return commitTo(salt, secret), nil
+}
+
+// commitTo appends the commitment hash of secret to salt.
+func commitTo(salt, secret []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(commitmentPrefix))
+	h.Write(salt)
+	h.Write(secret)
+	return h.Sum(salt)
+}
+
+// checkCommitments checks a reconstructed secret against the commitment of
+// every share that records one.
+func checkCommitments(shares []*Share, secret []byte) error {
+	for i, s := range shares {
+		if s.commitment == nil {
+			continue
+		}
+		if len(s.commitment) != commitmentSize {
+			return fmt.Errorf("share %d: %w: malformed commitment", i, ErrReconstructionMismatch)
+		}
+		expected := commitTo(s.commitment[:commitmentSaltSize:commitmentSaltSize], secret)
+		if subtle.ConstantTimeCompare(expected, s.commitment) != 1 {
+			return fmt.Errorf("share %d: %w", i, ErrReconstructionMismatch)
+		}
+	}
+	return nil
+}
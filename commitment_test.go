@@ -0,0 +1,86 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCommitment(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x42}, 32)
+	shares, err := SplitWithOptions(secret, WithParts(4), WithThreshold(3), WithCommitment(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEnvelope(shares[0]) {
+		t.Fatal("WithCommitment did not envelope the shares")
+	}
+	if got, err := Combine(shares[1:]); err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("combine: %v", err)
+	}
+
+	// Corrupt a payload and recompute the checksum, as a careless repair or a
+	// deliberate edit would: only the commitment notices.
+	s, err := ParseShare(shares[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Payload[3] ^= 0x10
+	edited, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, combine := range map[string]func([][]byte) ([]byte, error){
+		"Combine":            Combine,
+		"CombineWithOptions": func(p [][]byte) ([]byte, error) { return CombineWithOptions(p) },
+	} {
+		if _, err := combine([][]byte{edited, shares[1], shares[2]}); !errors.Is(err, ErrReconstructionMismatch) {
+			t.Errorf("%s: expected ErrReconstructionMismatch, got %v", name, err)
+		}
+	}
+
+	// Stripping the commitment from one share is a mismatch too.
+	s, _ = ParseShare(shares[0])
+	s.commitment = nil
+	stripped, _ := s.MarshalBinary()
+	if _, err := Combine([][]byte{stripped, shares[1], shares[2]}); !errors.Is(err, ErrMismatchedShares) {
+		t.Errorf("stripped commitment: expected ErrMismatchedShares, got %v", err)
+	}
+}
+
+func TestCommitmentEncoding(t *testing.T) {
+	plain, err := SplitWithOptions([]byte("k"), WithParts(2), WithThreshold(2), WithLabels("a", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	committed, err := SplitWithOptions([]byte("k"), WithParts(2), WithThreshold(2), WithLabels("a", "b"), WithCommitment(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grown := len(committed[0]) - len(plain[0]); grown != 3+commitmentSize {
+		t.Errorf("commitment added %d bytes, want %d", grown, 3+commitmentSize)
+	}
+
+	var s Share
+	if err := s.UnmarshalBinary(committed[0]); err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Share
+	if err := decoded.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.commitment, s.commitment) {
+		t.Error("commitment lost in JSON round trip")
+	}
+
+	// Each split commits under a fresh salt.
+	again, _ := SplitWithOptions([]byte("k"), WithParts(2), WithThreshold(2), WithCommitment(true))
+	other, _ := ParseShare(again[0])
+	if bytes.Equal(other.commitment, s.commitment) {
+		t.Error("equal secrets produced equal commitments")
+	}
+}
@@ -0,0 +1,318 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Compact text encodings familiar from cryptocurrency custody: Bech32m
+// (BIP-350) and Base58Check. Both carry a checksum, so a mistyped share is
+// reported as a TranscriptionError before its contents are even looked at,
+// while a share that decodes cleanly but is malformed fails with the usual
+// share errors (ErrTooShort, ErrInvalidEnvelope, ...).
+
+const (
+	bech32Charset   = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32mConst    = 0x2bc830a3
+	bech32Checksum  = 6
+	bech32MaxHRPLen = 83
+
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	base58Checksum = 4
+)
+
+// TranscriptionError reports a Bech32 or Base58Check share that was copied
+// wrongly: a character outside the alphabet, or a checksum that does not
+// match. It matches ErrTranscription with errors.Is.
+type TranscriptionError struct {
+	Encoding string // "bech32" or "base58check"
+	Position int    // Offset of the offending character, or -1 for a checksum mismatch
+	Reason   string
+}
+
+func (e *TranscriptionError) Error() string {
+	if e.Position < 0 {
+		return fmt.Sprintf("shamir: %s share mistyped: %s", e.Encoding, e.Reason)
+	}
+	return fmt.Sprintf("shamir: %s share mistyped at character %d: %s", e.Encoding, e.Position+1, e.Reason)
+}
+
+// Unwrap returns ErrTranscription.
+func (e *TranscriptionError) Unwrap() error {
+	return ErrTranscription
+}
+
+// EncodeShareBech32 encodes a raw or enveloped share as a Bech32m string with
+// the human-readable prefix hrp, such as "share1qy...". The prefix is
+// lowercased and must be 1 to 83 printable ASCII characters.
+//
+// BIP-173 limits Bech32 strings to 90 characters; shares are usually longer,
+// so the limit is not applied. The checksum still detects any error with
+// probability 1 - 2^-30, but guarantees detection of up to four errors only
+// within the first 89 characters.
+func EncodeShareBech32(hrp string, share []byte) (string, error) {
+	hrp = strings.ToLower(hrp)
+	if len(hrp) == 0 || len(hrp) > bech32MaxHRPLen {
+		return "", NewValidationError("hrp", len(hrp), "shamir: Bech32 prefix must be 1 to 83 characters")
+	}
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", NewValidationError("hrp", int(hrp[i]), "shamir: Bech32 prefix must be printable ASCII")
+		}
+	}
+	if err := checkShare(share); err != nil {
+		return "", err
+	}
+
+	data := convertBits(share, 8, 5)
+	defer secureZeroBytes(data)
+	checksum := bech32Polymod(hrp, data, make([]byte, bech32Checksum)) ^ bech32mConst
+
+	var b strings.Builder
+	b.Grow(len(hrp) + 1 + len(data) + bech32Checksum)
+	b.WriteString(hrp)
+	b.WriteByte('1')
+	for _, d := range data {
+		b.WriteByte(bech32Charset[d])
+	}
+	for i := range bech32Checksum {
+		b.WriteByte(bech32Charset[(checksum>>(5*(bech32Checksum-1-i)))&31])
+	}
+	return b.String(), nil
+}
+
+// DecodeShareBech32 decodes a share encoded by EncodeShareBech32 and returns
+// its prefix, lowercased, along with the share. Surrounding whitespace is
+// ignored and the string may be all upper case, as when typed from a QR code
+// in alphanumeric mode. Typing mistakes fail with a *TranscriptionError.
+func DecodeShareBech32(s string) (hrp string, share []byte, err error) {
+	s = strings.TrimSpace(s)
+	mistyped := func(pos int, reason string, args ...any) error {
+		return &TranscriptionError{Encoding: "bech32", Position: pos, Reason: fmt.Sprintf(reason, args...)}
+	}
+
+	lower, upper := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c < 33 || c > 126:
+			return "", nil, mistyped(i, "invalid character %q", c)
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		}
+		if lower && upper {
+			return "", nil, mistyped(i, "mixed upper and lower case")
+		}
+	}
+	s = strings.ToLower(s)
+
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep > bech32MaxHRPLen {
+		return "", nil, mistyped(-1, "missing prefix or separator")
+	}
+	if len(s)-sep-1 < bech32Checksum {
+		return "", nil, mistyped(-1, "too short")
+	}
+	hrp = s[:sep]
+	data := make([]byte, len(s)-sep-1)
+	defer secureZeroBytes(data)
+	for i := range data {
+		d := strings.IndexByte(bech32Charset, s[sep+1+i])
+		if d < 0 {
+			return "", nil, mistyped(sep+1+i, "invalid character %q", s[sep+1+i])
+		}
+		data[i] = byte(d)
+	}
+	if bech32Polymod(hrp, data, nil) != bech32mConst {
+		return "", nil, mistyped(-1, "checksum mismatch")
+	}
+
+	payload := data[:len(data)-bech32Checksum]
+	if pad := len(payload) * 5 % 8; pad >= 5 || (pad > 0 && payload[len(payload)-1]&(1<<pad-1) != 0) {
+		return "", nil, fmt.Errorf("%w: bech32 share has invalid padding", ErrInvalidEnvelope)
+	}
+	share = convertBits(payload, 5, 8)
+	if err := checkShare(share); err != nil {
+		secureZeroBytes(share)
+		return "", nil, err
+	}
+	return hrp, share, nil
+}
+
+// EncodeShareBase58Check encodes a raw or enveloped share in Base58Check, the
+// share followed by the first four bytes of its double SHA-256, in the
+// Bitcoin alphabet.
+func EncodeShareBase58Check(share []byte) (string, error) {
+	if err := checkShare(share); err != nil {
+		return "", err
+	}
+	payload := make([]byte, 0, len(share)+base58Checksum)
+	payload = append(payload, share...)
+	payload = append(payload, base58CheckSum(share)...)
+	defer secureZeroBytes(payload)
+	return base58Encode(payload), nil
+}
+
+// DecodeShareBase58Check decodes a share encoded by EncodeShareBase58Check.
+// Surrounding whitespace is ignored. Typing mistakes fail with a
+// *TranscriptionError.
+func DecodeShareBase58Check(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	payload, bad := base58Decode(s)
+	defer secureZeroBytes(payload)
+	if bad >= 0 {
+		return nil, &TranscriptionError{Encoding: "base58check", Position: bad, Reason: fmt.Sprintf("invalid character %q", s[bad])}
+	}
+	if len(payload) < base58Checksum {
+		return nil, &TranscriptionError{Encoding: "base58check", Position: -1, Reason: "too short"}
+	}
+	body := payload[:len(payload)-base58Checksum]
+	if !bytes.Equal(base58CheckSum(body), payload[len(body):]) {
+		return nil, &TranscriptionError{Encoding: "base58check", Position: -1, Reason: "checksum mismatch"}
+	}
+	share := append([]byte(nil), body...)
+	if err := checkShare(share); err != nil {
+		secureZeroBytes(share)
+		return nil, err
+	}
+	return share, nil
+}
+
+// checkShare verifies that share is a well-formed raw or enveloped share.
+func checkShare(share []byte) error {
+	if IsEnvelope(share) {
+		s, err := ParseShare(share)
+		if err != nil {
+			return err
+		}
+		secureZeroBytes(s.Payload)
+		return nil
+	}
+	return validateShare(share)
+}
+
+// bech32Polymod computes the BIP-173 checksum polynomial over the expanded
+// prefix, data and tail.
+func bech32Polymod(hrp string, data, tail []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	step := func(v byte) {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := range gen {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	for i := 0; i < len(hrp); i++ {
+		step(hrp[i] >> 5)
+	}
+	step(0)
+	for i := 0; i < len(hrp); i++ {
+		step(hrp[i] & 31)
+	}
+	for _, v := range data {
+		step(v)
+	}
+	for _, v := range tail {
+		step(v)
+	}
+	return chk
+}
+
+// convertBits regroups data from groups of from bits into groups of to bits,
+// most significant first. Leftover bits are zero padded when splitting bytes
+// into 5-bit groups and dropped when joining 5-bit groups back into bytes.
+func convertBits(data []byte, from, to uint) []byte {
+	out := make([]byte, 0, (len(data)*int(from)+int(to)-1)/int(to))
+	var acc uint32
+	var bits uint
+	for _, v := range data {
+		acc = acc<<from | uint32(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits)&(1<<to-1))
+		}
+	}
+	if bits > 0 && to < from {
+		out = append(out, byte(acc<<(to-bits))&(1<<to-1))
+	}
+	return out
+}
+
+// base58CheckSum returns the first four bytes of the double SHA-256 of data.
+func base58CheckSum(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:base58Checksum]
+}
+
+// base58Encode encodes data in the Bitcoin Base58 alphabet, each leading zero
+// byte as a '1'.
+func base58Encode(data []byte) string {
+	// Repeated division by 58 of data read as a big-endian number; digits
+	// are kept least significant first.
+	digits := make([]byte, 0, len(data)*138/100+1)
+	defer secureZeroBytes(digits)
+	for _, b := range data {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+
+	var b strings.Builder
+	b.Grow(len(digits) + len(data))
+	for i := 0; i < len(data) && data[i] == 0; i++ {
+		b.WriteByte(base58Alphabet[0])
+	}
+	for i := len(digits) - 1; i >= 0; i-- {
+		b.WriteByte(base58Alphabet[digits[i]])
+	}
+	return b.String()
+}
+
+// base58Decode reverses base58Encode. It returns the position of the first
+// character outside the alphabet, or -1 if there is none.
+func base58Decode(s string) ([]byte, int) {
+	// Repeated multiplication by 58, least significant byte first.
+	out := make([]byte, 0, len(s)*733/1000+1)
+	zeros := 0
+	for i := 0; i < len(s); i++ {
+		d := strings.IndexByte(base58Alphabet, s[i])
+		if d < 0 {
+			return out, i
+		}
+		if d == 0 && zeros == i {
+			zeros++
+		}
+		carry := d
+		for j := range out {
+			carry += int(out[j]) * 58
+			out[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			out = append(out, byte(carry))
+			carry >>= 8
+		}
+	}
+	for range zeros {
+		out = append(out, 0)
+	}
+	slices.Reverse(out)
+	return out, -1
+}
@@ -0,0 +1,191 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompactEncodingsRoundTrip(t *testing.T) {
+	raw, err := Split([]byte("cold storage"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enveloped, err := SplitKey(bytes.Repeat([]byte{7}, 32), AlgAES256GCM, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, shares := range map[string][][]byte{"raw": raw, "envelope": enveloped} {
+		t.Run(name, func(t *testing.T) {
+			for i, share := range shares {
+				b32, err := EncodeShareBech32("Share", share)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.HasPrefix(b32, "share1") || strings.ToLower(b32) != b32 {
+					t.Fatalf("unexpected Bech32 encoding %q", b32)
+				}
+				hrp, got, err := DecodeShareBech32(" " + strings.ToUpper(b32) + "\n")
+				if err != nil || hrp != "share" || !bytes.Equal(got, share) {
+					t.Fatalf("share %d: Bech32 round trip: %q, %v", i, hrp, err)
+				}
+
+				b58, err := EncodeShareBase58Check(share)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err = DecodeShareBase58Check(b58)
+				if err != nil || !bytes.Equal(got, share) {
+					t.Fatalf("share %d: Base58Check round trip: %v", i, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCompactEncodingsTypos(t *testing.T) {
+	shares, err := SplitWithOptions([]byte("typed by hand"), WithParts(3), WithThreshold(2), WithLabels("a", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b32, _ := EncodeShareBech32("share", shares[0])
+	b58, _ := EncodeShareBase58Check(shares[0])
+
+	// Swap one character for another in the same alphabet.
+	substitute := func(s string, pos int, alphabet string) string {
+		c := alphabet[(strings.IndexByte(alphabet, s[pos])+1)%len(alphabet)]
+		return s[:pos] + string(c) + s[pos+1:]
+	}
+	// Swap the first two differing neighbours from pos on.
+	transpose := func(s string, pos int) string {
+		for s[pos] == s[pos+1] {
+			pos++
+		}
+		return s[:pos] + s[pos+1:pos+2] + s[pos:pos+1] + s[pos+2:]
+	}
+
+	tests := []struct {
+		name     string
+		decode   func(string) error
+		input    string
+		position int
+	}{
+		{"bech32 substitution", decodeBech32Err, substitute(b32, 20, bech32Charset), -1},
+		{"bech32 transposition", decodeBech32Err, transpose(b32, 10), -1},
+		{"bech32 bad character", decodeBech32Err, b32[:15] + "b" + b32[16:], 15},
+		{"bech32 mixed case", decodeBech32Err, "S" + b32[1:], 1},
+		{"bech32 no separator", decodeBech32Err, "qpzry9x8gf2tvdw0", -1},
+		{"base58 substitution", decodeBase58Err, substitute(b58, 20, base58Alphabet), -1},
+		{"base58 bad character", decodeBase58Err, b58[:5] + "0" + b58[6:], 5},
+		{"base58 truncated", decodeBase58Err, b58[:len(b58)-1], -1},
+	}
+	for _, tt := range tests {
+		err := tt.decode(tt.input)
+		var terr *TranscriptionError
+		if !errors.As(err, &terr) || !errors.Is(err, ErrTranscription) {
+			t.Errorf("%s: expected a TranscriptionError, got %v", tt.name, err)
+			continue
+		}
+		if terr.Position != tt.position {
+			t.Errorf("%s: position %d, want %d (%v)", tt.name, terr.Position, tt.position, err)
+		}
+	}
+}
+
+func decodeBech32Err(s string) error {
+	_, _, err := DecodeShareBech32(s)
+	return err
+}
+
+func decodeBase58Err(s string) error {
+	_, err := DecodeShareBase58Check(s)
+	return err
+}
+
+func TestCompactEncodingsInvalidShares(t *testing.T) {
+	// Correctly encoded, so not a typo, but not a share either.
+	notShares := map[string][]byte{
+		"too short":    {1},
+		"zero index":   {0, 1, 2},
+		"bad envelope": {0x00, 'S', 'E', 1, 2, 3},
+	}
+	for name, data := range notShares {
+		b32 := bech32String("share", data)
+		_, _, err := DecodeShareBech32(b32)
+		if err == nil || errors.Is(err, ErrTranscription) {
+			t.Errorf("bech32 %s: expected a share error, got %v", name, err)
+		}
+		b58 := base58Encode(append(append([]byte(nil), data...), base58CheckSum(data)...))
+		_, err = DecodeShareBase58Check(b58)
+		if err == nil || errors.Is(err, ErrTranscription) {
+			t.Errorf("base58 %s: expected a share error, got %v", name, err)
+		}
+
+		if _, err := EncodeShareBech32("share", data); err == nil {
+			t.Errorf("EncodeShareBech32 accepted %s", name)
+		}
+		if _, err := EncodeShareBase58Check(data); err == nil {
+			t.Errorf("EncodeShareBase58Check accepted %s", name)
+		}
+	}
+
+	var verr *ValidationError
+	for _, hrp := range []string{"", "bad prefix", strings.Repeat("x", 84)} {
+		if _, err := EncodeShareBech32(hrp, []byte{1, 2}); !errors.As(err, &verr) || verr.Field != "hrp" {
+			t.Errorf("prefix %q: expected a ValidationError, got %v", hrp, err)
+		}
+	}
+}
+
+// bech32String encodes arbitrary data, bypassing the share checks.
+func bech32String(hrp string, data []byte) string {
+	values := convertBits(data, 8, 5)
+	checksum := bech32Polymod(hrp, values, make([]byte, bech32Checksum)) ^ bech32mConst
+	s := hrp + "1"
+	for _, v := range values {
+		s += string(bech32Charset[v])
+	}
+	for i := range bech32Checksum {
+		s += string(bech32Charset[(checksum>>(5*(bech32Checksum-1-i)))&31])
+	}
+	return s
+}
+
+func TestBech32mVectors(t *testing.T) {
+	// Valid Bech32m strings from BIP-350.
+	for _, s := range []string{
+		"a1lqfn3a",
+		"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx",
+		"split1checkupstagehandshakeupstreamerranterredcaperredlc445v",
+		"?1v759aa",
+	} {
+		sep := strings.LastIndexByte(s, '1')
+		var values []byte
+		for _, c := range s[sep+1:] {
+			values = append(values, byte(strings.IndexRune(bech32Charset, c)))
+		}
+		if bech32Polymod(s[:sep], values, nil) != bech32mConst {
+			t.Errorf("%s: checksum does not verify", s)
+		}
+	}
+}
+
+func TestBase58CheckVector(t *testing.T) {
+	// The genesis block's coinbase address: version 0 and a HASH160.
+	const address = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	payload, _ := hex.DecodeString("0062e907b15cbf27d5425399ebf6f0fb50ebb88f18")
+
+	decoded, bad := base58Decode(address)
+	if bad >= 0 || !bytes.Equal(decoded[:len(payload)], payload) || !bytes.Equal(decoded[len(payload):], base58CheckSum(payload)) {
+		t.Fatalf("decoded %x, bad character at %d", decoded, bad)
+	}
+	if got := base58Encode(decoded); got != address {
+		t.Errorf("encoded %s, want %s", got, address)
+	}
+	if got, _ := base58Decode("111"); !bytes.Equal(got, []byte{0, 0, 0}) {
+		t.Errorf("leading zeros: got %x", got)
+	}
+}
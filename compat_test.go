@@ -0,0 +1,254 @@
+package shamir_test
+
+import (
+	"bytes"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+	"github.com/morizta/go-shamir/mnemonic"
+)
+
+// The compatibility corpus holds shares produced by each released format
+// version. Every directory under testdata/compat is one release and is never
+// modified; the current decoders must parse and combine all of them.
+//
+//go:embed testdata/compat
+var compatCorpus embed.FS
+
+var compatGenerate = flag.String("compat.generate", "",
+	"write a new corpus for the named release (e.g. v1) into testdata/compat and exit")
+
+// compatCase is one entry of a release's cases.json.
+type compatCase struct {
+	Name      string   `json:"name"`
+	Format    string   `json:"format"`
+	Secret    string   `json:"secret"` // Hex
+	Threshold int      `json:"threshold"`
+	Key       string   `json:"key,omitempty"` // Hex, for authenticated shares
+	Algorithm string   `json:"algorithm,omitempty"`
+	Purpose   string   `json:"purpose,omitempty"`
+	Files     []string `json:"files"`
+}
+
+func TestCompatCorpus(t *testing.T) {
+	if *compatGenerate != "" {
+		generateCompatCorpus(t, *compatGenerate)
+		return
+	}
+
+	releases, err := fs.ReadDir(compatCorpus, "testdata/compat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) == 0 {
+		t.Fatal("compatibility corpus is empty")
+	}
+
+	for _, release := range releases {
+		dir := path.Join("testdata/compat", release.Name())
+		data, err := compatCorpus.ReadFile(path.Join(dir, "cases.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cases []compatCase
+		if err := json.Unmarshal(data, &cases); err != nil {
+			t.Fatalf("%s: %v", release.Name(), err)
+		}
+
+		for _, c := range cases {
+			t.Run(release.Name()+"/"+c.Name, func(t *testing.T) {
+				shares, err := loadCompatShares(dir, c)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, _ := hex.DecodeString(c.Secret)
+
+				// Any quorum must reconstruct, not just the first one
+				for _, quorum := range [][][]byte{shares[:c.Threshold], shares[len(shares)-c.Threshold:]} {
+					got, err := combineCompat(c, quorum)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !bytes.Equal(got, want) {
+						t.Fatalf("reconstructed %x, want %x", got, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+// loadCompatShares decodes the shares of a case from the corpus.
+func loadCompatShares(dir string, c compatCase) ([][]byte, error) {
+	var shares [][]byte
+	for _, name := range c.Files {
+		data, err := compatCorpus.ReadFile(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		switch c.Format {
+		case "pem":
+			for len(bytes.TrimSpace(data)) > 0 {
+				share, rest, err := shamir.DecodeSharePEM(data)
+				if err != nil {
+					return nil, err
+				}
+				shares, data = append(shares, share), rest
+			}
+		case "json":
+			decoded, err := shamir.DecodeSharesJSON(data)
+			if err != nil {
+				return nil, err
+			}
+			shares = append(shares, decoded...)
+		case "mnemonic":
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				share, err := mnemonic.DecodeString(line)
+				if err != nil {
+					return nil, err
+				}
+				shares = append(shares, share)
+			}
+		default:
+			shares = append(shares, data)
+		}
+	}
+	return shares, nil
+}
+
+// combineCompat reconstructs a case's secret with the matching combine function.
+func combineCompat(c compatCase, parts [][]byte) ([]byte, error) {
+	switch c.Format {
+	case "raw", "mnemonic":
+		return shamir.Combine(parts)
+	case "integrity":
+		return shamir.CombineWithIntegrity(parts)
+	case "authenticated":
+		key, _ := hex.DecodeString(c.Key)
+		return shamir.CombineAuthenticated(parts, key)
+	case "key", "pem", "json":
+		return shamir.CombineKey(parts, c.Algorithm)
+	case "purpose":
+		return shamir.CombineWithOptions(parts, shamir.WithPurpose(c.Purpose), shamir.WithStrictPurpose(true))
+	case "vault":
+		return shamir.CombineVault(parts)
+	default:
+		return nil, fmt.Errorf("unknown corpus format %q", c.Format)
+	}
+}
+
+// generateCompatCorpus writes shares in every format for a new release. It
+// refuses to touch an existing release directory.
+func generateCompatCorpus(t *testing.T, release string) {
+	dir := filepath.Join("testdata", "compat", release)
+	if _, err := os.Stat(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("%s already exists; released corpora are never regenerated", dir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	secret := []byte("go-shamir compatibility corpus")
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	var cases []compatCase
+
+	write := func(name string, data []byte) string {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+	addBinary := func(c compatCase, shares [][]byte, err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, share := range shares {
+			c.Files = append(c.Files, write(fmt.Sprintf("%s-%d.bin", c.Name, i+1), share))
+		}
+		cases = append(cases, c)
+	}
+	base := func(name, format string, secret []byte) compatCase {
+		return compatCase{Name: name, Format: format, Secret: hex.EncodeToString(secret), Threshold: 3}
+	}
+
+	shares, err := shamir.Split(secret, 5, 3)
+	addBinary(base("raw", "raw", secret), shares, err)
+
+	shares, err = shamir.SplitWithIntegrity(secret, 5, 3)
+	addBinary(base("integrity", "integrity", secret), shares, err)
+
+	c := base("authenticated", "authenticated", secret)
+	c.Key = hex.EncodeToString(key)
+	shares, err = shamir.SplitAuthenticated(secret, 5, 3, key)
+	addBinary(c, shares, err)
+
+	c = base("key", "key", key)
+	c.Algorithm = shamir.AlgAES256GCM
+	keyShares, err := shamir.SplitKey(key, shamir.AlgAES256GCM, 5, 3)
+	addBinary(c, keyShares, err)
+
+	c = base("purpose", "purpose", secret)
+	c.Purpose = "compatibility"
+	shares, err = shamir.SplitWithOptions(secret, shamir.WithParts(5), shamir.WithThreshold(3), shamir.WithPurpose(c.Purpose))
+	addBinary(c, shares, err)
+
+	shares, err = shamir.SplitVault(secret, 5, 3)
+	addBinary(base("vault", "vault", secret), shares, err)
+
+	c = base("pem", "pem", key)
+	c.Algorithm = shamir.AlgAES256GCM
+	var pemData []byte
+	for _, share := range keyShares {
+		block, err := shamir.EncodeSharePEM(share)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pemData = append(pemData, block...)
+	}
+	c.Files = []string{write("pem.pem", pemData)}
+	cases = append(cases, c)
+
+	c = base("json", "json", key)
+	c.Algorithm = shamir.AlgAES256GCM
+	jsonData, err := shamir.EncodeSharesJSON(keyShares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Files = []string{write("json.json", jsonData)}
+	cases = append(cases, c)
+
+	shares, err = shamir.Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, share := range shares {
+		phrase, err := mnemonic.EncodeString(share)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, phrase)
+	}
+	c = base("mnemonic", "mnemonic", secret)
+	c.Files = []string{write("mnemonic.txt", []byte(strings.Join(lines, "\n")+"\n"))}
+	cases = append(cases, c)
+
+	index, err := json.MarshalIndent(cases, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	write("cases.json", append(index, '\n'))
+	t.Logf("wrote %d cases to %s", len(cases), dir)
+}
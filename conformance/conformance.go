@@ -0,0 +1,282 @@
+// Package conformance publishes a machine-readable test suite for the share
+// formats and semantics of the parent package, so that ports to other
+// languages can certify that they split and combine exactly as it does.
+//
+// The suite is a single JSON document (see SuiteJSON) listing cases. Each case
+// names an operation, its inputs and either the expected output or the code of
+// the expected error. Byte strings are hex encoded. The operations are:
+//
+//	split                  Split/SplitWithOptions: secret, parts, threshold,
+//	                       optional x_coordinates and integrity, with the
+//	                       polynomial coefficients drawn from the suite RNG
+//	                       keyed by rng_seed; expects shares
+//	split-deterministic    SplitDeterministic with seed; expects shares
+//	combine                Combine; expects secret
+//	combine-integrity      CombineWithIntegrity; expects secret
+//	combine-authenticated  CombineAuthenticated with key; expects secret
+//	combine-vault          CombineVault; expects secret
+//
+// The suite RNG is SHA-256 in counter mode: its output is the concatenation
+// of SHA-256(rng_seed || uint32be(i)) for i = 0, 1, 2, ... A split draws
+// threshold-1 rows of len(secret) bytes from it, the coefficients of degree 1
+// first, and the coefficient of degree d for secret byte j is byte j of row d.
+// A port reproduces the expected shares only if it consumes randomness in the
+// same order.
+//
+// Error codes are listed by ErrorCode. A port passes a case that expects an
+// error if it fails with an error it classifies under the same code.
+//
+// Enveloped formats (SplitKey, purpose binding, labels) record the creation
+// time and a random set ID, so they are covered by the combine cases of the
+// compatibility corpus in the parent package rather than here.
+//
+// Alongside the suite, the package exports property checks that any backend
+// must pass whatever its field or arithmetic (see RunProperties and Scheme).
+// A backend test is usually just:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.RunProperties(t, conformance.Funcs{
+//			SplitFunc:   mybackend.Split,
+//			CombineFunc: mybackend.Combine,
+//		}, conformance.Config{})
+//	}
+package conformance
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// Operations understood by Case.Run.
+const (
+	OpSplit                = "split"
+	OpSplitDeterministic   = "split-deterministic"
+	OpCombine              = "combine"
+	OpCombineIntegrity     = "combine-integrity"
+	OpCombineAuthenticated = "combine-authenticated"
+	OpCombineVault         = "combine-vault"
+)
+
+//go:embed suite.json
+var suiteJSON []byte
+
+// Suite is the conformance suite.
+type Suite struct {
+	Version int    `json:"version"`
+	Cases   []Case `json:"cases"`
+}
+
+// Case is one conformance test. Byte-string fields are hex encoded.
+type Case struct {
+	Name         string   `json:"name"`
+	Op           string   `json:"op"`
+	Secret       string   `json:"secret,omitempty"` // Input of splits, expected output of combines
+	Parts        int      `json:"parts,omitempty"`
+	Threshold    int      `json:"threshold,omitempty"`
+	XCoordinates []int    `json:"x_coordinates,omitempty"`
+	Integrity    bool     `json:"integrity,omitempty"`
+	RNGSeed      string   `json:"rng_seed,omitempty"` // Suite RNG key for split
+	Seed         string   `json:"seed,omitempty"`     // SplitDeterministic seed
+	Key          string   `json:"key,omitempty"`      // CombineAuthenticated key
+	Shares       []string `json:"shares,omitempty"`   // Output of splits, input of combines
+	Error        string   `json:"error,omitempty"`    // Expected error code
+}
+
+// SuiteJSON returns a copy of the suite document, for publishing to ports.
+func SuiteJSON() []byte {
+	return bytes.Clone(suiteJSON)
+}
+
+// Load parses the embedded suite.
+func Load() (*Suite, error) {
+	var s Suite
+	if err := json.Unmarshal(suiteJSON, &s); err != nil {
+		return nil, fmt.Errorf("conformance: invalid suite: %w", err)
+	}
+	return &s, nil
+}
+
+// Run executes c against the parent package and returns nil if the result
+// matches the expectation.
+func (c *Case) Run() error {
+	secret, err := hex.DecodeString(c.Secret)
+	if err != nil {
+		return fmt.Errorf("conformance: %s: invalid secret: %w", c.Name, err)
+	}
+	shares := make([][]byte, len(c.Shares))
+	for i, s := range c.Shares {
+		if shares[i], err = hex.DecodeString(s); err != nil {
+			return fmt.Errorf("conformance: %s: invalid share %d: %w", c.Name, i, err)
+		}
+	}
+
+	var got [][]byte
+	switch c.Op {
+	case OpSplit, OpSplitDeterministic:
+		got, err = c.split(secret)
+		if c.Error == "" && err == nil && !equalShares(got, shares) {
+			return fmt.Errorf("conformance: %s: shares %x, want %x", c.Name, got, shares)
+		}
+	case OpCombine, OpCombineIntegrity, OpCombineAuthenticated, OpCombineVault:
+		var out []byte
+		out, err = c.combine(shares)
+		if c.Error == "" && err == nil && !bytes.Equal(out, secret) {
+			return fmt.Errorf("conformance: %s: secret %x, want %x", c.Name, out, secret)
+		}
+	default:
+		return fmt.Errorf("conformance: %s: unknown operation %q", c.Name, c.Op)
+	}
+
+	if code := ErrorCode(err); code != c.Error {
+		if c.Error == "" {
+			return fmt.Errorf("conformance: %s: unexpected error: %w", c.Name, err)
+		}
+		return fmt.Errorf("conformance: %s: got error code %q (%v), want %q", c.Name, code, err, c.Error)
+	}
+	return nil
+}
+
+// split runs a split case.
+func (c *Case) split(secret []byte) ([][]byte, error) {
+	if c.Op == OpSplitDeterministic {
+		seed, err := hex.DecodeString(c.Seed)
+		if err != nil {
+			return nil, fmt.Errorf("conformance: %s: invalid seed: %w", c.Name, err)
+		}
+		return shamir.SplitDeterministic(secret, c.Parts, c.Threshold, seed)
+	}
+
+	rngSeed, err := hex.DecodeString(c.RNGSeed)
+	if err != nil {
+		return nil, fmt.Errorf("conformance: %s: invalid rng_seed: %w", c.Name, err)
+	}
+	opts := []shamir.Option{
+		shamir.WithParts(c.Parts),
+		shamir.WithThreshold(c.Threshold),
+		shamir.WithIntegrity(c.Integrity),
+		shamir.WithRand(NewRand(rngSeed)),
+		// The streaming strategy draws coefficients window by window.
+		shamir.WithStrategy(shamir.StrategyScalar),
+	}
+	if c.XCoordinates != nil {
+		xs := make([]byte, len(c.XCoordinates))
+		for i, x := range c.XCoordinates {
+			xs[i] = byte(x)
+		}
+		opts = append(opts, shamir.WithXCoordinates(xs...))
+	}
+	return shamir.SplitWithOptions(secret, opts...)
+}
+
+// combine runs a combine case.
+func (c *Case) combine(shares [][]byte) ([]byte, error) {
+	switch c.Op {
+	case OpCombineIntegrity:
+		return shamir.CombineWithIntegrity(shares)
+	case OpCombineAuthenticated:
+		key, err := hex.DecodeString(c.Key)
+		if err != nil {
+			return nil, fmt.Errorf("conformance: %s: invalid key: %w", c.Name, err)
+		}
+		return shamir.CombineAuthenticated(shares, key)
+	case OpCombineVault:
+		return shamir.CombineVault(shares)
+	default:
+		return shamir.Combine(shares)
+	}
+}
+
+func equalShares(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrorCode classifies err under the suite's portable error codes:
+//
+//	empty-secret, invalid-parts, invalid-threshold, invalid-x-coordinate,
+//	too-few-parts, too-short, different-lengths, duplicate-part,
+//	integrity-check-failed, authentication-failed, format-mismatch
+//
+// It returns "" for a nil error and "other" for errors outside the suite.
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var verr *shamir.ValidationError
+	if errors.As(err, &verr) {
+		switch verr.Field {
+		case "parts":
+			return "invalid-parts"
+		case "threshold":
+			return "invalid-threshold"
+		case "x-coordinate":
+			return "invalid-x-coordinate"
+		case "share":
+			return "duplicate-part"
+		}
+	}
+
+	for _, e := range []struct {
+		err  error
+		code string
+	}{
+		{shamir.ErrEmptySecret, "empty-secret"},
+		{shamir.ErrTooFewParts, "too-few-parts"},
+		{shamir.ErrTooShort, "too-short"},
+		{shamir.ErrDifferentLengths, "different-lengths"},
+		{shamir.ErrDuplicatePart, "duplicate-part"},
+		{shamir.ErrIntegrityCheckFailed, "integrity-check-failed"},
+		{shamir.ErrAuthenticationFailed, "authentication-failed"},
+		{shamir.ErrFormatMismatch, "format-mismatch"},
+	} {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+	return "other"
+}
+
+// NewRand returns the suite RNG keyed by seed: SHA-256 in counter mode, as
+// described in the package documentation. It never fails and is not a
+// cryptographically sound generator for real splits.
+func NewRand(seed []byte) io.Reader {
+	return &counterRand{seed: bytes.Clone(seed)}
+}
+
+type counterRand struct {
+	seed    []byte
+	counter uint32
+	buf     []byte
+}
+
+func (r *counterRand) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.buf) == 0 {
+			block := sha256.Sum256(binary.BigEndian.AppendUint32(bytes.Clone(r.seed), r.counter))
+			r.buf = block[:]
+			r.counter++
+		}
+		copied := copy(p[n:], r.buf)
+		r.buf = r.buf[copied:]
+		n += copied
+	}
+	return n, nil
+}
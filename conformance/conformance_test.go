@@ -0,0 +1,249 @@
+package conformance_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+	"github.com/morizta/go-shamir/conformance"
+)
+
+var generate = flag.Bool("conformance.generate", false,
+	"write suite.json from the case definitions below and exit; refuses to overwrite a published suite")
+
+func TestSuite(t *testing.T) {
+	if *generate {
+		generateSuite(t)
+		return
+	}
+
+	suite, err := conformance.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suite.Cases) == 0 {
+		t.Fatal("conformance suite is empty")
+	}
+	seen := make(map[string]bool)
+	for _, c := range suite.Cases {
+		if seen[c.Name] {
+			t.Fatalf("duplicate case name %q", c.Name)
+		}
+		seen[c.Name] = true
+		t.Run(c.Name, func(t *testing.T) {
+			if err := c.Run(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRunDetectsMismatch(t *testing.T) {
+	suite, err := conformance.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range suite.Cases {
+		if c.Error != "" {
+			continue
+		}
+		c.Error = "empty-secret"
+		if err := c.Run(); err == nil {
+			t.Errorf("%s: Run passed with the wrong expected error", c.Name)
+		}
+	}
+
+	tampered := suite.Cases[0]
+	tampered.Shares = append([]string(nil), tampered.Shares...)
+	tampered.Shares[0] = "ff" + tampered.Shares[0][2:]
+	if err := tampered.Run(); err == nil {
+		t.Errorf("%s: Run passed with tampered shares", tampered.Name)
+	}
+}
+
+func TestNewRand(t *testing.T) {
+	// The first block is SHA-256(seed || 00000000), the second SHA-256(seed || 00000001).
+	seed := []byte("seed")
+	got := make([]byte, 40)
+	r := conformance.NewRand(seed)
+	for i := 0; i < len(got); i += 7 {
+		// Odd-sized reads straddle the block boundary.
+		if _, err := r.Read(got[i:min(i+7, len(got))]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h0 := sha256Hex(append(bytes.Clone(seed), 0, 0, 0, 0))
+	h1 := sha256Hex(append(bytes.Clone(seed), 0, 0, 0, 1))
+	if want := h0 + h1[:16]; hex.EncodeToString(got) != want {
+		t.Fatalf("NewRand = %x, want %s", got, want)
+	}
+}
+
+func TestSuiteJSONIsCopy(t *testing.T) {
+	data := conformance.SuiteJSON()
+	data[0] = 'x'
+	if _, err := conformance.Load(); err != nil {
+		t.Fatalf("modifying SuiteJSON's result changed the suite: %v", err)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	if code := conformance.ErrorCode(nil); code != "" {
+		t.Errorf("ErrorCode(nil) = %q", code)
+	}
+	if code := conformance.ErrorCode(errors.New("boom")); code != "other" {
+		t.Errorf("ErrorCode(unrelated) = %q, want other", code)
+	}
+	_, err := shamir.Split(nil, 3, 2)
+	if code := conformance.ErrorCode(err); code != "empty-secret" {
+		t.Errorf("ErrorCode(%v) = %q, want empty-secret", err, code)
+	}
+}
+
+// generateSuite computes the expected results of the case definitions with
+// this package and writes suite.json.
+func generateSuite(t *testing.T) {
+	if suite, err := conformance.Load(); err == nil && len(suite.Cases) > 0 {
+		t.Fatal("suite.json is published and never regenerated; add cases with a new suite version")
+	}
+
+	secret := []byte("go-shamir conformance")
+	key := bytes.Repeat([]byte{0x5a}, 32)
+	hexs := hex.EncodeToString
+	encode := func(shares [][]byte) []string {
+		out := make([]string, len(shares))
+		for i, s := range shares {
+			out[i] = hexs(s)
+		}
+		return out
+	}
+	rng := func(name string) string { return hexs([]byte(name)) }
+	must := func(shares [][]byte, err error) [][]byte {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return shares
+	}
+
+	cases := []conformance.Case{
+		{Name: "split/2-of-3", Op: conformance.OpSplit, Secret: hexs(secret), Parts: 3, Threshold: 2, RNGSeed: rng("split/2-of-3")},
+		{Name: "split/3-of-5", Op: conformance.OpSplit, Secret: hexs(secret), Parts: 5, Threshold: 3, RNGSeed: rng("split/3-of-5")},
+		{Name: "split/5-of-5", Op: conformance.OpSplit, Secret: hexs(secret), Parts: 5, Threshold: 5, RNGSeed: rng("split/5-of-5")},
+		{Name: "split/single-byte", Op: conformance.OpSplit, Secret: "00", Parts: 4, Threshold: 2, RNGSeed: rng("split/single-byte")},
+		{Name: "split/all-bytes", Op: conformance.OpSplit, Secret: hexs(allBytes()), Parts: 4, Threshold: 3, RNGSeed: rng("split/all-bytes")},
+		{Name: "split/255-parts", Op: conformance.OpSplit, Secret: "c0ffee", Parts: 255, Threshold: 2, RNGSeed: rng("split/255-parts")},
+		{Name: "split/x-coordinates", Op: conformance.OpSplit, Secret: hexs(secret), Threshold: 2, XCoordinates: []int{7, 200, 255}, RNGSeed: rng("split/x-coordinates")},
+		{Name: "split/integrity", Op: conformance.OpSplit, Secret: hexs(secret), Parts: 3, Threshold: 2, Integrity: true, RNGSeed: rng("split/integrity")},
+		{Name: "split/empty-secret", Op: conformance.OpSplit, Parts: 3, Threshold: 2, RNGSeed: rng("x"), Error: "empty-secret"},
+		{Name: "split/one-part", Op: conformance.OpSplit, Secret: "01", Parts: 1, Threshold: 1, RNGSeed: rng("x"), Error: "invalid-parts"},
+		{Name: "split/256-parts", Op: conformance.OpSplit, Secret: "01", Parts: 256, Threshold: 2, RNGSeed: rng("x"), Error: "invalid-parts"},
+		{Name: "split/threshold-1", Op: conformance.OpSplit, Secret: "01", Parts: 3, Threshold: 1, RNGSeed: rng("x"), Error: "invalid-threshold"},
+		{Name: "split/threshold-above-parts", Op: conformance.OpSplit, Secret: "01", Parts: 3, Threshold: 4, RNGSeed: rng("x"), Error: "invalid-threshold"},
+		{Name: "split/zero-x-coordinate", Op: conformance.OpSplit, Secret: "01", Threshold: 2, XCoordinates: []int{0, 1}, RNGSeed: rng("x"), Error: "invalid-x-coordinate"},
+		{Name: "split/duplicate-x-coordinate", Op: conformance.OpSplit, Secret: "01", Threshold: 2, XCoordinates: []int{3, 3}, RNGSeed: rng("x"), Error: "invalid-x-coordinate"},
+		{Name: "split-deterministic/3-of-5", Op: conformance.OpSplitDeterministic, Secret: hexs(secret), Parts: 5, Threshold: 3, Seed: hexs(bytes.Repeat([]byte{1}, 32))},
+		{Name: "split-deterministic/2-of-2", Op: conformance.OpSplitDeterministic, Secret: "ff", Parts: 2, Threshold: 2, Seed: hexs(bytes.Repeat([]byte{2}, 16))},
+	}
+
+	for i := range cases {
+		if cases[i].Error == "" {
+			cases[i].Shares = encode(splitLikeRun(t, cases[i]))
+		}
+	}
+
+	raw := must(shamir.SplitWithOptions(secret, shamir.WithParts(5), shamir.WithThreshold(3),
+		shamir.WithRand(conformance.NewRand([]byte("combine"))), shamir.WithStrategy(shamir.StrategyScalar)))
+	integrity := must(shamir.SplitWithOptions(secret, shamir.WithParts(3), shamir.WithThreshold(2), shamir.WithIntegrity(true),
+		shamir.WithRand(conformance.NewRand([]byte("combine-integrity"))), shamir.WithStrategy(shamir.StrategyScalar)))
+	authenticated := must(shamir.SplitAuthenticated(secret, 3, 2, key))
+	vault := must(shamir.SplitVault(secret, 3, 2))
+
+	corrupt := func(share []byte, i int) []byte {
+		share = bytes.Clone(share)
+		share[i] ^= 0x01
+		return share
+	}
+	combineCases := []conformance.Case{
+		{Name: "combine/first-quorum", Op: conformance.OpCombine, Shares: encode(raw[:3])},
+		{Name: "combine/last-quorum-reversed", Op: conformance.OpCombine, Shares: encode([][]byte{raw[4], raw[3], raw[2]})},
+		{Name: "combine/all-shares", Op: conformance.OpCombine, Shares: encode(raw)},
+		{Name: "combine/one-share", Op: conformance.OpCombine, Shares: encode(raw[:1]), Error: "too-few-parts"},
+		{Name: "combine/too-short", Op: conformance.OpCombine, Shares: []string{"01", "02"}, Error: "too-short"},
+		{Name: "combine/different-lengths", Op: conformance.OpCombine, Shares: []string{hexs(raw[0]), hexs(raw[1][:len(raw[1])-1])}, Error: "different-lengths"},
+		{Name: "combine/duplicate-share", Op: conformance.OpCombine, Shares: encode([][]byte{raw[0], raw[1], raw[0]}), Error: "duplicate-part"},
+		{Name: "combine-integrity/quorum", Op: conformance.OpCombineIntegrity, Shares: encode(integrity[1:])},
+		{Name: "combine-integrity/corrupted", Op: conformance.OpCombineIntegrity, Shares: encode([][]byte{integrity[0], corrupt(integrity[1], 3)}), Error: "integrity-check-failed"},
+		{Name: "combine-authenticated/quorum", Op: conformance.OpCombineAuthenticated, Key: hexs(key), Shares: encode(authenticated[:2])},
+		{Name: "combine-authenticated/forged", Op: conformance.OpCombineAuthenticated, Key: hexs(key), Shares: encode([][]byte{authenticated[0], corrupt(authenticated[2], 2)}), Error: "authentication-failed"},
+		{Name: "combine-authenticated/wrong-key", Op: conformance.OpCombineAuthenticated, Key: hexs(bytes.Repeat([]byte{0xa5}, 32)), Shares: encode(authenticated[:2]), Error: "authentication-failed"},
+		{Name: "combine-vault/quorum", Op: conformance.OpCombineVault, Shares: encode(vault[1:])},
+	}
+	for i := range combineCases {
+		if combineCases[i].Error == "" {
+			combineCases[i].Secret = hexs(secret)
+		}
+	}
+	cases = append(cases, combineCases...)
+
+	for _, c := range cases {
+		if err := c.Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := json.MarshalIndent(conformance.Suite{Version: 1, Cases: cases}, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("suite.json", append(data, '\n'), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("wrote %d cases to suite.json", len(cases))
+}
+
+// splitLikeRun performs a split case's operation with this package.
+func splitLikeRun(t *testing.T, c conformance.Case) [][]byte {
+	secret, _ := hex.DecodeString(c.Secret)
+	var shares [][]byte
+	var err error
+	if c.Op == conformance.OpSplitDeterministic {
+		seed, _ := hex.DecodeString(c.Seed)
+		shares, err = shamir.SplitDeterministic(secret, c.Parts, c.Threshold, seed)
+	} else {
+		rngSeed, _ := hex.DecodeString(c.RNGSeed)
+		opts := []shamir.Option{shamir.WithParts(c.Parts), shamir.WithThreshold(c.Threshold), shamir.WithIntegrity(c.Integrity),
+			shamir.WithRand(conformance.NewRand(rngSeed)), shamir.WithStrategy(shamir.StrategyScalar)}
+		if c.XCoordinates != nil {
+			xs := make([]byte, len(c.XCoordinates))
+			for i, x := range c.XCoordinates {
+				xs[i] = byte(x)
+			}
+			opts = append(opts, shamir.WithXCoordinates(xs...))
+		}
+		shares, err = shamir.SplitWithOptions(secret, opts...)
+	}
+	if err != nil {
+		t.Fatalf("%s: %v", c.Name, err)
+	}
+	return shares
+}
+
+func allBytes() []byte {
+	b := make([]byte, 256)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
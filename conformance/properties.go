@@ -0,0 +1,479 @@
+package conformance
+
+// Properties of secret sharing backends.
+//
+// Where the suite pins down this package's exact bytes, the properties below
+// hold for any correct implementation of Split and Combine, whatever field
+// and arithmetic it uses, so alternative backends (another field such as
+// GF(2¹⁶), new vector kernels, constant-time code) are validated against one
+// harness:
+//
+//   - any threshold of the shares reconstructs the secret, and so does any
+//     larger set;
+//   - fewer than threshold shares do not reconstruct it;
+//   - any threshold-1 shares are statistically independent of the secret;
+//   - shares differ from one split to the next, even for the same secret.
+//
+// Each property has a Check function returning an error that describes the
+// first violation; RunProperties checks them all as subtests. The checks see
+// shares only through Split and Combine, and the statistical tests look at
+// share bytes.
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	mrand "math/rand/v2"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// Scheme is the backend under test.
+type Scheme interface {
+	// Split divides secret into parts shares, any threshold of which
+	// reconstruct it.
+	Split(secret []byte, parts, threshold int) ([][]byte, error)
+
+	// Combine reconstructs a secret from at least threshold shares.
+	Combine(shares [][]byte) ([]byte, error)
+}
+
+// Funcs adapts a pair of functions to the Scheme interface.
+type Funcs struct {
+	SplitFunc   func(secret []byte, parts, threshold int) ([][]byte, error)
+	CombineFunc func(shares [][]byte) ([]byte, error)
+}
+
+// Split calls f.SplitFunc.
+func (f Funcs) Split(secret []byte, parts, threshold int) ([][]byte, error) {
+	return f.SplitFunc(secret, parts, threshold)
+}
+
+// Combine calls f.CombineFunc.
+func (f Funcs) Combine(shares [][]byte) ([]byte, error) {
+	return f.CombineFunc(shares)
+}
+
+// Shamir is the shamir package's own Split and Combine.
+var Shamir Scheme = Funcs{SplitFunc: shamir.Split, CombineFunc: shamir.Combine}
+
+// ErrViolation is wrapped by every error reporting a broken property, as
+// opposed to a backend failing outright.
+var ErrViolation = errors.New("conformance: invariant violated")
+
+// Params is one threshold scheme: Threshold of Parts shares reconstruct.
+type Params struct {
+	Parts, Threshold int
+}
+
+func (p Params) String() string {
+	return fmt.Sprintf("%d-of-%d", p.Threshold, p.Parts)
+}
+
+// Config selects what RunProperties checks. The zero value checks the defaults.
+type Config struct {
+	// Params lists the schemes whose reconstruction is checked; nil means
+	// DefaultParams.
+	Params []Params
+
+	// StatParams lists the schemes checked for independence, which splits
+	// many times; nil means DefaultStatParams.
+	StatParams []Params
+
+	// SecretSizes lists the secret lengths checked; nil means 1, 16, 33 and
+	// 1000 bytes. Backends working on wider field elements can restrict them
+	// to multiples of the element size.
+	SecretSizes []int
+
+	// Trials is the number of splits behind each statistical test; 0 means
+	// DefaultTrials. Fewer trials make the independence test weaker.
+	Trials int
+
+	// Payload returns the bytes of a share that carry y-values, which the
+	// statistical tests examine; nil means all but the first
+	// shamir.ShareOverhead bytes. Backends whose shares carry other fixed or
+	// structured fields, such as wider x-coordinates or checksums, must strip
+	// them.
+	Payload func(share []byte) []byte
+}
+
+// DefaultParams are the schemes RunProperties checks for reconstruction by default,
+// from the smallest to 255 parts.
+var DefaultParams = []Params{{2, 2}, {3, 2}, {5, 3}, {7, 7}, {10, 4}, {20, 13}, {255, 2}, {255, 129}}
+
+// DefaultStatParams are the schemes RunProperties checks for independence by default.
+var DefaultStatParams = []Params{{2, 2}, {3, 2}, {5, 3}, {6, 6}}
+
+// DefaultTrials is the number of splits behind each statistical test when
+// Config.Trials is zero.
+const DefaultTrials = 1000
+
+// maxSubsets bounds the threshold-sized subsets CheckReconstruction tries;
+// beyond it, a random sample is taken.
+const maxSubsets = 200
+
+func (c Config) params() []Params {
+	if c.Params == nil {
+		return DefaultParams
+	}
+	return c.Params
+}
+
+func (c Config) statParams() []Params {
+	if c.StatParams == nil {
+		return DefaultStatParams
+	}
+	return c.StatParams
+}
+
+func (c Config) secretSizes() []int {
+	if c.SecretSizes == nil {
+		return []int{1, 16, 33, 1000}
+	}
+	return c.SecretSizes
+}
+
+func (c Config) trials() int {
+	if c.Trials <= 0 {
+		return DefaultTrials
+	}
+	return c.Trials
+}
+
+func (c Config) payload(share []byte) []byte {
+	if c.Payload == nil {
+		return share[shamir.ShareOverhead:]
+	}
+	return c.Payload(share)
+}
+
+// RunProperties checks every property for s as subtests of t. Under -short, the
+// statistical tests use a tenth of the trials.
+func RunProperties(t *testing.T, s Scheme, cfg Config) {
+	t.Run("reconstruction", func(t *testing.T) {
+		for _, p := range cfg.params() {
+			for _, size := range cfg.secretSizes() {
+				secret := randomSecret(size)
+				if err := CheckReconstruction(s, secret, p); err != nil {
+					t.Errorf("%s, %d bytes: %v", p, size, err)
+				}
+				if err := CheckBelowThreshold(s, secret, p); err != nil {
+					t.Errorf("%s, %d bytes: %v", p, size, err)
+				}
+			}
+		}
+	})
+	t.Run("independence", func(t *testing.T) {
+		trials := cfg.trials()
+		if testing.Short() {
+			trials = max(trials/10, 50)
+		}
+		for _, p := range cfg.statParams() {
+			if err := CheckIndependence(s, p, trials, cfg.Payload); err != nil {
+				t.Errorf("%s: %v", p, err)
+			}
+		}
+	})
+	t.Run("freshness", func(t *testing.T) {
+		for _, p := range cfg.params() {
+			if err := CheckFreshness(s, p, 8); err != nil {
+				t.Errorf("%s: %v", p, err)
+			}
+		}
+	})
+}
+
+// CheckReconstruction splits secret and checks that every threshold-sized
+// subset of the shares reconstructs it, in their original and a shuffled
+// order, and so do larger sets up to all shares. When there are too many
+// subsets to try, a deterministic sample is taken, always including the
+// first and last threshold shares.
+func CheckReconstruction(s Scheme, secret []byte, p Params) error {
+	shares, err := s.Split(secret, p.Parts, p.Threshold)
+	if err != nil {
+		return fmt.Errorf("conformance: Split: %w", err)
+	}
+	if len(shares) != p.Parts {
+		return fmt.Errorf("%w: Split returned %d shares, want %d", ErrViolation, len(shares), p.Parts)
+	}
+
+	rng := mrand.New(mrand.NewPCG(uint64(p.Parts), uint64(p.Threshold)))
+	for _, subset := range subsets(p.Parts, p.Threshold, rng) {
+		quorum := pick(shares, subset)
+		if err := reconstructs(s, quorum, secret); err != nil {
+			return fmt.Errorf("shares %v: %w", subset, err)
+		}
+		rng.Shuffle(len(quorum), func(i, j int) { quorum[i], quorum[j] = quorum[j], quorum[i] })
+		if err := reconstructs(s, quorum, secret); err != nil {
+			return fmt.Errorf("shares %v, shuffled: %w", subset, err)
+		}
+	}
+
+	for n := p.Threshold + 1; n <= p.Parts; n = next(n, p.Parts) {
+		subset := rng.Perm(p.Parts)[:n]
+		if err := reconstructs(s, pick(shares, subset), secret); err != nil {
+			return fmt.Errorf("%d shares %v: %w", n, subset, err)
+		}
+	}
+	return nil
+}
+
+// next steps through the sizes above the threshold: each of the first few,
+// then doubling, then all shares.
+func next(n, parts int) int {
+	if n < 8 || n == parts {
+		return n + 1
+	}
+	return min(2*n, parts)
+}
+
+// reconstructs checks that s.Combine recovers want from quorum.
+func reconstructs(s Scheme, quorum [][]byte, want []byte) error {
+	got, err := s.Combine(quorum)
+	if err != nil {
+		return fmt.Errorf("%w: Combine failed: %w", ErrViolation, err)
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("%w: Combine returned a different secret", ErrViolation)
+	}
+	return nil
+}
+
+// CheckBelowThreshold splits secret and checks that no sample of
+// threshold-1 shares reconstructs it: Combine must fail or return another
+// value. Secrets shorter than 8 bytes can be hit by chance and are padded
+// with random bytes first.
+func CheckBelowThreshold(s Scheme, secret []byte, p Params) error {
+	if p.Threshold < 3 {
+		return nil // One share alone is refused by Combine, or is the secret in a 1-of-n split
+	}
+	if len(secret) < 8 {
+		secret = append(bytes.Clone(secret), randomSecret(8-len(secret))...)
+	}
+	shares, err := s.Split(secret, p.Parts, p.Threshold)
+	if err != nil {
+		return fmt.Errorf("conformance: Split: %w", err)
+	}
+
+	rng := mrand.New(mrand.NewPCG(uint64(p.Threshold), uint64(p.Parts)))
+	for _, subset := range subsets(p.Parts, p.Threshold-1, rng) {
+		if got, err := s.Combine(pick(shares, subset)); err == nil && bytes.Equal(got, secret) {
+			return fmt.Errorf("%w: %d shares %v, below the threshold, reconstruct the secret", ErrViolation, len(subset), subset)
+		}
+	}
+	return nil
+}
+
+// statSecretSize is the length of the secrets CheckIndependence splits.
+const statSecretSize = 32
+
+// chiSquaredCritical is the χ² value with 255 degrees of freedom exceeded
+// with probability 10⁻⁶ (Wilson–Hilferty approximation), the rejection point
+// of every statistical test: with a few dozen tests per run, a correct
+// backend fails spuriously about once in ten thousand runs.
+var chiSquaredCritical = func() float64 {
+	const df, z = 255.0, 4.753
+	v := 1 - 2/(9*df) + z*math.Sqrt(2/(9*df))
+	return df * v * v * v
+}()
+
+// CheckIndependence checks that threshold-1 shares reveal nothing about the
+// secret. It splits two secrets, all zero bytes and all 0xff bytes, trials
+// times each, and tallies the payload bytes (see Config.Payload; nil means
+// all but the x-coordinate) of the first and of the last threshold-1 shares,
+// each share separately and XORed together. For a correct backend every
+// tally is uniform whatever the secret, so a χ² test rejects any that is not,
+// and any pair of tallies that differs between the two secrets.
+func CheckIndependence(s Scheme, p Params, trials int, payload func(share []byte) []byte) error {
+	if p.Threshold < 2 {
+		return nil
+	}
+	if payload == nil {
+		payload = Config{}.payload
+	}
+	k := p.Threshold - 1
+	first, last := make([]int, k), make([]int, k)
+	for i := range first {
+		first[i], last[i] = i, p.Parts-k+i
+	}
+
+	secrets := [2][]byte{make([]byte, statSecretSize), bytes.Repeat([]byte{0xff}, statSecretSize)}
+	// tallies[secret][subset][i] counts payload bytes of share i of the
+	// subset, with i == k for the XOR of all of them.
+	var tallies [2][2][][256]int
+	for si, secret := range secrets {
+		for sub := range tallies[si] {
+			tallies[si][sub] = make([][256]int, k+1)
+		}
+		for trial := 0; trial < trials; trial++ {
+			shares, err := s.Split(secret, p.Parts, p.Threshold)
+			if err != nil {
+				return fmt.Errorf("conformance: Split: %w", err)
+			}
+			for sub, subset := range [2][]int{first, last} {
+				tally := tallies[si][sub]
+				var sum []byte
+				for i, idx := range subset {
+					y := payload(shares[idx])
+					if sum == nil {
+						sum = make([]byte, len(y))
+					}
+					for j, b := range y {
+						tally[i][b]++
+						sum[j] ^= b
+					}
+				}
+				for _, b := range sum {
+					tally[k][b]++
+				}
+			}
+		}
+	}
+
+	for sub, subset := range [2][]int{first, last} {
+		for i := 0; i <= k; i++ {
+			what := fmt.Sprintf("share %d", subset[min(i, k-1)])
+			if i == k {
+				what = fmt.Sprintf("XOR of shares %v", subset)
+			}
+			for si := range secrets {
+				if x := chiSquaredUniform(&tallies[si][sub][i]); x > chiSquaredCritical {
+					return fmt.Errorf("%w: bytes of %s are not uniform for secret %x... (χ² = %.0f)", ErrViolation, what, secrets[si][:4], x)
+				}
+			}
+			if x := chiSquaredHomogeneity(&tallies[0][sub][i], &tallies[1][sub][i]); x > chiSquaredCritical {
+				return fmt.Errorf("%w: bytes of %s depend on the secret (χ² = %.0f)", ErrViolation, what, x)
+			}
+		}
+	}
+	return nil
+}
+
+// chiSquaredUniform returns the χ² statistic of tally against the uniform
+// distribution.
+func chiSquaredUniform(tally *[256]int) float64 {
+	total := 0
+	for _, n := range tally {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	expected := float64(total) / 256
+	var x float64
+	for _, n := range tally {
+		d := float64(n) - expected
+		x += d * d / expected
+	}
+	return x
+}
+
+// chiSquaredHomogeneity returns the χ² statistic for the hypothesis that two
+// tallies of equal size come from the same distribution.
+func chiSquaredHomogeneity(a, b *[256]int) float64 {
+	var x float64
+	for i := range a {
+		if n := a[i] + b[i]; n > 0 {
+			d := float64(a[i] - b[i])
+			x += d * d / float64(n)
+		}
+	}
+	return x
+}
+
+// CheckFreshness splits the same secret calls times and checks that no
+// share is ever repeated: every split must draw fresh randomness.
+func CheckFreshness(s Scheme, p Params, calls int) error {
+	secret := randomSecret(32)
+	seen := make(map[string]int)
+	for call := 0; call < calls; call++ {
+		shares, err := s.Split(secret, p.Parts, p.Threshold)
+		if err != nil {
+			return fmt.Errorf("conformance: Split: %w", err)
+		}
+		for i, share := range shares {
+			if prev, ok := seen[string(share)]; ok {
+				return fmt.Errorf("%w: split %d repeated share %d of split %d", ErrViolation, call, i, prev)
+			}
+			seen[string(share)] = call
+		}
+	}
+	return nil
+}
+
+// subsets returns the k-element subsets of 0..n-1 CheckReconstruction and
+// CheckBelowThreshold try: all of them if there are at most maxSubsets,
+// otherwise the first, the last and a random sample.
+func subsets(n, k int, rng *mrand.Rand) [][]int {
+	if binomialAtMost(n, k, maxSubsets) {
+		var all [][]int
+		combinations(n, k, func(c []int) { all = append(all, append([]int(nil), c...)) })
+		return all
+	}
+	first, last := make([]int, k), make([]int, k)
+	for i := range first {
+		first[i], last[i] = i, n-k+i
+	}
+	sample := [][]int{first, last}
+	for len(sample) < maxSubsets {
+		sample = append(sample, rng.Perm(n)[:k])
+	}
+	return sample
+}
+
+// binomialAtMost reports whether n choose k is at most limit.
+func binomialAtMost(n, k, limit int) bool {
+	k = min(k, n-k)
+	c := 1
+	for i := 1; i <= k; i++ {
+		c = c * (n - k + i) / i
+		if c > limit {
+			return false
+		}
+	}
+	return true
+}
+
+// combinations calls fn with every k-element subset of 0..n-1 in
+// lexicographic order. fn must not retain its argument.
+func combinations(n, k int, fn func([]int)) {
+	c := make([]int, k)
+	for i := range c {
+		c[i] = i
+	}
+	for {
+		fn(c)
+		i := k - 1
+		for i >= 0 && c[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+		c[i]++
+		for j := i + 1; j < k; j++ {
+			c[j] = c[j-1] + 1
+		}
+	}
+}
+
+// pick returns the shares at the given indices.
+func pick(shares [][]byte, indices []int) [][]byte {
+	out := make([][]byte, len(indices))
+	for i, idx := range indices {
+		out[i] = shares[idx]
+	}
+	return out
+}
+
+// randomSecret returns size random bytes.
+func randomSecret(size int) []byte {
+	secret := make([]byte, size)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return secret
+}
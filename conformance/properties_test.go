@@ -0,0 +1,110 @@
+package conformance_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+	"github.com/morizta/go-shamir/conformance"
+	"github.com/morizta/go-shamir/shamirtest"
+)
+
+func TestPropertiesShamir(t *testing.T) {
+	conformance.RunProperties(t, conformance.Shamir, conformance.Config{})
+}
+
+func TestPropertiesStrategies(t *testing.T) {
+	for _, strategy := range []shamir.Strategy{shamir.StrategyScalar, shamir.StrategySIMD, shamir.StrategyParallel, shamir.StrategyStreaming} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			opt := shamir.WithStrategy(strategy)
+			conformance.RunProperties(t, conformance.Funcs{
+				SplitFunc: func(secret []byte, parts, threshold int) ([][]byte, error) {
+					return shamir.SplitWithOptions(secret, shamir.WithParts(parts), shamir.WithThreshold(threshold), opt)
+				},
+				CombineFunc: func(shares [][]byte) ([]byte, error) {
+					return shamir.CombineWithOptions(shares, opt)
+				},
+			}, conformance.Config{
+				Params:     []conformance.Params{{3, 2}, {5, 3}, {255, 7}},
+				StatParams: []conformance.Params{{3, 2}, {5, 3}},
+				Trials:     300,
+			})
+		})
+	}
+}
+
+func TestPropertiesReference(t *testing.T) {
+	conformance.RunProperties(t, conformance.Funcs{
+		SplitFunc: func(secret []byte, parts, threshold int) ([][]byte, error) {
+			return shamirtest.ReferenceSplit(secret, parts, threshold, rand.Reader)
+		},
+		CombineFunc: shamirtest.ReferenceCombine,
+	}, conformance.Config{
+		Params:     []conformance.Params{{3, 2}, {5, 3}, {10, 10}},
+		StatParams: []conformance.Params{{3, 2}, {4, 4}},
+		Trials:     300,
+	})
+}
+
+// zeroReader stands in for a broken random number generator.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestPropertiesDetectBrokenBackends(t *testing.T) {
+	secret := []byte("a sixteen byte k")
+	p := conformance.Params{Parts: 5, Threshold: 3}
+
+	// Coefficients that are always zero make every share the secret.
+	noRandomness := conformance.Funcs{
+		SplitFunc: func(secret []byte, parts, threshold int) ([][]byte, error) {
+			return shamir.SplitWithOptions(secret, shamir.WithParts(parts), shamir.WithThreshold(threshold), shamir.WithRand(zeroReader{}))
+		},
+		CombineFunc: shamir.Combine,
+	}
+	if err := conformance.CheckIndependence(noRandomness, p, 100, nil); !errors.Is(err, conformance.ErrViolation) {
+		t.Errorf("zero coefficients: expected a violation, got %v", err)
+	}
+
+	// A fixed seed repeats the same shares on every call.
+	fixedSeed := conformance.Funcs{
+		SplitFunc: func(secret []byte, parts, threshold int) ([][]byte, error) {
+			return shamir.SplitDeterministic(secret, parts, threshold, bytes.Repeat([]byte{1}, 32))
+		},
+		CombineFunc: shamir.Combine,
+	}
+	if err := conformance.CheckFreshness(fixedSeed, p, 2); !errors.Is(err, conformance.ErrViolation) {
+		t.Errorf("fixed seed: expected a violation, got %v", err)
+	}
+
+	// Ignoring the requested threshold lets two shares reconstruct.
+	lowThreshold := conformance.Funcs{
+		SplitFunc: func(secret []byte, parts, threshold int) ([][]byte, error) {
+			return shamir.Split(secret, parts, 2)
+		},
+		CombineFunc: shamir.Combine,
+	}
+	if err := conformance.CheckBelowThreshold(lowThreshold, secret, p); !errors.Is(err, conformance.ErrViolation) {
+		t.Errorf("low threshold: expected a violation, got %v", err)
+	}
+
+	// Dropping a share makes a threshold-sized quorum fall short.
+	dropsShare := conformance.Funcs{
+		SplitFunc: shamir.Split,
+		CombineFunc: func(shares [][]byte) ([]byte, error) {
+			return shamir.Combine(shares[1:])
+		},
+	}
+	if err := conformance.CheckReconstruction(dropsShare, secret, p); !errors.Is(err, conformance.ErrViolation) {
+		t.Errorf("dropped share: expected a violation, got %v", err)
+	}
+
+	if err := conformance.CheckReconstruction(conformance.Shamir, secret, conformance.Params{Parts: 2, Threshold: 3}); err == nil || errors.Is(err, conformance.ErrViolation) {
+		t.Errorf("invalid parameters: expected a Split error, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package shamir
+
+import (
+	"crypto/subtle"
+
+	"github.com/morizta/go-shamir/gf256"
+)
+
+// Constant-time reconstruction.
+//
+// The default combine path is fast rather than constant-time: the field
+// operations index log/exp tables with share bytes, multiplication
+// special-cases zero, and duplicate detection stops at the first repeated
+// x-coordinate. An attacker who can time reconstructions, or observe the
+// cache of the machine doing them, learns something about the values
+// involved. The hardened path removes those dependencies.
+
+// WithConstantTime selects the hardened reconstruction path in
+// CombineWithOptions, CombineContext and the combines built on them, for
+// raw, integrity-checked and enveloped shares alike.
+//
+// On that path nothing reconstruction does branches on, or indexes memory
+// with, share contents: duplicate x-coordinates are found by comparing every
+// pair, the Lagrange weights are computed with gf256.MulConstantTime and
+// gf256.InvConstantTime, so a zero denominator yields a zero weight rather
+// than a skipped term, and the payloads are multiplied with
+// gf256.MulAddSliceConstantTime. The time taken depends only on the number
+// and length of the shares. Errors are still returned early, since whether
+// a combine failed is visible to an attacker anyway.
+//
+// The guarantee covers interpolation, not the checks around it: the CRC32
+// of WithIntegrity and envelope parsing are table-driven, policy hooks and
+// the CombineStrict cross-check run as usual, and the x-coordinates are
+// passed to hooks in the clear. It is several times slower than the default
+// path on CPUs with a vector kernel, and much slower on others.
+func WithConstantTime(enabled bool) Option {
+	return func(o *options) { o.constantTime = enabled }
+}
+
+// validateCombineParamsConstantTime is validateCombineParams for the
+// constant-time path: it reports the same duplicate share, found by comparing
+// every pair of x-coordinates without an early exit.
+func validateCombineParamsConstantTime(shares [][]byte) error {
+	if err := validateCombineLayout(shares); err != nil {
+		return err
+	}
+
+	// Find the first share that repeats an earlier x-coordinate, scanning
+	// backwards so the last selected index is the first.
+	duplicate := -1
+	for i := len(shares) - 1; i > 0; i-- {
+		repeated := 0
+		for j := 0; j < i; j++ {
+			repeated |= subtle.ConstantTimeByteEq(shares[i][0], shares[j][0])
+		}
+		duplicate = subtle.ConstantTimeSelect(repeated, i, duplicate)
+	}
+	if duplicate >= 0 {
+		return NewValidationError("share", duplicate, "shamir: duplicate share identifier detected")
+	}
+	return nil
+}
+
+// lagrangeBasisConstantTime is lagrangeBasis without table lookups.
+func lagrangeBasisConstantTime(xCoords []byte, x byte) []byte {
+	basis := make([]byte, len(xCoords))
+	gf256.LagrangeBasisConstantTime(basis, xCoords, x)
+	return basis
+}
@@ -0,0 +1,74 @@
+package shamir
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestConstantTimeCombine(t *testing.T) {
+	ct := WithConstantTime(true)
+	for _, size := range []int{1, 15, 16, 33, 5000} {
+		secret := make([]byte, size)
+		rand.Read(secret)
+		shares, err := Split(secret, 5, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		subset := [][]byte{shares[4], shares[1], shares[2]}
+		for _, s := range []Strategy{StrategyAuto, StrategyScalar, StrategyParallel, StrategyStreaming} {
+			got, err := CombineWithOptions(subset, ct, WithStrategy(s), WithParallelism(2))
+			if err != nil || !bytes.Equal(got, secret) {
+				t.Fatalf("size %d, %v: %v", size, s, err)
+			}
+		}
+		got, err := CombineContext(context.Background(), subset, ct)
+		if err != nil || !bytes.Equal(got, secret) {
+			t.Fatalf("size %d, CombineContext: %v", size, err)
+		}
+	}
+
+	secret := []byte("constant-time reconstruction")
+	integrity, err := SplitWithOptions(secret, WithParts(4), WithThreshold(2), WithIntegrity(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := CombineWithOptions(integrity[2:], WithIntegrity(true), ct); err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("integrity shares: %v", err)
+	}
+	enveloped, err := SplitWithOptions(secret, WithParts(4), WithThreshold(2), WithPurpose("backup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := CombineWithOptions(enveloped[1:3], WithPurpose("backup"), ct); err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("enveloped shares: %v", err)
+	}
+}
+
+func TestConstantTimeDuplicates(t *testing.T) {
+	shares, err := Split([]byte("secret"), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, parts := range [][][]byte{
+		{shares[0], shares[1], shares[0]},
+		{shares[2], shares[2], shares[3], shares[3]},
+		{shares[1], shares[3], shares[4], shares[3], shares[1]},
+	} {
+		want := validateCombineParams(parts)
+		_, err := CombineWithOptions(parts, WithConstantTime(true))
+		var got, expected *ValidationError
+		if !errors.As(err, &got) || !errors.As(want, &expected) {
+			t.Fatalf("expected validation errors, got %v and %v", err, want)
+		}
+		if got.Value != expected.Value {
+			t.Errorf("duplicate reported at share %v, want %v", got.Value, expected.Value)
+		}
+	}
+
+	if _, err := CombineWithOptions([][]byte{shares[0]}, WithConstantTime(true)); !errors.Is(err, ErrTooFewParts) {
+		t.Errorf("expected ErrTooFewParts, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package shamir
+
+import "context"
+
+// SplitContext is SplitWithOptions with cancellation: the secret is processed
+// in 1 MiB windows and ctx is checked before each one, so splitting a very
+// large secret in a request-scoped flow can be aborted. On cancellation the
+// partially generated shares are wiped and ctx.Err() is returned.
+func SplitContext(ctx context.Context, secret []byte, opts ...Option) ([][]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return SplitWithOptions(secret, append(opts[:len(opts):len(opts)], withContext(ctx))...)
+}
+
+// CombineContext is CombineWithOptions with cancellation: ctx is checked
+// before each 1 MiB window of the secret is interpolated. On cancellation the
+// partially reconstructed secret is wiped and ctx.Err() is returned.
+func CombineContext(ctx context.Context, parts [][]byte, opts ...Option) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return CombineWithOptions(parts, append(opts[:len(opts):len(opts)], withContext(ctx))...)
+}
+
+// withContext makes the split or combine check ctx between windows.
+func withContext(ctx context.Context) Option {
+	return func(o *options) { o.ctx = ctx }
+}
+
+// contextErr returns ctx.Err(), or nil if ctx is nil.
+func contextErr(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Err()
+}
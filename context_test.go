@@ -0,0 +1,90 @@
+package shamir
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countdownContext reports cancellation once Err has been called n times,
+// simulating a request that is aborted part-way through an operation.
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (c *countdownContext) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func TestSplitCombineContext(t *testing.T) {
+	secret := bytes.Repeat([]byte("0123456789abcdef"), 3*strategyWindowSize/16+7)
+
+	shares, err := SplitContext(context.Background(), secret, WithParts(3), WithThreshold(2), WithIntegrity(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CombineContext(context.Background(), shares[1:], WithIntegrity(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+
+	t.Run("already cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := SplitContext(ctx, secret, WithParts(3), WithThreshold(2)); !errors.Is(err, context.Canceled) {
+			t.Errorf("SplitContext: expected context.Canceled, got %v", err)
+		}
+		if _, err := CombineContext(ctx, shares, WithIntegrity(true)); !errors.Is(err, context.Canceled) {
+			t.Errorf("CombineContext: expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("cancelled between windows", func(t *testing.T) {
+		ctx := &countdownContext{Context: context.Background(), n: 2}
+		if _, err := SplitContext(ctx, secret, WithParts(3), WithThreshold(2)); !errors.Is(err, context.Canceled) {
+			t.Errorf("SplitContext: expected context.Canceled, got %v", err)
+		}
+
+		ctx = &countdownContext{Context: context.Background(), n: 2}
+		if _, err := CombineContext(ctx, shares[:2], WithIntegrity(true)); !errors.Is(err, context.Canceled) {
+			t.Errorf("CombineContext: expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("enveloped", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		shares, err := SplitContext(ctx, []byte("small"), WithParts(2), WithThreshold(2), WithPurpose("backup"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := CombineContext(ctx, shares, WithPurpose("backup"))
+		if err != nil || string(got) != "small" {
+			t.Fatalf("got %q, %v", got, err)
+		}
+	})
+
+	t.Run("options not aliased", func(t *testing.T) {
+		opts := make([]Option, 2, 3)
+		opts[0], opts[1] = WithParts(2), WithThreshold(2)
+		ctx, cancel := context.WithCancel(context.Background())
+		if _, err := SplitContext(ctx, []byte("x"), opts...); err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		// The caller's spare capacity must not have captured the context.
+		if _, err := SplitWithOptions([]byte("x"), append(opts, WithIntegrity(false))...); err != nil {
+			t.Fatalf("SplitWithOptions affected by an earlier SplitContext: %v", err)
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package shamir
+
+import (
+	"sort"
+)
+
+// CombineWithCorrection reconstructs the secret from more than threshold shares,
+// using the redundancy to detect and correct corrupted shares.
+//
+// With n shares and threshold k, up to (n-k)/2 corrupted shares are corrected
+// at every byte position using the Berlekamp-Welch decoder. Byte positions
+// where all shares agree take a fast path that costs little more than Combine.
+//
+// Parameters:
+//   - parts: Array of shares generated by Split (at least threshold shares)
+//   - threshold: The threshold used when the shares were split
+//
+// Returns:
+//   - []byte: The reconstructed secret
+//   - []int: Indices into parts of every share found to be corrupted (sorted)
+//   - error: ErrUncorrectable if there are too many corrupted shares to correct
+func CombineWithCorrection(parts [][]byte, threshold int) ([]byte, []int, error) {
+	if err := validateCombineParams(parts); err != nil {
+		return nil, nil, err
+	}
+	if threshold < 2 || threshold > len(parts) {
+		return nil, nil, NewValidationError("threshold", threshold,
+			"shamir: threshold must be between 2 and the number of shares")
+	}
+
+	n := len(parts)
+	k := threshold
+	secretLen := len(parts[0]) - ShareOverhead
+
+	xCoords := make([]byte, n)
+	for i, part := range parts {
+		xCoords[i] = part[0]
+	}
+	meta := CombineMetadata{Shares: n, Indices: xCoords, SecretSize: secretLen, Threshold: k}
+	if err := checkPolicy(meta, nil); err != nil {
+		return nil, nil, err
+	}
+	return correctErrors(parts, xCoords, k)
+}
+
+// correctErrors implements CombineWithCorrection once the shares and
+// threshold k have been validated.
+func correctErrors(parts [][]byte, xCoords []byte, k int) ([]byte, []int, error) {
+	n := len(parts)
+	secretLen := len(parts[0]) - ShareOverhead
+
+	// Lagrange weights of the first k shares, evaluated at x=0 and at every
+	// remaining share's x-coordinate, for the consistency fast path.
+	secretWeights := lagrangeBasis(xCoords[:k], 0)
+	checkWeights := make([][]byte, n-k)
+	for i := range checkWeights {
+		checkWeights[i] = lagrangeBasis(xCoords[:k], xCoords[k+i])
+	}
+
+	secret := make([]byte, secretLen)
+	yCoords := make([]byte, n)
+	auditTrack("correction.ycoords", yCoords)
+	defer func() {
+		secureZeroBytes(yCoords)
+		auditRelease(yCoords)
+	}()
+
+	bad := make(map[int]bool)
+	for pos := 0; pos < secretLen; pos++ {
+		for i, part := range parts {
+			yCoords[i] = part[pos+ShareOverhead]
+		}
+
+		consistent := true
+		for i, weights := range checkWeights {
+			if dotProduct(weights, yCoords[:k]) != yCoords[k+i] {
+				consistent = false
+				break
+			}
+		}
+		if consistent {
+			secret[pos] = dotProduct(secretWeights, yCoords[:k])
+			continue
+		}
+
+		poly, ok := berlekampWelch(xCoords, yCoords, k)
+		if !ok {
+			secureZeroBytes(secret)
+			return nil, nil, ErrUncorrectable
+		}
+		secret[pos] = poly[0]
+		for i := range parts {
+			if gfPolyEval(poly, xCoords[i]) != yCoords[i] {
+				bad[i] = true
+			}
+		}
+		secureZeroBytes(poly)
+	}
+
+	badShares := make([]int, 0, len(bad))
+	for i := range bad {
+		badShares = append(badShares, i)
+	}
+	sort.Ints(badShares)
+
+	return secret, badShares, nil
+}
+
+// dotProduct returns sum(a[i] * b[i]) in GF(256).
+func dotProduct(a, b []byte) byte {
+	var result byte
+	for i := range a {
+		result ^= gfMult(a[i], b[i])
+	}
+	return result
+}
+
+// berlekampWelch decodes the polynomial of degree < k passing through all but at most
+// (n-k)/2 of the points (xCoords[i], yCoords[i]). Returns false if no such polynomial exists.
+func berlekampWelch(xCoords, yCoords []byte, k int) ([]byte, bool) {
+	n := len(xCoords)
+	e := (n - k) / 2
+	if e == 0 {
+		return nil, false // Enough redundancy to detect errors but not to correct them
+	}
+
+	// Unknowns: Q(x) with k+e coefficients and E(x) = x^e + e_(e-1) x^(e-1) + ... + e_0.
+	// Each point gives Q(x_i) - y_i * (E(x_i) - x_i^e) = y_i * x_i^e.
+	cols := k + 2*e
+	matrix := make([][]byte, n)
+	for i := range matrix {
+		row := make([]byte, cols+1)
+		power := byte(1)
+		for j := 0; j < k+e; j++ {
+			row[j] = power
+			if j < e {
+				row[k+e+j] = gfMult(yCoords[i], power)
+			}
+			if j == e { // e < k+e, so the x^e term is always reached
+				row[cols] = gfMult(yCoords[i], power)
+			}
+			power = gfMult(power, xCoords[i])
+		}
+		matrix[i] = row
+	}
+
+	solution, ok := solveLinearSystem(matrix, cols)
+	if !ok {
+		return nil, false
+	}
+
+	q := solution[:k+e]
+	locator := make([]byte, e+1)
+	copy(locator, solution[k+e:])
+	locator[e] = 1
+
+	poly, remainder := polyDivide(q, locator)
+	for _, r := range remainder {
+		if r != 0 {
+			return nil, false
+		}
+	}
+	if len(poly) > k {
+		for _, c := range poly[k:] {
+			if c != 0 {
+				return nil, false
+			}
+		}
+		poly = poly[:k]
+	}
+
+	mismatches := 0
+	for i := range xCoords {
+		if gfPolyEval(poly, xCoords[i]) != yCoords[i] {
+			mismatches++
+		}
+	}
+	if mismatches > e {
+		return nil, false
+	}
+
+	return poly, true
+}
+
+// solveLinearSystem solves the augmented system matrix (rows of cols coefficients
+// followed by the right-hand side) over GF(256) by Gaussian elimination. Free
+// variables are set to zero. Returns false if the system is inconsistent.
+func solveLinearSystem(matrix [][]byte, cols int) ([]byte, bool) {
+	pivotCols := make([]int, 0, cols)
+	row := 0
+	for col := 0; col < cols && row < len(matrix); col++ {
+		pivot := -1
+		for r := row; r < len(matrix); r++ {
+			if matrix[r][col] != 0 {
+				pivot = r
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		matrix[row], matrix[pivot] = matrix[pivot], matrix[row]
+
+		inv := gfInv(matrix[row][col])
+		gfMultSlice(matrix[row], matrix[row], inv)
+
+		scratch := make([]byte, cols+1)
+		for r := range matrix {
+			if r != row && matrix[r][col] != 0 {
+				gfMultSlice(scratch, matrix[row], matrix[r][col])
+				gfAddSlice(matrix[r], matrix[r], scratch)
+			}
+		}
+
+		pivotCols = append(pivotCols, col)
+		row++
+	}
+
+	// Remaining rows are all-zero on the left; a non-zero right-hand side is a contradiction.
+	for r := row; r < len(matrix); r++ {
+		if matrix[r][cols] != 0 {
+			return nil, false
+		}
+	}
+
+	solution := make([]byte, cols)
+	for r, col := range pivotCols {
+		solution[col] = matrix[r][cols]
+	}
+	return solution, true
+}
+
+// polyDivide divides num by den (coefficients in ascending degree order, den monic or not)
+// and returns the quotient and remainder.
+func polyDivide(num, den []byte) ([]byte, []byte) {
+	for len(den) > 0 && den[len(den)-1] == 0 {
+		den = den[:len(den)-1]
+	}
+	remainder := append([]byte(nil), num...)
+	if len(num) < len(den) {
+		return []byte{0}, remainder
+	}
+
+	quotient := make([]byte, len(num)-len(den)+1)
+	lead := den[len(den)-1]
+	for i := len(quotient) - 1; i >= 0; i-- {
+		coeff := gfDiv(remainder[i+len(den)-1], lead)
+		quotient[i] = coeff
+		if coeff == 0 {
+			continue
+		}
+		for j := range den {
+			remainder[i+j] ^= gfMult(coeff, den[j])
+		}
+	}
+
+	return quotient, remainder[:len(den)-1]
+}
@@ -0,0 +1,110 @@
+package shamir
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCombineWithCorrection(t *testing.T) {
+	secret := []byte("error correcting reconstruction")
+
+	shares, err := Split(secret, 7, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	copyShares := func() [][]byte {
+		out := make([][]byte, len(shares))
+		for i, s := range shares {
+			out[i] = append([]byte(nil), s...)
+		}
+		return out
+	}
+
+	t.Run("no corruption", func(t *testing.T) {
+		reconstructed, bad, err := CombineWithCorrection(copyShares(), 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(reconstructed, secret) {
+			t.Fatal("reconstruction failed")
+		}
+		if len(bad) != 0 {
+			t.Fatalf("expected no bad shares, got %v", bad)
+		}
+	})
+
+	t.Run("corrects up to (n-k)/2 shares", func(t *testing.T) {
+		corrupted := copyShares()
+		corrupted[1][1] ^= 0x01 // Single flipped byte
+		for i := 1; i < len(corrupted[4]); i++ {
+			corrupted[4][i] ^= byte(i) | 0x80 // Whole share damaged
+		}
+
+		reconstructed, bad, err := CombineWithCorrection(corrupted, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(reconstructed, secret) {
+			t.Fatal("reconstruction failed")
+		}
+		if !reflect.DeepEqual(bad, []int{1, 4}) {
+			t.Fatalf("expected bad shares [1 4], got %v", bad)
+		}
+	})
+
+	t.Run("corrupted share among the first k", func(t *testing.T) {
+		corrupted := copyShares()
+		corrupted[0][5] ^= 0xff
+
+		reconstructed, bad, err := CombineWithCorrection(corrupted[:5], 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(reconstructed, secret) {
+			t.Fatal("reconstruction failed")
+		}
+		if !reflect.DeepEqual(bad, []int{0}) {
+			t.Fatalf("expected bad shares [0], got %v", bad)
+		}
+	})
+
+	t.Run("too many corrupted shares", func(t *testing.T) {
+		corrupted := copyShares()
+		for _, i := range []int{0, 2, 5} {
+			corrupted[i][2] ^= 0x5a
+		}
+
+		if _, _, err := CombineWithCorrection(corrupted, 3); err != ErrUncorrectable {
+			t.Fatalf("expected ErrUncorrectable, got %v", err)
+		}
+	})
+
+	t.Run("detect only", func(t *testing.T) {
+		corrupted := copyShares()[:4]
+		corrupted[3][1] ^= 0x01
+
+		if _, _, err := CombineWithCorrection(corrupted, 3); err != ErrUncorrectable {
+			t.Fatalf("expected ErrUncorrectable, got %v", err)
+		}
+	})
+
+	t.Run("invalid threshold", func(t *testing.T) {
+		if _, _, err := CombineWithCorrection(copyShares()[:3], 4); err == nil {
+			t.Fatal("expected error for threshold above share count")
+		}
+	})
+}
+
+func TestPolyDivide(t *testing.T) {
+	// (x + 2)(x + 3) = x^2 + (2^3)x + 6 in GF(256)
+	product := []byte{gfMult(2, 3), gfAdd(2, 3), 1}
+	quotient, remainder := polyDivide(product, []byte{2, 1})
+	if !bytes.Equal(quotient, []byte{3, 1}) {
+		t.Fatalf("unexpected quotient %v", quotient)
+	}
+	if !bytes.Equal(remainder, []byte{0}) {
+		t.Fatalf("unexpected remainder %v", remainder)
+	}
+}
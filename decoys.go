@@ -0,0 +1,123 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SplitWithDecoys splits a secret into parts real shares and adds decoys
+// syntactically valid dummy shares that carry random payloads.
+//
+// Real and decoy shares are indistinguishable without the returned manifest:
+// they share the same envelope, set ID, threshold and length, x-coordinates are
+// drawn at random for all of them, and the result is shuffled. Store decoys
+// alongside real shares so that an attacker who seizes a storage location
+// cannot tell how many real shares they obtained.
+//
+// The manifest flags every decoy and must be kept private by the dealer; use
+// Manifest.RemoveDecoys to select the real shares before combining.
+func SplitWithDecoys(secret []byte, parts, threshold, decoys int) ([][]byte, *Manifest, error) {
+	if decoys < 0 {
+		return nil, nil, NewValidationError("decoys", decoys, "shamir: decoy count cannot be negative")
+	}
+	if err := validateSplitParams(secret, parts, threshold); err != nil {
+		return nil, nil, err
+	}
+	if parts+decoys > 255 {
+		return nil, nil, NewValidationError("decoys", decoys, "shamir: parts plus decoys must not exceed 255")
+	}
+
+	xCoords, err := randomXCoords(parts+decoys, rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	setID, err := newSetID()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	o := newOptions([]Option{WithThreshold(threshold)})
+	o.xCoords = xCoords[:parts]
+	template := Share{SetID: setID}
+	genuine, err := splitEnvelopes(secret, o, template)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template.Threshold = threshold
+	all := append(genuine, make([][]byte, decoys)...)
+	for i, x := range xCoords[parts:] {
+		decoy := template
+		decoy.Index = x
+		decoy.Payload = make([]byte, len(secret))
+		if _, err := rand.Read(decoy.Payload); err != nil {
+			return nil, nil, fmt.Errorf("shamir: failed to generate decoy payload: %w", err)
+		}
+		if all[parts+i], err = decoy.MarshalBinary(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Shuffle so that position does not reveal which shares are real.
+	isDecoy := make([]bool, len(all))
+	for i := parts; i < len(all); i++ {
+		isDecoy[i] = true
+	}
+	for i := len(all) - 1; i > 0; i-- {
+		j, err := randIntn(rand.Reader, i+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		all[i], all[j] = all[j], all[i]
+		isDecoy[i], isDecoy[j] = isDecoy[j], isDecoy[i]
+	}
+
+	manifest := newManifest(setID, "", threshold, make([]string, len(all)), all)
+	for i := range manifest.Custodians {
+		manifest.Custodians[i].Decoy = isDecoy[i]
+	}
+	manifest.Parts = parts
+
+	return all, manifest, nil
+}
+
+// randomXCoords returns n distinct, non-zero x-coordinates in random order.
+func randomXCoords(n int, rng io.Reader) ([]byte, error) {
+	if n < 0 || n > 255 {
+		return nil, NewValidationError("parts", n, "shamir: parts must not exceed 255")
+	}
+
+	pool := make([]byte, 255)
+	for i := range pool {
+		pool[i] = byte(i + 1)
+	}
+	// Partial Fisher-Yates shuffle: the first n entries become a uniform random sample.
+	for i := 0; i < n; i++ {
+		j, err := randIntn(rng, len(pool)-i)
+		if err != nil {
+			return nil, err
+		}
+		pool[i], pool[i+j] = pool[i+j], pool[i]
+	}
+
+	return pool[:n], nil
+}
+
+// randIntn returns a uniform random integer in [0, n) for 0 < n <= 256,
+// using rejection sampling to avoid modulo bias.
+func randIntn(rng io.Reader, n int) (int, error) {
+	limit := 256 - 256%n
+	var b [1]byte
+	for {
+		if _, err := io.ReadFull(rng, b[:]); errors.Is(err, ErrEntropyUnavailable) {
+			return 0, err
+		} else if err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrEntropyUnavailable, err)
+		}
+		if int(b[0]) < limit {
+			return int(b[0]) % n, nil
+		}
+	}
+}
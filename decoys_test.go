@@ -0,0 +1,86 @@
+package shamir
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitWithDecoys(t *testing.T) {
+	secret := []byte("deniable secret")
+
+	shares, manifest, err := SplitWithDecoys(secret, 4, 3, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 10 || len(manifest.Custodians) != 10 {
+		t.Fatalf("expected 10 shares, got %d", len(shares))
+	}
+
+	decoys := 0
+	indexes := make(map[byte]bool)
+	var setID SetID
+	for i, share := range shares {
+		s, err := ParseShare(share)
+		if err != nil {
+			t.Fatalf("share %d is not a valid envelope: %v", i, err)
+		}
+		if len(share) != len(shares[0]) {
+			t.Fatal("decoys must have the same length as real shares")
+		}
+		if i == 0 {
+			setID = s.SetID
+		} else if s.SetID != setID {
+			t.Fatal("decoys must carry the same set ID as real shares")
+		}
+		if indexes[s.Index] {
+			t.Fatalf("duplicate x-coordinate %d", s.Index)
+		}
+		indexes[s.Index] = true
+		if manifest.Custodians[i].Decoy {
+			decoys++
+		}
+	}
+	if decoys != 6 {
+		t.Fatalf("expected 6 decoys flagged in manifest, got %d", decoys)
+	}
+
+	genuine := manifest.RemoveDecoys(shares)
+	if len(genuine) != 4 {
+		t.Fatalf("expected 4 real shares, got %d", len(genuine))
+	}
+
+	parsed, err := parseEnvelopes(genuine[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	reconstructed, err := combineEnvelopes(parsed, engine{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("reconstruction from real shares failed")
+	}
+}
+
+func TestSplitWithDecoysValidation(t *testing.T) {
+	if _, _, err := SplitWithDecoys([]byte("s"), 3, 2, -1); err == nil {
+		t.Fatal("expected error for negative decoys")
+	}
+	if _, _, err := SplitWithDecoys([]byte("s"), 200, 2, 100); err == nil {
+		t.Fatal("expected error for more than 255 shares")
+	}
+}
+
+func TestRandomXCoords(t *testing.T) {
+	xs, err := randomXCoords(255, zeroReader{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[byte]bool)
+	for _, x := range xs {
+		if x == 0 || seen[x] {
+			t.Fatalf("invalid or duplicate x-coordinate %d", x)
+		}
+		seen[x] = true
+	}
+}
@@ -0,0 +1,107 @@
+package shamir
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// deterministicInfo is the HKDF context string for SplitDeterministic's coefficient stream.
+const deterministicInfo = "go-shamir deterministic split v1"
+
+// MinSeedSize is the minimum seed length accepted by SplitDeterministic.
+const MinSeedSize = 16
+
+// hkdfBlockSize is the largest output of a single HKDF-SHA256 expansion.
+const hkdfBlockSize = 255 * sha256.Size
+
+// SplitDeterministic splits a secret like Split, but derives the polynomial
+// coefficients from seed with HKDF-SHA256 instead of reading crypto/rand, so
+// the same secret, threshold and seed always produce the same shares.
+//
+// The coefficients do not depend on parts: splitting again with more parts
+// yields the original shares plus new ones, and a lost share can be re-issued
+// by repeating the split without invalidating the shares already handed out.
+//
+// The shares are only as unpredictable as the seed. It must be at least
+// MinSeedSize bytes of key material kept as secret as the secret itself; anyone
+// who learns it and threshold-1 shares can recover the secret.
+func SplitDeterministic(secret []byte, parts, threshold int, seed []byte) ([][]byte, error) {
+	if len(seed) < MinSeedSize {
+		return nil, NewValidationError("seed", len(seed), "shamir: seed must be at least 16 bytes")
+	}
+
+	o := newOptions([]Option{WithParts(parts), WithThreshold(threshold)})
+	xCoords, err := o.coordinates(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	rng, err := newSeedStream(seed, secret, threshold)
+	if err != nil {
+		return nil, err
+	}
+	defer rng.wipe()
+
+	// The streaming strategy draws coefficients one window at a time, which
+	// would make the shares depend on the secret size thresholds; every other
+	// strategy reads them in the same order.
+	eng := o.engine()
+	if strategy, _ := eng.resolve(len(secret)); strategy == StrategyStreaming {
+		eng.strategy = StrategyParallel
+	}
+	return splitAt(secret, xCoords, threshold, rng, eng)
+}
+
+// seedStream is an io.Reader producing the HKDF-SHA256 expansion of a seed in
+// counter-numbered blocks.
+type seedStream struct {
+	prk   []byte
+	info  []byte // deterministicInfo || threshold
+	block uint64
+	buf   []byte
+	off   int
+}
+
+// newSeedStream keys a coefficient stream from seed. The secret is mixed in
+// so that reusing a seed for different secrets does not reuse coefficients,
+// and the threshold so that different thresholds get unrelated polynomials.
+func newSeedStream(seed, secret []byte, threshold int) (*seedStream, error) {
+	ikm := make([]byte, 0, len(seed)+len(secret))
+	ikm = append(append(ikm, seed...), secret...)
+	defer secureZeroBytes(ikm)
+
+	prk, err := hkdf.Extract(sha256.New, ikm, nil)
+	if err != nil {
+		return nil, fmt.Errorf("shamir: failed to derive coefficient key: %w", err)
+	}
+	info := binary.BigEndian.AppendUint16([]byte(deterministicInfo), uint16(threshold))
+	return &seedStream{prk: prk, info: info}, nil
+}
+
+func (s *seedStream) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if s.off == len(s.buf) {
+			secureZeroBytes(s.buf)
+			info := binary.BigEndian.AppendUint64(append([]byte(nil), s.info...), s.block)
+			buf, err := hkdf.Expand(sha256.New, s.prk, string(info), hkdfBlockSize)
+			if err != nil {
+				return n, fmt.Errorf("shamir: failed to expand seed: %w", err)
+			}
+			s.buf, s.off = buf, 0
+			s.block++
+		}
+		copied := copy(p[n:], s.buf[s.off:])
+		s.off += copied
+		n += copied
+	}
+	return n, nil
+}
+
+// wipe clears the key and any buffered output.
+func (s *seedStream) wipe() {
+	secureZeroBytes(s.prk)
+	secureZeroBytes(s.buf)
+}
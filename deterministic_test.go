@@ -0,0 +1,126 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestSplitDeterministic(t *testing.T) {
+	secret := []byte("provisioned API token")
+	seed := bytes.Repeat([]byte{0x5a}, 32)
+
+	first, err := SplitDeterministic(secret, 5, 3, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := SplitDeterministic(secret, 5, 3, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range first {
+		if !bytes.Equal(first[i], second[i]) {
+			t.Fatalf("share %d differs between identical splits", i)
+		}
+	}
+
+	reconstructed, err := Combine([][]byte{first[4], first[0], first[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+
+	t.Run("known answer", func(t *testing.T) {
+		// Pins the derivation so that upgrades keep re-issuing the same shares.
+		const want = "0156e2702e69de114de8c905504a3db605a2baf8ed2f"
+		if got := hex.EncodeToString(first[0]); got != want {
+			t.Fatalf("share 1 = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("lost share can be re-issued", func(t *testing.T) {
+		more, err := SplitDeterministic(secret, 7, 3, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range first {
+			if !bytes.Equal(first[i], more[i]) {
+				t.Fatalf("share %d changed when adding parts", i)
+			}
+		}
+		reconstructed, err := Combine([][]byte{first[1], more[5], more[6]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(reconstructed, secret) {
+			t.Fatal("re-issued shares do not combine with the originals")
+		}
+	})
+
+	t.Run("inputs change the polynomial", func(t *testing.T) {
+		otherSeed := bytes.Repeat([]byte{0xa5}, 32)
+		otherSecret := []byte("provisioned API tokeN")
+		for name, shares := range map[string]func() ([][]byte, error){
+			"seed":      func() ([][]byte, error) { return SplitDeterministic(secret, 5, 3, otherSeed) },
+			"threshold": func() ([][]byte, error) { return SplitDeterministic(secret, 5, 4, seed) },
+			"secret":    func() ([][]byte, error) { return SplitDeterministic(otherSecret, 5, 3, seed) },
+		} {
+			other, err := shares()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Only the last byte of the secret differs, so identical
+			// coefficients would leave the leading bytes equal.
+			if bytes.Equal(first[0][1:5], other[0][1:5]) {
+				t.Errorf("changing the %s reused the coefficients", name)
+			}
+		}
+	})
+
+	t.Run("short seed", func(t *testing.T) {
+		_, err := SplitDeterministic(secret, 5, 3, make([]byte, MinSeedSize-1))
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Field != "seed" {
+			t.Fatalf("expected seed ValidationError, got %v", err)
+		}
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		_, err := SplitDeterministic(secret, 2, 3, seed)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Field != "threshold" {
+			t.Fatalf("expected threshold ValidationError, got %v", err)
+		}
+	})
+}
+
+func TestSeedStreamCrossesBlocks(t *testing.T) {
+	stream, err := newSeedStream([]byte("0123456789abcdef"), nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.wipe()
+
+	whole := make([]byte, 2*hkdfBlockSize+10)
+	if _, err := stream.Read(whole); err != nil {
+		t.Fatal(err)
+	}
+
+	again, _ := newSeedStream([]byte("0123456789abcdef"), nil, 2)
+	defer again.wipe()
+	pieces := make([]byte, 0, len(whole))
+	chunk := make([]byte, 1000)
+	for len(pieces) < len(whole) {
+		n, _ := again.Read(chunk[:min(len(chunk), len(whole)-len(pieces))])
+		pieces = append(pieces, chunk[:n]...)
+	}
+	if !bytes.Equal(whole, pieces) {
+		t.Fatal("stream output depends on read sizes")
+	}
+	if bytes.Equal(whole[:64], whole[hkdfBlockSize:hkdfBlockSize+64]) {
+		t.Fatal("consecutive blocks repeat")
+	}
+}
@@ -0,0 +1,378 @@
+// Package dkg implements distributed key generation over GF(256): n parties
+// jointly create a random secret that none of them ever sees, each ending up
+// with a raw share of it that shamir.Combine accepts. There is no trusted
+// dealer; every party deals a random contribution and the secret is the sum
+// (XOR) of the contributions of the qualified dealers.
+//
+// The protocol runs in three rounds over authenticated private channels plus
+// a broadcast channel on which every party sees the same messages:
+//
+//	Round1    each party deals a random sharing to every party (privately,
+//	          including itself) and broadcasts a Commitment to the deals
+//	Round2    each party checks its deals against the commitments and
+//	          broadcasts a Response: complaints about dealers whose deal was
+//	          missing or did not match, and masked evaluations for the rest
+//	Finalize  each party disqualifies every dealer with a complaint or whose
+//	          masked evaluations do not lie on one polynomial of degree below
+//	          the threshold, and sums its deals from the remaining dealers
+//
+// GF(256) has no group in which to publish Feldman commitments, so dealers
+// prove their sharing consistent with a masked degree check instead. Along
+// with its share of the contribution f, every deal carries shares of Checks
+// random mask polynomials mₖ. The challenge rₖ is derived from all
+// commitments, so it is fixed only after every dealer is bound to its deals.
+// Each party then broadcasts mₖ(x) + rₖ·f(x) for its x. These values reveal
+// nothing about f, because mₖ is uniformly random. If f has degree threshold
+// or more, they lie on a polynomial of degree below the threshold for at most
+// one value of each rₖ. A cheating dealer therefore passes with probability
+// at most 255⁻ᶜʰᵉᶜᵏˢ.
+//
+// All parties reach the same qualified set from the broadcast messages alone.
+// The secret is uniformly random and unknown to any coalition below the
+// threshold as long as one honest dealer is qualified. The protocol does not
+// attribute blame: a party that lies in its Response can get an honest dealer
+// disqualified, and a run in which every dealer is disqualified fails with
+// ErrNoQualifiedDealers.
+package dkg
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+
+	shamir "github.com/morizta/go-shamir"
+	"github.com/morizta/go-shamir/gf256"
+)
+
+// Checks is the number of masked degree checks per dealer.
+const Checks = 16
+
+// saltSize is the length of the random salt that makes deal digests hiding.
+const saltSize = 32
+
+var (
+	// ErrInvalidConfig indicates an invalid participant set, threshold or secret size.
+	ErrInvalidConfig = errors.New("dkg: invalid configuration")
+
+	// ErrInvalidMessage indicates a malformed message, or one from or for an
+	// unknown or duplicated party.
+	ErrInvalidMessage = errors.New("dkg: invalid message")
+
+	// ErrRoundOrder indicates that a round was run out of order or twice.
+	ErrRoundOrder = errors.New("dkg: round run out of order")
+
+	// ErrNoQualifiedDealers indicates that every dealer was disqualified.
+	ErrNoQualifiedDealers = errors.New("dkg: no qualified dealers")
+)
+
+// Config describes one run of the protocol. All parties must use the same.
+type Config struct {
+	Participants []byte // x-coordinate of every party, distinct and non-zero
+	Threshold    int    // Shares required to reconstruct the secret
+	SecretSize   int    // Length of the secret in bytes
+	Session      []byte // Identifier unique to this run, agreed beforehand
+}
+
+// Commitment is a dealer's broadcast binding it to its deals: Digests[i] is
+// the digest of the deal for Participants[i].
+type Commitment struct {
+	Dealer  byte
+	Digests [][sha256.Size]byte
+}
+
+// Deal is a dealer's private message to one recipient. Its payload holds a
+// salt, the recipient's share of the dealer's contribution and its shares of
+// the mask polynomials; it must only be sent to the recipient.
+type Deal struct {
+	Dealer    byte
+	Recipient byte
+	Payload   []byte
+}
+
+// Response is a party's broadcast after checking its deals. Masked[i] holds
+// its masked evaluations for the dealer Participants[i], nil for the dealers
+// it complains about.
+type Response struct {
+	From       byte
+	Complaints []byte   // Dealers whose deal was missing or did not match its digest
+	Masked     [][]byte // Checks masked evaluations of SecretSize bytes each
+}
+
+// Result is the outcome of a run for one party.
+type Result struct {
+	Share     []byte // Raw share of the secret, as from shamir.Split
+	Qualified []byte // Dealers whose contributions make up the secret, ascending
+}
+
+// Party runs the protocol for one participant. It is not safe for concurrent
+// use.
+type Party struct {
+	cfg   Config
+	self  byte
+	round int
+
+	deals [][]byte // Payloads received, by dealer index, from Round2
+}
+
+// NewParty returns the party with x-coordinate self in the run described by
+// cfg.
+func NewParty(cfg Config, self byte) (*Party, error) {
+	if len(cfg.Participants) < 2 || len(cfg.Participants) > 255 {
+		return nil, fmt.Errorf("%w: need 2 to 255 participants", ErrInvalidConfig)
+	}
+	var seen [256]bool
+	for _, x := range cfg.Participants {
+		if x == 0 || seen[x] {
+			return nil, fmt.Errorf("%w: participant x-coordinates must be distinct and non-zero", ErrInvalidConfig)
+		}
+		seen[x] = true
+	}
+	if cfg.Threshold < 2 || cfg.Threshold > len(cfg.Participants) {
+		return nil, fmt.Errorf("%w: threshold must be between 2 and the number of participants", ErrInvalidConfig)
+	}
+	if cfg.SecretSize < 1 {
+		return nil, fmt.Errorf("%w: secret size must be positive", ErrInvalidConfig)
+	}
+	if len(cfg.Session) == 0 {
+		return nil, fmt.Errorf("%w: session identifier required", ErrInvalidConfig)
+	}
+	if !seen[self] {
+		return nil, fmt.Errorf("%w: %d is not a participant", ErrInvalidConfig, self)
+	}
+
+	cfg.Participants = slices.Clone(cfg.Participants)
+	cfg.Session = slices.Clone(cfg.Session)
+	return &Party{cfg: cfg, self: self}, nil
+}
+
+// Round1 deals the party's random contribution. It returns the commitment to
+// broadcast and one deal per participant, including the party itself, to send
+// privately to its recipient.
+func (p *Party) Round1() (Commitment, []Deal, error) {
+	if p.round != 0 {
+		return Commitment{}, nil, ErrRoundOrder
+	}
+	n, size := len(p.cfg.Participants), p.cfg.SecretSize
+
+	// Row 0 is the contribution, rows 1 through Checks the masks.
+	sharings := make([][][]byte, 1+Checks)
+	defer func() {
+		for _, shares := range sharings {
+			wipeAll(shares)
+		}
+	}()
+	secret := make([]byte, size)
+	defer clear(secret)
+	for row := range sharings {
+		if _, err := rand.Read(secret); err != nil {
+			return Commitment{}, nil, err
+		}
+		shares, err := shamir.Deal(secret, p.cfg.Participants, p.cfg.Threshold)
+		if err != nil {
+			return Commitment{}, nil, err
+		}
+		sharings[row] = shares
+	}
+
+	commitment := Commitment{Dealer: p.self, Digests: make([][sha256.Size]byte, n)}
+	deals := make([]Deal, n)
+	for i, x := range p.cfg.Participants {
+		payload := make([]byte, saltSize, saltSize+(1+Checks)*size)
+		if _, err := rand.Read(payload); err != nil {
+			return Commitment{}, nil, err
+		}
+		for _, shares := range sharings {
+			payload = append(payload, shares[i][shamir.ShareOverhead:]...)
+		}
+		deals[i] = Deal{Dealer: p.self, Recipient: x, Payload: payload}
+		commitment.Digests[i] = p.digest(p.self, x, payload)
+	}
+	p.round = 1
+	return commitment, deals, nil
+}
+
+// Round2 checks the deals sent to this party against the broadcast
+// commitments of every participant, its own included, and returns the
+// response to broadcast. Deals for other recipients are ignored; missing or
+// mismatched deals become complaints.
+func (p *Party) Round2(commitments []Commitment, deals []Deal) (Response, error) {
+	if p.round != 1 {
+		return Response{}, ErrRoundOrder
+	}
+	n, size := len(p.cfg.Participants), p.cfg.SecretSize
+
+	byDealer := make([]Commitment, n)
+	for _, c := range commitments {
+		i := p.index(c.Dealer)
+		if i < 0 || byDealer[i].Digests != nil || len(c.Digests) != n {
+			return Response{}, fmt.Errorf("%w: commitment from %d", ErrInvalidMessage, c.Dealer)
+		}
+		byDealer[i] = c
+	}
+	for i, c := range byDealer {
+		if c.Digests == nil {
+			return Response{}, fmt.Errorf("%w: no commitment from %d", ErrInvalidMessage, p.cfg.Participants[i])
+		}
+	}
+
+	self := p.index(p.self)
+	received := make([][]byte, n)
+	for _, d := range deals {
+		i := p.index(d.Dealer)
+		if d.Recipient != p.self || i < 0 || received[i] != nil {
+			continue
+		}
+		want := byDealer[i].Digests[self]
+		if len(d.Payload) == saltSize+(1+Checks)*size && p.digest(d.Dealer, p.self, d.Payload) == want {
+			received[i] = slices.Clone(d.Payload)
+		}
+	}
+
+	challenge := p.challenge(byDealer)
+	resp := Response{From: p.self, Masked: make([][]byte, n)}
+	tmp := make([]byte, size)
+	for i, payload := range received {
+		if payload == nil {
+			resp.Complaints = append(resp.Complaints, p.cfg.Participants[i])
+			continue
+		}
+		share := payload[saltSize : saltSize+size]
+		masked := make([]byte, Checks*size)
+		for k := 0; k < Checks; k++ {
+			mask := payload[saltSize+(1+k)*size : saltSize+(2+k)*size]
+			gf256.MulSlice(tmp, share, challenge[k])
+			gf256.AddSlice(masked[k*size:(k+1)*size], mask, tmp)
+		}
+		resp.Masked[i] = masked
+	}
+	clear(tmp)
+
+	p.deals = received
+	p.round = 2
+	return resp, nil
+}
+
+// Finalize determines the qualified dealers from every participant's
+// Response, its own included, and returns the party's share of the secret.
+func (p *Party) Finalize(responses []Response) (*Result, error) {
+	if p.round != 2 {
+		return nil, ErrRoundOrder
+	}
+	n, size := len(p.cfg.Participants), p.cfg.SecretSize
+
+	byFrom := make([]*Response, n)
+	for r := range responses {
+		resp := &responses[r]
+		i := p.index(resp.From)
+		if i < 0 || byFrom[i] != nil || len(resp.Masked) != n {
+			return nil, fmt.Errorf("%w: response from %d", ErrInvalidMessage, resp.From)
+		}
+		byFrom[i] = resp
+	}
+	for i, resp := range byFrom {
+		if resp == nil {
+			return nil, fmt.Errorf("%w: no response from %d", ErrInvalidMessage, p.cfg.Participants[i])
+		}
+	}
+
+	p.round = 3
+	defer wipeAll(p.deals)
+
+	var qualified []byte
+	share := make([]byte, shamir.ShareOverhead+size)
+	share[0] = p.self
+	for d, dealer := range p.cfg.Participants {
+		if !p.consistent(byFrom, d) {
+			continue
+		}
+		qualified = append(qualified, dealer)
+		gf256.AddSlice(share[shamir.ShareOverhead:], share[shamir.ShareOverhead:], p.deals[d][saltSize:saltSize+size])
+	}
+	if len(qualified) == 0 {
+		return nil, ErrNoQualifiedDealers
+	}
+	slices.Sort(qualified)
+	return &Result{Share: share, Qualified: qualified}, nil
+}
+
+// consistent reports whether no party complained about dealer d and every
+// party's masked evaluations for d lie on polynomials of degree below the
+// threshold.
+func (p *Party) consistent(responses []*Response, d int) bool {
+	n, size, t := len(p.cfg.Participants), p.cfg.SecretSize, p.cfg.Threshold
+	dealer := p.cfg.Participants[d]
+	for _, resp := range responses {
+		if bytes.IndexByte(resp.Complaints, dealer) >= 0 || len(resp.Masked[d]) != Checks*size {
+			return false
+		}
+	}
+
+	// Predict the evaluations of the parties beyond the first t from the
+	// first t and compare.
+	weights := make([]byte, t)
+	predicted := make([]byte, Checks*size)
+	tmp := make([]byte, Checks*size)
+	for j := t; j < n; j++ {
+		gf256.LagrangeBasis(weights, p.cfg.Participants[:t], p.cfg.Participants[j])
+		clear(predicted)
+		for i := 0; i < t; i++ {
+			gf256.MulSlice(tmp, responses[i].Masked[d], weights[i])
+			gf256.AddSlice(predicted, predicted, tmp)
+		}
+		if !bytes.Equal(predicted, responses[j].Masked[d]) {
+			return false
+		}
+	}
+	return true
+}
+
+// index returns the position of x among the participants, or -1.
+func (p *Party) index(x byte) int {
+	return bytes.IndexByte(p.cfg.Participants, x)
+}
+
+// digest commits to the deal from dealer to recipient.
+func (p *Party) digest(dealer, recipient byte, payload []byte) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte("go-shamir dkg deal\x00"))
+	h.Write(p.cfg.Session)
+	h.Write([]byte{0, dealer, recipient})
+	h.Write(payload)
+	var out [sha256.Size]byte
+	h.Sum(out[:0])
+	return out
+}
+
+// challenge derives the non-zero check multipliers from the session and all
+// commitments.
+func (p *Party) challenge(commitments []Commitment) [Checks]byte {
+	h := sha256.New()
+	h.Write([]byte("go-shamir dkg challenge\x00"))
+	h.Write(p.cfg.Session)
+	h.Write([]byte{0, byte(p.cfg.Threshold)})
+	h.Write(p.cfg.Participants)
+	for _, c := range commitments {
+		for _, digest := range c.Digests {
+			h.Write(digest[:])
+		}
+	}
+	sum := h.Sum(nil)
+
+	var r [Checks]byte
+	copy(r[:], sum)
+	for k := range r {
+		if r[k] == 0 {
+			r[k] = 1 // Zero would check the mask alone; the bias is negligible
+		}
+	}
+	return r
+}
+
+func wipeAll(bs [][]byte) {
+	for _, b := range bs {
+		clear(b)
+	}
+}
@@ -0,0 +1,210 @@
+package dkg
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// run executes the protocol among all participants, letting tamper modify
+// the messages of round 1 before delivery.
+func run(t *testing.T, cfg Config, tamper func(commitments []Commitment, deals [][]Deal)) ([]*Result, error) {
+	t.Helper()
+	parties := make([]*Party, len(cfg.Participants))
+	for i, x := range cfg.Participants {
+		p, err := NewParty(cfg, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parties[i] = p
+	}
+
+	commitments := make([]Commitment, len(parties))
+	deals := make([][]Deal, len(parties)) // By dealer
+	for i, p := range parties {
+		c, d, err := p.Round1()
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitments[i], deals[i] = c, d
+	}
+	if tamper != nil {
+		tamper(commitments, deals)
+	}
+
+	responses := make([]Response, len(parties))
+	for i, p := range parties {
+		var inbox []Deal
+		for _, fromDealer := range deals {
+			for _, d := range fromDealer {
+				if d.Recipient == cfg.Participants[i] {
+					inbox = append(inbox, d)
+				}
+			}
+		}
+		resp, err := p.Round2(commitments, inbox)
+		if err != nil {
+			t.Fatal(err)
+		}
+		responses[i] = resp
+	}
+
+	results := make([]*Result, len(parties))
+	for i, p := range parties {
+		r, err := p.Finalize(responses)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = r
+	}
+	return results, nil
+}
+
+func testConfig() Config {
+	return Config{Participants: []byte{1, 2, 3, 4, 5}, Threshold: 3, SecretSize: 32, Session: []byte("test session")}
+}
+
+// checkShares checks that every threshold-sized quorum of the results
+// combines to the same secret and returns it.
+func checkShares(t *testing.T, results []*Result, threshold int) []byte {
+	t.Helper()
+	shares := make([][]byte, len(results))
+	for i, r := range results {
+		shares[i] = r.Share
+		if !bytes.Equal(r.Qualified, results[0].Qualified) {
+			t.Fatalf("parties disagree on the qualified set: %v and %v", r.Qualified, results[0].Qualified)
+		}
+	}
+	secret, err := shamir.Combine(shares[:threshold])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other, err := shamir.Combine(shares[len(shares)-threshold:]); err != nil || !bytes.Equal(other, secret) {
+		t.Fatalf("quorums disagree: %x and %x (%v)", secret, other, err)
+	}
+	if _, err := shamir.CombineStrict(shares, threshold); err != nil {
+		t.Fatalf("shares are inconsistent: %v", err)
+	}
+	return secret
+}
+
+func TestDKG(t *testing.T) {
+	cfg := testConfig()
+	results, err := run(t, cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(results[0].Qualified, cfg.Participants) {
+		t.Fatalf("Qualified = %v, want every participant", results[0].Qualified)
+	}
+	secret := checkShares(t, results, cfg.Threshold)
+	if bytes.Equal(secret, make([]byte, cfg.SecretSize)) {
+		t.Fatal("secret is zero")
+	}
+
+	// A second run yields an unrelated secret.
+	again, err := run(t, cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(checkShares(t, again, cfg.Threshold), secret) {
+		t.Fatal("two runs produced the same secret")
+	}
+}
+
+func TestInconsistentDealerDisqualified(t *testing.T) {
+	cfg := testConfig()
+	var digest func(dealer, recipient byte, payload []byte) [32]byte
+	if p, err := NewParty(cfg, 1); err == nil {
+		digest = p.digest
+	}
+
+	results, err := run(t, cfg, func(commitments []Commitment, deals [][]Deal) {
+		// Dealer 2 gives recipient 4 a share off its polynomial, and commits
+		// to it so the deal itself passes the digest check.
+		d := &deals[1][3]
+		d.Payload[saltSize] ^= 0x5a
+		commitments[1].Digests[3] = digest(d.Dealer, d.Recipient, d.Payload)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{1, 3, 4, 5}; !bytes.Equal(results[0].Qualified, want) {
+		t.Fatalf("Qualified = %v, want %v", results[0].Qualified, want)
+	}
+	checkShares(t, results, cfg.Threshold)
+}
+
+func TestMissingDealComplaint(t *testing.T) {
+	cfg := testConfig()
+	results, err := run(t, cfg, func(commitments []Commitment, deals [][]Deal) {
+		deals[4][0].Payload[0] ^= 1 // Dealer 5's deal to 1 no longer matches its digest
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{1, 2, 3, 4}; !bytes.Equal(results[2].Qualified, want) {
+		t.Fatalf("Qualified = %v, want %v", results[2].Qualified, want)
+	}
+	checkShares(t, results, cfg.Threshold)
+}
+
+func TestNoQualifiedDealers(t *testing.T) {
+	cfg := Config{Participants: []byte{7, 9}, Threshold: 2, SecretSize: 4, Session: []byte{1}}
+	_, err := run(t, cfg, func(commitments []Commitment, deals [][]Deal) {
+		for _, fromDealer := range deals {
+			fromDealer[0].Payload[0] ^= 1
+		}
+	})
+	if !errors.Is(err, ErrNoQualifiedDealers) {
+		t.Fatalf("got %v, want ErrNoQualifiedDealers", err)
+	}
+}
+
+func TestRoundOrderAndMessages(t *testing.T) {
+	cfg := testConfig()
+	p, err := NewParty(cfg, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Finalize(nil); !errors.Is(err, ErrRoundOrder) {
+		t.Errorf("Finalize first: got %v", err)
+	}
+	c, _, err := p.Round1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := p.Round1(); !errors.Is(err, ErrRoundOrder) {
+		t.Errorf("Round1 twice: got %v", err)
+	}
+	if _, err := p.Round2([]Commitment{c}, nil); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("missing commitments: got %v", err)
+	}
+	if _, err := p.Round2([]Commitment{c, c, c, c, c}, nil); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("duplicate commitments: got %v", err)
+	}
+}
+
+func TestNewPartyValidation(t *testing.T) {
+	good := testConfig()
+	for name, tc := range map[string]struct {
+		mutate func(*Config)
+		self   byte
+	}{
+		"one participant": {func(c *Config) { c.Participants = []byte{1} }, 1},
+		"zero x":          {func(c *Config) { c.Participants = []byte{0, 1, 2} }, 1},
+		"duplicate x":     {func(c *Config) { c.Participants = []byte{1, 2, 2} }, 1},
+		"threshold":       {func(c *Config) { c.Threshold = 6 }, 1},
+		"secret size":     {func(c *Config) { c.SecretSize = 0 }, 1},
+		"session":         {func(c *Config) { c.Session = nil }, 1},
+		"outsider":        {func(*Config) {}, 9},
+	} {
+		cfg := good
+		tc.mutate(&cfg)
+		if _, err := NewParty(cfg, tc.self); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: got %v, want ErrInvalidConfig", name, err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package shamir
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Entropy reads.
+//
+// A split needs threshold-1 random bytes per secret byte. crypto/rand blocks
+// until the kernel's pool is seeded, which on a freshly booted VM or container
+// can take long enough to stall a service's startup indefinitely. Splits
+// therefore read all of a polynomial's randomness before computing any share,
+// and until crypto/rand has delivered once in the process, give up with
+// ErrEntropyUnavailable after DefaultEntropyTimeout. Once seeded, crypto/rand
+// never blocks again, so later reads are synchronous and cost nothing extra.
+// WithEntropyTimeout or a context (WithContext) puts every read under a
+// watchdog instead, which is the only way to bound a WithRand source. A failed
+// split never returns shares; the coefficients and any partly computed shares
+// are wiped. Splits of very large secrets (StrategyStreaming) read randomness
+// one window at a time, so the timeout applies to each window.
+
+// DefaultEntropyTimeout is how long a split waits for crypto/rand before it
+// has been seeded, failing with ErrEntropyUnavailable.
+const DefaultEntropyTimeout = 10 * time.Second
+
+// WithEntropyTimeout sets how long every read of a split waits for the
+// WithRand source (crypto/rand by default) before failing with
+// ErrEntropyUnavailable. A duration of zero or less waits indefinitely. By
+// default only the reads before crypto/rand is first seeded are bounded, by
+// DefaultEntropyTimeout, and WithRand sources are read without a timeout.
+func WithEntropyTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			d = -1
+		}
+		o.entropyTimeout = d
+	}
+}
+
+// cryptoRandSeeded is set once a read from crypto/rand has succeeded, after
+// which default reads skip the watchdog.
+var cryptoRandSeeded atomic.Bool
+
+// readEntropy fills every buffer in bufs from rng, giving up when timeout
+// elapses or ctx is done. A timeout of 0 means DefaultEntropyTimeout until
+// crypto/rand is seeded and none afterwards, or for other sources; a negative
+// timeout means never. On error the buffers must no longer be touched: a read
+// still in progress may write to them, and release is called once it returns.
+func readEntropy(ctx context.Context, rng io.Reader, timeout time.Duration, bufs [][]byte, release func()) error {
+	if timeout == 0 {
+		if rng != rand.Reader || cryptoRandSeeded.Load() {
+			timeout = -1
+		} else {
+			timeout = DefaultEntropyTimeout
+		}
+	}
+
+	if timeout < 0 && ctx == nil {
+		err := readAll(rng, bufs)
+		if err != nil {
+			release()
+		}
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- readAll(rng, bufs) }()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	var cancelled <-chan struct{}
+	if ctx != nil {
+		cancelled = ctx.Done()
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			release()
+		} else if rng == rand.Reader {
+			cryptoRandSeeded.Store(true)
+		}
+		return err
+	case <-expired:
+		go func() { <-done; release() }()
+		return fmt.Errorf("%w: no randomness after %v", ErrEntropyUnavailable, timeout)
+	case <-cancelled:
+		go func() { <-done; release() }()
+		return ctx.Err()
+	}
+}
+
+// readAll fills every buffer in bufs from rng.
+func readAll(rng io.Reader, bufs [][]byte) error {
+	for _, buf := range bufs {
+		if _, err := io.ReadFull(rng, buf); err != nil {
+			return fmt.Errorf("%w: %w", ErrEntropyUnavailable, err)
+		}
+	}
+	return nil
+}
+
+// entropyReader applies an entropy timeout to every read from rng, for
+// consumers such as randomXCoords that draw a variable number of bytes.
+type entropyReader struct {
+	rng     io.Reader
+	timeout time.Duration
+}
+
+func (r entropyReader) Read(p []byte) (int, error) {
+	buf := make([]byte, len(p))
+	if err := readEntropy(nil, r.rng, r.timeout, [][]byte{buf}, func() { secureZeroBytes(buf) }); err != nil {
+		return 0, err
+	}
+	copy(p, buf)
+	secureZeroBytes(buf)
+	return len(p), nil
+}
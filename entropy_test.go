@@ -0,0 +1,134 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingReader blocks every read until unblock is closed, then fills p with
+// ones.
+type blockingReader struct{ unblock chan struct{} }
+
+func (r blockingReader) Read(p []byte) (int, error) {
+	<-r.unblock
+	for i := range p {
+		p[i] = 1
+	}
+	return len(p), nil
+}
+
+func TestSplitEntropyTimeout(t *testing.T) {
+	secret := []byte("early boot secret")
+	rng := blockingReader{make(chan struct{})}
+	defer close(rng.unblock)
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"default strategy", nil},
+		{"streaming", []Option{WithStrategy(StrategyStreaming)}},
+		{"random x-coordinates", []Option{WithRandomXCoordinates(true)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithParts(5), WithThreshold(3), WithRand(rng), WithEntropyTimeout(20 * time.Millisecond)}, tt.opts...)
+			start := time.Now()
+			shares, err := SplitWithOptions(secret, opts...)
+			if !errors.Is(err, ErrEntropyUnavailable) {
+				t.Fatalf("expected ErrEntropyUnavailable, got %v", err)
+			}
+			if shares != nil {
+				t.Fatal("shares returned alongside an error")
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("split took %v despite the timeout", elapsed)
+			}
+		})
+	}
+}
+
+func TestSplitEntropyFailure(t *testing.T) {
+	secret := []byte("secret")
+
+	shares, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithRand(failingReader{}))
+	if !errors.Is(err, ErrEntropyUnavailable) || shares != nil {
+		t.Fatalf("expected ErrEntropyUnavailable and no shares, got %v, %v", shares, err)
+	}
+	if _, err := splitVault(secret, 3, 2, failingReader{}); !errors.Is(err, ErrEntropyUnavailable) {
+		t.Fatalf("SplitVault: expected ErrEntropyUnavailable, got %v", err)
+	}
+	if _, err := zeroSharing([]byte{1, 2}, len(secret), 2, failingReader{}); !errors.Is(err, ErrEntropyUnavailable) {
+		t.Fatalf("zeroSharing: expected ErrEntropyUnavailable, got %v", err)
+	}
+}
+
+func TestSplitWithoutEntropyTimeout(t *testing.T) {
+	secret := []byte("patient secret")
+	rng := blockingReader{make(chan struct{})}
+	time.AfterFunc(50*time.Millisecond, func() { close(rng.unblock) })
+
+	shares, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithRand(rng), WithEntropyTimeout(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reconstructed, err := Combine(shares[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("reconstruction failed")
+	}
+}
+
+func TestDefaultEntropyReadsAreSynchronous(t *testing.T) {
+	if _, err := Split([]byte("seeds crypto/rand"), 3, 2); err != nil {
+		t.Fatal(err)
+	}
+	if !cryptoRandSeeded.Load() {
+		t.Fatal("a successful read did not mark crypto/rand as seeded")
+	}
+
+	buf := make([]byte, 32)
+	bufs := [][]byte{buf}
+	release := func() {}
+	if allocs := testing.AllocsPerRun(100, func() {
+		if err := readEntropy(nil, rand.Reader, 0, bufs, release); err != nil {
+			t.Fatal(err)
+		}
+	}); allocs != 0 {
+		t.Errorf("default read from seeded crypto/rand allocates %v times, want 0", allocs)
+	}
+}
+
+func TestReadEntropyReleasesAbandonedBuffers(t *testing.T) {
+	rng := blockingReader{make(chan struct{})}
+	buf := make([]byte, 8)
+	released := make(chan struct{})
+
+	err := readEntropy(nil, rng, time.Millisecond, [][]byte{buf}, func() {
+		secureZeroBytes(buf)
+		close(released)
+	})
+	if !errors.Is(err, ErrEntropyUnavailable) {
+		t.Fatalf("expected ErrEntropyUnavailable, got %v", err)
+	}
+	select {
+	case <-released:
+		t.Fatal("buffers released while the read was still in progress")
+	default:
+	}
+
+	close(rng.unblock)
+	select {
+	case <-released:
+	case <-time.After(5 * time.Second):
+		t.Fatal("buffers not released after the abandoned read returned")
+	}
+	if !bytes.Equal(buf, make([]byte, 8)) {
+		t.Fatal("abandoned buffer not wiped")
+	}
+}
@@ -0,0 +1,439 @@
+package shamir
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"time"
+)
+
+// Share envelope format.
+//
+// Shares produced by the metadata-aware APIs (such as SplitKey) are wrapped in
+// a small self-describing envelope:
+//
+//	offset  size  field
+//	0       3     magic: 0x00 'S' 'H'
+//	3       1     format version (currently 1)
+//	4       1     flags, see envelopeCriticalFlags
+//	5       1     threshold
+//	6       1     x-coordinate
+//	7       2     metadata length M (big-endian)
+//	9       M     metadata records: tag (1), length (2, big-endian), value
+//	9+M     n     payload: one y-value per secret byte
+//	end-4   4     CRC32 (IEEE) of all preceding bytes, big-endian
+//
+// A raw share from Split never starts with 0x00 because x-coordinates are never
+// zero, so the two formats cannot be confused. Unknown metadata records and
+// non-critical flags are preserved when a share is decoded and re-encoded, so
+// older readers can pass newer shares through unchanged.
+
+const (
+	// EnvelopeVersion is the envelope format version written by this package.
+	EnvelopeVersion = 1
+
+	envelopeHeaderSize   = 9
+	envelopeChecksumSize = 4
+	maxMetadataSize      = 0xffff
+)
+
+// envelopeMagic identifies an enveloped share.
+var envelopeMagic = [3]byte{0x00, 'S', 'H'}
+
+// envelopeCriticalFlags are the flag bits a reader must understand. A later
+// release that changes how the payload is interpreted sets one of them, and
+// this release refuses the share instead of combining garbage. The other bits
+// announce optional features; readers that do not know them ignore them and
+// preserve them on re-encoding. No flags are defined yet.
+const envelopeCriticalFlags = 0x0f
+
+// Metadata record tags.
+const (
+	tagAlgorithm  byte = 1 // Intended key algorithm, see SplitKey
+	tagPurpose    byte = 2 // Purpose the sharing is bound to, see WithPurpose
+	tagPurposeMAC byte = 3 // Secret-keyed MAC binding the purpose to the secret
+	tagSetID      byte = 4 // Random identifier shared by all shares of one split
+	tagLabel      byte = 5 // Operational label, e.g. the custodian holding the share
+	tagCreatedAt  byte = 6 // Creation time, Unix seconds (8 bytes, big-endian)
+	tagNotBefore  byte = 7 // Start of the validity window, Unix seconds (8 bytes, big-endian)
+	tagNotAfter   byte = 8 // End of the validity window, Unix seconds (8 bytes, big-endian)
+	tagCommitment byte = 9 // Salted SHA-256 commitment to the secret, see WithCommitment
+
+	// lastKnownTag is the highest tag this version decodes into Share fields.
+	lastKnownTag = tagCommitment
+)
+
+// Share is a decoded share envelope.
+type Share struct {
+	Version   byte      // Envelope format version
+	Threshold int       // Number of shares required for reconstruction
+	Index     byte      // x-coordinate of the share (never zero)
+	Algorithm string    // Intended key algorithm, empty if not recorded
+	Purpose   string    // Purpose the sharing is bound to, empty if unbound
+	SetID     SetID     // Identifies the split the share belongs to, zero if not recorded
+	Label     string    // Operational label such as "alice@ops" or "HSM-2", empty if none
+	CreatedAt time.Time // When the share was created (second precision), zero if not recorded
+	NotBefore time.Time // Start of the share's validity window, zero if unbounded; see WithValidity
+	NotAfter  time.Time // End of the share's validity window, zero if unbounded
+	Payload   []byte    // y-values, one per secret byte
+
+	purposeMAC []byte       // MAC of Purpose keyed from the secret
+	commitment []byte       // Salted commitment to the secret, nil if none
+	flags      byte         // Non-critical flags this version does not understand
+	extra      []metaRecord // Metadata records this version does not understand
+}
+
+// metaRecord is a single tag-length-value metadata entry.
+type metaRecord struct {
+	tag   byte
+	value []byte
+}
+
+// IsEnvelope reports whether data starts with the share envelope magic.
+func IsEnvelope(data []byte) bool {
+	return len(data) >= len(envelopeMagic) &&
+		data[0] == envelopeMagic[0] && data[1] == envelopeMagic[1] && data[2] == envelopeMagic[2]
+}
+
+// ParseShare decodes and verifies an enveloped share.
+func ParseShare(data []byte) (*Share, error) {
+	s := new(Share)
+	if err := s.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// MarshalBinary encodes the share in the envelope format.
+func (s *Share) MarshalBinary() ([]byte, error) {
+	if s.Threshold < 1 || s.Threshold > 255 {
+		return nil, NewValidationError("threshold", s.Threshold, "shamir: threshold must be between 1 and 255")
+	}
+	if s.Index == 0 {
+		return nil, NewValidationError("index", 0, "shamir: share index cannot be zero")
+	}
+	if len(s.Payload) == 0 {
+		return nil, ErrEmptySecret
+	}
+
+	metadata, err := s.encodeMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, envelopeHeaderSize+len(metadata)+len(s.Payload)+envelopeChecksumSize)
+	out = append(out, envelopeMagic[:]...)
+	out = append(out, EnvelopeVersion, s.flags&^envelopeCriticalFlags, byte(s.Threshold), s.Index)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(metadata)))
+	out = append(out, metadata...)
+	out = append(out, s.Payload...)
+	out = binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out))
+
+	return out, nil
+}
+
+// UnmarshalBinary decodes and verifies an enveloped share.
+// The payload is copied, so data may be reused or wiped afterwards.
+func (s *Share) UnmarshalBinary(data []byte) error {
+	if !IsEnvelope(data) {
+		return ErrInvalidEnvelope
+	}
+	if len(data) < envelopeHeaderSize+envelopeChecksumSize+1 {
+		return fmt.Errorf("%w: too short", ErrInvalidEnvelope)
+	}
+	if data[3] != EnvelopeVersion {
+		return fmt.Errorf("%w: version %d", ErrUnsupportedVersion, data[3])
+	}
+
+	body := data[:len(data)-envelopeChecksumSize]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(body):]) {
+		return ErrIntegrityCheckFailed
+	}
+
+	if flags := data[4] & envelopeCriticalFlags; flags != 0 {
+		return fmt.Errorf("%w: flags %#x", ErrUnsupportedVersion, flags)
+	}
+	threshold := int(data[5])
+	index := data[6]
+	if threshold < 1 || index == 0 {
+		return fmt.Errorf("%w: invalid threshold or index", ErrInvalidEnvelope)
+	}
+
+	metaLen := int(binary.BigEndian.Uint16(data[7:9]))
+	if envelopeHeaderSize+metaLen >= len(body) {
+		return fmt.Errorf("%w: metadata exceeds share length", ErrInvalidEnvelope)
+	}
+
+	decoded := Share{
+		Version:   data[3],
+		Threshold: threshold,
+		Index:     index,
+		flags:     data[4],
+	}
+	if err := decoded.decodeMetadata(body[envelopeHeaderSize : envelopeHeaderSize+metaLen]); err != nil {
+		return err
+	}
+	decoded.Payload = append([]byte(nil), body[envelopeHeaderSize+metaLen:]...)
+
+	*s = decoded
+	return nil
+}
+
+// encodeMetadata serializes the known metadata fields followed by any preserved records.
+func (s *Share) encodeMetadata() ([]byte, error) {
+	records := make([]metaRecord, 0, int(lastKnownTag)+len(s.extra))
+	if s.Algorithm != "" {
+		records = append(records, metaRecord{tagAlgorithm, []byte(s.Algorithm)})
+	}
+	if s.Purpose != "" {
+		records = append(records, metaRecord{tagPurpose, []byte(s.Purpose)})
+	}
+	if len(s.purposeMAC) > 0 {
+		records = append(records, metaRecord{tagPurposeMAC, s.purposeMAC})
+	}
+	if !s.SetID.IsZero() {
+		records = append(records, metaRecord{tagSetID, s.SetID[:]})
+	}
+	if s.Label != "" {
+		records = append(records, metaRecord{tagLabel, []byte(s.Label)})
+	}
+	if !s.CreatedAt.IsZero() {
+		records = append(records, metaRecord{tagCreatedAt, binary.BigEndian.AppendUint64(nil, uint64(s.CreatedAt.Unix()))})
+	}
+	if !s.NotBefore.IsZero() {
+		records = append(records, metaRecord{tagNotBefore, binary.BigEndian.AppendUint64(nil, uint64(s.NotBefore.Unix()))})
+	}
+	if !s.NotAfter.IsZero() {
+		records = append(records, metaRecord{tagNotAfter, binary.BigEndian.AppendUint64(nil, uint64(s.NotAfter.Unix()))})
+	}
+	if len(s.commitment) > 0 {
+		records = append(records, metaRecord{tagCommitment, s.commitment})
+	}
+	records = append(records, s.extra...)
+
+	var out []byte
+	for _, r := range records {
+		if len(r.value) > maxMetadataSize {
+			return nil, NewValidationError("metadata", int(r.tag), "shamir: metadata value too long")
+		}
+		out = append(out, r.tag)
+		out = binary.BigEndian.AppendUint16(out, uint16(len(r.value)))
+		out = append(out, r.value...)
+	}
+	if len(out) > maxMetadataSize {
+		return nil, NewValidationError("metadata", len(out), "shamir: metadata too long")
+	}
+
+	return out, nil
+}
+
+// decodeMetadata parses metadata records into the known fields, keeping unknown ones.
+func (s *Share) decodeMetadata(data []byte) error {
+	for len(data) > 0 {
+		if len(data) < 3 {
+			return fmt.Errorf("%w: truncated metadata record", ErrInvalidEnvelope)
+		}
+		tag := data[0]
+		n := int(binary.BigEndian.Uint16(data[1:3]))
+		if 3+n > len(data) {
+			return fmt.Errorf("%w: truncated metadata record", ErrInvalidEnvelope)
+		}
+		value := data[3 : 3+n]
+		data = data[3+n:]
+
+		switch tag {
+		case tagAlgorithm:
+			s.Algorithm = string(value)
+		case tagPurpose:
+			s.Purpose = string(value)
+		case tagPurposeMAC:
+			s.purposeMAC = append([]byte(nil), value...)
+		case tagSetID:
+			if len(value) != len(s.SetID) {
+				return fmt.Errorf("%w: invalid set ID length", ErrInvalidEnvelope)
+			}
+			copy(s.SetID[:], value)
+		case tagLabel:
+			s.Label = string(value)
+		case tagCreatedAt:
+			if len(value) != 8 {
+				return fmt.Errorf("%w: invalid creation time length", ErrInvalidEnvelope)
+			}
+			s.CreatedAt = time.Unix(int64(binary.BigEndian.Uint64(value)), 0).UTC()
+		case tagNotBefore, tagNotAfter:
+			if len(value) != 8 {
+				return fmt.Errorf("%w: invalid validity time length", ErrInvalidEnvelope)
+			}
+			t := time.Unix(int64(binary.BigEndian.Uint64(value)), 0).UTC()
+			if tag == tagNotBefore {
+				s.NotBefore = t
+			} else {
+				s.NotAfter = t
+			}
+		case tagCommitment:
+			s.commitment = append([]byte(nil), value...)
+		default:
+			s.extra = append(s.extra, metaRecord{tag, append([]byte(nil), value...)})
+		}
+	}
+	return nil
+}
+
+// splitEnvelopes splits a secret as configured by o and wraps every share in an
+// envelope built from the template; the Threshold, Index, Payload and purpose
+// MAC fields of the template are filled in here, Label too when WithLabels was
+// given, the validity window when WithValidity was, the commitment when
+// WithCommitment was, and SetID with a random ID unless the template sets one.
+func splitEnvelopes(secret []byte, o *options, template Share) ([][]byte, error) {
+	xCoords, err := o.coordinates(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.checkLabels(len(xCoords)); err != nil {
+		return nil, err
+	}
+	if err := o.checkValidityWindow(); err != nil {
+		return nil, err
+	}
+	raw, err := splitAt(secret, xCoords, o.threshold, o.rand, o.engine())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, share := range raw {
+			secureZeroBytes(share)
+		}
+	}()
+
+	template.Threshold = o.threshold
+	template.NotBefore, template.NotAfter = o.notBefore, o.notAfter
+	if template.SetID.IsZero() {
+		if template.SetID, err = newSetID(); err != nil {
+			return nil, err
+		}
+	}
+	if template.Purpose != "" {
+		if template.purposeMAC, err = computePurposeMAC(secret, template.Purpose); err != nil {
+			return nil, err
+		}
+	}
+	if o.commitment {
+		if template.commitment, err = newCommitment(secret, o.rand); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([][]byte, len(raw))
+	for i, share := range raw {
+		env := template
+		env.Index = share[0]
+		env.Payload = share[ShareOverhead:]
+		if o.labels != nil {
+			env.Label = o.labels[i]
+		}
+		if out[i], err = env.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// parseEnvelopes decodes every share, reporting which one was malformed. A
+// lone share is reported against the threshold it records, unless that is 1.
+func parseEnvelopes(parts [][]byte) ([]*Share, error) {
+	if parts == nil {
+		return nil, ErrNilShares
+	}
+	if len(parts) < 2 {
+		if len(parts) == 1 && IsEnvelope(parts[0]) {
+			if s, err := ParseShare(parts[0]); err == nil {
+				if s.Threshold == 1 {
+					return []*Share{s}, nil
+				}
+				secureZeroBytes(s.Payload)
+				return nil, &InsufficientSharesError{Have: 1, Need: s.Threshold}
+			}
+		}
+		return nil, ErrTooFewParts
+	}
+
+	shares := make([]*Share, len(parts))
+	for i, part := range parts {
+		s, err := ParseShare(part)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", i, err)
+		}
+		shares[i] = s
+	}
+	return shares, nil
+}
+
+// checkSameSplit checks that decoded shares carry the same split metadata and
+// that there are at least threshold of them.
+func checkSameSplit(shares []*Share) error {
+	first := shares[0]
+	for i, s := range shares[1:] {
+		if !sameSplit(first, s) {
+			return fmt.Errorf("share %d: %w", i+1, ErrMismatchedShares)
+		}
+	}
+	if len(shares) < first.Threshold {
+		return &InsufficientSharesError{Have: len(shares), Need: first.Threshold}
+	}
+	return nil
+}
+
+// sameSplit reports whether two decoded shares carry the same split metadata.
+func sameSplit(a, b *Share) bool {
+	return a.Threshold == b.Threshold && a.Algorithm == b.Algorithm &&
+		a.Purpose == b.Purpose && a.SetID == b.SetID && (a.commitment == nil) == (b.commitment == nil)
+}
+
+// combineEnvelopes checks that decoded shares belong to the same split,
+// reconstructs the secret from them and checks it against their commitments.
+func combineEnvelopes(shares []*Share, eng engine) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrTooFewParts
+	}
+	if err := checkTrivialThreshold(shares, eng); err != nil {
+		return nil, err
+	}
+	if len(shares) < eng.minParts() {
+		return nil, ErrTooFewParts
+	}
+
+	if err := checkSameSplit(shares); err != nil {
+		return nil, err
+	}
+	if eng.strict > 0 && eng.strict != shares[0].Threshold {
+		return nil, fmt.Errorf("%w: threshold %d, expected %d", ErrMismatchedShares, shares[0].Threshold, eng.strict)
+	}
+	if !eng.allowExpired {
+		if err := checkValidity(shares, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	raw := make([][]byte, len(shares))
+	defer func() {
+		for _, share := range raw {
+			secureZeroBytes(share)
+		}
+	}()
+	for i, s := range shares {
+		raw[i] = make([]byte, 0, ShareOverhead+len(s.Payload))
+		raw[i] = append(append(raw[i], s.Index), s.Payload...)
+	}
+
+	eng.meta = envelopeMetadata(shares)
+	secret, err := combine(raw, eng)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCommitments(shares, secret); err != nil {
+		freeSecret(secret)
+		return nil, err
+	}
+	return secret, nil
+}
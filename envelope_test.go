@@ -0,0 +1,107 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	original := Share{
+		Threshold: 3,
+		Index:     7,
+		Algorithm: AlgAES256GCM,
+		Payload:   []byte{1, 2, 3, 4, 5},
+		extra:     []metaRecord{{tag: 200, value: []byte("future field")}},
+	}
+
+	encoded, err := original.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEnvelope(encoded) {
+		t.Fatal("encoded share not recognized as an envelope")
+	}
+
+	decoded, err := ParseShare(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Version != EnvelopeVersion || decoded.Threshold != 3 || decoded.Index != 7 ||
+		decoded.Algorithm != AlgAES256GCM || !bytes.Equal(decoded.Payload, original.Payload) {
+		t.Fatalf("decoded share mismatch: %+v", decoded)
+	}
+
+	// Unknown metadata must survive a decode/encode cycle byte for byte.
+	reencoded, err := decoded.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reencoded, encoded) {
+		t.Fatal("re-encoded share differs from original")
+	}
+}
+
+func TestEnvelopeErrors(t *testing.T) {
+	valid, err := (&Share{Threshold: 2, Index: 1, Payload: []byte{9}}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("raw share is not an envelope", func(t *testing.T) {
+		if _, err := ParseShare([]byte{1, 2, 3}); !errors.Is(err, ErrInvalidEnvelope) {
+			t.Fatalf("expected ErrInvalidEnvelope, got %v", err)
+		}
+	})
+
+	t.Run("corrupted checksum", func(t *testing.T) {
+		corrupted := append([]byte(nil), valid...)
+		corrupted[len(corrupted)-5] ^= 1
+		if _, err := ParseShare(corrupted); err != ErrIntegrityCheckFailed {
+			t.Fatalf("expected ErrIntegrityCheckFailed, got %v", err)
+		}
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		future := append([]byte(nil), valid...)
+		future[3] = EnvelopeVersion + 1
+		if _, err := ParseShare(future); !errors.Is(err, ErrUnsupportedVersion) {
+			t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+		}
+	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		if _, err := (&Share{Threshold: 0, Index: 1, Payload: []byte{1}}).MarshalBinary(); err == nil {
+			t.Fatal("expected error for threshold 0")
+		}
+		if _, err := (&Share{Threshold: 2, Index: 0, Payload: []byte{1}}).MarshalBinary(); err == nil {
+			t.Fatal("expected error for index 0")
+		}
+		if _, err := (&Share{Threshold: 2, Index: 1}).MarshalBinary(); err != ErrEmptySecret {
+			t.Fatalf("expected ErrEmptySecret, got %v", err)
+		}
+	})
+}
+
+func TestEnvelopeBelowThreshold(t *testing.T) {
+	secret := []byte("three of five")
+	shares, err := SplitWithOptions(secret, WithParts(5), WithThreshold(3), WithLabels(make([]string, 5)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two or one shares of a 3-threshold set would interpolate a wrong secret;
+	// the recorded threshold turns that into an error naming the shortfall.
+	for _, n := range []int{2, 1} {
+		got, err := Combine(shares[:n])
+		var ierr *InsufficientSharesError
+		if !errors.As(err, &ierr) || !errors.Is(err, ErrInsufficientShares) || ierr.Have != n || ierr.Need != 3 {
+			t.Fatalf("%d shares: expected InsufficientSharesError{%d, 3}, got %v, %v", n, n, got, err)
+		}
+	}
+
+	got, err := Combine(shares[1:4])
+	if err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("three shares: %v", err)
+	}
+}
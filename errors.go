@@ -1,6 +1,9 @@
 package shamir
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Standard errors for Shamir Secret Sharing operations.
 // These errors provide clear, actionable information about what went wrong.
@@ -30,6 +33,108 @@ var (
 	// ErrIntegrityCheckFailed indicates that a share's integrity check (CRC32) failed.
 	ErrIntegrityCheckFailed = errors.New("shamir: share integrity check failed")
 
+	// ErrAuthenticationFailed indicates that a share's HMAC-SHA256 tag or dealer signature did not
+	// verify, meaning the share was forged, corrupted, or checked with the wrong key.
+	ErrAuthenticationFailed = errors.New("shamir: share authentication failed")
+
+	// ErrUncorrectable indicates that too many shares are corrupted for error correction to succeed.
+	ErrUncorrectable = errors.New("shamir: too many corrupted shares to correct")
+
+	// ErrInvalidEnvelope indicates that an enveloped share is malformed.
+	ErrInvalidEnvelope = errors.New("shamir: invalid share envelope")
+
+	// ErrUnsupportedVersion indicates that a share envelope uses a format version this package cannot read.
+	ErrUnsupportedVersion = errors.New("shamir: unsupported share format version")
+
+	// ErrMismatchedShares indicates that shares carry conflicting metadata and do not belong to the same split.
+	ErrMismatchedShares = errors.New("shamir: shares do not belong to the same split")
+
+	// ErrNoSetID indicates that a share does not record which split it belongs to, as with raw shares from Split.
+	ErrNoSetID = errors.New("shamir: share carries no set ID")
+
+	// ErrInconsistentShares indicates that surplus shares disagree on the secret; see InconsistentSharesError.
+	ErrInconsistentShares = errors.New("shamir: shares are inconsistent")
+
+	// ErrUnknownAlgorithm indicates that a key algorithm is not supported by SplitKey/CombineKey.
+	ErrUnknownAlgorithm = errors.New("shamir: unknown key algorithm")
+
+	// ErrAlgorithmMismatch indicates that the shares record a different key algorithm than expected.
+	ErrAlgorithmMismatch = errors.New("shamir: key algorithm mismatch")
+
+	// ErrInvalidKeyLength indicates that a key's length does not suit its algorithm.
+	ErrInvalidKeyLength = errors.New("shamir: invalid key length for algorithm")
+
+	// ErrPurposeMismatch indicates that shares are not bound to the purpose the caller expected.
+	ErrPurposeMismatch = errors.New("shamir: share purpose mismatch")
+
+	// ErrPurposeRequired indicates that strict purpose mode is enabled but no purpose was supplied.
+	ErrPurposeRequired = errors.New("shamir: purpose required in strict mode")
+
+	// ErrUnknownShare indicates that a share is not listed in the manifest it was checked against.
+	ErrUnknownShare = errors.New("shamir: share not listed in manifest")
+
+	// ErrInvalidPEM indicates that data does not contain a well-formed SHAMIR SHARE PEM block.
+	ErrInvalidPEM = errors.New("shamir: invalid share PEM block")
+
+	// ErrTranscription indicates a Bech32 or Base58Check share with a bad character or checksum, as
+	// from a typo; see TranscriptionError. Shares that decode but are malformed fail with other errors.
+	ErrTranscription = errors.New("shamir: share mistyped")
+
+	// ErrShareRejected indicates that an imported share blob violated the import limits or schema.
+	ErrShareRejected = errors.New("shamir: imported share rejected")
+
+	// ErrInvalidStream indicates a malformed, truncated or reordered framed share stream.
+	ErrInvalidStream = errors.New("shamir: invalid share stream")
+
+	// ErrInvalidSealed indicates that a SealSplit ciphertext or encrypted share is malformed, tampered with
+	// or does not match its key.
+	ErrInvalidSealed = errors.New("shamir: invalid sealed ciphertext")
+
+	// ErrReconstructionMismatch indicates that a reconstructed secret does not match the commitment
+	// recorded in its shares; see WithCommitment.
+	ErrReconstructionMismatch = errors.New("shamir: reconstructed secret does not match its commitment")
+
+	// ErrWrongPassphrase indicates that a passphrase-protected share did not decrypt: the passphrase
+	// is wrong or the share was tampered with.
+	ErrWrongPassphrase = errors.New("shamir: wrong passphrase or tampered share")
+
+	// ErrShareExpired indicates a share past the end of its validity window; see WithValidity.
+	ErrShareExpired = errors.New("shamir: share expired")
+
+	// ErrShareNotYetValid indicates a share before the start of its validity window; see WithValidity.
+	ErrShareNotYetValid = errors.New("shamir: share not yet valid")
+
+	// ErrSessionClosed indicates that a combine session has already reconstructed or been closed.
+	ErrSessionClosed = errors.New("shamir: combine session closed")
+
+	// ErrFormatMismatch indicates that shares are in a format the called function does not handle.
+	// The returned error is a *MigrationError naming the function to use instead.
+	ErrFormatMismatch = errors.New("shamir: share format not supported by this function")
+
+	// ErrPolicyDenied indicates that a policy hook refused a combine; the hook's own error is wrapped as well.
+	ErrPolicyDenied = errors.New("shamir: combine denied by policy")
+
+	// ErrInvalidMultiShare indicates that a SplitMulti share is malformed or corrupted.
+	ErrInvalidMultiShare = errors.New("shamir: invalid multi-secret share")
+
+	// ErrUnknownSecret indicates that multi-secret shares hold no secret of the requested name.
+	ErrUnknownSecret = errors.New("shamir: no such secret in share set")
+
+	// ErrInvalidPreparedShare indicates that a PrepareShare share is malformed or corrupted.
+	ErrInvalidPreparedShare = errors.New("shamir: invalid prepared share")
+
+	// ErrShareFileName indicates a share file whose name is not canonical or does not match its share;
+	// see ReadShareFiles.
+	ErrShareFileName = errors.New("shamir: share file name does not match its share")
+
+	// ErrEntropyUnavailable indicates that the randomness source failed or did not
+	// deliver within the entropy timeout (see WithEntropyTimeout). No shares are
+	// returned alongside it.
+	ErrEntropyUnavailable = errors.New("shamir: randomness unavailable")
+
+	// ErrScalarOutOfRange indicates that a SplitScalar secret or share value is not reduced modulo the field order.
+	ErrScalarOutOfRange = errors.New("shamir: scalar out of range for field")
+
 	// ErrInsufficientShares indicates that fewer shares than required threshold were provided.
 	ErrInsufficientShares = errors.New("shamir: insufficient shares for reconstruction")
 
@@ -58,4 +163,77 @@ func NewValidationError(field string, value int, message string) *ValidationErro
 		Value:   value,
 		Message: message,
 	}
-}
\ No newline at end of file
+}
+
+// MigrationError reports shares passed to a function that does not handle
+// their format, such as enveloped shares given to Combine, and names the
+// replacement to use. It matches ErrFormatMismatch with errors.Is.
+type MigrationError struct {
+	Func        string // The function that was called, e.g. "Combine"
+	Share       int    // Index of the first share in the unsupported format
+	Format      string // Detected format, e.g. "enveloped" or "PEM"
+	Replacement string // What to use instead
+}
+
+func (e *MigrationError) Error() string {
+	return fmt.Sprintf("shamir: %s: share %d is a %s share; use %s", e.Func, e.Share, e.Format, e.Replacement)
+}
+
+// Unwrap returns ErrFormatMismatch.
+func (e *MigrationError) Unwrap() error {
+	return ErrFormatMismatch
+}
+
+// InsufficientSharesError reports a combine given fewer shares than the
+// threshold recorded in them, which would otherwise interpolate a wrong
+// secret. It matches ErrInsufficientShares with errors.Is.
+type InsufficientSharesError struct {
+	Have int // Number of shares supplied
+	Need int // Threshold the shares require
+}
+
+func (e *InsufficientSharesError) Error() string {
+	return fmt.Sprintf("shamir: insufficient shares for reconstruction: have %d, need %d", e.Have, e.Need)
+}
+
+// Unwrap returns ErrInsufficientShares.
+func (e *InsufficientSharesError) Unwrap() error {
+	return ErrInsufficientShares
+}
+
+// InconsistentSharesError reports shares that do not all lie on one polynomial
+// of the expected degree, as found by CombineStrict. It matches
+// ErrInconsistentShares with errors.Is.
+type InconsistentSharesError struct {
+	Outliers []int // Indices of the shares that disagree with the rest; nil if they could not be told apart
+}
+
+func (e *InconsistentSharesError) Error() string {
+	if len(e.Outliers) == 0 {
+		return "shamir: shares are inconsistent; too few surplus shares to identify the outliers"
+	}
+	return fmt.Sprintf("shamir: shares are inconsistent; outliers: %v", e.Outliers)
+}
+
+// Unwrap returns ErrInconsistentShares.
+func (e *InconsistentSharesError) Unwrap() error {
+	return ErrInconsistentShares
+}
+
+// IntegrityError reports every share whose CRC32 did not verify in
+// CombineWithIntegrity, not only the first, so that all damaged shares can be
+// traced to their custodians at once. It matches ErrIntegrityCheckFailed with
+// errors.Is.
+type IntegrityError struct {
+	BadShares    []int  // Indices of the failing shares in the input, ascending
+	XCoordinates []byte // Their x-coordinates, which may themselves be damaged
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("shamir: share integrity check failed: shares %v (x-coordinates %v)", e.BadShares, e.XCoordinates)
+}
+
+// Unwrap returns ErrIntegrityCheckFailed.
+func (e *IntegrityError) Unwrap() error {
+	return ErrIntegrityCheckFailed
+}
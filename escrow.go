@@ -0,0 +1,95 @@
+package shamir
+
+import (
+	"fmt"
+)
+
+// EscrowPolicy describes one escrow arrangement: who the custodians are and how
+// many of them must cooperate. Each custodian receives exactly one share.
+type EscrowPolicy struct {
+	Name       string   // Human-readable name, e.g. "legal" or "operations"
+	Threshold  int      // Shares required for reconstruction
+	Custodians []string // Unique custodian identifiers, one share each
+}
+
+// EscrowSharing is one independent sharing produced by SplitDualEscrow.
+type EscrowSharing struct {
+	Manifest *Manifest // Dealer's record; links to the other sharing by set ID only
+	Shares   [][]byte  // Enveloped shares; Shares[i] belongs to Manifest.Custodians[i]
+}
+
+// SplitDualEscrow produces two independent sharings of the same secret for
+// organizations that must keep separate escrow arrangements (e.g. legal and
+// operational).
+//
+// Each sharing uses fresh randomness, its own set ID, threshold and custodian
+// set, so shares from one arrangement are useless in the other and reveal
+// nothing about it. The manifests reference each other only through set IDs;
+// neither lists the other's custodians.
+func SplitDualEscrow(secret []byte, first, second EscrowPolicy) (*EscrowSharing, *EscrowSharing, error) {
+	a, err := splitEscrow(secret, first)
+	if err != nil {
+		return nil, nil, fmt.Errorf("shamir: escrow %q: %w", first.Name, err)
+	}
+	b, err := splitEscrow(secret, second)
+	if err != nil {
+		return nil, nil, fmt.Errorf("shamir: escrow %q: %w", second.Name, err)
+	}
+
+	a.Manifest.Linked = append(a.Manifest.Linked, b.Manifest.SetID)
+	b.Manifest.Linked = append(b.Manifest.Linked, a.Manifest.SetID)
+
+	return a, b, nil
+}
+
+// CombineEscrow reconstructs the secret from shares of a single escrow sharing,
+// checking that every share is listed in the sharing's manifest.
+func CombineEscrow(manifest *Manifest, parts [][]byte) ([]byte, error) {
+	for i, part := range parts {
+		if _, err := manifest.Custodian(part); err != nil {
+			return nil, fmt.Errorf("share %d: %w", i, err)
+		}
+	}
+
+	shares, err := parseEnvelopes(parts)
+	if err != nil {
+		return nil, err
+	}
+	return combineEnvelopes(shares, engine{})
+}
+
+// splitEscrow creates one sharing and its manifest for the policy.
+func splitEscrow(secret []byte, policy EscrowPolicy) (*EscrowSharing, error) {
+	seen := make(map[string]bool, len(policy.Custodians))
+	for i, c := range policy.Custodians {
+		if c == "" {
+			return nil, NewValidationError("custodian", i, "shamir: custodian identifier cannot be empty")
+		}
+		if seen[c] {
+			return nil, NewValidationError("custodian", i, "shamir: duplicate custodian identifier")
+		}
+		seen[c] = true
+	}
+
+	setID, err := newSetID()
+	if err != nil {
+		return nil, err
+	}
+
+	// Each share is labelled with its custodian so holders can be audited
+	// from the shares alone.
+	o := newOptions([]Option{
+		WithParts(len(policy.Custodians)),
+		WithThreshold(policy.Threshold),
+		WithLabels(policy.Custodians...),
+	})
+	created := creationTime()
+	shares, err := splitEnvelopes(secret, o, Share{SetID: setID, CreatedAt: created})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := newManifest(setID, policy.Name, policy.Threshold, policy.Custodians, shares)
+	manifest.CreatedAt = created
+	return &EscrowSharing{Manifest: manifest, Shares: shares}, nil
+}
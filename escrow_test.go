@@ -0,0 +1,99 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSplitDualEscrow(t *testing.T) {
+	secret := []byte("root CA private key")
+
+	legal, ops, err := SplitDualEscrow(secret,
+		EscrowPolicy{Name: "legal", Threshold: 2, Custodians: []string{"counsel", "notary", "auditor"}},
+		EscrowPolicy{Name: "operations", Threshold: 3, Custodians: []string{"alice", "bob", "carol", "dave", "erin"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(legal.Shares) != 3 || len(ops.Shares) != 5 {
+		t.Fatalf("unexpected share counts: %d, %d", len(legal.Shares), len(ops.Shares))
+	}
+	if legal.Manifest.SetID == ops.Manifest.SetID {
+		t.Fatal("sharings must use independent set IDs")
+	}
+	if len(legal.Manifest.Linked) != 1 || legal.Manifest.Linked[0] != ops.Manifest.SetID {
+		t.Fatal("legal manifest not linked to operations sharing")
+	}
+	if len(ops.Manifest.Linked) != 1 || ops.Manifest.Linked[0] != legal.Manifest.SetID {
+		t.Fatal("operations manifest not linked to legal sharing")
+	}
+
+	t.Run("each sharing reconstructs", func(t *testing.T) {
+		for _, sharing := range []*EscrowSharing{legal, ops} {
+			reconstructed, err := CombineEscrow(sharing.Manifest, sharing.Shares[:sharing.Manifest.Threshold])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(reconstructed, secret) {
+				t.Fatalf("%s: reconstruction failed", sharing.Manifest.Name)
+			}
+		}
+	})
+
+	t.Run("shares cannot be mixed", func(t *testing.T) {
+		mixed := [][]byte{legal.Shares[0], ops.Shares[1], ops.Shares[2]}
+		if _, err := CombineEscrow(ops.Manifest, mixed); !errors.Is(err, ErrUnknownShare) {
+			t.Fatalf("expected ErrUnknownShare, got %v", err)
+		}
+
+		shares, err := parseEnvelopes(mixed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := combineEnvelopes(shares, engine{}); !errors.Is(err, ErrMismatchedShares) {
+			t.Fatalf("expected ErrMismatchedShares, got %v", err)
+		}
+	})
+
+	t.Run("manifest identifies custodians", func(t *testing.T) {
+		custodian, err := ops.Manifest.Custodian(ops.Shares[3])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if custodian != "dave" {
+			t.Fatalf("expected dave, got %s", custodian)
+		}
+	})
+
+	t.Run("manifest json round trip", func(t *testing.T) {
+		data, err := json.Marshal(legal.Manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte("alice")) {
+			t.Fatal("legal manifest must not mention operations custodians")
+		}
+
+		var decoded Manifest
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.SetID != legal.Manifest.SetID || decoded.Custodians[1] != legal.Manifest.Custodians[1] {
+			t.Fatal("manifest did not survive JSON round trip")
+		}
+	})
+}
+
+func TestSplitDualEscrowValidation(t *testing.T) {
+	valid := EscrowPolicy{Name: "ok", Threshold: 2, Custodians: []string{"a", "b"}}
+
+	if _, _, err := SplitDualEscrow([]byte("s"), valid, EscrowPolicy{Threshold: 2, Custodians: []string{"a", "a"}}); err == nil {
+		t.Fatal("expected error for duplicate custodians")
+	}
+	if _, _, err := SplitDualEscrow([]byte("s"), EscrowPolicy{Threshold: 3, Custodians: []string{"a", "b"}}, valid); err == nil {
+		t.Fatal("expected error for threshold above custodian count")
+	}
+}
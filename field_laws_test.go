@@ -0,0 +1,164 @@
+//go:build longtest
+
+package shamir
+
+// Exhaustive GF(256) field-law and interpolation tests.
+// These are slow and only run with: go test -tags longtest ./...
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+)
+
+// refMult multiplies in GF(256) by shift-and-add, independently of the lookup tables.
+func refMult(a, b byte) byte {
+	var p byte
+	for b != 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1d // Low byte of 0x11d
+		}
+		b >>= 1
+	}
+	return p
+}
+
+func TestFieldLawsExhaustive(t *testing.T) {
+	t.Run("multiplication matches reference", func(t *testing.T) {
+		for a := 0; a < 256; a++ {
+			for b := 0; b < 256; b++ {
+				if got, want := gfMult(byte(a), byte(b)), refMult(byte(a), byte(b)); got != want {
+					t.Fatalf("gfMult(%d, %d) = %d, want %d", a, b, got, want)
+				}
+			}
+		}
+	})
+
+	t.Run("commutativity", func(t *testing.T) {
+		for a := 0; a < 256; a++ {
+			for b := 0; b < 256; b++ {
+				if gfMult(byte(a), byte(b)) != gfMult(byte(b), byte(a)) {
+					t.Fatalf("multiplication not commutative for %d, %d", a, b)
+				}
+			}
+		}
+	})
+
+	t.Run("associativity and distributivity", func(t *testing.T) {
+		for a := 0; a < 256; a++ {
+			for b := 0; b < 256; b++ {
+				ab := gfMult(byte(a), byte(b))
+				for c := 0; c < 256; c++ {
+					if gfMult(ab, byte(c)) != gfMult(byte(a), gfMult(byte(b), byte(c))) {
+						t.Fatalf("multiplication not associative for %d, %d, %d", a, b, c)
+					}
+					if gfMult(byte(a), gfAdd(byte(b), byte(c))) != gfAdd(ab, gfMult(byte(a), byte(c))) {
+						t.Fatalf("multiplication not distributive for %d, %d, %d", a, b, c)
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("inverses and division", func(t *testing.T) {
+		for a := 1; a < 256; a++ {
+			if gfMult(byte(a), gfInv(byte(a))) != 1 {
+				t.Fatalf("a * inv(a) != 1 for %d", a)
+			}
+			for b := 0; b < 256; b++ {
+				if gfMult(gfDiv(byte(b), byte(a)), byte(a)) != byte(b) {
+					t.Fatalf("(%d / %d) * %d != %d", b, a, a, b)
+				}
+			}
+		}
+	})
+
+	t.Run("slice kernels match scalar", func(t *testing.T) {
+		src := make([]byte, 256+7) // Odd length exercises the tail loop
+		for i := range src {
+			src[i] = byte(i)
+		}
+		dst := make([]byte, len(src))
+		for scalar := 0; scalar < 256; scalar++ {
+			gfMultSlice(dst, src, byte(scalar))
+			for i := range src {
+				if dst[i] != gfMult(src[i], byte(scalar)) {
+					t.Fatalf("gfMultSlice mismatch at %d for scalar %d", i, scalar)
+				}
+			}
+		}
+	})
+}
+
+func TestPolynomialIdentities(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	for degree := 0; degree < 32; degree++ {
+		coeffs := make([]byte, degree+1)
+		for i := range coeffs {
+			coeffs[i] = byte(rng.UintN(256))
+		}
+
+		// The slice evaluator must agree with the scalar evaluator at every point.
+		sliceCoeffs := make([][]byte, len(coeffs))
+		for i, c := range coeffs {
+			sliceCoeffs[i] = []byte{c}
+		}
+		dst := make([]byte, 1)
+		for x := 0; x < 256; x++ {
+			gfPolyEvalSlice(dst, sliceCoeffs, byte(x))
+			if dst[0] != gfPolyEval(coeffs, byte(x)) {
+				t.Fatalf("degree %d: slice and scalar evaluation disagree at x=%d", degree, x)
+			}
+		}
+
+		// Interpolating degree+1 points must reproduce the polynomial everywhere.
+		xs := make([]byte, degree+1)
+		ys := make([]byte, degree+1)
+		for i := range xs {
+			xs[i] = byte(i + 1)
+			ys[i] = gfPolyEval(coeffs, xs[i])
+		}
+		for x := 0; x < 256; x++ {
+			if got, want := lagrangeInterpolate(xs, ys, byte(x)), gfPolyEval(coeffs, byte(x)); got != want {
+				t.Fatalf("degree %d: interpolation gives %d at x=%d, want %d", degree, got, x, want)
+			}
+		}
+	}
+}
+
+func TestInterpolationAllThresholds(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+
+	for threshold := 2; threshold <= 255; threshold++ {
+		secret := make([]byte, 1+rng.IntN(48))
+		for i := range secret {
+			secret[i] = byte(rng.UintN(256))
+		}
+
+		shares, err := Split(secret, 255, threshold)
+		if err != nil {
+			t.Fatalf("threshold %d: Split failed: %v", threshold, err)
+		}
+
+		// Pick a random threshold-sized subset in random order.
+		perm := rng.Perm(len(shares))
+		subset := make([][]byte, threshold)
+		for i := range subset {
+			subset[i] = shares[perm[i]]
+		}
+
+		reconstructed, err := Combine(subset)
+		if err != nil {
+			t.Fatalf("threshold %d: Combine failed: %v", threshold, err)
+		}
+		if !bytes.Equal(reconstructed, secret) {
+			t.Fatalf("threshold %d: reconstruction mismatch", threshold)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package gf256
+
+// Constant-time arithmetic.
+//
+// The table-based operations index memory with their operands and
+// special-case zero, so their timing depends on the values involved. The
+// functions here compute the same results with shifts and masks only: no
+// branch and no memory access depends on an operand. They are several times
+// slower and meant for code that handles secret values, such as
+// reconstruction with shamir.WithConstantTime.
+
+// MulConstantTime returns a · b, as Mul does, in constant time.
+func MulConstantTime(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= -(b & 1) & a
+		b >>= 1
+		// Multiply a by x, reducing by the polynomial if x⁸ overflowed.
+		a = a<<1 ^ -(a>>7)&(Polynomial&0xff)
+	}
+	return p
+}
+
+// InvConstantTime returns the multiplicative inverse of a in constant time,
+// as a²⁵⁴. Unlike Inv it does not panic: the result for 0 is 0.
+func InvConstantTime(a byte) byte {
+	// a²⁵⁴ = a² · a⁴ · a⁸ · … · a¹²⁸
+	square := MulConstantTime(a, a)
+	result := square
+	for i := 2; i < 8; i++ {
+		square = MulConstantTime(square, square)
+		result = MulConstantTime(result, square)
+	}
+	return result
+}
+
+// LagrangeBasisConstantTime sets basis as LagrangeBasis does, in constant
+// time with respect to xs and x. The loops run the same number of times
+// whatever the points are, and repeated points yield zero entries without a
+// branch, since InvConstantTime(0) is 0.
+func LagrangeBasisConstantTime(basis, xs []byte, x byte) {
+	n := len(xs)
+	for i := 0; i < n; i++ {
+		numerator := byte(1)
+		denominator := byte(1)
+		for j := 0; j < n; j++ {
+			if i == j {
+				continue
+			}
+			numerator = MulConstantTime(numerator, x^xs[j])
+			denominator = MulConstantTime(denominator, xs[i]^xs[j])
+		}
+		basis[i] = MulConstantTime(numerator, InvConstantTime(denominator))
+	}
+}
+
+// MulAddSliceConstantTime sets dst[i] = dst[i] + src[i] · c, as MulAddSlice
+// does, in constant time with respect to the contents of src and dst and to
+// c. The vector kernels shuffle through nibble tables built for c here rather
+// than looked up by it; the remaining bytes use MulConstantTime.
+func MulAddSliceConstantTime(dst, src []byte, c byte) {
+	if len(dst) != len(src) {
+		panic("gf256: destination and source slices must have same length")
+	}
+
+	var low, high [16]byte
+	for i := range low {
+		low[i] = MulConstantTime(c, byte(i))
+		high[i] = MulConstantTime(c, byte(i<<4))
+	}
+	i := active.mulAddTables(&low, &high, dst, src)
+	for ; i < len(src); i++ {
+		dst[i] ^= MulConstantTime(src[i], c)
+	}
+}
@@ -0,0 +1,83 @@
+package gf256
+
+import (
+	"os"
+	"strings"
+)
+
+// CPU dispatch.
+//
+// Every instruction set the package has vector kernels for is described by a
+// kernel value, and one is selected at startup: the fastest the CPU supports,
+// unless GODEBUG says otherwise. GODEBUG=shamircpu=off selects the portable
+// table-lookup code, and shamircpu=avx2, ssse3 or neon a specific kernel if
+// the CPU supports it, which helps when bisecting a suspected kernel bug.
+
+// kernel is the set of vector routines for one instruction set. Each routine
+// processes the longest prefix of its operands that is a whole number of
+// vectors and returns its length; the caller finishes the tail.
+type kernel struct {
+	name   string
+	reason string // Why the kernel was selected, see Kernel
+
+	mul    func(dst, src []byte, c byte) int // dst = c·src
+	mulAdd func(dst, src []byte, c byte) int // dst += c·src
+	add    func(dst, a, b []byte) int        // dst = a + b
+
+	// mulAddTables is mulAdd with the caller's nibble tables for c, see
+	// MulAddSliceConstantTime.
+	mulAddTables func(low, high *[16]byte, dst, src []byte) int
+}
+
+// genericKernel leaves every byte to the table-lookup code.
+var genericKernel = kernel{
+	name:   "generic",
+	mul:    func(dst, src []byte, c byte) int { return 0 },
+	mulAdd: func(dst, src []byte, c byte) int { return 0 },
+	add:    func(dst, a, b []byte) int { return 0 },
+
+	mulAddTables: func(low, high *[16]byte, dst, src []byte) int { return 0 },
+}
+
+// active is the kernel the slice operations use in this process.
+var active = selectKernel(os.Getenv("GODEBUG"))
+
+// selectKernel picks the fastest kernel the CPU supports, honouring a
+// shamircpu setting in godebug.
+func selectKernel(godebug string) kernel {
+	available := cpuKernels()
+	best := genericKernel
+	best.reason = genericReason()
+	if len(available) > 0 {
+		best = available[0]
+	}
+
+	setting, ok := godebugValue(godebug, "shamircpu")
+	if !ok {
+		return best
+	}
+	if setting == "off" || setting == "generic" {
+		k := genericKernel
+		k.reason = "disabled by GODEBUG=shamircpu=" + setting
+		return k
+	}
+	for _, k := range available {
+		if k.name == setting {
+			k.reason = "selected by GODEBUG=shamircpu=" + setting
+			return k
+		}
+	}
+	best.reason += "; GODEBUG=shamircpu=" + setting + " is not available"
+	return best
+}
+
+// godebugValue returns the value of key in a GODEBUG-style list of
+// comma-separated key=value settings. As in the runtime, the last one wins.
+func godebugValue(godebug, key string) (value string, ok bool) {
+	for _, setting := range strings.Split(godebug, ",") {
+		if k, v, found := strings.Cut(strings.TrimSpace(setting), "="); found && k == key {
+			value, ok = v, true
+		}
+	}
+	return value, ok
+}
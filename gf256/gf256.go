@@ -0,0 +1,305 @@
+// Package gf256 implements arithmetic in the finite field GF(2⁸) used by the
+// parent shamir package: the field of bytes modulo the irreducible polynomial
+// x⁸ + x⁴ + x³ + x² + 1 (0x11d), with generator 2.
+//
+// It is the single field implementation behind Split, Combine, error
+// correction and the share arithmetic, exposed so that other constructions on
+// the same shares (verifiable secret sharing, custom decoders) can reuse it.
+// Element operations use log/exp tables; the slice operations use AVX2, SSSE3
+// or NEON kernels where available (see Kernel).
+//
+// Addition is XOR, so subtraction is the same operation. Table lookups are
+// not constant-time with respect to their operands; MulConstantTime and the
+// other ConstantTime functions are, at some cost in speed.
+//
+// Note that HashiCorp Vault and SLIP-0039 use the AES field (0x11b) instead;
+// their shares are not elements of this field.
+package gf256
+
+// Polynomial is the irreducible polynomial defining the field, including the x⁸ term.
+const Polynomial = 0x11d
+
+// fieldTables holds the pre-computed exponential and logarithm tables for GF(256).
+type fieldTables struct {
+	exp [256]byte // Exponential table: exp[i] = generator^i
+	log [256]byte // Logarithm table: log[exp[i]] = i
+}
+
+// Global field tables initialized at package load time.
+var tables fieldTables
+
+func init() {
+	buildFieldTables()
+}
+
+// buildFieldTables constructs the exponential and logarithm lookup tables for GF(256)
+// with generator 2.
+func buildFieldTables() {
+	// Generator element (primitive root) for GF(256)
+	generator := 1
+
+	// Build exponential table: exp[i] = generator^i mod irreducible_polynomial
+	for i := 0; i < 255; i++ {
+		tables.exp[i] = byte(generator)
+		tables.log[generator] = byte(i)
+
+		// Multiply by 2 (shift left) and reduce if necessary
+		generator <<= 1
+		if generator&0x100 != 0 {
+			generator ^= Polynomial // Reduce by irreducible polynomial
+		}
+	}
+
+	// Handle special cases
+	tables.exp[255] = tables.exp[0] // exp[255] = exp[0] = 1
+	tables.log[0] = 255             // log[0] is undefined, use 255 as sentinel
+}
+
+// Add returns a + b, which is a XOR b. It is also a - b.
+func Add(a, b byte) byte {
+	return a ^ b
+}
+
+// Mul returns a · b.
+func Mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	// Multiplication in GF(256): a * b = exp[(log[a] + log[b]) mod 255]
+	logSum := int(tables.log[a]) + int(tables.log[b])
+	return tables.exp[logSum%255]
+}
+
+// Div returns a / b. It panics if b is zero.
+func Div(a, b byte) byte {
+	if b == 0 {
+		panic("gf256: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+
+	// Division in GF(256): a / b = exp[(log[a] - log[b] + 255) mod 255]
+	logDiff := int(tables.log[a]) - int(tables.log[b]) + 255
+	return tables.exp[logDiff%255]
+}
+
+// Inv returns the multiplicative inverse of a. It panics if a is zero.
+func Inv(a byte) byte {
+	if a == 0 {
+		panic("gf256: inverse of zero is undefined")
+	}
+
+	// Inverse in GF(256): a^-1 = exp[(255 - log[a]) mod 255]
+	return tables.exp[255-int(tables.log[a])]
+}
+
+// Exp returns 2ⁿ, the generator raised to n, for any n ≥ 0.
+func Exp(n int) byte {
+	return tables.exp[n%255]
+}
+
+// Log returns the discrete logarithm of a to base 2, in [0, 255). It panics if
+// a is zero.
+func Log(a byte) int {
+	if a == 0 {
+		panic("gf256: logarithm of zero is undefined")
+	}
+	return int(tables.log[a])
+}
+
+// MulSlice sets dst[i] = src[i] · c. dst and src must have the same length
+// and may be the same slice. Multiplying by 0 and 1 is special-cased; other
+// scalars use the vector kernel for the aligned prefix and table lookups for
+// the rest (see SetMulTable).
+func MulSlice(dst, src []byte, c byte) {
+	if len(dst) != len(src) {
+		panic("gf256: destination and source slices must have same length")
+	}
+
+	// Handle special cases for performance
+	switch c {
+	case 0:
+		// Multiply by 0: result is all zeros
+		for i := range dst {
+			dst[i] = 0
+		}
+		return
+	case 1:
+		// Multiply by 1: result is identity
+		copy(dst, src)
+		return
+	}
+
+	// Use the vector kernel for the aligned prefix where the CPU has one
+	i := active.mul(dst, src, c)
+
+	// One lookup per byte in the scalar's row of the full table, if enabled
+	if table := mulTable.Load(); table != nil {
+		row := &table[c]
+		for ; i < len(src); i++ {
+			dst[i] = row[src[i]]
+		}
+		return
+	}
+
+	// General case: use lookup table multiplication
+	scalarLog := tables.log[c]
+
+	// Process the remainder in chunks for better cache performance
+	for i+8 <= len(src) {
+		// Process 8 bytes at once
+		for j := 0; j < 8; j++ {
+			if src[i+j] == 0 {
+				dst[i+j] = 0
+			} else {
+				logSum := int(tables.log[src[i+j]]) + int(scalarLog)
+				dst[i+j] = tables.exp[logSum%255]
+			}
+		}
+		i += 8
+	}
+
+	// Handle remaining bytes
+	for i < len(src) {
+		if src[i] == 0 {
+			dst[i] = 0
+		} else {
+			logSum := int(tables.log[src[i]]) + int(scalarLog)
+			dst[i] = tables.exp[logSum%255]
+		}
+		i++
+	}
+}
+
+// AddSlice sets dst[i] = a[i] + b[i]. All three slices must have the same
+// length; dst may alias a or b.
+func AddSlice(dst, a, b []byte) {
+	if len(dst) != len(a) || len(dst) != len(b) {
+		panic("gf256: all slices must have the same length")
+	}
+
+	n := len(dst)
+	i := active.add(dst, a, b)
+
+	// Process 8 bytes at a time using 64-bit XOR
+	i += xorWords(dst[i:], a[i:], b[i:])
+
+	// Handle remaining bytes
+	for i < n {
+		dst[i] = a[i] ^ b[i]
+		i++
+	}
+}
+
+// MulAddSlice sets dst[i] = dst[i] + src[i] · c, the multiply-accumulate step
+// of Lagrange interpolation, without a scratch buffer. dst and src must have
+// the same length and may be the same slice, but must not otherwise overlap.
+func MulAddSlice(dst, src []byte, c byte) {
+	if len(dst) != len(src) {
+		panic("gf256: destination and source slices must have same length")
+	}
+
+	switch c {
+	case 0:
+		return
+	case 1:
+		AddSlice(dst, dst, src)
+		return
+	}
+
+	i := active.mulAdd(dst, src, c)
+	if table := mulTable.Load(); table != nil {
+		row := &table[c]
+		for ; i < len(src); i++ {
+			dst[i] ^= row[src[i]]
+		}
+		return
+	}
+
+	scalarLog := int(tables.log[c])
+	for ; i < len(src); i++ {
+		if src[i] != 0 {
+			dst[i] ^= tables.exp[(int(tables.log[src[i]])+scalarLog)%255]
+		}
+	}
+}
+
+// PolyEval evaluates the polynomial Σ coefficients[i]·xⁱ at x with Horner's
+// method. The constant term comes first.
+func PolyEval(coefficients []byte, x byte) byte {
+	if len(coefficients) == 0 {
+		return 0
+	}
+
+	// Horner's method: P(x) = a_n + x(a_(n-1) + x(a_(n-2) + ... + x*a_1))
+	result := coefficients[len(coefficients)-1]
+	for i := len(coefficients) - 2; i >= 0; i-- {
+		result = Mul(result, x) ^ coefficients[i]
+	}
+
+	return result
+}
+
+// PolyEvalSlice evaluates len(dst) polynomials at x at once: dst[j] is the
+// polynomial with coefficients coefficients[0][j], coefficients[1][j], ...
+// evaluated at x. Each coefficient row must be as long as dst. This is how a
+// split evaluates every byte of a secret for one share.
+func PolyEvalSlice(dst []byte, coefficients [][]byte, x byte) {
+	if len(coefficients) == 0 || len(dst) == 0 {
+		return
+	}
+
+	// Start with the highest degree coefficients
+	copy(dst, coefficients[len(coefficients)-1])
+
+	// Apply Horner's method: multiply by x and add next coefficient
+	for i := len(coefficients) - 2; i >= 0; i-- {
+		MulSlice(dst, dst, x)               // Multiply current result by x
+		AddSlice(dst, dst, coefficients[i]) // Add coefficient for this degree
+	}
+}
+
+// LagrangeBasis sets basis[i] to the Lagrange basis polynomial Lᵢ evaluated
+// at x for the points xs, so that the value at x of the polynomial through
+// (xs[i], yᵢ) is Σ basis[i]·yᵢ. basis must be as long as xs, and the xs must
+// be distinct; entries for repeated points are set to zero.
+func LagrangeBasis(basis, xs []byte, x byte) {
+	n := len(xs)
+	for i := 0; i < n; i++ {
+		numerator := byte(1)
+		denominator := byte(1)
+
+		// Lᵢ(x) = Π_{j≠i} (x - xs[j]) / (xs[i] - xs[j])
+		for j := 0; j < n; j++ {
+			if i == j {
+				continue
+			}
+
+			numerator = Mul(numerator, x^xs[j])
+			denominator = Mul(denominator, xs[i]^xs[j])
+		}
+
+		if denominator == 0 {
+			basis[i] = 0
+			continue
+		}
+
+		basis[i] = Div(numerator, denominator)
+	}
+}
+
+// Interpolate returns the value at x of the polynomial of degree len(xs)-1
+// through the points (xs[i], ys[i]). With x = 0 this recovers a Shamir secret
+// byte from its shares.
+func Interpolate(xs, ys []byte, x byte) byte {
+	var basis [256]byte
+	LagrangeBasis(basis[:len(xs)], xs, x)
+
+	var result byte
+	for i, y := range ys {
+		result ^= Mul(basis[i], y)
+	}
+	return result
+}
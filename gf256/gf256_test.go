@@ -0,0 +1,539 @@
+package gf256
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGFArithmetic(t *testing.T) {
+	t.Run("addition properties", func(t *testing.T) {
+		// Test commutativity: a + b = b + a
+		a, b := byte(123), byte(45)
+		if Add(a, b) != Add(b, a) {
+			t.Error("Addition is not commutative")
+		}
+
+		// Test identity: a + 0 = a
+		if Add(a, 0) != a {
+			t.Error("Addition identity failed")
+		}
+
+		// Test inverse: a + a = 0
+		if Add(a, a) != 0 {
+			t.Error("Addition inverse failed")
+		}
+	})
+
+	t.Run("multiplication properties", func(t *testing.T) {
+		// Test commutativity: a * b = b * a
+		a, b := byte(123), byte(45)
+		if Mul(a, b) != Mul(b, a) {
+			t.Error("Multiplication is not commutative")
+		}
+
+		// Test identity: a * 1 = a
+		if Mul(a, 1) != a {
+			t.Error("Multiplication identity failed")
+		}
+
+		// Test zero: a * 0 = 0
+		if Mul(a, 0) != 0 {
+			t.Error("Multiplication by zero failed")
+		}
+	})
+
+	t.Run("division properties", func(t *testing.T) {
+		a, b := byte(123), byte(45)
+
+		// Test division: (a * b) / b = a
+		product := Mul(a, b)
+		if Div(product, b) != a {
+			t.Error("Division failed")
+		}
+
+		// Test division by 1: a / 1 = a
+		if Div(a, 1) != a {
+			t.Error("Division by 1 failed")
+		}
+
+		// Test division of zero: 0 / a = 0
+		if Div(0, a) != 0 {
+			t.Error("Division of zero failed")
+		}
+	})
+
+	t.Run("inverse properties", func(t *testing.T) {
+		a := byte(123)
+
+		// Test inverse: a * inv(a) = 1
+		inv := Inv(a)
+		if Mul(a, inv) != 1 {
+			t.Error("Multiplicative inverse failed")
+		}
+	})
+}
+
+func TestGFSliceOperations(t *testing.T) {
+	t.Run("multiply slice", func(t *testing.T) {
+		src := []byte{1, 2, 3, 4, 5}
+		dst := make([]byte, len(src))
+		scalar := byte(3)
+
+		MulSlice(dst, src, scalar)
+
+		// Verify each element
+		for i := range src {
+			expected := Mul(src[i], scalar)
+			if dst[i] != expected {
+				t.Errorf("MulSlice[%d] = %d, want %d", i, dst[i], expected)
+			}
+		}
+	})
+
+	t.Run("multiply by zero", func(t *testing.T) {
+		src := []byte{1, 2, 3, 4, 5}
+		dst := make([]byte, len(src))
+
+		MulSlice(dst, src, 0)
+
+		for i, v := range dst {
+			if v != 0 {
+				t.Errorf("MulSlice by zero[%d] = %d, want 0", i, v)
+			}
+		}
+	})
+
+	t.Run("multiply by one", func(t *testing.T) {
+		src := []byte{1, 2, 3, 4, 5}
+		dst := make([]byte, len(src))
+
+		MulSlice(dst, src, 1)
+
+		if !bytes.Equal(dst, src) {
+			t.Error("MulSlice by one should be identity")
+		}
+	})
+
+	t.Run("add slices", func(t *testing.T) {
+		a := []byte{1, 2, 3, 4, 5}
+		b := []byte{5, 4, 3, 2, 1}
+		dst := make([]byte, len(a))
+
+		AddSlice(dst, a, b)
+
+		for i := range a {
+			expected := Add(a[i], b[i])
+			if dst[i] != expected {
+				t.Errorf("AddSlice[%d] = %d, want %d", i, dst[i], expected)
+			}
+		}
+	})
+}
+
+func TestPolynomialEvaluation(t *testing.T) {
+	t.Run("constant polynomial", func(t *testing.T) {
+		coeffs := []byte{42} // P(x) = 42
+		x := byte(5)
+
+		result := PolyEval(coeffs, x)
+		if result != 42 {
+			t.Errorf("constant polynomial evaluation = %d, want 42", result)
+		}
+	})
+
+	t.Run("linear polynomial", func(t *testing.T) {
+		coeffs := []byte{10, 3} // P(x) = 10 + 3x
+		x := byte(2)
+
+		// Expected: 10 + 3*2 = 10 + 6 = 10 XOR 6 in GF(256)
+		expected := Add(10, Mul(3, 2))
+		result := PolyEval(coeffs, x)
+
+		if result != expected {
+			t.Errorf("linear polynomial evaluation = %d, want %d", result, expected)
+		}
+	})
+
+	t.Run("slice evaluation", func(t *testing.T) {
+		// Multiple polynomials with same coefficients structure
+		coeffs := [][]byte{
+			{10, 20, 30}, // Constant terms
+			{3, 6, 9},    // Linear coefficients
+		}
+		x := byte(2)
+		dst := make([]byte, 3)
+
+		PolyEvalSlice(dst, coeffs, x)
+
+		// Verify each position independently
+		for i := 0; i < 3; i++ {
+			singleCoeffs := []byte{coeffs[0][i], coeffs[1][i]}
+			expected := PolyEval(singleCoeffs, x)
+
+			if dst[i] != expected {
+				t.Errorf("slice evaluation[%d] = %d, want %d", i, dst[i], expected)
+			}
+		}
+	})
+}
+
+func TestFieldTables(t *testing.T) {
+	t.Run("table consistency", func(t *testing.T) {
+		// Test that exp and log tables are inverses
+		for i := 1; i < 256; i++ {
+			b := byte(i)
+			if tables.exp[tables.log[b]] != b {
+				t.Errorf("table inconsistency at %d", i)
+			}
+		}
+	})
+
+	t.Run("generator properties", func(t *testing.T) {
+		// Test that exp[0] = 1 (generator^0 = 1)
+		if tables.exp[0] != 1 {
+			t.Error("exp[0] should be 1")
+		}
+
+		// Test that log[1] = 0
+		if tables.log[1] != 0 {
+			t.Error("log[1] should be 0")
+		}
+	})
+}
+
+func BenchmarkGFOperations(b *testing.B) {
+	a, c := byte(123), byte(45)
+
+	b.Run("multiply", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = Mul(a, c)
+		}
+	})
+
+	b.Run("add", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = Add(a, c)
+		}
+	})
+
+	b.Run("divide", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = Div(a, c)
+		}
+	})
+}
+
+func BenchmarkSliceOperations(b *testing.B) {
+	src := make([]byte, 1024)
+	dst := make([]byte, 1024)
+	scalar := byte(123)
+
+	for i := range src {
+		src[i] = byte(i % 256)
+	}
+
+	b.Run("multiply_slice", func(b *testing.B) {
+		b.SetBytes(1024)
+		for i := 0; i < b.N; i++ {
+			MulSlice(dst, src, scalar)
+		}
+	})
+
+	b.Run("add_slice", func(b *testing.B) {
+		b.SetBytes(1024)
+		for i := 0; i < b.N; i++ {
+			AddSlice(dst, src, dst)
+		}
+	})
+}
+
+func TestExpLog(t *testing.T) {
+	for n := 0; n < 255; n++ {
+		if got := Log(Exp(n)); got != n {
+			t.Fatalf("Log(Exp(%d)) = %d", n, got)
+		}
+	}
+	if Exp(255) != 1 || Exp(256) != 2 {
+		t.Error("Exp does not wrap around the multiplicative group")
+	}
+}
+
+func TestZeroPanics(t *testing.T) {
+	for name, fn := range map[string]func(){
+		"Div": func() { Div(1, 0) },
+		"Inv": func() { Inv(0) },
+		"Log": func() { Log(0) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic on zero", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	// P(x) = 42 + 7x + 200x²
+	coeffs := []byte{42, 7, 200}
+	xs := []byte{3, 17, 250}
+	ys := make([]byte, len(xs))
+	for i, x := range xs {
+		ys[i] = PolyEval(coeffs, x)
+	}
+
+	if got := Interpolate(xs, ys, 0); got != 42 {
+		t.Errorf("Interpolate at 0 = %d, want 42", got)
+	}
+	if got, want := Interpolate(xs, ys, 99), PolyEval(coeffs, 99); got != want {
+		t.Errorf("Interpolate at 99 = %d, want %d", got, want)
+	}
+
+	basis := make([]byte, len(xs))
+	LagrangeBasis(basis, xs, xs[1])
+	if !bytes.Equal(basis, []byte{0, 1, 0}) {
+		t.Errorf("LagrangeBasis at a sample point = %v, want [0 1 0]", basis)
+	}
+}
+
+func TestKernel(t *testing.T) {
+	name, reason := Kernel()
+	if reason == "" {
+		t.Error("Kernel gave no reason")
+	}
+	if (name != "generic") != Accelerated() {
+		t.Errorf("Kernel = %q but Accelerated() = %v", name, Accelerated())
+	}
+}
+
+func TestMulAddSlice(t *testing.T) {
+	src := make([]byte, 77)
+	dst := make([]byte, len(src))
+	for i := range src {
+		src[i] = byte(i * 7)
+		dst[i] = byte(i * 13)
+	}
+	for _, c := range []byte{0, 1, 2, 0x53, 0xff} {
+		got := bytes.Clone(dst)
+		MulAddSlice(got, src, c)
+		for i := range got {
+			if want := dst[i] ^ Mul(src[i], c); got[i] != want {
+				t.Fatalf("c=%#x: byte %d = %#x, want %#x", c, i, got[i], want)
+			}
+		}
+	}
+}
+
+// TestConstantTime checks the constant-time operations against the table
+// code, exhaustively for the element operations and with every kernel for
+// the slice operation.
+func TestConstantTime(t *testing.T) {
+	for a := 0; a < 256; a++ {
+		for b := 0; b < 256; b++ {
+			if got, want := MulConstantTime(byte(a), byte(b)), Mul(byte(a), byte(b)); got != want {
+				t.Fatalf("MulConstantTime(%#x, %#x) = %#x, want %#x", a, b, got, want)
+			}
+		}
+		want := byte(0)
+		if a != 0 {
+			want = Inv(byte(a))
+		}
+		if got := InvConstantTime(byte(a)); got != want {
+			t.Fatalf("InvConstantTime(%#x) = %#x, want %#x", a, got, want)
+		}
+	}
+
+	xs := []byte{3, 17, 250, 1, 99}
+	for _, x := range []byte{0, 17, 200} {
+		got, want := make([]byte, len(xs)), make([]byte, len(xs))
+		LagrangeBasisConstantTime(got, xs, x)
+		LagrangeBasis(want, xs, x)
+		if !bytes.Equal(got, want) {
+			t.Errorf("LagrangeBasisConstantTime at %d = %v, want %v", x, got, want)
+		}
+	}
+	repeated := []byte{3, 17, 3}
+	got, want := make([]byte, 3), make([]byte, 3)
+	LagrangeBasisConstantTime(got, repeated, 0)
+	LagrangeBasis(want, repeated, 0)
+	if !bytes.Equal(got, want) {
+		t.Errorf("LagrangeBasisConstantTime with repeated points = %v, want %v", got, want)
+	}
+
+	defer func(k kernel) { active = k }(active)
+	src := make([]byte, 77)
+	dst := make([]byte, len(src))
+	for i := range src {
+		src[i] = byte(i * 7)
+		dst[i] = byte(i * 13)
+	}
+	for _, k := range append(cpuKernels(), genericKernel) {
+		active = k
+		for _, c := range []byte{0, 1, 2, 0x53, 0xff} {
+			got, want := bytes.Clone(dst), bytes.Clone(dst)
+			MulAddSliceConstantTime(got, src, c)
+			MulAddSlice(want, src, c)
+			if !bytes.Equal(got, want) {
+				t.Fatalf("%s: MulAddSliceConstantTime with c=%#x differs from MulAddSlice", k.name, c)
+			}
+		}
+	}
+}
+
+// TestXORWords checks the word XOR on every combination of misaligned
+// slices, including ones that alias, for both the default and the safe path.
+func TestXORWords(t *testing.T) {
+	buf := make([]byte, 3*96)
+	for i := range buf {
+		buf[i] = byte(i*31 + 7)
+	}
+	for name, xor := range map[string]func(dst, a, b []byte) int{"xorWords": xorWords, "xorWordsSafe": xorWordsSafe} {
+		for n := 0; n <= 40; n++ {
+			for offA := 0; offA < 8; offA++ {
+				for offD := 0; offD < 8; offD++ {
+					a := buf[offA : offA+n]
+					b := buf[96+3 : 96+3+n]
+					dst := make([]byte, 200)[offD : offD+n]
+					done := xor(dst, a, b)
+					if done > n || n-done >= 8 {
+						t.Fatalf("%s: n=%d: handled %d bytes", name, n, done)
+					}
+					for i := 0; i < done; i++ {
+						if dst[i] != a[i]^b[i] {
+							t.Fatalf("%s: n=%d offsets %d/%d: byte %d wrong", name, n, offA, offD, i)
+						}
+					}
+				}
+			}
+
+			// In place, as AddSlice(dst, dst, b) does.
+			dst := bytes.Clone(buf[5 : 5+n])
+			b := buf[96+1 : 96+1+n]
+			want := bytes.Clone(dst)
+			done := xor(dst, dst, b)
+			for i := 0; i < done; i++ {
+				if dst[i] != want[i]^b[i] {
+					t.Fatalf("%s: in place n=%d: byte %d wrong", name, n, i)
+				}
+			}
+		}
+	}
+}
+
+// TestCPUKernels checks every kernel this CPU supports against the generic
+// code, over lengths and offsets that exercise the vector prefix and the tail.
+func TestCPUKernels(t *testing.T) {
+	kernels := cpuKernels()
+	if len(kernels) == 0 {
+		t.Skip("no vector kernels on this CPU")
+	}
+	defer func(k kernel) { active = k }(active)
+
+	src := make([]byte, 200)
+	other := make([]byte, len(src))
+	for i := range src {
+		src[i] = byte(i*31 + 5)
+		other[i] = byte(i*17 + 3)
+	}
+	for _, k := range kernels {
+		for _, n := range []int{0, 1, 15, 16, 31, 32, 33, 64, 100, 199} {
+			for _, off := range []int{0, 1} {
+				s, o := src[off:off+n], other[off:off+n]
+				for _, c := range []byte{2, 0x1d, 0x80, 0xff} {
+					active = genericKernel
+					wantMul, wantMulAdd, wantAdd := make([]byte, n), bytes.Clone(o), make([]byte, n)
+					MulSlice(wantMul, s, c)
+					MulAddSlice(wantMulAdd, s, c)
+					AddSlice(wantAdd, s, o)
+
+					active = k
+					gotMul, gotMulAdd, gotAdd := make([]byte, n), bytes.Clone(o), make([]byte, n)
+					MulSlice(gotMul, s, c)
+					MulAddSlice(gotMulAdd, s, c)
+					AddSlice(gotAdd, s, o)
+					if !bytes.Equal(gotMul, wantMul) || !bytes.Equal(gotMulAdd, wantMulAdd) || !bytes.Equal(gotAdd, wantAdd) {
+						t.Fatalf("%s: n=%d, offset %d, c=%#x: results differ from the generic code", k.name, n, off, c)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSelectKernel(t *testing.T) {
+	best := selectKernel("")
+	if off := selectKernel("panicnil=1,shamircpu=off"); off.name != "generic" || !strings.Contains(off.reason, "shamircpu=off") {
+		t.Errorf("shamircpu=off selected %s (%s)", off.name, off.reason)
+	}
+	if k := selectKernel("shamircpu=bogus"); k.name != best.name || !strings.Contains(k.reason, "not available") {
+		t.Errorf("shamircpu=bogus selected %s (%s)", k.name, k.reason)
+	}
+	for _, want := range cpuKernels() {
+		if k := selectKernel("shamircpu=" + want.name); k.name != want.name {
+			t.Errorf("shamircpu=%s selected %s", want.name, k.name)
+		}
+	}
+	if k := selectKernel("shamircpu=off,shamircpu=" + best.name); k.name != best.name {
+		t.Errorf("the last shamircpu setting should win, got %s", k.name)
+	}
+}
+
+func TestMulTable(t *testing.T) {
+	defer func(k kernel) { active = k }(active)
+	defer SetMulTable(false)
+
+	src := make([]byte, 300)
+	acc := make([]byte, len(src))
+	for i := range src {
+		src[i] = byte(i)
+		acc[i] = byte(i * 29)
+	}
+	for _, k := range append(cpuKernels(), genericKernel) {
+		active = k
+		for _, c := range []byte{2, 0x8e, 0xff} {
+			SetMulTable(false)
+			wantMul, wantMulAdd := make([]byte, len(src)), bytes.Clone(acc)
+			MulSlice(wantMul, src, c)
+			MulAddSlice(wantMulAdd, src, c)
+
+			SetMulTable(true)
+			if !MulTableEnabled() {
+				t.Fatal("SetMulTable(true) did not enable the table")
+			}
+			gotMul, gotMulAdd := make([]byte, len(src)), bytes.Clone(acc)
+			MulSlice(gotMul, src, c)
+			MulAddSlice(gotMulAdd, src, c)
+			if !bytes.Equal(gotMul, wantMul) || !bytes.Equal(gotMulAdd, wantMulAdd) {
+				t.Fatalf("%s kernel, c=%#x: table results differ from the log/exp tables", k.name, c)
+			}
+		}
+	}
+}
+
+func BenchmarkMulTable(b *testing.B) {
+	defer func(k kernel) { active = k }(active)
+	defer SetMulTable(false)
+	active = genericKernel
+
+	src := make([]byte, 1024)
+	dst := make([]byte, 1024)
+	for i := range src {
+		src[i] = byte(i % 256)
+	}
+	for _, enabled := range []bool{false, true} {
+		SetMulTable(enabled)
+		name := "log_exp"
+		if enabled {
+			name = "full_table"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(1024)
+			for i := 0; i < b.N; i++ {
+				MulSlice(dst, src, 123)
+			}
+		})
+	}
+}
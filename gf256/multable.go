@@ -0,0 +1,51 @@
+package gf256
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Full multiplication table.
+//
+// Without a vector kernel, and for the tail bytes a kernel leaves, the slice
+// operations multiply through the log/exp tables: two lookups, a modulo 255
+// and a branch on zero per byte. A 256×256 table of every product turns that
+// into a single lookup in the scalar's 256-byte row, at the cost of 64 KiB of
+// memory that is mostly out of cache on small machines. It is opt-in, for
+// servers without SIMD that have the memory to spare.
+
+// mulTable points at the full table while it is enabled.
+var mulTable atomic.Pointer[[256][256]byte]
+
+var (
+	fullTable     *[256][256]byte
+	fullTableOnce sync.Once
+)
+
+// SetMulTable controls whether MulSlice and MulAddSlice look products up in a
+// precomputed 64 KiB table (table[a][b] = a·b) instead of the log/exp tables,
+// for the bytes no vector kernel handles. It is meant to be called once, from
+// an init function, but is safe to call at any time; the table is built on
+// first use and kept for the life of the process. Element operations are
+// unaffected.
+func SetMulTable(enabled bool) {
+	if !enabled {
+		mulTable.Store(nil)
+		return
+	}
+	fullTableOnce.Do(func() {
+		fullTable = new([256][256]byte)
+		for a := 1; a < 256; a++ {
+			for b := 1; b < 256; b++ {
+				fullTable[a][b] = Mul(byte(a), byte(b))
+			}
+		}
+	})
+	mulTable.Store(fullTable)
+}
+
+// MulTableEnabled reports whether the slice operations use the full
+// multiplication table, see SetMulTable.
+func MulTableEnabled() bool {
+	return mulTable.Load() != nil
+}
@@ -0,0 +1,42 @@
+package gf256
+
+// Split-nibble multiplication tables used by the SIMD kernels.
+//
+// For a scalar c, every byte b satisfies c*b = c*(b & 0x0f) ^ c*(b & 0xf0), so
+// a 16-entry table for each nibble turns multiplication into two byte shuffles
+// (PSHUFB or VPSHUFB on amd64, TBL on arm64) and an XOR, processing 16 or 32
+// bytes at a time.
+
+// nibbleTables holds the low- and high-nibble product tables for every scalar.
+var nibbleTables struct {
+	low  [256][16]byte // low[c][i] = c * i
+	high [256][16]byte // high[c][i] = c * (i << 4)
+}
+
+func init() {
+	buildNibbleTables()
+}
+
+// buildNibbleTables fills nibbleTables from the GF(256) multiplication routine.
+func buildNibbleTables() {
+	for c := 0; c < 256; c++ {
+		for i := 0; i < 16; i++ {
+			nibbleTables.low[c][i] = Mul(byte(c), byte(i))
+			nibbleTables.high[c][i] = Mul(byte(c), byte(i<<4))
+		}
+	}
+}
+
+// Kernel names the kernel the slice operations use on this CPU, "avx2" (amd64
+// VPSHUFB), "ssse3" (amd64 PSHUFB), "neon" (arm64 TBL) or "generic" (table
+// lookups only), and why it was selected. The choice is made once at startup;
+// GODEBUG=shamircpu=off forces the generic code, and shamircpu=<name> a
+// specific kernel the CPU supports.
+func Kernel() (name, reason string) {
+	return active.name, active.reason
+}
+
+// Accelerated reports whether the slice operations use a vector kernel.
+func Accelerated() bool {
+	return active.name != genericKernel.name
+}
@@ -0,0 +1,134 @@
+//go:build amd64 && !purego
+
+package gf256
+
+var (
+	// hasSSSE3 reports whether the CPU supports the PSHUFB instruction.
+	hasSSSE3 = detectSSSE3()
+
+	// hasAVX2 reports whether the CPU and operating system support AVX2.
+	hasAVX2 = detectAVX2()
+)
+
+// detectSSSE3 queries CPUID leaf 1 for the SSSE3 feature bit (ECX bit 9).
+func detectSSSE3() bool {
+	maxLeaf, _, _, _ := cpuid(0, 0)
+	if maxLeaf < 1 {
+		return false
+	}
+	_, _, ecx, _ := cpuid(1, 0)
+	return ecx&(1<<9) != 0
+}
+
+// detectAVX2 queries CPUID leaf 7 for the AVX2 feature bit (EBX bit 5). The
+// operating system must also save the YMM registers on context switches:
+// OSXSAVE (leaf 1, ECX bit 27) and the SSE and AVX state bits of XCR0.
+func detectAVX2() bool {
+	maxLeaf, _, _, _ := cpuid(0, 0)
+	if maxLeaf < 7 {
+		return false
+	}
+	_, _, ecx, _ := cpuid(1, 0)
+	if ecx&(1<<27) == 0 {
+		return false
+	}
+	if xcr0, _ := xgetbv(); xcr0&6 != 6 {
+		return false
+	}
+	_, ebx, _, _ := cpuid(7, 0)
+	return ebx&(1<<5) != 0
+}
+
+// cpuid executes the CPUID instruction. Implemented in simd_amd64.s.
+//
+//go:noescape
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+// xgetbv reads extended control register 0. Implemented in simd_amd64.s.
+func xgetbv() (eax, edx uint32)
+
+// The kernels below process len(src)&^15 (SSSE3) or len(src)&^31 (AVX2)
+// bytes using the given nibble tables. Implemented in simd_amd64.s.
+
+//go:noescape
+func gfMulNibblesSSSE3(low, high *[16]byte, dst, src []byte)
+
+//go:noescape
+func gfMulAddNibblesSSSE3(low, high *[16]byte, dst, src []byte)
+
+//go:noescape
+func xorSSE2(dst, a, b []byte)
+
+//go:noescape
+func gfMulNibblesAVX2(low, high *[16]byte, dst, src []byte)
+
+//go:noescape
+func gfMulAddNibblesAVX2(low, high *[16]byte, dst, src []byte)
+
+//go:noescape
+func xorAVX2(dst, a, b []byte)
+
+// cpuKernels returns the kernels this CPU supports, fastest first.
+func cpuKernels() []kernel {
+	var kernels []kernel
+	if hasAVX2 {
+		kernels = append(kernels, kernel{
+			name:   "avx2",
+			reason: "CPU supports AVX2",
+			mul: func(dst, src []byte, c byte) int {
+				n := len(src) &^ 31
+				gfMulNibblesAVX2(&nibbleTables.low[c], &nibbleTables.high[c], dst[:n], src[:n])
+				return n
+			},
+			mulAdd: func(dst, src []byte, c byte) int {
+				n := len(src) &^ 31
+				gfMulAddNibblesAVX2(&nibbleTables.low[c], &nibbleTables.high[c], dst[:n], src[:n])
+				return n
+			},
+			add: func(dst, a, b []byte) int {
+				n := len(dst) &^ 31
+				xorAVX2(dst[:n], a[:n], b[:n])
+				return n
+			},
+
+			mulAddTables: func(low, high *[16]byte, dst, src []byte) int {
+				n := len(src) &^ 31
+				gfMulAddNibblesAVX2(low, high, dst[:n], src[:n])
+				return n
+			},
+		})
+	}
+	if hasSSSE3 {
+		kernels = append(kernels, kernel{
+			name:   "ssse3",
+			reason: "CPU supports SSSE3",
+			mul: func(dst, src []byte, c byte) int {
+				n := len(src) &^ 15
+				gfMulNibblesSSSE3(&nibbleTables.low[c], &nibbleTables.high[c], dst[:n], src[:n])
+				return n
+			},
+			mulAdd: func(dst, src []byte, c byte) int {
+				n := len(src) &^ 15
+				gfMulAddNibblesSSSE3(&nibbleTables.low[c], &nibbleTables.high[c], dst[:n], src[:n])
+				return n
+			},
+			add: func(dst, a, b []byte) int {
+				n := len(dst) &^ 15
+				xorSSE2(dst[:n], a[:n], b[:n])
+				return n
+			},
+
+			mulAddTables: func(low, high *[16]byte, dst, src []byte) int {
+				n := len(src) &^ 15
+				gfMulAddNibblesSSSE3(low, high, dst[:n], src[:n])
+				return n
+			},
+		})
+	}
+	return kernels
+}
+
+// genericReason explains why no vector kernel is available, for Kernel.
+func genericReason() string {
+	return "CPU lacks SSSE3"
+}
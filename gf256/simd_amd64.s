@@ -0,0 +1,214 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func xgetbv() (eax, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
+
+// func gfMulNibblesSSSE3(low, high *[16]byte, dst, src []byte)
+TEXT ·gfMulNibblesSSSE3(SB), NOSPLIT, $0-64
+	MOVQ low+0(FP), AX
+	MOVQ high+8(FP), BX
+	MOVQ dst_base+16(FP), DI
+	MOVQ src_base+40(FP), SI
+	MOVQ src_len+48(FP), CX
+	SHRQ $4, CX
+	JZ   done
+
+	MOVOU (AX), X6 // Low-nibble products
+	MOVOU (BX), X7 // High-nibble products
+	MOVQ  $0x0f0f0f0f0f0f0f0f, DX
+	MOVQ  DX, X8
+	PUNPCKLQDQ X8, X8 // Nibble mask in every byte
+
+loop:
+	MOVOU  (SI), X0
+	MOVOU  X0, X1
+	PSRLQ  $4, X1
+	PAND   X8, X0 // Low nibbles
+	PAND   X8, X1 // High nibbles
+	MOVOU  X6, X2
+	MOVOU  X7, X3
+	PSHUFB X0, X2
+	PSHUFB X1, X3
+	PXOR   X3, X2
+	MOVOU  X2, (DI)
+	ADDQ   $16, SI
+	ADDQ   $16, DI
+	DECQ   CX
+	JNZ    loop
+
+done:
+	RET
+
+// func gfMulAddNibblesSSSE3(low, high *[16]byte, dst, src []byte)
+TEXT ·gfMulAddNibblesSSSE3(SB), NOSPLIT, $0-64
+	MOVQ low+0(FP), AX
+	MOVQ high+8(FP), BX
+	MOVQ dst_base+16(FP), DI
+	MOVQ src_base+40(FP), SI
+	MOVQ src_len+48(FP), CX
+	SHRQ $4, CX
+	JZ   done
+
+	MOVOU (AX), X6 // Low-nibble products
+	MOVOU (BX), X7 // High-nibble products
+	MOVQ  $0x0f0f0f0f0f0f0f0f, DX
+	MOVQ  DX, X8
+	PUNPCKLQDQ X8, X8 // Nibble mask in every byte
+
+loop:
+	MOVOU  (SI), X0
+	MOVOU  X0, X1
+	PSRLQ  $4, X1
+	PAND   X8, X0 // Low nibbles
+	PAND   X8, X1 // High nibbles
+	MOVOU  X6, X2
+	MOVOU  X7, X3
+	PSHUFB X0, X2
+	PSHUFB X1, X3
+	PXOR   X3, X2
+	MOVOU  (DI), X4
+	PXOR   X4, X2 // Accumulate into dst
+	MOVOU  X2, (DI)
+	ADDQ   $16, SI
+	ADDQ   $16, DI
+	DECQ   CX
+	JNZ    loop
+
+done:
+	RET
+
+// func xorSSE2(dst, a, b []byte)
+TEXT ·xorSSE2(SB), NOSPLIT, $0-72
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ a_base+24(FP), SI
+	MOVQ b_base+48(FP), BX
+	SHRQ $4, CX
+	JZ   done
+
+loop:
+	MOVOU (SI), X0
+	MOVOU (BX), X1
+	PXOR  X1, X0
+	MOVOU X0, (DI)
+	ADDQ  $16, SI
+	ADDQ  $16, BX
+	ADDQ  $16, DI
+	DECQ  CX
+	JNZ   loop
+
+done:
+	RET
+
+// func gfMulNibblesAVX2(low, high *[16]byte, dst, src []byte)
+TEXT ·gfMulNibblesAVX2(SB), NOSPLIT, $0-64
+	MOVQ low+0(FP), AX
+	MOVQ high+8(FP), BX
+	MOVQ dst_base+16(FP), DI
+	MOVQ src_base+40(FP), SI
+	MOVQ src_len+48(FP), CX
+	SHRQ $5, CX
+	JZ   done
+
+	VBROADCASTI128 (AX), Y6 // Low-nibble products in both lanes
+	VBROADCASTI128 (BX), Y7 // High-nibble products in both lanes
+	MOVQ           $0x0f0f0f0f0f0f0f0f, DX
+	VMOVQ          DX, X8
+	VPBROADCASTQ   X8, Y8   // Nibble mask in every byte
+
+loop:
+	VMOVDQU (SI), Y0
+	VPSRLQ  $4, Y0, Y1
+	VPAND   Y8, Y0, Y0 // Low nibbles
+	VPAND   Y8, Y1, Y1 // High nibbles
+	VPSHUFB Y0, Y6, Y2
+	VPSHUFB Y1, Y7, Y3
+	VPXOR   Y3, Y2, Y2
+	VMOVDQU Y2, (DI)
+	ADDQ    $32, SI
+	ADDQ    $32, DI
+	DECQ    CX
+	JNZ     loop
+
+	VZEROUPPER
+
+done:
+	RET
+
+// func gfMulAddNibblesAVX2(low, high *[16]byte, dst, src []byte)
+TEXT ·gfMulAddNibblesAVX2(SB), NOSPLIT, $0-64
+	MOVQ low+0(FP), AX
+	MOVQ high+8(FP), BX
+	MOVQ dst_base+16(FP), DI
+	MOVQ src_base+40(FP), SI
+	MOVQ src_len+48(FP), CX
+	SHRQ $5, CX
+	JZ   done
+
+	VBROADCASTI128 (AX), Y6 // Low-nibble products in both lanes
+	VBROADCASTI128 (BX), Y7 // High-nibble products in both lanes
+	MOVQ           $0x0f0f0f0f0f0f0f0f, DX
+	VMOVQ          DX, X8
+	VPBROADCASTQ   X8, Y8   // Nibble mask in every byte
+
+loop:
+	VMOVDQU (SI), Y0
+	VPSRLQ  $4, Y0, Y1
+	VPAND   Y8, Y0, Y0 // Low nibbles
+	VPAND   Y8, Y1, Y1 // High nibbles
+	VPSHUFB Y0, Y6, Y2
+	VPSHUFB Y1, Y7, Y3
+	VPXOR   Y3, Y2, Y2
+	VPXOR   (DI), Y2, Y2 // Accumulate into dst
+	VMOVDQU Y2, (DI)
+	ADDQ    $32, SI
+	ADDQ    $32, DI
+	DECQ    CX
+	JNZ     loop
+
+	VZEROUPPER
+
+done:
+	RET
+
+// func xorAVX2(dst, a, b []byte)
+TEXT ·xorAVX2(SB), NOSPLIT, $0-72
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ a_base+24(FP), SI
+	MOVQ b_base+48(FP), BX
+	SHRQ $5, CX
+	JZ   done
+
+loop:
+	VMOVDQU (SI), Y0
+	VPXOR   (BX), Y0, Y0
+	VMOVDQU Y0, (DI)
+	ADDQ    $32, SI
+	ADDQ    $32, BX
+	ADDQ    $32, DI
+	DECQ    CX
+	JNZ     loop
+
+	VZEROUPPER
+
+done:
+	RET
@@ -0,0 +1,51 @@
+//go:build arm64 && !purego
+
+package gf256
+
+// The kernels below process len(src)&^15 bytes using the given nibble
+// tables. Implemented in simd_arm64.s.
+
+//go:noescape
+func gfMulNibblesNEON(low, high *[16]byte, dst, src []byte)
+
+//go:noescape
+func gfMulAddNibblesNEON(low, high *[16]byte, dst, src []byte)
+
+//go:noescape
+func xorNEON(dst, a, b []byte)
+
+// cpuKernels returns the kernels this CPU supports, fastest first. Advanced
+// SIMD (NEON) is mandatory on arm64, so no feature detection is needed.
+func cpuKernels() []kernel {
+	return []kernel{{
+		name:   "neon",
+		reason: "Advanced SIMD is mandatory on arm64",
+		mul: func(dst, src []byte, c byte) int {
+			n := len(src) &^ 15
+			gfMulNibblesNEON(&nibbleTables.low[c], &nibbleTables.high[c], dst[:n], src[:n])
+			return n
+		},
+		mulAdd: func(dst, src []byte, c byte) int {
+			n := len(src) &^ 15
+			gfMulAddNibblesNEON(&nibbleTables.low[c], &nibbleTables.high[c], dst[:n], src[:n])
+			return n
+		},
+		add: func(dst, a, b []byte) int {
+			n := len(dst) &^ 15
+			xorNEON(dst[:n], a[:n], b[:n])
+			return n
+		},
+
+		mulAddTables: func(low, high *[16]byte, dst, src []byte) int {
+			n := len(src) &^ 15
+			gfMulAddNibblesNEON(low, high, dst[:n], src[:n])
+			return n
+		},
+	}}
+}
+
+// genericReason explains why no vector kernel is available, for Kernel. NEON
+// is always available on arm64, so it is never reported.
+func genericReason() string {
+	return "no vector kernel selected"
+}
@@ -0,0 +1,81 @@
+//go:build arm64 && !purego
+
+#include "textflag.h"
+
+// func gfMulNibblesNEON(low, high *[16]byte, dst, src []byte)
+TEXT ·gfMulNibblesNEON(SB), NOSPLIT, $0-64
+	MOVD low+0(FP), R0
+	MOVD high+8(FP), R1
+	MOVD dst_base+16(FP), R2
+	MOVD src_base+40(FP), R3
+	MOVD src_len+48(FP), R4
+	LSR  $4, R4, R4
+	CBZ  R4, done
+
+	VLD1 (R0), [V6.B16] // Low-nibble products
+	VLD1 (R1), [V7.B16] // High-nibble products
+	VMOVI $15, V8.B16   // Nibble mask in every byte
+
+loop:
+	VLD1.P 16(R3), [V0.B16]
+	VUSHR  $4, V0.B16, V1.B16         // High nibbles
+	VAND   V8.B16, V0.B16, V0.B16     // Low nibbles
+	VTBL   V0.B16, [V6.B16], V2.B16
+	VTBL   V1.B16, [V7.B16], V3.B16
+	VEOR   V3.B16, V2.B16, V2.B16
+	VST1.P [V2.B16], 16(R2)
+	SUBS   $1, R4, R4
+	BNE    loop
+
+done:
+	RET
+
+// func gfMulAddNibblesNEON(low, high *[16]byte, dst, src []byte)
+TEXT ·gfMulAddNibblesNEON(SB), NOSPLIT, $0-64
+	MOVD low+0(FP), R0
+	MOVD high+8(FP), R1
+	MOVD dst_base+16(FP), R2
+	MOVD src_base+40(FP), R3
+	MOVD src_len+48(FP), R4
+	LSR  $4, R4, R4
+	CBZ  R4, done
+
+	VLD1 (R0), [V6.B16] // Low-nibble products
+	VLD1 (R1), [V7.B16] // High-nibble products
+	VMOVI $15, V8.B16   // Nibble mask in every byte
+
+loop:
+	VLD1.P 16(R3), [V0.B16]
+	VLD1   (R2), [V4.B16]
+	VUSHR  $4, V0.B16, V1.B16         // High nibbles
+	VAND   V8.B16, V0.B16, V0.B16     // Low nibbles
+	VTBL   V0.B16, [V6.B16], V2.B16
+	VTBL   V1.B16, [V7.B16], V3.B16
+	VEOR   V3.B16, V2.B16, V2.B16
+	VEOR   V4.B16, V2.B16, V2.B16     // Accumulate into dst
+	VST1.P [V2.B16], 16(R2)
+	SUBS   $1, R4, R4
+	BNE    loop
+
+done:
+	RET
+
+// func xorNEON(dst, a, b []byte)
+TEXT ·xorNEON(SB), NOSPLIT, $0-72
+	MOVD dst_base+0(FP), R0
+	MOVD dst_len+8(FP), R1
+	MOVD a_base+24(FP), R2
+	MOVD b_base+48(FP), R3
+	LSR  $4, R1, R1
+	CBZ  R1, done
+
+loop:
+	VLD1.P 16(R2), [V0.B16]
+	VLD1.P 16(R3), [V1.B16]
+	VEOR   V1.B16, V0.B16, V0.B16
+	VST1.P [V0.B16], 16(R0)
+	SUBS   $1, R1, R1
+	BNE    loop
+
+done:
+	RET
@@ -0,0 +1,19 @@
+//go:build (!amd64 && !arm64) || purego
+
+package gf256
+
+import "runtime"
+
+// cpuKernels returns no kernels: this platform has no vector implementation,
+// so the slice operations use table lookups for every byte.
+func cpuKernels() []kernel {
+	return nil
+}
+
+// genericReason explains why no vector kernel is available, for Kernel.
+func genericReason() string {
+	if runtime.GOARCH == "amd64" || runtime.GOARCH == "arm64" {
+		return "built with the purego tag"
+	}
+	return "no vector kernel for " + runtime.GOARCH
+}
@@ -0,0 +1,16 @@
+package gf256
+
+import "encoding/binary"
+
+// xorWordsSafe XORs a and b into dst 8 bytes at a time through encoding/binary,
+// which the compiler turns into single loads and stores where the
+// architecture allows unaligned access, and into byte accesses elsewhere. It
+// handles the longest prefix whose length is a multiple of 8 and returns that
+// length.
+func xorWordsSafe(dst, a, b []byte) int {
+	n := len(dst) &^ 7
+	for i := 0; i < n; i += 8 {
+		binary.LittleEndian.PutUint64(dst[i:], binary.LittleEndian.Uint64(a[i:])^binary.LittleEndian.Uint64(b[i:]))
+	}
+	return n
+}
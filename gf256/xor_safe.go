@@ -0,0 +1,10 @@
+//go:build !(386 || amd64 || arm64 || ppc64 || ppc64le || s390x) || safe
+
+package gf256
+
+// xorWords XORs a prefix of a and b into dst a word at a time and returns its
+// length. Without the unsafe word path (on this architecture, or with the
+// safe build tag), it is xorWordsSafe.
+func xorWords(dst, a, b []byte) int {
+	return xorWordsSafe(dst, a, b)
+}
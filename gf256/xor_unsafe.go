@@ -0,0 +1,36 @@
+//go:build (386 || amd64 || arm64 || ppc64 || ppc64le || s390x) && !safe
+
+package gf256
+
+import "unsafe"
+
+// xorWords XORs a prefix of a and b into dst a word at a time and returns its
+// length. When the three slices share the same offset from 8-byte alignment,
+// it XORs bytes up to the boundary and then whole aligned uint64 words
+// through unsafe pointers; otherwise it falls back to xorWordsSafe. Build
+// with the safe tag to use xorWordsSafe always.
+func xorWords(dst, a, b []byte) int {
+	n := len(dst)
+	if n < 8 {
+		return 0
+	}
+	off := uintptr(unsafe.Pointer(unsafe.SliceData(dst))) & 7
+	if uintptr(unsafe.Pointer(unsafe.SliceData(a)))&7 != off || uintptr(unsafe.Pointer(unsafe.SliceData(b)))&7 != off {
+		return xorWordsSafe(dst, a, b)
+	}
+
+	i := 0
+	if off != 0 {
+		for ; i < int(8-off); i++ {
+			dst[i] = a[i] ^ b[i]
+		}
+	}
+	words := (n - i) / 8
+	d := unsafe.Slice((*uint64)(unsafe.Pointer(&dst[i])), words)
+	x := unsafe.Slice((*uint64)(unsafe.Pointer(&a[i])), words)
+	y := unsafe.Slice((*uint64)(unsafe.Pointer(&b[i])), words)
+	for k := range d {
+		d[k] = x[k] ^ y[k]
+	}
+	return i + 8*words
+}
@@ -0,0 +1,181 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// Group is one node of a hierarchical access structure: Threshold of its
+// members and nested groups together must cooperate. A nested group counts
+// as a single member once its own threshold is met.
+//
+// For example, "2 of 3 executives OR 3 of 5 engineers" is two top-level
+// groups; "the CFO plus 2 of 3 directors" is a group with Threshold 2, the CFO
+// as a member and the directors as a nested group.
+type Group struct {
+	Name      string   // Unique among sibling groups; identifies the group in GroupShare.Path
+	Threshold int      // Members and nested groups required, at least 2
+	Members   []string // Custodians receiving one share of this group each
+	Groups    []Group  // Nested groups
+}
+
+// GroupShare is one custodian's share of a hierarchical split.
+type GroupShare struct {
+	Custodian string
+	Path      []string // Group names from the top level down to the group holding the share
+	Share     []byte   // Raw share within that group
+}
+
+// SplitHierarchical splits a secret for an access structure the flat
+// (parts, threshold) API cannot express. Top-level groups are alternatives:
+// the secret can be recovered by satisfying any one of them. Within a group,
+// the secret (or, for a nested group, the share it stands in for) is split
+// among its members and nested groups, so sharings are layered one per level.
+//
+// A custodian listed in several groups receives one GroupShare per group.
+// Combine with CombineHierarchical and the same groups.
+func SplitHierarchical(secret []byte, groups []Group) ([]GroupShare, error) {
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("shamir: at least one group is required")
+	}
+	if err := validateGroups(groups, nil); err != nil {
+		return nil, err
+	}
+
+	var out []GroupShare
+	for _, g := range groups {
+		shares, err := splitGroup(secret, g, []string{g.Name})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, shares...)
+	}
+	return out, nil
+}
+
+// validateGroups checks names, thresholds and sizes throughout the tree.
+func validateGroups(groups []Group, parent []string) error {
+	names := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		path := append(append([]string(nil), parent...), g.Name)
+		if g.Name == "" || names[g.Name] {
+			return fmt.Errorf("shamir: group %q: names must be non-empty and unique among siblings", path)
+		}
+		names[g.Name] = true
+
+		n := len(g.Members) + len(g.Groups)
+		if err := validateSplitParams([]byte{0}, n, g.Threshold); err != nil {
+			return fmt.Errorf("shamir: group %q: %w", path, err)
+		}
+		if err := validateGroups(g.Groups, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitGroup shares value among a group's members and nested groups. Members
+// take x-coordinates 1..len(Members), nested groups the ones after.
+func splitGroup(value []byte, g Group, path []string) ([]GroupShare, error) {
+	n := len(g.Members) + len(g.Groups)
+	shares, err := split(value, n, g.Threshold, rand.Reader, engine{})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]GroupShare, 0, len(g.Members))
+	for i, member := range g.Members {
+		out = append(out, GroupShare{Custodian: member, Path: path, Share: shares[i]})
+	}
+	for i, sub := range g.Groups {
+		share := shares[len(g.Members)+i]
+		nested, err := splitGroup(share, sub, append(append([]string(nil), path...), sub.Name))
+		secureZeroBytes(share)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, nested...)
+	}
+	return out, nil
+}
+
+// CombineHierarchical recovers the secret from shares of a SplitHierarchical
+// split with the given groups. It tries each top-level group in turn and
+// returns ErrInsufficientShares if none has enough shares. Shares whose path
+// or index does not fit the groups are ignored.
+func CombineHierarchical(groups []Group, shares []GroupShare) ([]byte, error) {
+	if err := validateGroups(groups, nil); err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[string][][]byte)
+	for _, s := range shares {
+		key := groupKey(s.Path)
+		byPath[key] = append(byPath[key], s.Share)
+	}
+
+	for _, g := range groups {
+		secret, err := combineGroup(g, []string{g.Name}, byPath)
+		if err != nil || secret != nil {
+			return secret, err
+		}
+	}
+	return nil, fmt.Errorf("%w: no group has enough shares", ErrInsufficientShares)
+}
+
+// combineGroup reconstructs the value a group was split from, if enough of its
+// members' shares and nested groups' values are available; otherwise it returns
+// nil. The only error it reports is a policy hook refusing a combine.
+func combineGroup(g Group, path []string, byPath map[string][][]byte) ([]byte, error) {
+	var parts [][]byte
+	seen := make(map[byte]bool)
+	for _, share := range byPath[groupKey(path)] {
+		if len(share) < 2 || share[0] == 0 || int(share[0]) > len(g.Members) || seen[share[0]] {
+			continue
+		}
+		seen[share[0]] = true
+		parts = append(parts, share)
+	}
+
+	var recovered [][]byte
+	defer func() {
+		for _, r := range recovered {
+			freeSecret(r)
+		}
+	}()
+	for _, sub := range g.Groups {
+		if len(parts) >= g.Threshold {
+			break
+		}
+		value, err := combineGroup(sub, append(append([]string(nil), path...), sub.Name), byPath)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			recovered = append(recovered, value)
+			parts = append(parts, value)
+		}
+	}
+
+	if len(parts) < g.Threshold {
+		return nil, nil
+	}
+	value, err := Combine(parts[:g.Threshold])
+	if errors.Is(err, ErrPolicyDenied) {
+		return nil, err
+	}
+	return value, nil
+}
+
+// groupKey joins a path into a map key; NUL cannot appear in a meaningful name.
+func groupKey(path []string) string {
+	key := ""
+	for _, name := range path {
+		key += name + "\x00"
+	}
+	return key
+}
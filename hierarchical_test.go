@@ -0,0 +1,130 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func filterGroupShares(shares []GroupShare, custodians ...string) []GroupShare {
+	want := make(map[string]bool, len(custodians))
+	for _, c := range custodians {
+		want[c] = true
+	}
+	var out []GroupShare
+	for _, s := range shares {
+		if want[s.Custodian] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func TestSplitHierarchical(t *testing.T) {
+	secret := []byte("enterprise root key")
+	groups := []Group{
+		{Name: "executives", Threshold: 2, Members: []string{"ceo", "cfo", "cto"}},
+		{Name: "engineers", Threshold: 3, Members: []string{"e1", "e2", "e3", "e4", "e5"}},
+	}
+	shares, err := SplitHierarchical(secret, groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 8 {
+		t.Fatalf("got %d shares, want 8", len(shares))
+	}
+
+	tests := []struct {
+		name       string
+		custodians []string
+		ok         bool
+	}{
+		{"two executives", []string{"ceo", "cto"}, true},
+		{"three engineers", []string{"e1", "e3", "e5"}, true},
+		{"one executive and two engineers", []string{"cfo", "e2", "e4"}, false},
+		{"two engineers", []string{"e1", "e2"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CombineHierarchical(groups, filterGroupShares(shares, tt.custodians...))
+			if !tt.ok {
+				if !errors.Is(err, ErrInsufficientShares) {
+					t.Errorf("expected ErrInsufficientShares, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, secret) {
+				t.Fatal("wrong secret")
+			}
+		})
+	}
+}
+
+func TestSplitHierarchicalNested(t *testing.T) {
+	secret := []byte("nested")
+	// The CFO plus 2 of 3 directors, or 2 of 3 directors plus 2 of 2 auditors
+	groups := []Group{{
+		Name: "board", Threshold: 2, Members: []string{"cfo"},
+		Groups: []Group{
+			{Name: "directors", Threshold: 2, Members: []string{"d1", "d2", "d3"}},
+			{Name: "auditors", Threshold: 2, Members: []string{"a1", "a2"}},
+		},
+	}}
+	shares, err := SplitHierarchical(secret, groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		custodians []string
+		ok         bool
+	}{
+		{[]string{"cfo", "d1", "d3"}, true},
+		{[]string{"d2", "d3", "a1", "a2"}, true},
+		{[]string{"cfo", "a1", "a2"}, true},
+		{[]string{"cfo", "d1", "a1"}, false},
+		{[]string{"d1", "d2", "d3"}, false},
+	}
+	for _, tt := range tests {
+		got, err := CombineHierarchical(groups, filterGroupShares(shares, tt.custodians...))
+		if tt.ok {
+			if err != nil || !bytes.Equal(got, secret) {
+				t.Errorf("%v: expected recovery, got %v", tt.custodians, err)
+			}
+		} else if !errors.Is(err, ErrInsufficientShares) {
+			t.Errorf("%v: expected ErrInsufficientShares, got %v", tt.custodians, err)
+		}
+	}
+}
+
+func TestSplitHierarchicalValidation(t *testing.T) {
+	secret := []byte("x")
+	tests := []struct {
+		name   string
+		groups []Group
+	}{
+		{"no groups", nil},
+		{"unnamed", []Group{{Threshold: 2, Members: []string{"a", "b"}}}},
+		{"duplicate names", []Group{
+			{Name: "g", Threshold: 2, Members: []string{"a", "b"}},
+			{Name: "g", Threshold: 2, Members: []string{"c", "d"}},
+		}},
+		{"threshold above members", []Group{{Name: "g", Threshold: 3, Members: []string{"a", "b"}}}},
+		{"threshold 1", []Group{{Name: "g", Threshold: 1, Members: []string{"a", "b"}}}},
+		{"bad nested group", []Group{{Name: "g", Threshold: 2, Members: []string{"a"},
+			Groups: []Group{{Name: "h", Threshold: 2, Members: []string{"b"}}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := SplitHierarchical(secret, tt.groups); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+	if _, err := SplitHierarchical(nil, []Group{{Name: "g", Threshold: 2, Members: []string{"a", "b"}}}); err != ErrEmptySecret {
+		t.Errorf("expected ErrEmptySecret, got %v", err)
+	}
+}
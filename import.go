@@ -0,0 +1,170 @@
+package shamir
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Share formats recognised by Import, in addition to registered ShareCodecs.
+const (
+	FormatEnvelope = "envelope" // Binary share envelope, see ParseShare
+	FormatPEM      = "pem"      // SHAMIR SHARE PEM block, see EncodeSharePEM
+	FormatJSON     = "json"     // JSON share object, see Share.MarshalJSON
+	FormatRaw      = "raw"      // Raw share from Split, only with ImportLimits.AllowRaw
+)
+
+// ImportLimits bounds what Import accepts from an untrusted source.
+type ImportLimits struct {
+	MaxSize            int  // Maximum blob size in bytes
+	MaxMetadataRecords int  // Maximum number of envelope metadata records
+	MaxMetadataSize    int  // Maximum total size of envelope metadata in bytes
+	AllowRaw           bool // Accept raw shares, which carry no threshold or checksum
+}
+
+// DefaultImportLimits returns conservative limits suitable for shares received
+// by email or upload: 64 KiB blobs, at most 8 metadata records totalling 1 KiB,
+// and enveloped shares only.
+func DefaultImportLimits() ImportLimits {
+	return ImportLimits{
+		MaxSize:            64 << 10,
+		MaxMetadataRecords: 8,
+		MaxMetadataSize:    1 << 10,
+	}
+}
+
+// QuarantinedShare is a share imported from an untrusted source. Its metadata
+// can be inspected, but the share itself is only released by Promote, so
+// unreviewed input cannot reach Combine by accident.
+type QuarantinedShare struct {
+	Format      string           // Format the blob was decoded from (Format* constants or a ShareCodec name)
+	Index       byte             // x-coordinate of the share
+	Threshold   int              // Threshold recorded in the envelope, 0 for raw shares
+	Algorithm   string           // Key algorithm recorded in the envelope, if any
+	Purpose     string           // Purpose the share is bound to, if any
+	SetID       SetID            // Set the share belongs to, zero if not recorded
+	Label       string           // Label recorded in the envelope, if any
+	CreatedAt   time.Time        // Creation time recorded in the envelope, zero if not recorded
+	NotBefore   time.Time        // Start of the validity window, zero if unbounded
+	NotAfter    time.Time        // End of the validity window, zero if unbounded
+	Fingerprint ShareFingerprint // Fingerprint of the decoded binary share
+
+	data []byte
+}
+
+// Import decodes an untrusted share blob in any supported format (binary
+// envelope, PEM, JSON, a registered ShareCodec, or raw if allowed) and
+// validates it against limits.
+// The blob is never modified. Violations are reported as ErrShareRejected;
+// checksum failures as ErrIntegrityCheckFailed.
+func Import(blob []byte, limits ImportLimits) (*QuarantinedShare, error) {
+	if limits.MaxSize > 0 && len(blob) > limits.MaxSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrShareRejected, len(blob), limits.MaxSize)
+	}
+
+	format, share, err := decodeImport(blob, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &QuarantinedShare{
+		Format:      format,
+		Fingerprint: FingerprintShare(share),
+		data:        share,
+	}
+
+	if format == FormatRaw {
+		q.Index = share[0]
+		return q, nil
+	}
+
+	s, err := ParseShare(share)
+	if err != nil {
+		return nil, err
+	}
+	secureZeroBytes(s.Payload)
+	if err := checkMetadataLimits(share, s, limits); err != nil {
+		secureZeroBytes(share)
+		return nil, err
+	}
+	q.Index, q.Threshold = s.Index, s.Threshold
+	q.Algorithm, q.Purpose, q.SetID = s.Algorithm, s.Purpose, s.SetID
+	q.Label, q.CreatedAt = s.Label, s.CreatedAt
+	q.NotBefore, q.NotAfter = s.NotBefore, s.NotAfter
+
+	return q, nil
+}
+
+// decodeImport detects the blob's format and returns a private copy of the binary share.
+func decodeImport(blob []byte, limits ImportLimits) (string, []byte, error) {
+	format, share, err := decodeShare(blob)
+	switch {
+	case err != nil:
+		return "", nil, err
+
+	case format == "" && limits.AllowRaw:
+		if err := validateShare(blob); err != nil {
+			return "", nil, fmt.Errorf("%w: %v", ErrShareRejected, err)
+		}
+		return FormatRaw, append([]byte(nil), blob...), nil
+
+	case format == "":
+		return "", nil, fmt.Errorf("%w: unrecognised share format", ErrShareRejected)
+
+	case !IsEnvelope(share) && !limits.AllowRaw:
+		secureZeroBytes(share)
+		return "", nil, fmt.Errorf("%w: raw shares are not allowed", ErrShareRejected)
+	}
+	return format, share, nil
+}
+
+// checkMetadataLimits enforces the metadata bounds on a decoded envelope.
+func checkMetadataLimits(share []byte, s *Share, limits ImportLimits) error {
+	metaLen := int(binary.BigEndian.Uint16(share[7:9]))
+	if limits.MaxMetadataSize > 0 && metaLen > limits.MaxMetadataSize {
+		return fmt.Errorf("%w: %d bytes of metadata exceeds limit of %d", ErrShareRejected, metaLen, limits.MaxMetadataSize)
+	}
+
+	records := len(s.extra)
+	for _, present := range []bool{
+		s.Algorithm != "", s.Purpose != "", len(s.purposeMAC) > 0, !s.SetID.IsZero(), s.Label != "", !s.CreatedAt.IsZero(),
+		!s.NotBefore.IsZero(), !s.NotAfter.IsZero(), len(s.commitment) > 0,
+	} {
+		if present {
+			records++
+		}
+	}
+	if limits.MaxMetadataRecords > 0 && records > limits.MaxMetadataRecords {
+		return fmt.Errorf("%w: %d metadata records exceeds limit of %d", ErrShareRejected, records, limits.MaxMetadataRecords)
+	}
+	return nil
+}
+
+// Promote releases the share for use with Combine and its variants. The
+// quarantine is emptied, so a share can be promoted only once.
+func (q *QuarantinedShare) Promote() ([]byte, error) {
+	if q.data == nil {
+		return nil, fmt.Errorf("%w: share already promoted or discarded", ErrShareRejected)
+	}
+	share := q.data
+	q.data = nil
+	return share, nil
+}
+
+// PromoteWithManifest promotes the share only if it is listed in the manifest.
+// The share stays quarantined if it is not.
+func (q *QuarantinedShare) PromoteWithManifest(m *Manifest) ([]byte, error) {
+	if q.data == nil {
+		return nil, fmt.Errorf("%w: share already promoted or discarded", ErrShareRejected)
+	}
+	if _, err := m.Custodian(q.data); err != nil {
+		return nil, err
+	}
+	return q.Promote()
+}
+
+// Discard wipes the quarantined share without promoting it.
+func (q *QuarantinedShare) Discard() {
+	secureZeroBytes(q.data)
+	q.data = nil
+}
@@ -0,0 +1,165 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestImport(t *testing.T) {
+	key := bytes.Repeat([]byte{0x11}, 32)
+	shares, err := SplitKey(key, AlgAES256GCM, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pemBlock, err := EncodeSharePEM(shares[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ParseShare(shares[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonBlob, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blobs := []struct {
+		format string
+		blob   []byte
+		share  []byte
+	}{
+		{FormatEnvelope, shares[0], shares[0]},
+		{FormatPEM, pemBlock, shares[1]},
+		{FormatJSON, append([]byte("\n  "), jsonBlob...), shares[2]},
+	}
+
+	var promoted [][]byte
+	for _, b := range blobs {
+		q, err := Import(b.blob, DefaultImportLimits())
+		if err != nil {
+			t.Fatalf("%s: %v", b.format, err)
+		}
+		if q.Format != b.format || q.Threshold != 2 || q.Algorithm != AlgAES256GCM {
+			t.Fatalf("%s: unexpected metadata %+v", b.format, q)
+		}
+		if q.Fingerprint != FingerprintShare(b.share) {
+			t.Fatalf("%s: fingerprint mismatch", b.format)
+		}
+
+		share, err := q.Promote()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(share, b.share) {
+			t.Fatalf("%s: promoted share differs", b.format)
+		}
+		if _, err := q.Promote(); !errors.Is(err, ErrShareRejected) {
+			t.Fatalf("%s: expected second promotion to fail, got %v", b.format, err)
+		}
+		promoted = append(promoted, share)
+	}
+
+	recovered, err := CombineKey(promoted[1:], AlgAES256GCM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, key) {
+		t.Fatal("key mismatch after import")
+	}
+
+	// Importing must not alias the caller's buffer.
+	q, _ := Import(shares[0], DefaultImportLimits())
+	q.Discard()
+	if _, err := ParseShare(shares[0]); err != nil {
+		t.Fatal("Discard wiped the caller's blob")
+	}
+}
+
+func TestImportLimits(t *testing.T) {
+	raw, err := Split([]byte("raw share"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("raw rejected by default", func(t *testing.T) {
+		if _, err := Import(raw[0], DefaultImportLimits()); !errors.Is(err, ErrShareRejected) {
+			t.Fatalf("expected ErrShareRejected, got %v", err)
+		}
+		pemBlock, _ := EncodeSharePEM(raw[0])
+		if _, err := Import(pemBlock, DefaultImportLimits()); !errors.Is(err, ErrShareRejected) {
+			t.Fatalf("expected ErrShareRejected for raw PEM, got %v", err)
+		}
+	})
+
+	t.Run("raw allowed", func(t *testing.T) {
+		limits := DefaultImportLimits()
+		limits.AllowRaw = true
+		q, err := Import(raw[1], limits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.Format != FormatRaw || q.Index != 2 || q.Threshold != 0 {
+			t.Fatalf("unexpected metadata %+v", q)
+		}
+	})
+
+	t.Run("size", func(t *testing.T) {
+		limits := DefaultImportLimits()
+		limits.MaxSize = 16
+		big := &Share{Threshold: 2, Index: 1, Payload: make([]byte, 32)}
+		blob, _ := big.MarshalBinary()
+		if _, err := Import(blob, limits); !errors.Is(err, ErrShareRejected) {
+			t.Fatalf("expected ErrShareRejected, got %v", err)
+		}
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		crowded := &Share{Threshold: 2, Index: 1, Payload: []byte{1}}
+		for tag := byte(100); tag < 110; tag++ {
+			crowded.extra = append(crowded.extra, metaRecord{tag, []byte("x")})
+		}
+		blob, _ := crowded.MarshalBinary()
+		if _, err := Import(blob, DefaultImportLimits()); !errors.Is(err, ErrShareRejected) {
+			t.Fatalf("expected ErrShareRejected for too many records, got %v", err)
+		}
+
+		bulky := &Share{Threshold: 2, Index: 1, Payload: []byte{1}, Purpose: string(make([]byte, 2000))}
+		blob, _ = bulky.MarshalBinary()
+		if _, err := Import(blob, DefaultImportLimits()); !errors.Is(err, ErrShareRejected) {
+			t.Fatalf("expected ErrShareRejected for oversized metadata, got %v", err)
+		}
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		shares, _ := SplitKey(make([]byte, 16), AlgAES128GCM, 3, 2)
+		bad := append([]byte(nil), shares[0]...)
+		bad[len(bad)-5] ^= 1
+		if _, err := Import(bad, DefaultImportLimits()); err != ErrIntegrityCheckFailed {
+			t.Fatalf("expected ErrIntegrityCheckFailed, got %v", err)
+		}
+	})
+
+	t.Run("promote with manifest", func(t *testing.T) {
+		shares, m, err := SplitWithDecoys([]byte("listed"), 3, 2, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, _ := Import(shares[0], DefaultImportLimits())
+		if _, err := q.PromoteWithManifest(m); err != nil {
+			t.Fatal(err)
+		}
+
+		stranger, _ := SplitKey(make([]byte, 16), AlgAES128GCM, 3, 2)
+		q, _ = Import(stranger[0], DefaultImportLimits())
+		if _, err := q.PromoteWithManifest(m); err != ErrUnknownShare {
+			t.Fatalf("expected ErrUnknownShare, got %v", err)
+		}
+		if _, err := q.Promote(); err != nil {
+			t.Fatal("unlisted share should stay quarantined, not be discarded")
+		}
+	})
+}
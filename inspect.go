@@ -0,0 +1,228 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"time"
+)
+
+// ShareInfo describes a share without reconstructing anything from it, as
+// returned by InspectShare. Fields the share's format does not record are
+// zero.
+type ShareInfo struct {
+	// Format is "raw" for shares from Split, "integrity" for shares from
+	// SplitWithIntegrity, "enveloped", "multi-secret" or "prepared", or the
+	// name of a format whose contents are opaque without a key or the other
+	// shares: "framed stream", "sealed ciphertext", "X25519-encrypted",
+	// "passphrase-protected", "threshold ciphertext", "KMS-wrapped" or
+	// "token-wrapped".
+	// Versioned formats from a newer release read as "newer versioned".
+	Format string
+
+	// Encoding is "PEM" or "JSON" if the share was given in that text
+	// encoding, and empty for binary shares.
+	Encoding string
+
+	Version    int    // Format version, 0 for raw shares which have none
+	Index      byte   // x-coordinate
+	Threshold  int    // Shares required for reconstruction, 0 if not recorded
+	PayloadLen int    // Number of y-values, the length of the secret(s)
+	SetID      SetID  // Split the share belongs to, zero if not recorded
+	Label      string // Operational label, see WithLabels
+
+	NotBefore time.Time // Start of the validity window, zero if unbounded; see WithValidity
+	NotAfter  time.Time // End of the validity window, zero if unbounded
+
+	HasIntegrity   bool // The share carries a checksum
+	IntegrityValid bool // The checksum matches; header fields are unverified otherwise
+
+	// Fingerprint is FingerprintShare of the binary share, after any text
+	// encoding is removed, for looking the share up in a Manifest.
+	Fingerprint ShareFingerprint
+}
+
+// InspectShare reports the format, index, payload length, checksum state and
+// set ID of a share, for operational dashboards and for sorting out shares
+// that have been mixed up. It never needs other shares or any key.
+//
+// A share whose checksum does not match is not an error: InspectShare returns
+// what the header claims with IntegrityValid false. Raw shares carry no
+// checksum, so a share from SplitWithIntegrity with a damaged trailer reads as
+// a raw share. PEM and JSON shares are verified as they are decoded, so a
+// damaged one is an error.
+func InspectShare(share []byte) (ShareInfo, error) {
+	switch format := shareFormat(share); format {
+	case "PEM":
+		decoded, _, err := DecodeSharePEM(share)
+		if err != nil {
+			return ShareInfo{}, err
+		}
+		return inspectEncoded(decoded, "PEM")
+	case "JSON":
+		decoded, err := decodeShareJSON(share)
+		if err != nil {
+			return ShareInfo{}, err
+		}
+		return inspectEncoded(decoded, "JSON")
+	case "":
+		return inspectRaw(share)
+	case "enveloped":
+		return inspectEnvelope(share)
+	case "multi-secret":
+		return inspectMulti(share)
+	case "prepared":
+		return inspectPrepared(share)
+	default:
+		return ShareInfo{Format: format, Fingerprint: FingerprintShare(share)}, nil
+	}
+}
+
+// inspectEncoded inspects a share decoded from a text encoding.
+func inspectEncoded(share []byte, encoding string) (ShareInfo, error) {
+	info, err := InspectShare(share)
+	info.Encoding = encoding
+	return info, err
+}
+
+// decodeShareJSON decodes a single JSON share object, or an array from
+// EncodeSharesJSON holding exactly one share, into a binary envelope.
+func decodeShareJSON(data []byte) ([]byte, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		decoded, err := DecodeSharesJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		if len(decoded) != 1 {
+			return nil, NewValidationError("share", len(decoded), "shamir: JSON holds more than one share")
+		}
+		return decoded[0], nil
+	}
+
+	var s Share
+	if err := s.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	defer secureZeroBytes(s.Payload)
+	return s.MarshalBinary()
+}
+
+// inspectRaw inspects a share from Split or SplitWithIntegrity.
+func inspectRaw(share []byte) (ShareInfo, error) {
+	if err := validateShare(share); err != nil {
+		return ShareInfo{}, err
+	}
+	info := ShareInfo{
+		Format:      "raw",
+		Index:       share[0],
+		PayloadLen:  len(share) - ShareOverhead,
+		Fingerprint: FingerprintShare(share),
+	}
+	if hasIntegrityTrailer(share) {
+		info.Format = "integrity"
+		info.PayloadLen -= 4
+		info.HasIntegrity, info.IntegrityValid = true, true
+	}
+	return info, nil
+}
+
+// trailerValid reports whether data ends in the big-endian CRC32 of the
+// preceding bytes, the checksum of every magic-prefixed format.
+func trailerValid(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	body := data[:len(data)-4]
+	return crc32.ChecksumIEEE(body) == binary.BigEndian.Uint32(data[len(body):])
+}
+
+// inspectEnvelope inspects an enveloped share, reading the header directly if
+// the checksum does not match.
+func inspectEnvelope(share []byte) (ShareInfo, error) {
+	info := ShareInfo{
+		Format:       "enveloped",
+		HasIntegrity: true,
+		Fingerprint:  FingerprintShare(share),
+	}
+	if len(share) < envelopeHeaderSize+envelopeChecksumSize+1 {
+		return ShareInfo{}, ErrInvalidEnvelope
+	}
+	info.Version = int(share[3])
+	if !trailerValid(share) {
+		info.Threshold, info.Index = int(share[5]), share[6]
+		if n := len(share) - envelopeHeaderSize - envelopeChecksumSize - int(binary.BigEndian.Uint16(share[7:9])); n > 0 {
+			info.PayloadLen = n
+		}
+		return info, nil
+	}
+
+	s, err := ParseShare(share)
+	if err != nil {
+		return info, err
+	}
+	secureZeroBytes(s.Payload)
+	info.IntegrityValid = true
+	info.Threshold, info.Index = s.Threshold, s.Index
+	info.PayloadLen = len(s.Payload)
+	info.SetID, info.Label = s.SetID, s.Label
+	info.NotBefore, info.NotAfter = s.NotBefore, s.NotAfter
+	return info, nil
+}
+
+// inspectMulti inspects a multi-secret share. PayloadLen is the total length
+// of all the secrets it holds.
+func inspectMulti(share []byte) (ShareInfo, error) {
+	info := ShareInfo{
+		Format:       "multi-secret",
+		HasIntegrity: true,
+		Fingerprint:  FingerprintShare(share),
+	}
+	if len(share) < multiHeaderSize+multiChecksumSize {
+		return ShareInfo{}, ErrInvalidMultiShare
+	}
+	info.Version = int(share[len(multiMagic)])
+	if !trailerValid(share) {
+		p := len(multiMagic) + 1
+		p += copy(info.SetID[:], share[p:])
+		info.Threshold, info.Index = int(share[p]), share[p+1]
+		return info, nil
+	}
+
+	s, err := parseMultiShare(share)
+	if err != nil {
+		return info, err
+	}
+	info.IntegrityValid = true
+	info.SetID, info.Threshold, info.Index = s.setID, s.threshold, s.index
+	for _, payload := range s.payloads {
+		info.PayloadLen += len(payload)
+	}
+	return info, nil
+}
+
+// inspectPrepared inspects a prepared share. Its threshold is the size of the
+// quorum it was prepared for.
+func inspectPrepared(share []byte) (ShareInfo, error) {
+	info := ShareInfo{
+		Format:       "prepared",
+		HasIntegrity: true,
+		Fingerprint:  FingerprintShare(share),
+	}
+	if len(share) < preparedHeaderSize+preparedChecksumSize {
+		return ShareInfo{}, ErrInvalidPreparedShare
+	}
+	info.Version = int(share[len(preparedMagic)])
+	if !trailerValid(share) {
+		info.Index, info.Threshold = share[4], int(share[5])
+		return info, nil
+	}
+
+	s, err := parsePreparedShare(share)
+	if err != nil {
+		return info, err
+	}
+	info.IntegrityValid = true
+	info.Index, info.Threshold = s.index, len(s.quorum)
+	info.PayloadLen = len(s.payload)
+	return info, nil
+}
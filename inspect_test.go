@@ -0,0 +1,129 @@
+package shamir
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInspectShare(t *testing.T) {
+	secret := []byte("inspect me")
+
+	raw, err := Split(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checked, err := SplitWithIntegrity(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enveloped, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithLabels("a", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	setID, err := ShareSetID(enveloped[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	multi, err := SplitMulti(map[string][]byte{"a": secret, "b": []byte("xy")}, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	multiID, err := ShareSetID(multi[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	prepared, err := PrepareShare(raw[0], []byte{1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemShare, err := EncodeSharePEM(enveloped[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonShares, err := EncodeSharesJSON(enveloped[1:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		share []byte
+		want  ShareInfo
+	}{
+		"raw": {raw[1], ShareInfo{Format: "raw", Index: 2, PayloadLen: 10, Fingerprint: FingerprintShare(raw[1])}},
+		"integrity": {checked[2], ShareInfo{Format: "integrity", Index: 3, PayloadLen: 10,
+			HasIntegrity: true, IntegrityValid: true, Fingerprint: FingerprintShare(checked[2])}},
+		"enveloped": {enveloped[1], ShareInfo{Format: "enveloped", Version: EnvelopeVersion, Index: 2, Threshold: 2,
+			PayloadLen: 10, SetID: setID, Label: "b", HasIntegrity: true, IntegrityValid: true,
+			Fingerprint: FingerprintShare(enveloped[1])}},
+		"multi-secret": {multi[2], ShareInfo{Format: "multi-secret", Version: 1, Index: 3, Threshold: 2,
+			PayloadLen: 12, SetID: multiID, HasIntegrity: true, IntegrityValid: true,
+			Fingerprint: FingerprintShare(multi[2])}},
+		"prepared": {prepared, ShareInfo{Format: "prepared", Version: 1, Index: 1, Threshold: 2,
+			PayloadLen: 10, HasIntegrity: true, IntegrityValid: true, Fingerprint: FingerprintShare(prepared)}},
+		"PEM": {pemShare, ShareInfo{Format: "enveloped", Encoding: "PEM", Version: EnvelopeVersion, Index: 2,
+			Threshold: 2, PayloadLen: 10, SetID: setID, Label: "b", HasIntegrity: true, IntegrityValid: true,
+			Fingerprint: FingerprintShare(enveloped[1])}},
+		"JSON": {jsonShares, ShareInfo{Format: "enveloped", Encoding: "JSON", Version: EnvelopeVersion, Index: 2,
+			Threshold: 2, PayloadLen: 10, SetID: setID, Label: "b", HasIntegrity: true, IntegrityValid: true,
+			Fingerprint: FingerprintShare(enveloped[1])}},
+	} {
+		got, err := InspectShare(tc.share)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s:\n got %+v\nwant %+v", name, got, tc.want)
+		}
+	}
+}
+
+func TestInspectShareCorrupted(t *testing.T) {
+	enveloped, err := SplitWithOptions([]byte("corrupt"), WithParts(4), WithThreshold(3), WithPurpose("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	damaged := append([]byte(nil), enveloped[3]...)
+	damaged[len(damaged)-6] ^= 1
+
+	info, err := InspectShare(damaged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.HasIntegrity || info.IntegrityValid {
+		t.Errorf("damaged envelope: HasIntegrity %v, IntegrityValid %v", info.HasIntegrity, info.IntegrityValid)
+	}
+	if info.Index != 4 || info.Threshold != 3 || info.PayloadLen != 7 {
+		t.Errorf("damaged envelope header: %+v", info)
+	}
+
+	checked, err := SplitWithIntegrity([]byte("corrupt"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checked[0][1] ^= 1
+	if info, err := InspectShare(checked[0]); err != nil || info.Format != "raw" || info.HasIntegrity {
+		t.Errorf("damaged integrity share: %+v, %v", info, err)
+	}
+}
+
+func TestInspectShareOpaqueAndInvalid(t *testing.T) {
+	ciphertext, _, err := SealSplit([]byte("sealed"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := InspectShare(ciphertext); err != nil || info.Format != "sealed ciphertext" {
+		t.Errorf("sealed ciphertext: %+v, %v", info, err)
+	}
+
+	if _, err := InspectShare(nil); !errors.Is(err, ErrTooShort) {
+		t.Errorf("empty share: got %v, want ErrTooShort", err)
+	}
+	var verr *ValidationError
+	if _, err := InspectShare([]byte{0, 1, 2}); !errors.As(err, &verr) {
+		t.Errorf("zero x-coordinate: got %v, want ValidationError", err)
+	}
+	if _, err := InspectShare([]byte("-----BEGIN SHAMIR SHARE-----\nbroken")); !errors.Is(err, ErrInvalidPEM) {
+		t.Errorf("broken PEM: got %v, want ErrInvalidPEM", err)
+	}
+}
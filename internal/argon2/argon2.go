@@ -0,0 +1,230 @@
+// Package argon2 implements the Argon2id memory-hard key derivation function
+// (RFC 9106), for deriving share encryption keys from passphrases without
+// depending on golang.org/x/crypto.
+package argon2
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"sync"
+)
+
+const (
+	version    = 0x13
+	typeID     = 2 // Argon2id
+	syncPoints = 4 // slices per pass
+	blockWords = 128
+)
+
+// block is one 1 KiB memory block.
+type block [blockWords]uint64
+
+// IDKey derives a keyLen-byte key from password and salt with Argon2id, using
+// time passes over memory KiB split into threads lanes. The lanes of each slice
+// are filled concurrently. time and threads must be at least 1.
+func IDKey(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	return deriveKey(password, salt, nil, nil, time, memory, threads, keyLen)
+}
+
+// deriveKey is Argon2id with the optional secret and associated data inputs,
+// which the test vectors use.
+func deriveKey(password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	if time < 1 || threads < 1 {
+		panic("argon2: time and threads must be at least 1")
+	}
+	lanes := uint32(threads)
+
+	h := newBlake2b(64)
+	for _, v := range []uint32{lanes, keyLen, memory, time, version, typeID} {
+		h.writeUint32(v)
+	}
+	for _, p := range [][]byte{password, salt, secret, data} {
+		h.writeUint32(uint32(len(p)))
+		h.Write(p)
+	}
+	var h0 [72]byte
+	h.Sum(h0[:0])
+	defer clear(h0[:])
+
+	memory = memory / (syncPoints * lanes) * (syncPoints * lanes)
+	if memory < 2*syncPoints*lanes {
+		memory = 2 * syncPoints * lanes
+	}
+	B := make([]block, memory)
+	defer clear(B)
+	laneLength := memory / lanes
+
+	var buf [1024]byte
+	for lane := range lanes {
+		for i := range uint32(2) {
+			binary.LittleEndian.PutUint32(h0[64:], i)
+			binary.LittleEndian.PutUint32(h0[68:], lane)
+			blake2bLong(buf[:], h0[:])
+			for k := range B[lane*laneLength+i] {
+				B[lane*laneLength+i][k] = binary.LittleEndian.Uint64(buf[8*k:])
+			}
+		}
+	}
+
+	fillMemory(B, time, memory, lanes)
+
+	final := B[memory-1]
+	for lane := range lanes - 1 {
+		for k, w := range B[lane*laneLength+laneLength-1] {
+			final[k] ^= w
+		}
+	}
+	for k, w := range final {
+		binary.LittleEndian.PutUint64(buf[8*k:], w)
+	}
+	key := make([]byte, keyLen)
+	blake2bLong(key, buf[:])
+	clear(buf[:])
+	return key
+}
+
+// fillMemory runs the passes over B, one slice at a time.
+func fillMemory(B []block, time, memory, lanes uint32) {
+	laneLength := memory / lanes
+	segmentLength := laneLength / syncPoints
+
+	fillSegment := func(pass, slice, lane uint32) {
+		// The first half of the first pass is data-independent (Argon2i),
+		// taking reference positions from a pseudo-random address stream.
+		independent := pass == 0 && slice < syncPoints/2
+		var addresses, input, zero block
+		if independent {
+			input[0] = uint64(pass)
+			input[1] = uint64(lane)
+			input[2] = uint64(slice)
+			input[3] = uint64(memory)
+			input[4] = uint64(time)
+			input[5] = typeID
+		}
+
+		index := uint32(0)
+		if pass == 0 && slice == 0 {
+			index = 2 // the first two blocks of each lane are set from H0
+			input[6]++
+			compress(&addresses, &input, &zero, false)
+			compress(&addresses, &addresses, &zero, false)
+		}
+		offset := lane*laneLength + slice*segmentLength + index
+		for ; index < segmentLength; index, offset = index+1, offset+1 {
+			prev := offset - 1
+			if index == 0 && slice == 0 {
+				prev += laneLength
+			}
+			var random uint64
+			if independent {
+				if index%blockWords == 0 {
+					input[6]++
+					compress(&addresses, &input, &zero, false)
+					compress(&addresses, &addresses, &zero, false)
+				}
+				random = addresses[index%blockWords]
+			} else {
+				random = B[prev][0]
+			}
+			ref := referenceIndex(random, laneLength, segmentLength, lanes, pass, slice, lane, index)
+			// From version 0x13 later passes XOR into the old block; in the
+			// first pass the block is still zero, so XOR is plain assignment.
+			compress(&B[offset], &B[prev], &B[ref], true)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for pass := range time {
+		for slice := range uint32(syncPoints) {
+			for lane := range lanes {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					fillSegment(pass, slice, lane)
+				}()
+			}
+			wg.Wait()
+		}
+	}
+}
+
+// referenceIndex maps a pseudo-random value to the block the current block
+// references (RFC 9106, section 3.4.1.2).
+func referenceIndex(random uint64, laneLength, segmentLength, lanes, pass, slice, lane, index uint32) uint32 {
+	refLane := uint32(random>>32) % lanes
+	if pass == 0 && slice == 0 {
+		refLane = lane
+	}
+
+	// The reference area is every finished block the current one may see.
+	area, start := 3*segmentLength, ((slice+1)%syncPoints)*segmentLength
+	if lane == refLane {
+		area += index
+	}
+	if pass == 0 {
+		area, start = slice*segmentLength, 0
+		if slice == 0 || lane == refLane {
+			area += index
+		}
+	}
+	if index == 0 || lane == refLane {
+		area--
+	}
+
+	x := random & 0xFFFFFFFF
+	x = x * x >> 32
+	x = uint64(area) * x >> 32
+	relative := uint64(area) - 1 - x
+	return refLane*laneLength + uint32((uint64(start)+relative)%uint64(laneLength))
+}
+
+// compress is the compression function G: out = G(x, y), or out ^= G(x, y).
+func compress(out, x, y *block, xor bool) {
+	var r, q block
+	for i := range r {
+		r[i] = x[i] ^ y[i]
+	}
+	q = r
+	for i := 0; i < blockWords; i += 16 {
+		permute(&q, i, i+1, i+2, i+3, i+4, i+5, i+6, i+7, i+8, i+9, i+10, i+11, i+12, i+13, i+14, i+15)
+	}
+	for i := 0; i < blockWords/8; i += 2 {
+		permute(&q, i, i+1, i+16, i+17, i+32, i+33, i+48, i+49, i+64, i+65, i+80, i+81, i+96, i+97, i+112, i+113)
+	}
+	if xor {
+		for i := range out {
+			out[i] ^= r[i] ^ q[i]
+		}
+	} else {
+		for i := range out {
+			out[i] = r[i] ^ q[i]
+		}
+	}
+}
+
+// permute is the permutation P on sixteen words of b: a BLAKE2b round with
+// the additions replaced by BlaMka's multiply-add.
+func permute(b *block, i0, i1, i2, i3, i4, i5, i6, i7, i8, i9, i10, i11, i12, i13, i14, i15 int) {
+	v := [16]uint64{b[i0], b[i1], b[i2], b[i3], b[i4], b[i5], b[i6], b[i7], b[i8], b[i9], b[i10], b[i11], b[i12], b[i13], b[i14], b[i15]}
+	g := func(a, b, c, d int) {
+		v[a] += v[b] + 2*uint64(uint32(v[a]))*uint64(uint32(v[b]))
+		v[d] = bits.RotateLeft64(v[d]^v[a], -32)
+		v[c] += v[d] + 2*uint64(uint32(v[c]))*uint64(uint32(v[d]))
+		v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+		v[a] += v[b] + 2*uint64(uint32(v[a]))*uint64(uint32(v[b]))
+		v[d] = bits.RotateLeft64(v[d]^v[a], -16)
+		v[c] += v[d] + 2*uint64(uint32(v[c]))*uint64(uint32(v[d]))
+		v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+	}
+	g(0, 4, 8, 12)
+	g(1, 5, 9, 13)
+	g(2, 6, 10, 14)
+	g(3, 7, 11, 15)
+	g(0, 5, 10, 15)
+	g(1, 6, 11, 12)
+	g(2, 7, 8, 13)
+	g(3, 4, 9, 14)
+	for k, i := range [16]int{i0, i1, i2, i3, i4, i5, i6, i7, i8, i9, i10, i11, i12, i13, i14, i15} {
+		b[i] = v[k]
+	}
+}
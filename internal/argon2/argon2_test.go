@@ -0,0 +1,54 @@
+package argon2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestBlake2b(t *testing.T) {
+	// RFC 7693, Appendix A.
+	d := newBlake2b(64)
+	d.Write([]byte("abc"))
+	want := "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"
+	if got := hex.EncodeToString(d.Sum(nil)); got != want {
+		t.Errorf("BLAKE2b-512(abc) = %s", got)
+	}
+
+	// Writes that split or fill blocks exactly must not change the digest.
+	msg := bytes.Repeat([]byte{0x5a}, 3*blake2bBlockSize)
+	whole := newBlake2b(32)
+	whole.Write(msg)
+	pieces := newBlake2b(32)
+	pieces.Write(msg[:blake2bBlockSize])
+	pieces.Write(msg[blake2bBlockSize : blake2bBlockSize+1])
+	pieces.Write(msg[blake2bBlockSize+1:])
+	if !bytes.Equal(whole.Sum(nil), pieces.Sum(nil)) {
+		t.Error("digest depends on how the input is written")
+	}
+}
+
+func TestArgon2idVector(t *testing.T) {
+	// RFC 9106, section 5.3.
+	password := bytes.Repeat([]byte{0x01}, 32)
+	salt := bytes.Repeat([]byte{0x02}, 16)
+	secret := bytes.Repeat([]byte{0x03}, 8)
+	data := bytes.Repeat([]byte{0x04}, 12)
+	got := deriveKey(password, salt, secret, data, 3, 32, 4, 32)
+	want := "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659"
+	if hex.EncodeToString(got) != want {
+		t.Errorf("tag %x, want %s", got, want)
+	}
+}
+
+func TestIDKey(t *testing.T) {
+	// From the test suite of the Argon2 reference implementation.
+	got := IDKey([]byte("password"), []byte("somesalt"), 2, 1<<16, 1, 32)
+	want := "09316115d5cf24ed5a15a31a3ba326e5cf32edc24702987c02b6566f61913cf7"
+	if hex.EncodeToString(got) != want {
+		t.Errorf("key %x, want %s", got, want)
+	}
+	if long := IDKey([]byte("password"), []byte("somesalt"), 1, 64, 2, 100); len(long) != 100 {
+		t.Errorf("key length %d, want 100", len(long))
+	}
+}
@@ -0,0 +1,156 @@
+// Package bcshamir implements the Shamir secret sharing variant specified by
+// SLIP-0039 and reused by Blockchain Commons' SSKR: GF(256) with the Rijndael
+// polynomial, the secret at x = 255 and a digest share at x = 254 that lets
+// recovery detect a wrong set of shares.
+package bcshamir
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+)
+
+var (
+	// ErrInvalidShares indicates that points repeat an x-coordinate or differ in length.
+	ErrInvalidShares = errors.New("share indices must be unique and values of equal length")
+
+	// ErrDigestMismatch indicates that the recovered secret failed its digest check.
+	ErrDigestMismatch = errors.New("invalid digest of the shared secret")
+)
+
+// GF(256) with the Rijndael polynomial x⁸+x⁴+x³+x+1 (0x11b), unlike the
+// parent package, so it has its own tables.
+var gfExp, gfLog = buildTables()
+
+func buildTables() (exp [255]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		log[x] = byte(i)
+		// Multiply by the generator 3: x·3 = x·2 ⊕ x
+		x2 := x << 1
+		if x&0x80 != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+	return exp, log
+}
+
+const (
+	digestIndex  = 254 // x-coordinate of the digest share
+	secretIndex  = 255 // x-coordinate of the shared secret
+	digestLength = 4
+)
+
+// Point is one evaluation of a sharing polynomial.
+type Point struct {
+	X byte
+	Y []byte
+}
+
+// interpolate evaluates at x the polynomial passing through points.
+func interpolate(points []Point, x byte) ([]byte, error) {
+	seen := make(map[byte]bool, len(points))
+	for _, p := range points {
+		if seen[p.X] || len(p.Y) != len(points[0].Y) {
+			return nil, ErrInvalidShares
+		}
+		seen[p.X] = true
+	}
+	for _, p := range points {
+		if p.X == x {
+			return append([]byte(nil), p.Y...), nil
+		}
+	}
+
+	result := make([]byte, len(points[0].Y))
+	for i, pi := range points {
+		// log of the basis polynomial L_i(x) = Π_{j≠i} (x - x_j) / (x_i - x_j)
+		logBasis := 0
+		for j, pj := range points {
+			if j == i {
+				continue
+			}
+			logBasis += int(gfLog[x^pj.X]) - int(gfLog[pi.X^pj.X])
+		}
+		logBasis = ((logBasis % 255) + 255) % 255
+
+		for k, v := range pi.Y {
+			if v != 0 {
+				result[k] ^= gfExp[(int(gfLog[v])+logBasis)%255]
+			}
+		}
+	}
+	return result, nil
+}
+
+// Split shares secret among count participants, at x = 0 through count-1,
+// with the given threshold. With a threshold of 1 every participant receives
+// a copy of the secret.
+func Split(threshold, count int, secret []byte) ([]Point, error) {
+	if threshold == 1 {
+		points := make([]Point, count)
+		for i := range points {
+			points[i] = Point{byte(i), append([]byte(nil), secret...)}
+		}
+		return points, nil
+	}
+
+	randomCount := threshold - 2
+	points := make([]Point, 0, count)
+	for i := 0; i < randomCount; i++ {
+		y := make([]byte, len(secret))
+		if _, err := rand.Read(y); err != nil {
+			return nil, err
+		}
+		points = append(points, Point{byte(i), y})
+	}
+
+	randomPart := make([]byte, len(secret)-digestLength)
+	if _, err := rand.Read(randomPart); err != nil {
+		return nil, err
+	}
+	digestShare := append(createDigest(randomPart, secret), randomPart...)
+
+	base := append(append([]Point(nil), points...),
+		Point{digestIndex, digestShare},
+		Point{secretIndex, secret},
+	)
+	for i := randomCount; i < count; i++ {
+		y, err := interpolate(base, byte(i))
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, Point{byte(i), y})
+	}
+	return points, nil
+}
+
+// Recover interpolates the secret from threshold points and checks the digest.
+func Recover(threshold int, points []Point) ([]byte, error) {
+	if threshold == 1 {
+		return append([]byte(nil), points[0].Y...), nil
+	}
+
+	secret, err := interpolate(points, secretIndex)
+	if err != nil {
+		return nil, err
+	}
+	digestShare, err := interpolate(points, digestIndex)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(digestShare[:digestLength], createDigest(digestShare[digestLength:], secret)) {
+		return nil, ErrDigestMismatch
+	}
+	return secret, nil
+}
+
+// createDigest returns the first four bytes of HMAC-SHA256(randomPart, secret).
+func createDigest(randomPart, secret []byte) []byte {
+	mac := hmac.New(sha256.New, randomPart)
+	mac.Write(secret)
+	return mac.Sum(nil)[:digestLength]
+}
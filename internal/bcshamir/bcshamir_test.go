@@ -0,0 +1,37 @@
+package bcshamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSplitRecover(t *testing.T) {
+	secret := []byte("0123456789abcdef")
+	for _, threshold := range []int{1, 2, 3, 5} {
+		points, err := Split(threshold, 5, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Recover(threshold, points[5-threshold:])
+		if err != nil {
+			t.Fatalf("threshold %d: %v", threshold, err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Fatalf("threshold %d: recovered %x", threshold, got)
+		}
+	}
+
+	points, err := Split(3, 5, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	points[1].Y[0] ^= 1
+	if _, err := Recover(3, points[:3]); !errors.Is(err, ErrDigestMismatch) {
+		t.Fatalf("expected ErrDigestMismatch, got %v", err)
+	}
+	points[1].X = points[0].X
+	if _, err := Recover(3, points[:3]); !errors.Is(err, ErrInvalidShares) {
+		t.Fatalf("expected ErrInvalidShares, got %v", err)
+	}
+}
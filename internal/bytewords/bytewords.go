@@ -0,0 +1,101 @@
+// Package bytewords implements Blockchain Commons' Bytewords (BCR-2020-012):
+// every byte is one of 256 four-letter English words, uniquely identified by
+// its first and last letter. The minimal form, two letters per byte followed
+// by a CRC32, is the body of UR strings.
+package bytewords
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"strings"
+)
+
+// ErrInvalid indicates malformed Bytewords or a checksum mismatch.
+var ErrInvalid = errors.New("invalid bytewords")
+
+// Words maps byte values to words, in alphabetical order.
+var Words = [256]string{
+	"able", "acid", "also", "apex", "aqua", "arch", "atom", "aunt",
+	"away", "axis", "back", "bald", "barn", "belt", "beta", "bias",
+	"blue", "body", "brag", "brew", "bulb", "buzz", "calm", "cash",
+	"cats", "chef", "city", "claw", "code", "cola", "cook", "cost",
+	"crux", "curl", "cusp", "cyan", "dark", "data", "days", "deli",
+	"dice", "diet", "door", "down", "draw", "drop", "drum", "dull",
+	"duty", "each", "easy", "echo", "edge", "epic", "even", "exam",
+	"exit", "eyes", "fact", "fair", "fern", "figs", "film", "fish",
+	"fizz", "flap", "flew", "flux", "foxy", "free", "frog", "fuel",
+	"fund", "gala", "game", "gear", "gems", "gift", "girl", "glow",
+	"good", "gray", "grim", "guru", "gush", "gyro", "half", "hang",
+	"hard", "hawk", "heat", "help", "high", "hill", "holy", "hope",
+	"horn", "huts", "iced", "idea", "idle", "inch", "inky", "into",
+	"iris", "iron", "item", "jade", "jazz", "join", "jolt", "jowl",
+	"judo", "jugs", "jump", "junk", "jury", "keep", "keno", "kept",
+	"keys", "kick", "kiln", "king", "kite", "kiwi", "knob", "lamb",
+	"lava", "lazy", "leaf", "legs", "liar", "limp", "lion", "list",
+	"logo", "loud", "love", "luau", "luck", "lung", "main", "many",
+	"math", "maze", "memo", "menu", "meow", "mild", "mint", "miss",
+	"monk", "nail", "navy", "need", "news", "next", "noon", "note",
+	"numb", "obey", "oboe", "omit", "onyx", "open", "oval", "owls",
+	"paid", "part", "peck", "play", "plus", "poem", "pool", "pose",
+	"puff", "puma", "purr", "quad", "quiz", "race", "ramp", "real",
+	"redo", "rich", "road", "rock", "roof", "ruby", "ruin", "runs",
+	"rust", "safe", "saga", "scar", "sets", "silk", "skew", "slot",
+	"soap", "solo", "song", "stub", "surf", "swan", "taco", "task",
+	"taxi", "tent", "tied", "time", "tiny", "toil", "tomb", "toys",
+	"trip", "tuna", "twin", "ugly", "undo", "unit", "urge", "user",
+	"vast", "very", "veto", "vial", "vibe", "view", "visa", "void",
+	"vows", "wall", "wand", "warm", "wasp", "wave", "waxy", "webs",
+	"what", "when", "whiz", "wolf", "work", "yank", "yawn", "yell",
+	"yoga", "yurt", "zaps", "zero", "zest", "zinc", "zone", "zoom",
+}
+
+// index maps full and abbreviated (first and last letter) words to byte values.
+var index = buildIndex()
+
+func buildIndex() map[string]byte {
+	m := make(map[string]byte, 2*len(Words))
+	for i, word := range Words {
+		m[word] = byte(i)
+		m[word[:1]+word[len(word)-1:]] = byte(i)
+	}
+	return m
+}
+
+// Lookup returns the byte for a full or abbreviated word, in any case.
+func Lookup(word string) (byte, bool) {
+	b, ok := index[strings.ToLower(word)]
+	return b, ok
+}
+
+// EncodeMinimal returns data followed by its CRC32 in minimal Bytewords.
+func EncodeMinimal(data []byte) string {
+	var sb strings.Builder
+	sb.Grow(2 * (len(data) + 4))
+	for _, b := range binary.BigEndian.AppendUint32(append([]byte(nil), data...), crc32.ChecksumIEEE(data)) {
+		sb.WriteString(Words[b][:1])
+		sb.WriteString(Words[b][3:])
+	}
+	return sb.String()
+}
+
+// DecodeMinimal decodes minimal Bytewords, verifies the trailing CRC32 and
+// returns the data without it.
+func DecodeMinimal(s string) ([]byte, error) {
+	if len(s)%2 != 0 || len(s) < 2*5 {
+		return nil, ErrInvalid
+	}
+	data := make([]byte, len(s)/2)
+	for i := range data {
+		b, ok := Lookup(s[2*i : 2*i+2])
+		if !ok {
+			return nil, ErrInvalid
+		}
+		data[i] = b
+	}
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(body):]) {
+		return nil, ErrInvalid
+	}
+	return body, nil
+}
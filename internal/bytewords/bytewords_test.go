@@ -0,0 +1,45 @@
+package bytewords
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMinimal(t *testing.T) {
+	// Example from BCR-2020-012.
+	data := []byte{0x00, 0x01, 0x02, 0x80, 0xff}
+	const want = "aeadaolazmjendeoti"
+
+	got := EncodeMinimal(data)
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	decoded, err := DecodeMinimal(strings.ToUpper(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Fatalf("decoded %x, want %x", decoded, data)
+	}
+
+	for _, bad := range []string{"", "aeadao", want[:len(want)-1], "aeadaolazmjendeotx", "aeadaolazmjendeoty"} {
+		if _, err := DecodeMinimal(bad); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%q: expected ErrInvalid, got %v", bad, err)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for i, word := range Words {
+		for _, w := range []string{word, strings.ToUpper(word), word[:1] + word[3:]} {
+			if b, ok := Lookup(w); !ok || int(b) != i {
+				t.Fatalf("Lookup(%q) = %d, %v; want %d", w, b, ok, i)
+			}
+		}
+	}
+	if _, ok := Lookup("zzzz"); ok {
+		t.Fatal("unknown word found")
+	}
+}
@@ -0,0 +1,311 @@
+// Package chacha20poly1305 implements the ChaCha20-Poly1305 AEAD (RFC 8439)
+// and its extended-nonce variant XChaCha20-Poly1305, for passphrase-protected
+// shares without depending on golang.org/x/crypto. It favours clarity over
+// speed: shares are short.
+package chacha20poly1305
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+const (
+	// KeySize is the size of the key used by this AEAD, in bytes.
+	KeySize = 32
+	// NonceSize is the size of the nonce used with the RFC 8439 AEAD.
+	NonceSize = 12
+	// NonceSizeX is the size of the nonce used with XChaCha20-Poly1305.
+	NonceSizeX = 24
+	// Overhead is the size of the Poly1305 authentication tag.
+	Overhead = 16
+)
+
+var errOpen = errors.New("chacha20poly1305: message authentication failed")
+
+type aead struct {
+	key    [KeySize]byte
+	nonceX bool
+}
+
+// New returns a ChaCha20-Poly1305 AEAD that uses the given 256-bit key.
+func New(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, errors.New("chacha20poly1305: bad key length")
+	}
+	a := &aead{}
+	copy(a.key[:], key)
+	return a, nil
+}
+
+// NewX returns an XChaCha20-Poly1305 AEAD that uses the given 256-bit key. Its
+// 192-bit nonces are long enough to be chosen at random.
+func NewX(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, errors.New("chacha20poly1305: bad key length")
+	}
+	a := &aead{nonceX: true}
+	copy(a.key[:], key)
+	return a, nil
+}
+
+func (a *aead) NonceSize() int {
+	if a.nonceX {
+		return NonceSizeX
+	}
+	return NonceSize
+}
+
+func (a *aead) Overhead() int { return Overhead }
+
+// keyNonce returns the ChaCha20 key and 96-bit nonce for nonce, deriving a
+// subkey with HChaCha20 for XChaCha20.
+func (a *aead) keyNonce(nonce []byte) (key [KeySize]byte, n [NonceSize]byte) {
+	if len(nonce) != a.NonceSize() {
+		panic("chacha20poly1305: bad nonce length passed to Seal or Open")
+	}
+	if !a.nonceX {
+		copy(n[:], nonce)
+		return a.key, n
+	}
+	key = hChaCha20(&a.key, nonce[:16])
+	copy(n[4:], nonce[16:])
+	return key, n
+}
+
+func (a *aead) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	key, n := a.keyNonce(nonce)
+	defer clear(key[:])
+
+	ret, out := sliceForAppend(dst, len(plaintext)+Overhead)
+	ciphertext, tag := out[:len(plaintext)], out[len(plaintext):]
+	xorKeyStream(ciphertext, plaintext, &key, &n, 1)
+	copy(tag, authenticate(&key, &n, additionalData, ciphertext))
+	return ret
+}
+
+func (a *aead) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < Overhead {
+		return nil, errOpen
+	}
+	key, n := a.keyNonce(nonce)
+	defer clear(key[:])
+
+	tag := ciphertext[len(ciphertext)-Overhead:]
+	ciphertext = ciphertext[:len(ciphertext)-Overhead]
+	if subtle.ConstantTimeCompare(authenticate(&key, &n, additionalData, ciphertext), tag) != 1 {
+		return nil, errOpen
+	}
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	xorKeyStream(out, ciphertext, &key, &n, 1)
+	return ret, nil
+}
+
+// authenticate computes the AEAD tag over additionalData and ciphertext,
+// keyed with the first block of the keystream.
+func authenticate(key *[KeySize]byte, nonce *[NonceSize]byte, additionalData, ciphertext []byte) []byte {
+	var polyKey [32]byte
+	xorKeyStream(polyKey[:], polyKey[:], key, nonce, 0)
+	defer clear(polyKey[:])
+
+	var lengths [16]byte
+	binary.LittleEndian.PutUint64(lengths[:8], uint64(len(additionalData)))
+	binary.LittleEndian.PutUint64(lengths[8:], uint64(len(ciphertext)))
+	padding := make([]byte, 15)
+	msg := make([]byte, 0, len(additionalData)+len(ciphertext)+2*15+len(lengths))
+	msg = append(msg, additionalData...)
+	msg = append(msg, padding[:(16-len(additionalData)%16)%16]...)
+	msg = append(msg, ciphertext...)
+	msg = append(msg, padding[:(16-len(ciphertext)%16)%16]...)
+	msg = append(msg, lengths[:]...)
+	return poly1305(&polyKey, msg)
+}
+
+// sliceForAppend extends in by n bytes, returning the whole slice and the
+// extension.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	return head, head[len(in):]
+}
+
+// ChaCha20 (RFC 8439, section 2.3).
+
+func quarterRound(a, b, c, d uint32) (uint32, uint32, uint32, uint32) {
+	a += b
+	d = bits.RotateLeft32(d^a, 16)
+	c += d
+	b = bits.RotateLeft32(b^c, 12)
+	a += b
+	d = bits.RotateLeft32(d^a, 8)
+	c += d
+	b = bits.RotateLeft32(b^c, 7)
+	return a, b, c, d
+}
+
+// rounds applies the 20 ChaCha rounds to s in place.
+func rounds(s *[16]uint32) {
+	for range 10 {
+		s[0], s[4], s[8], s[12] = quarterRound(s[0], s[4], s[8], s[12])
+		s[1], s[5], s[9], s[13] = quarterRound(s[1], s[5], s[9], s[13])
+		s[2], s[6], s[10], s[14] = quarterRound(s[2], s[6], s[10], s[14])
+		s[3], s[7], s[11], s[15] = quarterRound(s[3], s[7], s[11], s[15])
+		s[0], s[5], s[10], s[15] = quarterRound(s[0], s[5], s[10], s[15])
+		s[1], s[6], s[11], s[12] = quarterRound(s[1], s[6], s[11], s[12])
+		s[2], s[7], s[8], s[13] = quarterRound(s[2], s[7], s[8], s[13])
+		s[3], s[4], s[9], s[14] = quarterRound(s[3], s[4], s[9], s[14])
+	}
+}
+
+// initialState lays out the constants, key and 16 bytes of counter and nonce.
+func initialState(key *[KeySize]byte, tail []byte) [16]uint32 {
+	s := [16]uint32{0x61707865, 0x3320646e, 0x79622d32, 0x6b206574}
+	for i := range 8 {
+		s[4+i] = binary.LittleEndian.Uint32(key[4*i:])
+	}
+	for i := range 4 {
+		s[12+i] = binary.LittleEndian.Uint32(tail[4*i:])
+	}
+	return s
+}
+
+// block computes the keystream block for counter.
+func block(out *[64]byte, key *[KeySize]byte, nonce *[NonceSize]byte, counter uint32) {
+	var tail [16]byte
+	binary.LittleEndian.PutUint32(tail[:], counter)
+	copy(tail[4:], nonce[:])
+	initial := initialState(key, tail[:])
+	s := initial
+	rounds(&s)
+	for i := range s {
+		binary.LittleEndian.PutUint32(out[4*i:], s[i]+initial[i])
+	}
+}
+
+// xorKeyStream XORs src with the keystream starting at counter into dst.
+func xorKeyStream(dst, src []byte, key *[KeySize]byte, nonce *[NonceSize]byte, counter uint32) {
+	var ks [64]byte
+	defer clear(ks[:])
+	for len(src) > 0 {
+		block(&ks, key, nonce, counter)
+		counter++
+		n := min(len(src), len(ks))
+		subtle.XORBytes(dst[:n], src[:n], ks[:n])
+		dst, src = dst[n:], src[n:]
+	}
+}
+
+// hChaCha20 derives an XChaCha20 subkey from key and the first 16 bytes of
+// the nonce (draft-irtf-cfrg-xchacha, section 2.2).
+func hChaCha20(key *[KeySize]byte, nonce []byte) [KeySize]byte {
+	s := initialState(key, nonce)
+	rounds(&s)
+	var out [KeySize]byte
+	for i := range 4 {
+		binary.LittleEndian.PutUint32(out[4*i:], s[i])
+		binary.LittleEndian.PutUint32(out[16+4*i:], s[12+i])
+	}
+	return out
+}
+
+// poly1305 computes the one-time authenticator of msg (RFC 8439, section 2.5)
+// with 26-bit limbs, so that every product fits in 64 bits.
+func poly1305(key *[32]byte, msg []byte) []byte {
+	const mask = 1<<26 - 1
+	r0 := uint64(binary.LittleEndian.Uint32(key[0:])) & 0x3ffffff
+	r1 := uint64(binary.LittleEndian.Uint32(key[3:])>>2) & 0x3ffff03
+	r2 := uint64(binary.LittleEndian.Uint32(key[6:])>>4) & 0x3ffc0ff
+	r3 := uint64(binary.LittleEndian.Uint32(key[9:])>>6) & 0x3f03fff
+	r4 := uint64(binary.LittleEndian.Uint32(key[12:])>>8) & 0x00fffff
+	s1, s2, s3, s4 := r1*5, r2*5, r3*5, r4*5
+
+	var h0, h1, h2, h3, h4 uint64
+	for len(msg) > 0 {
+		var m [16]byte
+		hibit := uint64(1 << 24)
+		if len(msg) >= 16 {
+			copy(m[:], msg)
+			msg = msg[16:]
+		} else {
+			m[copy(m[:], msg)] = 1
+			msg = nil
+			hibit = 0
+		}
+		h0 += uint64(binary.LittleEndian.Uint32(m[0:])) & mask
+		h1 += uint64(binary.LittleEndian.Uint32(m[3:])>>2) & mask
+		h2 += uint64(binary.LittleEndian.Uint32(m[6:])>>4) & mask
+		h3 += uint64(binary.LittleEndian.Uint32(m[9:])>>6) & mask
+		h4 += uint64(binary.LittleEndian.Uint32(m[12:])>>8) | hibit
+
+		d0 := h0*r0 + h1*s4 + h2*s3 + h3*s2 + h4*s1
+		d1 := h0*r1 + h1*r0 + h2*s4 + h3*s3 + h4*s2
+		d2 := h0*r2 + h1*r1 + h2*r0 + h3*s4 + h4*s3
+		d3 := h0*r3 + h1*r2 + h2*r1 + h3*r0 + h4*s4
+		d4 := h0*r4 + h1*r3 + h2*r2 + h3*r1 + h4*r0
+
+		d1 += d0 >> 26
+		h0 = d0 & mask
+		d2 += d1 >> 26
+		h1 = d1 & mask
+		d3 += d2 >> 26
+		h2 = d2 & mask
+		d4 += d3 >> 26
+		h3 = d3 & mask
+		h0 += (d4 >> 26) * 5
+		h4 = d4 & mask
+		h1 += h0 >> 26
+		h0 &= mask
+	}
+
+	// Fully reduce h modulo 2^130 - 5.
+	h2 += h1 >> 26
+	h1 &= mask
+	h3 += h2 >> 26
+	h2 &= mask
+	h4 += h3 >> 26
+	h3 &= mask
+	h0 += (h4 >> 26) * 5
+	h4 &= mask
+	h1 += h0 >> 26
+	h0 &= mask
+
+	g0 := h0 + 5
+	g1 := h1 + g0>>26
+	g0 &= mask
+	g2 := h2 + g1>>26
+	g1 &= mask
+	g3 := h3 + g2>>26
+	g2 &= mask
+	g4 := h4 + g3>>26 - 1<<26
+	g3 &= mask
+
+	// Select g = h - p if it did not underflow, in constant time.
+	sel := (g4 >> 63) - 1
+	h0 = h0&^sel | g0&sel
+	h1 = h1&^sel | g1&sel
+	h2 = h2&^sel | g2&sel
+	h3 = h3&^sel | g3&sel
+	h4 = h4&^sel | g4&sel
+
+	w0 := (h0 | h1<<26) & 0xffffffff
+	w1 := (h1>>6 | h2<<20) & 0xffffffff
+	w2 := (h2>>12 | h3<<14) & 0xffffffff
+	w3 := (h3>>18 | h4<<8) & 0xffffffff
+
+	tag := make([]byte, 16)
+	f := w0 + uint64(binary.LittleEndian.Uint32(key[16:]))
+	binary.LittleEndian.PutUint32(tag[0:], uint32(f))
+	f = w1 + uint64(binary.LittleEndian.Uint32(key[20:])) + f>>32
+	binary.LittleEndian.PutUint32(tag[4:], uint32(f))
+	f = w2 + uint64(binary.LittleEndian.Uint32(key[24:])) + f>>32
+	binary.LittleEndian.PutUint32(tag[8:], uint32(f))
+	f = w3 + uint64(binary.LittleEndian.Uint32(key[28:])) + f>>32
+	binary.LittleEndian.PutUint32(tag[12:], uint32(f))
+	return tag
+}
@@ -0,0 +1,99 @@
+package chacha20poly1305
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func sequence(from byte, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = from + byte(i)
+	}
+	return b
+}
+
+func TestChaCha20Block(t *testing.T) {
+	// RFC 8439, section 2.3.2.
+	var key [KeySize]byte
+	var nonce [NonceSize]byte
+	copy(key[:], sequence(0, KeySize))
+	copy(nonce[:], unhex("000000090000004a00000000"))
+	var out [64]byte
+	block(&out, &key, &nonce, 1)
+	if want := unhex("10f1e7e4d13b5915500fdd1fa32071c4"); !bytes.HasPrefix(out[:], want) {
+		t.Errorf("block %x", out)
+	}
+}
+
+func TestPoly1305(t *testing.T) {
+	// RFC 8439, section 2.5.2.
+	var key [32]byte
+	copy(key[:], unhex("85d6be7857556d337f4452fe42d506a80103808afb0db2fd4abff6af4149f51b"))
+	tag := poly1305(&key, []byte("Cryptographic Forum Research Group"))
+	if want := unhex("a8061dc1305136c6c22b8baf0c0127a9"); !bytes.Equal(tag, want) {
+		t.Errorf("tag %x", tag)
+	}
+}
+
+func TestHChaCha20(t *testing.T) {
+	// draft-irtf-cfrg-xchacha-03, section 2.2.1.
+	var key [KeySize]byte
+	copy(key[:], sequence(0, KeySize))
+	subkey := hChaCha20(&key, unhex("000000090000004a0000000031415927"))
+	if want := unhex("82413b4227b27bfed30e42508a877d73a0f9e4d58a74a853c12ec41326d3ecdc"); !bytes.Equal(subkey[:], want) {
+		t.Errorf("subkey %x", subkey)
+	}
+}
+
+var sunscreen = []byte("Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it.")
+
+func TestAEADVectors(t *testing.T) {
+	aad := unhex("50515253c0c1c2c3c4c5c6c7")
+	key := sequence(0x80, KeySize)
+	tests := []struct {
+		name   string
+		nonce  string
+		prefix string
+		tag    string
+	}{
+		// RFC 8439, section 2.8.2.
+		{"ChaCha20-Poly1305", "070000004041424344454647", "d31a8d34648e60db7b86afbc53ef7ec2", "1ae10b594f09e26a7e902ecbd0600691"},
+		// draft-irtf-cfrg-xchacha-03, section A.3.1.
+		{"XChaCha20-Poly1305", "404142434445464748494a4b4c4d4e4f5051525354555657", "bd6d179d3e83d43b9576579493c0e939", "c0875924c1c7987947deafd8780acf49"},
+	}
+	for _, tt := range tests {
+		aead, _ := New(key)
+		if len(tt.nonce) == 2*NonceSizeX {
+			aead, _ = NewX(key)
+		}
+		sealed := aead.Seal(nil, unhex(tt.nonce), sunscreen, aad)
+		if !bytes.HasPrefix(sealed, unhex(tt.prefix)) || !bytes.HasSuffix(sealed, unhex(tt.tag)) {
+			t.Errorf("%s: sealed %x", tt.name, sealed)
+			continue
+		}
+		opened, err := aead.Open(nil, unhex(tt.nonce), sealed, aad)
+		if err != nil || !bytes.Equal(opened, sunscreen) {
+			t.Errorf("%s: open: %v", tt.name, err)
+		}
+		for _, i := range []int{0, len(sunscreen), len(sealed) - 1} {
+			tampered := bytes.Clone(sealed)
+			tampered[i] ^= 1
+			if _, err := aead.Open(nil, unhex(tt.nonce), tampered, aad); err == nil {
+				t.Errorf("%s: byte %d tampered undetected", tt.name, i)
+			}
+		}
+		if _, err := aead.Open(nil, unhex(tt.nonce), sealed, aad[1:]); err == nil {
+			t.Errorf("%s: wrong additional data accepted", tt.name)
+		}
+	}
+}
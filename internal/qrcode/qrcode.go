@@ -0,0 +1,252 @@
+// Package qrcode is a minimal QR code encoder (ISO/IEC 18004) supporting byte
+// mode at all versions and error correction levels. It exists so shares can be
+// printed for paper escrow without third-party dependencies.
+package qrcode
+
+import (
+	"errors"
+)
+
+// Level is the error correction level of a symbol.
+type Level int
+
+// Error correction levels, from least to most redundant.
+const (
+	LevelL Level = iota // Recovers ~7% of codewords
+	LevelM              // Recovers ~15% of codewords
+	LevelQ              // Recovers ~25% of codewords
+	LevelH              // Recovers ~30% of codewords
+)
+
+// ErrTooLarge indicates that the data does not fit in a version 40 symbol.
+var ErrTooLarge = errors.New("qrcode: data too large")
+
+// formatBits are the level indicators used in the format information.
+var formatBits = [4]int{LevelL: 1, LevelM: 0, LevelQ: 3, LevelH: 2}
+
+// eccPerBlock and numBlocks are indexed by level, then version (index 0 unused).
+var eccPerBlock = [4][41]int{
+	{0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{0, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{0, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+var numBlocks = [4][41]int{
+	{0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{0, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{0, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// Code is an encoded QR symbol. Modules are addressed with (0, 0) at the top
+// left; the quiet zone is not included.
+type Code struct {
+	Version int
+	Size    int
+	Level   Level
+	Mask    int
+
+	modules    []bool // Dark modules, row-major
+	isFunction []bool // Modules reserved for function patterns
+}
+
+// Dark reports whether the module at column x, row y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y*c.Size+x]
+}
+
+// Encode encodes data in byte mode using the smallest version that fits at the
+// given level, choosing the mask with the lowest penalty score.
+func Encode(data []byte, level Level) (*Code, error) {
+	if level < LevelL || level > LevelH {
+		return nil, errors.New("qrcode: invalid error correction level")
+	}
+
+	version := 0
+	for v := 1; v <= 40; v++ {
+		if 4+countBits(v)+8*len(data) <= 8*dataCodewords(v, level) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLarge
+	}
+
+	codewords := addErrorCorrection(dataBits(data, version, level), version, level)
+
+	c := &Code{Version: version, Size: 4*version + 17, Level: level}
+	c.modules = make([]bool, c.Size*c.Size)
+	c.isFunction = make([]bool, c.Size*c.Size)
+	c.drawFunctionPatterns()
+	c.drawCodewords(codewords)
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.applyMask(mask) // XOR again to undo
+	}
+	c.Mask = best
+	c.applyMask(best)
+	c.drawFormatBits(best)
+	return c, nil
+}
+
+// countBits is the width of the byte-mode character count for a version.
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// rawDataModules is the number of modules available for codewords, including
+// remainder bits, in a symbol of the given version.
+func rawDataModules(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		n -= (25*align-10)*align - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+// dataCodewords is the number of data codewords at a version and level.
+func dataCodewords(version int, level Level) int {
+	return rawDataModules(version)/8 - eccPerBlock[level][version]*numBlocks[level][version]
+}
+
+// dataBits builds the padded data codewords: mode indicator, count, data,
+// terminator and pad bytes.
+func dataBits(data []byte, version int, level Level) []byte {
+	capacity := dataCodewords(version, level)
+	var bb bitBuffer
+	bb.append(0x4, 4) // Byte mode
+	bb.append(len(data), countBits(version))
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	terminator := min(4, 8*capacity-bb.n)
+	bb.append(0, terminator)
+	bb.append(0, (8-bb.n%8)%8)
+
+	out := bb.bytes
+	for pad := byte(0xec); len(out) < capacity; pad ^= 0xec ^ 0x11 {
+		out = append(out, pad)
+	}
+	return out
+}
+
+// bitBuffer accumulates bits most significant first.
+type bitBuffer struct {
+	bytes []byte
+	n     int
+}
+
+func (bb *bitBuffer) append(value, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if bb.n%8 == 0 {
+			bb.bytes = append(bb.bytes, 0)
+		}
+		if value>>i&1 != 0 {
+			bb.bytes[bb.n/8] |= 0x80 >> (bb.n % 8)
+		}
+		bb.n++
+	}
+}
+
+// addErrorCorrection splits data into blocks, appends Reed-Solomon codewords
+// to each and interleaves the result.
+func addErrorCorrection(data []byte, version int, level Level) []byte {
+	blocks := numBlocks[level][version]
+	ecc := eccPerBlock[level][version]
+	raw := rawDataModules(version) / 8
+	shortBlocks := blocks - raw%blocks
+	shortLen := raw/blocks - ecc // Data codewords in a short block
+
+	generator := rsGenerator(ecc)
+	dataBlocks := make([][]byte, blocks)
+	eccBlocks := make([][]byte, blocks)
+	for i, off := 0, 0; i < blocks; i++ {
+		n := shortLen
+		if i >= shortBlocks {
+			n++
+		}
+		dataBlocks[i] = data[off : off+n]
+		eccBlocks[i] = rsRemainder(dataBlocks[i], generator)
+		off += n
+	}
+
+	out := make([]byte, 0, raw)
+	for i := 0; i <= shortLen; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := 0; i < ecc; i++ {
+		for _, block := range eccBlocks {
+			out = append(out, block[i])
+		}
+	}
+	return out
+}
+
+// gfMul multiplies in GF(256) with the QR polynomial x^8+x^4+x^3+x^2+1.
+func gfMul(a, b byte) byte {
+	var p byte
+	for b != 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		hi := a & 0x80
+		a <<= 1
+		if hi != 0 {
+			a ^= 0x1d
+		}
+		b >>= 1
+	}
+	return p
+}
+
+// rsGenerator returns the coefficients, highest degree first and excluding the
+// leading 1, of the generator polynomial (x-α^0)(x-α^1)...(x-α^(degree-1)).
+func rsGenerator(degree int) []byte {
+	g := make([]byte, degree)
+	g[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := 0; j < degree; j++ {
+			g[j] = gfMul(g[j], root)
+			if j+1 < degree {
+				g[j] ^= g[j+1]
+			}
+		}
+		root = gfMul(root, 2)
+	}
+	return g
+}
+
+// rsRemainder returns the error correction codewords for data.
+func rsRemainder(data, generator []byte) []byte {
+	rem := make([]byte, len(generator))
+	for _, b := range data {
+		factor := b ^ rem[0]
+		copy(rem, rem[1:])
+		rem[len(rem)-1] = 0
+		for i, g := range generator {
+			rem[i] ^= gfMul(g, factor)
+		}
+	}
+	return rem
+}
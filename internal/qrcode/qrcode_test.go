@@ -0,0 +1,257 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" at 1-M, from the ISO/IEC 18004 worked example
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsGenerator(10)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	// Byte-mode capacities from the specification's capacity table
+	tests := []struct {
+		version int
+		level   Level
+		bytes   int
+	}{
+		{1, LevelL, 17}, {1, LevelM, 14}, {1, LevelQ, 11}, {1, LevelH, 7},
+		{10, LevelM, 213}, {25, LevelQ, 715}, {40, LevelL, 2953}, {40, LevelH, 1273},
+	}
+	for _, tt := range tests {
+		data := make([]byte, tt.bytes)
+		c, err := Encode(data, tt.level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Version != tt.version {
+			t.Errorf("%d bytes at level %d: version %d, want %d", tt.bytes, tt.level, c.Version, tt.version)
+		}
+		if tt.version < 40 {
+			if c, _ := Encode(append(data, 0), tt.level); c.Version != tt.version+1 {
+				t.Errorf("%d bytes at level %d: version %d, want %d", tt.bytes+1, tt.level, c.Version, tt.version+1)
+			}
+		}
+	}
+
+	if _, err := Encode(make([]byte, 2954), LevelL); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	c := &Code{Version: 7, Size: 45, Level: LevelM}
+	c.modules = make([]bool, c.Size*c.Size)
+	c.isFunction = make([]bool, c.Size*c.Size)
+
+	c.drawFormatBits(0)
+	// Level M, mask 0 is 101010000010010, bit 14 first along row 8
+	var got strings.Builder
+	for x := 0; x <= 8; x++ {
+		if x == 6 {
+			continue // Timing column
+		}
+		got.WriteByte(bitChar(c.Dark(x, 8)))
+	}
+	for y := 7; y >= 0; y-- {
+		if y == 6 {
+			continue
+		}
+		got.WriteByte(bitChar(c.Dark(8, y)))
+	}
+	if got.String() != "101010000010010" {
+		t.Errorf("format bits = %s, want 101010000010010", got.String())
+	}
+
+	c.drawVersion()
+	// Version 7 is 000111110010010100, bit 0 at (0, size-11)
+	got.Reset()
+	for i := 17; i >= 0; i-- {
+		got.WriteByte(bitChar(c.Dark(i/3, c.Size-11+i%3)))
+	}
+	if got.String() != "000111110010010100" {
+		t.Errorf("version bits = %s, want 000111110010010100", got.String())
+	}
+}
+
+func bitChar(dark bool) byte {
+	if dark {
+		return '1'
+	}
+	return '0'
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, level := range []Level{LevelL, LevelM, LevelQ, LevelH} {
+		for _, n := range []int{0, 1, 20, 100, 400, 1200} {
+			data := make([]byte, n)
+			for i := range data {
+				data[i] = byte(i*7 + n)
+			}
+			c, err := Encode(data, level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := decode(c)
+			if err != nil {
+				t.Fatalf("level %d, %d bytes (version %d): %v", level, n, c.Version, err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("level %d, %d bytes: round trip mismatch", level, n)
+			}
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	c, err := Encode([]byte("hello"), LevelM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := c.WritePNG(&buf, 3); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	side := (c.Size + 2*QuietZone) * 3
+	if b := img.Bounds(); b.Dx() != side || b.Dy() != side {
+		t.Errorf("image is %dx%d, want %dx%d", b.Dx(), b.Dy(), side, side)
+	}
+	// Top-left finder corner is dark, the quiet zone light
+	if r, _, _, _ := img.At(QuietZone*3, QuietZone*3).RGBA(); r != 0 {
+		t.Error("finder corner should be dark")
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+		t.Error("quiet zone should be light")
+	}
+
+	buf.Reset()
+	if err := c.WriteSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	if !strings.Contains(svg, fmt.Sprintf(`viewBox="0 0 %d %d"`, c.Size+8, c.Size+8)) {
+		t.Error("SVG missing viewBox")
+	}
+	if !strings.Contains(svg, "M4,4h1v1h-1z") {
+		t.Error("SVG missing finder module")
+	}
+}
+
+// decode reads a symbol produced by Encode back into its data, undoing the
+// mask, zigzag placement and block interleaving. It relies on the encoder's
+// function-module map instead of locating patterns in an image.
+func decode(c *Code) ([]byte, error) {
+	// Read the format information from the first copy
+	bits := 0
+	for i := 14; i >= 9; i-- {
+		bits = bits<<1 | boolInt(c.Dark(14-i, 8))
+	}
+	bits = bits<<1 | boolInt(c.Dark(7, 8))
+	bits = bits<<1 | boolInt(c.Dark(8, 8))
+	bits = bits<<1 | boolInt(c.Dark(8, 7))
+	for i := 5; i >= 0; i-- {
+		bits = bits<<1 | boolInt(c.Dark(8, i))
+	}
+	bits ^= 0x5412
+	mask := bits >> 10 & 7
+	if bits>>13 != formatBits[c.Level] {
+		return nil, fmt.Errorf("format level %d does not match", bits>>13)
+	}
+
+	u := &Code{Version: c.Version, Size: c.Size, Level: c.Level, modules: append([]bool(nil), c.modules...), isFunction: c.isFunction}
+	u.applyMask(mask)
+
+	raw := rawDataModules(c.Version) / 8
+	codewords := make([]byte, raw)
+	i := 0
+	for right := u.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < u.Size; vert++ {
+			y := vert
+			if upward {
+				y = u.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if u.isFunction[y*u.Size+x] || i >= 8*raw {
+					continue
+				}
+				if u.Dark(x, y) {
+					codewords[i/8] |= 0x80 >> (i % 8)
+				}
+				i++
+			}
+		}
+	}
+
+	// Deinterleave and check each block's error correction
+	blocks := numBlocks[c.Level][c.Version]
+	ecc := eccPerBlock[c.Level][c.Version]
+	shortBlocks := blocks - raw%blocks
+	shortLen := raw/blocks - ecc
+	dataBlocks := make([][]byte, blocks)
+	pos := 0
+	for k := 0; k <= shortLen; k++ {
+		for b := range dataBlocks {
+			if k < shortLen || b >= shortBlocks {
+				dataBlocks[b] = append(dataBlocks[b], codewords[pos])
+				pos++
+			}
+		}
+	}
+	var data []byte
+	generator := rsGenerator(ecc)
+	for b, block := range dataBlocks {
+		want := make([]byte, ecc)
+		for k := range want {
+			want[k] = codewords[pos+k*blocks+b]
+		}
+		if !bytes.Equal(rsRemainder(block, generator), want) {
+			return nil, fmt.Errorf("block %d: error correction mismatch", b)
+		}
+		data = append(data, block...)
+	}
+
+	// Parse the byte-mode segment
+	read := func(off, n int) int {
+		v := 0
+		for k := off; k < off+n; k++ {
+			v = v<<1 | int(data[k/8]>>(7-k%8)&1)
+		}
+		return v
+	}
+	if read(0, 4) != 0x4 {
+		return nil, fmt.Errorf("unexpected mode %x", read(0, 4))
+	}
+	n := read(4, countBits(c.Version))
+	out := make([]byte, n)
+	for k := range out {
+		out[k] = byte(read(4+countBits(c.Version)+8*k, 8))
+	}
+	return out, nil
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,398 @@
+// Package threshold implements threshold decryption: data is encrypted to a
+// public key whose private scalar is Shamir-shared among custodians, and
+// decrypting it takes partial decryptions from a threshold of them. The
+// private key is never reconstructed, not even by whoever combines the
+// partials.
+//
+// The scheme is hashed ElGamal (ECIES) on P-256 with AES-256-GCM:
+//
+//	encrypt:    R = r·G, S = r·P, key = HKDF(S, R ‖ P)
+//	custodian:  Dᵢ = xᵢ·R, with a proof that log_G(Yᵢ) = log_R(Dᵢ)
+//	combine:    S = Σ λᵢ·Dᵢ = x·R, key = HKDF(S, R ‖ P)
+//
+// where x is the private key, xᵢ its shares from shamir.SplitScalar over
+// shamir.P256, P = x·G the public key and Yᵢ = xᵢ·G the public verification
+// key of share i. Every partial decryption carries a Chaum-Pedersen proof
+// against its verification key, so a custodian who submits a wrong partial
+// is identified rather than merely causing decryption to fail.
+//
+// Ciphertext format, in the versioned layout of the parent package:
+//
+//	[3 bytes]  magic 0x00 'S' 'T'
+//	[1 byte]   format version (1)
+//	[33 bytes] ephemeral point R, compressed
+//	[n bytes]  AES-256-GCM ciphertext and tag
+//
+// The key is used for a single message, so the nonce is fixed; the header and
+// the caller's additional data are authenticated.
+//
+// This package is experimental and deliberately internal. Key shares go
+// through the deprecated big.Int arithmetic of crypto/elliptic, which is not
+// constant time, so a custodian's partial decryptions and proofs may leak its
+// share through timing. It can become public once it is ported to
+// constant-time group arithmetic, which the standard library does not expose.
+package threshold
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// PointSize is the length of a compressed P-256 point.
+const PointSize = 33
+
+var (
+	// ErrInvalidKey indicates a malformed public key or key share.
+	ErrInvalidKey = errors.New("threshold: invalid key")
+
+	// ErrInvalidCiphertext indicates a ciphertext that is malformed or not of this package.
+	ErrInvalidCiphertext = errors.New("threshold: invalid ciphertext")
+
+	// ErrInvalidPartial indicates a partial decryption that is malformed or
+	// whose proof does not verify against its custodian's verification key.
+	ErrInvalidPartial = errors.New("threshold: invalid partial decryption")
+
+	// ErrTooFewPartials indicates fewer partial decryptions than the threshold.
+	ErrTooFewPartials = errors.New("threshold: too few partial decryptions")
+
+	// ErrDecryptionFailed indicates that the ciphertext or its additional data
+	// was tampered with, or that it was encrypted to another key.
+	ErrDecryptionFailed = errors.New("threshold: decryption failed")
+)
+
+// ciphertextMagic identifies a threshold ciphertext.
+var ciphertextMagic = [3]byte{0x00, 'S', 'T'}
+
+const (
+	formatVersion = 1
+	headerSize    = len(ciphertextMagic) + 1 + PointSize
+	proofSize     = 2 * shamir.ScalarSize
+	hkdfInfo      = "go-shamir threshold elgamal v1"
+	proofDomain   = "go-shamir threshold dleq v1"
+)
+
+var curve = elliptic.P256()
+
+// order is the order of the group, the modulus of shamir.P256.
+var order = shamir.P256.Order
+
+// PublicKey is the public half of a shared key. It holds no secrets and can
+// be published.
+type PublicKey struct {
+	Point     []byte // Compressed public key P = x·G
+	Threshold int    // Partial decryptions required to decrypt
+
+	// VerificationKeys[i] is the compressed point Yᵢ = xᵢ·G of the key share
+	// with index i+1, used to check that custodian's partial decryptions.
+	VerificationKeys [][]byte
+}
+
+// Partial is one custodian's partial decryption of a ciphertext.
+type Partial struct {
+	Index byte   // Index of the key share that produced it
+	Point []byte // Compressed Dᵢ = xᵢ·R
+	Proof []byte // Chaum-Pedersen proof (challenge ‖ response)
+}
+
+// GenerateKey creates a random private key and returns its public key and
+// parts shares of it, threshold of which can decrypt. The private key itself
+// is discarded. Give each custodian one share.
+func GenerateKey(parts, threshold int) (*PublicKey, []shamir.ScalarShare, error) {
+	x, err := randomScalar()
+	if err != nil {
+		return nil, nil, err
+	}
+	return splitKey(x, parts, threshold)
+}
+
+// SplitKey splits an existing private key, a non-zero big-endian P-256
+// scalar, into parts shares with the given threshold.
+func SplitKey(key []byte, parts, threshold int) (*PublicKey, []shamir.ScalarShare, error) {
+	x := new(big.Int).SetBytes(key)
+	if len(key) > shamir.ScalarSize || x.Sign() == 0 || x.Cmp(order) >= 0 {
+		return nil, nil, fmt.Errorf("%w: private key must be a non-zero scalar below the group order", ErrInvalidKey)
+	}
+	return splitKey(x, parts, threshold)
+}
+
+func splitKey(x *big.Int, parts, threshold int) (*PublicKey, []shamir.ScalarShare, error) {
+	shares, err := shamir.SplitScalar(shamir.P256, x, parts, threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+	pub := &PublicKey{
+		Point:            baseMult(x.Bytes()),
+		Threshold:        threshold,
+		VerificationKeys: make([][]byte, parts),
+	}
+	for i, s := range shares {
+		pub.VerificationKeys[i] = baseMult(s.Value[:])
+	}
+	return pub, shares, nil
+}
+
+// Encrypt encrypts plaintext to pub. aad is authenticated but not encrypted,
+// and must be passed to Combine unchanged; it may be nil.
+func Encrypt(pub *PublicKey, plaintext, aad []byte) ([]byte, error) {
+	px, py, err := decodePoint(pub.Point)
+	if err != nil {
+		return nil, fmt.Errorf("%w: public key", ErrInvalidKey)
+	}
+	r, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	rBytes := scalarBytes(r)
+	defer clear(rBytes)
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, ciphertextMagic[:]...)
+	header = append(header, formatVersion)
+	header = append(header, baseMult(rBytes)...)
+
+	sx, sy := curve.ScalarMult(px, py, rBytes)
+	aead, err := deriveAEAD(sx, sy, header[len(header)-PointSize:], pub.Point)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(header, make([]byte, aead.NonceSize()), plaintext, additionalData(header, aad)), nil
+}
+
+// PartialDecrypt computes a custodian's partial decryption of ciphertext with
+// its key share. It reveals nothing about the share or the plaintext; the
+// combiner needs threshold of them.
+func PartialDecrypt(share shamir.ScalarShare, ciphertext []byte) (*Partial, error) {
+	if _, err := shamir.ParseScalarShare(shamir.P256, share.Bytes()); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+	rx, ry, err := parseHeader(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	dx, dy := curve.ScalarMult(rx, ry, share.Value[:])
+	partial := &Partial{Index: share.Index, Point: elliptic.MarshalCompressed(curve, dx, dy)}
+	partial.Proof, err = prove(share, rx, ry, dx, dy)
+	if err != nil {
+		return nil, err
+	}
+	return partial, nil
+}
+
+// Combine checks the partial decryptions of ciphertext against pub and, given
+// at least pub.Threshold valid ones, decrypts it. A partial with a bad proof
+// fails with an error naming its index and matching ErrInvalidPartial.
+func Combine(pub *PublicKey, ciphertext, aad []byte, partials []*Partial) ([]byte, error) {
+	rx, ry, err := parseHeader(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if len(partials) < pub.Threshold || len(partials) < 2 {
+		return nil, fmt.Errorf("%w: have %d, need %d", ErrTooFewPartials, len(partials), pub.Threshold)
+	}
+
+	indices := make([]shamir.ScalarShare, len(partials))
+	xs, ys := make([]*big.Int, len(partials)), make([]*big.Int, len(partials))
+	for i, p := range partials {
+		if p.Index == 0 || int(p.Index) > len(pub.VerificationKeys) {
+			return nil, fmt.Errorf("%w: unknown index %d", ErrInvalidPartial, p.Index)
+		}
+		dx, dy, err := decodePoint(p.Point)
+		if err != nil || !verify(pub.VerificationKeys[p.Index-1], rx, ry, dx, dy, p.Proof) {
+			return nil, fmt.Errorf("%w: index %d", ErrInvalidPartial, p.Index)
+		}
+		indices[i] = shamir.ScalarShare{Index: p.Index}
+		xs[i], ys[i] = dx, dy
+	}
+	lambdas, err := shamir.ScalarLagrangeCoefficients(shamir.P256, indices)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPartial, err)
+	}
+
+	// S = Σ λᵢ·Dᵢ, interpolation at zero in the exponent.
+	var sx, sy *big.Int
+	for i := range partials {
+		tx, ty := curve.ScalarMult(xs[i], ys[i], scalarBytes(lambdas[i]))
+		if sx == nil {
+			sx, sy = tx, ty
+		} else {
+			sx, sy = curve.Add(sx, sy, tx, ty)
+		}
+	}
+
+	header := ciphertext[:headerSize]
+	aead, err := deriveAEAD(sx, sy, header[len(header)-PointSize:], pub.Point)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext[headerSize:], additionalData(header, aad))
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+// Bytes encodes the partial as [index][33-byte point][64-byte proof].
+func (p *Partial) Bytes() []byte {
+	out := make([]byte, 0, 1+PointSize+proofSize)
+	out = append(out, p.Index)
+	out = append(out, p.Point...)
+	return append(out, p.Proof...)
+}
+
+// ParsePartial decodes a partial encoded by Partial.Bytes.
+func ParsePartial(data []byte) (*Partial, error) {
+	if len(data) != 1+PointSize+proofSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidPartial, len(data))
+	}
+	return &Partial{
+		Index: data[0],
+		Point: bytes.Clone(data[1 : 1+PointSize]),
+		Proof: bytes.Clone(data[1+PointSize:]),
+	}, nil
+}
+
+// parseHeader checks the ciphertext header and returns its ephemeral point.
+func parseHeader(ciphertext []byte) (*big.Int, *big.Int, error) {
+	if len(ciphertext) < headerSize || !bytes.HasPrefix(ciphertext, ciphertextMagic[:]) {
+		return nil, nil, ErrInvalidCiphertext
+	}
+	if v := ciphertext[len(ciphertextMagic)]; v != formatVersion {
+		return nil, nil, fmt.Errorf("%w: version %d", ErrInvalidCiphertext, v)
+	}
+	rx, ry, err := decodePoint(ciphertext[headerSize-PointSize : headerSize])
+	if err != nil {
+		return nil, nil, ErrInvalidCiphertext
+	}
+	return rx, ry, nil
+}
+
+// deriveAEAD derives the AES-256-GCM instance from the shared point S.
+func deriveAEAD(sx, sy *big.Int, ephemeral, public []byte) (cipher.AEAD, error) {
+	shared := elliptic.MarshalCompressed(curve, sx, sy)
+	defer clear(shared)
+	salt := append(bytes.Clone(ephemeral), public...)
+	key, err := hkdf.Key(sha256.New, shared, salt, hkdfInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("threshold: failed to derive key: %w", err)
+	}
+	defer clear(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func additionalData(header, aad []byte) []byte {
+	return append(bytes.Clone(header), aad...)
+}
+
+// prove returns a Chaum-Pedersen proof that Y = x·G and D = x·R share the
+// discrete logarithm x, the value of share.
+func prove(share shamir.ScalarShare, rx, ry, dx, dy *big.Int) ([]byte, error) {
+	k, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	kBytes := scalarBytes(k)
+	defer clear(kBytes)
+
+	a1 := baseMult(kBytes)
+	a2x, a2y := curve.ScalarMult(rx, ry, kBytes)
+	c := challenge(baseMult(share.Value[:]), rx, ry, dx, dy, a1, elliptic.MarshalCompressed(curve, a2x, a2y))
+
+	// s = k - c·x mod n
+	s := new(big.Int).Mul(c, share.Int())
+	s.Sub(k, s)
+	s.Mod(s, order)
+	return append(scalarBytes(c), scalarBytes(s)...), nil
+}
+
+// verify checks a proof produced by prove against the verification key y.
+func verify(y []byte, rx, ry, dx, dy *big.Int, proof []byte) bool {
+	if len(proof) != proofSize {
+		return false
+	}
+	yx, yy, err := decodePoint(y)
+	if err != nil {
+		return false
+	}
+	c, s := proof[:shamir.ScalarSize], proof[shamir.ScalarSize:]
+	if new(big.Int).SetBytes(c).Cmp(order) >= 0 || new(big.Int).SetBytes(s).Cmp(order) >= 0 {
+		return false
+	}
+
+	// A1 = s·G + c·Y, A2 = s·R + c·D
+	a1x, a1y := addPoints(curve.ScalarBaseMult(s))(curve.ScalarMult(yx, yy, c))
+	a2x, a2y := addPoints(curve.ScalarMult(rx, ry, s))(curve.ScalarMult(dx, dy, c))
+	if a1x == nil || a2x == nil {
+		return false
+	}
+	want := challenge(y, rx, ry, dx, dy, elliptic.MarshalCompressed(curve, a1x, a1y), elliptic.MarshalCompressed(curve, a2x, a2y))
+	return bytes.Equal(scalarBytes(want), c)
+}
+
+// addPoints returns a function adding its argument to (ax, ay). The result is
+// nil for the point at infinity, which crypto/elliptic cannot encode.
+func addPoints(ax, ay *big.Int) func(bx, by *big.Int) (*big.Int, *big.Int) {
+	return func(bx, by *big.Int) (*big.Int, *big.Int) {
+		x, y := curve.Add(ax, ay, bx, by)
+		if x.Sign() == 0 && y.Sign() == 0 {
+			return nil, nil
+		}
+		return x, y
+	}
+}
+
+// challenge hashes the proof statement and commitments to a scalar.
+func challenge(y []byte, rx, ry, dx, dy *big.Int, a1, a2 []byte) *big.Int {
+	h := sha256.New()
+	h.Write([]byte(proofDomain))
+	h.Write(y)
+	h.Write(elliptic.MarshalCompressed(curve, rx, ry))
+	h.Write(elliptic.MarshalCompressed(curve, dx, dy))
+	h.Write(a1)
+	h.Write(a2)
+	c := new(big.Int).SetBytes(h.Sum(nil))
+	return c.Mod(c, order)
+}
+
+// randomScalar returns a uniformly random non-zero scalar.
+func randomScalar() (*big.Int, error) {
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(order, big.NewInt(1)))
+	if err != nil {
+		return nil, fmt.Errorf("threshold: failed to generate random scalar: %w", err)
+	}
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+// scalarBytes encodes a scalar as a fixed-length big-endian byte string.
+func scalarBytes(k *big.Int) []byte {
+	return k.FillBytes(make([]byte, shamir.ScalarSize))
+}
+
+// baseMult returns the compressed point k·G.
+func baseMult(k []byte) []byte {
+	x, y := curve.ScalarBaseMult(k)
+	return elliptic.MarshalCompressed(curve, x, y)
+}
+
+// decodePoint decodes a compressed point, rejecting invalid encodings.
+func decodePoint(data []byte) (*big.Int, *big.Int, error) {
+	x, y := elliptic.UnmarshalCompressed(curve, data)
+	if x == nil {
+		return nil, nil, ErrInvalidCiphertext
+	}
+	return x, y, nil
+}
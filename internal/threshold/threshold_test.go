@@ -0,0 +1,231 @@
+package threshold
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// decrypt runs PartialDecrypt for each share and combines the partials.
+func decrypt(t *testing.T, pub *PublicKey, shares []shamir.ScalarShare, ct, aad []byte) ([]byte, error) {
+	t.Helper()
+	partials := make([]*Partial, len(shares))
+	for i, share := range shares {
+		var err error
+		if partials[i], err = PartialDecrypt(share, ct); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return Combine(pub, ct, aad, partials)
+}
+
+func TestThresholdDecryption(t *testing.T) {
+	pub, shares, err := GenerateKey(5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte("launch codes")
+	aad := []byte("vault/prod")
+	ct, err := Encrypt(pub, plaintext, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, subset := range [][]shamir.ScalarShare{shares[:3], shares[2:], {shares[4], shares[0], shares[2]}, shares} {
+		got, err := decrypt(t, pub, subset, ct, aad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("got %q, want %q", got, plaintext)
+		}
+	}
+
+	if _, err := decrypt(t, pub, shares[:2], ct, aad); !errors.Is(err, ErrTooFewPartials) {
+		t.Errorf("two partials: expected ErrTooFewPartials, got %v", err)
+	}
+	if _, err := decrypt(t, pub, shares[:3], ct, []byte("vault/dev")); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("wrong aad: expected ErrDecryptionFailed, got %v", err)
+	}
+}
+
+func TestSplitKeyMatchesPrivateKey(t *testing.T) {
+	key := big.NewInt(0x1234567890abcdef)
+	pub, shares, err := SplitKey(key.Bytes(), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub.Point, baseMult(key.Bytes())) {
+		t.Fatal("public key does not match the private key")
+	}
+
+	ct, err := Encrypt(pub, []byte("hello"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Decrypting with the whole key gives the same shared point.
+	rx, ry, _ := parseHeader(ct)
+	sx, sy := curve.ScalarMult(rx, ry, key.Bytes())
+	aead, err := deriveAEAD(sx, sy, ct[headerSize-PointSize:headerSize], pub.Point)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := aead.Open(nil, make([]byte, aead.NonceSize()), ct[headerSize:], ct[:headerSize])
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decrypt(t, pub, shares[1:], ct, nil)
+	if err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("threshold decryption %q, %v does not match full-key decryption %q", got, err, want)
+	}
+
+	for _, bad := range [][]byte{nil, {0}, order.Bytes(), make([]byte, 33)} {
+		if _, _, err := SplitKey(bad, 3, 2); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("SplitKey(%x): expected ErrInvalidKey, got %v", bad, err)
+		}
+	}
+}
+
+func TestCombineIdentifiesBadPartial(t *testing.T) {
+	pub, shares, err := GenerateKey(4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := Encrypt(pub, []byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partials := make([]*Partial, 3)
+	for i := range partials {
+		if partials[i], err = PartialDecrypt(shares[i], ct); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A custodian answering with another share's value, or a point without a
+	// matching proof, is caught by the proof.
+	forged, err := PartialDecrypt(shamir.ScalarShare{Index: 2, Value: shares[3].Value}, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rx, ry, _ := parseHeader(ct)
+	dx, dy := curve.ScalarMult(rx, ry, big.NewInt(7).Bytes())
+	swapped := &Partial{Index: 2, Point: elliptic.MarshalCompressed(curve, dx, dy), Proof: partials[1].Proof}
+
+	for name, bad := range map[string]*Partial{"forged": forged, "swapped point": swapped} {
+		_, err := Combine(pub, ct, nil, []*Partial{partials[0], bad, partials[2]})
+		if !errors.Is(err, ErrInvalidPartial) || !bytes.Contains([]byte(err.Error()), []byte("index 2")) {
+			t.Errorf("%s: expected ErrInvalidPartial naming index 2, got %v", name, err)
+		}
+	}
+
+	unknown := &Partial{Index: 9, Point: partials[1].Point, Proof: partials[1].Proof}
+	if _, err := Combine(pub, ct, nil, []*Partial{partials[0], unknown, partials[2]}); !errors.Is(err, ErrInvalidPartial) {
+		t.Errorf("unknown index: expected ErrInvalidPartial, got %v", err)
+	}
+	if _, err := Combine(pub, ct, nil, []*Partial{partials[0], partials[1], partials[1]}); !errors.Is(err, ErrInvalidPartial) {
+		t.Errorf("duplicate index: expected ErrInvalidPartial, got %v", err)
+	}
+
+	// A partial for another ciphertext does not verify against this one.
+	other, err := Encrypt(pub, []byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, err := PartialDecrypt(shares[1], other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Combine(pub, ct, nil, []*Partial{partials[0], stale, partials[2]}); !errors.Is(err, ErrInvalidPartial) {
+		t.Errorf("partial of another ciphertext: expected ErrInvalidPartial, got %v", err)
+	}
+}
+
+func TestPartialEncoding(t *testing.T) {
+	pub, shares, err := GenerateKey(3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := Encrypt(pub, []byte("over the wire"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var partials []*Partial
+	for _, share := range shares[:2] {
+		p, err := PartialDecrypt(share, ct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := ParsePartial(p.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		partials = append(partials, decoded)
+	}
+	if got, err := Combine(pub, ct, nil, partials); err != nil || string(got) != "over the wire" {
+		t.Fatalf("Combine after encoding: %q, %v", got, err)
+	}
+	if _, err := ParsePartial(partials[0].Bytes()[1:]); !errors.Is(err, ErrInvalidPartial) {
+		t.Errorf("truncated partial: expected ErrInvalidPartial, got %v", err)
+	}
+}
+
+func TestInvalidCiphertext(t *testing.T) {
+	pub, shares, err := GenerateKey(3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := Encrypt(pub, []byte("x"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	badVersion := bytes.Clone(ct)
+	badVersion[3] = 2
+	badPoint := bytes.Clone(ct)
+	badPoint[4] = 0x05
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"truncated": ct[:headerSize-1],
+		"magic":     append([]byte{0x00, 'S', 'H'}, ct[3:]...),
+		"version":   badVersion,
+		"point":     badPoint,
+	} {
+		if _, err := PartialDecrypt(shares[0], data); !errors.Is(err, ErrInvalidCiphertext) {
+			t.Errorf("%s: expected ErrInvalidCiphertext, got %v", name, err)
+		}
+	}
+
+	tampered := bytes.Clone(ct)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := decrypt(t, pub, shares[:2], tampered, nil); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("tampered: expected ErrDecryptionFailed, got %v", err)
+	}
+
+	if _, err := PartialDecrypt(shamir.ScalarShare{Index: 0, Value: shares[0].Value}, ct); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("zero index: expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestCiphertextIsVersionedFormat(t *testing.T) {
+	pub, _, err := GenerateKey(2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := Encrypt(pub, []byte("x"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := shamir.InspectShare(ct)
+	if err != nil || info.Format != "threshold ciphertext" {
+		t.Errorf("InspectShare: %+v, %v", info, err)
+	}
+	var merr *shamir.MigrationError
+	if _, err := shamir.Combine([][]byte{ct, ct}); !errors.As(err, &merr) || merr.Format != "threshold ciphertext" {
+		t.Errorf("Combine: expected a MigrationError, got %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+// Package toprf implements a threshold oblivious pseudorandom function (2HashDH)
+// on the NIST P-256 group.
+//
+// The OPRF key k is Shamir-shared in the scalar field of the group among n
+// servers. A client blinds its input, any t servers evaluate the blinded
+// element with their key shares, and the client combines the partial
+// evaluations with Lagrange coefficients in the exponent before unblinding:
+//
+//	client:   P = H(input), B = r·P                        (Blind)
+//	server i: Z_i = k_i·B                                   (Evaluate)
+//	client:   Z = Σ λ_i·Z_i = k·B, N = r⁻¹·Z = k·P          (Finalize)
+//	output:   SHA-256(input ‖ N)
+//
+// No server learns the input or the output, and no coalition of fewer than t
+// servers learns anything about k. The client cannot tell whether it combined
+// enough partials; with fewer than t it simply gets a different output.
+//
+// This package is experimental and deliberately internal. It is built on the
+// deprecated big.Int arithmetic of crypto/elliptic, which is not constant
+// time, and hashes to the curve by try-and-increment, whose running time
+// depends on the client's input, the very value an OPRF must hide. It is not
+// interoperable with RFC 9497 OPRF suites either. It can become public once
+// it is ported to constant-time group arithmetic and RFC 9380 hash-to-curve,
+// which the standard library does not expose.
+package toprf
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// ScalarSize is the length of an encoded key share scalar.
+const ScalarSize = 32
+
+var (
+	// ErrInvalidElement indicates that an encoded group element is malformed,
+	// not on the curve, or the identity.
+	ErrInvalidElement = errors.New("toprf: invalid group element")
+
+	// ErrInvalidKeyShare indicates that a key share has a zero index or an out-of-range scalar.
+	ErrInvalidKeyShare = errors.New("toprf: invalid key share")
+
+	// ErrDuplicateShare indicates that two partial evaluations carry the same index.
+	ErrDuplicateShare = errors.New("toprf: duplicate partial evaluation")
+
+	// ErrTooFewPartials indicates that fewer than two partial evaluations were supplied.
+	ErrTooFewPartials = errors.New("toprf: at least 2 partial evaluations required")
+)
+
+var curve = elliptic.P256()
+
+// order is the prime order of the group, i.e. the modulus of the scalar field.
+var order = shamir.P256.Order
+
+// KeyShare is one server's share of the OPRF key, a shamir.ScalarShare in
+// the P-256 scalar field.
+type KeyShare struct {
+	Index byte             // Non-zero evaluation point of the sharing polynomial
+	Value [ScalarSize]byte // Share of the key, big-endian scalar modulo the group order
+}
+
+// Partial is a server's evaluation of a blinded element with its key share.
+type Partial struct {
+	Index   byte   // Index of the key share that produced it
+	Element []byte // Compressed point k_i·B
+}
+
+// BlindState is the client state kept between Blind and Finalize. It must not
+// be reused for another evaluation.
+type BlindState struct {
+	input []byte
+	r     *big.Int
+}
+
+// GenerateKey creates a random OPRF key and returns it split into parts shares,
+// any threshold of which can evaluate the OPRF. The key itself is discarded.
+func GenerateKey(parts, threshold int) ([]KeyShare, error) {
+	key, err := randomScalar(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return splitKey(key, parts, threshold)
+}
+
+// SplitKey splits an existing OPRF key, a big-endian scalar modulo the group
+// order, into parts shares with the given threshold.
+func SplitKey(key []byte, parts, threshold int) ([]KeyShare, error) {
+	k := new(big.Int).SetBytes(key)
+	if len(key) > ScalarSize || k.Sign() == 0 || k.Cmp(order) >= 0 {
+		return nil, fmt.Errorf("toprf: key must be a non-zero scalar below the group order")
+	}
+	return splitKey(k, parts, threshold)
+}
+
+// splitKey Shamir-shares key in the scalar field of the group.
+func splitKey(key *big.Int, parts, threshold int) ([]KeyShare, error) {
+	scalarShares, err := shamir.SplitScalar(shamir.P256, key, parts, threshold)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]KeyShare, len(scalarShares))
+	for i, s := range scalarShares {
+		shares[i] = KeyShare(s)
+	}
+	return shares, nil
+}
+
+// Blind hashes input to the group and blinds it with a fresh random scalar.
+// Send the returned element to the servers and keep the state for Finalize.
+func Blind(input []byte) ([]byte, *BlindState, error) {
+	r, err := randomScalar(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	px, py := hashToCurve(input)
+	bx, by := curve.ScalarMult(px, py, scalarBytes(r))
+
+	state := &BlindState{input: append([]byte(nil), input...), r: r}
+	return elliptic.MarshalCompressed(curve, bx, by), state, nil
+}
+
+// Evaluate computes a server's partial evaluation of a blinded element.
+func Evaluate(share KeyShare, blinded []byte) (Partial, error) {
+	k, err := share.scalar()
+	if err != nil {
+		return Partial{}, err
+	}
+	bx, by, err := decodeElement(blinded)
+	if err != nil {
+		return Partial{}, err
+	}
+
+	zx, zy := curve.ScalarMult(bx, by, scalarBytes(k))
+	return Partial{Index: share.Index, Element: elliptic.MarshalCompressed(curve, zx, zy)}, nil
+}
+
+// Finalize combines at least threshold partial evaluations of the element
+// returned by Blind and unblinds the result, returning the 32-byte OPRF output.
+func Finalize(state *BlindState, partials []Partial) ([]byte, error) {
+	if len(partials) < 2 {
+		return nil, ErrTooFewPartials
+	}
+
+	quorum := make([]shamir.ScalarShare, len(partials))
+	seen := make(map[byte]bool, len(partials))
+	for i, p := range partials {
+		if p.Index == 0 {
+			return nil, ErrInvalidKeyShare
+		}
+		if seen[p.Index] {
+			return nil, ErrDuplicateShare
+		}
+		seen[p.Index] = true
+		quorum[i].Index = p.Index
+	}
+	lambdas, err := shamir.ScalarLagrangeCoefficients(shamir.P256, quorum)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lagrange interpolation at zero, performed in the exponent.
+	var zx, zy *big.Int
+	for i, p := range partials {
+		px, py, err := decodeElement(p.Element)
+		if err != nil {
+			return nil, fmt.Errorf("partial %d: %w", i, err)
+		}
+		tx, ty := curve.ScalarMult(px, py, scalarBytes(lambdas[i]))
+		if zx == nil {
+			zx, zy = tx, ty
+		} else {
+			zx, zy = curve.Add(zx, zy, tx, ty)
+		}
+	}
+
+	rInv := new(big.Int).ModInverse(state.r, order)
+	nx, ny := curve.ScalarMult(zx, zy, scalarBytes(rInv))
+
+	return finalHash(state.input, nx, ny), nil
+}
+
+// evaluateUnblinded computes the OPRF output directly from the full key. It is
+// the reference the threshold protocol must agree with.
+func evaluateUnblinded(key *big.Int, input []byte) []byte {
+	px, py := hashToCurve(input)
+	nx, ny := curve.ScalarMult(px, py, scalarBytes(key))
+	return finalHash(input, nx, ny)
+}
+
+// finalHash derives the OPRF output from the input and the unblinded element.
+func finalHash(input []byte, x, y *big.Int) []byte {
+	h := sha256.New()
+	h.Write([]byte("toprf-P256-SHA256 finalize"))
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(input))))
+	h.Write(input)
+	h.Write(elliptic.MarshalCompressed(curve, x, y))
+	return h.Sum(nil)
+}
+
+// hashToCurve maps input to a P-256 point with unknown discrete logarithm by
+// try-and-increment: the first counter whose hash is a valid x-coordinate wins.
+func hashToCurve(input []byte) (*big.Int, *big.Int) {
+	params := curve.Params()
+	three := big.NewInt(3)
+
+	for counter := uint32(0); ; counter++ {
+		h := sha256.New()
+		h.Write([]byte("toprf-P256-SHA256 hash-to-curve"))
+		h.Write(binary.BigEndian.AppendUint32(nil, counter))
+		h.Write(input)
+		x := new(big.Int).SetBytes(h.Sum(nil))
+		if x.Cmp(params.P) >= 0 {
+			continue
+		}
+
+		// y² = x³ - 3x + b
+		y2 := new(big.Int).Exp(x, three, params.P)
+		y2.Sub(y2, new(big.Int).Mul(three, x))
+		y2.Add(y2, params.B)
+		y2.Mod(y2, params.P)
+
+		y := new(big.Int).ModSqrt(y2, params.P)
+		if y == nil {
+			continue
+		}
+		if y.Bit(0) == 1 {
+			y.Sub(params.P, y) // Canonical even y
+		}
+		return x, y
+	}
+}
+
+// randomScalar returns a uniformly random non-zero scalar.
+func randomScalar(rng io.Reader) (*big.Int, error) {
+	max := new(big.Int).Sub(order, big.NewInt(1))
+	k, err := rand.Int(rng, max)
+	if err != nil {
+		return nil, fmt.Errorf("toprf: failed to generate random scalar: %w", err)
+	}
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+// scalarBytes encodes a scalar as a fixed-length big-endian byte string.
+func scalarBytes(k *big.Int) []byte {
+	return k.FillBytes(make([]byte, ScalarSize))
+}
+
+// scalar decodes and validates the share value.
+func (s KeyShare) scalar() (*big.Int, error) {
+	k := new(big.Int).SetBytes(s.Value[:])
+	if s.Index == 0 || k.Sign() == 0 || k.Cmp(order) >= 0 {
+		return nil, ErrInvalidKeyShare
+	}
+	return k, nil
+}
+
+// decodeElement decodes a compressed point, rejecting invalid encodings.
+func decodeElement(data []byte) (*big.Int, *big.Int, error) {
+	x, y := elliptic.UnmarshalCompressed(curve, data)
+	if x == nil {
+		return nil, nil, ErrInvalidElement
+	}
+	return x, y, nil
+}
@@ -0,0 +1,113 @@
+package toprf
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// evaluate runs the full protocol for input against the given key shares.
+func evaluate(t *testing.T, shares []KeyShare, input []byte) []byte {
+	t.Helper()
+	blinded, state, err := Blind(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partials := make([]Partial, len(shares))
+	for i, share := range shares {
+		if partials[i], err = Evaluate(share, blinded); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, err := Finalize(state, partials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestThresholdMatchesFullKey(t *testing.T) {
+	key := big.NewInt(0x1234567890abcdef)
+	shares, err := SplitKey(key.Bytes(), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := []byte("correct horse battery staple")
+	want := evaluateUnblinded(key, input)
+
+	for _, subset := range [][]KeyShare{shares[:3], shares[2:], {shares[0], shares[2], shares[4]}, shares} {
+		if got := evaluate(t, subset, input); !bytes.Equal(got, want) {
+			t.Fatalf("threshold output %x does not match full-key output %x", got, want)
+		}
+	}
+
+	if got := evaluate(t, shares[:2], input); bytes.Equal(got, want) {
+		t.Fatal("fewer than threshold partials produced the real output")
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	shares, err := GenerateKey(3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := evaluate(t, shares[:2], []byte("alice"))
+	if !bytes.Equal(a, evaluate(t, shares[1:], []byte("alice"))) {
+		t.Fatal("output depends on which servers answered")
+	}
+	if bytes.Equal(a, evaluate(t, shares[:2], []byte("bob"))) {
+		t.Fatal("different inputs produced the same output")
+	}
+}
+
+func TestBlindingHidesInput(t *testing.T) {
+	b1, _, err := Blind([]byte("same input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2, _, err := Blind([]byte("same input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(b1, b2) {
+		t.Fatal("blinded elements for the same input are linkable")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	shares, err := GenerateKey(3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blinded, state, err := Blind([]byte("input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Evaluate(shares[0], []byte{2, 1, 2, 3}); err != ErrInvalidElement {
+		t.Errorf("expected ErrInvalidElement, got %v", err)
+	}
+	if _, err := Evaluate(KeyShare{Index: 1}, blinded); err != ErrInvalidKeyShare {
+		t.Errorf("expected ErrInvalidKeyShare, got %v", err)
+	}
+
+	p, err := Evaluate(shares[0], blinded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Finalize(state, []Partial{p}); err != ErrTooFewPartials {
+		t.Errorf("expected ErrTooFewPartials, got %v", err)
+	}
+	if _, err := Finalize(state, []Partial{p, p}); err != ErrDuplicateShare {
+		t.Errorf("expected ErrDuplicateShare, got %v", err)
+	}
+
+	if _, err := SplitKey(make([]byte, 32), 3, 2); err == nil {
+		t.Error("expected error for zero key")
+	}
+	if _, err := GenerateKey(3, 4); err == nil {
+		t.Error("expected error for threshold above parts")
+	}
+}
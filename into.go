@@ -0,0 +1,154 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"sync"
+
+	"github.com/morizta/go-shamir/gf256"
+)
+
+// Buffer-reusing Split and Combine.
+//
+// Split and Combine allocate the shares, the secret and their working memory
+// on every call. Services that split or combine many small secrets, such as
+// per-request key wrapping, can instead pass their own buffers to SplitInto
+// and CombineInto and keep the garbage collector out of the hot path.
+
+// coeffScratch is the polynomial coefficient storage of one SplitInto call.
+type coeffScratch struct {
+	flat []byte   // threshold-1 random coefficient rows, back to back
+	rows [][]byte // The secret followed by the rows of flat
+	bufs [][]byte // flat, as passed to readEntropy
+
+	release func() // Wipes the scratch and returns it to coeffPool
+}
+
+// coeffPool recycles coefficient storage between SplitInto calls. Scratch is
+// wiped before it is put back.
+var coeffPool sync.Pool
+
+// getCoeffScratch takes scratch from coeffPool or creates it.
+func getCoeffScratch() *coeffScratch {
+	if s, ok := coeffPool.Get().(*coeffScratch); ok {
+		return s
+	}
+	s := &coeffScratch{bufs: make([][]byte, 1)}
+	s.release = func() {
+		secureZeroBytes(s.flat)
+		s.rows[0] = nil
+		coeffPool.Put(s)
+	}
+	return s
+}
+
+// SplitInto splits secret like Split, writing the shares into dst instead of
+// allocating them. dst must hold at least parts slices; dst[i] is resliced to
+// len(secret)+ShareOverhead bytes and receives the share at x = i+1, and is
+// only reallocated if its capacity is too small. Slices beyond parts are left
+// untouched.
+//
+// Coefficient storage is pooled between calls, so once dst has grown to size a
+// split does not allocate. With memory locking enabled (see SetMemoryLocking)
+// the coefficients are allocated in locked memory on every call instead.
+func SplitInto(dst [][]byte, secret []byte, parts, threshold int) error {
+	if err := validateSplitParams(secret, parts, threshold); err != nil {
+		return err
+	}
+	if len(dst) < parts {
+		return NewValidationError("dst", len(dst), "shamir: dst holds fewer slices than parts")
+	}
+
+	var coeffs [][]byte
+	if memoryLocking.Load() {
+		locked, err := randomCoefficients(secret, threshold, rand.Reader, engine{})
+		if err != nil {
+			return err
+		}
+		defer wipeCoefficients(locked)
+		coeffs = locked
+	} else {
+		scratch := getCoeffScratch()
+		scratch.reset(secret, threshold)
+		if err := readEntropy(nil, rand.Reader, 0, scratch.bufs, scratch.release); err != nil {
+			return err
+		}
+		defer scratch.release()
+		coeffs = scratch.rows
+	}
+
+	n := len(secret) + ShareOverhead
+	for i := 0; i < parts; i++ {
+		if cap(dst[i]) < n {
+			dst[i] = make([]byte, n)
+		}
+		share := dst[i][:n]
+		share[0] = byte(i + 1)
+		gfPolyEvalSlice(share[ShareOverhead:], coeffs, byte(i+1))
+		dst[i] = share
+	}
+	return nil
+}
+
+// reset sizes s for a polynomial of the given threshold over secret, reusing
+// its storage where possible.
+func (s *coeffScratch) reset(secret []byte, threshold int) {
+	size := len(secret) * (threshold - 1)
+	if cap(s.flat) < size {
+		s.flat = make([]byte, size)
+	}
+	s.flat = s.flat[:size]
+	if cap(s.rows) < threshold {
+		s.rows = make([][]byte, threshold)
+	}
+	s.rows = s.rows[:threshold]
+	s.rows[0], s.bufs[0] = secret, s.flat
+	for i := 1; i < threshold; i++ {
+		s.rows[i] = s.flat[(i-1)*len(secret) : i*len(secret)]
+	}
+}
+
+// CombineInto reconstructs the secret like Combine, writing it into dst
+// instead of allocating it, and returns its length. dst must be at least as
+// long as the secret (the share length minus ShareOverhead).
+//
+// CombineInto handles raw shares only and does not allocate; shares in any
+// other format yield a *MigrationError naming the function to use.
+func CombineInto(dst []byte, parts [][]byte) (int, error) {
+	enveloped, err := checkLegacyFormat("CombineInto", parts, "CombineWithOptions")
+	if err != nil {
+		return 0, err
+	}
+	if enveloped {
+		return 0, &MigrationError{Func: "CombineInto", Share: 0, Format: "enveloped", Replacement: "CombineWithOptions"}
+	}
+	if err := checkRawLayout("CombineInto", parts); err != nil {
+		return 0, err
+	}
+	if err := validateCombineParams(parts); err != nil {
+		return 0, err
+	}
+
+	secretLen := len(parts[0]) - ShareOverhead
+	if len(dst) < secretLen {
+		return 0, NewValidationError("dst", len(dst), "shamir: dst is shorter than the secret")
+	}
+	// validateCombineParams guarantees distinct x-coordinates, so at most 256 shares.
+	var xBuf, weightBuf [256]byte
+	xCoords, weights := xBuf[:len(parts)], weightBuf[:len(parts)]
+	for i, part := range parts {
+		xCoords[i] = part[0]
+	}
+	gf256.LagrangeBasis(weights, xCoords, 0)
+
+	interpolateInto(dst[:secretLen], parts, weights)
+	return secretLen, nil
+}
+
+// interpolateInto reconstructs secret as the weighted sum of the share
+// payloads on the calling goroutine, without allocating.
+func interpolateInto(secret []byte, parts [][]byte, weights []byte) {
+	clear(secret)
+	for i, part := range parts {
+		gfMulAddSlice(secret, part[ShareOverhead:ShareOverhead+len(secret)], weights[i])
+	}
+}
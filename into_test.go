@@ -0,0 +1,137 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSplitIntoCombineInto(t *testing.T) {
+	dst := make([][]byte, 6)
+	out := make([]byte, 2048)
+	for _, size := range []int{1, 32, 1500} {
+		secret := bytes.Repeat([]byte{byte(size)}, size)
+		if err := SplitInto(dst, secret, 5, 3); err != nil {
+			t.Fatal(err)
+		}
+		if dst[5] != nil {
+			t.Fatal("SplitInto wrote past parts")
+		}
+		for i, share := range dst[:5] {
+			if len(share) != size+ShareOverhead || share[0] != byte(i+1) {
+				t.Fatalf("share %d: len %d, x %d", i, len(share), share[0])
+			}
+		}
+
+		// Interchangeable with Combine
+		got, err := Combine(dst[1:4])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Fatalf("Combine = %x, want %x", got, secret)
+		}
+
+		n, err := CombineInto(out, [][]byte{dst[4], dst[0], dst[2]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out[:n], secret) {
+			t.Fatalf("CombineInto = %x, want %x", out[:n], secret)
+		}
+	}
+}
+
+func TestSplitIntoReusesBuffers(t *testing.T) {
+	dst := make([][]byte, 3)
+	for i := range dst {
+		dst[i] = make([]byte, 0, 64)
+	}
+	backing := make([]*byte, len(dst))
+	for i := range dst {
+		backing[i] = &dst[i][:1][0]
+	}
+
+	if err := SplitInto(dst, []byte("reused"), 3, 2); err != nil {
+		t.Fatal(err)
+	}
+	for i := range dst {
+		if &dst[i][0] != backing[i] {
+			t.Errorf("share %d was reallocated despite sufficient capacity", i)
+		}
+	}
+}
+
+func TestSplitIntoCombineIntoAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random under the race detector")
+	}
+	secret := make([]byte, 1024)
+	dst := make([][]byte, 5)
+	if err := SplitInto(dst, secret, 5, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	out := make([]byte, len(secret))
+	if allocs := testing.AllocsPerRun(100, func() {
+		if _, err := CombineInto(out, dst[:3]); err != nil {
+			t.Fatal(err)
+		}
+	}); allocs != 0 {
+		t.Errorf("CombineInto allocates %v times per call, want 0", allocs)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		if err := SplitInto(dst, secret, 5, 3); err != nil {
+			t.Fatal(err)
+		}
+	}); allocs != 0 {
+		t.Errorf("SplitInto allocates %v times per call, want 0", allocs)
+	}
+}
+
+func TestSplitIntoCombineIntoValidation(t *testing.T) {
+	var verr *ValidationError
+	if err := SplitInto(make([][]byte, 2), []byte("x"), 3, 2); !errors.As(err, &verr) || verr.Field != "dst" {
+		t.Errorf("short dst: got %v", err)
+	}
+	if err := SplitInto(make([][]byte, 3), nil, 3, 2); !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("empty secret: got %v", err)
+	}
+
+	shares, err := Split([]byte("secret"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CombineInto(make([]byte, 5), shares); !errors.As(err, &verr) || verr.Field != "dst" {
+		t.Errorf("short dst: got %v", err)
+	}
+	if _, err := CombineInto(make([]byte, 6), shares[:1]); !errors.Is(err, ErrTooFewParts) {
+		t.Errorf("one share: got %v", err)
+	}
+
+	enveloped, err := SplitWithOptions([]byte("secret"), WithParts(3), WithThreshold(2), WithPurpose("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CombineInto(make([]byte, 64), enveloped); !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("enveloped shares: got %v, want ErrFormatMismatch", err)
+	}
+}
+
+func TestSplitIntoMemoryLocking(t *testing.T) {
+	SetMemoryLocking(true)
+	defer SetMemoryLocking(false)
+
+	dst := make([][]byte, 3)
+	if err := SplitInto(dst, []byte("locked"), 3, 2); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Combine(dst[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "locked" {
+		t.Fatalf("Combine = %q", got)
+	}
+}
@@ -0,0 +1,56 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"iter"
+)
+
+// SplitIter splits a secret like Split but generates the shares lazily, one
+// per iteration, so callers can stream them to storage without holding
+// parts × len(secret) bytes in memory. Only the polynomial coefficients
+// (threshold × len(secret) bytes) and the share being yielded are live at once.
+//
+// Each Share carries its threshold and a set ID common to the split; marshal it
+// with MarshalBinary for an enveloped share, or use Index and Payload directly
+// for the Split format. Payloads are freshly allocated and owned by the caller.
+// The iterator yields the share's position (0 to parts-1) with each share.
+//
+// The sequence may be ranged over once. The coefficients are wiped when the
+// range ends, whether it completes or breaks early.
+func SplitIter(secret []byte, parts, threshold int) (iter.Seq2[int, Share], error) {
+	if err := validateSplitParams(secret, parts, threshold); err != nil {
+		return nil, err
+	}
+	setID, err := newSetID()
+	if err != nil {
+		return nil, err
+	}
+	coeffs, err := randomCoefficients(secret, threshold, rand.Reader, engine{})
+	if err != nil {
+		return nil, err
+	}
+
+	used := false
+	return func(yield func(int, Share) bool) {
+		if used {
+			return
+		}
+		used = true
+		defer wipeCoefficients(coeffs)
+
+		for i := 0; i < parts; i++ {
+			x := byte(i + 1) // x-coordinates are 1-based (never 0)
+			share := Share{
+				Version:   EnvelopeVersion,
+				Threshold: threshold,
+				Index:     x,
+				SetID:     setID,
+				Payload:   make([]byte, len(secret)),
+			}
+			gfPolyEvalSlice(share.Payload, coeffs, x)
+			if !yield(i, share) {
+				return
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,85 @@
+package shamir
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitIter(t *testing.T) {
+	secret := []byte("lazily generated shares")
+	seq, err := SplitIter(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var raw, enveloped [][]byte
+	var setID SetID
+	for i, share := range seq {
+		if int(share.Index) != i+1 || share.Threshold != 3 {
+			t.Fatalf("share %d: index %d, threshold %d", i, share.Index, share.Threshold)
+		}
+		if i == 0 {
+			setID = share.SetID
+		} else if share.SetID != setID || setID.IsZero() {
+			t.Fatal("shares must share a non-zero set ID")
+		}
+
+		raw = append(raw, append([]byte{share.Index}, share.Payload...))
+		env, err := share.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		enveloped = append(enveloped, env)
+	}
+	if len(raw) != 5 {
+		t.Fatalf("got %d shares, want 5", len(raw))
+	}
+
+	reconstructed, err := Combine(raw[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("raw shares did not reconstruct the secret")
+	}
+
+	reconstructed, err = CombineWithOptions(enveloped[:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("enveloped shares did not reconstruct the secret")
+	}
+
+	t.Run("single use", func(t *testing.T) {
+		for range seq {
+			t.Fatal("second range yielded a share")
+		}
+	})
+
+	t.Run("early break", func(t *testing.T) {
+		seq, err := SplitIter(secret, 5, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for range seq {
+			n++
+			if n == 2 {
+				break
+			}
+		}
+		if n != 2 {
+			t.Fatalf("yielded %d shares before break, want 2", n)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		if _, err := SplitIter(nil, 5, 3); err != ErrEmptySecret {
+			t.Errorf("expected ErrEmptySecret, got %v", err)
+		}
+		if _, err := SplitIter(secret, 3, 4); err == nil {
+			t.Error("expected error for threshold above parts")
+		}
+	})
+}
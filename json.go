@@ -0,0 +1,192 @@
+package shamir
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"time"
+)
+
+// shareJSON is the JSON form of an enveloped share. Byte strings use standard
+// base64 (the encoding/json default); the checksum is the envelope's CRC32 in
+// hexadecimal, so a JSON share and its binary envelope verify identically.
+type shareJSON struct {
+	Version    byte             `json:"version"`
+	Threshold  int              `json:"threshold"`
+	Index      byte             `json:"index"`
+	Flags      byte             `json:"flags,omitempty"`
+	Algorithm  string           `json:"algorithm,omitempty"`
+	Purpose    string           `json:"purpose,omitempty"`
+	PurposeMAC []byte           `json:"purpose_mac,omitempty"`
+	SetID      *SetID           `json:"set_id,omitempty"`
+	Label      string           `json:"label,omitempty"`
+	CreatedAt  *time.Time       `json:"created_at,omitempty"`
+	NotBefore  *time.Time       `json:"not_before,omitempty"`
+	NotAfter   *time.Time       `json:"not_after,omitempty"`
+	Commitment []byte           `json:"commitment,omitempty"`
+	Metadata   []metaRecordJSON `json:"metadata,omitempty"`
+	Payload    []byte           `json:"payload"`
+	Checksum   string           `json:"checksum"`
+}
+
+// metaRecordJSON is the JSON form of a metadata record this version does not understand.
+type metaRecordJSON struct {
+	Tag   byte   `json:"tag"`
+	Value []byte `json:"value"`
+}
+
+// MarshalJSON encodes the share as a self-describing JSON object carrying the
+// format version, threshold, x-coordinate, metadata, base64 payload and checksum.
+func (s *Share) MarshalJSON() ([]byte, error) {
+	binaryShare, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	defer secureZeroBytes(binaryShare)
+
+	out := shareJSON{
+		Version:    EnvelopeVersion,
+		Threshold:  s.Threshold,
+		Index:      s.Index,
+		Flags:      s.flags &^ envelopeCriticalFlags,
+		Algorithm:  s.Algorithm,
+		Purpose:    s.Purpose,
+		PurposeMAC: s.purposeMAC,
+		Label:      s.Label,
+		Commitment: s.commitment,
+		Payload:    s.Payload,
+		Checksum:   hex.EncodeToString(binaryShare[len(binaryShare)-envelopeChecksumSize:]),
+	}
+	if !s.SetID.IsZero() {
+		out.SetID = &s.SetID
+	}
+	if !s.CreatedAt.IsZero() {
+		created := time.Unix(s.CreatedAt.Unix(), 0).UTC()
+		out.CreatedAt = &created
+	}
+	if !s.NotBefore.IsZero() {
+		notBefore := time.Unix(s.NotBefore.Unix(), 0).UTC()
+		out.NotBefore = &notBefore
+	}
+	if !s.NotAfter.IsZero() {
+		notAfter := time.Unix(s.NotAfter.Unix(), 0).UTC()
+		out.NotAfter = &notAfter
+	}
+	for _, r := range s.extra {
+		out.Metadata = append(out.Metadata, metaRecordJSON{Tag: r.tag, Value: r.value})
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a share produced by MarshalJSON and verifies its checksum.
+func (s *Share) UnmarshalJSON(data []byte) error {
+	var in shareJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	if in.Version != EnvelopeVersion {
+		return fmt.Errorf("%w: version %d", ErrUnsupportedVersion, in.Version)
+	}
+	if flags := in.Flags & envelopeCriticalFlags; flags != 0 {
+		return fmt.Errorf("%w: flags %#x", ErrUnsupportedVersion, flags)
+	}
+
+	checksum, err := hex.DecodeString(in.Checksum)
+	if err != nil || len(checksum) != envelopeChecksumSize {
+		return fmt.Errorf("%w: malformed checksum", ErrInvalidEnvelope)
+	}
+
+	decoded := Share{
+		Version:    in.Version,
+		Threshold:  in.Threshold,
+		Index:      in.Index,
+		Algorithm:  in.Algorithm,
+		Purpose:    in.Purpose,
+		Label:      in.Label,
+		Payload:    in.Payload,
+		purposeMAC: in.PurposeMAC,
+		commitment: in.Commitment,
+		flags:      in.Flags,
+	}
+	if in.SetID != nil {
+		decoded.SetID = *in.SetID
+	}
+	if in.CreatedAt != nil {
+		decoded.CreatedAt = in.CreatedAt.UTC()
+	}
+	if in.NotBefore != nil {
+		decoded.NotBefore = in.NotBefore.UTC()
+	}
+	if in.NotAfter != nil {
+		decoded.NotAfter = in.NotAfter.UTC()
+	}
+	for _, r := range in.Metadata {
+		if r.Tag <= lastKnownTag {
+			return fmt.Errorf("%w: metadata tag %d is reserved", ErrInvalidEnvelope, r.Tag)
+		}
+		decoded.extra = append(decoded.extra, metaRecord{r.Tag, r.Value})
+	}
+
+	// Rebuild the envelope so the checksum covers exactly what the binary form does.
+	binaryShare, err := decoded.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	defer secureZeroBytes(binaryShare)
+	body := binaryShare[:len(binaryShare)-envelopeChecksumSize]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(checksum) {
+		return ErrIntegrityCheckFailed
+	}
+
+	*s = decoded
+	return nil
+}
+
+// EncodeSharesJSON encodes enveloped shares (such as those from SplitKey or
+// SplitWithOptions with a purpose) as a JSON array of share objects. Raw shares
+// from Split carry no threshold or version and are rejected with ErrInvalidEnvelope.
+func EncodeSharesJSON(parts [][]byte) ([]byte, error) {
+	shares := make([]*Share, len(parts))
+	for i, part := range parts {
+		s, err := ParseShare(part)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", i, err)
+		}
+		shares[i] = s
+	}
+	defer func() {
+		for _, s := range shares {
+			secureZeroBytes(s.Payload)
+		}
+	}()
+
+	return json.Marshal(shares)
+}
+
+// DecodeSharesJSON decodes a JSON array produced by EncodeSharesJSON back into
+// binary enveloped shares, verifying every checksum.
+func DecodeSharesJSON(data []byte) ([][]byte, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+
+	parts := make([][]byte, len(raw))
+	for i, msg := range raw {
+		var s Share
+		if err := s.UnmarshalJSON(msg); err != nil {
+			return nil, fmt.Errorf("share %d: %w", i, err)
+		}
+		encoded, err := s.MarshalBinary()
+		secureZeroBytes(s.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", i, err)
+		}
+		parts[i] = encoded
+	}
+
+	return parts, nil
+}
@@ -0,0 +1,122 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShareJSONRoundTrip(t *testing.T) {
+	original := &Share{
+		Threshold: 3,
+		Index:     7,
+		Algorithm: AlgAES256GCM,
+		Purpose:   "backup",
+		SetID:     SetID{1, 2, 3},
+		Label:     "alice@ops",
+		CreatedAt: time.Unix(1700000000, 0).UTC(),
+		Payload:   []byte{0xde, 0xad, 0xbe, 0xef},
+		extra:     []metaRecord{{tag: 200, value: []byte("future field")}},
+	}
+	original.purposeMAC = []byte("0123456789abcdef")
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"version":1`, `"threshold":3`, `"index":7`, `"payload":"3q2+7w=="`, `"checksum":"`, `"label":"alice@ops"`, `"created_at":"2023-11-14T22:13:20Z"`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("JSON %s is missing %s", data, field)
+		}
+	}
+
+	var decoded Share
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	original.Version = EnvelopeVersion
+	if !reflect.DeepEqual(&decoded, original) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", decoded, *original)
+	}
+
+	// The JSON checksum is the binary envelope's CRC32.
+	binaryShare, _ := original.MarshalBinary()
+	fromBinary, _ := ParseShare(binaryShare)
+	fromJSON, _ := decoded.MarshalBinary()
+	if !bytes.Equal(binaryShare, fromJSON) || fromBinary.Index != decoded.Index {
+		t.Fatal("JSON and binary encodings disagree")
+	}
+}
+
+func TestEncodeDecodeSharesJSON(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	shares, err := SplitKey(key, AlgAES256GCM, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := EncodeSharesJSON(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeSharesJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, shares) {
+		t.Fatal("decoded shares differ from the originals")
+	}
+
+	recovered, err := CombineKey(decoded[2:], AlgAES256GCM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, key) {
+		t.Fatal("key mismatch after JSON round trip")
+	}
+}
+
+func TestShareJSONErrors(t *testing.T) {
+	share := &Share{Threshold: 2, Index: 1, Payload: []byte("payload")}
+	valid, err := json.Marshal(share)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edit := func(from, to string) []byte {
+		return []byte(strings.Replace(string(valid), from, to, 1))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"tampered payload", edit(`"payload":"cGF5bG9hZA=="`, `"payload":"cGF5bG9hZQ=="`), ErrIntegrityCheckFailed},
+		{"tampered index", edit(`"index":1`, `"index":2`), ErrIntegrityCheckFailed},
+		{"future version", edit(`"version":1`, `"version":2`), ErrUnsupportedVersion},
+		{"malformed checksum", edit(`"checksum":"`, `"checksum":"zz`), ErrInvalidEnvelope},
+		{"invalid threshold", edit(`"threshold":2`, `"threshold":0`), ErrInvalidEnvelope},
+		{"not json", []byte("{"), ErrInvalidEnvelope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Share
+			if err := s.UnmarshalJSON(tt.data); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("raw shares rejected", func(t *testing.T) {
+		raw, _ := Split([]byte("secret"), 3, 2)
+		if _, err := EncodeSharesJSON(raw); !errors.Is(err, ErrInvalidEnvelope) {
+			t.Fatalf("expected ErrInvalidEnvelope, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package shamir
+
+// Differential fuzz targets comparing the vectorized slice kernels against
+// straightforward byte-at-a-time reference implementations. The seed corpus
+// runs as part of go test; run e.g. go test -fuzz=FuzzMultSlice to explore.
+
+import (
+	"bytes"
+	"testing"
+)
+
+// refMultSlice is the scalar reference for gfMultSlice.
+func refMultSlice(src []byte, scalar byte) []byte {
+	out := make([]byte, len(src))
+	for i, v := range src {
+		out[i] = gfMult(v, scalar)
+	}
+	return out
+}
+
+// refAddSlice is the scalar reference for gfAddSlice.
+func refAddSlice(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = gfAdd(a[i], b[i])
+	}
+	return out
+}
+
+// unaligned returns data starting at offset (mod len+1) so kernels see
+// slices that do not start on an 8-byte boundary.
+func unaligned(data []byte, offset uint8) []byte {
+	if len(data) == 0 {
+		return data
+	}
+	return data[int(offset)%(len(data)+1):]
+}
+
+func addKernelSeeds(f *testing.F) {
+	f.Add([]byte{}, byte(0), uint8(0))
+	f.Add([]byte{1}, byte(1), uint8(0))
+	f.Add([]byte{1, 2, 3, 4, 5, 6, 7}, byte(2), uint8(1))
+	f.Add([]byte("exactly sixteen!"), byte(0x53), uint8(3))
+	f.Add(bytes.Repeat([]byte{0xff, 0x00, 0x80}, 33), byte(0xff), uint8(5))
+}
+
+func FuzzMultSlice(f *testing.F) {
+	addKernelSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte, scalar byte, offset uint8) {
+		src := unaligned(data, offset)
+		dst := make([]byte, len(src)+1)[1:] // Deliberately misaligned destination
+		gfMultSlice(dst, src, scalar)
+		if want := refMultSlice(src, scalar); !bytes.Equal(dst, want) {
+			t.Fatalf("gfMultSlice(%x, %d) = %x, want %x", src, scalar, dst, want)
+		}
+
+		// In-place operation is used by gfPolyEvalSlice and must give the same result.
+		inPlace := append([]byte(nil), src...)
+		gfMultSlice(inPlace, inPlace, scalar)
+		if !bytes.Equal(inPlace, dst) {
+			t.Fatalf("in-place gfMultSlice differs: %x vs %x", inPlace, dst)
+		}
+	})
+}
+
+func FuzzAddSlice(f *testing.F) {
+	addKernelSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte, scalar byte, offset uint8) {
+		a := unaligned(data, offset)
+		b := make([]byte, len(a)+3)[3:]
+		for i := range b {
+			b[i] = a[len(a)-1-i] ^ scalar
+		}
+		dst := make([]byte, len(a)+5)[5:]
+		gfAddSlice(dst, a, b)
+		if want := refAddSlice(a, b); !bytes.Equal(dst, want) {
+			t.Fatalf("gfAddSlice(%x, %x) = %x, want %x", a, b, dst, want)
+		}
+	})
+}
+
+func FuzzPolyEvalSlice(f *testing.F) {
+	addKernelSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte, x byte, offset uint8) {
+		coeffs := unaligned(data, offset)
+		if len(coeffs) == 0 {
+			return
+		}
+
+		// Treat each byte as the coefficient of a one-byte-wide polynomial.
+		sliceCoeffs := make([][]byte, len(coeffs))
+		for i, c := range coeffs {
+			sliceCoeffs[i] = []byte{c, c ^ 0xff}
+		}
+		dst := make([]byte, 2)
+		gfPolyEvalSlice(dst, sliceCoeffs, x)
+
+		inverted := make([]byte, len(coeffs))
+		for i, c := range coeffs {
+			inverted[i] = c ^ 0xff
+		}
+		if dst[0] != gfPolyEval(coeffs, x) || dst[1] != gfPolyEval(inverted, x) {
+			t.Fatalf("gfPolyEvalSlice disagrees with gfPolyEval at x=%d", x)
+		}
+	})
+}
+
+func FuzzInterpolateSlice(f *testing.F) {
+	addKernelSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte, x byte, offset uint8) {
+		payload := unaligned(data, offset)
+		n := 2 + int(offset)%6
+		if len(payload) < n {
+			return
+		}
+
+		// Use distinct x-coordinates 1..n and carve y-values out of the payload.
+		xs := make([]byte, n)
+		ys := make([][]byte, n)
+		width := len(payload) / n
+		for i := range xs {
+			xs[i] = byte(i + 1)
+			ys[i] = payload[i*width : (i+1)*width]
+		}
+
+		dst := make([]byte, width)
+		lagrangeInterpolateSlice(dst, xs, ys, x)
+
+		column := make([]byte, n)
+		for pos := 0; pos < width; pos++ {
+			for i := range ys {
+				column[i] = ys[i][pos]
+			}
+			if want := lagrangeInterpolate(xs, column, x); dst[pos] != want {
+				t.Fatalf("position %d: slice interpolation %d, scalar %d", pos, dst[pos], want)
+			}
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package shamir
+
+import (
+	"fmt"
+)
+
+// Supported key algorithms for SplitKey.
+const (
+	AlgAES128GCM        = "AES-128-GCM"
+	AlgAES192GCM        = "AES-192-GCM"
+	AlgAES256GCM        = "AES-256-GCM"
+	AlgChaCha20Poly1305 = "ChaCha20-Poly1305"
+	AlgHMACSHA256       = "HMAC-SHA256"
+	AlgHMACSHA512       = "HMAC-SHA512"
+	AlgBIP39Entropy     = "BIP39-entropy" // Wallet mnemonic entropy, see the bip39 sub-package
+	AlgBIP32Seed        = "BIP32-seed"    // Wallet master seed, see the bip39 sub-package
+)
+
+// keyLengths lists the accepted key lengths (inclusive range) for each algorithm.
+var keyLengths = map[string][2]int{
+	AlgAES128GCM:        {16, 16},
+	AlgAES192GCM:        {24, 24},
+	AlgAES256GCM:        {32, 32},
+	AlgChaCha20Poly1305: {32, 32},
+	AlgHMACSHA256:       {32, 64},  // At least the hash output size, at most one block
+	AlgHMACSHA512:       {64, 128}, // At least the hash output size, at most one block
+	AlgBIP39Entropy:     {16, 32},  // 128 to 256 bits; the bip39 package checks for whole words
+	AlgBIP32Seed:        {16, 64},  // 128 to 512 bits, as BIP-0032 allows
+}
+
+// SplitKey splits a symmetric key and records its intended algorithm in every
+// share's envelope, so the recovered key is self-describing.
+//
+// Parameters:
+//   - key: The key to split; its length must suit alg
+//   - alg: One of the Alg* constants (e.g. AlgAES256GCM)
+//   - parts, threshold: As for Split
+//
+// Returns enveloped shares (see ParseShare); they must be combined with CombineKey.
+func SplitKey(key []byte, alg string, parts, threshold int) ([][]byte, error) {
+	if err := validateKeyLength(key, alg); err != nil {
+		return nil, err
+	}
+	o := newOptions([]Option{WithParts(parts), WithThreshold(threshold)})
+	return splitEnvelopes(key, o, Share{Algorithm: alg})
+}
+
+// CombineKey reconstructs a key split by SplitKey and checks that it was
+// intended for alg. Returns ErrAlgorithmMismatch if the shares record a
+// different algorithm, and ErrInvalidKeyLength if the recovered key does not
+// suit it.
+func CombineKey(parts [][]byte, alg string) ([]byte, error) {
+	if _, ok := keyLengths[alg]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, alg)
+	}
+
+	shares, err := parseEnvelopes(parts)
+	if err != nil {
+		return nil, err
+	}
+	for i, s := range shares {
+		if s.Algorithm != alg {
+			return nil, fmt.Errorf("share %d: %w: recorded %q, expected %q", i, ErrAlgorithmMismatch, s.Algorithm, alg)
+		}
+	}
+
+	key, err := combineEnvelopes(shares, engine{})
+	if err != nil {
+		return nil, err
+	}
+	if err := validateKeyLength(key, alg); err != nil {
+		freeSecret(key)
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// validateKeyLength checks that key has a valid length for alg.
+func validateKeyLength(key []byte, alg string) error {
+	lengths, ok := keyLengths[alg]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownAlgorithm, alg)
+	}
+	if len(key) < lengths[0] || len(key) > lengths[1] {
+		return fmt.Errorf("%w: %s requires %d to %d bytes, got %d",
+			ErrInvalidKeyLength, alg, lengths[0], lengths[1], len(key))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSplitKey(t *testing.T) {
+	key := bytes.Repeat([]byte{0xa5}, 32)
+
+	shares, err := SplitKey(key, AlgAES256GCM, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, share := range shares {
+		s, err := ParseShare(share)
+		if err != nil {
+			t.Fatalf("share %d: %v", i, err)
+		}
+		if s.Algorithm != AlgAES256GCM || s.Threshold != 3 || s.Index != byte(i+1) {
+			t.Fatalf("share %d has unexpected metadata: %+v", i, s)
+		}
+	}
+
+	t.Run("combine", func(t *testing.T) {
+		recovered, err := CombineKey(shares[2:], AlgAES256GCM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(recovered, key) {
+			t.Fatal("recovered key mismatch")
+		}
+	})
+
+	t.Run("wrong algorithm", func(t *testing.T) {
+		if _, err := CombineKey(shares[:3], AlgHMACSHA256); !errors.Is(err, ErrAlgorithmMismatch) {
+			t.Fatalf("expected ErrAlgorithmMismatch, got %v", err)
+		}
+	})
+
+	t.Run("insufficient shares", func(t *testing.T) {
+		var ierr *InsufficientSharesError
+		if _, err := CombineKey(shares[:2], AlgAES256GCM); !errors.As(err, &ierr) || ierr.Have != 2 || ierr.Need != 3 {
+			t.Fatalf("expected ErrInsufficientShares needing 3, got %v", err)
+		}
+	})
+
+	t.Run("raw shares rejected", func(t *testing.T) {
+		raw, _ := Split(key, 3, 2)
+		if _, err := CombineKey(raw, AlgAES256GCM); !errors.Is(err, ErrInvalidEnvelope) {
+			t.Fatalf("expected ErrInvalidEnvelope, got %v", err)
+		}
+	})
+}
+
+func TestSplitKeyValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyLen  int
+		alg     string
+		wantErr error
+	}{
+		{"aes-128", 16, AlgAES128GCM, nil},
+		{"aes-256 short key", 16, AlgAES256GCM, ErrInvalidKeyLength},
+		{"hmac-sha256 long key", 64, AlgHMACSHA256, nil},
+		{"hmac-sha256 short key", 16, AlgHMACSHA256, ErrInvalidKeyLength},
+		{"unknown algorithm", 32, "DES", ErrUnknownAlgorithm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SplitKey(make([]byte, tt.keyLen), tt.alg, 3, 2)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/morizta/go-shamir/internal/sigv4"
+)
+
+// AWSConfig configures an AWS KMS key. Region, KeyID and the access key are
+// required.
+type AWSConfig struct {
+	Region string
+
+	// KeyID is the key's ARN, or an alias ARN. Use a full ARN rather than a
+	// bare key ID so that shares record unambiguously which key wrapped them.
+	KeyID string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // For temporary credentials; optional
+
+	// Endpoint overrides the regional endpoint https://kms.<region>.amazonaws.com,
+	// e.g. for a VPC endpoint or FIPS endpoint.
+	Endpoint string
+
+	// Client sends the requests; nil means http.DefaultClient.
+	Client *http.Client
+}
+
+// awsContextKey is the encryption context key the share's set ID and index
+// are recorded under.
+const awsContextKey = "go-shamir-share"
+
+// AWSKey is a symmetric AWS KMS key. Requests use the KMS JSON API, signed
+// with AWS Signature Version 4.
+type AWSKey struct {
+	cfg      AWSConfig
+	endpoint string
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewAWSKey returns the key described by cfg. It does not contact AWS.
+func NewAWSKey(cfg AWSConfig) (*AWSKey, error) {
+	if cfg.Region == "" || cfg.KeyID == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%w: AWS region, key ID and access key are required", ErrInvalidConfig)
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://kms." + cfg.Region + ".amazonaws.com"
+	}
+	if u, err := url.Parse(endpoint); err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%w: AWS endpoint %q", ErrInvalidConfig, endpoint)
+	}
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &AWSKey{cfg: cfg, endpoint: strings.TrimSuffix(endpoint, "/") + "/", client: client, now: time.Now}, nil
+}
+
+// ID returns the configured key ID.
+func (k *AWSKey) ID() string {
+	return k.cfg.KeyID
+}
+
+// Encrypt implements Key with the KMS Encrypt action; aad becomes the
+// encryption context.
+func (k *AWSKey) Encrypt(ctx context.Context, plaintext, aad []byte) ([]byte, error) {
+	req := struct {
+		KeyId             string
+		Plaintext         []byte
+		EncryptionContext map[string]string
+	}{k.cfg.KeyID, plaintext, map[string]string{awsContextKey: string(aad)}}
+	var resp struct {
+		CiphertextBlob []byte
+	}
+	if err := k.call(ctx, "Encrypt", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.CiphertextBlob, nil
+}
+
+// Decrypt implements Key with the KMS Decrypt action.
+func (k *AWSKey) Decrypt(ctx context.Context, ciphertext, aad []byte) ([]byte, error) {
+	req := struct {
+		KeyId             string
+		CiphertextBlob    []byte
+		EncryptionContext map[string]string
+	}{k.cfg.KeyID, ciphertext, map[string]string{awsContextKey: string(aad)}}
+	var resp struct {
+		Plaintext []byte
+	}
+	if err := k.call(ctx, "Decrypt", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
+
+// call invokes a KMS action.
+func (k *AWSKey) call(ctx context.Context, action string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("kms: AWS %s: %w", action, err)
+	}
+	defer clear(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("kms: AWS %s: %w", action, err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	sigv4.Sign(req, body, k.now(), "kms", k.cfg.Region, sigv4.Credentials{
+		AccessKeyID:     k.cfg.AccessKeyID,
+		SecretAccessKey: k.cfg.SecretAccessKey,
+		SessionToken:    k.cfg.SessionToken,
+	})
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kms: AWS %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var aerr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"` // Matches "Message" too
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&aerr)
+		return fmt.Errorf("kms: AWS %s with %s: %s: %s %s", action, k.cfg.KeyID, resp.Status, aerr.Type, aerr.Message)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("kms: AWS %s: decoding response: %w", action, err)
+	}
+	return nil
+}
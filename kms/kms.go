@@ -0,0 +1,240 @@
+// Package kms escrows a secret with cloud key management services: each share
+// is wrapped by a different KMS key, one per custodian, account or region, as
+// soon as it is generated, and unwrapped again only to combine. Any threshold
+// of the keys recover the secret, so a "3 of 5 regions" arrangement survives
+// the loss of two regions, and no plaintext share is ever written out.
+//
+// Keys are pluggable: AWSKey (AWS KMS) and GCPKey (Google Cloud KMS) are
+// built in and talk to the services' HTTP APIs directly, and anything else,
+// such as an HSM, can implement Key.
+//
+// Wrapped share format, in the versioned layout of the parent package:
+//
+//	[3 bytes]  magic 0x00 'S' 'K'
+//	[1 byte]   format version (1)
+//	[16 bytes] set ID of the split
+//	[1 byte]   share index
+//	[2 bytes]  key ID length, big-endian
+//	[n bytes]  key ID, e.g. a key ARN or resource name
+//	[m bytes]  ciphertext returned by the KMS
+//
+// The plaintext is an enveloped share. The set ID and index, as
+// "<set ID>/<index>", are bound to the ciphertext as KMS additional
+// authenticated data (the encryption context for AWS), so a wrapped share
+// cannot be relabelled, and they show up in the services' audit logs.
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+var (
+	// ErrInvalidShare indicates a wrapped share that is malformed or whose
+	// unwrapped contents do not match its header.
+	ErrInvalidShare = errors.New("kms: invalid wrapped share")
+
+	// ErrNoKey indicates that no key was given for a wrapped share's key ID.
+	ErrNoKey = errors.New("kms: no key for wrapped share")
+
+	// ErrInvalidConfig indicates a key configuration missing a required field.
+	ErrInvalidConfig = errors.New("kms: invalid configuration")
+)
+
+// Key is a key held by a key management service.
+type Key interface {
+	// ID identifies the key, and is recorded in every share it wraps so that
+	// Combine can find the key again.
+	ID() string
+
+	// Encrypt encrypts plaintext, binding aad to the ciphertext.
+	Encrypt(ctx context.Context, plaintext, aad []byte) ([]byte, error)
+
+	// Decrypt decrypts a ciphertext from Encrypt with the same aad.
+	Decrypt(ctx context.Context, ciphertext, aad []byte) ([]byte, error)
+}
+
+// wrappedMagic identifies a KMS-wrapped share.
+var wrappedMagic = [3]byte{0x00, 'S', 'K'}
+
+const (
+	formatVersion = 1
+	headerSize    = len(wrappedMagic) + 1 + len(shamir.SetID{}) + 1 + 2
+)
+
+// header is the decoded header of a wrapped share.
+type header struct {
+	set   shamir.SetID
+	index byte
+	keyID string
+	size  int // Encoded length
+}
+
+// aad returns the additional authenticated data bound to the ciphertext.
+func (h *header) aad() []byte {
+	return fmt.Appendf(nil, "%s/%03d", h.set, h.index)
+}
+
+func (h *header) marshal() []byte {
+	out := make([]byte, 0, headerSize+len(h.keyID))
+	out = append(out, wrappedMagic[:]...)
+	out = append(out, formatVersion)
+	out = append(out, h.set[:]...)
+	out = append(out, h.index)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(h.keyID)))
+	return append(out, h.keyID...)
+}
+
+func parseHeader(wrapped []byte) (*header, error) {
+	if len(wrapped) < headerSize || !bytes.HasPrefix(wrapped, wrappedMagic[:]) {
+		return nil, ErrInvalidShare
+	}
+	if v := wrapped[len(wrappedMagic)]; v != formatVersion {
+		return nil, fmt.Errorf("%w: wrapped share version %d", shamir.ErrUnsupportedVersion, v)
+	}
+	h := &header{}
+	p := len(wrappedMagic) + 1
+	p += copy(h.set[:], wrapped[p:])
+	h.index = wrapped[p]
+	n := int(binary.BigEndian.Uint16(wrapped[p+1:]))
+	p += 3
+	if n == 0 || len(wrapped) <= p+n {
+		return nil, ErrInvalidShare
+	}
+	h.keyID = string(wrapped[p : p+n])
+	h.size = p + n
+	return h, nil
+}
+
+// KeyID returns the ID of the key that wrapped a share, so that it can be
+// routed to the custodian holding that key.
+func KeyID(wrapped []byte) (string, error) {
+	h, err := parseHeader(wrapped)
+	if err != nil {
+		return "", err
+	}
+	return h.keyID, nil
+}
+
+// recipient adapts a Key to shamir.Recipient for SplitEncrypted.
+type recipient struct {
+	ctx context.Context
+	key Key
+}
+
+func (r recipient) EncryptShare(share []byte) ([]byte, error) {
+	info, err := shamir.InspectShare(share)
+	if err != nil {
+		return nil, err
+	}
+	h := &header{set: info.SetID, index: info.Index, keyID: r.key.ID()}
+	if h.keyID == "" || len(h.keyID) > 0xffff {
+		return nil, fmt.Errorf("%w: key ID must be 1 to 65535 bytes", ErrInvalidConfig)
+	}
+	ciphertext, err := r.key.Encrypt(r.ctx, share, h.aad())
+	if err != nil {
+		return nil, err
+	}
+	return append(h.marshal(), ciphertext...), nil
+}
+
+// Split splits secret into one share per key, threshold of which recover it,
+// and returns each share wrapped by its key: the i-th wrapped share is for
+// keys[i]. Each plaintext share is wiped as soon as it is wrapped. If any key
+// fails, Split fails and no shares are returned.
+func Split(ctx context.Context, secret []byte, threshold int, keys []Key) ([][]byte, error) {
+	recipients := make([]shamir.Recipient, len(keys))
+	for i, key := range keys {
+		if key == nil {
+			return nil, shamir.NewValidationError("keys", i, "kms: key cannot be nil")
+		}
+		recipients[i] = recipient{ctx: ctx, key: key}
+	}
+	return shamir.SplitEncrypted(secret, len(keys), threshold, recipients)
+}
+
+// Unwrap decrypts one wrapped share with key, returning the enveloped share.
+// The key's ID must match the one recorded in the share.
+func Unwrap(ctx context.Context, wrapped []byte, key Key) ([]byte, error) {
+	h, err := parseHeader(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if key.ID() != h.keyID {
+		return nil, fmt.Errorf("%w: share is wrapped by %s", ErrNoKey, h.keyID)
+	}
+	share, err := key.Decrypt(ctx, wrapped[h.size:], h.aad())
+	if err != nil {
+		return nil, err
+	}
+	if s, err := shamir.ParseShare(share); err != nil || s.SetID != h.set || s.Index != h.index {
+		clear(share)
+		return nil, fmt.Errorf("%w: unwrapped share does not match its header", ErrInvalidShare)
+	}
+	return share, nil
+}
+
+// Combine unwraps shares with the given keys, matched by ID, until it has as
+// many as the threshold, and reconstructs the secret. Shares whose key is not
+// given, or whose KMS call fails, are skipped, so the secret is recovered as
+// long as a threshold of keys are reachable. If too few shares can be
+// unwrapped, the error matches shamir.ErrInsufficientShares and includes the
+// reason for each skipped share.
+func Combine(ctx context.Context, wrapped [][]byte, keys []Key) ([]byte, error) {
+	byID := make(map[string]Key, len(keys))
+	for _, key := range keys {
+		byID[key.ID()] = key
+	}
+
+	var shares [][]byte
+	defer func() {
+		for _, share := range shares {
+			clear(share)
+		}
+	}()
+	var failures []error
+	threshold := 0
+	for i, w := range wrapped {
+		if threshold > 0 && len(shares) >= threshold {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		id, err := KeyID(w)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("share %d: %w", i, err))
+			continue
+		}
+		key, ok := byID[id]
+		if !ok {
+			failures = append(failures, fmt.Errorf("share %d: %w: %s", i, ErrNoKey, id))
+			continue
+		}
+		share, err := Unwrap(ctx, w, key)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("share %d: %w", i, err))
+			continue
+		}
+		shares = append(shares, share)
+		if threshold == 0 {
+			if s, err := shamir.ParseShare(share); err == nil {
+				threshold = s.Threshold
+			}
+		}
+	}
+
+	if need := max(threshold, 2); len(shares) < need {
+		err := fmt.Errorf("%w: unwrapped %d of %d shares, need %d", shamir.ErrInsufficientShares, len(shares), len(wrapped), need)
+		if len(failures) > 0 {
+			err = fmt.Errorf("%w: %w", err, errors.Join(failures...))
+		}
+		return nil, err
+	}
+	return shamir.CombineWithOptions(shares)
+}
@@ -0,0 +1,295 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// localKey is an in-process AES-GCM key standing in for a KMS key.
+type localKey struct {
+	id   string
+	aead cipher.AEAD
+	down bool // Fail every call, like an unreachable region
+}
+
+func newLocalKey(t *testing.T, id string) *localKey {
+	t.Helper()
+	key := make([]byte, 32)
+	rand.Read(key)
+	block, _ := aes.NewCipher(key)
+	aead, _ := cipher.NewGCM(block)
+	return &localKey{id: id, aead: aead}
+}
+
+func (k *localKey) ID() string { return k.id }
+
+func (k *localKey) Encrypt(_ context.Context, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	rand.Read(nonce)
+	return k.aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func (k *localKey) Decrypt(_ context.Context, ciphertext, aad []byte) ([]byte, error) {
+	if k.down {
+		return nil, errors.New("region unavailable")
+	}
+	n := k.aead.NonceSize()
+	if len(ciphertext) < n {
+		return nil, errors.New("invalid ciphertext")
+	}
+	return k.aead.Open(nil, ciphertext[:n], ciphertext[n:], aad)
+}
+
+func regionKeys(t *testing.T) []Key {
+	keys := make([]Key, 5)
+	for i, region := range []string{"us-east-1", "us-west-2", "eu-west-1", "eu-central-1", "ap-south-1"} {
+		keys[i] = newLocalKey(t, "arn:aws:kms:"+region+":111122223333:key/escrow")
+	}
+	return keys
+}
+
+func TestSplitCombine(t *testing.T) {
+	ctx := context.Background()
+	keys := regionKeys(t)
+	secret := []byte("organisation root key")
+
+	wrapped, err := Split(ctx, secret, 3, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, w := range wrapped {
+		if id, err := KeyID(w); err != nil || id != keys[i].ID() {
+			t.Fatalf("share %d: KeyID %q, %v", i, id, err)
+		}
+		if bytes.Contains(w, secret) {
+			t.Fatalf("share %d contains the secret", i)
+		}
+	}
+
+	for _, available := range [][]Key{keys, keys[2:], {keys[4], keys[0], keys[2]}} {
+		got, err := Combine(ctx, wrapped, available)
+		if err != nil || !bytes.Equal(got, secret) {
+			t.Fatalf("Combine with %d keys: %q, %v", len(available), got, err)
+		}
+	}
+
+	// Two regions lost, a third down: too few shares, and the reasons are reported.
+	keys[3].(*localKey).down = true
+	_, err = Combine(ctx, wrapped, keys[2:])
+	if !errors.Is(err, shamir.ErrInsufficientShares) || !errors.Is(err, ErrNoKey) || !strings.Contains(err.Error(), "region unavailable") {
+		t.Errorf("expected ErrInsufficientShares with the failures, got %v", err)
+	}
+	if got, err := Combine(ctx, wrapped, keys); err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("Combine with one region down: %q, %v", got, err)
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	ctx := context.Background()
+	keys := regionKeys(t)[:3]
+	wrapped, err := Split(ctx, []byte("secret"), 2, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	share, err := Unwrap(ctx, wrapped[1], keys[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := shamir.ParseShare(share); err != nil || s.Index != 2 || s.Threshold != 2 {
+		t.Fatalf("unwrapped share: %+v, %v", s, err)
+	}
+
+	if _, err := Unwrap(ctx, wrapped[1], keys[0]); !errors.Is(err, ErrNoKey) {
+		t.Errorf("wrong key: expected ErrNoKey, got %v", err)
+	}
+
+	// The set ID and index are authenticated by the KMS.
+	relabelled := bytes.Clone(wrapped[1])
+	relabelled[len(wrappedMagic)+1+16] = 7
+	if _, err := Unwrap(ctx, relabelled, keys[1]); err == nil {
+		t.Error("relabelled share unwrapped")
+	}
+
+	badVersion := bytes.Clone(wrapped[0])
+	badVersion[3] = 9
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"truncated": wrapped[0][:headerSize+3],
+		"magic":     append([]byte{0x00, 'S', 'H'}, wrapped[0][3:]...),
+	} {
+		if _, err := KeyID(data); !errors.Is(err, ErrInvalidShare) {
+			t.Errorf("%s: expected ErrInvalidShare, got %v", name, err)
+		}
+	}
+	if _, err := KeyID(badVersion); !errors.Is(err, shamir.ErrUnsupportedVersion) {
+		t.Errorf("version: expected ErrUnsupportedVersion, got %v", err)
+	}
+}
+
+func TestWrappedIsVersionedFormat(t *testing.T) {
+	wrapped, err := Split(context.Background(), []byte("secret"), 2, regionKeys(t)[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := shamir.InspectShare(wrapped[0]); err != nil || info.Format != "KMS-wrapped" {
+		t.Errorf("InspectShare: %+v, %v", info, err)
+	}
+	var merr *shamir.MigrationError
+	if _, err := shamir.Combine(wrapped); !errors.As(err, &merr) || !strings.HasPrefix(merr.Replacement, "kms.Combine") {
+		t.Errorf("Combine: expected a MigrationError, got %v", err)
+	}
+}
+
+// fakeKMS is a fake of a cloud KMS. Its "ciphertext" is the plaintext and
+// additional data in JSON, tagged with the key, which is enough to check what
+// the client sends.
+type fakeKMS struct {
+	calls int
+}
+
+type fakeCiphertext struct {
+	Key       string
+	AAD       string
+	Plaintext []byte
+}
+
+func (f *fakeKMS) seal(key, aad string, plaintext []byte) []byte {
+	data, _ := json.Marshal(fakeCiphertext{key, aad, plaintext})
+	return data
+}
+
+func (f *fakeKMS) open(key, aad string, ciphertext []byte) ([]byte, bool) {
+	var c fakeCiphertext
+	if json.Unmarshal(ciphertext, &c) != nil || c.Key != key || c.AAD != aad {
+		return nil, false
+	}
+	return c.Plaintext, true
+}
+
+// aws serves the AWS KMS JSON API.
+func (f *fakeKMS) aws(w http.ResponseWriter, r *http.Request) {
+	f.calls++
+	if !strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/kms/aws4_request") ||
+		r.Header.Get("Content-Type") != "application/x-amz-json-1.1" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"__type":"IncompleteSignatureException","message":"bad request"}`))
+		return
+	}
+	var req struct {
+		KeyId             string
+		Plaintext         []byte
+		CiphertextBlob    []byte
+		EncryptionContext map[string]string
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	aad := req.EncryptionContext[awsContextKey]
+	switch r.Header.Get("X-Amz-Target") {
+	case "TrentService.Encrypt":
+		json.NewEncoder(w).Encode(map[string]any{"KeyId": req.KeyId, "CiphertextBlob": f.seal(req.KeyId, aad, req.Plaintext)})
+	case "TrentService.Decrypt":
+		plaintext, ok := f.open(req.KeyId, aad, req.CiphertextBlob)
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"InvalidCiphertextException"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"KeyId": req.KeyId, "Plaintext": plaintext})
+	}
+}
+
+// gcp serves the Cloud KMS REST API.
+func (f *fakeKMS) gcp(w http.ResponseWriter, r *http.Request) {
+	f.calls++
+	if r.Header.Get("Authorization") != "Bearer ya29.token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"status":"UNAUTHENTICATED","message":"invalid token"}}`))
+		return
+	}
+	name, method, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v1/"), ":")
+	var req struct {
+		Plaintext                   []byte `json:"plaintext"`
+		Ciphertext                  []byte `json:"ciphertext"`
+		AdditionalAuthenticatedData []byte `json:"additionalAuthenticatedData"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	aad := string(req.AdditionalAuthenticatedData)
+	switch method {
+	case "encrypt":
+		json.NewEncoder(w).Encode(map[string]any{"name": name + "/cryptoKeyVersions/1", "ciphertext": f.seal(name, aad, req.Plaintext)})
+	case "decrypt":
+		plaintext, ok := f.open(name, aad, req.Ciphertext)
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"status":"INVALID_ARGUMENT","message":"Decryption failed"}}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"plaintext": plaintext})
+	}
+}
+
+func TestCloudKeys(t *testing.T) {
+	fake := &fakeKMS{}
+	awsSrv := httptest.NewServer(http.HandlerFunc(fake.aws))
+	defer awsSrv.Close()
+	gcpSrv := httptest.NewServer(http.HandlerFunc(fake.gcp))
+	defer gcpSrv.Close()
+
+	newAWS := func(keyID string) *AWSKey {
+		k, err := NewAWSKey(AWSConfig{Region: "eu-west-1", KeyID: keyID, AccessKeyID: "AKID", SecretAccessKey: "secret", Endpoint: awsSrv.URL})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	token := func(context.Context) (string, error) { return "ya29.token", nil }
+	gcpKey, err := NewGCPKey(GCPConfig{Name: "projects/p/locations/europe-west1/keyRings/escrow/cryptoKeys/share", Token: token, Endpoint: gcpSrv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []Key{newAWS("arn:aws:kms:eu-west-1:1:key/a"), newAWS("arn:aws:kms:eu-west-1:1:key/b"), gcpKey}
+	ctx := context.Background()
+	secret := []byte("cross-cloud escrow")
+	wrapped, err := Split(ctx, secret, 2, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pair := range [][]Key{keys[:2], keys[1:], {keys[0], keys[2]}} {
+		if got, err := Combine(ctx, wrapped, pair); err != nil || !bytes.Equal(got, secret) {
+			t.Fatalf("Combine: %q, %v", got, err)
+		}
+	}
+
+	// Combine stops calling the KMS once it has a threshold of shares.
+	fake.calls = 0
+	if _, err := Combine(ctx, wrapped, keys); err != nil || fake.calls != 2 {
+		t.Errorf("Combine made %d KMS calls, want 2 (%v)", fake.calls, err)
+	}
+
+	badToken, _ := NewGCPKey(GCPConfig{Name: gcpKey.ID(), Token: func(context.Context) (string, error) { return "expired", nil }, Endpoint: gcpSrv.URL})
+	if _, err := Unwrap(ctx, wrapped[2], badToken); err == nil || !strings.Contains(err.Error(), "UNAUTHENTICATED") {
+		t.Errorf("GCP with a bad token: %v", err)
+	}
+	if _, err := Unwrap(ctx, append(bytes.Clone(wrapped[0]), 'x'), keys[0]); err == nil || !strings.Contains(err.Error(), "InvalidCiphertextException") {
+		t.Errorf("AWS with a corrupt ciphertext: %v", err)
+	}
+
+	if _, err := NewAWSKey(AWSConfig{Region: "eu-west-1"}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("NewAWSKey: expected ErrInvalidConfig, got %v", err)
+	}
+	if _, err := NewGCPKey(GCPConfig{Name: "share", Token: token}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("NewGCPKey: expected ErrInvalidConfig, got %v", err)
+	}
+}
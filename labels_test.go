@@ -0,0 +1,142 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEnvelopeLabelAndCreationTime(t *testing.T) {
+	created := time.Unix(1700000000, 0).UTC()
+	encoded, err := (&Share{
+		Threshold: 2,
+		Index:     1,
+		Label:     "HSM-2",
+		CreatedAt: created.Add(750 * time.Millisecond),
+		Payload:   []byte{9},
+	}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := ParseShare(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Label != "HSM-2" {
+		t.Errorf("Label = %q, want HSM-2", decoded.Label)
+	}
+	if !decoded.CreatedAt.Equal(created) || decoded.CreatedAt.Location() != time.UTC {
+		t.Errorf("CreatedAt = %v, want %v truncated to the second in UTC", decoded.CreatedAt, created)
+	}
+
+	// A creation time of the wrong length is rejected.
+	bad := Share{Threshold: 2, Index: 1, Payload: []byte{9}, extra: []metaRecord{{tag: tagCreatedAt, value: []byte{1, 2}}}}
+	data, err := bad.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseShare(data); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Fatalf("expected ErrInvalidEnvelope, got %v", err)
+	}
+}
+
+func TestWithLabels(t *testing.T) {
+	secret := []byte("database root password")
+	labels := []string{"alice@ops", "bob@ops", ""}
+
+	before := time.Now().Add(-time.Second)
+	shares, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithLabels(labels...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, share := range shares {
+		s, err := ParseShare(share)
+		if err != nil {
+			t.Fatalf("share %d: %v", i, err)
+		}
+		if s.Label != labels[i] {
+			t.Errorf("share %d: Label = %q, want %q", i, s.Label, labels[i])
+		}
+		if s.CreatedAt.Before(before) || s.CreatedAt.After(time.Now()) {
+			t.Errorf("share %d: CreatedAt = %v, not the time of the split", i, s.CreatedAt)
+		}
+	}
+
+	reconstructed, err := CombineWithOptions(shares[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+
+	t.Run("label count must match parts", func(t *testing.T) {
+		_, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithLabels("alice", "bob"))
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Field != "labels" {
+			t.Fatalf("expected labels ValidationError, got %v", err)
+		}
+	})
+}
+
+func TestLabelShare(t *testing.T) {
+	secret := []byte("signing key")
+	shares, err := SplitWithOptions(secret, WithParts(2), WithThreshold(2), WithLabels("alice", "bob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, _ := ParseShare(shares[0])
+
+	relabelled, err := LabelShare(shares[0], "carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ParseShare(relabelled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Label != "carol" || !s.CreatedAt.Equal(original.CreatedAt) || !bytes.Equal(s.Payload, original.Payload) {
+		t.Fatalf("relabelled share mismatch: %+v", s)
+	}
+
+	reconstructed, err := CombineWithOptions([][]byte{relabelled, shares[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+
+	raw, _ := Split(secret, 2, 2)
+	if _, err := LabelShare(raw[0], "carol"); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Fatalf("expected ErrInvalidEnvelope for a raw share, got %v", err)
+	}
+}
+
+func TestEscrowSharesCarryCustodianLabels(t *testing.T) {
+	sharing, err := splitEscrow([]byte("root CA private key"), EscrowPolicy{
+		Name:       "ops",
+		Threshold:  2,
+		Custodians: []string{"alice", "bob", "carol"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, share := range sharing.Shares {
+		s, err := ParseShare(share)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry := sharing.Manifest.Custodians[i]
+		if s.Label != entry.Custodian {
+			t.Errorf("share %d: Label = %q, want custodian %q", i, s.Label, entry.Custodian)
+		}
+		if !s.CreatedAt.Equal(sharing.Manifest.CreatedAt) {
+			t.Errorf("share %d: CreatedAt = %v, manifest says %v", i, s.CreatedAt, sharing.Manifest.CreatedAt)
+		}
+	}
+}
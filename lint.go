@@ -0,0 +1,130 @@
+package shamir
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lint finding codes reported by LintPolicy.
+const (
+	LintInvalidParams      = "invalid-params"      // Split would reject the parameters
+	LintNoRedundancy       = "no-redundancy"       // threshold == parts: losing any share loses the secret
+	LintLowThreshold       = "low-threshold"       // Threshold 2 spread over many shares
+	LintCustodianCount     = "custodian-count"     // Custodian list does not match parts
+	LintExpiredCustodians  = "expired-custodians"  // Custodians have expired; an error once too few remain
+	LintExpiringCustodians = "expiring-custodians" // Expiries within the window leave too few custodians
+)
+
+// lowThresholdParts is the share count from which a threshold of 2 is flagged.
+const lowThresholdParts = 5
+
+// LintSeverity ranks lint findings.
+type LintSeverity int
+
+const (
+	LintWarning LintSeverity = iota // Risky but workable
+	LintError                       // The policy cannot work as intended
+)
+
+// String returns "warning" or "error".
+func (s LintSeverity) String() string {
+	if s == LintError {
+		return "error"
+	}
+	return "warning"
+}
+
+// LintFinding is one problem reported by LintPolicy.
+type LintFinding struct {
+	Code     string // One of the Lint* codes
+	Severity LintSeverity
+	Message  string
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Severity, f.Message, f.Code)
+}
+
+// CustodianStatus describes a custodian for expiry checks.
+type CustodianStatus struct {
+	Name    string
+	Expires time.Time // When the custodian's appointment or credentials lapse; zero if never
+}
+
+// LintOptions supplies optional context to LintPolicy.
+type LintOptions struct {
+	Custodians   []CustodianStatus // Custodians holding the shares, one per share
+	Now          time.Time         // Reference time for expiry checks; zero means time.Now
+	ExpiryWindow time.Duration     // Also flag custodians expiring within this window
+}
+
+// LintPolicy reviews a threshold policy before it is provisioned and returns
+// findings for risky configurations, most severe first. An empty result means
+// nothing was flagged. It never fails: invalid parameters are reported as a
+// LintInvalidParams finding so pipelines can surface every problem at once.
+func LintPolicy(parts, threshold int, opts LintOptions) []LintFinding {
+	var findings []LintFinding
+	add := func(code string, severity LintSeverity, format string, args ...any) {
+		findings = append(findings, LintFinding{Code: code, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if err := validateSplitParams([]byte{0}, parts, threshold); err != nil {
+		add(LintInvalidParams, LintError, "%v", err)
+		return findings
+	}
+
+	if threshold == parts {
+		add(LintNoRedundancy, LintWarning,
+			"threshold equals parts (%d): losing any single share makes the secret unrecoverable", parts)
+	}
+	if threshold == 2 && parts >= lowThresholdParts {
+		add(LintLowThreshold, LintWarning,
+			"threshold 2 of %d: any two of %d custodians can reconstruct the secret", parts, parts)
+	}
+
+	if len(opts.Custodians) > 0 {
+		if len(opts.Custodians) != parts {
+			add(LintCustodianCount, LintWarning, "%d custodians listed for %d shares", len(opts.Custodians), parts)
+		}
+
+		now := opts.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		expired, expiring := 0, 0
+		for _, c := range opts.Custodians {
+			switch {
+			case c.Expires.IsZero():
+			case !c.Expires.After(now):
+				expired++
+			case opts.ExpiryWindow > 0 && c.Expires.Before(now.Add(opts.ExpiryWindow)):
+				expiring++
+			}
+		}
+
+		active := len(opts.Custodians) - expired
+		switch {
+		case active < threshold:
+			add(LintExpiredCustodians, LintError,
+				"%d of %d custodians have expired, leaving %d of the %d needed", expired, len(opts.Custodians), active, threshold)
+		case expired > 0:
+			add(LintExpiredCustodians, LintWarning,
+				"%d of %d custodians have expired; reshare to restore redundancy", expired, len(opts.Custodians))
+		}
+		if active >= threshold && active-expiring < threshold {
+			add(LintExpiringCustodians, LintWarning,
+				"%d custodians expire within %s, leaving %d of the %d needed", expiring, opts.ExpiryWindow, active-expiring, threshold)
+		}
+	}
+
+	// Most severe first, otherwise in the order checked
+	sorted := make([]LintFinding, 0, len(findings))
+	for _, severity := range []LintSeverity{LintError, LintWarning} {
+		for _, f := range findings {
+			if f.Severity == severity {
+				sorted = append(sorted, f)
+			}
+		}
+	}
+	return sorted
+}
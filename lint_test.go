@@ -0,0 +1,98 @@
+package shamir
+
+import (
+	"testing"
+	"time"
+)
+
+func lintCodes(findings []LintFinding) []string {
+	codes := make([]string, len(findings))
+	for i, f := range findings {
+		codes[i] = f.Code
+	}
+	return codes
+}
+
+func TestLintPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		parts     int
+		threshold int
+		want      []string
+	}{
+		{"sound", 5, 3, nil},
+		{"no redundancy", 3, 3, []string{LintNoRedundancy}},
+		{"low threshold", 7, 2, []string{LintLowThreshold}},
+		{"small 2 of 3", 3, 2, nil},
+		{"invalid threshold", 3, 4, []string{LintInvalidParams}},
+		{"invalid parts", 300, 3, []string{LintInvalidParams}},
+		{"two of two", 2, 2, []string{LintNoRedundancy}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lintCodes(LintPolicy(tt.parts, tt.threshold, LintOptions{}))
+			if len(got) != len(tt.want) {
+				t.Fatalf("findings = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("findings = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLintPolicyCustodians(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	past, soon, later := now.Add(-time.Hour), now.Add(24*time.Hour), now.Add(365*24*time.Hour)
+
+	custodians := func(expiries ...time.Time) []CustodianStatus {
+		cs := make([]CustodianStatus, len(expiries))
+		for i, e := range expiries {
+			cs[i] = CustodianStatus{Name: string(rune('a' + i)), Expires: e}
+		}
+		return cs
+	}
+
+	t.Run("unrecoverable", func(t *testing.T) {
+		findings := LintPolicy(4, 3, LintOptions{Custodians: custodians(past, past, later, time.Time{}), Now: now})
+		if len(findings) != 1 || findings[0].Code != LintExpiredCustodians || findings[0].Severity != LintError {
+			t.Errorf("unexpected findings: %v", findings)
+		}
+	})
+
+	t.Run("some expired", func(t *testing.T) {
+		findings := LintPolicy(4, 2, LintOptions{Custodians: custodians(past, later, later, later), Now: now})
+		if len(findings) != 1 || findings[0].Code != LintExpiredCustodians || findings[0].Severity != LintWarning {
+			t.Errorf("unexpected findings: %v", findings)
+		}
+	})
+
+	t.Run("expiring within window", func(t *testing.T) {
+		opts := LintOptions{Custodians: custodians(soon, soon, later, later), Now: now, ExpiryWindow: 30 * 24 * time.Hour}
+		findings := LintPolicy(4, 3, opts)
+		if len(findings) != 1 || findings[0].Code != LintExpiringCustodians {
+			t.Errorf("unexpected findings: %v", findings)
+		}
+
+		opts.ExpiryWindow = 0
+		if findings := LintPolicy(4, 3, opts); len(findings) != 0 {
+			t.Errorf("no window: unexpected findings: %v", findings)
+		}
+	})
+
+	t.Run("count mismatch and ordering", func(t *testing.T) {
+		findings := LintPolicy(3, 3, LintOptions{Custodians: custodians(past, later), Now: now})
+		got := lintCodes(findings)
+		want := []string{LintExpiredCustodians, LintNoRedundancy, LintCustodianCount}
+		if len(got) != len(want) {
+			t.Fatalf("findings = %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("findings = %v, want %v", got, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package shamir
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// SetID identifies a single split; every share of the split carries the same SetID.
+type SetID [16]byte
+
+// newSetID returns a random SetID.
+func newSetID() (SetID, error) {
+	var id SetID
+	if _, err := rand.Read(id[:]); err != nil {
+		return id, fmt.Errorf("shamir: failed to generate set ID: %w", err)
+	}
+	return id, nil
+}
+
+// IsZero reports whether the ID is unset.
+func (id SetID) IsZero() bool {
+	return id == SetID{}
+}
+
+// String returns the ID in hexadecimal.
+func (id SetID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// MarshalText encodes the ID in hexadecimal.
+func (id SetID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText decodes a hexadecimal ID.
+func (id *SetID) UnmarshalText(text []byte) error {
+	if hex.DecodedLen(len(text)) != len(id) {
+		return fmt.Errorf("shamir: set ID must be %d hex characters", 2*len(id))
+	}
+	_, err := hex.Decode(id[:], text)
+	return err
+}
+
+// ShareFingerprint is the SHA-256 of an encoded share. It identifies a share
+// without revealing anything about the secret.
+type ShareFingerprint [32]byte
+
+// String returns the fingerprint in hexadecimal.
+func (f ShareFingerprint) String() string {
+	return hex.EncodeToString(f[:])
+}
+
+// MarshalText encodes the fingerprint in hexadecimal.
+func (f ShareFingerprint) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText decodes a hexadecimal fingerprint.
+func (f *ShareFingerprint) UnmarshalText(text []byte) error {
+	if hex.DecodedLen(len(text)) != len(f) {
+		return fmt.Errorf("shamir: fingerprint must be %d hex characters", 2*len(f))
+	}
+	_, err := hex.Decode(f[:], text)
+	return err
+}
+
+// DealerSignature is an archive dealer's Ed25519 signature over a share's
+// manifest entry, see SplitArchival.
+type DealerSignature [ed25519.SignatureSize]byte
+
+// String returns the signature in hexadecimal.
+func (s DealerSignature) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// MarshalText encodes the signature in hexadecimal.
+func (s DealerSignature) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a hexadecimal signature.
+func (s *DealerSignature) UnmarshalText(text []byte) error {
+	if hex.DecodedLen(len(text)) != len(s) {
+		return fmt.Errorf("shamir: signature must be %d hex characters", 2*len(s))
+	}
+	_, err := hex.Decode(s[:], text)
+	return err
+}
+
+// FingerprintShare returns the fingerprint of an encoded share.
+func FingerprintShare(share []byte) ShareFingerprint {
+	return sha256.Sum256(share)
+}
+
+// Manifest is the dealer's record of a split: who holds which share and how the
+// split relates to others. It never contains secret material and can be
+// serialized with encoding/json.
+type Manifest struct {
+	SetID      SetID             `json:"set_id"`
+	Name       string            `json:"name,omitempty"`
+	Threshold  int               `json:"threshold"`
+	Parts      int               `json:"parts"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Custodians []ManifestEntry   `json:"custodians"`
+	Linked     []SetID           `json:"linked,omitempty"` // Other splits of the same secret
+	Notes      map[string]string `json:"notes,omitempty"`
+
+	DealerKey    ed25519.PublicKey `json:"dealer_key,omitempty"`    // Archive dealer's key, see SplitArchival
+	ReverifyDays int               `json:"reverify_days,omitempty"` // Re-verification interval, see VerificationSchedule
+}
+
+// ManifestEntry records the share assigned to one custodian.
+type ManifestEntry struct {
+	Custodian   string           `json:"custodian"`
+	Index       byte             `json:"index"`
+	Fingerprint ShareFingerprint `json:"fingerprint"`
+	Medium      string           `json:"medium,omitempty"` // Storage medium, see SplitPlanned
+	Decoy       bool             `json:"decoy,omitempty"`  // Dealer-private flag, see SplitWithDecoys
+
+	Signature    DealerSignature `json:"signature,omitzero"`     // Dealer signature, see SplitArchival
+	LastVerified time.Time       `json:"last_verified,omitzero"` // Last successful VerifyArchivedShare
+}
+
+// Custodian returns the custodian holding the given encoded share, or an error
+// if the share is not listed in the manifest.
+func (m *Manifest) Custodian(share []byte) (string, error) {
+	fp := FingerprintShare(share)
+	for _, entry := range m.Custodians {
+		if entry.Fingerprint == fp {
+			return entry.Custodian, nil
+		}
+	}
+	return "", ErrUnknownShare
+}
+
+// RemoveDecoys returns the shares that the manifest does not flag as decoys,
+// preserving order. Shares unknown to the manifest are kept.
+func (m *Manifest) RemoveDecoys(shares [][]byte) [][]byte {
+	decoys := make(map[ShareFingerprint]bool)
+	for _, entry := range m.Custodians {
+		if entry.Decoy {
+			decoys[entry.Fingerprint] = true
+		}
+	}
+
+	kept := make([][]byte, 0, len(shares))
+	for _, share := range shares {
+		if !decoys[FingerprintShare(share)] {
+			kept = append(kept, share)
+		}
+	}
+	return kept
+}
+
+// newManifest builds a manifest for enveloped shares assigned to custodians in order.
+func newManifest(setID SetID, name string, threshold int, custodians []string, shares [][]byte) *Manifest {
+	m := &Manifest{
+		SetID:      setID,
+		Name:       name,
+		Threshold:  threshold,
+		Parts:      len(shares),
+		CreatedAt:  time.Now().UTC(),
+		Custodians: make([]ManifestEntry, len(shares)),
+	}
+	for i, share := range shares {
+		// The shares come straight from a split, so they are well formed.
+		index, _ := ShareIndex(share)
+		m.Custodians[i] = ManifestEntry{
+			Custodian:   custodians[i],
+			Index:       index,
+			Fingerprint: FingerprintShare(share),
+		}
+	}
+	return m
+}
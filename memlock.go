@@ -0,0 +1,96 @@
+package shamir
+
+import (
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Locked memory for secret buffers.
+//
+// The Go heap may be swapped to disk, where polynomial coefficients and
+// reconstructed secrets could outlive the process. With memory locking
+// enabled, those buffers are instead allocated outside the heap in pages
+// locked into RAM with mlock, and are zeroized, unlocked and unmapped when
+// released. Locking is opt-in because locked memory is a scarce resource
+// (RLIMIT_MEMLOCK) and a secret returned in it must be released explicitly.
+// On platforms without mlock, and whenever a lock cannot be obtained, buffers
+// come from the heap as usual.
+
+var memoryLocking atomic.Bool
+
+// lockedRegions maps the first byte of every live locked buffer to its whole
+// mapping, which is page-aligned and may be longer than the buffer.
+var lockedRegions struct {
+	sync.Mutex
+	m map[*byte][]byte
+}
+
+// SetMemoryLocking controls whether polynomial coefficients and secrets
+// reconstructed by Combine and its variants are kept in memory locked against
+// swapping. While enabled, release every secret returned by this package with
+// ReleaseSecret; it stays locked, and counts against RLIMIT_MEMLOCK, until
+// then. Locking has no effect where MemoryLockingSupported reports false.
+func SetMemoryLocking(enabled bool) {
+	memoryLocking.Store(enabled)
+}
+
+// MemoryLockingSupported reports whether this platform can lock memory.
+func MemoryLockingSupported() bool {
+	return memlockSupported
+}
+
+// ReleaseSecret zeroizes a secret returned by this package and, if it is held
+// in locked memory, unlocks and unmaps it. The slice must not be used
+// afterwards. Secrets on the Go heap are only zeroized, so it is safe to call
+// ReleaseSecret whether or not memory locking was enabled.
+func ReleaseSecret(secret []byte) {
+	freeSecret(secret)
+}
+
+// allocSecret returns a zeroed n-byte buffer for secret material, in locked
+// memory when memory locking is enabled and available. Release it with
+// freeSecret.
+func allocSecret(n int) []byte {
+	if n == 0 || !memoryLocking.Load() {
+		return make([]byte, n)
+	}
+	region, err := lockedAlloc(n)
+	if err != nil {
+		// Over RLIMIT_MEMLOCK or unsupported: fall back to the heap.
+		return make([]byte, n)
+	}
+
+	lockedRegions.Lock()
+	defer lockedRegions.Unlock()
+	if lockedRegions.m == nil {
+		lockedRegions.m = make(map[*byte][]byte)
+	}
+	lockedRegions.m[unsafe.SliceData(region)] = region
+	return region[:n:n]
+}
+
+// freeSecret zeroizes b and, if allocSecret placed it in locked memory,
+// unlocks and unmaps it.
+func freeSecret(b []byte) {
+	secureZeroBytes(b)
+	if cap(b) == 0 {
+		return
+	}
+
+	lockedRegions.Lock()
+	region, ok := lockedRegions.m[unsafe.SliceData(b)]
+	delete(lockedRegions.m, unsafe.SliceData(b))
+	lockedRegions.Unlock()
+	if ok {
+		lockedFree(region)
+	}
+}
+
+// isLocked reports whether b is a live buffer in locked memory.
+func isLocked(b []byte) bool {
+	lockedRegions.Lock()
+	defer lockedRegions.Unlock()
+	_, ok := lockedRegions.m[unsafe.SliceData(b)]
+	return ok
+}
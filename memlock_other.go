@@ -0,0 +1,16 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package shamir
+
+import "errors"
+
+// memlockSupported reports whether lockedAlloc can lock memory here.
+const memlockSupported = false
+
+// lockedAlloc always fails on this platform, so secrets stay on the heap.
+func lockedAlloc(n int) ([]byte, error) {
+	return nil, errors.New("shamir: memory locking is not supported on this platform")
+}
+
+// lockedFree is never reached on this platform.
+func lockedFree(region []byte) {}
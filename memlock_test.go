@@ -0,0 +1,100 @@
+package shamir
+
+import (
+	"bytes"
+	"testing"
+)
+
+// enableMemoryLocking turns memory locking on for the duration of the test
+// and skips it if locked pages cannot be obtained here.
+func enableMemoryLocking(t *testing.T) {
+	t.Helper()
+	if !MemoryLockingSupported() {
+		t.Skip("memory locking is not supported on this platform")
+	}
+	region, err := lockedAlloc(1)
+	if err != nil {
+		t.Skipf("cannot lock memory: %v", err)
+	}
+	lockedFree(region)
+
+	SetMemoryLocking(true)
+	t.Cleanup(func() { SetMemoryLocking(false) })
+}
+
+// lockedCount returns the number of live locked buffers.
+func lockedCount() int {
+	lockedRegions.Lock()
+	defer lockedRegions.Unlock()
+	return len(lockedRegions.m)
+}
+
+func TestMemoryLockingRoundTrip(t *testing.T) {
+	enableMemoryLocking(t)
+	before := lockedCount()
+
+	for _, size := range []int{1, 32, 5000} {
+		secret := bytes.Repeat([]byte{0xa5}, size)
+		shares, err := Split(secret, 5, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := lockedCount(); n != before {
+			t.Fatalf("size %d: %d coefficient buffers still locked after Split", size, n-before)
+		}
+
+		got, err := Combine(shares[:3])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Fatalf("size %d: reconstructed secret mismatch", size)
+		}
+		if !isLocked(got) {
+			t.Fatalf("size %d: reconstructed secret is not in locked memory", size)
+		}
+
+		ReleaseSecret(got)
+		if isLocked(got) || lockedCount() != before {
+			t.Fatalf("size %d: ReleaseSecret did not unlock the secret", size)
+		}
+	}
+}
+
+func TestMemoryLockingInternalSecrets(t *testing.T) {
+	enableMemoryLocking(t)
+	before := lockedCount()
+
+	key := bytes.Repeat([]byte{7}, 32)
+	keyShares, err := SplitKey(key, AlgAES256GCM, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, sealShares, err := SealSplit([]byte("sealed payload"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Secrets reconstructed only to be checked or used internally must not
+	// stay locked.
+	if _, err := SealCombine(ciphertext, sealShares[:2]); err != nil {
+		t.Fatal(err)
+	}
+	got, err := CombineKey(keyShares[:2], AlgAES256GCM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ReleaseSecret(got)
+	if n := lockedCount(); n != before {
+		t.Fatalf("%d internal buffers left locked", n-before)
+	}
+}
+
+func TestReleaseSecretHeap(t *testing.T) {
+	secret := []byte("not locked")
+	ReleaseSecret(secret)
+	if !bytes.Equal(secret, make([]byte, len(secret))) {
+		t.Fatal("ReleaseSecret did not zeroize a heap secret")
+	}
+	ReleaseSecret(nil)
+}
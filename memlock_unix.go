@@ -0,0 +1,43 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package shamir
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// memlockSupported reports whether lockedAlloc can lock memory here.
+const memlockSupported = true
+
+// lockedAlloc maps whole anonymous pages for n bytes and locks them into RAM.
+func lockedAlloc(n int) ([]byte, error) {
+	page := syscall.Getpagesize()
+	size := (n + page - 1) / page * page
+	region, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+	if err := memlockSyscall(syscall.SYS_MLOCK, region); err != nil {
+		syscall.Munmap(region)
+		return nil, err
+	}
+	return region, nil
+}
+
+// lockedFree unlocks and unmaps a region from lockedAlloc. The caller must
+// already have zeroized it.
+func lockedFree(region []byte) {
+	memlockSyscall(syscall.SYS_MUNLOCK, region)
+	syscall.Munmap(region)
+}
+
+// memlockSyscall calls mlock or munlock on region. The syscall package only
+// wraps them on some platforms.
+func memlockSyscall(trap uintptr, region []byte) error {
+	_, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(unsafe.SliceData(region))), uintptr(len(region)), 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+// Migration support for the original raw-share API.
+//
+// Split, Combine and the integrity functions predate the share envelope and
+// the text encodings. Handed shares in a newer format they used to fail with
+// generic length or x-coordinate errors. They now recognise those formats:
+// formats they cannot process yield a *MigrationError naming the function to
+// use instead, and enveloped shares are either combined on the caller's behalf
+// with a warning (the default) or refused in strict mode.
+//
+// Raw layouts that differ only in where the bytes sit are harder to spot,
+// because they would otherwise be combined into garbage without an error.
+// checkRawLayout recognises the two common mix-ups, checksummed shares given
+// to Combine and Vault shares with the x-coordinate at the end, and
+// checkIntegrityLayout the reverse of the first.
+
+var (
+	strictMigration  atomic.Bool
+	migrationHandler atomic.Pointer[func(*MigrationError)]
+)
+
+// SetStrictMigration controls how the raw-share functions treat enveloped
+// shares. By default they process them as CombineWithOptions would and report
+// a warning to the handler set with SetMigrationHandler; in strict mode they
+// return a *MigrationError instead, which helps flush out outdated call sites.
+func SetStrictMigration(strict bool) {
+	strictMigration.Store(strict)
+}
+
+// SetMigrationHandler registers fn to receive a warning whenever a raw-share
+// function processes shares it only supports for compatibility. A nil fn
+// discards the warnings, which is the default. fn may be called concurrently.
+func SetMigrationHandler(fn func(*MigrationError)) {
+	if fn == nil {
+		migrationHandler.Store(nil)
+		return
+	}
+	migrationHandler.Store(&fn)
+}
+
+// shareFormat names the format of a share that is not a raw share, or
+// returns "" if it looks like one.
+func shareFormat(share []byte) string {
+	switch {
+	case IsEnvelope(share):
+		return "enveloped"
+	case isVersioned(share):
+		return wireFormat(share)
+	case bytes.HasPrefix(bytes.TrimSpace(share), []byte("-----BEGIN "+SharePEMType+"-----")):
+		return "PEM"
+	case len(share) > 0 && (share[0] == '{' || share[0] == '[') && bytes.Contains(share, []byte(`"payload"`)) && json.Valid(share):
+		return "JSON"
+	}
+	return ""
+}
+
+// migrationReplacements names what to use instead of fn for each format
+// that cannot be processed in place.
+var migrationReplacements = map[string]string{
+	"framed stream":        "CombineStream",
+	"sealed ciphertext":    "SealCombine",
+	"X25519-encrypted":     "X25519Identity.DecryptShare to decrypt it first",
+	"passphrase-protected": "CombineWithPassphrases, or UnlockShare to decrypt it first",
+	"threshold ciphertext": "threshold.PartialDecrypt and threshold.Combine",
+	"KMS-wrapped":          "kms.Combine, or kms.Unwrap to unwrap it first",
+	"token-wrapped":        "token.Combine, or token.Unwrap to unwrap it first",
+	"multi-secret":         "CombineMulti",
+	"prepared":             "CombinePrepared, or UnprepareShare to convert it back",
+	"PEM":                  "DecodeSharePEM to decode it first",
+	"JSON":                 "DecodeSharesJSON (or json.Unmarshal into a Share) to decode it first",
+	newerFormat:            "a newer release of this package",
+}
+
+// checkLegacyFormat inspects shares passed to the raw-share function fn. It
+// reports whether the shares are enveloped and may be processed as such, or
+// returns a *MigrationError if they are in a format fn cannot handle.
+func checkLegacyFormat(fn string, parts [][]byte, replacement string) (enveloped bool, err error) {
+	for i, part := range parts {
+		format := shareFormat(part)
+		if format == "" {
+			continue
+		}
+		merr := &MigrationError{Func: fn, Share: i, Format: format, Replacement: replacement}
+		if format != "enveloped" {
+			merr.Replacement = migrationReplacements[format]
+			return false, merr
+		}
+		if i != 0 || strictMigration.Load() {
+			// Mixed raw and enveloped shares cannot be combined either way.
+			return false, merr
+		}
+		if h := migrationHandler.Load(); h != nil {
+			(*h)(merr)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// checkRawLayout looks for signs that shares passed to Combine are raw shares
+// of another layout. Every share ending in a valid integrity checksum means
+// they came from SplitWithIntegrity; repeated or zero x-coordinates with
+// distinct non-zero last bytes mean Vault's [y...][x] layout.
+func checkRawLayout(fn string, parts [][]byte) error {
+	if len(parts) < 2 {
+		return nil
+	}
+
+	checksummed := true
+	for _, part := range parts {
+		if !hasIntegrityTrailer(part) {
+			checksummed = false
+			break
+		}
+	}
+	if checksummed {
+		return &MigrationError{Func: fn, Share: 0, Format: "SplitWithIntegrity",
+			Replacement: "CombineWithIntegrity or CombineWithOptions with WithIntegrity(true)"}
+	}
+
+	if i, ok := invalidFirstX(parts); ok && distinctLastX(parts) {
+		return &MigrationError{Func: fn, Share: i, Format: "HashiCorp Vault",
+			Replacement: "CombineVault"}
+	}
+	return nil
+}
+
+// checkIntegrityLayout reports shares passed to CombineWithIntegrity none of
+// which carries a valid checksum, which most likely means they came from plain
+// Split. Since every share being corrupted looks the same, the error matches
+// ErrIntegrityCheckFailed as well as ErrFormatMismatch.
+func checkIntegrityLayout(fn string, parts [][]byte, replacement string) error {
+	if len(parts) < 2 {
+		return nil
+	}
+	for _, part := range parts {
+		if len(part) < 6 || hasIntegrityTrailer(part) {
+			return nil
+		}
+	}
+	merr := &MigrationError{Func: fn, Share: 0, Format: "plain Split", Replacement: replacement}
+	return fmt.Errorf("%w on every share, suggesting they carry no checksum: %w", ErrIntegrityCheckFailed, merr)
+}
+
+// hasIntegrityTrailer reports whether share ends in the CRC32 that
+// addIntegrityCheck appends.
+func hasIntegrityTrailer(share []byte) bool {
+	if len(share) < 6 {
+		return false
+	}
+	n := len(share) - 4
+	return calculateCRC32(share[1:n]) == binary.LittleEndian.Uint32(share[n:])
+}
+
+// invalidFirstX returns the index of the first share whose leading byte is
+// zero or repeats an earlier one, if any.
+func invalidFirstX(parts [][]byte) (int, bool) {
+	var seen [256]bool
+	for i, part := range parts {
+		if len(part) == 0 {
+			return 0, false
+		}
+		if part[0] == 0 || seen[part[0]] {
+			return i, true
+		}
+		seen[part[0]] = true
+	}
+	return 0, false
+}
+
+// distinctLastX reports whether the shares' trailing bytes could be the
+// distinct non-zero x-coordinates of Vault shares of equal length.
+func distinctLastX(parts [][]byte) bool {
+	var seen [256]bool
+	for _, part := range parts {
+		if len(part) < 2 || len(part) != len(parts[0]) {
+			return false
+		}
+		x := part[len(part)-1]
+		if x == 0 || seen[x] {
+			return false
+		}
+		seen[x] = true
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLegacyFunctionsWithEnvelopes(t *testing.T) {
+	secret := []byte("migrated secret")
+	shares, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithPurpose("backup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings []*MigrationError
+	SetMigrationHandler(func(e *MigrationError) { warnings = append(warnings, e) })
+	t.Cleanup(func() {
+		SetMigrationHandler(nil)
+		SetStrictMigration(false)
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		for name, combine := range map[string]func([][]byte) ([]byte, error){
+			"Combine":              Combine,
+			"CombineWithIntegrity": CombineWithIntegrity,
+		} {
+			warnings = nil
+			got, err := combine(shares[:2])
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !bytes.Equal(got, secret) {
+				t.Fatalf("%s: reconstructed secret mismatch", name)
+			}
+			if len(warnings) != 1 || warnings[0].Func != name || warnings[0].Format != "enveloped" {
+				t.Fatalf("%s: unexpected warnings %v", name, warnings)
+			}
+		}
+
+		if err := VerifyIntegrity(shares[0]); err != nil {
+			t.Fatalf("VerifyIntegrity: %v", err)
+		}
+		corrupted := append([]byte(nil), shares[0]...)
+		corrupted[len(corrupted)-5] ^= 1
+		if err := VerifyIntegrity(corrupted); err == nil {
+			t.Fatal("VerifyIntegrity accepted a corrupted envelope")
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		SetStrictMigration(true)
+		defer SetStrictMigration(false)
+
+		_, err := Combine(shares[:2])
+		var merr *MigrationError
+		if !errors.As(err, &merr) || !errors.Is(err, ErrFormatMismatch) {
+			t.Fatalf("expected a MigrationError, got %v", err)
+		}
+		if merr.Func != "Combine" || merr.Share != 0 || merr.Replacement != "CombineWithOptions" {
+			t.Fatalf("unexpected MigrationError %+v", merr)
+		}
+		if !strings.Contains(err.Error(), "use CombineWithOptions") {
+			t.Fatalf("error does not name the replacement: %v", err)
+		}
+	})
+
+	t.Run("mixed formats", func(t *testing.T) {
+		raw, _ := Split(secret, 3, 2)
+		_, err := Combine([][]byte{raw[0], shares[1]})
+		var merr *MigrationError
+		if !errors.As(err, &merr) || merr.Share != 1 {
+			t.Fatalf("expected a MigrationError for share 1, got %v", err)
+		}
+	})
+}
+
+func TestLegacyFunctionsWithEncodedShares(t *testing.T) {
+	raw, err := Split([]byte("encoded secret"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemShare, _ := EncodeSharePEM(raw[0])
+	enveloped, _ := SplitWithOptions([]byte("encoded secret"), WithParts(2), WithThreshold(2), WithLabels("a", "b"))
+	jsonShares, err := EncodeSharesJSON(enveloped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamShare bytes.Buffer
+	if err := SplitStream(strings.NewReader("streamed"), []io.Writer{&streamShare, io.Discard}, 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	sealed, _, err := SealSplit([]byte("sealed"), 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		share       []byte
+		format      string
+		replacement string
+	}{
+		{pemShare, "PEM", "DecodeSharePEM"},
+		{jsonShares, "JSON", "DecodeSharesJSON"},
+		{streamShare.Bytes(), "framed stream", "CombineStream"},
+		{sealed, "sealed ciphertext", "SealCombine"},
+	}
+	for _, tt := range tests {
+		_, err := Combine([][]byte{tt.share, raw[1]})
+		var merr *MigrationError
+		if !errors.As(err, &merr) || merr.Format != tt.format || !strings.HasPrefix(merr.Replacement, tt.replacement) {
+			t.Errorf("%s: expected a MigrationError suggesting %s, got %v", tt.format, tt.replacement, err)
+		}
+		if err := VerifyIntegrity(tt.share); !errors.Is(err, ErrFormatMismatch) {
+			t.Errorf("%s: VerifyIntegrity returned %v", tt.format, err)
+		}
+	}
+}
+
+func TestCombineLayoutDiagnostics(t *testing.T) {
+	secret := []byte("mixed tooling")
+
+	t.Run("checksummed shares", func(t *testing.T) {
+		shares, err := SplitWithIntegrity(secret, 3, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, combine := range map[string]func([][]byte) ([]byte, error){
+			"Combine":            Combine,
+			"CombineWithOptions": func(p [][]byte) ([]byte, error) { return CombineWithOptions(p) },
+		} {
+			_, err := combine(shares[:2])
+			var merr *MigrationError
+			if !errors.As(err, &merr) || merr.Func != name || merr.Format != "SplitWithIntegrity" ||
+				!strings.Contains(merr.Replacement, "WithIntegrity(true)") {
+				t.Errorf("%s: expected a MigrationError suggesting WithIntegrity, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("plain shares to integrity combine", func(t *testing.T) {
+		shares, err := Split(secret, 3, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = CombineWithIntegrity(shares[:2])
+		var merr *MigrationError
+		if !errors.As(err, &merr) || merr.Replacement != "Combine" {
+			t.Fatalf("expected a MigrationError suggesting Combine, got %v", err)
+		}
+		if !errors.Is(err, ErrIntegrityCheckFailed) {
+			t.Fatalf("error should still match ErrIntegrityCheckFailed: %v", err)
+		}
+
+		// A single corrupted share is an integrity failure, not a format mix-up.
+		checked, _ := SplitWithIntegrity(secret, 3, 2)
+		checked[1][3] ^= 1
+		if _, err := CombineWithIntegrity(checked[:2]); errors.As(err, &merr) || !errors.Is(err, ErrIntegrityCheckFailed) {
+			t.Fatalf("expected a plain integrity failure, got %v", err)
+		}
+	})
+
+	t.Run("vault layout", func(t *testing.T) {
+		// Vault shares whose first bytes collide cannot be valid Split shares.
+		shares := [][]byte{
+			{0x42, 0x10, 0x20, 0x9a},
+			{0x42, 0x11, 0x21, 0x07},
+			{0x00, 0x12, 0x22, 0xc3},
+		}
+		_, err := Combine(shares)
+		var merr *MigrationError
+		if !errors.As(err, &merr) || merr.Format != "HashiCorp Vault" || merr.Replacement != "CombineVault" || merr.Share != 1 {
+			t.Fatalf("expected a MigrationError suggesting CombineVault, got %v", err)
+		}
+
+		// Without a telltale last byte the duplicate is reported as before.
+		shares[1][3] = shares[0][3]
+		if _, err := Combine(shares); errors.As(err, &merr) {
+			t.Fatalf("unexpected MigrationError %v", err)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+// Package mnemonic encodes shares as human-readable word lists, so operators
+// can print them on paper or read them out over the phone.
+//
+// Every byte becomes one word from a fixed list of 256 four-letter words. A
+// mnemonic is laid out as
+//
+//	[index word] [one word per share byte ...] [4 checksum words]
+//
+// The leading index word is the share's x-coordinate, so custodians can tell
+// shares apart at a glance; the trailing words are a CRC32 of everything
+// before them and catch transcription mistakes. Both raw shares from
+// shamir.Split and enveloped shares are supported.
+//
+// Decoding accepts words in any case, and each word may be abbreviated to its
+// first and last letter ("able" or "ae").
+package mnemonic
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	shamir "github.com/morizta/go-shamir"
+	"github.com/morizta/go-shamir/internal/bytewords"
+)
+
+const checksumWords = 4
+
+var (
+	// ErrUnknownWord indicates that a word is not in the word list.
+	ErrUnknownWord = errors.New("mnemonic: unknown word")
+
+	// ErrInvalidMnemonic indicates that a mnemonic is too short or its index word
+	// does not match the share it encodes.
+	ErrInvalidMnemonic = errors.New("mnemonic: invalid mnemonic")
+)
+
+// Encode returns the mnemonic words for a raw or enveloped share.
+func Encode(share []byte) ([]string, error) {
+	index, err := shamir.ShareIndex(share)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMnemonic, err)
+	}
+
+	data := make([]byte, 0, 1+len(share)+checksumWords)
+	data = append(data, index)
+	data = append(data, share...)
+	data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+
+	words := make([]string, len(data))
+	for i, b := range data {
+		words[i] = wordlist[b]
+	}
+	return words, nil
+}
+
+// EncodeString returns the mnemonic for a share as a single space-separated string.
+func EncodeString(share []byte) (string, error) {
+	words, err := Encode(share)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(words, " "), nil
+}
+
+// Decode converts mnemonic words back into a share, verifying the checksum and
+// index word. Returns shamir.ErrIntegrityCheckFailed if the checksum does not match.
+func Decode(words []string) ([]byte, error) {
+	if len(words) < 1+2+checksumWords {
+		return nil, fmt.Errorf("%w: too few words", ErrInvalidMnemonic)
+	}
+
+	data := make([]byte, len(words))
+	for i, word := range words {
+		b, ok := bytewords.Lookup(word)
+		if !ok {
+			return nil, fmt.Errorf("%w %d: %q", ErrUnknownWord, i+1, word)
+		}
+		data[i] = b
+	}
+
+	body := data[:len(data)-checksumWords]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(body):]) {
+		return nil, shamir.ErrIntegrityCheckFailed
+	}
+
+	share := append([]byte(nil), body[1:]...)
+	index, err := shamir.ShareIndex(share)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMnemonic, err)
+	}
+	if index != body[0] {
+		return nil, fmt.Errorf("%w: index word %q does not match share %d", ErrInvalidMnemonic, words[0], index)
+	}
+
+	return share, nil
+}
+
+// DecodeString decodes a mnemonic given as a whitespace-separated string.
+func DecodeString(mnemonic string) ([]byte, error) {
+	return Decode(strings.Fields(mnemonic))
+}
@@ -0,0 +1,135 @@
+package mnemonic
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+func TestWordlist(t *testing.T) {
+	if !sort.StringsAreSorted(wordlist[:]) {
+		t.Error("word list is not sorted")
+	}
+	abbreviations := make(map[string]bool, len(wordlist))
+	for _, word := range wordlist {
+		if len(word) != 4 {
+			t.Errorf("word %q is not four letters", word)
+		}
+		short := word[:1] + word[3:]
+		if abbreviations[short] {
+			t.Errorf("abbreviation %q is ambiguous", short)
+		}
+		abbreviations[short] = true
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	raw, err := shamir.Split([]byte("printed on paper"), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enveloped, err := shamir.SplitKey(bytes.Repeat([]byte{3}, 16), shamir.AlgAES128GCM, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, shares := range map[string][][]byte{"raw": raw, "envelope": enveloped} {
+		t.Run(name, func(t *testing.T) {
+			decoded := make([][]byte, len(shares))
+			for i, share := range shares {
+				words, err := Encode(share)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(words) != 1+len(share)+checksumWords {
+					t.Fatalf("expected %d words, got %d", 1+len(share)+checksumWords, len(words))
+				}
+				if want := wordlist[i+1]; words[0] != want {
+					t.Fatalf("expected index word %q, got %q", want, words[0])
+				}
+
+				if decoded[i], err = Decode(words); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(decoded[i], share) {
+					t.Fatalf("share %d mismatch", i)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeString(t *testing.T) {
+	shares, err := shamir.Split([]byte("dictated"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phrase, err := EncodeString(shares[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Abbreviated, upper-case and irregularly spaced input decodes the same.
+	var abbreviated []string
+	for _, word := range strings.Fields(phrase) {
+		abbreviated = append(abbreviated, strings.ToUpper(word[:1]+word[3:]))
+	}
+	got, err := DecodeString("  " + strings.Join(abbreviated, "\n\t") + " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, shares[1]) {
+		t.Fatal("abbreviated mnemonic mismatch")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	shares, err := shamir.Split([]byte("secret"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words, err := Encode(shares[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edit := func(i int, word string) []string {
+		out := append([]string(nil), words...)
+		out[i] = word
+		return out
+	}
+	// A word that differs from the original, to simulate a transcription slip.
+	other := func(i int) string {
+		if words[i] == wordlist[0] {
+			return wordlist[1]
+		}
+		return wordlist[0]
+	}
+
+	tests := []struct {
+		name  string
+		words []string
+		want  error
+	}{
+		{"transcription slip", edit(3, other(3)), shamir.ErrIntegrityCheckFailed},
+		{"unknown word", edit(2, "banana"), ErrUnknownWord},
+		{"too short", words[:5], ErrInvalidMnemonic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.words); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("encode invalid share", func(t *testing.T) {
+		if _, err := Encode([]byte{1}); err == nil {
+			t.Fatal("expected error for a one-byte share")
+		}
+	})
+}
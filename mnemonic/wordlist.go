@@ -0,0 +1,8 @@
+package mnemonic
+
+import "github.com/morizta/go-shamir/internal/bytewords"
+
+// wordlist maps byte values to words. It is the Bytewords list: 256 four-letter
+// English words in alphabetical order, each uniquely identified by its first and
+// last letter, so a word can be written or dictated in abbreviated form.
+var wordlist = bytewords.Words
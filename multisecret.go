@@ -0,0 +1,224 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"sort"
+)
+
+// Multi-secret sharing.
+//
+// Escrowing many keys to the same committee would normally mean handing every
+// custodian one share per key. SplitMulti instead gives each custodian a single
+// blob holding their share of every secret. The secrets are split
+// independently, with fresh coefficients and a common x-coordinate per
+// custodian, so reconstructing one of them reveals nothing about the others and
+// CombineMulti can recover any one selectively.
+//
+// Multi-secret share format:
+//
+//	[3 bytes]  magic 0x00 'S' 'M'
+//	[1 byte]   format version (1)
+//	[16 bytes] set ID
+//	[1 byte]   threshold
+//	[1 byte]   x-coordinate
+//	[2 bytes]  number of secrets (big-endian)
+//	per secret, in name order:
+//	  [1 byte]  name length, then the name
+//	  [4 bytes] payload length (big-endian), then one y-value per secret byte
+//	[4 bytes]  CRC32 (IEEE) of all preceding bytes, big-endian
+
+// multiMagic identifies a multi-secret share.
+var multiMagic = [3]byte{0x00, 'S', 'M'}
+
+const (
+	multiFormatVersion = 1
+	multiHeaderSize    = len(multiMagic) + 1 + len(SetID{}) + 4
+	multiChecksumSize  = 4
+	maxMultiSecrets    = 0xffff
+	maxSecretNameSize  = 0xff
+)
+
+// multiShare is a decoded multi-secret share.
+type multiShare struct {
+	setID     SetID
+	threshold int
+	index     byte
+	names     []string
+	payloads  map[string][]byte
+}
+
+// SplitMulti splits several named secrets among parts custodians so that each
+// holds a single blob, and any threshold of the blobs recover any one secret
+// with CombineMulti. Secrets may differ in length; names must be non-empty and
+// at most 255 bytes. Every blob carries the size and name of every secret.
+func SplitMulti(secrets map[string][]byte, parts, threshold int) ([][]byte, error) {
+	if len(secrets) == 0 {
+		return nil, ErrEmptySecret
+	}
+	if len(secrets) > maxMultiSecrets {
+		return nil, NewValidationError("secrets", len(secrets), "shamir: too many secrets for one share set")
+	}
+	names := make([]string, 0, len(secrets))
+	for name, secret := range secrets {
+		if name == "" || len(name) > maxSecretNameSize {
+			return nil, NewValidationError("name", len(name), "shamir: secret name must be 1 to 255 bytes")
+		}
+		if err := validateSplitParams(secret, parts, threshold); err != nil {
+			return nil, fmt.Errorf("secret %q: %w", name, err)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	o := newOptions([]Option{WithParts(parts), WithThreshold(threshold)})
+	xCoords, err := o.coordinates(secrets[names[0]])
+	if err != nil {
+		return nil, err
+	}
+	setID, err := newSetID()
+	if err != nil {
+		return nil, err
+	}
+
+	blobs := make([][]byte, len(xCoords))
+	for i, x := range xCoords {
+		blobs[i] = append(blobs[i], multiMagic[:]...)
+		blobs[i] = append(blobs[i], multiFormatVersion)
+		blobs[i] = append(blobs[i], setID[:]...)
+		blobs[i] = append(blobs[i], byte(threshold), x)
+		blobs[i] = binary.BigEndian.AppendUint16(blobs[i], uint16(len(names)))
+	}
+	wipe := func() {
+		for _, blob := range blobs {
+			secureZeroBytes(blob)
+		}
+	}
+
+	for _, name := range names {
+		secret := secrets[name]
+		shares, err := splitAt(secret, xCoords, threshold, rand.Reader, engine{})
+		if err != nil {
+			wipe()
+			return nil, err
+		}
+		for i, share := range shares {
+			blobs[i] = append(blobs[i], byte(len(name)))
+			blobs[i] = append(blobs[i], name...)
+			blobs[i] = binary.BigEndian.AppendUint32(blobs[i], uint32(len(secret)))
+			blobs[i] = append(blobs[i], share[ShareOverhead:]...)
+			secureZeroBytes(share)
+		}
+	}
+
+	for i, blob := range blobs {
+		blobs[i] = binary.BigEndian.AppendUint32(blob, crc32.ChecksumIEEE(blob))
+	}
+	return blobs, nil
+}
+
+// CombineMulti reconstructs the secret called name from at least threshold
+// blobs produced by SplitMulti; the other secrets are left untouched. It
+// returns ErrUnknownSecret if the blobs hold no secret of that name and
+// ErrMismatchedShares if they come from different calls to SplitMulti.
+func CombineMulti(blobs [][]byte, name string) ([]byte, error) {
+	if blobs == nil {
+		return nil, ErrNilShares
+	}
+	if len(blobs) < 2 {
+		return nil, ErrTooFewParts
+	}
+
+	shares := make([]*multiShare, len(blobs))
+	for i, blob := range blobs {
+		s, err := parseMultiShare(blob)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", i, err)
+		}
+		if i > 0 && (s.setID != shares[0].setID || s.threshold != shares[0].threshold) {
+			return nil, fmt.Errorf("share %d: %w", i, ErrMismatchedShares)
+		}
+		shares[i] = s
+	}
+	if len(shares) < shares[0].threshold {
+		return nil, ErrInsufficientShares
+	}
+
+	raw := make([][]byte, len(shares))
+	defer func() {
+		for _, part := range raw {
+			secureZeroBytes(part)
+		}
+	}()
+	for i, s := range shares {
+		payload, ok := s.payloads[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownSecret, name)
+		}
+		raw[i] = append(append(make([]byte, 0, ShareOverhead+len(payload)), s.index), payload...)
+	}
+
+	meta := &CombineMetadata{Threshold: shares[0].threshold, SetID: shares[0].setID}
+	return combine(raw, engine{meta: meta})
+}
+
+// MultiSecretNames returns the names of the secrets held in a blob produced
+// by SplitMulti, in sorted order.
+func MultiSecretNames(blob []byte) ([]string, error) {
+	s, err := parseMultiShare(blob)
+	if err != nil {
+		return nil, err
+	}
+	return s.names, nil
+}
+
+// parseMultiShare decodes and checks a multi-secret share. The payloads alias
+// data.
+func parseMultiShare(data []byte) (*multiShare, error) {
+	if len(data) < multiHeaderSize+multiChecksumSize || !bytes.HasPrefix(data, multiMagic[:]) {
+		return nil, ErrInvalidMultiShare
+	}
+	if v := data[len(multiMagic)]; v != multiFormatVersion {
+		return nil, fmt.Errorf("%w: multi-secret share version %d", ErrUnsupportedVersion, v)
+	}
+	body := data[:len(data)-multiChecksumSize]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(body):]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidMultiShare)
+	}
+
+	s := &multiShare{payloads: make(map[string][]byte)}
+	p := len(multiMagic) + 1
+	p += copy(s.setID[:], body[p:])
+	s.threshold, s.index = int(body[p]), body[p+1]
+	count := int(binary.BigEndian.Uint16(body[p+2:]))
+	p += 4
+	if s.threshold < 2 || s.index == 0 || count == 0 {
+		return nil, fmt.Errorf("%w: bad header", ErrInvalidMultiShare)
+	}
+
+	for range count {
+		if p >= len(body) || p+1+int(body[p])+4 > len(body) {
+			return nil, fmt.Errorf("%w: truncated", ErrInvalidMultiShare)
+		}
+		name := string(body[p+1 : p+1+int(body[p])])
+		p += 1 + len(name)
+		size := int(binary.BigEndian.Uint32(body[p:]))
+		p += 4
+		if name == "" || size == 0 || size > len(body)-p {
+			return nil, fmt.Errorf("%w: bad secret record", ErrInvalidMultiShare)
+		}
+		if _, dup := s.payloads[name]; dup {
+			return nil, fmt.Errorf("%w: duplicate secret %q", ErrInvalidMultiShare, name)
+		}
+		s.names = append(s.names, name)
+		s.payloads[name] = body[p : p+size]
+		p += size
+	}
+	if p != len(body) {
+		return nil, fmt.Errorf("%w: trailing data", ErrInvalidMultiShare)
+	}
+	return s, nil
+}
@@ -0,0 +1,99 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSplitCombineMulti(t *testing.T) {
+	secrets := map[string][]byte{
+		"db-master":  []byte("database master key"),
+		"signing":    bytes.Repeat([]byte{0x42}, 64),
+		"backup-pin": []byte("0000"),
+	}
+	blobs, err := SplitMulti(secrets, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blobs) != 5 {
+		t.Fatalf("got %d blobs, want 5", len(blobs))
+	}
+
+	names, err := MultiSecretNames(blobs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(names, []string{"backup-pin", "db-master", "signing"}) {
+		t.Fatalf("names = %q", names)
+	}
+
+	for name, secret := range secrets {
+		got, err := CombineMulti([][]byte{blobs[4], blobs[1], blobs[2]}, name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Fatalf("%s: reconstructed secret mismatch", name)
+		}
+	}
+
+	if _, err := CombineMulti(blobs[:3], "missing"); !errors.Is(err, ErrUnknownSecret) {
+		t.Fatalf("expected ErrUnknownSecret, got %v", err)
+	}
+	if _, err := CombineMulti(blobs[:2], "signing"); !errors.Is(err, ErrInsufficientShares) {
+		t.Fatalf("expected ErrInsufficientShares, got %v", err)
+	}
+}
+
+func TestCombineMultiErrors(t *testing.T) {
+	secrets := map[string][]byte{"a": []byte("first"), "b": []byte("second")}
+	blobs, err := SplitMulti(secrets, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := SplitMulti(secrets, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CombineMulti([][]byte{blobs[0], other[1]}, "a"); !errors.Is(err, ErrMismatchedShares) {
+		t.Fatalf("expected ErrMismatchedShares, got %v", err)
+	}
+
+	corrupted := slices.Clone(blobs[1])
+	corrupted[len(corrupted)-6] ^= 1
+	if _, err := CombineMulti([][]byte{blobs[0], corrupted}, "a"); !errors.Is(err, ErrInvalidMultiShare) {
+		t.Fatalf("expected ErrInvalidMultiShare, got %v", err)
+	}
+
+	raw, _ := Split([]byte("raw"), 2, 2)
+	if _, err := CombineMulti(raw, "a"); !errors.Is(err, ErrInvalidMultiShare) {
+		t.Fatalf("expected ErrInvalidMultiShare, got %v", err)
+	}
+
+	// Handing multi-secret shares to Combine names the right function.
+	var merr *MigrationError
+	if _, err := Combine(blobs[:2]); !errors.As(err, &merr) || merr.Replacement != "CombineMulti" {
+		t.Fatalf("expected a MigrationError pointing at CombineMulti, got %v", err)
+	}
+}
+
+func TestSplitMultiValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets map[string][]byte
+		parts   int
+	}{
+		{"no secrets", nil, 3},
+		{"empty name", map[string][]byte{"": []byte("x")}, 3},
+		{"empty secret", map[string][]byte{"a": nil}, 3},
+		{"bad parts", map[string][]byte{"a": []byte("x")}, 1},
+	}
+	for _, tt := range tests {
+		if _, err := SplitMulti(tt.secrets, tt.parts, 2); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package shamir
+
+const raceEnabled = false
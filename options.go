@@ -0,0 +1,240 @@
+package shamir
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Option configures SplitWithOptions and CombineWithOptions.
+type Option func(*options)
+
+// options holds the settings collected from Option values.
+type options struct {
+	parts       int
+	threshold   int
+	integrity   bool
+	rand        io.Reader
+	parallelism int      // Maximum goroutines; 0 means runtime.GOMAXPROCS
+	strategy    Strategy // Evaluation strategy, see WithStrategy
+
+	purpose       string // Purpose to bind at split time or expect at combine time
+	strictPurpose bool   // Require a matching purpose at combine time
+
+	xCoords []byte // Explicit x-coordinates; overrides parts when set
+	randomX bool   // Draw x-coordinates at random, see WithRandomXCoordinates
+
+	spillDir string // Directory for SplitSpilled's temporary files; "" means os.TempDir
+
+	labels []string // Per-share envelope labels, see WithLabels
+
+	notBefore, notAfter time.Time // Share validity window, see WithValidity
+	allowExpired        bool      // Combine shares outside their window, see WithAllowExpired
+
+	commitment bool // Record a commitment to the secret, see WithCommitment
+
+	allowTrivial bool // Permit a threshold of 1, see WithAllowTrivialThreshold
+
+	chunkSize int // Split secrets longer than this in framed chunks, see WithChunkSize
+
+	ctx context.Context // Cancellation for SplitContext and CombineContext
+
+	policyHooks []PolicyHook // Per-call combine policy, see WithPolicyHook
+
+	strict int // Threshold surplus shares are cross-checked against, see CombineStrict
+
+	entropyTimeout time.Duration // See WithEntropyTimeout; 0 means the default, negative never
+
+	constantTime bool // Use the hardened combine path, see WithConstantTime
+}
+
+// newOptions applies opts over the defaults: crypto/rand and an automatically
+// selected strategy.
+func newOptions(opts []Option) *options {
+	o := &options{
+		rand: rand.Reader,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithParts sets the total number of shares to generate (2-255). Required for SplitWithOptions.
+func WithParts(parts int) Option {
+	return func(o *options) { o.parts = parts }
+}
+
+// WithThreshold sets the minimum number of shares needed for reconstruction. Required for SplitWithOptions.
+func WithThreshold(threshold int) Option {
+	return func(o *options) { o.threshold = threshold }
+}
+
+// WithIntegrity appends a CRC32 checksum to every share when splitting, and
+// verifies and strips it when combining (as SplitWithIntegrity/CombineWithIntegrity).
+func WithIntegrity(enabled bool) Option {
+	return func(o *options) { o.integrity = enabled }
+}
+
+// WithRand sets the source of randomness for polynomial coefficients.
+// A nil reader selects crypto/rand. Only use other sources for testing.
+func WithRand(r io.Reader) Option {
+	return func(o *options) {
+		if r == nil {
+			r = rand.Reader
+		}
+		o.rand = r
+	}
+}
+
+// WithParallelism sets the maximum number of goroutines used to generate shares
+// or reconstruct the secret. Values below 1 are treated as 1 (sequential).
+// The default is runtime.GOMAXPROCS(0); whether more than one goroutine is
+// actually used depends on the strategy (see WithStrategy).
+func WithParallelism(n int) Option {
+	return func(o *options) {
+		if n < 1 {
+			n = 1
+		}
+		o.parallelism = n
+	}
+}
+
+// WithXCoordinates assigns each share an explicit x-coordinate (its first
+// byte): the i-th share is evaluated at xs[i]. This lets a caller pin a share
+// to a custodian, for instance to reissue one custodian's share at the same
+// point. The values must be distinct and non-zero; the share count is len(xs),
+// and WithParts, if given, must agree with it. Takes precedence over
+// WithRandomXCoordinates.
+func WithXCoordinates(xs ...byte) Option {
+	return func(o *options) { o.xCoords = append([]byte(nil), xs...) }
+}
+
+// WithRandomXCoordinates evaluates the shares at distinct x-coordinates drawn
+// at random from 1-255 using the WithRand source, as HashiCorp Vault does,
+// instead of 1 through parts. Sequential coordinates reveal each share's
+// position in the split and how many shares were issued; random ones reveal
+// neither.
+func WithRandomXCoordinates(enabled bool) Option {
+	return func(o *options) { o.randomX = enabled }
+}
+
+// SplitWithOptions splits a secret configured by functional options.
+//
+// Example:
+//
+//	shares, err := shamir.SplitWithOptions(secret,
+//		shamir.WithParts(5),
+//		shamir.WithThreshold(3),
+//		shamir.WithIntegrity(true),
+//	)
+//
+// WithParts and WithThreshold are required; all other options are optional.
+func SplitWithOptions(secret []byte, opts ...Option) ([][]byte, error) {
+	o := newOptions(opts)
+
+	if o.chunkSize > maxStreamChunkSize {
+		return nil, NewValidationError("chunkSize", o.chunkSize, "shamir: chunk size must not exceed 16 MiB")
+	}
+	if o.chunkSize > 0 && len(secret) > o.chunkSize {
+		return splitChunked(secret, o)
+	}
+	if err := checkSecretSize(secret); err != nil {
+		return nil, err
+	}
+	if o.purpose != "" || o.labels != nil || o.hasValidity() || o.commitment {
+		// Enveloped shares always carry a CRC32, so WithIntegrity is implied.
+		return splitEnvelopes(secret, o, Share{Purpose: o.purpose, CreatedAt: creationTime()})
+	}
+
+	shares, err := o.split(secret)
+	if err != nil {
+		return nil, err
+	}
+	if o.integrity {
+		shares = withIntegrityChecks(shares)
+	}
+
+	return shares, nil
+}
+
+// split generates raw shares as configured by o.
+func (o *options) split(secret []byte) ([][]byte, error) {
+	xCoords, err := o.coordinates(secret)
+	if err != nil {
+		return nil, err
+	}
+	return splitAt(secret, xCoords, o.threshold, o.rand, o.engine())
+}
+
+// coordinates validates the split parameters in o and returns the share
+// x-coordinates: the explicit ones if set (see WithXCoordinates), random ones
+// if requested, otherwise 1 through parts.
+func (o *options) coordinates(secret []byte) ([]byte, error) {
+	if o.xCoords == nil {
+		if err := o.validateSplitParams(secret, o.parts); err != nil {
+			return nil, err
+		}
+		if o.randomX {
+			// Rejection sampling needs a little over one byte per share; buffer
+			// the reads so each waits on the entropy timeout only once.
+			return randomXCoords(o.parts, bufio.NewReaderSize(entropyReader{o.rand, o.entropyTimeout}, 2*o.parts))
+		}
+		xCoords := make([]byte, o.parts)
+		for i := range xCoords {
+			xCoords[i] = byte(i + 1)
+		}
+		return xCoords, nil
+	}
+
+	if o.parts != 0 && o.parts != len(o.xCoords) {
+		return nil, NewValidationError("parts", o.parts, "shamir: parts does not match the number of x-coordinates")
+	}
+	if err := o.validateSplitParams(secret, len(o.xCoords)); err != nil {
+		return nil, err
+	}
+	seen := make(map[byte]bool, len(o.xCoords))
+	for i, x := range o.xCoords {
+		if x == 0 {
+			return nil, zeroXError(i)
+		}
+		if seen[x] {
+			return nil, NewValidationError("x-coordinate", i, "shamir: duplicate x-coordinate")
+		}
+		seen[x] = true
+	}
+	return o.xCoords, nil
+}
+
+// CombineWithOptions reconstructs a secret from shares produced by SplitWithOptions.
+// Pass the same WithIntegrity setting that was used when splitting; WithParallelism
+// is honoured, and split-only options are ignored. Purpose-bound shares are
+// detected automatically and checked against WithPurpose/WithStrictPurpose, and
+// chunked shares (see WithChunkSize) are reassembled one chunk at a time.
+func CombineWithOptions(parts [][]byte, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+
+	if len(parts) > 0 && IsEnvelope(parts[0]) {
+		return combinePurposeBound(parts, o)
+	}
+	if o.strictPurpose {
+		return nil, fmt.Errorf("%w: shares are not bound to a purpose", ErrPurposeMismatch)
+	}
+	if isChunked(parts) {
+		return combineChunked(parts, o.engine())
+	}
+
+	if o.integrity {
+		if err := checkIntegrityLayout("CombineWithOptions", parts, "CombineWithOptions without WithIntegrity"); err != nil {
+			return nil, err
+		}
+		return combineWithIntegrity(parts, o.engine())
+	}
+	if err := checkRawLayout("CombineWithOptions", parts); err != nil {
+		return nil, err
+	}
+	return combine(parts, o.engine())
+}
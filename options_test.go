@@ -0,0 +1,165 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// zeroReader is a deterministic randomness source for tests.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// failingReader always returns an error.
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("entropy source failed")
+}
+
+func TestSplitWithOptions(t *testing.T) {
+	secret := bytes.Repeat([]byte("options based secret "), 1000)
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"defaults", nil},
+		{"integrity", []Option{WithIntegrity(true)}},
+		{"parallel", []Option{WithParallelism(4)}},
+		{"parallel with integrity", []Option{WithParallelism(8), WithIntegrity(true)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithParts(7), WithThreshold(4)}, tt.opts...)
+			shares, err := SplitWithOptions(secret, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(shares) != 7 {
+				t.Fatalf("expected 7 shares, got %d", len(shares))
+			}
+
+			reconstructed, err := CombineWithOptions(shares[2:6], opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(reconstructed, secret) {
+				t.Fatal("reconstruction failed")
+			}
+		})
+	}
+}
+
+func TestSplitWithOptionsRand(t *testing.T) {
+	secret := []byte("deterministic")
+
+	shares, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithRand(zeroReader{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// With all-zero coefficients every share's payload equals the secret.
+	for i, share := range shares {
+		if !bytes.Equal(share[1:], secret) {
+			t.Fatalf("share %d payload differs from secret with zero coefficients", i)
+		}
+	}
+
+	if _, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithRand(failingReader{})); err == nil {
+		t.Fatal("expected error from failing randomness source")
+	}
+}
+
+func TestSplitWithOptionsValidation(t *testing.T) {
+	if _, err := SplitWithOptions([]byte("x")); err == nil {
+		t.Fatal("expected error when parts and threshold are missing")
+	}
+	if _, err := SplitWithOptions([]byte("x"), WithParts(3), WithThreshold(4)); err == nil {
+		t.Fatal("expected error for threshold > parts")
+	}
+}
+
+func TestSplitWithRandomXCoordinates(t *testing.T) {
+	secret := []byte("random coordinates")
+
+	shares, err := SplitWithOptions(secret, WithParts(5), WithThreshold(3), WithRandomXCoordinates(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[byte]bool)
+	sequential := true
+	for i, share := range shares {
+		if share[0] == 0 || seen[share[0]] {
+			t.Fatalf("share %d has zero or duplicate x-coordinate %d", i, share[0])
+		}
+		seen[share[0]] = true
+		sequential = sequential && share[0] == byte(i+1)
+	}
+	// The chance of drawing exactly 1..5 in order is about 1 in 10^12.
+	if sequential {
+		t.Fatal("random x-coordinates came out sequential")
+	}
+
+	reconstructed, err := CombineWithOptions(shares[1:4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("reconstruction failed")
+	}
+
+	if _, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithRandomXCoordinates(true), WithRand(failingReader{})); err == nil {
+		t.Fatal("expected error from failing randomness source")
+	}
+}
+
+func TestSplitWithXCoordinates(t *testing.T) {
+	secret := []byte("explicit coordinates")
+	xs := []byte{200, 17, 99, 4}
+
+	shares, err := SplitWithOptions(secret, WithThreshold(3), WithXCoordinates(xs...), WithRandomXCoordinates(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != len(xs) {
+		t.Fatalf("expected %d shares, got %d", len(xs), len(shares))
+	}
+	for i, share := range shares {
+		if share[0] != xs[i] {
+			t.Fatalf("share %d: x-coordinate %d, want %d", i, share[0], xs[i])
+		}
+	}
+	reconstructed, err := CombineWithOptions([][]byte{shares[3], shares[0], shares[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("reconstruction failed")
+	}
+
+	invalid := []struct {
+		name string
+		opts []Option
+	}{
+		{"zero", []Option{WithXCoordinates(1, 0, 2)}},
+		{"duplicate", []Option{WithXCoordinates(1, 2, 1)}},
+		{"too few", []Option{WithXCoordinates(1)}},
+		{"parts mismatch", []Option{WithParts(4), WithXCoordinates(1, 2, 3)}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithThreshold(2)}, tt.opts...)
+			var verr *ValidationError
+			if _, err := SplitWithOptions(secret, opts...); !errors.As(err, &verr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+// Package paper encodes shares for printing on paper, in a format that
+// survives OCR and typing mistakes during manual recovery:
+//
+//	# shamir paper share 3, 3 lines
+//	00  041P6 VVJE9 JP6X1 0D1QQ  B6K99M
+//	01  4WV54 1H62X 3MCNS 7K6M1  JHFWRQ
+//	G2  PS20  0W2W15
+//
+// Each line is a Reed-Solomon codeword over the 32 characters of Crockford's
+// base32: two line header characters (the line number, starting with G or
+// later on the last line), up to 20 data characters in groups of five, and six parity characters. The
+// parity doubles as the per-line checksum: it detects any damaged line and
+// corrects up to three wrong characters in it. The data characters spell out
+//
+//	[1 byte]   format version (1)
+//	[n bytes]  the share, raw or enveloped
+//	[4 bytes]  CRC32 of the above
+//
+// and the CRC32 catches the rare damage beyond what the parity corrects.
+//
+// The parser is forgiving: it ignores case, spaces, hyphens and dots, reads
+// the letters O, I, L and U as the 0, 1, 1 and V they are commonly confused
+// with, skips blank lines and lines starting with '#', and accepts the lines
+// in any order.
+package paper
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"slices"
+	"strings"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+const (
+	formatVersion = 1
+
+	headerSymbols = 2  // Line number and last-line flag
+	dataSymbols   = 20 // Data characters on a full line
+	paritySymbols = 6  // Reed-Solomon parity characters per line
+	groupSize     = 5  // Characters per printed group
+
+	// lastLine flags the last line in the header, whose other nine bits
+	// are the line number.
+	lastLine = 1 << 9
+
+	// MaxLines is the most lines a paper share can have, which bounds the
+	// share length to about 6 KB.
+	MaxLines = 512
+)
+
+// alphabet is Crockford's base32.
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var (
+	// ErrInvalidPaper indicates text that is not a paper share, or lines of
+	// several paper shares mixed together.
+	ErrInvalidPaper = errors.New("paper: invalid paper share")
+
+	// ErrUncorrectable indicates a line with more errors than its parity can
+	// correct. Retype the line it names.
+	ErrUncorrectable = errors.New("paper: line has too many errors to correct")
+
+	// ErrMissingLines indicates that lines of the share are missing.
+	ErrMissingLines = errors.New("paper: missing lines")
+
+	// ErrTooLong indicates a share too long to encode in MaxLines lines.
+	ErrTooLong = errors.New("paper: share too long")
+)
+
+// Correction is a character the parser corrected.
+type Correction struct {
+	Line   int  // Line number, from the line's first two characters
+	Column int  // Position of the character in the line, from 0, ignoring separators
+	Got    byte // Character read
+	Want   byte // Character it was corrected to
+}
+
+// Encode returns the paper encoding of a raw or enveloped share, one line per
+// codeword under a comment line naming the share, each line ending in a
+// newline.
+func Encode(share []byte) (string, error) {
+	index, err := shamir.ShareIndex(share)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidPaper, err)
+	}
+
+	data := make([]byte, 0, 1+len(share)+4)
+	data = append(data, formatVersion)
+	data = append(data, share...)
+	data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+	defer clear(data)
+	symbols := toSymbols(data)
+	defer clear(symbols)
+
+	lines := (len(symbols) + dataSymbols - 1) / dataSymbols
+	if lines > MaxLines {
+		return "", fmt.Errorf("%w: %d bytes need %d lines, at most %d allowed", ErrTooLong, len(share), lines, MaxLines)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# shamir paper share %d, %d lines\n", index, lines)
+	for n := range lines {
+		chunk := symbols[n*dataSymbols : min((n+1)*dataSymbols, len(symbols))]
+		header := n
+		if n == lines-1 {
+			header |= lastLine
+		}
+		word := make([]byte, 0, headerSymbols+len(chunk)+paritySymbols)
+		word = append(word, byte(header>>5), byte(header&31))
+		word = append(word, chunk...)
+		word = append(word, rsEncode(word)...)
+
+		writeSymbols(&b, word[:headerSymbols])
+		b.WriteString(" ")
+		for i := headerSymbols; i < len(word)-paritySymbols; i += groupSize {
+			b.WriteString(" ")
+			writeSymbols(&b, word[i:min(i+groupSize, len(word)-paritySymbols)])
+		}
+		b.WriteString("  ")
+		writeSymbols(&b, word[len(word)-paritySymbols:])
+		b.WriteString("\n")
+		clear(word)
+	}
+	return b.String(), nil
+}
+
+// Decode parses a paper share, correcting what errors it can, and returns the
+// share. See DecodeWithCorrections to learn which characters were corrected.
+func Decode(text string) ([]byte, error) {
+	share, _, err := DecodeWithCorrections(text)
+	return share, err
+}
+
+// DecodeWithCorrections parses a paper share and also returns every character
+// it corrected, so the operator can fix the printed copy. A line with too
+// many errors fails with ErrUncorrectable naming the line, and damage that
+// slips past the parity fails the CRC32 with shamir.ErrIntegrityCheckFailed.
+func DecodeWithCorrections(text string) ([]byte, []Correction, error) {
+	type line struct {
+		number int
+		last   bool
+		data   []byte
+	}
+	var (
+		lines       []line
+		corrections []Correction
+	)
+	for i, raw := range strings.Split(text, "\n") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" || strings.HasPrefix(raw, "#") {
+			continue
+		}
+		word, err := parseSymbols(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("text line %d: %w", i+1, err)
+		}
+		if len(word) < headerSymbols+1+paritySymbols || len(word) > headerSymbols+dataSymbols+paritySymbols {
+			return nil, nil, fmt.Errorf("%w: text line %d has %d characters, want %d",
+				ErrInvalidPaper, i+1, len(word), headerSymbols+dataSymbols+paritySymbols)
+		}
+		read := slices.Clone(word)
+		positions, ok := rsCorrect(word)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: text line %d: %s", ErrUncorrectable, i+1, raw)
+		}
+		header := int(word[0])<<5 | int(word[1])
+		number := header &^ lastLine
+		for _, p := range positions {
+			corrections = append(corrections, Correction{Line: number, Column: p, Got: alphabet[read[p]], Want: alphabet[word[p]]})
+		}
+		lines = append(lines, line{number: number, last: header&lastLine != 0, data: word[headerSymbols : len(word)-paritySymbols]})
+	}
+	if len(lines) == 0 {
+		return nil, nil, fmt.Errorf("%w: no lines", ErrInvalidPaper)
+	}
+
+	slices.SortStableFunc(lines, func(a, b line) int { return a.number - b.number })
+	lines = slices.CompactFunc(lines, func(a, b line) bool {
+		return a.number == b.number && a.last == b.last && slices.Equal(a.data, b.data)
+	})
+	var symbols []byte
+	for i, l := range lines {
+		switch {
+		case l.number != i && (i == 0 || l.number != lines[i-1].number):
+			return nil, nil, fmt.Errorf("%w: line %02d", ErrMissingLines, i)
+		case l.number != i:
+			return nil, nil, fmt.Errorf("%w: two different lines numbered %02d", ErrInvalidPaper, l.number)
+		case l.last != (i == len(lines)-1):
+			if l.last {
+				return nil, nil, fmt.Errorf("%w: lines after the last line %02d", ErrInvalidPaper, l.number)
+			}
+			if i == len(lines)-1 {
+				return nil, nil, fmt.Errorf("%w: after line %02d", ErrMissingLines, l.number)
+			}
+		case !l.last && len(l.data) != dataSymbols:
+			return nil, nil, fmt.Errorf("%w: line %02d is short", ErrInvalidPaper, l.number)
+		}
+		symbols = append(symbols, l.data...)
+	}
+
+	data, ok := fromSymbols(symbols)
+	if !ok || len(data) < 1+shamir.ShareOverhead+1+4 {
+		return nil, nil, fmt.Errorf("%w: bad padding or length", ErrInvalidPaper)
+	}
+	defer clear(data)
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(body):]) {
+		return nil, nil, shamir.ErrIntegrityCheckFailed
+	}
+	if body[0] != formatVersion {
+		return nil, nil, fmt.Errorf("%w: paper share version %d", shamir.ErrUnsupportedVersion, body[0])
+	}
+	share := slices.Clone(body[1:])
+	if _, err := shamir.ShareIndex(share); err != nil {
+		clear(share)
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidPaper, err)
+	}
+	return share, corrections, nil
+}
+
+// parseSymbols maps a line of text to symbols, skipping separators.
+func parseSymbols(s string) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+	for _, r := range strings.ToUpper(s) {
+		switch r {
+		case ' ', '\t', '\r', '-', '.':
+			continue
+		case 'O':
+			r = '0'
+		case 'I', 'L':
+			r = '1'
+		case 'U':
+			r = 'V'
+		}
+		i := strings.IndexRune(alphabet, r)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: unexpected character %q", ErrInvalidPaper, r)
+		}
+		out = append(out, byte(i))
+	}
+	return out, nil
+}
+
+func writeSymbols(b *strings.Builder, symbols []byte) {
+	for _, s := range symbols {
+		b.WriteByte(alphabet[s])
+	}
+}
+
+// toSymbols splits data into 5-bit symbols, most significant bits first,
+// padding the last symbol with zero bits.
+func toSymbols(data []byte) []byte {
+	out := make([]byte, 0, (len(data)*8+4)/5)
+	var acc uint32
+	bits := 0
+	for _, b := range data {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out = append(out, byte(acc>>bits)&31)
+		}
+	}
+	if bits > 0 {
+		out = append(out, byte(acc<<(5-bits))&31)
+	}
+	return out
+}
+
+// fromSymbols reverses toSymbols, reporting false if the padding is not zero
+// or there are more padding bits than toSymbols writes.
+func fromSymbols(symbols []byte) ([]byte, bool) {
+	out := make([]byte, 0, len(symbols)*5/8)
+	var acc uint32
+	bits := 0
+	for _, s := range symbols {
+		acc = acc<<5 | uint32(s)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			out = append(out, byte(acc>>bits))
+		}
+	}
+	return out, bits < 5 && acc&(1<<bits-1) == 0
+}
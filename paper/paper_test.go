@@ -0,0 +1,203 @@
+package paper
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+func TestRoundTrip(t *testing.T) {
+	raw, err := shamir.Split([]byte("printed on paper"), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enveloped, err := shamir.SplitKey(bytes.Repeat([]byte{3}, 32), shamir.AlgAES256GCM, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, shares := range map[string][][]byte{"raw": raw, "envelope": enveloped} {
+		t.Run(name, func(t *testing.T) {
+			decoded := make([][]byte, len(shares))
+			for i, share := range shares {
+				text, err := Encode(share)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n")[1:] {
+					if n := len(strings.NewReplacer(" ", "").Replace(line)); n > headerSymbols+dataSymbols+paritySymbols {
+						t.Fatalf("line %q has %d characters", line, n)
+					}
+				}
+				decoded[i], err = Decode(text)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(decoded[i], share) {
+					t.Fatalf("share %d changed in the round trip", i)
+				}
+			}
+			if _, err := shamir.Combine(decoded); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestCorrectsErrors(t *testing.T) {
+	share := append([]byte{7}, bytes.Repeat([]byte("paper"), 10)...)
+	text, err := Encode(share)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(text, "\n")
+
+	// Three wrong characters on one line, two on another, one in a header.
+	damage := func(line, column int) {
+		b := []byte(lines[line])
+		pos := -1
+		for i, c := range b {
+			if c != ' ' {
+				pos++
+			}
+			if pos == column {
+				b[i] = alphabet[(strings.IndexByte(alphabet, c)+1)%32]
+				break
+			}
+		}
+		lines[line] = string(b)
+	}
+	damage(1, 0)
+	damage(1, 9)
+	damage(1, 27)
+	damage(2, 3)
+	damage(2, 4)
+	damage(3, 1)
+
+	got, corrections, err := DecodeWithCorrections(strings.Join(lines, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, share) {
+		t.Fatal("corrected share differs")
+	}
+	want := []Correction{{0, 0, 0, 0}, {0, 9, 0, 0}, {0, 27, 0, 0}, {1, 3, 0, 0}, {1, 4, 0, 0}, {2, 1, 0, 0}}
+	if len(corrections) != len(want) {
+		t.Fatalf("expected %d corrections, got %+v", len(want), corrections)
+	}
+	for i, c := range corrections {
+		if c.Line != want[i].Line || c.Column != want[i].Column || c.Got == c.Want {
+			t.Errorf("correction %d: got %+v, want line %d column %d", i, c, want[i].Line, want[i].Column)
+		}
+	}
+
+	// A fourth error on a line is more than the parity can correct.
+	damage(1, 14)
+	if _, err := Decode(strings.Join(lines, "\n")); !errors.Is(err, ErrUncorrectable) {
+		t.Errorf("four errors: expected ErrUncorrectable, got %v", err)
+	}
+}
+
+func TestSloppyInput(t *testing.T) {
+	share := []byte{2, 0x10, 0x20, 0x30, 0x40, 0x50, 0x60, 0x70, 0x80, 0x90}
+	text, err := Encode(share)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")[1:]
+	var sloppy strings.Builder
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.ToLower(lines[i])
+		line = strings.NewReplacer("0", "o", "1", "l", "v", "u", "  ", " - ").Replace(line)
+		sloppy.WriteString("\t " + line + " \r\n\n")
+	}
+	got, corrections, err := DecodeWithCorrections(sloppy.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, share) || len(corrections) != 0 {
+		t.Fatalf("got %x with corrections %+v", got, corrections)
+	}
+
+	if _, err := Decode("00 ABCDE ?FGH1 23456"); !errors.Is(err, ErrInvalidPaper) {
+		t.Errorf("bad character: expected ErrInvalidPaper, got %v", err)
+	}
+}
+
+func TestLines(t *testing.T) {
+	share := append([]byte{1}, bytes.Repeat([]byte{0xAB}, 30)...)
+	text, err := Encode(share)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")[1:]
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	join := func(l ...string) string { return strings.Join(l, "\n") }
+
+	if _, err := Decode(join(lines[0], lines[2])); !errors.Is(err, ErrMissingLines) {
+		t.Errorf("middle line missing: expected ErrMissingLines, got %v", err)
+	}
+	if _, err := Decode(join(lines[1], lines[2])); !errors.Is(err, ErrMissingLines) {
+		t.Errorf("first line missing: expected ErrMissingLines, got %v", err)
+	}
+	if _, err := Decode(join(lines[0], lines[1])); !errors.Is(err, ErrMissingLines) {
+		t.Errorf("last line missing: expected ErrMissingLines, got %v", err)
+	}
+	if got, err := Decode(join(lines[2], lines[0], lines[1], lines[0])); err != nil || !bytes.Equal(got, share) {
+		t.Errorf("reordered and repeated lines: %v", err)
+	}
+
+	other, _ := Encode(append([]byte{2}, bytes.Repeat([]byte{0xCD}, 30)...))
+	otherLines := strings.Split(strings.TrimSuffix(other, "\n"), "\n")[1:]
+	if _, err := Decode(join(lines[0], otherLines[1], lines[1], lines[2])); !errors.Is(err, ErrInvalidPaper) {
+		t.Errorf("mixed shares: expected ErrInvalidPaper, got %v", err)
+	}
+	if _, err := Decode(join(lines[0], otherLines[1], lines[2])); !errors.Is(err, shamir.ErrIntegrityCheckFailed) {
+		t.Errorf("spliced line: expected ErrIntegrityCheckFailed, got %v", err)
+	}
+	if _, err := Decode("# nothing here\n"); !errors.Is(err, ErrInvalidPaper) {
+		t.Errorf("no lines: expected ErrInvalidPaper, got %v", err)
+	}
+}
+
+func TestEncodeRejects(t *testing.T) {
+	if _, err := Encode([]byte{0, 1, 2}); !errors.Is(err, ErrInvalidPaper) {
+		t.Errorf("zero index: expected ErrInvalidPaper, got %v", err)
+	}
+	if _, err := Encode([]byte{1}); !errors.Is(err, shamir.ErrTooShort) {
+		t.Errorf("short share: expected ErrTooShort, got %v", err)
+	}
+	if _, err := Encode(append([]byte{1}, make([]byte, 8000)...)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("long share: expected ErrTooLong, got %v", err)
+	}
+}
+
+func TestReedSolomon(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 2000; trial++ {
+		msg := make([]byte, 3+rng.Intn(headerSymbols+dataSymbols-2))
+		for i := range msg {
+			msg[i] = byte(rng.Intn(32))
+		}
+		word := append(append([]byte(nil), msg...), rsEncode(msg)...)
+		if _, clean := syndromes(word); !clean {
+			t.Fatalf("encoded word %v is not a codeword", word)
+		}
+		want := append([]byte(nil), word...)
+
+		errs := rng.Intn(paritySymbols/2 + 1)
+		for _, p := range rng.Perm(len(word))[:errs] {
+			word[p] ^= byte(1 + rng.Intn(31))
+		}
+		positions, ok := rsCorrect(word)
+		if !ok || !bytes.Equal(word, want) || len(positions) != errs {
+			t.Fatalf("%d errors: ok %v, positions %v, word %v, want %v", errs, ok, positions, word, want)
+		}
+	}
+}
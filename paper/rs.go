@@ -0,0 +1,190 @@
+package paper
+
+// Reed-Solomon coding over GF(32), one field element per base32 character.
+//
+// The field is GF(2)[x]/(x^5 + x^2 + 1), in which x is primitive. Codewords
+// have paritySymbols check symbols and at most 31 symbols in all; the
+// generator polynomial has roots α^1 through α^paritySymbols, so a codeword
+// corrects up to paritySymbols/2 wrong characters.
+
+const gfPoly = 0x25 // x^5 + x^2 + 1
+
+// The tables are built in a variable initializer rather than init so that
+// generator, which depends on them, sees them filled.
+var gfExp, gfLog = func() (exp, log [32]byte) {
+	x := byte(1)
+	for i := 0; i < 31; i++ {
+		exp[i] = x
+		log[x] = byte(i)
+		x <<= 1
+		if x&0x20 != 0 {
+			x ^= gfPoly
+		}
+	}
+	exp[31] = exp[0]
+	return exp, log
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])+int(gfLog[b]))%31]
+}
+
+func gfInv(a byte) byte {
+	return gfExp[(31-int(gfLog[a]))%31]
+}
+
+// gfPow returns α^e.
+func gfPow(e int) byte {
+	return gfExp[((e%31)+31)%31]
+}
+
+// generator is the generator polynomial, highest degree first.
+var generator = func() []byte {
+	g := []byte{1}
+	for i := 1; i <= paritySymbols; i++ {
+		// g *= (x - α^i)
+		next := make([]byte, len(g)+1)
+		for j, c := range g {
+			next[j] ^= c
+			next[j+1] ^= gfMul(c, gfPow(i))
+		}
+		g = next
+	}
+	return g
+}()
+
+// rsEncode returns the parity symbols for msg, whose symbols are the
+// coefficients of the message polynomial, highest degree first.
+func rsEncode(msg []byte) []byte {
+	rem := make([]byte, paritySymbols)
+	for _, m := range msg {
+		factor := m ^ rem[0]
+		copy(rem, rem[1:])
+		rem[paritySymbols-1] = 0
+		for j := range rem {
+			rem[j] ^= gfMul(generator[j+1], factor)
+		}
+	}
+	return rem
+}
+
+// syndromes evaluates the received word at the roots of the generator. They
+// are all zero exactly when the word is a codeword.
+func syndromes(word []byte) ([paritySymbols]byte, bool) {
+	var s [paritySymbols]byte
+	clean := true
+	for i := range s {
+		root := gfPow(i + 1)
+		var v byte
+		for _, c := range word {
+			v = gfMul(v, root) ^ c
+		}
+		s[i] = v
+		clean = clean && v == 0
+	}
+	return s, clean
+}
+
+// rsCorrect corrects word in place and returns the positions it changed, or
+// false if the word has more errors than the code can correct.
+func rsCorrect(word []byte) ([]int, bool) {
+	s, clean := syndromes(word)
+	if clean {
+		return nil, true
+	}
+
+	// Berlekamp-Massey: the error locator Λ(x), lowest degree first.
+	lambda := []byte{1}
+	prev := []byte{1}
+	l, m, b := 0, 1, byte(1)
+	for n := 0; n < paritySymbols; n++ {
+		d := s[n]
+		for i := 1; i <= l && i < len(lambda); i++ {
+			d ^= gfMul(lambda[i], s[n-i])
+		}
+		if d == 0 {
+			m++
+			continue
+		}
+		coef := gfMul(d, gfInv(b))
+		next := append([]byte(nil), lambda...)
+		for len(next) < len(prev)+m {
+			next = append(next, 0)
+		}
+		for i, p := range prev {
+			next[i+m] ^= gfMul(coef, p)
+		}
+		if 2*l <= n {
+			prev, l, b, m = lambda, n+1-l, d, 1
+		} else {
+			m++
+		}
+		lambda = next
+	}
+	for len(lambda) > 1 && lambda[len(lambda)-1] == 0 {
+		lambda = lambda[:len(lambda)-1]
+	}
+	if l > paritySymbols/2 || len(lambda)-1 != l {
+		return nil, false
+	}
+
+	// Chien search: position p (from the start of word) has locator
+	// X = α^(len-1-p), and is in error if Λ(X⁻¹) = 0.
+	n := len(word)
+	var positions []int
+	for p := 0; p < n; p++ {
+		xinv := gfPow(-(n - 1 - p))
+		if evalLow(lambda, xinv) == 0 {
+			positions = append(positions, p)
+		}
+	}
+	if len(positions) != l {
+		return nil, false
+	}
+
+	// Forney: Ω(x) = S(x)Λ(x) mod x^paritySymbols, and the error value at X
+	// is Ω(X⁻¹) / Λ'(X⁻¹) for generator roots starting at α^1.
+	omega := make([]byte, paritySymbols)
+	for i := range omega {
+		for j := 0; j <= i && j < len(lambda); j++ {
+			omega[i] ^= gfMul(lambda[j], s[i-j])
+		}
+	}
+	for _, p := range positions {
+		xinv := gfPow(-(n - 1 - p))
+		var deriv byte // Λ'(x) keeps the odd terms in characteristic 2
+		for i := 1; i < len(lambda); i += 2 {
+			deriv ^= gfMul(lambda[i], gfPowOf(xinv, i-1))
+		}
+		if deriv == 0 {
+			return nil, false
+		}
+		word[p] ^= gfMul(evalLow(omega, xinv), gfInv(deriv))
+	}
+
+	if _, clean := syndromes(word); !clean {
+		return nil, false
+	}
+	return positions, true
+}
+
+// evalLow evaluates a polynomial given lowest degree first.
+func evalLow(poly []byte, x byte) byte {
+	var v byte
+	for i := len(poly) - 1; i >= 0; i-- {
+		v = gfMul(v, x) ^ poly[i]
+	}
+	return v
+}
+
+// gfPowOf returns x^e.
+func gfPowOf(x byte, e int) byte {
+	v := byte(1)
+	for range e {
+		v = gfMul(v, x)
+	}
+	return v
+}
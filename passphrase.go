@@ -0,0 +1,245 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/morizta/go-shamir/internal/argon2"
+	"github.com/morizta/go-shamir/internal/chacha20poly1305"
+)
+
+// Passphrase-protected shares.
+//
+// SplitWithPassphrase encrypts every share under its custodian's passphrase,
+// so a share file that is copied or stolen is useless without the passphrase
+// as well. It is the Recipient mechanism of SplitEncrypted with a passphrase
+// in place of a public key, for custodians who have no key pair to manage.
+//
+// Passphrase-protected share format:
+//
+//	[3 bytes]  magic 0x00 'S' 'W'
+//	[1 byte]   format version (1)
+//	[1 byte]   x-coordinate of the share, so a prompt can name it
+//	[4 bytes]  Argon2id passes, big-endian
+//	[4 bytes]  Argon2id memory in KiB, big-endian
+//	[1 byte]   Argon2id lanes
+//	[16 bytes] salt
+//	[24 bytes] nonce
+//	[n bytes]  XChaCha20-Poly1305 ciphertext and tag of the enveloped share
+//
+// The key is Argon2id of the passphrase and salt with the recorded cost; the
+// header is authenticated.
+
+// passphraseMagic identifies a passphrase-protected share.
+var passphraseMagic = [3]byte{0x00, 'S', 'W'}
+
+const (
+	passphraseFormatVersion = 1
+	passphraseSaltSize      = 16
+	passphraseHeaderSize    = len(passphraseMagic) + 1 + 1 + 4 + 4 + 1 + passphraseSaltSize + chacha20poly1305.NonceSizeX
+
+	// passphraseAttempts is how often CombineWithPassphrases asks for the
+	// passphrase of a share before giving up.
+	passphraseAttempts = 3
+
+	// maxPassphraseMemory bounds the memory a share may ask Argon2id for, in
+	// KiB, so that a forged header cannot exhaust the combining machine.
+	maxPassphraseMemory = 4 << 20
+	maxPassphraseTime   = 64
+)
+
+// PassphraseParams are the Argon2id cost parameters for deriving share keys
+// from passphrases. Raising them slows down guessing as much as unlocking.
+type PassphraseParams struct {
+	Time    uint32 // Passes over memory
+	Memory  uint32 // Memory in KiB
+	Threads uint8  // Lanes, computed in parallel
+}
+
+// DefaultPassphraseParams are the parameters SplitWithPassphrase uses, the
+// second recommended option of RFC 9106: 3 passes over 64 MiB in 4 lanes.
+var DefaultPassphraseParams = PassphraseParams{Time: 3, Memory: 64 << 10, Threads: 4}
+
+func (p PassphraseParams) validate() error {
+	if p.Time < 1 || p.Time > maxPassphraseTime {
+		return NewValidationError("time", int(p.Time), "shamir: Argon2id passes must be between 1 and 64")
+	}
+	if p.Threads < 1 {
+		return NewValidationError("threads", int(p.Threads), "shamir: Argon2id needs at least one lane")
+	}
+	if p.Memory < 8*uint32(p.Threads) || p.Memory > maxPassphraseMemory {
+		return NewValidationError("memory", int(p.Memory), "shamir: Argon2id memory must be between 8 KiB per lane and 4 GiB")
+	}
+	return nil
+}
+
+// SplitWithPassphrase splits a secret into one share per passphrase and
+// returns each share encrypted under its passphrase, with
+// DefaultPassphraseParams: the i-th share is protected by passphrases[i].
+// parts must equal len(passphrases). The plaintext shares are enveloped
+// shares with a common set ID, and are wiped once encrypted. Unlock the shares
+// with UnlockShare, or combine them directly with CombineWithPassphrases.
+func SplitWithPassphrase(secret []byte, parts, threshold int, passphrases [][]byte) ([][]byte, error) {
+	if len(passphrases) != parts {
+		return nil, NewValidationError("passphrases", len(passphrases), "shamir: need exactly one passphrase per share")
+	}
+	recipients := make([]Recipient, len(passphrases))
+	for i, passphrase := range passphrases {
+		r, err := NewPassphraseRecipient(passphrase, DefaultPassphraseParams)
+		if err != nil {
+			return nil, fmt.Errorf("passphrase %d: %w", i, err)
+		}
+		recipients[i] = r
+	}
+	return SplitEncrypted(secret, parts, threshold, recipients)
+}
+
+// PassphraseRecipient encrypts shares under a passphrase. Use it with
+// SplitEncrypted to mix passphrase custodians with key-holding ones, or to
+// choose other Argon2id parameters than SplitWithPassphrase does.
+type PassphraseRecipient struct {
+	passphrase []byte
+	params     PassphraseParams
+}
+
+// NewPassphraseRecipient returns a recipient for a non-empty passphrase.
+func NewPassphraseRecipient(passphrase []byte, params PassphraseParams) (*PassphraseRecipient, error) {
+	if len(passphrase) == 0 {
+		return nil, NewValidationError("passphrases", 0, "shamir: passphrase cannot be empty")
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	return &PassphraseRecipient{passphrase: bytes.Clone(passphrase), params: params}, nil
+}
+
+// EncryptShare encrypts share under the passphrase with a fresh salt.
+func (r *PassphraseRecipient) EncryptShare(share []byte) ([]byte, error) {
+	var index byte
+	if len(share) > 0 {
+		index = share[0]
+		if IsEnvelope(share) {
+			s, err := ParseShare(share)
+			if err != nil {
+				return nil, err
+			}
+			index = s.Index
+		}
+	}
+
+	header := make([]byte, passphraseHeaderSize)
+	copy(header, passphraseMagic[:])
+	header[3] = passphraseFormatVersion
+	header[4] = index
+	binary.BigEndian.PutUint32(header[5:], r.params.Time)
+	binary.BigEndian.PutUint32(header[9:], r.params.Memory)
+	header[13] = r.params.Threads
+	if _, err := rand.Read(header[14:]); err != nil {
+		return nil, fmt.Errorf("shamir: failed to generate salt: %w", err)
+	}
+
+	key := r.params.deriveKey(r.passphrase, header)
+	defer secureZeroBytes(key)
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(header)+len(share)+aead.Overhead())
+	return aead.Seal(append(out, header...), header[passphraseHeaderSize-aead.NonceSize():], share, header), nil
+}
+
+// deriveKey derives the share key from passphrase and the salt in header.
+func (p PassphraseParams) deriveKey(passphrase, header []byte) []byte {
+	salt := header[14 : 14+passphraseSaltSize]
+	return argon2.IDKey(passphrase, salt, p.Time, p.Memory, p.Threads, chacha20poly1305.KeySize)
+}
+
+// PassphraseShareIndex returns the x-coordinate recorded in the clear in a
+// passphrase-protected share, so that a prompt can say which share it asks
+// for. It returns ErrInvalidSealed if ciphertext is not such a share.
+func PassphraseShareIndex(ciphertext []byte) (byte, error) {
+	if len(ciphertext) < passphraseHeaderSize || !bytes.HasPrefix(ciphertext, passphraseMagic[:]) {
+		return 0, ErrInvalidSealed
+	}
+	return ciphertext[4], nil
+}
+
+// UnlockShare decrypts a passphrase-protected share. It returns
+// ErrWrongPassphrase if the passphrase is wrong or the share was tampered
+// with, which cannot be told apart, and ErrInvalidSealed if the ciphertext is
+// not a passphrase-protected share or asks for implausible Argon2id costs.
+func UnlockShare(ciphertext, passphrase []byte) ([]byte, error) {
+	if len(ciphertext) < passphraseHeaderSize || !bytes.HasPrefix(ciphertext, passphraseMagic[:]) {
+		return nil, ErrInvalidSealed
+	}
+	if v := ciphertext[len(passphraseMagic)]; v != passphraseFormatVersion {
+		return nil, fmt.Errorf("%w: passphrase-protected share version %d", ErrUnsupportedVersion, v)
+	}
+	header := ciphertext[:passphraseHeaderSize]
+	params := PassphraseParams{
+		Time:    binary.BigEndian.Uint32(header[5:]),
+		Memory:  binary.BigEndian.Uint32(header[9:]),
+		Threads: header[13],
+	}
+	if params.validate() != nil {
+		return nil, ErrInvalidSealed
+	}
+
+	key := params.deriveKey(passphrase, header)
+	defer secureZeroBytes(key)
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	share, err := aead.Open(nil, header[passphraseHeaderSize-aead.NonceSize():], ciphertext[passphraseHeaderSize:], header)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return share, nil
+}
+
+// PassphraseFunc supplies the passphrase for the share with x-coordinate
+// index. attempt counts from 1 and increases each time the previous
+// passphrase for the same share was wrong, so an interactive prompt can say
+// so. An error from the function aborts the combine.
+type PassphraseFunc func(index byte, attempt int) ([]byte, error)
+
+// CombineWithPassphrases unlocks passphrase-protected shares and combines
+// them with CombineWithOptions and opts. passphrase is asked for each share in
+// turn, up to three times while the answer is wrong. The unlocked shares never leave this function and are
+// wiped before it returns.
+func CombineWithPassphrases(parts [][]byte, passphrase PassphraseFunc, opts ...Option) ([]byte, error) {
+	if passphrase == nil {
+		return nil, NewValidationError("passphrase", 0, "shamir: passphrase function cannot be nil")
+	}
+	shares := make([][]byte, 0, len(parts))
+	defer func() {
+		for _, share := range shares {
+			secureZeroBytes(share)
+		}
+	}()
+
+	for i, part := range parts {
+		index, err := PassphraseShareIndex(part)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", i, err)
+		}
+		for attempt := 1; ; attempt++ {
+			pass, err := passphrase(index, attempt)
+			if err != nil {
+				return nil, fmt.Errorf("share %d: %w", i, err)
+			}
+			share, err := UnlockShare(part, pass)
+			if err == nil {
+				shares = append(shares, share)
+				break
+			}
+			if err != ErrWrongPassphrase || attempt == passphraseAttempts {
+				return nil, fmt.Errorf("share %d: %w", i, err)
+			}
+		}
+	}
+	return CombineWithOptions(shares, opts...)
+}
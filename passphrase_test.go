@@ -0,0 +1,138 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// fastPassphraseParams keep the tests quick; never use them for real shares.
+var fastPassphraseParams = PassphraseParams{Time: 1, Memory: 64, Threads: 1}
+
+func TestSplitWithPassphrase(t *testing.T) {
+	secret := []byte("useless without the passphrase")
+	passphrases := [][]byte{[]byte("correct horse"), []byte("battery staple"), []byte("tr0ub4dor&3")}
+
+	locked, err := SplitWithPassphrase(secret, 3, 2, passphrases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, share := range locked {
+		if format := shareFormat(share); format != "passphrase-protected" {
+			t.Fatalf("share %d: format %q", i, format)
+		}
+		if index, err := PassphraseShareIndex(share); err != nil || index != byte(i+1) {
+			t.Fatalf("share %d: index %d, %v", i, index, err)
+		}
+	}
+
+	unlocked, err := UnlockShare(locked[2], passphrases[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := ParseShare(unlocked); err != nil || s.Index != 3 {
+		t.Fatalf("unlocked share: %v", err)
+	}
+	if _, err := UnlockShare(locked[2], passphrases[1]); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("wrong passphrase: expected ErrWrongPassphrase, got %v", err)
+	}
+
+	got, err := CombineWithPassphrases(locked[:2], func(index byte, attempt int) ([]byte, error) {
+		return passphrases[index-1], nil
+	})
+	if err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("CombineWithPassphrases: %v", err)
+	}
+
+	// Combine cannot read the shares before they are unlocked.
+	var merr *MigrationError
+	if _, err := Combine(locked[:2]); !errors.As(err, &merr) || merr.Format != "passphrase-protected" {
+		t.Errorf("Combine: expected a MigrationError, got %v", err)
+	}
+}
+
+func TestCombineWithPassphrasesPrompt(t *testing.T) {
+	secret := []byte("asked for at the prompt")
+	recipients := make([]Recipient, 3)
+	for i := range recipients {
+		r, err := NewPassphraseRecipient(fmt.Appendf(nil, "passphrase %d", i+1), fastPassphraseParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recipients[i] = r
+	}
+	locked, err := SplitEncrypted(secret, 3, 2, recipients)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The custodian of share 3 mistypes once.
+	var asked []string
+	prompt := func(index byte, attempt int) ([]byte, error) {
+		asked = append(asked, fmt.Sprintf("%d/%d", index, attempt))
+		if index == 3 && attempt == 1 {
+			return []byte("passphrase 33"), nil
+		}
+		return fmt.Appendf(nil, "passphrase %d", index), nil
+	}
+	got, err := CombineWithPassphrases([][]byte{locked[2], locked[0]}, prompt)
+	if err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("combine: %v", err)
+	}
+	if fmt.Sprint(asked) != "[3/1 3/2 1/1]" {
+		t.Errorf("prompts %v", asked)
+	}
+
+	t.Run("too many attempts", func(t *testing.T) {
+		attempts := 0
+		wrong := func(byte, int) ([]byte, error) { attempts++; return []byte("guess"), nil }
+		if _, err := CombineWithPassphrases(locked[:2], wrong); !errors.Is(err, ErrWrongPassphrase) || attempts != 3 {
+			t.Errorf("expected ErrWrongPassphrase after 3 attempts, got %v after %d", err, attempts)
+		}
+	})
+
+	t.Run("prompt cancelled", func(t *testing.T) {
+		errCancelled := errors.New("cancelled")
+		cancel := func(byte, int) ([]byte, error) { return nil, errCancelled }
+		if _, err := CombineWithPassphrases(locked[:2], cancel); !errors.Is(err, errCancelled) {
+			t.Errorf("expected the prompt's error, got %v", err)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		// Every header field is authenticated, including the ones read in
+		// the clear; out-of-range costs are refused before any derivation.
+		for _, offset := range []int{4, 12, 20, passphraseHeaderSize - 1, len(locked[0]) - 1} {
+			tampered := bytes.Clone(locked[0])
+			tampered[offset] ^= 1
+			if _, err := UnlockShare(tampered, []byte("passphrase 1")); !errors.Is(err, ErrWrongPassphrase) {
+				t.Errorf("offset %d: expected ErrWrongPassphrase, got %v", offset, err)
+			}
+		}
+		huge := bytes.Clone(locked[0])
+		huge[9] = 0xFF
+		if _, err := UnlockShare(huge, []byte("passphrase 1")); !errors.Is(err, ErrInvalidSealed) {
+			t.Errorf("huge memory cost: expected ErrInvalidSealed, got %v", err)
+		}
+		if _, err := UnlockShare(locked[0][:passphraseHeaderSize-1], []byte("passphrase 1")); !errors.Is(err, ErrInvalidSealed) {
+			t.Errorf("truncated: expected ErrInvalidSealed, got %v", err)
+		}
+	})
+}
+
+func TestSplitWithPassphraseValidation(t *testing.T) {
+	var verr *ValidationError
+	if _, err := SplitWithPassphrase([]byte("s"), 3, 2, [][]byte{[]byte("a"), []byte("b")}); !errors.As(err, &verr) || verr.Field != "passphrases" {
+		t.Errorf("passphrase count: expected a ValidationError, got %v", err)
+	}
+	if _, err := SplitWithPassphrase([]byte("s"), 2, 2, [][]byte{[]byte("a"), nil}); !errors.As(err, &verr) || verr.Field != "passphrases" {
+		t.Errorf("empty passphrase: expected a ValidationError, got %v", err)
+	}
+	if _, err := NewPassphraseRecipient([]byte("a"), PassphraseParams{Time: 1, Memory: 4, Threads: 1}); !errors.As(err, &verr) || verr.Field != "memory" {
+		t.Errorf("too little memory: expected a ValidationError, got %v", err)
+	}
+	if _, err := CombineWithPassphrases(nil, nil); !errors.As(err, &verr) {
+		t.Errorf("nil passphrase function: expected a ValidationError, got %v", err)
+	}
+}
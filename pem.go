@@ -0,0 +1,119 @@
+package shamir
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+)
+
+// SharePEMType is the PEM block type used for shares.
+const SharePEMType = "SHAMIR SHARE"
+
+// PEM header names written by EncodeSharePEM.
+const (
+	pemHeaderIndex     = "Index"
+	pemHeaderThreshold = "Threshold"
+	pemHeaderChecksum  = "Checksum"
+)
+
+// EncodeSharePEM encodes a raw or enveloped share as a
+// "-----BEGIN SHAMIR SHARE-----" block, so it can be stored alongside keys and
+// handled by PKI tooling. The block carries headers with the share's index,
+// its threshold (enveloped shares only, since raw shares do not record it) and
+// a CRC32 of the share in hexadecimal.
+func EncodeSharePEM(share []byte) ([]byte, error) {
+	headers := make(map[string]string, 3)
+
+	if IsEnvelope(share) {
+		s, err := ParseShare(share)
+		if err != nil {
+			return nil, err
+		}
+		secureZeroBytes(s.Payload)
+		headers[pemHeaderIndex] = strconv.Itoa(int(s.Index))
+		headers[pemHeaderThreshold] = strconv.Itoa(s.Threshold)
+	} else {
+		if err := validateShare(share); err != nil {
+			return nil, err
+		}
+		headers[pemHeaderIndex] = strconv.Itoa(int(share[0]))
+	}
+	headers[pemHeaderChecksum] = pemChecksum(share)
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:    SharePEMType,
+		Headers: headers,
+		Bytes:   share,
+	}), nil
+}
+
+// DecodeSharePEM decodes the first SHAMIR SHARE block in data and verifies it
+// against its headers. It returns the share and the remainder of data, so a
+// file holding several shares can be decoded by calling it repeatedly, as with
+// pem.Decode. Blocks of other types before the share are skipped.
+func DecodeSharePEM(data []byte) (share, rest []byte, err error) {
+	var block *pem.Block
+	for {
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, data, ErrInvalidPEM
+		}
+		if block.Type == SharePEMType {
+			break
+		}
+	}
+
+	if sum, ok := block.Headers[pemHeaderChecksum]; ok && sum != pemChecksum(block.Bytes) {
+		return nil, data, ErrIntegrityCheckFailed
+	}
+
+	index, threshold := 0, 0
+	if IsEnvelope(block.Bytes) {
+		s, err := ParseShare(block.Bytes)
+		if err != nil {
+			return nil, data, err
+		}
+		secureZeroBytes(s.Payload)
+		index, threshold = int(s.Index), s.Threshold
+	} else {
+		if err := validateShare(block.Bytes); err != nil {
+			return nil, data, err
+		}
+		index = int(block.Bytes[0])
+	}
+
+	if err := checkPEMHeader(block.Headers, pemHeaderIndex, index); err != nil {
+		return nil, data, err
+	}
+	if threshold > 0 {
+		if err := checkPEMHeader(block.Headers, pemHeaderThreshold, threshold); err != nil {
+			return nil, data, err
+		}
+	}
+
+	return block.Bytes, data, nil
+}
+
+// pemChecksum returns the CRC32 (IEEE) of share in hexadecimal.
+func pemChecksum(share []byte) string {
+	return hex.EncodeToString(binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(share)))
+}
+
+// checkPEMHeader verifies that an optional numeric header, if present, matches want.
+func checkPEMHeader(headers map[string]string, name string, want int) error {
+	value, ok := headers[name]
+	if !ok {
+		return nil
+	}
+	got, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%w: malformed %s header", ErrInvalidPEM, name)
+	}
+	if got != want {
+		return fmt.Errorf("%w: %s header %d does not match share (%d)", ErrInvalidPEM, name, got, want)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSharePEMRoundTrip(t *testing.T) {
+	secret := []byte("stored next to the TLS keys")
+
+	raw, err := Split(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enveloped, err := SplitKey(bytes.Repeat([]byte{9}, 32), AlgAES256GCM, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, shares := range map[string][][]byte{"raw": raw, "envelope": enveloped} {
+		t.Run(name, func(t *testing.T) {
+			// Concatenate all shares into one file, as a custodian bundle would.
+			var file []byte
+			for _, share := range shares {
+				block, err := EncodeSharePEM(share)
+				if err != nil {
+					t.Fatal(err)
+				}
+				file = append(file, block...)
+			}
+			if !strings.HasPrefix(string(file), "-----BEGIN SHAMIR SHARE-----") {
+				t.Fatalf("unexpected PEM output:\n%s", file)
+			}
+
+			rest := file
+			for i, want := range shares {
+				var got []byte
+				if got, rest, err = DecodeSharePEM(rest); err != nil {
+					t.Fatalf("share %d: %v", i, err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Fatalf("share %d mismatch", i)
+				}
+			}
+			if _, _, err := DecodeSharePEM(rest); err != ErrInvalidPEM {
+				t.Fatalf("expected ErrInvalidPEM after the last block, got %v", err)
+			}
+		})
+	}
+
+	t.Run("headers", func(t *testing.T) {
+		block, _ := EncodeSharePEM(enveloped[1])
+		for _, header := range []string{"Index: 2", "Threshold: 2", "Checksum: "} {
+			if !strings.Contains(string(block), header) {
+				t.Errorf("missing header %q in:\n%s", header, block)
+			}
+		}
+		block, _ = EncodeSharePEM(raw[0])
+		if strings.Contains(string(block), "Threshold:") {
+			t.Error("raw shares must not claim a threshold")
+		}
+	})
+}
+
+func TestSharePEMErrors(t *testing.T) {
+	shares, err := Split([]byte("secret"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := EncodeSharePEM(shares[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("mismatched index header", func(t *testing.T) {
+		forged := strings.Replace(string(block), "Index: 1", "Index: 3", 1)
+		if _, _, err := DecodeSharePEM([]byte(forged)); !errors.Is(err, ErrInvalidPEM) {
+			t.Fatalf("expected ErrInvalidPEM, got %v", err)
+		}
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		other, _ := EncodeSharePEM(shares[1])
+		// Graft the second share's body onto the first share's headers.
+		headers := string(block[:strings.Index(string(block), "\n\n")+2])
+		body := string(other[strings.Index(string(other), "\n\n")+2:])
+		if _, _, err := DecodeSharePEM([]byte(headers + body)); err != ErrIntegrityCheckFailed {
+			t.Fatalf("expected ErrIntegrityCheckFailed, got %v", err)
+		}
+	})
+
+	t.Run("skips other blocks", func(t *testing.T) {
+		data := append([]byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n"), block...)
+		got, _, err := DecodeSharePEM(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, shares[0]) {
+			t.Fatal("share mismatch")
+		}
+	})
+
+	t.Run("not pem", func(t *testing.T) {
+		if _, _, err := DecodeSharePEM([]byte("garbage")); err != ErrInvalidPEM {
+			t.Fatalf("expected ErrInvalidPEM, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package shamir
+
+import (
+	"fmt"
+)
+
+// Storage media recognised by PlanShares.
+const (
+	MediumPaper = "paper" // Printed or engraved, kept in a safe
+	MediumHSM   = "hsm"   // Hardware security module
+	MediumCloud = "cloud" // Cloud key management or secret storage
+)
+
+// mediumLoss holds the default probability that a share on each medium is lost
+// over the planning horizon (roughly a year). They are deliberately
+// conservative; supply measured figures through PlanCustodian.LossProbability.
+var mediumLoss = map[string]float64{
+	MediumPaper: 0.05,
+	MediumHSM:   0.01,
+	MediumCloud: 0.02,
+}
+
+// PlanCustodian describes a custodian and how their shares are stored.
+type PlanCustodian struct {
+	Name            string
+	Medium          string  // One of the Medium* constants, or any label if LossProbability is set
+	LossProbability float64 // Probability that all of this custodian's shares are lost; 0 uses the medium's default
+}
+
+// ShareAssignment is the number of shares planned for one custodian.
+type ShareAssignment struct {
+	Custodian       string
+	Medium          string
+	LossProbability float64
+	Shares          int
+}
+
+// SharePlan is a share assignment recommended by PlanShares.
+type SharePlan struct {
+	Threshold           int
+	Parts               int
+	Assignments         []ShareAssignment // In the order the custodians were given
+	RecoveryProbability float64           // Probability that at least Threshold shares survive
+}
+
+// PlanShares distributes parts shares among custodians, weighting by the risk
+// of each custodian's storage medium. Every custodian receives one share; the
+// remaining parts-len(custodians) shares go, one at a time, to whichever
+// custodian most improves the probability that at least threshold shares
+// survive, assuming custodians are lost independently. No custodian is given
+// threshold or more shares, so none can reconstruct alone.
+//
+// Pass the plan to SplitPlanned to generate the shares and manifest.
+func PlanShares(custodians []PlanCustodian, threshold, parts int) (*SharePlan, error) {
+	if err := validateSplitParams([]byte{0}, parts, threshold); err != nil {
+		return nil, err
+	}
+	if len(custodians) > parts {
+		return nil, NewValidationError("parts", parts, "shamir: parts must be at least the number of custodians")
+	}
+
+	plan := &SharePlan{Threshold: threshold, Parts: parts, Assignments: make([]ShareAssignment, len(custodians))}
+	seen := make(map[string]bool, len(custodians))
+	for i, c := range custodians {
+		if c.Name == "" || seen[c.Name] {
+			return nil, NewValidationError("custodian", i, "shamir: custodian names must be non-empty and unique")
+		}
+		seen[c.Name] = true
+
+		p := c.LossProbability
+		if p == 0 {
+			var ok bool
+			if p, ok = mediumLoss[c.Medium]; !ok {
+				return nil, fmt.Errorf("shamir: custodian %q: unknown medium %q requires a loss probability", c.Name, c.Medium)
+			}
+		}
+		if p < 0 || p >= 1 {
+			return nil, fmt.Errorf("shamir: custodian %q: loss probability must be in [0, 1)", c.Name)
+		}
+		plan.Assignments[i] = ShareAssignment{Custodian: c.Name, Medium: c.Medium, LossProbability: p, Shares: 1}
+	}
+
+	for extra := parts - len(custodians); extra > 0; extra-- {
+		best, bestProb := -1, -1.0
+		for i := range plan.Assignments {
+			a := &plan.Assignments[i]
+			if a.Shares+1 >= threshold {
+				continue
+			}
+			a.Shares++
+			p := recoveryProbability(plan.Assignments, threshold)
+			a.Shares--
+			if p > bestProb || (p == bestProb && a.LossProbability < plan.Assignments[best].LossProbability) {
+				best, bestProb = i, p
+			}
+		}
+		if best < 0 {
+			return nil, NewValidationError("parts", parts,
+				"shamir: too many shares for the custodians without letting one reconstruct alone")
+		}
+		plan.Assignments[best].Shares++
+	}
+
+	plan.RecoveryProbability = recoveryProbability(plan.Assignments, threshold)
+	return plan, nil
+}
+
+// recoveryProbability returns the probability that at least threshold shares
+// survive when each custodian's shares are lost together and independently.
+func recoveryProbability(assignments []ShareAssignment, threshold int) float64 {
+	total := 0
+	for _, a := range assignments {
+		total += a.Shares
+	}
+	dist := make([]float64, total+1) // dist[n]: probability that n shares survive
+	dist[0] = 1
+	for _, a := range assignments {
+		next := make([]float64, total+1)
+		for n, p := range dist {
+			if p == 0 {
+				continue
+			}
+			next[n] += p * a.LossProbability
+			next[n+a.Shares] += p * (1 - a.LossProbability)
+		}
+		dist = next
+	}
+
+	sum := 0.0
+	for n := threshold; n <= total; n++ {
+		sum += dist[n]
+	}
+	return sum
+}
+
+// SplitPlanned splits a secret as planned by PlanShares. The returned manifest
+// lists every share with its custodian and storage medium; a custodian holding
+// several shares appears once per share. Combine with CombineEscrow.
+func SplitPlanned(secret []byte, plan *SharePlan, name string) (*EscrowSharing, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("shamir: share plan is required")
+	}
+
+	var custodians, media []string
+	for _, a := range plan.Assignments {
+		for i := 0; i < a.Shares; i++ {
+			custodians = append(custodians, a.Custodian)
+			media = append(media, a.Medium)
+		}
+	}
+	if len(custodians) != plan.Parts {
+		return nil, NewValidationError("parts", plan.Parts, "shamir: plan assignments do not add up to parts")
+	}
+
+	setID, err := newSetID()
+	if err != nil {
+		return nil, err
+	}
+	o := newOptions([]Option{WithParts(plan.Parts), WithThreshold(plan.Threshold), WithLabels(custodians...)})
+	created := creationTime()
+	shares, err := splitEnvelopes(secret, o, Share{SetID: setID, CreatedAt: created})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := newManifest(setID, name, plan.Threshold, custodians, shares)
+	manifest.CreatedAt = created
+	for i := range manifest.Custodians {
+		manifest.Custodians[i].Medium = media[i]
+	}
+	return &EscrowSharing{Manifest: manifest, Shares: shares}, nil
+}
@@ -0,0 +1,130 @@
+package shamir
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestPlanShares(t *testing.T) {
+	custodians := []PlanCustodian{
+		{Name: "vault", Medium: MediumHSM},
+		{Name: "safe", Medium: MediumPaper},
+		{Name: "kms", Medium: MediumCloud},
+		{Name: "drawer", Medium: "usb", LossProbability: 0.3},
+	}
+
+	plan, err := PlanShares(custodians, 3, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, a := range plan.Assignments {
+		total += a.Shares
+		if a.Shares >= plan.Threshold {
+			t.Errorf("%s holds %d shares, enough to reconstruct alone", a.Custodian, a.Shares)
+		}
+	}
+	if total != 6 {
+		t.Fatalf("assigned %d shares, want 6", total)
+	}
+	// The extra shares belong with the most reliable media, not the USB stick
+	if plan.Assignments[0].Shares != 2 || plan.Assignments[3].Shares != 1 {
+		t.Errorf("unexpected assignment: %+v", plan.Assignments)
+	}
+
+	flat, err := PlanShares(custodians, 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.RecoveryProbability <= flat.RecoveryProbability {
+		t.Errorf("extra shares should improve recovery: %v <= %v", plan.RecoveryProbability, flat.RecoveryProbability)
+	}
+}
+
+func TestRecoveryProbability(t *testing.T) {
+	// Two custodians with one share each, both needed: (1-p1)(1-p2)
+	got := recoveryProbability([]ShareAssignment{
+		{LossProbability: 0.1, Shares: 1},
+		{LossProbability: 0.2, Shares: 1},
+	}, 2)
+	if want := 0.9 * 0.8; math.Abs(got-want) > 1e-12 {
+		t.Errorf("recoveryProbability = %v, want %v", got, want)
+	}
+
+	// Three custodians, any two: 1 - P(at most one survives)
+	p := 0.1
+	got = recoveryProbability([]ShareAssignment{
+		{LossProbability: p, Shares: 1}, {LossProbability: p, Shares: 1}, {LossProbability: p, Shares: 1},
+	}, 2)
+	if want := 3*p*(1-p)*(1-p) + (1-p)*(1-p)*(1-p); math.Abs(got-want) > 1e-12 {
+		t.Errorf("recoveryProbability = %v, want %v", got, want)
+	}
+}
+
+func TestPlanSharesValidation(t *testing.T) {
+	two := []PlanCustodian{{Name: "a", Medium: MediumHSM}, {Name: "b", Medium: MediumPaper}}
+	tests := []struct {
+		name       string
+		custodians []PlanCustodian
+		threshold  int
+		parts      int
+	}{
+		{"fewer parts than custodians", two, 2, 1},
+		{"unknown medium", []PlanCustodian{{Name: "a", Medium: "tape"}, {Name: "b", Medium: MediumHSM}}, 2, 2},
+		{"bad probability", []PlanCustodian{{Name: "a", LossProbability: 1}, {Name: "b", Medium: MediumHSM}}, 2, 2},
+		{"duplicate name", []PlanCustodian{{Name: "a", Medium: MediumHSM}, {Name: "a", Medium: MediumHSM}}, 2, 2},
+		{"single custodian could reconstruct", two, 2, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := PlanShares(tt.custodians, tt.threshold, tt.parts); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestSplitPlanned(t *testing.T) {
+	secret := []byte("planned secret")
+	plan, err := PlanShares([]PlanCustodian{
+		{Name: "vault", Medium: MediumHSM},
+		{Name: "safe", Medium: MediumPaper},
+		{Name: "kms", Medium: MediumCloud},
+	}, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sharing, err := SplitPlanned(secret, plan, "planned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sharing.Shares) != 5 || len(sharing.Manifest.Custodians) != 5 {
+		t.Fatalf("got %d shares, %d manifest entries", len(sharing.Shares), len(sharing.Manifest.Custodians))
+	}
+
+	held := make(map[string]int)
+	for i, entry := range sharing.Manifest.Custodians {
+		held[entry.Custodian]++
+		if entry.Medium == "" {
+			t.Errorf("entry %d has no medium", i)
+		}
+		if holder, err := sharing.Manifest.Custodian(sharing.Shares[i]); err != nil || holder != entry.Custodian {
+			t.Errorf("share %d: custodian %q, %v", i, holder, err)
+		}
+	}
+	for _, a := range plan.Assignments {
+		if held[a.Custodian] != a.Shares {
+			t.Errorf("%s holds %d shares, plan says %d", a.Custodian, held[a.Custodian], a.Shares)
+		}
+	}
+
+	reconstructed, err := CombineEscrow(sharing.Manifest, sharing.Shares[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("reconstruction failed")
+	}
+}
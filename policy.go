@@ -0,0 +1,127 @@
+package shamir
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Combine-time policy.
+//
+// Deployments often have rules about when a secret may be reconstructed: only
+// inside a maintenance window, only with a ticket approved, only from an
+// attested location. Rather than wrapping every call site, embedders register
+// a PolicyHook once; it runs on every reconstruction after the shares have
+// been validated and before any interpolation, and its error aborts the
+// combine. Hooks see share metadata only, never share values.
+
+// CombineMetadata describes a pending reconstruction to a PolicyHook.
+type CombineMetadata struct {
+	Shares     int             // Number of shares presented
+	Indices    []byte          // Their x-coordinates, in the order given
+	SecretSize int             // Secret length in bytes; -1 for share streams
+	Threshold  int             // Threshold recorded with the shares; 0 if unknown
+	Enveloped  bool            // Whether the shares are enveloped; the fields below are set only then
+	SetID      SetID           // Split identifier
+	Purpose    string          // Bound purpose, see WithPurpose
+	Algorithm  string          // Key algorithm, see SplitKey
+	Labels     []string        // Per-share labels in the order given, see WithLabels
+	NotBefore  time.Time       // Latest start of the shares' validity windows, zero if unbounded
+	NotAfter   time.Time       // Earliest end of the shares' validity windows, zero if unbounded
+	Context    context.Context // Context passed to CombineContext; nil otherwise
+}
+
+// PolicyHook decides whether a reconstruction may proceed. Returning an error
+// aborts it with an error matching both ErrPolicyDenied and the hook's error.
+type PolicyHook func(meta CombineMetadata) error
+
+var policyHooks struct {
+	sync.RWMutex
+	hooks []PolicyHook
+}
+
+// RegisterPolicyHook adds a hook consulted before every reconstruction in
+// this package: Combine and all its variants, key and seal recovery, escrow
+// and quorum combines, and the streaming joiners. Hooks run in registration
+// order, before any supplied with WithPolicyHook, and must be safe for
+// concurrent use. It is meant to be called during program initialisation.
+func RegisterPolicyHook(hook PolicyHook) {
+	if hook == nil {
+		panic("shamir: nil policy hook")
+	}
+	policyHooks.Lock()
+	defer policyHooks.Unlock()
+	policyHooks.hooks = append(policyHooks.hooks, hook)
+}
+
+// WithPolicyHook adds a hook that CombineWithOptions and CombineContext
+// consult after the registered hooks.
+func WithPolicyHook(hook PolicyHook) Option {
+	return func(o *options) {
+		if hook != nil {
+			o.policyHooks = append(o.policyHooks, hook)
+		}
+	}
+}
+
+// checkPolicy runs the registered hooks, then extra, on meta.
+func checkPolicy(meta CombineMetadata, extra []PolicyHook) error {
+	policyHooks.RLock()
+	hooks := policyHooks.hooks
+	policyHooks.RUnlock()
+	if len(hooks) == 0 && len(extra) == 0 {
+		return nil
+	}
+
+	for _, hook := range append(hooks[:len(hooks):len(hooks)], extra...) {
+		// Each hook gets its own copies so none can alter what the next sees.
+		m := meta
+		m.Indices = append([]byte(nil), meta.Indices...)
+		m.Labels = append([]string(nil), meta.Labels...)
+		if err := hook(m); err != nil {
+			return fmt.Errorf("%w: %w", ErrPolicyDenied, err)
+		}
+	}
+	return nil
+}
+
+// envelopeMetadata returns the policy metadata recorded in decoded shares.
+func envelopeMetadata(shares []*Share) *CombineMetadata {
+	first := shares[0]
+	meta := &CombineMetadata{
+		Threshold: first.Threshold,
+		Enveloped: true,
+		SetID:     first.SetID,
+		Purpose:   first.Purpose,
+		Algorithm: first.Algorithm,
+	}
+	for i, s := range shares {
+		if !s.NotBefore.IsZero() && s.NotBefore.After(meta.NotBefore) {
+			meta.NotBefore = s.NotBefore
+		}
+		if !s.NotAfter.IsZero() && (meta.NotAfter.IsZero() || s.NotAfter.Before(meta.NotAfter)) {
+			meta.NotAfter = s.NotAfter
+		}
+		if s.Label != "" && meta.Labels == nil {
+			meta.Labels = make([]string, len(shares))
+		}
+		if meta.Labels != nil {
+			meta.Labels[i] = s.Label
+		}
+	}
+	return meta
+}
+
+// checkPolicy runs the policy hooks for a combine of shares at xCoords.
+func (e engine) checkPolicy(xCoords []byte, secretLen int) error {
+	var meta CombineMetadata
+	if e.meta != nil {
+		meta = *e.meta
+	}
+	meta.Shares = len(xCoords)
+	meta.Indices = xCoords
+	meta.SecretSize = secretLen
+	meta.Context = e.ctx
+	return checkPolicy(meta, e.hooks)
+}
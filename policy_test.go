@@ -0,0 +1,172 @@
+package shamir
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// registerTestPolicyHook registers hook for the duration of the test.
+func registerTestPolicyHook(t *testing.T, hook PolicyHook) {
+	t.Helper()
+	policyHooks.Lock()
+	saved := policyHooks.hooks
+	policyHooks.Unlock()
+	t.Cleanup(func() {
+		policyHooks.Lock()
+		policyHooks.hooks = saved
+		policyHooks.Unlock()
+	})
+	RegisterPolicyHook(hook)
+}
+
+var errOutsideWindow = errors.New("outside maintenance window")
+
+func TestPolicyHookDeniesCombine(t *testing.T) {
+	secret := []byte("policy protected")
+	raw, err := Split(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checked, err := SplitWithIntegrity(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enveloped, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithLabels("alice", "bob", "carol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault, err := SplitVault(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	registerTestPolicyHook(t, func(CombineMetadata) error {
+		calls++
+		return errOutsideWindow
+	})
+
+	combines := map[string]func() error{
+		"Combine":              func() error { _, err := Combine(raw[:2]); return err },
+		"CombineWithIntegrity": func() error { _, err := CombineWithIntegrity(checked[:2]); return err },
+		"CombineWithOptions":   func() error { _, err := CombineWithOptions(enveloped[:2]); return err },
+		"CombineSecure":        func() error { _, err := CombineSecure(append([][]byte(nil), raw[:2]...), 2); return err },
+		"CombineVault":         func() error { _, err := CombineVault(vault[:2]); return err },
+		"CombineWithCorrection": func() error {
+			_, _, err := CombineWithCorrection(raw, 2)
+			return err
+		},
+		"NewJoiner": func() error {
+			r, err := NewJoiner([]io.Reader{bytes.NewReader(raw[0]), bytes.NewReader(raw[1])})
+			if err != nil {
+				return err
+			}
+			_, err = io.ReadAll(r)
+			return err
+		},
+	}
+	for name, combine := range combines {
+		calls = 0
+		err := combine()
+		if !errors.Is(err, ErrPolicyDenied) || !errors.Is(err, errOutsideWindow) {
+			t.Errorf("%s: expected policy denial, got %v", name, err)
+		}
+		if calls != 1 {
+			t.Errorf("%s: hook called %d times, want 1", name, calls)
+		}
+	}
+}
+
+func TestPolicyHookMetadata(t *testing.T) {
+	secret := []byte("policy metadata")
+	shares, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2),
+		WithPurpose("root-ca"), WithLabels("alice", "bob", "carol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ParseShare(shares[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "ticket-42")
+
+	var got CombineMetadata
+	result, err := CombineContext(ctx, [][]byte{shares[2], shares[0]}, WithPolicyHook(func(meta CombineMetadata) error {
+		got = meta
+		meta.Indices[0] = 0xff // Must not affect the combine
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+
+	if got.Shares != 2 || !bytes.Equal(got.Indices, []byte{0xff, 1}) || got.SecretSize != len(secret) {
+		t.Errorf("unexpected share metadata: %+v", got)
+	}
+	if !got.Enveloped || got.Threshold != 2 || got.SetID != want.SetID || got.Purpose != "root-ca" {
+		t.Errorf("unexpected envelope metadata: %+v", got)
+	}
+	if len(got.Labels) != 2 || got.Labels[0] != "carol" || got.Labels[1] != "alice" {
+		t.Errorf("labels = %q, want [carol alice]", got.Labels)
+	}
+	if got.Context == nil || got.Context.Value(ctxKey{}) != "ticket-42" {
+		t.Error("hook did not receive the CombineContext context")
+	}
+}
+
+func TestPolicyHookOrder(t *testing.T) {
+	shares, err := Split([]byte("ordered"), 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var order []string
+	registerTestPolicyHook(t, func(CombineMetadata) error {
+		order = append(order, "registered")
+		return nil
+	})
+	errNoApproval := errors.New("missing approval")
+	_, err = CombineWithOptions(shares, WithPolicyHook(func(meta CombineMetadata) error {
+		order = append(order, "option")
+		if meta.Enveloped || meta.Threshold != 0 {
+			t.Errorf("raw shares reported envelope metadata: %+v", meta)
+		}
+		return errNoApproval
+	}))
+	if !errors.Is(err, errNoApproval) {
+		t.Fatalf("expected per-call hook error, got %v", err)
+	}
+	if len(order) != 2 || order[0] != "registered" || order[1] != "option" {
+		t.Fatalf("hook order = %q", order)
+	}
+
+	// Per-call hooks apply to that call only.
+	order = nil
+	if _, err := Combine(shares); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 1 {
+		t.Fatalf("hook order = %q", order)
+	}
+}
+
+func TestPolicyHookHierarchical(t *testing.T) {
+	groups := []Group{{Name: "ops", Threshold: 2, Members: []string{"a", "b", "c"}}}
+	shares, err := SplitHierarchical([]byte("hierarchical"), groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerTestPolicyHook(t, func(CombineMetadata) error { return errOutsideWindow })
+
+	if _, err := CombineHierarchical(groups, shares); !errors.Is(err, errOutsideWindow) {
+		t.Fatalf("expected policy denial, got %v", err)
+	}
+}
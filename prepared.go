@@ -0,0 +1,203 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"slices"
+)
+
+// Combine-ready shares.
+//
+// Reconstruction multiplies every share payload by its Lagrange weight and
+// sums the products. The weights depend only on which shares are combined, so
+// a storage backend that knows the quorum it will restore from, such as an
+// archive keeping the same k shares on k volumes, can apply them ahead of
+// time. PrepareShare stores a share pre-multiplied by its weight for a given
+// quorum; CombinePrepared then reduces to XORing the payloads together, with
+// no field multiplication on the restore path. A pre-multiplied share reveals
+// nothing the original does not, since the weight is public and never zero,
+// and UnprepareShare converts it back.
+//
+// Prepared share format:
+//
+//	[3 bytes] magic 0x00 'S' 'P'
+//	[1 byte]  format version (1)
+//	[1 byte]  x-coordinate
+//	[1 byte]  quorum size k
+//	[k bytes] x-coordinates of the quorum, ascending
+//	[n bytes] y-values multiplied by the share's Lagrange weight at x = 0
+//	[4 bytes] CRC32 (IEEE) of all preceding bytes, big-endian
+
+// preparedMagic identifies a prepared share.
+var preparedMagic = [3]byte{0x00, 'S', 'P'}
+
+const (
+	preparedFormatVersion = 1
+	preparedHeaderSize    = len(preparedMagic) + 1 + 1 + 1
+	preparedChecksumSize  = 4
+)
+
+// preparedShare is a decoded prepared share.
+type preparedShare struct {
+	index   byte
+	quorum  []byte
+	payload []byte
+}
+
+// PrepareShare converts a share returned by Split into the combine-ready form
+// read by CombinePrepared, for recovery from exactly the shares whose
+// x-coordinates (first bytes) are listed in quorum. The quorum must include
+// the share itself and should have threshold members; prepared shares cannot
+// be combined with any other set.
+func PrepareShare(share, quorum []byte) ([]byte, error) {
+	if format := shareFormat(share); format != "" {
+		return nil, &MigrationError{Func: "PrepareShare", Share: 0, Format: format, Replacement: migrationReplacements[format]}
+	}
+	if len(share) <= ShareOverhead {
+		return nil, ErrTooShort
+	}
+	quorum, err := normalizeQuorum(quorum)
+	if err != nil {
+		return nil, err
+	}
+	pos, ok := slices.BinarySearch(quorum, share[0])
+	if !ok {
+		return nil, NewValidationError("index", int(share[0]), "shamir: share is not a member of the quorum")
+	}
+
+	payload := share[ShareOverhead:]
+	out := make([]byte, 0, preparedHeaderSize+len(quorum)+len(payload)+preparedChecksumSize)
+	out = append(out, preparedMagic[:]...)
+	out = append(out, preparedFormatVersion, share[0], byte(len(quorum)))
+	out = append(out, quorum...)
+	out = out[:len(out)+len(payload)]
+	gfMultSlice(out[len(out)-len(payload):], payload, lagrangeBasis(quorum, 0)[pos])
+
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out)), nil
+}
+
+// UnprepareShare converts a share produced by PrepareShare back to the share
+// Split returned.
+func UnprepareShare(prepared []byte) ([]byte, error) {
+	s, err := parsePreparedShare(prepared)
+	if err != nil {
+		return nil, err
+	}
+	pos, _ := slices.BinarySearch(s.quorum, s.index)
+	share := make([]byte, ShareOverhead+len(s.payload))
+	share[0] = s.index
+	gfMultSlice(share[ShareOverhead:], s.payload, gfInv(lagrangeBasis(s.quorum, 0)[pos]))
+	return share, nil
+}
+
+// CombinePrepared reconstructs the secret from the shares produced by
+// PrepareShare for one quorum, which must all be present. It returns
+// ErrInsufficientShares if some are missing and ErrMismatchedShares if the
+// shares were prepared for different quorums. WithParallelism, WithContext
+// and WithPolicyHook apply as they do to CombineWithOptions; other options are
+// ignored.
+func CombinePrepared(prepared [][]byte, opts ...Option) ([]byte, error) {
+	if prepared == nil {
+		return nil, ErrNilShares
+	}
+	if len(prepared) < 2 {
+		return nil, ErrTooFewParts
+	}
+
+	shares := make([]*preparedShare, len(prepared))
+	xCoords := make([]byte, len(prepared))
+	for i, p := range prepared {
+		s, err := parsePreparedShare(p)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", i, err)
+		}
+		if i > 0 && !bytes.Equal(s.quorum, shares[0].quorum) {
+			return nil, fmt.Errorf("share %d: %w: prepared for a different quorum", i, ErrMismatchedShares)
+		}
+		if i > 0 && len(s.payload) != len(shares[0].payload) {
+			return nil, ErrDifferentLengths
+		}
+		if bytes.IndexByte(xCoords[:i], s.index) >= 0 {
+			return nil, NewValidationError("share", i, "shamir: duplicate share identifier detected")
+		}
+		shares[i], xCoords[i] = s, s.index
+	}
+	if quorum := shares[0].quorum; len(shares) != len(quorum) {
+		return nil, fmt.Errorf("%w: %d of the %d prepared shares", ErrInsufficientShares, len(shares), len(quorum))
+	}
+
+	secretLen := len(shares[0].payload)
+	eng := newOptions(opts).engine()
+	if err := eng.checkPolicy(xCoords, secretLen); err != nil {
+		return nil, err
+	}
+	_, workers := eng.resolve(secretLen)
+
+	secret := allocSecret(secretLen)
+	for start := 0; start < secretLen; start += strategyWindowSize {
+		if err := contextErr(eng.ctx); err != nil {
+			freeSecret(secret)
+			return nil, err
+		}
+		end := min(start+strategyWindowSize, secretLen)
+		chunks := chunkCount(end-start, workers)
+		parallelFor(chunks, workers, func(c int) {
+			lo, hi := chunkBounds(end-start, chunks, c)
+			dst := secret[start+lo : start+hi]
+			for _, s := range shares {
+				gfAddSlice(dst, dst, s.payload[start+lo:start+hi])
+			}
+		})
+	}
+	return secret, nil
+}
+
+// normalizeQuorum returns a sorted copy of quorum after checking that it
+// lists at least two distinct, non-zero x-coordinates.
+func normalizeQuorum(quorum []byte) ([]byte, error) {
+	if len(quorum) < 2 || len(quorum) > 255 {
+		return nil, NewValidationError("quorum", len(quorum), "shamir: quorum must have between 2 and 255 members")
+	}
+	sorted := slices.Clone(quorum)
+	slices.Sort(sorted)
+	for i, x := range sorted {
+		if x == 0 || (i > 0 && x == sorted[i-1]) {
+			return nil, NewValidationError("quorum", int(x), "shamir: quorum x-coordinates must be distinct and non-zero")
+		}
+	}
+	return sorted, nil
+}
+
+// parsePreparedShare decodes and checks a prepared share. The quorum and
+// payload alias data.
+func parsePreparedShare(data []byte) (*preparedShare, error) {
+	if len(data) < preparedHeaderSize+preparedChecksumSize || !bytes.HasPrefix(data, preparedMagic[:]) {
+		return nil, ErrInvalidPreparedShare
+	}
+	if v := data[len(preparedMagic)]; v != preparedFormatVersion {
+		return nil, fmt.Errorf("%w: prepared share version %d", ErrUnsupportedVersion, v)
+	}
+	body := data[:len(data)-preparedChecksumSize]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(body):]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidPreparedShare)
+	}
+
+	k := int(body[5])
+	if len(body) <= preparedHeaderSize+k {
+		return nil, fmt.Errorf("%w: truncated", ErrInvalidPreparedShare)
+	}
+	s := &preparedShare{
+		index:   body[4],
+		quorum:  body[preparedHeaderSize : preparedHeaderSize+k],
+		payload: body[preparedHeaderSize+k:],
+	}
+	if q, err := normalizeQuorum(s.quorum); err != nil || !bytes.Equal(q, s.quorum) {
+		return nil, fmt.Errorf("%w: bad quorum", ErrInvalidPreparedShare)
+	}
+	if _, ok := slices.BinarySearch(s.quorum, s.index); !ok {
+		return nil, fmt.Errorf("%w: share is not a member of its quorum", ErrInvalidPreparedShare)
+	}
+	return s, nil
+}
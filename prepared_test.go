@@ -0,0 +1,176 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// prepareQuorum prepares the given shares for recovery from exactly that set.
+func prepareQuorum(t testing.TB, shares [][]byte) [][]byte {
+	t.Helper()
+	quorum := make([]byte, len(shares))
+	for i, share := range shares {
+		quorum[i] = share[0]
+	}
+	prepared := make([][]byte, len(shares))
+	for i, share := range shares {
+		var err error
+		if prepared[i], err = PrepareShare(share, quorum); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return prepared
+}
+
+func TestPrepareShareRoundTrip(t *testing.T) {
+	for _, size := range []int{1, 64, 1000} {
+		secret := make([]byte, size)
+		for i := range secret {
+			secret[i] = byte(i*7 + 3)
+		}
+		shares, err := Split(secret, 5, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 2, 3, 4}} {
+			picked := make([][]byte, len(subset))
+			for i, j := range subset {
+				picked[i] = shares[j]
+			}
+			prepared := prepareQuorum(t, picked)
+
+			// Any order of the prepared quorum reconstructs the secret.
+			prepared[0], prepared[len(prepared)-1] = prepared[len(prepared)-1], prepared[0]
+			got, err := CombinePrepared(prepared)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, secret) {
+				t.Fatalf("size %d, shares %v: reconstructed secret mismatch", size, subset)
+			}
+
+			for _, p := range prepared {
+				back, err := UnprepareShare(p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(back, shares[back[0]-1]) {
+					t.Fatalf("size %d: UnprepareShare did not restore share %d", size, back[0])
+				}
+			}
+		}
+	}
+}
+
+func TestCombinePreparedParallel(t *testing.T) {
+	secret := make([]byte, 3*minParallelChunk+17)
+	for i := range secret {
+		secret[i] = byte(i)
+	}
+	shares, err := Split(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CombinePrepared(prepareQuorum(t, shares[1:]), WithParallelism(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+}
+
+func TestCombinePreparedErrors(t *testing.T) {
+	shares, err := Split([]byte("prepared secret"), 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab := prepareQuorum(t, shares[:2])
+	abc := prepareQuorum(t, shares[:3])
+
+	if _, err := CombinePrepared(ab[:1]); !errors.Is(err, ErrTooFewParts) {
+		t.Fatalf("expected ErrTooFewParts, got %v", err)
+	}
+	if _, err := CombinePrepared(abc[:2]); !errors.Is(err, ErrInsufficientShares) {
+		t.Fatalf("partial quorum: expected ErrInsufficientShares, got %v", err)
+	}
+	if _, err := CombinePrepared([][]byte{ab[0], abc[1]}); !errors.Is(err, ErrMismatchedShares) {
+		t.Fatalf("mixed quorums: expected ErrMismatchedShares, got %v", err)
+	}
+	if _, err := CombinePrepared([][]byte{ab[0], ab[0]}); err == nil {
+		t.Fatal("expected an error for duplicate shares")
+	}
+
+	corrupt := bytes.Clone(ab[1])
+	corrupt[len(corrupt)-5] ^= 1
+	if _, err := CombinePrepared([][]byte{ab[0], corrupt}); !errors.Is(err, ErrInvalidPreparedShare) {
+		t.Fatalf("expected ErrInvalidPreparedShare, got %v", err)
+	}
+
+	if _, err := PrepareShare(shares[3], []byte{shares[0][0], shares[1][0]}); err == nil {
+		t.Fatal("expected an error for a share outside the quorum")
+	}
+	if _, err := PrepareShare(shares[0], []byte{shares[0][0], shares[0][0]}); err == nil {
+		t.Fatal("expected an error for a repeated quorum member")
+	}
+
+	// Prepared shares are recognised by the raw-share functions and vice versa.
+	if _, err := Combine(ab); !errors.Is(err, ErrFormatMismatch) {
+		t.Fatalf("Combine: expected ErrFormatMismatch, got %v", err)
+	}
+	if _, err := PrepareShare(ab[0], []byte{1, 2}); !errors.Is(err, ErrFormatMismatch) {
+		t.Fatalf("PrepareShare: expected ErrFormatMismatch, got %v", err)
+	}
+	if _, err := UnprepareShare(shares[0]); !errors.Is(err, ErrInvalidPreparedShare) {
+		t.Fatalf("UnprepareShare: expected ErrInvalidPreparedShare, got %v", err)
+	}
+}
+
+func TestCombinePreparedPolicy(t *testing.T) {
+	denied := errors.New("denied")
+	registerTestPolicyHook(t, func(meta CombineMetadata) error {
+		if meta.Shares != 2 || meta.SecretSize != 6 {
+			return fmt.Errorf("unexpected metadata %+v", meta)
+		}
+		return denied
+	})
+	shares, err := Split([]byte("secret"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CombinePrepared(prepareQuorum(t, shares[1:])); !errors.Is(err, denied) || !errors.Is(err, ErrPolicyDenied) {
+		t.Fatalf("expected policy denial, got %v", err)
+	}
+}
+
+func BenchmarkCombinePrepared(b *testing.B) {
+	secret := make([]byte, 1<<20)
+	for i := range secret {
+		secret[i] = byte(i)
+	}
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		b.Fatal(err)
+	}
+	prepared := prepareQuorum(b, shares[:3])
+
+	b.Run("raw", func(b *testing.B) {
+		b.SetBytes(int64(len(secret)))
+		for i := 0; i < b.N; i++ {
+			if _, err := CombineWithOptions(shares[:3], WithParallelism(1)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("prepared", func(b *testing.B) {
+		b.SetBytes(int64(len(secret)))
+		for i := 0; i < b.N; i++ {
+			if _, err := CombinePrepared(prepared, WithParallelism(1)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
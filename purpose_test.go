@@ -0,0 +1,84 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPurposeBinding(t *testing.T) {
+	secret := []byte("prod database master key material")
+
+	shares, err := SplitWithOptions(secret, WithParts(5), WithThreshold(3), WithPurpose("prod-db-master-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := ParseShare(shares[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Purpose != "prod-db-master-key" {
+		t.Fatalf("expected purpose to be recorded, got %q", s.Purpose)
+	}
+
+	t.Run("matching purpose", func(t *testing.T) {
+		reconstructed, err := CombineWithOptions(shares[:3], WithPurpose("prod-db-master-key"), WithStrictPurpose(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(reconstructed, secret) {
+			t.Fatal("reconstruction failed")
+		}
+	})
+
+	t.Run("non-strict without purpose", func(t *testing.T) {
+		reconstructed, err := CombineWithOptions(shares[1:4])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(reconstructed, secret) {
+			t.Fatal("reconstruction failed")
+		}
+	})
+
+	t.Run("wrong purpose", func(t *testing.T) {
+		if _, err := CombineWithOptions(shares[:3], WithPurpose("staging-db")); !errors.Is(err, ErrPurposeMismatch) {
+			t.Fatalf("expected ErrPurposeMismatch, got %v", err)
+		}
+	})
+
+	t.Run("strict without purpose", func(t *testing.T) {
+		if _, err := CombineWithOptions(shares[:3], WithStrictPurpose(true)); err != ErrPurposeRequired {
+			t.Fatalf("expected ErrPurposeRequired, got %v", err)
+		}
+	})
+
+	t.Run("relabelled shares", func(t *testing.T) {
+		relabelled := make([][]byte, 3)
+		for i := range relabelled {
+			s, err := ParseShare(shares[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Purpose = "staging-db"
+			if relabelled[i], err = s.MarshalBinary(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if _, err := CombineWithOptions(relabelled, WithPurpose("staging-db")); !errors.Is(err, ErrPurposeMismatch) {
+			t.Fatalf("expected ErrPurposeMismatch for relabelled shares, got %v", err)
+		}
+	})
+
+	t.Run("strict rejects unbound shares", func(t *testing.T) {
+		raw, err := Split(secret, 3, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := CombineWithOptions(raw, WithPurpose("prod-db-master-key"), WithStrictPurpose(true)); !errors.Is(err, ErrPurposeMismatch) {
+			t.Fatalf("expected ErrPurposeMismatch, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package shamir
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/morizta/go-shamir/internal/qrcode"
+)
+
+// qrScale is the default PNG resolution in pixels per module.
+const qrScale = 8
+
+// ShareQRPayload returns the text stored in a share's QR code: the share's PEM
+// block from EncodeSharePEM, so the index, threshold and checksum headers
+// travel with it and a scan can be verified before use.
+func ShareQRPayload(share []byte) ([]byte, error) {
+	return EncodeSharePEM(share)
+}
+
+// WriteShareQRPNG renders the share as a QR code PNG with scale pixels per
+// module (8 if scale is zero or negative). Symbols use error correction level
+// M, which tolerates the wear typical of paper escrow.
+func WriteShareQRPNG(w io.Writer, share []byte, scale int) error {
+	code, err := shareQRCode(share)
+	if err != nil {
+		return err
+	}
+	if scale <= 0 {
+		scale = qrScale
+	}
+	return code.WritePNG(w, scale)
+}
+
+// WriteShareQRSVG renders the share as a QR code in SVG, which prints at any
+// size without loss.
+func WriteShareQRSVG(w io.Writer, share []byte) error {
+	code, err := shareQRCode(share)
+	if err != nil {
+		return err
+	}
+	return code.WriteSVG(w)
+}
+
+// DecodeShareQR parses the payload returned by a QR scanner and verifies it
+// against its headers. Scanner output often gains or loses trailing
+// whitespace, which is ignored.
+func DecodeShareQR(payload []byte) ([]byte, error) {
+	share, rest, err := DecodeSharePEM(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(rest)) != 0 {
+		return nil, fmt.Errorf("%w: unexpected data after share", ErrInvalidPEM)
+	}
+	return share, nil
+}
+
+// shareQRCode encodes a share's payload as a QR symbol.
+func shareQRCode(share []byte) (*qrcode.Code, error) {
+	payload, err := ShareQRPayload(share)
+	if err != nil {
+		return nil, err
+	}
+	code, err := qrcode.Encode(payload, qrcode.LevelM)
+	if err != nil {
+		return nil, fmt.Errorf("shamir: %d byte share does not fit in a QR code: %w", len(share), err)
+	}
+	return code, nil
+}
@@ -0,0 +1,96 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestShareQR(t *testing.T) {
+	secret := []byte("paper escrow secret")
+	parts, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithPurpose("backup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, share := range parts {
+		var buf bytes.Buffer
+		if err := WriteShareQRPNG(&buf, share, 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := png.Decode(&buf); err != nil {
+			t.Fatalf("invalid PNG: %v", err)
+		}
+
+		buf.Reset()
+		if err := WriteShareQRSVG(&buf, share); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(buf.String(), "<?xml") || !strings.Contains(buf.String(), "<svg") {
+			t.Fatal("invalid SVG output")
+		}
+
+		payload, err := ShareQRPayload(share)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Scanners commonly drop the trailing newline
+		decoded, err := DecodeShareQR(bytes.TrimSpace(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, share) {
+			t.Fatal("decoded share does not match")
+		}
+	}
+
+	t.Run("raw shares", func(t *testing.T) {
+		raw, err := Split(secret, 2, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload, err := ShareQRPayload(raw[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := DecodeShareQR(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, raw[0]) {
+			t.Fatal("decoded share does not match")
+		}
+	})
+
+	t.Run("corrupted payload", func(t *testing.T) {
+		payload, _ := ShareQRPayload(parts[0])
+		lines := strings.Split(string(payload), "\n")
+		for i, line := range lines {
+			if strings.HasPrefix(line, "Index:") {
+				lines[i] = "Index: 9"
+			}
+		}
+		if _, err := DecodeShareQR([]byte(strings.Join(lines, "\n"))); err == nil {
+			t.Error("expected error for tampered header")
+		}
+		if _, err := DecodeShareQR([]byte("not a share")); !errors.Is(err, ErrInvalidPEM) {
+			t.Errorf("expected ErrInvalidPEM, got %v", err)
+		}
+		trailing := append(append([]byte(nil), payload...), "extra"...)
+		if _, err := DecodeShareQR(trailing); !errors.Is(err, ErrInvalidPEM) {
+			t.Errorf("expected ErrInvalidPEM, got %v", err)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		big, err := Split(make([]byte, 4096), 2, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteShareQRSVG(&bytes.Buffer{}, big[0]); err == nil {
+			t.Error("expected error for share exceeding QR capacity")
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package shamir
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CustodianAgent fetches one custodian's share from wherever it is held, for
+// example over the network from an unseal agent. Fetch must return promptly
+// once ctx is cancelled.
+type CustodianAgent struct {
+	Custodian string
+	Fetch     func(ctx context.Context) ([]byte, error)
+}
+
+// QuorumPolicy configures CombineQuorum.
+type QuorumPolicy struct {
+	Agents    []CustodianAgent
+	Threshold int           // Shares to collect; 0 takes the manifest's threshold
+	Manifest  *Manifest     // Optional; if set, each share must be listed for the custodian returning it
+	Timeout   time.Duration // Overall deadline for collecting the quorum; 0 relies on ctx alone
+}
+
+// QuorumReport records what each custodian contributed to a CombineQuorum call.
+type QuorumReport struct {
+	Contributed []string         // Custodians whose shares were combined
+	Failed      map[string]error // Unreachable custodians, invalid shares and missed deadlines
+	Skipped     []string         // Custodians still outstanding when the quorum was reached
+}
+
+// CombineQuorum queries every agent concurrently and reconstructs the secret as
+// soon as Threshold valid shares have arrived, cancelling the remaining
+// requests. It is the building block for automated disaster-recovery unseal.
+//
+// A share is valid if its envelope (when enveloped) verifies and, when a
+// manifest is given, the manifest lists it for the custodian that returned it.
+// The report is returned even on failure, so callers can tell which
+// custodians were unreachable. If the deadline passes first, the error wraps
+// both ErrInsufficientShares and the context error.
+func CombineQuorum(ctx context.Context, policy QuorumPolicy) ([]byte, *QuorumReport, error) {
+	threshold := policy.Threshold
+	if threshold == 0 && policy.Manifest != nil {
+		threshold = policy.Manifest.Threshold
+	}
+	if threshold < 2 {
+		return nil, nil, NewValidationError("threshold", threshold, "shamir: threshold must be at least 2")
+	}
+	if len(policy.Agents) < threshold {
+		return nil, nil, fmt.Errorf("%w: %d agents configured, threshold is %d", ErrInsufficientShares, len(policy.Agents), threshold)
+	}
+	seen := make(map[string]bool, len(policy.Agents))
+	for _, a := range policy.Agents {
+		if seen[a.Custodian] {
+			return nil, nil, fmt.Errorf("shamir: custodian %q configured twice", a.Custodian)
+		}
+		if a.Fetch == nil {
+			return nil, nil, fmt.Errorf("shamir: custodian %q has no fetch function", a.Custodian)
+		}
+		seen[a.Custodian] = true
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if policy.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+
+	type response struct {
+		agent int
+		share []byte
+		err   error
+	}
+	responses := make(chan response, len(policy.Agents)) // Buffered so late agents never block
+	for i, a := range policy.Agents {
+		go func() {
+			share, err := a.Fetch(ctx)
+			responses <- response{agent: i, share: share, err: err}
+		}()
+	}
+
+	report := &QuorumReport{Failed: make(map[string]error)}
+	answered := make([]bool, len(policy.Agents))
+	var parts [][]byte
+	defer func() {
+		for _, part := range parts {
+			secureZeroBytes(part)
+		}
+	}()
+
+collect:
+	for outstanding := len(policy.Agents); outstanding > 0 && len(parts) < threshold; outstanding-- {
+		select {
+		case r := <-responses:
+			answered[r.agent] = true
+			name := policy.Agents[r.agent].Custodian
+			if r.err == nil {
+				r.err = validateQuorumShare(policy.Manifest, name, r.share)
+			}
+			if r.err != nil {
+				report.Failed[name] = r.err
+				continue
+			}
+			parts = append(parts, append([]byte(nil), r.share...))
+			report.Contributed = append(report.Contributed, name)
+		case <-ctx.Done():
+			break collect
+		}
+	}
+	deadlineErr := ctx.Err()
+	cancel()
+
+	for i, a := range policy.Agents {
+		if answered[i] {
+			continue
+		}
+		if len(parts) >= threshold {
+			report.Skipped = append(report.Skipped, a.Custodian)
+		} else {
+			report.Failed[a.Custodian] = deadlineErr
+		}
+	}
+
+	if len(parts) < threshold {
+		if deadlineErr != nil {
+			return nil, report, fmt.Errorf("%w: %d of %d shares before the deadline: %w", ErrInsufficientShares, len(parts), threshold, deadlineErr)
+		}
+		return nil, report, fmt.Errorf("%w: %d of %d shares, %d custodians failed", ErrInsufficientShares, len(parts), threshold, len(report.Failed))
+	}
+
+	secret, err := CombineWithOptions(parts)
+	if err != nil {
+		return nil, report, err
+	}
+	return secret, report, nil
+}
+
+// validateQuorumShare checks a share returned by a custodian's agent.
+func validateQuorumShare(m *Manifest, custodian string, share []byte) error {
+	if len(share) < 2 {
+		return ErrTooShort
+	}
+	if IsEnvelope(share) {
+		s, err := ParseShare(share)
+		if err != nil {
+			return err
+		}
+		secureZeroBytes(s.Payload)
+	}
+	if m != nil {
+		holder, err := m.Custodian(share)
+		if err != nil {
+			return err
+		}
+		if holder != custodian {
+			return fmt.Errorf("%w: share belongs to %q", ErrUnknownShare, holder)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package shamir
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+// quorumAgents returns agents that serve the sharing's shares after the given
+// delays; a negative delay never answers.
+func quorumAgents(sharing *EscrowSharing, delays []time.Duration) []CustodianAgent {
+	agents := make([]CustodianAgent, len(delays))
+	for i, delay := range delays {
+		share := sharing.Shares[i]
+		agents[i] = CustodianAgent{
+			Custodian: sharing.Manifest.Custodians[i].Custodian,
+			Fetch: func(ctx context.Context) ([]byte, error) {
+				if delay < 0 {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+				select {
+				case <-time.After(delay):
+					return share, nil
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			},
+		}
+	}
+	return agents
+}
+
+func newQuorumSharing(t *testing.T, secret []byte) *EscrowSharing {
+	t.Helper()
+	sharing, err := splitEscrow(secret, EscrowPolicy{
+		Name: "dr", Threshold: 3, Custodians: []string{"alice", "bob", "carol", "dave", "erin"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sharing
+}
+
+func TestCombineQuorum(t *testing.T) {
+	secret := []byte("unseal key")
+	sharing := newQuorumSharing(t, secret)
+
+	t.Run("proceeds at k", func(t *testing.T) {
+		agents := quorumAgents(sharing, []time.Duration{0, -1, 10 * time.Millisecond, 0, -1})
+		agents[1].Fetch = func(context.Context) ([]byte, error) { return nil, errors.New("connection refused") }
+
+		got, report, err := CombineQuorum(context.Background(), QuorumPolicy{Agents: agents, Manifest: sharing.Manifest})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Fatal("wrong secret")
+		}
+		sort.Strings(report.Contributed)
+		if len(report.Contributed) != 3 || report.Contributed[0] != "alice" || report.Contributed[1] != "carol" || report.Contributed[2] != "dave" {
+			t.Errorf("contributed = %v", report.Contributed)
+		}
+		if _, ok := report.Failed["bob"]; !ok || len(report.Failed) != 1 {
+			t.Errorf("failed = %v, want bob only", report.Failed)
+		}
+		if len(report.Skipped) != 1 || report.Skipped[0] != "erin" {
+			t.Errorf("skipped = %v, want [erin]", report.Skipped)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		agents := quorumAgents(sharing, []time.Duration{0, 0, -1, -1, -1})
+		_, report, err := CombineQuorum(context.Background(), QuorumPolicy{
+			Agents: agents, Manifest: sharing.Manifest, Timeout: 20 * time.Millisecond,
+		})
+		if !errors.Is(err, ErrInsufficientShares) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected ErrInsufficientShares and DeadlineExceeded, got %v", err)
+		}
+		if len(report.Contributed) != 2 || len(report.Failed) != 3 {
+			t.Errorf("report = %+v", report)
+		}
+		if !errors.Is(report.Failed["carol"], context.DeadlineExceeded) {
+			t.Errorf("carol: %v", report.Failed["carol"])
+		}
+	})
+
+	t.Run("share from wrong custodian", func(t *testing.T) {
+		agents := quorumAgents(sharing, []time.Duration{0, 0, 0, 0, 0})
+		agents[0].Fetch = agents[1].Fetch // alice's agent returns bob's share
+		got, report, err := CombineQuorum(context.Background(), QuorumPolicy{Agents: agents, Manifest: sharing.Manifest})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Fatal("wrong secret")
+		}
+		if !errors.Is(report.Failed["alice"], ErrUnknownShare) {
+			t.Errorf("alice: expected ErrUnknownShare, got %v", report.Failed["alice"])
+		}
+	})
+
+	t.Run("all fail", func(t *testing.T) {
+		agents := quorumAgents(sharing, []time.Duration{0, 0, 0, 0, 0})
+		for i := 1; i < len(agents); i++ {
+			agents[i].Fetch = func(context.Context) ([]byte, error) { return []byte{0x01}, nil }
+		}
+		_, report, err := CombineQuorum(context.Background(), QuorumPolicy{Agents: agents, Threshold: 3})
+		if !errors.Is(err, ErrInsufficientShares) {
+			t.Fatalf("expected ErrInsufficientShares, got %v", err)
+		}
+		if len(report.Failed) != 4 {
+			t.Errorf("failed = %v", report.Failed)
+		}
+	})
+
+	t.Run("configuration", func(t *testing.T) {
+		agents := quorumAgents(sharing, []time.Duration{0, 0, 0, 0, 0})
+		if _, _, err := CombineQuorum(context.Background(), QuorumPolicy{Agents: agents}); err == nil {
+			t.Error("expected error without threshold or manifest")
+		}
+		if _, _, err := CombineQuorum(context.Background(), QuorumPolicy{Agents: agents[:2], Threshold: 3}); !errors.Is(err, ErrInsufficientShares) {
+			t.Errorf("expected ErrInsufficientShares, got %v", err)
+		}
+		dup := append(agents[:2:2], agents[0])
+		if _, _, err := CombineQuorum(context.Background(), QuorumPolicy{Agents: dup, Threshold: 2}); err == nil {
+			t.Error("expected error for duplicate custodian")
+		}
+	})
+}
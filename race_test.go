@@ -0,0 +1,7 @@
+//go:build race
+
+package shamir
+
+// raceEnabled reports whether the race detector, which defeats sync.Pool
+// reuse and so allocation counts, is enabled.
+const raceEnabled = true
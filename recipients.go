@@ -0,0 +1,202 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+)
+
+// Encrypted share distribution.
+//
+// SplitEncrypted encrypts every share to its custodian's public key as soon as
+// it is generated, so the dealer only ever hands out ciphertext. Recipients
+// are pluggable: X25519Recipient is built in, and other schemes such as age
+// can be adapted by implementing Recipient (see the README) without this
+// package depending on them.
+//
+// X25519 share ciphertext format:
+//
+//	[3 bytes]  magic 0x00 'S' 'X'
+//	[1 byte]   format version (1)
+//	[32 bytes] ephemeral X25519 public key
+//	[n bytes]  AES-256-GCM ciphertext and tag of the enveloped share
+//
+// The AES key is derived with HKDF-SHA256 from the X25519 shared secret,
+// salted with the ephemeral and recipient public keys. Each key encrypts a
+// single share, so a fixed nonce is used; the header is authenticated.
+
+// x25519Magic identifies a share encrypted to an X25519 recipient.
+var x25519Magic = [3]byte{0x00, 'S', 'X'}
+
+const (
+	x25519FormatVersion = 1
+	x25519HeaderSize    = len(x25519Magic) + 1 + 32
+	x25519Info          = "go-shamir x25519 share v1"
+)
+
+// Recipient encrypts a share for one custodian.
+type Recipient interface {
+	EncryptShare(share []byte) ([]byte, error)
+}
+
+// SplitEncrypted splits a secret into one share per recipient and returns
+// each share encrypted to its recipient: the i-th ciphertext is for
+// recipients[i]. parts must equal len(recipients). The plaintext shares are
+// enveloped shares with a common set ID, and are wiped once encrypted; after
+// decryption they combine with CombineWithOptions.
+func SplitEncrypted(secret []byte, parts, threshold int, recipients []Recipient) ([][]byte, error) {
+	if len(recipients) != parts {
+		return nil, NewValidationError("recipients", len(recipients), "shamir: need exactly one recipient per share")
+	}
+	for i, r := range recipients {
+		if r == nil {
+			return nil, NewValidationError("recipients", i, "shamir: recipient cannot be nil")
+		}
+	}
+
+	setID, err := newSetID()
+	if err != nil {
+		return nil, err
+	}
+	o := newOptions([]Option{WithParts(parts), WithThreshold(threshold)})
+	shares, err := splitEnvelopes(secret, o, Share{SetID: setID, CreatedAt: creationTime()})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, share := range shares {
+			secureZeroBytes(share)
+		}
+	}()
+
+	out := make([][]byte, len(shares))
+	for i, share := range shares {
+		if out[i], err = recipients[i].EncryptShare(share); err != nil {
+			return nil, fmt.Errorf("share %d: encryption failed: %w", i, err)
+		}
+	}
+	return out, nil
+}
+
+// X25519Recipient encrypts shares to an X25519 public key.
+type X25519Recipient struct {
+	key *ecdh.PublicKey
+}
+
+// NewX25519Recipient returns a recipient for a 32-byte X25519 public key.
+func NewX25519Recipient(publicKey []byte) (*X25519Recipient, error) {
+	key, err := ecdh.X25519().NewPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("shamir: invalid X25519 public key: %w", err)
+	}
+	return &X25519Recipient{key: key}, nil
+}
+
+// PublicKey returns the recipient's 32-byte public key.
+func (r *X25519Recipient) PublicKey() []byte {
+	return r.key.Bytes()
+}
+
+// EncryptShare encrypts share to the recipient under a fresh ephemeral key.
+func (r *X25519Recipient) EncryptShare(share []byte) ([]byte, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("shamir: failed to generate ephemeral key: %w", err)
+	}
+	shared, err := ephemeral.ECDH(r.key)
+	if err != nil {
+		return nil, err
+	}
+	defer secureZeroBytes(shared)
+
+	header := append(append(x25519Magic[:], x25519FormatVersion), ephemeral.PublicKey().Bytes()...)
+	aead, err := x25519AEAD(shared, header[len(x25519Magic)+1:], r.key.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(header)+len(share)+aead.Overhead())
+	return aead.Seal(append(out, header...), make([]byte, aead.NonceSize()), share, header), nil
+}
+
+// X25519Identity is a custodian's X25519 private key, used to decrypt shares
+// produced for the matching X25519Recipient.
+type X25519Identity struct {
+	key *ecdh.PrivateKey
+}
+
+// GenerateX25519Identity creates a new random identity.
+func GenerateX25519Identity() (*X25519Identity, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("shamir: failed to generate X25519 key: %w", err)
+	}
+	return &X25519Identity{key: key}, nil
+}
+
+// NewX25519Identity returns the identity for a 32-byte X25519 private key.
+func NewX25519Identity(privateKey []byte) (*X25519Identity, error) {
+	key, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("shamir: invalid X25519 private key: %w", err)
+	}
+	return &X25519Identity{key: key}, nil
+}
+
+// Bytes returns the identity's 32-byte private key. Protect it accordingly.
+func (id *X25519Identity) Bytes() []byte {
+	return id.key.Bytes()
+}
+
+// Recipient returns the recipient that encrypts shares to this identity.
+func (id *X25519Identity) Recipient() *X25519Recipient {
+	return &X25519Recipient{key: id.key.PublicKey()}
+}
+
+// DecryptShare decrypts a share encrypted to the identity. It returns
+// ErrInvalidSealed if the ciphertext is malformed, tampered with or was
+// encrypted to another key.
+func (id *X25519Identity) DecryptShare(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < x25519HeaderSize || !bytes.HasPrefix(ciphertext, x25519Magic[:]) {
+		return nil, ErrInvalidSealed
+	}
+	if v := ciphertext[len(x25519Magic)]; v != x25519FormatVersion {
+		return nil, fmt.Errorf("%w: encrypted share version %d", ErrUnsupportedVersion, v)
+	}
+	header := ciphertext[:x25519HeaderSize]
+	ephemeralKey := header[len(x25519Magic)+1:]
+
+	ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralKey)
+	if err != nil {
+		return nil, ErrInvalidSealed
+	}
+	shared, err := id.key.ECDH(ephemeral)
+	if err != nil {
+		return nil, ErrInvalidSealed
+	}
+	defer secureZeroBytes(shared)
+
+	aead, err := x25519AEAD(shared, ephemeralKey, id.key.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	share, err := aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext[x25519HeaderSize:], header)
+	if err != nil {
+		return nil, ErrInvalidSealed
+	}
+	return share, nil
+}
+
+// x25519AEAD derives the AES-256-GCM instance for one encrypted share.
+func x25519AEAD(shared, ephemeralKey, recipientKey []byte) (cipher.AEAD, error) {
+	salt := append(append([]byte(nil), ephemeralKey...), recipientKey...)
+	key, err := hkdf.Key(sha256.New, shared, salt, x25519Info, 32)
+	if err != nil {
+		return nil, fmt.Errorf("shamir: failed to derive share key: %w", err)
+	}
+	defer secureZeroBytes(key)
+	return sealAEAD(key)
+}
@@ -0,0 +1,94 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSplitEncrypted(t *testing.T) {
+	secret := []byte("distributed without plaintext shares")
+
+	identities := make([]*X25519Identity, 4)
+	recipients := make([]Recipient, len(identities))
+	for i := range identities {
+		id, err := GenerateX25519Identity()
+		if err != nil {
+			t.Fatal(err)
+		}
+		identities[i] = id
+		// Recipients are usually built from a published public key.
+		if recipients[i], err = NewX25519Recipient(id.Recipient().PublicKey()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	encrypted, err := SplitEncrypted(secret, 4, 3, recipients)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decrypted := make([][]byte, len(encrypted))
+	for i, ct := range encrypted {
+		if decrypted[i], err = identities[i].DecryptShare(ct); err != nil {
+			t.Fatalf("share %d: %v", i, err)
+		}
+		if !IsEnvelope(decrypted[i]) {
+			t.Fatalf("share %d is not enveloped", i)
+		}
+	}
+	reconstructed, err := CombineWithOptions(decrypted[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+
+	t.Run("wrong identity", func(t *testing.T) {
+		if _, err := identities[1].DecryptShare(encrypted[0]); !errors.Is(err, ErrInvalidSealed) {
+			t.Fatalf("expected ErrInvalidSealed, got %v", err)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		for _, offset := range []int{3, 10, len(encrypted[0]) - 1} {
+			tampered := append([]byte(nil), encrypted[0]...)
+			tampered[offset] ^= 1
+			if _, err := identities[0].DecryptShare(tampered); err == nil {
+				t.Errorf("offset %d: tampering not detected", offset)
+			}
+		}
+	})
+
+	t.Run("identity round trip", func(t *testing.T) {
+		restored, err := NewX25519Identity(identities[2].Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := restored.DecryptShare(encrypted[2]); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("recipient count", func(t *testing.T) {
+		var ve *ValidationError
+		if _, err := SplitEncrypted(secret, 5, 3, recipients); !errors.As(err, &ve) || ve.Field != "recipients" {
+			t.Fatalf("expected recipients ValidationError, got %v", err)
+		}
+	})
+
+	t.Run("recipient failure", func(t *testing.T) {
+		failing := append([]Recipient(nil), recipients...)
+		failing[2] = failingRecipient{}
+		if _, err := SplitEncrypted(secret, 4, 3, failing); !errors.Is(err, errRecipientDown) {
+			t.Fatalf("expected the recipient's error, got %v", err)
+		}
+	})
+}
+
+var errRecipientDown = errors.New("key server unavailable")
+
+type failingRecipient struct{}
+
+func (failingRecipient) EncryptShare([]byte) ([]byte, error) { return nil, errRecipientDown }
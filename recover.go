@@ -0,0 +1,80 @@
+package shamir
+
+// RecoverShare re-issues the share at x-coordinate missingX, e.g. for a
+// custodian who lost theirs, from at least threshold existing shares of the
+// same split. The secret is never reconstructed: the missing share is
+// interpolated directly, so the caller only ever sees share material.
+//
+// Enveloped shares are checked to belong to the same split and to number at
+// least their threshold, and the recovered share is enveloped with the same
+// split metadata (threshold, set ID, algorithm and purpose binding) but no
+// label; use LabelShare to attach one. Raw shares do not record their
+// threshold, so supplying fewer than threshold of them silently yields a
+// share that does not belong to the set. Shares from SplitWithIntegrity must
+// have their checksums stripped first, since a CRC32 is not linear in the
+// share.
+//
+// missingX must be non-zero and not already held by one of the given shares.
+func RecoverShare(shares [][]byte, missingX byte) ([]byte, error) {
+	if missingX == 0 {
+		return nil, NewValidationError("x-coordinate", 0, "shamir: evaluating at x = 0 would reveal the secret")
+	}
+	if len(shares) > 0 && IsEnvelope(shares[0]) {
+		return recoverEnvelope(shares, missingX)
+	}
+
+	if err := validateCombineParams(shares); err != nil {
+		return nil, err
+	}
+	if err := checkMissingX(shares, missingX); err != nil {
+		return nil, err
+	}
+	return InterpolateShare(shares, missingX)
+}
+
+// recoverEnvelope implements RecoverShare for enveloped shares.
+func recoverEnvelope(parts [][]byte, missingX byte) ([]byte, error) {
+	shares, err := parseEnvelopes(parts)
+	if err != nil {
+		return nil, err
+	}
+	raw := make([][]byte, len(shares))
+	defer func() {
+		for i, s := range shares {
+			secureZeroBytes(s.Payload)
+			secureZeroBytes(raw[i])
+		}
+	}()
+	if err := checkSameSplit(shares); err != nil {
+		return nil, err
+	}
+	for i, s := range shares {
+		raw[i] = append([]byte{s.Index}, s.Payload...)
+	}
+	if err := checkMissingX(raw, missingX); err != nil {
+		return nil, err
+	}
+
+	recovered, err := InterpolateShare(raw, missingX)
+	if err != nil {
+		return nil, err
+	}
+	defer secureZeroBytes(recovered)
+
+	env := *shares[0]
+	env.Index = missingX
+	env.Payload = recovered[ShareOverhead:]
+	env.Label = ""
+	env.CreatedAt = creationTime()
+	return env.MarshalBinary()
+}
+
+// checkMissingX rejects an x-coordinate already held by one of the raw shares.
+func checkMissingX(shares [][]byte, missingX byte) error {
+	for i, share := range shares {
+		if share[0] == missingX {
+			return NewValidationError("x-coordinate", i, "shamir: share for this x-coordinate is already present")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRecoverShare(t *testing.T) {
+	secret := []byte("custodian lost their share")
+
+	t.Run("raw", func(t *testing.T) {
+		shares, err := Split(secret, 5, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recovered, err := RecoverShare([][]byte{shares[0], shares[2], shares[4]}, shares[1][0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(recovered, shares[1]) {
+			t.Fatal("recovered share differs from the lost one")
+		}
+	})
+
+	t.Run("enveloped", func(t *testing.T) {
+		shares, err := SplitWithOptions(secret, WithParts(5), WithThreshold(3), WithPurpose("backup"),
+			WithLabels("alice", "bob", "carol", "dave", "erin"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		recovered, err := RecoverShare([][]byte{shares[0], shares[2], shares[4]}, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, _ := ParseShare(recovered)
+		lost, _ := ParseShare(shares[1])
+		if got.Index != 2 || got.Threshold != 3 || got.Purpose != "backup" || got.SetID != lost.SetID ||
+			got.Label != "" || !bytes.Equal(got.Payload, lost.Payload) {
+			t.Fatalf("recovered share mismatch: %+v", got)
+		}
+
+		// The recovered share passes the purpose check alongside the originals.
+		reconstructed, err := CombineWithOptions([][]byte{recovered, shares[3], shares[4]}, WithPurpose("backup"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(reconstructed, secret) {
+			t.Fatal("reconstructed secret mismatch")
+		}
+
+		if _, err := RecoverShare(shares[:2], 3); !errors.Is(err, ErrInsufficientShares) {
+			t.Errorf("expected ErrInsufficientShares, got %v", err)
+		}
+		other, _ := SplitWithOptions(secret, WithParts(5), WithThreshold(3), WithPurpose("other"))
+		if _, err := RecoverShare([][]byte{shares[0], shares[2], other[4]}, 2); !errors.Is(err, ErrMismatchedShares) {
+			t.Errorf("expected ErrMismatchedShares, got %v", err)
+		}
+	})
+
+	t.Run("invalid x-coordinate", func(t *testing.T) {
+		shares, _ := Split(secret, 3, 2)
+		for _, x := range []byte{0, shares[0][0]} {
+			var ve *ValidationError
+			if _, err := RecoverShare(shares[:2], x); !errors.As(err, &ve) || ve.Field != "x-coordinate" {
+				t.Errorf("x = %d: expected x-coordinate ValidationError, got %v", x, err)
+			}
+		}
+	})
+}
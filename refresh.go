@@ -0,0 +1,138 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// Refresh produces a new, independent set of shares encoding the same secret
+// without ever reconstructing it.
+//
+// A random polynomial of degree threshold-1 with a zero constant term is
+// evaluated at every share's x-coordinate and added to that share. The secret
+// (the value at x=0) is unchanged, but the refreshed shares cannot be combined
+// with any old share, so shares leaked before the refresh become useless once
+// the old set is destroyed.
+//
+// Parameters:
+//   - shares: Shares of one split, either raw (Split) or enveloped; all
+//     shares that should remain usable must be refreshed together
+//   - threshold: The threshold of the split; must match enveloped shares
+//
+// Returns new shares in the same order and format as the input.
+func Refresh(shares [][]byte, threshold int) ([][]byte, error) {
+	return refresh(shares, threshold, rand.Reader)
+}
+
+// refresh implements Refresh with an explicit randomness source.
+func refresh(shares [][]byte, threshold int, rng io.Reader) ([][]byte, error) {
+	if threshold < 2 || threshold > 255 {
+		return nil, NewValidationError("threshold", threshold, "shamir: threshold must be between 2 and 255")
+	}
+	if len(shares) > 0 && IsEnvelope(shares[0]) {
+		return refreshEnvelopes(shares, threshold, rng)
+	}
+	if err := validateCombineParams(shares); err != nil {
+		return nil, err
+	}
+
+	payloadLen := len(shares[0]) - ShareOverhead
+	xCoords := make([]byte, len(shares))
+	for i, share := range shares {
+		xCoords[i] = share[0]
+	}
+
+	deltas, err := zeroSharing(xCoords, payloadLen, threshold, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshed := make([][]byte, len(shares))
+	for i, share := range shares {
+		refreshed[i] = make([]byte, len(share))
+		refreshed[i][0] = share[0]
+		gfAddSlice(refreshed[i][ShareOverhead:], share[ShareOverhead:], deltas[i])
+		secureZeroBytes(deltas[i])
+	}
+
+	return refreshed, nil
+}
+
+// refreshEnvelopes refreshes enveloped shares, preserving their metadata
+// except the set ID: the refreshed shares get a new one, since they cannot be
+// combined with the old set.
+func refreshEnvelopes(parts [][]byte, threshold int, rng io.Reader) ([][]byte, error) {
+	if len(parts) < 2 {
+		return nil, ErrTooFewParts
+	}
+
+	shares := make([]*Share, len(parts))
+	xCoords := make([]byte, len(parts))
+	seen := make(map[byte]bool, len(parts))
+	for i, part := range parts {
+		s, err := ParseShare(part)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", i, err)
+		}
+		if s.Threshold != threshold {
+			return nil, fmt.Errorf("share %d: %w: threshold %d, expected %d", i, ErrMismatchedShares, s.Threshold, threshold)
+		}
+		if i > 0 && (s.SetID != shares[0].SetID || len(s.Payload) != len(shares[0].Payload)) {
+			return nil, fmt.Errorf("share %d: %w", i, ErrMismatchedShares)
+		}
+		if seen[s.Index] {
+			return nil, NewValidationError("share", i, "shamir: duplicate share identifier detected")
+		}
+		seen[s.Index] = true
+		shares[i] = s
+		xCoords[i] = s.Index
+	}
+
+	setID, err := newSetID()
+	if err != nil {
+		return nil, err
+	}
+	deltas, err := zeroSharing(xCoords, len(shares[0].Payload), threshold, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshed := make([][]byte, len(shares))
+	for i, s := range shares {
+		s.SetID = setID
+		gfAddSlice(s.Payload, s.Payload, deltas[i])
+		secureZeroBytes(deltas[i])
+		if refreshed[i], err = s.MarshalBinary(); err != nil {
+			return nil, err
+		}
+		secureZeroBytes(s.Payload)
+	}
+
+	return refreshed, nil
+}
+
+// zeroSharing returns shares of an all-zero secret of length n at the given
+// x-coordinates, using a fresh random polynomial of degree threshold-1.
+func zeroSharing(xCoords []byte, n, threshold int, rng io.Reader) ([][]byte, error) {
+	coeffs := make([][]byte, threshold)
+	for i := range coeffs {
+		coeffs[i] = make([]byte, n) // A zero constant term keeps the secret unchanged
+	}
+	wipe := func() {
+		for _, c := range coeffs {
+			secureZeroBytes(c)
+		}
+	}
+	if err := readEntropy(nil, rng, 0, coeffs[1:], wipe); err != nil {
+		return nil, err
+	}
+	defer wipe()
+
+	deltas := make([][]byte, len(xCoords))
+	for i, x := range xCoords {
+		deltas[i] = make([]byte, n)
+		gfPolyEvalSlice(deltas[i], coeffs, x)
+	}
+	return deltas, nil
+}
@@ -0,0 +1,73 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRefresh(t *testing.T) {
+	secret := []byte("rotated periodically")
+
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed, err := Refresh(shares, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range shares {
+		if refreshed[i][0] != shares[i][0] {
+			t.Fatalf("share %d changed x-coordinate", i)
+		}
+		if bytes.Equal(refreshed[i], shares[i]) {
+			t.Fatalf("share %d was not refreshed", i)
+		}
+	}
+
+	reconstructed, err := Combine([][]byte{refreshed[0], refreshed[2], refreshed[4]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("refreshed shares do not reconstruct the secret")
+	}
+
+	// Mixing old and refreshed shares must not yield the secret.
+	mixed, err := Combine([][]byte{shares[0], refreshed[1], refreshed[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(mixed, secret) {
+		t.Fatal("old share combined with refreshed shares still reconstructs the secret")
+	}
+}
+
+func TestRefreshEnvelopes(t *testing.T) {
+	secret := []byte("purpose bound and refreshed")
+
+	shares, err := SplitWithOptions(secret, WithParts(4), WithThreshold(2), WithPurpose("refresh-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed, err := Refresh(shares, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reconstructed, err := CombineWithOptions(refreshed[2:], WithPurpose("refresh-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("refreshed envelopes do not reconstruct the secret")
+	}
+
+	if _, err := Refresh(shares, 3); !errors.Is(err, ErrMismatchedShares) {
+		t.Fatalf("expected ErrMismatchedShares for wrong threshold, got %v", err)
+	}
+}
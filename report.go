@@ -0,0 +1,151 @@
+package shamir
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Digest returns the SHA-256 of the manifest's JSON encoding with decoy flags
+// cleared. It is printed on ceremony reports so that anyone holding a copy of
+// the manifest can check it against the signed paper record.
+func (m *Manifest) Digest() ([32]byte, error) {
+	public := *m
+	public.Custodians = make([]ManifestEntry, len(m.Custodians))
+	for i, entry := range m.Custodians {
+		entry.Decoy = false
+		public.Custodians[i] = entry
+	}
+
+	data, err := json.Marshal(&public)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
+
+// WriteReport writes a printable HTML key ceremony report for the manifest:
+// the policy, linked splits, notes, every custodian with their share index and
+// fingerprint, the manifest digest, and signature lines for the custodians, the
+// dealer and a witness. Print it from a browser to obtain a PDF.
+//
+// The report never contains secret material. Decoy flags are dealer-private and
+// are left out; decoy entries appear like any other custodian.
+func WriteReport(w io.Writer, m *Manifest) error {
+	digest, err := m.Digest()
+	if err != nil {
+		return err
+	}
+
+	custodians := append([]ManifestEntry(nil), m.Custodians...)
+	sort.SliceStable(custodians, func(i, j int) bool { return custodians[i].Index < custodians[j].Index })
+
+	notes := make([]string, 0, len(m.Notes))
+	for key := range m.Notes {
+		notes = append(notes, key)
+	}
+	sort.Strings(notes)
+
+	return reportTemplate.Execute(w, reportData{
+		Manifest:   m,
+		Custodians: custodians,
+		NoteKeys:   notes,
+		Digest:     groupHex(digest[:]),
+		Generated:  time.Now().UTC(),
+	})
+}
+
+// reportData is the input of reportTemplate.
+type reportData struct {
+	Manifest   *Manifest
+	Custodians []ManifestEntry
+	NoteKeys   []string
+	Digest     string
+	Generated  time.Time
+}
+
+// groupHex formats b as hexadecimal in space-separated groups of four
+// characters, which is easier to read aloud and compare on paper.
+func groupHex(b []byte) string {
+	s := hex.EncodeToString(b)
+	groups := make([]string, 0, (len(s)+3)/4)
+	for len(s) > 4 {
+		groups = append(groups, s[:4])
+		s = s[4:]
+	}
+	return strings.Join(append(groups, s), " ")
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"grouphex": func(f ShareFingerprint) string { return groupHex(f[:]) },
+	"utc":      func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04:05 MST") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Key ceremony report{{with .Manifest.Name}}: {{.}}{{end}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #000; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { border: 1px solid #444; padding: 0.4em; text-align: left; vertical-align: top; }
+.mono { font-family: monospace; }
+.signature { height: 3em; }
+@media print { body { margin: 1cm; } section { page-break-inside: avoid; } }
+</style>
+</head>
+<body>
+<h1>Key ceremony report{{with .Manifest.Name}}: {{.}}{{end}}</h1>
+<p>This document records how a secret was split. It contains no secret material.</p>
+
+<section>
+<h2>Policy</h2>
+<table>
+<tr><th>Set ID</th><td class="mono">{{.Manifest.SetID}}</td></tr>
+<tr><th>Threshold</th><td>{{.Manifest.Threshold}} of {{.Manifest.Parts}} shares required</td></tr>
+<tr><th>Created</th><td>{{utc .Manifest.CreatedAt}}</td></tr>
+{{- range .Manifest.Linked}}
+<tr><th>Linked set</th><td class="mono">{{.}}</td></tr>
+{{- end}}
+{{- $notes := .Manifest.Notes}}{{range .NoteKeys}}
+<tr><th>{{.}}</th><td>{{index $notes .}}</td></tr>
+{{- end}}
+</table>
+</section>
+
+<section>
+<h2>Custodians</h2>
+<table>
+<tr><th>Index</th><th>Custodian</th><th>Share fingerprint (SHA-256)</th></tr>
+{{- range .Custodians}}
+<tr><td>{{.Index}}</td><td>{{.Custodian}}</td><td class="mono">{{grouphex .Fingerprint}}</td></tr>
+{{- end}}
+</table>
+</section>
+
+<section>
+<h2>Verification</h2>
+<p>Manifest digest (SHA-256):</p>
+<p class="mono">{{.Digest}}</p>
+</section>
+
+<section>
+<h2>Signatures</h2>
+<table>
+<tr><th>Role</th><th>Name</th><th>Signature</th><th>Date</th></tr>
+{{- range .Custodians}}
+<tr class="signature"><td>Custodian, share {{.Index}}</td><td>{{.Custodian}}</td><td></td><td></td></tr>
+{{- end}}
+<tr class="signature"><td>Dealer</td><td></td><td></td><td></td></tr>
+<tr class="signature"><td>Witness</td><td></td><td></td><td></td></tr>
+</table>
+</section>
+
+<p><small>Generated {{utc .Generated}}</small></p>
+</body>
+</html>
+`))
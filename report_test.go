@@ -0,0 +1,80 @@
+package shamir
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteReport(t *testing.T) {
+	secret := []byte("ceremony secret")
+	sharing, _, err := SplitDualEscrow(secret,
+		EscrowPolicy{Name: "legal", Threshold: 2, Custodians: []string{"counsel", "<notary>", "auditor"}},
+		EscrowPolicy{Name: "operations", Threshold: 2, Custodians: []string{"alice", "bob"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := sharing.Manifest
+	m.Notes = map[string]string{"location": "Vault room B"}
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	report := buf.String()
+
+	digest, err := m.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		m.SetID.String(),
+		"2 of 3 shares required",
+		"counsel",
+		"&lt;notary&gt;", // Custodian names are escaped
+		"Vault room B",
+		groupHex(m.Custodians[0].Fingerprint[:]),
+		groupHex(digest[:]),
+		m.Linked[0].String(),
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+
+	for _, share := range sharing.Shares {
+		s, err := ParseShare(share)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(report, groupHex(s.Payload)) {
+			t.Fatal("report contains share material")
+		}
+	}
+}
+
+func TestReportHidesDecoys(t *testing.T) {
+	_, m, err := SplitWithDecoys([]byte("deniable"), 3, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(strings.ToLower(buf.String()), "decoy") {
+		t.Fatal("report reveals decoy flags")
+	}
+
+	// The digest must not depend on the dealer-private decoy flags.
+	before, _ := m.Digest()
+	for i := range m.Custodians {
+		m.Custodians[i].Decoy = !m.Custodians[i].Decoy
+	}
+	after, _ := m.Digest()
+	if before != after {
+		t.Fatal("digest depends on decoy flags")
+	}
+}
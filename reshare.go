@@ -0,0 +1,124 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// Reshare converts an existing (n, k) sharing into a new (newParts, newThreshold)
+// sharing of the same secret without ever reconstructing the secret.
+//
+// Each of the first oldThreshold shares is itself split with a fresh random
+// polynomial of degree newThreshold-1, and every new share is the Lagrange-
+// weighted sum of the resulting sub-shares. The constant term of the new
+// polynomial equals the secret, but the secret is never computed.
+//
+// Parameters:
+//   - shares: At least oldThreshold shares of one split (raw or enveloped)
+//   - oldThreshold: Threshold of the existing split
+//   - newParts, newThreshold: Parameters of the new split, as for Split
+//
+// Returns newParts shares with x-coordinates 1..newParts in the input format.
+// Enveloped shares keep their metadata but receive a new set ID and creation
+// time, and no label.
+func Reshare(shares [][]byte, oldThreshold, newParts, newThreshold int) ([][]byte, error) {
+	return reshare(shares, oldThreshold, newParts, newThreshold, rand.Reader)
+}
+
+// reshare implements Reshare with an explicit randomness source.
+func reshare(parts [][]byte, oldThreshold, newParts, newThreshold int, rng io.Reader) ([][]byte, error) {
+	if oldThreshold < 2 || oldThreshold > 255 {
+		return nil, NewValidationError("threshold", oldThreshold, "shamir: threshold must be between 2 and 255")
+	}
+	if err := validateSplitParams([]byte{0}, newParts, newThreshold); err != nil {
+		return nil, err
+	}
+
+	enveloped := len(parts) > 0 && IsEnvelope(parts[0])
+	var template *Share
+	raw := parts
+	if enveloped {
+		shares, err := parseEnvelopes(parts)
+		if err != nil {
+			return nil, err
+		}
+		for i, s := range shares {
+			if s.Threshold != oldThreshold || s.SetID != shares[0].SetID || s.Purpose != shares[0].Purpose {
+				return nil, fmt.Errorf("share %d: %w", i, ErrMismatchedShares)
+			}
+		}
+		raw = make([][]byte, len(shares))
+		for i, s := range shares {
+			raw[i] = append([]byte{s.Index}, s.Payload...)
+			secureZeroBytes(s.Payload)
+		}
+		defer func() {
+			for _, r := range raw {
+				secureZeroBytes(r)
+			}
+		}()
+		template = shares[0]
+	}
+
+	if err := validateCombineParams(raw); err != nil {
+		return nil, err
+	}
+	if len(raw) < oldThreshold {
+		return nil, &InsufficientSharesError{Have: len(raw), Need: oldThreshold}
+	}
+	raw = raw[:oldThreshold]
+
+	xCoords := make([]byte, len(raw))
+	for i, share := range raw {
+		xCoords[i] = share[0]
+	}
+	weights := lagrangeBasis(xCoords, 0)
+
+	newXCoords := make([]byte, newParts)
+	for i := range newXCoords {
+		newXCoords[i] = byte(i + 1)
+	}
+
+	payloadLen := len(raw[0]) - ShareOverhead
+	result := make([][]byte, newParts)
+	for j := range result {
+		result[j] = make([]byte, payloadLen+ShareOverhead)
+		result[j][0] = newXCoords[j]
+	}
+
+	for i, share := range raw {
+		subShares, err := splitAt(share[ShareOverhead:], newXCoords, newThreshold, rng, engine{})
+		if err != nil {
+			return nil, err
+		}
+		for j, sub := range subShares {
+			gfMulAddSlice(result[j][ShareOverhead:], sub[ShareOverhead:], weights[i])
+			secureZeroBytes(sub)
+		}
+	}
+
+	if !enveloped {
+		return result, nil
+	}
+
+	setID, err := newSetID()
+	if err != nil {
+		return nil, err
+	}
+	env := *template
+	env.Threshold = newThreshold
+	env.SetID = setID
+	env.Label = ""
+	env.CreatedAt = creationTime()
+	out := make([][]byte, newParts)
+	for j, share := range result {
+		env.Index = share[0]
+		env.Payload = share[ShareOverhead:]
+		if out[j], err = env.MarshalBinary(); err != nil {
+			return nil, err
+		}
+		secureZeroBytes(share)
+	}
+	return out, nil
+}
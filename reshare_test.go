@@ -0,0 +1,118 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReshare(t *testing.T) {
+	secret := []byte("custodians join and leave")
+
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		newParts     int
+		newThreshold int
+	}{
+		{"grow", 9, 5},
+		{"shrink", 3, 2},
+		{"same", 5, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reshared, err := Reshare(shares[2:], 3, tt.newParts, tt.newThreshold)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(reshared) != tt.newParts {
+				t.Fatalf("expected %d shares, got %d", tt.newParts, len(reshared))
+			}
+
+			reconstructed, err := Combine(reshared[len(reshared)-tt.newThreshold:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(reconstructed, secret) {
+				t.Fatal("reshared shares do not reconstruct the secret")
+			}
+
+		})
+	}
+
+	t.Run("insufficient shares", func(t *testing.T) {
+		if _, err := Reshare(shares[:2], 3, 4, 2); !errors.Is(err, ErrInsufficientShares) {
+			t.Fatalf("expected ErrInsufficientShares, got %v", err)
+		}
+	})
+}
+
+func TestReshareEnvelopes(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+
+	shares, err := SplitKey(key, AlgAES256GCM, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reshared, err := Reshare(shares, 2, 6, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recovered, err := CombineKey(reshared[1:5], AlgAES256GCM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, key) {
+		t.Fatal("reshared key mismatch")
+	}
+
+	var ierr *InsufficientSharesError
+	if _, err := CombineKey(reshared[:3], AlgAES256GCM); !errors.As(err, &ierr) || ierr.Need != 4 {
+		t.Fatalf("expected ErrInsufficientShares with the new threshold, got %v", err)
+	}
+}
+
+func TestReshareDropsLabelsAndCreationTime(t *testing.T) {
+	secret := []byte("audit trail")
+	shares, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithLabels("alice", "bob", "carol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Unix(1600000000, 0).UTC()
+	for i, share := range shares {
+		s, err := ParseShare(share)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.CreatedAt = old
+		if shares[i], err = s.MarshalBinary(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	before := time.Now().Add(-time.Second)
+	reshared, err := Reshare(shares, 2, 4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, share := range reshared {
+		s, err := ParseShare(share)
+		if err != nil {
+			t.Fatalf("share %d: %v", i, err)
+		}
+		if s.Label != "" {
+			t.Errorf("share %d: Label = %q, want none", i, s.Label)
+		}
+		if s.CreatedAt.Before(before) || s.CreatedAt.After(time.Now()) {
+			t.Errorf("share %d: CreatedAt = %v, not the time of the reshare", i, s.CreatedAt)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package shamir_test
+
+// Differential fuzz targets comparing Split and Combine against the reference
+// implementation in shamirtest. The seed corpus runs as part of go test; run
+// e.g. go test -fuzz=FuzzRoundTrip to explore.
+
+import (
+	"testing"
+
+	"github.com/morizta/go-shamir/shamirtest"
+)
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add([]byte("secret"), 5, 3)
+	f.Add([]byte{0}, 0, 0)
+	f.Add([]byte{0xff, 0x00, 0x80}, 253, 253) // 255 parts, threshold 255
+	f.Add([]byte("two hundred and fifty-five"), 253, 0)
+	f.Add(make([]byte, 100), 30, 17)
+	f.Add([]byte{}, 1, 1)
+
+	f.Fuzz(func(t *testing.T, secret []byte, parts, threshold int) {
+		if len(secret) > 64 {
+			secret = secret[:64]
+		}
+		parts, threshold = shamirtest.Params(parts, threshold)
+		if err := shamirtest.RoundTrip(secret, parts, threshold); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzCombine(f *testing.F) {
+	f.Add([]byte{1, 10, 20}, []byte{2, 30, 40}, []byte{3, 50, 60})
+	f.Add([]byte{0, 's'}, []byte{7, 'x'}, []byte{})     // x = 0 share
+	f.Add([]byte{255, 1}, []byte{254, 2}, []byte{1, 3}) // highest x-coordinates
+	f.Add([]byte{1, 2}, []byte{1, 3}, []byte{})         // duplicate x
+	f.Add([]byte{1, 2, 3}, []byte{2, 3}, []byte{})      // different lengths
+
+	f.Fuzz(func(t *testing.T, a, b, c []byte) {
+		shares := [][]byte{a, b}
+		if len(c) > 0 {
+			shares = append(shares, c)
+		}
+		if err := shamirtest.DiffCombine(shares); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package shamir
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Prime-field sharing.
+//
+// Split and Combine work byte-wise in GF(256), which keeps shares the size of
+// the secret but means a share is not an element of any group a threshold
+// signature scheme can compute with. SplitScalar shares an integer modulo the
+// order of an elliptic-curve group instead, so shares can be handed directly
+// to threshold ECDSA/EdDSA/Schnorr protocols: Σ λ_i·s_i over a quorum equals
+// the secret, with λ_i from ScalarLagrangeCoefficients.
+
+// ScalarSize is the length of an encoded scalar share value.
+const ScalarSize = 32
+
+// ScalarField is a prime field of at most 256 bits, the scalar field of an
+// elliptic-curve group.
+type ScalarField struct {
+	Name  string
+	Order *big.Int // Prime modulus
+}
+
+// Scalar fields of common curves.
+var (
+	// Secp256k1 is the scalar field of secp256k1 (Bitcoin, Ethereum, threshold ECDSA).
+	Secp256k1 = &ScalarField{Name: "secp256k1", Order: mustHexInt("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")}
+
+	// P256 is the scalar field of NIST P-256.
+	P256 = &ScalarField{Name: "P-256", Order: elliptic.P256().Params().N}
+
+	// Ed25519 is the scalar field of the prime-order subgroup of edwards25519 (FROST, threshold EdDSA).
+	Ed25519 = &ScalarField{Name: "ed25519", Order: mustHexInt("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed")}
+)
+
+func mustHexInt(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("shamir: invalid field order " + s)
+	}
+	return n
+}
+
+// ScalarShare is one share of a SplitScalar secret: the sharing polynomial
+// evaluated at Index.
+type ScalarShare struct {
+	Index byte             // Non-zero x-coordinate
+	Value [ScalarSize]byte // Big-endian y-value modulo the field order
+}
+
+// Int returns the share value as an integer.
+func (s ScalarShare) Int() *big.Int {
+	return new(big.Int).SetBytes(s.Value[:])
+}
+
+// Bytes encodes the share like a raw share from Split: [index][32-byte value].
+func (s ScalarShare) Bytes() []byte {
+	return append([]byte{s.Index}, s.Value[:]...)
+}
+
+// ParseScalarShare decodes a share encoded by ScalarShare.Bytes and checks
+// that its value lies in field.
+func ParseScalarShare(field *ScalarField, data []byte) (ScalarShare, error) {
+	var s ScalarShare
+	if len(data) != ShareOverhead+ScalarSize {
+		return s, NewValidationError("share", len(data), "shamir: scalar share must be 33 bytes")
+	}
+	s.Index = data[0]
+	copy(s.Value[:], data[ShareOverhead:])
+	if err := s.validate(field); err != nil {
+		return ScalarShare{}, err
+	}
+	return s, nil
+}
+
+func (s ScalarShare) validate(field *ScalarField) error {
+	if s.Index == 0 {
+		return NewValidationError("share", 0, "shamir: scalar share index cannot be zero")
+	}
+	if s.Int().Cmp(field.Order) >= 0 {
+		return fmt.Errorf("%w: share %d is not reduced modulo the %s order", ErrScalarOutOfRange, s.Index, field.Name)
+	}
+	return nil
+}
+
+// SplitScalar splits secret, an integer in [0, field.Order), into parts shares
+// evaluated at x = 1 through parts, any threshold of which reconstruct it with
+// CombineScalar. The polynomial coefficients are drawn uniformly from the field
+// with crypto/rand.
+//
+// big.Int values cannot be reliably wiped, so the coefficients may linger in
+// memory until they are garbage collected.
+func SplitScalar(field *ScalarField, secret *big.Int, parts, threshold int) ([]ScalarShare, error) {
+	return splitScalarField(field, secret, parts, threshold, rand.Reader)
+}
+
+// SplitScalarBytes is SplitScalar for a fixed-size big-endian scalar.
+func SplitScalarBytes(field *ScalarField, secret [ScalarSize]byte, parts, threshold int) ([]ScalarShare, error) {
+	return SplitScalar(field, new(big.Int).SetBytes(secret[:]), parts, threshold)
+}
+
+// splitScalarField implements SplitScalar with an explicit randomness source.
+func splitScalarField(field *ScalarField, secret *big.Int, parts, threshold int, rng io.Reader) ([]ScalarShare, error) {
+	if secret == nil || secret.Sign() < 0 || secret.Cmp(field.Order) >= 0 {
+		return nil, fmt.Errorf("%w: secret must be in [0, %s order)", ErrScalarOutOfRange, field.Name)
+	}
+	// The byte-wise checks accept any non-empty secret; only parts and threshold matter.
+	if err := validateSplitParams([]byte{0}, parts, threshold); err != nil {
+		return nil, err
+	}
+
+	coeffs := make([]*big.Int, threshold)
+	coeffs[0] = secret
+	for i := 1; i < threshold; i++ {
+		c, err := rand.Int(rng, field.Order)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrEntropyUnavailable, err)
+		}
+		coeffs[i] = c
+	}
+	defer func() {
+		for _, c := range coeffs[1:] {
+			c.SetInt64(0)
+		}
+	}()
+
+	shares := make([]ScalarShare, parts)
+	y := new(big.Int)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+		// Horner's method from the highest-degree coefficient down.
+		y.SetInt64(0)
+		for j := threshold - 1; j >= 0; j-- {
+			y.Mul(y, x).Add(y, coeffs[j]).Mod(y, field.Order)
+		}
+		shares[i].Index = byte(i + 1)
+		y.FillBytes(shares[i].Value[:])
+	}
+	y.SetInt64(0)
+	return shares, nil
+}
+
+// CombineScalar reconstructs a SplitScalar secret from at least threshold
+// shares. Like Combine, it cannot tell whether enough shares were supplied;
+// fewer than threshold yield a wrong value.
+func CombineScalar(field *ScalarField, shares []ScalarShare) (*big.Int, error) {
+	lambdas, err := ScalarLagrangeCoefficients(field, shares)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := new(big.Int)
+	term := new(big.Int)
+	for i, s := range shares {
+		term.Mul(lambdas[i], s.Int())
+		secret.Add(secret, term)
+	}
+	term.SetInt64(0)
+	return secret.Mod(secret, field.Order), nil
+}
+
+// CombineScalarBytes is CombineScalar returning a fixed-size big-endian scalar.
+func CombineScalarBytes(field *ScalarField, shares []ScalarShare) ([ScalarSize]byte, error) {
+	var out [ScalarSize]byte
+	secret, err := CombineScalar(field, shares)
+	if err != nil {
+		return out, err
+	}
+	secret.FillBytes(out[:])
+	secret.SetInt64(0)
+	return out, nil
+}
+
+// ScalarLagrangeCoefficients returns the Lagrange coefficients λ_i at x = 0 for
+// the indices of shares, so that Σ λ_i·s_i is the secret. Threshold protocols
+// use them to turn a quorum's shares into additive shares of the secret, or
+// to combine partial results computed in the exponent.
+func ScalarLagrangeCoefficients(field *ScalarField, shares []ScalarShare) ([]*big.Int, error) {
+	if len(shares) < 2 {
+		return nil, ErrTooFewParts
+	}
+	seen := make(map[byte]bool, len(shares))
+	for i, s := range shares {
+		if err := s.validate(field); err != nil {
+			return nil, err
+		}
+		if seen[s.Index] {
+			return nil, NewValidationError("share", i, "shamir: duplicate share identifier detected")
+		}
+		seen[s.Index] = true
+	}
+
+	lambdas := make([]*big.Int, len(shares))
+	num, den := new(big.Int), new(big.Int)
+	for i, si := range shares {
+		num.SetInt64(1)
+		den.SetInt64(1)
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			// λ_i = Π x_j / (x_j - x_i)
+			xj := big.NewInt(int64(sj.Index))
+			num.Mul(num, xj)
+			den.Mul(den, xj.Sub(xj, big.NewInt(int64(si.Index))))
+		}
+		den.Mod(den, field.Order)
+		lambdas[i] = new(big.Int).Mul(num, den.ModInverse(den, field.Order))
+		lambdas[i].Mod(lambdas[i], field.Order)
+	}
+	return lambdas, nil
+}
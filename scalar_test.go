@@ -0,0 +1,144 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestSplitCombineScalar(t *testing.T) {
+	for _, field := range []*ScalarField{Secp256k1, P256, Ed25519} {
+		t.Run(field.Name, func(t *testing.T) {
+			// The largest scalar exercises the modular reduction.
+			maxScalar := new(big.Int).Sub(field.Order, big.NewInt(1))
+			for _, secret := range []*big.Int{big.NewInt(0), big.NewInt(42), maxScalar} {
+				shares, err := SplitScalar(field, secret, 5, 3)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, quorum := range [][]ScalarShare{shares[:3], {shares[4], shares[1], shares[3]}, shares} {
+					got, err := CombineScalar(field, quorum)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got.Cmp(secret) != 0 {
+						t.Fatalf("CombineScalar = %x, want %x", got, secret)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSplitScalarBytes(t *testing.T) {
+	var secret [ScalarSize]byte
+	copy(secret[:], bytes.Repeat([]byte{0x7f}, ScalarSize))
+	shares, err := SplitScalarBytes(Secp256k1, secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CombineScalarBytes(Secp256k1, shares[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != secret {
+		t.Fatalf("CombineScalarBytes = %x, want %x", got, secret)
+	}
+}
+
+func TestSplitScalarValidation(t *testing.T) {
+	if _, err := SplitScalar(P256, P256.Order, 3, 2); !errors.Is(err, ErrScalarOutOfRange) {
+		t.Errorf("secret = order: got %v, want ErrScalarOutOfRange", err)
+	}
+	if _, err := SplitScalar(P256, big.NewInt(-1), 3, 2); !errors.Is(err, ErrScalarOutOfRange) {
+		t.Errorf("negative secret: got %v, want ErrScalarOutOfRange", err)
+	}
+	var verr *ValidationError
+	if _, err := SplitScalar(P256, big.NewInt(1), 3, 4); !errors.As(err, &verr) || verr.Field != "threshold" {
+		t.Errorf("threshold > parts: got %v", err)
+	}
+	if _, err := splitScalarField(P256, big.NewInt(1), 3, 2, bytes.NewReader(nil)); !errors.Is(err, ErrEntropyUnavailable) {
+		t.Errorf("exhausted rng: got %v, want ErrEntropyUnavailable", err)
+	}
+}
+
+func TestCombineScalarValidation(t *testing.T) {
+	shares, err := SplitScalar(Ed25519, big.NewInt(7), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CombineScalar(Ed25519, shares[:1]); !errors.Is(err, ErrTooFewParts) {
+		t.Errorf("one share: got %v, want ErrTooFewParts", err)
+	}
+	var verr *ValidationError
+	if _, err := CombineScalar(Ed25519, []ScalarShare{shares[0], shares[0]}); !errors.As(err, &verr) {
+		t.Errorf("duplicate shares: got %v, want ValidationError", err)
+	}
+
+	// A secp256k1 share is generally not reduced modulo the smaller ed25519 order.
+	unreduced := ScalarShare{Index: 1}
+	Secp256k1.Order.FillBytes(unreduced.Value[:])
+	unreduced.Value[ScalarSize-1]--
+	if _, err := CombineScalar(Ed25519, []ScalarShare{unreduced, shares[1]}); !errors.Is(err, ErrScalarOutOfRange) {
+		t.Errorf("unreduced share: got %v, want ErrScalarOutOfRange", err)
+	}
+}
+
+func TestScalarShareEncoding(t *testing.T) {
+	shares, err := SplitScalar(Secp256k1, big.NewInt(99), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := shares[2].Bytes()
+	if len(data) != ShareOverhead+ScalarSize || data[0] != 3 {
+		t.Fatalf("Bytes = %x", data)
+	}
+	parsed, err := ParseScalarShare(Secp256k1, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != shares[2] {
+		t.Fatalf("ParseScalarShare = %+v, want %+v", parsed, shares[2])
+	}
+	if _, err := ParseScalarShare(Secp256k1, data[1:]); err == nil {
+		t.Error("ParseScalarShare accepted a truncated share")
+	}
+	data[0] = 0
+	if _, err := ParseScalarShare(Secp256k1, data); err == nil {
+		t.Error("ParseScalarShare accepted index 0")
+	}
+}
+
+// TestScalarLagrangeInExponent checks the property threshold signing relies
+// on: combining the public shares s_i·G with the Lagrange coefficients yields
+// the public key s·G.
+func TestScalarLagrangeInExponent(t *testing.T) {
+	curve := elliptic.P256()
+	secret := big.NewInt(123456789)
+	shares, err := SplitScalar(P256, secret, 4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quorum := shares[1:]
+	lambdas, err := ScalarLagrangeCoefficients(P256, quorum)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var x, y *big.Int
+	for i, s := range quorum {
+		px, py := curve.ScalarBaseMult(s.Value[:])
+		px, py = curve.ScalarMult(px, py, lambdas[i].Bytes())
+		if x == nil {
+			x, y = px, py
+		} else {
+			x, y = curve.Add(x, y, px, py)
+		}
+	}
+	wantX, wantY := curve.ScalarBaseMult(secret.Bytes())
+	if x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+		t.Fatal("Σ λ_i·(s_i·G) != s·G")
+	}
+}
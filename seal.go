@@ -0,0 +1,135 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+)
+
+// Hybrid sealing.
+//
+// Splitting a large secret directly makes every share as large as the secret
+// and costs threshold field operations per byte. SealSplit instead encrypts
+// the payload with AES-256-GCM under a fresh key and splits only the 32-byte
+// key, so the shares stay small and the ciphertext can be stored anywhere.
+//
+// Sealed ciphertext format:
+//
+//	[3 bytes]  magic 0x00 'S' 'C'
+//	[1 byte]   format version (1)
+//	[16 bytes] set ID of the key shares
+//	[12 bytes] GCM nonce
+//	[n bytes]  AES-256-GCM ciphertext and tag
+//
+// The magic, version and set ID are authenticated as additional data, so a
+// ciphertext cannot be paired with key shares from another seal.
+
+// sealMagic identifies a sealed ciphertext.
+var sealMagic = [3]byte{0x00, 'S', 'C'}
+
+const (
+	sealFormatVersion = 1
+	sealHeaderSize    = len(sealMagic) + 1 + len(SetID{})
+	sealNonceSize     = 12
+)
+
+// SealSplit encrypts plaintext with AES-256-GCM under a random key and splits
+// the key into parts shares, threshold of which are required to decrypt. It
+// returns the ciphertext and the key shares, which are enveloped shares as
+// from SplitKey with the ciphertext's set ID. Pass both to SealCombine.
+func SealSplit(plaintext []byte, parts, threshold int) (ciphertext []byte, keyShares [][]byte, err error) {
+	key := make([]byte, 32)
+	defer secureZeroBytes(key)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, fmt.Errorf("shamir: failed to generate sealing key: %w", err)
+	}
+	setID, err := newSetID()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	o := newOptions([]Option{WithParts(parts), WithThreshold(threshold)})
+	keyShares, err = splitEnvelopes(key, o, Share{Algorithm: AlgAES256GCM, SetID: setID, CreatedAt: creationTime()})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := sealAEAD(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := append(append(sealMagic[:], sealFormatVersion), setID[:]...)
+	nonce := make([]byte, sealNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("shamir: failed to generate nonce: %w", err)
+	}
+	ciphertext = make([]byte, 0, sealHeaderSize+sealNonceSize+len(plaintext)+aead.Overhead())
+	ciphertext = append(append(ciphertext, header...), nonce...)
+	ciphertext = aead.Seal(ciphertext, nonce, plaintext, header)
+	return ciphertext, keyShares, nil
+}
+
+// SealCombine reconstructs the key from at least threshold key shares and
+// decrypts a ciphertext produced by SealSplit. It returns ErrMismatchedShares
+// if the shares belong to another seal and ErrInvalidSealed if the ciphertext
+// is malformed or has been tampered with.
+func SealCombine(ciphertext []byte, keyShares [][]byte) ([]byte, error) {
+	if len(ciphertext) < sealHeaderSize+sealNonceSize || !bytes.HasPrefix(ciphertext, sealMagic[:]) {
+		return nil, ErrInvalidSealed
+	}
+	if v := ciphertext[len(sealMagic)]; v != sealFormatVersion {
+		return nil, fmt.Errorf("%w: sealed ciphertext version %d", ErrUnsupportedVersion, v)
+	}
+	header := ciphertext[:sealHeaderSize]
+	var setID SetID
+	copy(setID[:], header[len(sealMagic)+1:])
+
+	shares, err := parseEnvelopes(keyShares)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, s := range shares {
+			secureZeroBytes(s.Payload)
+		}
+	}()
+	for i, s := range shares {
+		if s.SetID != setID {
+			return nil, fmt.Errorf("share %d: %w: not a key share of this ciphertext", i, ErrMismatchedShares)
+		}
+		if s.Algorithm != AlgAES256GCM {
+			return nil, fmt.Errorf("share %d: %w: recorded %q, expected %q", i, ErrAlgorithmMismatch, s.Algorithm, AlgAES256GCM)
+		}
+	}
+
+	key, err := combineEnvelopes(shares, engine{})
+	if err != nil {
+		return nil, err
+	}
+	defer freeSecret(key)
+	if err := validateKeyLength(key, AlgAES256GCM); err != nil {
+		return nil, err
+	}
+
+	aead, err := sealAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := ciphertext[sealHeaderSize : sealHeaderSize+sealNonceSize]
+	plaintext, err := aead.Open(nil, nonce, ciphertext[sealHeaderSize+sealNonceSize:], header)
+	if err != nil {
+		return nil, ErrInvalidSealed
+	}
+	return plaintext, nil
+}
+
+// sealAEAD returns AES-256-GCM keyed with key.
+func sealAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
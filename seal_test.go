@@ -0,0 +1,85 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSealSplitCombine(t *testing.T) {
+	plaintext := bytes.Repeat([]byte("large archive payload "), 50000)
+
+	ciphertext, keyShares, err := SealSplit(plaintext, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keyShares) != 5 {
+		t.Fatalf("got %d key shares, want 5", len(keyShares))
+	}
+	for i, share := range keyShares {
+		s, err := ParseShare(share)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(s.Payload) != 32 || s.Algorithm != AlgAES256GCM || s.Threshold != 3 {
+			t.Fatalf("key share %d: unexpected %+v", i, s)
+		}
+	}
+	if len(ciphertext) != sealHeaderSize+sealNonceSize+len(plaintext)+16 {
+		t.Fatalf("unexpected ciphertext length %d", len(ciphertext))
+	}
+
+	got, err := SealCombine(ciphertext, [][]byte{keyShares[4], keyShares[1], keyShares[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatal("decrypted plaintext mismatch")
+	}
+
+	t.Run("too few shares", func(t *testing.T) {
+		if _, err := SealCombine(ciphertext, keyShares[:2]); !errors.Is(err, ErrInsufficientShares) {
+			t.Fatalf("expected ErrInsufficientShares, got %v", err)
+		}
+	})
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		for _, offset := range []int{4, sealHeaderSize, len(ciphertext) - 1} {
+			tampered := append([]byte(nil), ciphertext...)
+			tampered[offset] ^= 1
+			if _, err := SealCombine(tampered, keyShares[:3]); err == nil {
+				t.Errorf("offset %d: tampering not detected", offset)
+			}
+		}
+		if _, err := SealCombine(ciphertext[:sealHeaderSize], keyShares[:3]); !errors.Is(err, ErrInvalidSealed) {
+			t.Errorf("expected ErrInvalidSealed for a truncated ciphertext, got %v", err)
+		}
+	})
+
+	t.Run("shares of another seal", func(t *testing.T) {
+		_, otherShares, err := SealSplit([]byte("other"), 5, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := SealCombine(ciphertext, otherShares[:3]); !errors.Is(err, ErrMismatchedShares) {
+			t.Fatalf("expected ErrMismatchedShares, got %v", err)
+		}
+	})
+
+	t.Run("empty plaintext", func(t *testing.T) {
+		ciphertext, keyShares, err := SealSplit(nil, 2, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := SealCombine(ciphertext, keyShares)
+		if err != nil || len(got) != 0 {
+			t.Fatalf("got %q, %v", got, err)
+		}
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		if _, _, err := SealSplit(plaintext, 2, 3); err == nil {
+			t.Fatal("expected an error for threshold > parts")
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package shamir
+
+import (
+	"runtime"
+	"sync"
+)
+
+// SecretBuffer holds a reconstructed secret and wipes it when closed, giving
+// callers explicit control over how long the secret stays in memory. The
+// buffer is also wiped if it becomes unreachable without being closed, but
+// that happens at the garbage collector's discretion; call Close as soon as
+// the secret is no longer needed.
+type SecretBuffer struct {
+	b       []byte
+	once    sync.Once
+	cleanup runtime.Cleanup
+}
+
+// secretBufferWiped, if set by a test, is called once an unreachable buffer
+// has been wiped by its cleanup.
+var secretBufferWiped func()
+
+// orphanedSecret is the cleanup argument of a SecretBuffer. The hook is
+// captured when the buffer is created so the cleanup goroutine never reads
+// secretBufferWiped itself.
+type orphanedSecret struct {
+	b     []byte
+	wiped func()
+}
+
+// newSecretBuffer takes ownership of b, which must not be used elsewhere.
+func newSecretBuffer(b []byte) *SecretBuffer {
+	s := &SecretBuffer{b: b}
+	s.cleanup = runtime.AddCleanup(s, wipeOrphanedSecret, orphanedSecret{b, secretBufferWiped})
+	return s
+}
+
+// wipeOrphanedSecret wipes the secret of a buffer that was never closed.
+func wipeOrphanedSecret(o orphanedSecret) {
+	freeSecret(o.b)
+	if o.wiped != nil {
+		o.wiped()
+	}
+}
+
+// CombineBuffer is like CombineWithOptions but returns the secret in a
+// SecretBuffer, which the caller must close.
+func CombineBuffer(parts [][]byte, opts ...Option) (*SecretBuffer, error) {
+	secret, err := CombineWithOptions(parts, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newSecretBuffer(secret), nil
+}
+
+// Bytes returns the secret. The slice aliases the buffer: it is wiped by Close
+// and must not be retained. Bytes returns nil once the buffer is closed.
+func (s *SecretBuffer) Bytes() []byte {
+	return s.b
+}
+
+// Len returns the length of the secret, or 0 once the buffer is closed.
+func (s *SecretBuffer) Len() int {
+	return len(s.b)
+}
+
+// Close wipes the secret, releasing any locked memory holding it (see
+// SetMemoryLocking). It is safe to call more than once and always returns nil.
+// Close must not be called concurrently with Bytes.
+func (s *SecretBuffer) Close() error {
+	s.once.Do(func() {
+		s.cleanup.Stop()
+		freeSecret(s.b)
+		s.b = nil
+	})
+	return nil
+}
+
+// String keeps the secret out of logs and formatted output.
+func (s *SecretBuffer) String() string {
+	return "shamir.SecretBuffer[REDACTED]"
+}
+
+// GoString keeps the secret out of %#v output.
+func (s *SecretBuffer) GoString() string {
+	return s.String()
+}
@@ -0,0 +1,103 @@
+package shamir
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCombineBuffer(t *testing.T) {
+	secret := []byte("buffered secret")
+	shares, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithIntegrity(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := CombineBuffer(shares[1:], WithIntegrity(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), secret) || buf.Len() != len(secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+
+	for _, format := range []string{"%v", "%s", "%#v", "%+v"} {
+		if out := fmt.Sprintf(format, buf); strings.Contains(out, string(secret)) || !strings.Contains(out, "REDACTED") {
+			t.Errorf("%s formatting exposed the secret: %q", format, out)
+		}
+	}
+
+	b := buf.Bytes()
+	if err := buf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, make([]byte, len(secret))) {
+		t.Fatal("Close did not wipe the secret")
+	}
+	if buf.Bytes() != nil || buf.Len() != 0 {
+		t.Fatal("closed buffer still exposes the secret")
+	}
+	if err := buf.Close(); err != nil {
+		t.Fatal("second Close failed:", err)
+	}
+
+	if _, err := CombineBuffer(shares[:1]); err == nil {
+		t.Fatal("expected an error for a single share")
+	}
+}
+
+func TestSecretBufferWipedByGC(t *testing.T) {
+	shares, err := Split([]byte("forgotten secret"), 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wiped := make(chan struct{})
+	secretBufferWiped = func() { close(wiped) }
+	buf, err := CombineBuffer(shares)
+	secretBufferWiped = nil
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	buf = nil
+
+	deadline := time.After(5 * time.Second)
+	for done := false; !done; {
+		runtime.GC()
+		select {
+		case <-wiped:
+			done = true
+		case <-deadline:
+			t.Fatal("unreachable buffer was not wiped")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	if !bytes.Equal(b, make([]byte, len(b))) {
+		t.Fatal("cleanup did not wipe the secret")
+	}
+}
+
+func TestSecretBufferLocked(t *testing.T) {
+	enableMemoryLocking(t)
+	before := lockedCount()
+
+	shares, err := Split(bytes.Repeat([]byte{1}, 100), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := CombineBuffer(shares[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isLocked(buf.Bytes()) {
+		t.Fatal("secret is not in locked memory")
+	}
+	buf.Close()
+	if lockedCount() != before {
+		t.Fatal("Close did not release locked memory")
+	}
+}
@@ -10,39 +10,119 @@ func SplitWithIntegrity(secret []byte, parts, threshold int) ([][]byte, error) {
 		return nil, err
 	}
 
+	return withIntegrityChecks(shares), nil
+}
+
+// withIntegrityChecks appends a CRC32 to every share, wiping the unchecked originals.
+func withIntegrityChecks(shares [][]byte) [][]byte {
 	secureShares := make([][]byte, len(shares))
 	for i, share := range shares {
 		secureShares[i] = addIntegrityCheck(share)
+		secureZeroBytes(share)
 	}
 
-	return secureShares, nil
+	return secureShares
 }
 
+// CombineWithIntegrity verifies the CRC32 of every share from
+// SplitWithIntegrity and reconstructs the secret. If any checksum fails it
+// returns an *IntegrityError listing all the failing shares and no secret.
 func CombineWithIntegrity(parts [][]byte) ([]byte, error) {
-	if len(parts) < 2 {
+	enveloped, err := checkLegacyFormat("CombineWithIntegrity", parts, "CombineWithOptions; enveloped shares carry their own CRC32")
+	if err != nil {
+		return nil, err
+	}
+	if enveloped {
+		return combinePurposeBound(parts, newOptions(nil))
+	}
+	if err := checkIntegrityLayout("CombineWithIntegrity", parts, "Combine"); err != nil {
+		return nil, err
+	}
+	return combineWithIntegrity(parts, engine{})
+}
+
+// combineWithIntegrity implements CombineWithIntegrity with the given execution engine.
+func combineWithIntegrity(parts [][]byte, eng engine) ([]byte, error) {
+	if len(parts) < eng.minParts() {
 		return nil, ErrTooFewParts
 	}
 
 	validatedParts := make([][]byte, len(parts))
+	defer func() {
+		for _, validated := range validatedParts {
+			secureZeroBytes(validated)
+			auditRelease(validated)
+		}
+	}()
+
+	var bad *IntegrityError
 	for i, part := range parts {
 		validated, err := validateIntegrityCheck(part)
 		if err != nil {
-			return nil, fmt.Errorf("share %d integrity check failed: %w", i, err)
+			// Check the rest too, so every damaged share is reported.
+			if bad == nil {
+				bad = &IntegrityError{}
+			}
+			bad.BadShares = append(bad.BadShares, i)
+			bad.XCoordinates = append(bad.XCoordinates, part[0])
+			continue
+		}
+		if len(validated) == len(part) {
+			// Too short to carry a checksum and returned as-is; copy it so the
+			// deferred cleanup never wipes caller-owned data.
+			validated = append([]byte(nil), validated...)
 		}
+		auditTrack("integrity.validated", validated)
 		validatedParts[i] = validated
 	}
+	if bad != nil {
+		return nil, bad
+	}
 
-	return Combine(validatedParts)
+	return combine(validatedParts, eng)
 }
 
-func SplitSecure(secret []byte, parts, threshold int, enforceThreshold bool) ([][]byte, error) {
-	if enforceThreshold && len(secret) > 0 {
-		if parts < threshold {
-			return nil, ErrInvalidThreshold
+// VerifyIntegrity checks the CRC32 of a share produced by SplitWithIntegrity
+// without reconstructing anything. Returns ErrIntegrityCheckFailed on mismatch
+// and ErrTooShort if the share cannot carry a checksum. Enveloped shares are
+// checked against their own CRC32 (see SetStrictMigration).
+func VerifyIntegrity(share []byte) error {
+	enveloped, err := checkLegacyFormat("VerifyIntegrity", [][]byte{share}, "ParseShare")
+	if err != nil {
+		return err
+	}
+	if enveloped {
+		s, err := ParseShare(share)
+		if err != nil {
+			return err
 		}
+		secureZeroBytes(s.Payload)
+		return nil
+	}
+	if len(share) < 6 {
+		return ErrTooShort
 	}
+	validated, err := validateIntegrityCheck(share)
+	if err != nil {
+		return err
+	}
+	secureZeroBytes(validated)
+	return nil
+}
 
-	shares, err := Split(secret, parts, threshold)
+// SplitSecure splits secret like Split and wipes it afterwards. With
+// enforceThreshold, the shares are enveloped (see ParseShare) and record the
+// threshold, so that CombineSecure and every other combine can refuse too
+// few of them instead of interpolating a wrong secret; without it they are
+// raw Split shares.
+func SplitSecure(secret []byte, parts, threshold int, enforceThreshold bool) ([][]byte, error) {
+	var shares [][]byte
+	var err error
+	if enforceThreshold {
+		shares, err = splitRecordingThreshold(secret, parts, threshold)
+	} else {
+		shares, err = Split(secret, parts, threshold)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -52,8 +132,37 @@ func SplitSecure(secret []byte, parts, threshold int, enforceThreshold bool) ([]
 	return shares, nil
 }
 
+// splitRecordingThreshold implements SplitSecure with enforceThreshold.
+func splitRecordingThreshold(secret []byte, parts, threshold int) ([][]byte, error) {
+	if len(secret) > 0 && parts < threshold {
+		return nil, ErrInvalidThreshold
+	}
+	if err := checkSecretSize(secret); err != nil {
+		return nil, err
+	}
+	o := newOptions([]Option{WithParts(parts), WithThreshold(threshold)})
+	return splitEnvelopes(secret, o, Share{CreatedAt: creationTime()})
+}
+
+// CombineSecure reconstructs the secret and overwrites the shares afterwards.
+//
+// Enveloped shares, such as those of SplitSecure with enforceThreshold, are
+// held to the threshold recorded in them: fewer shares fail with an
+// *InsufficientSharesError whatever expectedThreshold is, and a non-zero
+// expectedThreshold that differs from the recorded one fails with
+// ErrMismatchedShares rather than being trusted over the shares. Raw shares
+// record no threshold, so for them expectedThreshold is the only check: fewer
+// than that many shares fail with ErrInsufficientShares; 0 disables it.
 func CombineSecure(parts [][]byte, expectedThreshold int) ([]byte, error) {
-	if expectedThreshold > 0 && len(parts) < expectedThreshold {
+	if len(parts) > 0 && IsEnvelope(parts[0]) {
+		threshold, err := recordedThreshold(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		if expectedThreshold > 0 && expectedThreshold != threshold {
+			return nil, fmt.Errorf("%w: shares record threshold %d, expected %d", ErrMismatchedShares, threshold, expectedThreshold)
+		}
+	} else if expectedThreshold > 0 && len(parts) < expectedThreshold {
 		return nil, ErrInsufficientShares
 	}
 
@@ -67,4 +176,15 @@ func CombineSecure(parts [][]byte, expectedThreshold int) ([]byte, error) {
 	}
 
 	return secret, nil
-}
\ No newline at end of file
+}
+
+// recordedThreshold returns the threshold recorded in an enveloped share.
+// Combine checks that the other shares record the same one.
+func recordedThreshold(share []byte) (int, error) {
+	s, err := ParseShare(share)
+	if err != nil {
+		return 0, err
+	}
+	secureZeroBytes(s.Payload)
+	return s.Threshold, nil
+}
@@ -3,7 +3,6 @@ package shamir
 import (
 	"hash/crc32"
 	"runtime"
-	"unsafe"
 )
 
 func secureZeroBytes(b []byte) {
@@ -18,28 +17,14 @@ func secureZeroBytes(b []byte) {
 	runtime.KeepAlive(b)
 }
 
+// secureOverwriteSlice zeroes slice with clear, which compiles to wide
+// stores without unaligned pointer accesses.
 func secureOverwriteSlice(slice []byte) {
 	if len(slice) == 0 {
 		return
 	}
 	
-	n := len(slice)
-	ptr := unsafe.Pointer(&slice[0])
-	
-	for i := 0; i < n; i += 8 {
-		end := i + 8
-		if end > n {
-			end = n
-		}
-		
-		if end-i >= 8 {
-			*(*uint64)(unsafe.Pointer(uintptr(ptr) + uintptr(i))) = 0
-		} else {
-			for j := i; j < end; j++ {
-				slice[j] = 0
-			}
-		}
-	}
+	clear(slice)
 	
 	runtime.KeepAlive(slice)
 }
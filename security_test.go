@@ -2,6 +2,8 @@ package shamir
 
 import (
 	"bytes"
+	"errors"
+	"slices"
 	"testing"
 )
 
@@ -97,6 +99,31 @@ func TestSecureFunctions(t *testing.T) {
 		}
 	})
 
+	t.Run("integrity failures name every bad share", func(t *testing.T) {
+		shares, err := SplitWithIntegrity(secret, 5, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares[1][3] ^= 0x40
+		shares[4][len(shares[4])-1] ^= 0x01
+
+		_, err = CombineWithIntegrity(shares)
+		var ierr *IntegrityError
+		if !errors.As(err, &ierr) || !errors.Is(err, ErrIntegrityCheckFailed) {
+			t.Fatalf("expected *IntegrityError, got %v", err)
+		}
+		if !slices.Equal(ierr.BadShares, []int{1, 4}) || !bytes.Equal(ierr.XCoordinates, []byte{shares[1][0], shares[4][0]}) {
+			t.Errorf("got bad shares %v at x-coordinates %v, want [1 4] at [%d %d]", ierr.BadShares, ierr.XCoordinates, shares[1][0], shares[4][0])
+		}
+
+		if _, err := CombineWithOptions(shares, WithIntegrity(true)); !errors.As(err, &ierr) || len(ierr.BadShares) != 2 {
+			t.Errorf("CombineWithOptions: expected *IntegrityError for 2 shares, got %v", err)
+		}
+		if got, err := CombineWithIntegrity([][]byte{shares[0], shares[2], shares[3]}); err != nil || !bytes.Equal(got, secret) {
+			t.Errorf("intact shares: %v", err)
+		}
+	})
+
 	t.Run("threshold enforcement", func(t *testing.T) {
 		shares, err := Split(secret, 5, 3)
 		if err != nil {
@@ -117,4 +144,76 @@ func TestSecureFunctions(t *testing.T) {
 			t.Error("secure combine failed")
 		}
 	})
+
+	t.Run("threshold recorded in shares", func(t *testing.T) {
+		shares, err := SplitSecure(bytes.Clone(secret), 5, 3, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, share := range shares {
+			s, err := ParseShare(share)
+			if err != nil || s.Threshold != 3 {
+				t.Fatalf("share %d does not record threshold 3: %v", i, err)
+			}
+		}
+
+		// CombineSecure overwrites the shares it combines.
+		cloneShares := func(parts [][]byte) [][]byte {
+			clones := make([][]byte, len(parts))
+			for i, part := range parts {
+				clones[i] = bytes.Clone(part)
+			}
+			return clones
+		}
+
+		// The shares' threshold wins over the caller's, in either direction.
+		var insufficient *InsufficientSharesError
+		if _, err := CombineSecure(cloneShares(shares[:2]), 0); !errors.As(err, &insufficient) || insufficient.Need != 3 {
+			t.Errorf("expected *InsufficientSharesError needing 3, got %v", err)
+		}
+		if _, err := CombineSecure(cloneShares(shares[:2]), 2); !errors.Is(err, ErrMismatchedShares) {
+			t.Errorf("expected ErrMismatchedShares for a lower expected threshold, got %v", err)
+		}
+		if _, err := CombineSecure(cloneShares(shares[:4]), 4); !errors.Is(err, ErrMismatchedShares) {
+			t.Errorf("expected ErrMismatchedShares for a higher expected threshold, got %v", err)
+		}
+
+		for _, expected := range []int{0, 3} {
+			reconstructed, err := CombineSecure(cloneShares(shares[1:4]), expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(reconstructed, secret) {
+				t.Errorf("expected threshold %d: secure combine failed", expected)
+			}
+		}
+
+		if _, err := SplitSecure(bytes.Clone(secret), 2, 3, true); !errors.Is(err, ErrInvalidThreshold) {
+			t.Errorf("expected ErrInvalidThreshold, got %v", err)
+		}
+	})
+
+	t.Run("verify integrity", func(t *testing.T) {
+		shares, err := SplitWithIntegrity(secret, 3, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		original := append([]byte(nil), shares[0]...)
+		if err := VerifyIntegrity(shares[0]); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(shares[0], original) {
+			t.Error("VerifyIntegrity modified the share")
+		}
+
+		shares[0][1] ^= 0x01
+		if err := VerifyIntegrity(shares[0]); err != ErrIntegrityCheckFailed {
+			t.Errorf("expected ErrIntegrityCheckFailed, got %v", err)
+		}
+
+		if err := VerifyIntegrity([]byte{1, 2, 3}); err != ErrTooShort {
+			t.Errorf("expected ErrTooShort, got %v", err)
+		}
+	})
 }
\ No newline at end of file
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// Client calls a dealer service.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	grpc    bool
+}
+
+// NewClient returns a client for the service at baseURL, such as
+// "https://dealer.example.com:8443", sending requests with httpClient. The
+// HTTP client must present a certificate the service accepts; see
+// ClientTLSConfig.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/"), http: httpClient}
+}
+
+// NewGRPCClient is like NewClient but calls the gRPC service instead of the
+// JSON endpoints. gRPC requires HTTP/2, so the HTTP client's transport must
+// negotiate it; an http.Transport with a custom TLSClientConfig only does so
+// with ForceAttemptHTTP2 set.
+func NewGRPCClient(baseURL string, httpClient *http.Client) *Client {
+	c := NewClient(baseURL, httpClient)
+	c.grpc = true
+	return c
+}
+
+// ClientTLSConfig returns a client TLS configuration presenting cert and
+// trusting servers issued by rootCAs, with TLS 1.3 only. Use it as
+//
+//	&http.Client{Transport: &http.Transport{TLSClientConfig: server.ClientTLSConfig(cert, roots)}}
+func ClientTLSConfig(cert tls.Certificate, rootCAs *x509.CertPool) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      rootCAs,
+		MinVersion:   tls.VersionTLS13,
+	}
+}
+
+// Error is a failure reported by the service. It matches the shamir sentinel
+// error for its code with errors.Is, so callers handle remote and local
+// failures alike.
+type Error struct {
+	StatusCode int    // HTTP status
+	GRPCCode   int    // gRPC status code, 0 for JSON calls
+	Code       string // See ErrorResponse
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.GRPCCode != 0 {
+		return fmt.Sprintf("server: %s (gRPC %d, %s)", e.Message, e.GRPCCode, e.Code)
+	}
+	return fmt.Sprintf("server: %s (%d, %s)", e.Message, e.StatusCode, e.Code)
+}
+
+// codeErrors maps conformance error codes back to sentinel errors.
+var codeErrors = map[string]error{
+	"empty-secret":           shamir.ErrEmptySecret,
+	"invalid-parts":          shamir.ErrInvalidParts,
+	"invalid-threshold":      shamir.ErrInvalidThreshold,
+	"too-few-parts":          shamir.ErrTooFewParts,
+	"too-short":              shamir.ErrTooShort,
+	"different-lengths":      shamir.ErrDifferentLengths,
+	"duplicate-part":         shamir.ErrDuplicatePart,
+	"integrity-check-failed": shamir.ErrIntegrityCheckFailed,
+	"authentication-failed":  shamir.ErrAuthenticationFailed,
+	"format-mismatch":        shamir.ErrFormatMismatch,
+}
+
+// Unwrap returns the sentinel error for the code, or nil.
+func (e *Error) Unwrap() error {
+	return codeErrors[e.Code]
+}
+
+// Split splits req.Secret on the service and returns the shares.
+func (c *Client) Split(ctx context.Context, req SplitRequest) ([][]byte, error) {
+	var resp SplitResponse
+	if err := c.do(ctx, OpSplit, &req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Shares, nil
+}
+
+// Combine reconstructs a secret on the service. integrity must match the split.
+func (c *Client) Combine(ctx context.Context, shares [][]byte, integrity bool) ([]byte, error) {
+	var resp CombineResponse
+	if err := c.do(ctx, OpCombine, &CombineRequest{Shares: shares, Integrity: integrity}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Secret, nil
+}
+
+// Verify checks shares on the service without combining them.
+func (c *Client) Verify(ctx context.Context, shares [][]byte) (*VerifyResponse, error) {
+	var resp VerifyResponse
+	if err := c.do(ctx, OpVerify, &VerifyRequest{Shares: shares}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Refresh refreshes all shares of a split on the service.
+func (c *Client) Refresh(ctx context.Context, shares [][]byte, threshold int) ([][]byte, error) {
+	var resp RefreshResponse
+	if err := c.do(ctx, OpRefresh, &RefreshRequest{Shares: shares, Threshold: threshold}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Shares, nil
+}
+
+// do posts req to the endpoint for op and decodes the reply into resp.
+func (c *Client) do(ctx context.Context, op string, req, resp protoMessage) error {
+	if c.grpc {
+		return c.doGRPC(ctx, op, req, resp)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	defer clear(body)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/"+op, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		var e ErrorResponse
+		if err := json.NewDecoder(httpResp.Body).Decode(&e); err != nil {
+			return &Error{StatusCode: httpResp.StatusCode, Code: "bad-response", Message: httpResp.Status}
+		}
+		return &Error{StatusCode: httpResp.StatusCode, Code: e.Code, Message: e.Message}
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
+
+// doGRPC calls the gRPC method for op with req and decodes the reply into resp.
+func (c *Client) doGRPC(ctx context.Context, op string, req, resp protoMessage) error {
+	msg := req.marshalProto()
+	body := appendFrame(nil, msg)
+	clear(msg)
+	defer clear(body)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+GRPCService+"/"+grpcMethod(op), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/grpc")
+	httpReq.Header.Set("TE", "trailers")
+
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return &Error{StatusCode: httpResp.StatusCode, Code: "bad-response", Message: httpResp.Status}
+	}
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return err
+	}
+
+	// Failures are usually trailers-only responses, with the status in the
+	// headers.
+	md := httpResp.Trailer
+	if md.Get("Grpc-Status") == "" {
+		md = httpResp.Header
+	}
+	switch status := md.Get("Grpc-Status"); status {
+	case strconv.Itoa(grpcOK):
+	case "":
+		return &Error{StatusCode: httpResp.StatusCode, Code: "bad-response", Message: "missing grpc-status"}
+	default:
+		code, _ := strconv.Atoi(status)
+		return &Error{
+			StatusCode: httpResp.StatusCode,
+			GRPCCode:   code,
+			Code:       md.Get("Shamir-Error-Code"),
+			Message:    decodeGRPCMessage(md.Get("Grpc-Message")),
+		}
+	}
+
+	msg, err = parseFrame(data)
+	if err != nil {
+		return err
+	}
+	return resp.unmarshalProto(msg)
+}
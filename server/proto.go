@@ -0,0 +1,438 @@
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// This file encodes the messages of dealer.proto in the protobuf wire format
+// and frames them for gRPC, so the service needs nothing beyond the standard
+// library. Only the wire types the schema uses are produced; unknown fields
+// are skipped when decoding, as protobuf requires.
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// errMalformed is returned for messages that are not valid protobuf or gRPC
+// framing.
+var errMalformed = errors.New("server: malformed protobuf message")
+
+// protoMessage is implemented by the request and response types, which are
+// the messages of the same name in dealer.proto.
+type protoMessage interface {
+	marshalProto() []byte
+	unmarshalProto([]byte) error
+}
+
+func appendTag(b []byte, num, typ int) []byte {
+	return binary.AppendUvarint(b, uint64(num)<<3|uint64(typ))
+}
+
+// appendBytes appends a length-delimited field, even if v is empty, as the
+// elements of repeated fields require.
+func appendBytes(b []byte, num int, v []byte) []byte {
+	b = appendTag(b, num, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendSingularBytes appends v unless it is empty, the proto3 default.
+func appendSingularBytes(b []byte, num int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	return appendBytes(b, num, v)
+}
+
+func appendInt(b []byte, num int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, num, wireVarint)
+	return binary.AppendUvarint(b, uint64(v))
+}
+
+func appendBool(b []byte, num int, v bool) []byte {
+	if !v {
+		return b
+	}
+	return appendInt(b, num, 1)
+}
+
+// protoField is one decoded field. Length-delimited data aliases the message.
+type protoField struct {
+	num    int
+	typ    int
+	varint uint64
+	data   []byte
+}
+
+func (f protoField) bytes() ([]byte, error) {
+	if f.typ != wireBytes {
+		return nil, fmt.Errorf("%w: field %d is not length-delimited", errMalformed, f.num)
+	}
+	return f.data, nil
+}
+
+func (f protoField) string() (string, error) {
+	b, err := f.bytes()
+	return string(b), err
+}
+
+func (f protoField) int() (int, error) {
+	if f.typ != wireVarint {
+		return 0, fmt.Errorf("%w: field %d is not a varint", errMalformed, f.num)
+	}
+	return int(int32(f.varint)), nil
+}
+
+func (f protoField) int64() (int64, error) {
+	if f.typ != wireVarint {
+		return 0, fmt.Errorf("%w: field %d is not a varint", errMalformed, f.num)
+	}
+	return int64(f.varint), nil
+}
+
+func (f protoField) bool() (bool, error) {
+	n, err := f.int64()
+	return n != 0, err
+}
+
+// forEachField calls fn with each field of the message b in order.
+func forEachField(b []byte, fn func(protoField) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 || tag>>3 == 0 {
+			return errMalformed
+		}
+		b = b[n:]
+		f := protoField{num: int(tag >> 3), typ: int(tag & 7)}
+		switch f.typ {
+		case wireVarint:
+			if f.varint, n = binary.Uvarint(b); n <= 0 {
+				return errMalformed
+			}
+		case wireFixed64:
+			n = 8
+		case wireFixed32:
+			n = 4
+		case wireBytes:
+			l, m := binary.Uvarint(b)
+			if m <= 0 || l > uint64(len(b)-m) {
+				return errMalformed
+			}
+			f.data = b[m : m+int(l)]
+			n = m + int(l)
+		default:
+			return fmt.Errorf("%w: unsupported wire type %d", errMalformed, f.typ)
+		}
+		if n > len(b) {
+			return errMalformed
+		}
+		b = b[n:]
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *SplitRequest) marshalProto() []byte {
+	b := appendSingularBytes(nil, 1, m.Secret)
+	b = appendInt(b, 2, int64(m.Parts))
+	b = appendInt(b, 3, int64(m.Threshold))
+	b = appendBool(b, 4, m.Integrity)
+	for _, label := range m.Labels {
+		b = appendBytes(b, 5, []byte(label))
+	}
+	return b
+}
+
+func (m *SplitRequest) unmarshalProto(b []byte) error {
+	return forEachField(b, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			m.Secret, err = f.bytes()
+		case 2:
+			m.Parts, err = f.int()
+		case 3:
+			m.Threshold, err = f.int()
+		case 4:
+			m.Integrity, err = f.bool()
+		case 5:
+			var label string
+			label, err = f.string()
+			m.Labels = append(m.Labels, label)
+		}
+		return err
+	})
+}
+
+// marshalShares and unmarshalShare handle the repeated bytes shares = 1 field
+// that most messages carry.
+func marshalShares(b []byte, shares [][]byte) []byte {
+	for _, share := range shares {
+		b = appendBytes(b, 1, share)
+	}
+	return b
+}
+
+func unmarshalShare(shares *[][]byte, f protoField) error {
+	share, err := f.bytes()
+	*shares = append(*shares, share)
+	return err
+}
+
+func (m *SplitResponse) marshalProto() []byte {
+	return marshalShares(nil, m.Shares)
+}
+
+func (m *SplitResponse) unmarshalProto(b []byte) error {
+	return forEachField(b, func(f protoField) error {
+		if f.num == 1 {
+			return unmarshalShare(&m.Shares, f)
+		}
+		return nil
+	})
+}
+
+func (m *CombineRequest) marshalProto() []byte {
+	b := marshalShares(nil, m.Shares)
+	return appendBool(b, 2, m.Integrity)
+}
+
+func (m *CombineRequest) unmarshalProto(b []byte) error {
+	return forEachField(b, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			err = unmarshalShare(&m.Shares, f)
+		case 2:
+			m.Integrity, err = f.bool()
+		}
+		return err
+	})
+}
+
+func (m *CombineResponse) marshalProto() []byte {
+	return appendSingularBytes(nil, 1, m.Secret)
+}
+
+func (m *CombineResponse) unmarshalProto(b []byte) error {
+	return forEachField(b, func(f protoField) (err error) {
+		if f.num == 1 {
+			m.Secret, err = f.bytes()
+		}
+		return err
+	})
+}
+
+func (m *VerifyRequest) marshalProto() []byte {
+	return marshalShares(nil, m.Shares)
+}
+
+func (m *VerifyRequest) unmarshalProto(b []byte) error {
+	return forEachField(b, func(f protoField) error {
+		if f.num == 1 {
+			return unmarshalShare(&m.Shares, f)
+		}
+		return nil
+	})
+}
+
+func (m *VerifyResponse) marshalProto() []byte {
+	b := appendBool(nil, 1, m.Valid)
+	for _, info := range m.Shares {
+		b = appendBytes(b, 2, marshalShareInfo(info))
+	}
+	for _, e := range m.Errors {
+		b = appendBytes(b, 3, []byte(e))
+	}
+	return b
+}
+
+func (m *VerifyResponse) unmarshalProto(b []byte) error {
+	return forEachField(b, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			m.Valid, err = f.bool()
+		case 2:
+			var data []byte
+			if data, err = f.bytes(); err != nil {
+				return err
+			}
+			var info shamir.ShareInfo
+			info, err = unmarshalShareInfo(data)
+			m.Shares = append(m.Shares, info)
+		case 3:
+			var e string
+			e, err = f.string()
+			m.Errors = append(m.Errors, e)
+		}
+		return err
+	})
+}
+
+func marshalShareInfo(info shamir.ShareInfo) []byte {
+	b := appendSingularBytes(nil, 1, []byte(info.Format))
+	b = appendSingularBytes(b, 2, []byte(info.Encoding))
+	b = appendInt(b, 3, int64(info.Version))
+	b = appendInt(b, 4, int64(info.Index))
+	b = appendInt(b, 5, int64(info.Threshold))
+	b = appendInt(b, 6, int64(info.PayloadLen))
+	if info.SetID != (shamir.SetID{}) {
+		b = appendBytes(b, 7, info.SetID[:])
+	}
+	b = appendSingularBytes(b, 8, []byte(info.Label))
+	b = appendInt(b, 9, unixSeconds(info.NotBefore))
+	b = appendInt(b, 10, unixSeconds(info.NotAfter))
+	b = appendBool(b, 11, info.HasIntegrity)
+	b = appendBool(b, 12, info.IntegrityValid)
+	if info.Fingerprint != (shamir.ShareFingerprint{}) {
+		b = appendBytes(b, 13, info.Fingerprint[:])
+	}
+	return b
+}
+
+func unmarshalShareInfo(b []byte) (shamir.ShareInfo, error) {
+	var info shamir.ShareInfo
+	err := forEachField(b, func(f protoField) (err error) {
+		var n int64
+		var data []byte
+		switch f.num {
+		case 1:
+			info.Format, err = f.string()
+		case 2:
+			info.Encoding, err = f.string()
+		case 3:
+			info.Version, err = f.int()
+		case 4:
+			n, err = f.int64()
+			info.Index = byte(n)
+		case 5:
+			info.Threshold, err = f.int()
+		case 6:
+			info.PayloadLen, err = f.int()
+		case 7:
+			if data, err = f.bytes(); err == nil && copy(info.SetID[:], data) != len(data) {
+				err = fmt.Errorf("%w: set ID of %d bytes", errMalformed, len(data))
+			}
+		case 8:
+			info.Label, err = f.string()
+		case 9:
+			n, err = f.int64()
+			info.NotBefore = fromUnixSeconds(n)
+		case 10:
+			n, err = f.int64()
+			info.NotAfter = fromUnixSeconds(n)
+		case 11:
+			info.HasIntegrity, err = f.bool()
+		case 12:
+			info.IntegrityValid, err = f.bool()
+		case 13:
+			if data, err = f.bytes(); err == nil && copy(info.Fingerprint[:], data) != len(data) {
+				err = fmt.Errorf("%w: fingerprint of %d bytes", errMalformed, len(data))
+			}
+		}
+		return err
+	})
+	return info, err
+}
+
+// unixSeconds maps the zero time, an unbounded validity window, to 0.
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func fromUnixSeconds(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+func (m *RefreshRequest) marshalProto() []byte {
+	b := marshalShares(nil, m.Shares)
+	return appendInt(b, 2, int64(m.Threshold))
+}
+
+func (m *RefreshRequest) unmarshalProto(b []byte) error {
+	return forEachField(b, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			err = unmarshalShare(&m.Shares, f)
+		case 2:
+			m.Threshold, err = f.int()
+		}
+		return err
+	})
+}
+
+func (m *RefreshResponse) marshalProto() []byte {
+	return marshalShares(nil, m.Shares)
+}
+
+func (m *RefreshResponse) unmarshalProto(b []byte) error {
+	return forEachField(b, func(f protoField) error {
+		if f.num == 1 {
+			return unmarshalShare(&m.Shares, f)
+		}
+		return nil
+	})
+}
+
+// appendFrame appends msg as an uncompressed gRPC length-prefixed message.
+func appendFrame(b, msg []byte) []byte {
+	b = append(b, 0)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(msg)))
+	return append(b, msg...)
+}
+
+// parseFrame returns the single message of a unary gRPC body.
+func parseFrame(b []byte) ([]byte, error) {
+	if len(b) < 5 || uint64(binary.BigEndian.Uint32(b[1:5])) != uint64(len(b)-5) {
+		return nil, fmt.Errorf("%w: bad gRPC framing", errMalformed)
+	}
+	if b[0] != 0 {
+		return nil, fmt.Errorf("%w: compressed gRPC messages are not supported", errMalformed)
+	}
+	return b[5:], nil
+}
+
+// grpcMethod returns the dealer.proto method name for op, e.g. "Split".
+func grpcMethod(op string) string {
+	return strings.ToUpper(op[:1]) + op[1:]
+}
+
+// encodeGRPCMessage percent-encodes s for the grpc-message trailer.
+func encodeGRPCMessage(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c > 0x7e || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func decodeGRPCMessage(s string) string {
+	if decoded, err := url.PathUnescape(s); err == nil {
+		return decoded
+	}
+	return s
+}
@@ -0,0 +1,435 @@
+// Package server runs the parent shamir package as a network dealing service,
+// so that teams can operate one central, audited dealer instead of linking
+// the library into every tool that splits or recovers secrets.
+//
+// The service speaks both JSON over HTTPS and gRPC, on the same handler, and
+// requires mutual TLS: every request must present a client certificate that
+// verifies against the configured client CAs, and the certificate's subject
+// identifies the caller in the audit log and to the authorization hook.
+//
+// The JSON endpoints encode byte strings as standard base64, the encoding/json
+// default:
+//
+//	POST /v1/split    SplitRequest   -> SplitResponse
+//	POST /v1/combine  CombineRequest -> CombineResponse
+//	POST /v1/verify   VerifyRequest  -> VerifyResponse
+//	POST /v1/refresh  RefreshRequest -> RefreshResponse
+//
+// The gRPC service is GRPCService, defined in dealer.proto, with one unary
+// method per endpoint. The package encodes its messages itself, so it needs
+// nothing beyond the standard library; gRPC clients must negotiate HTTP/2,
+// which http.Server does by default over TLS.
+//
+// Failures are reported with the portable error codes of the conformance
+// package, in an ErrorResponse or in the "shamir-error-code" gRPC trailer.
+// Client turns them back into errors matching the shamir sentinel errors.
+//
+// A minimal deployment:
+//
+//	srv := &http.Server{
+//		Addr:      ":8443",
+//		Handler:   server.New(server.WithAuditHook(logEvent)),
+//		TLSConfig: server.TLSConfig(cert, clientCAs),
+//	}
+//	log.Fatal(srv.ListenAndServeTLS("", ""))
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	shamir "github.com/morizta/go-shamir"
+	"github.com/morizta/go-shamir/conformance"
+)
+
+// DefaultMaxRequestSize bounds request bodies unless WithMaxRequestSize says
+// otherwise.
+const DefaultMaxRequestSize = 1 << 20
+
+// GRPCService is the full name of the gRPC service in dealer.proto. Its
+// methods are the operation names capitalized, e.g. Split.
+const GRPCService = "shamir.dealer.v1.Dealer"
+
+// Operation names, as used in paths, audit events and authorization.
+const (
+	OpSplit   = "split"
+	OpCombine = "combine"
+	OpVerify  = "verify"
+	OpRefresh = "refresh"
+)
+
+// SplitRequest asks the dealer to split a secret. With Integrity the shares
+// carry a CRC32 (see shamir.SplitWithIntegrity); with Labels, one per share,
+// they are enveloped and labelled (see shamir.WithLabels).
+type SplitRequest struct {
+	Secret    []byte   `json:"secret"`
+	Parts     int      `json:"parts"`
+	Threshold int      `json:"threshold"`
+	Integrity bool     `json:"integrity,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+}
+
+// SplitResponse holds the shares of a split.
+type SplitResponse struct {
+	Shares [][]byte `json:"shares"`
+}
+
+// CombineRequest asks the dealer to reconstruct a secret. Integrity must match
+// the split; enveloped shares are detected automatically.
+type CombineRequest struct {
+	Shares    [][]byte `json:"shares"`
+	Integrity bool     `json:"integrity,omitempty"`
+}
+
+// CombineResponse holds a reconstructed secret.
+type CombineResponse struct {
+	Secret []byte `json:"secret"`
+}
+
+// VerifyRequest asks the dealer to check shares without combining them.
+type VerifyRequest struct {
+	Shares [][]byte `json:"shares"`
+}
+
+// VerifyResponse describes each share as shamir.InspectShare does, in request
+// order. Valid is false if any share could not be parsed or failed its
+// checksum; Errors then holds the reason for each share, empty for good ones.
+type VerifyResponse struct {
+	Valid  bool               `json:"valid"`
+	Shares []shamir.ShareInfo `json:"shares"`
+	Errors []string           `json:"errors,omitempty"`
+}
+
+// RefreshRequest asks the dealer to refresh all shares of a split (see
+// shamir.Refresh).
+type RefreshRequest struct {
+	Shares    [][]byte `json:"shares"`
+	Threshold int      `json:"threshold"`
+}
+
+// RefreshResponse holds refreshed shares in request order.
+type RefreshResponse struct {
+	Shares [][]byte `json:"shares"`
+}
+
+// ErrorResponse is the body of every failed request. Code is a conformance
+// error code such as "invalid-threshold", or "unauthorized", "forbidden",
+// "bad-request" or "too-large" for failures before the operation ran.
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Event is an audit record of one request. It never contains secrets or
+// shares.
+type Event struct {
+	Time       time.Time
+	Op         string // One of the Op constants
+	Client     string // Subject of the client certificate
+	RemoteAddr string
+	Shares     int   // Number of shares returned (split, refresh) or received
+	Threshold  int   // Requested threshold, 0 if the operation takes none
+	Err        error // Why the request failed, nil on success
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithAuditHook calls fn with an Event after every request, including
+// rejected ones. fn may be called concurrently.
+func WithAuditHook(fn func(Event)) Option {
+	return func(s *Server) { s.audit = fn }
+}
+
+// WithAuthorizer lets fn decide whether the verified client certificate may
+// perform op. A non-nil error refuses the request with status 403 and is
+// recorded in the audit event. By default every verified client may perform
+// every operation.
+func WithAuthorizer(fn func(client *x509.Certificate, op string) error) Option {
+	return func(s *Server) { s.authorize = fn }
+}
+
+// WithMaxRequestSize bounds request bodies to n bytes.
+func WithMaxRequestSize(n int64) Option {
+	return func(s *Server) { s.maxRequestSize = n }
+}
+
+// Server is an http.Handler serving the dealer API.
+type Server struct {
+	mux            *http.ServeMux
+	audit          func(Event)
+	authorize      func(*x509.Certificate, string) error
+	maxRequestSize int64
+}
+
+// New returns a dealer service handler configured by opts.
+func New(opts ...Option) *Server {
+	s := &Server{maxRequestSize: DefaultMaxRequestSize}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.mux = http.NewServeMux()
+	s.handle(OpSplit, s.handleSplit)
+	s.handle(OpCombine, s.handleCombine)
+	s.handle(OpVerify, s.handleVerify)
+	s.handle(OpRefresh, s.handleRefresh)
+	return s
+}
+
+// handle serves op at its JSON endpoint and as a gRPC method.
+func (s *Server) handle(op string, h http.HandlerFunc) {
+	s.mux.HandleFunc("POST /v1/"+op, h)
+	s.mux.HandleFunc("POST /"+GRPCService+"/"+grpcMethod(op), h)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// TLSConfig returns a server TLS configuration presenting cert and requiring
+// client certificates issued by clientCAs, with TLS 1.3 only.
+func TLSConfig(cert tls.Certificate, clientCAs *x509.CertPool) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS13,
+	}
+}
+
+// errUnauthorized is recorded for requests without a verified client certificate.
+var errUnauthorized = errors.New("server: verified client certificate required")
+
+// call holds the state of one request for auditing.
+type call struct {
+	Event
+	w    http.ResponseWriter
+	grpc bool // The request is a gRPC call rather than JSON
+}
+
+// begin authenticates and authorizes a request for op and decodes its body
+// into req. It reports whether the request may proceed; if not, the response
+// has been written and the event audited.
+func (s *Server) begin(w http.ResponseWriter, r *http.Request, op string, req protoMessage) (*call, bool) {
+	c := &call{
+		Event: Event{Time: time.Now().UTC(), Op: op, RemoteAddr: r.RemoteAddr},
+		w:     w,
+		grpc:  strings.HasPrefix(r.URL.Path, "/"+GRPCService+"/"),
+	}
+
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		s.fail(c, http.StatusUnauthorized, "unauthorized", errUnauthorized)
+		return nil, false
+	}
+	client := r.TLS.VerifiedChains[0][0]
+	c.Client = client.Subject.String()
+	if s.authorize != nil {
+		if err := s.authorize(client, op); err != nil {
+			s.fail(c, http.StatusForbidden, "forbidden", err)
+			return nil, false
+		}
+	}
+
+	body := http.MaxBytesReader(w, r.Body, s.maxRequestSize)
+	if err := decode(c, body, req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			s.fail(c, http.StatusRequestEntityTooLarge, "too-large", err)
+		} else {
+			s.fail(c, http.StatusBadRequest, "bad-request", err)
+		}
+		return nil, false
+	}
+	return c, true
+}
+
+// decode reads the request body into req in the encoding of the call.
+func decode(c *call, body io.Reader, req protoMessage) error {
+	if !c.grpc {
+		return json.NewDecoder(body).Decode(req)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	msg, err := parseFrame(data)
+	if err != nil {
+		return err
+	}
+	return req.unmarshalProto(msg)
+}
+
+// finish writes the response for a completed operation and audits it.
+func (s *Server) finish(c *call, resp protoMessage, err error) {
+	if err != nil {
+		s.fail(c, http.StatusUnprocessableEntity, conformance.ErrorCode(err), err)
+		return
+	}
+	if c.grpc {
+		s.replyGRPC(c.w, resp)
+	} else {
+		s.reply(c.w, http.StatusOK, resp)
+	}
+	s.record(c.Event)
+}
+
+// fail writes an ErrorResponse, or the equivalent gRPC status, and audits the
+// failure.
+func (s *Server) fail(c *call, status int, code string, err error) {
+	c.Err = err
+	if c.grpc {
+		h := c.w.Header()
+		h.Set("Content-Type", "application/grpc")
+		h.Set("Grpc-Status", strconv.Itoa(grpcCode(status)))
+		h.Set("Grpc-Message", encodeGRPCMessage(err.Error()))
+		h.Set("Shamir-Error-Code", code)
+		c.w.WriteHeader(http.StatusOK)
+	} else {
+		s.reply(c.w, status, ErrorResponse{Code: code, Message: err.Error()})
+	}
+	s.record(c.Event)
+}
+
+// gRPC status codes the service reports.
+const (
+	grpcOK                = 0
+	grpcInvalidArgument   = 3
+	grpcPermissionDenied  = 7
+	grpcResourceExhausted = 8
+	grpcUnauthenticated   = 16
+)
+
+// grpcCode maps the HTTP status of a JSON failure to a gRPC status code.
+func grpcCode(status int) int {
+	switch status {
+	case http.StatusUnauthorized:
+		return grpcUnauthenticated
+	case http.StatusForbidden:
+		return grpcPermissionDenied
+	case http.StatusRequestEntityTooLarge:
+		return grpcResourceExhausted
+	default:
+		return grpcInvalidArgument
+	}
+}
+
+// replyGRPC writes msg as a successful unary gRPC response.
+func (s *Server) replyGRPC(w http.ResponseWriter, msg protoMessage) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	data := msg.marshalProto()
+	body := appendFrame(nil, data)
+	w.Write(body)
+	clear(data)
+	clear(body)
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(grpcOK))
+}
+
+func (s *Server) reply(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (s *Server) record(e Event) {
+	if s.audit != nil {
+		s.audit(e)
+	}
+}
+
+func (s *Server) handleSplit(w http.ResponseWriter, r *http.Request) {
+	var req SplitRequest
+	c, ok := s.begin(w, r, OpSplit, &req)
+	if !ok {
+		return
+	}
+	defer wipe(req.Secret)
+	c.Threshold = req.Threshold
+
+	opts := []shamir.Option{shamir.WithParts(req.Parts), shamir.WithThreshold(req.Threshold), shamir.WithIntegrity(req.Integrity)}
+	if req.Labels != nil {
+		opts = append(opts, shamir.WithLabels(req.Labels...))
+	}
+	shares, err := shamir.SplitWithOptions(req.Secret, opts...)
+	c.Shares = len(shares)
+	s.finish(c, &SplitResponse{Shares: shares}, err)
+	wipeAll(shares)
+}
+
+func (s *Server) handleCombine(w http.ResponseWriter, r *http.Request) {
+	var req CombineRequest
+	c, ok := s.begin(w, r, OpCombine, &req)
+	if !ok {
+		return
+	}
+	defer wipeAll(req.Shares)
+	c.Shares = len(req.Shares)
+
+	secret, err := shamir.CombineWithOptions(req.Shares, shamir.WithIntegrity(req.Integrity))
+	s.finish(c, &CombineResponse{Secret: secret}, err)
+	wipe(secret)
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var req VerifyRequest
+	c, ok := s.begin(w, r, OpVerify, &req)
+	if !ok {
+		return
+	}
+	defer wipeAll(req.Shares)
+	c.Shares = len(req.Shares)
+
+	resp := VerifyResponse{Valid: true, Shares: make([]shamir.ShareInfo, len(req.Shares))}
+	errs := make([]string, len(req.Shares))
+	for i, share := range req.Shares {
+		info, err := shamir.InspectShare(share)
+		if err == nil && info.HasIntegrity && !info.IntegrityValid {
+			err = shamir.ErrIntegrityCheckFailed
+		}
+		resp.Shares[i] = info
+		if err != nil {
+			resp.Valid = false
+			errs[i] = err.Error()
+		}
+	}
+	if !resp.Valid {
+		resp.Errors = errs
+	}
+	s.finish(c, &resp, nil)
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	c, ok := s.begin(w, r, OpRefresh, &req)
+	if !ok {
+		return
+	}
+	defer wipeAll(req.Shares)
+	c.Threshold = req.Threshold
+
+	shares, err := shamir.Refresh(req.Shares, req.Threshold)
+	c.Shares = len(shares)
+	s.finish(c, &RefreshResponse{Shares: shares}, err)
+	wipeAll(shares)
+}
+
+// wipe zeroes b once it has been written to the response.
+func wipe(b []byte) {
+	clear(b)
+}
+
+func wipeAll(bs [][]byte) {
+	for _, b := range bs {
+		wipe(b)
+	}
+}
@@ -0,0 +1,354 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// testPKI is a throwaway CA with a server and a client certificate.
+type testPKI struct {
+	pool           *x509.CertPool
+	server, client tls.Certificate
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := func(serial int64, name string, usage x509.ExtKeyUsage) tls.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	return &testPKI{
+		pool:   pool,
+		server: issue(2, "dealer", x509.ExtKeyUsageServerAuth),
+		client: issue(3, "ops-tool", x509.ExtKeyUsageClientAuth),
+	}
+}
+
+// startServer serves s over mutual TLS and returns a client for it.
+func startServer(t *testing.T, s *Server) (*Client, *httptest.Server, *testPKI) {
+	t.Helper()
+	pki := newTestPKI(t)
+	ts := httptest.NewUnstartedServer(s)
+	ts.TLS = TLSConfig(pki.server, pki.pool)
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: ClientTLSConfig(pki.client, pki.pool)}}
+	return NewClient(ts.URL, httpClient), ts, pki
+}
+
+// startGRPCServer serves s over mutual TLS with HTTP/2 and returns a gRPC
+// client for it. Every request must arrive over HTTP/2.
+func startGRPCServer(t *testing.T, s *Server) *Client {
+	t.Helper()
+	pki := newTestPKI(t)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			t.Errorf("%s request over %s", r.URL.Path, r.Proto)
+		}
+		s.ServeHTTP(w, r)
+	}))
+	ts.EnableHTTP2 = true
+	ts.TLS = TLSConfig(pki.server, pki.pool)
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+
+	httpClient := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   ClientTLSConfig(pki.client, pki.pool),
+		ForceAttemptHTTP2: true,
+	}}
+	return NewGRPCClient(ts.URL, httpClient)
+}
+
+func TestSplitCombineVerifyRefresh(t *testing.T) {
+	var mu sync.Mutex
+	var events []Event
+	c, _, _ := startServer(t, New(WithAuditHook(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})))
+	ctx := context.Background()
+	secret := []byte("dealt remotely")
+
+	shares, err := c.Split(ctx, SplitRequest{Secret: secret, Parts: 5, Threshold: 3, Integrity: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("got %d shares", len(shares))
+	}
+
+	verified, err := c.Verify(ctx, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified.Valid || verified.Shares[4].Format != "integrity" || verified.Shares[4].Index != 5 {
+		t.Fatalf("Verify = %+v", verified)
+	}
+
+	got, err := c.Combine(ctx, shares[2:], true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(secret) {
+		t.Fatalf("Combine = %q", got)
+	}
+
+	raw, err := shamir.Split(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err := c.Refresh(ctx, raw, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := shamir.Combine(refreshed[1:]); err != nil || string(got) != string(secret) {
+		t.Fatalf("Combine of refreshed shares = %q, %v", got, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 4 {
+		t.Fatalf("got %d audit events, want 4", len(events))
+	}
+	for _, e := range events {
+		if e.Client != "CN=ops-tool" || e.Err != nil {
+			t.Errorf("audit event %+v", e)
+		}
+	}
+	if events[0].Op != OpSplit || events[0].Shares != 5 || events[0].Threshold != 3 {
+		t.Errorf("split event %+v", events[0])
+	}
+}
+
+func TestErrorsMatchSentinels(t *testing.T) {
+	c, _, _ := startServer(t, New())
+	ctx := context.Background()
+
+	if _, err := c.Split(ctx, SplitRequest{Secret: []byte("x"), Parts: 3, Threshold: 4}); !errors.Is(err, shamir.ErrInvalidThreshold) {
+		t.Errorf("bad threshold: got %v", err)
+	}
+	if _, err := c.Combine(ctx, [][]byte{{1, 2}}, false); !errors.Is(err, shamir.ErrTooFewParts) {
+		t.Errorf("one share: got %v", err)
+	}
+
+	shares, err := shamir.SplitWithIntegrity([]byte("secret"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares[0][2] ^= 1
+	if _, err := c.Combine(ctx, shares[:2], true); !errors.Is(err, shamir.ErrIntegrityCheckFailed) {
+		t.Errorf("corrupted share: got %v", err)
+	}
+	var serr *Error
+	if _, err := c.Combine(ctx, shares[:2], true); !errors.As(err, &serr) || serr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("corrupted share: got %v, want 422", err)
+	}
+}
+
+func TestClientCertificateRequired(t *testing.T) {
+	_, ts, pki := startServer(t, New())
+
+	// The TLS handshake itself refuses clients without a certificate.
+	anonymous := NewClient(ts.URL, &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pki.pool}}})
+	if _, err := anonymous.Split(context.Background(), SplitRequest{Secret: []byte("x"), Parts: 2, Threshold: 2}); err == nil {
+		t.Fatal("request without a client certificate succeeded")
+	}
+
+	// Without TLS, the handler refuses on its own.
+	rec := httptest.NewRecorder()
+	New().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/split", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("plaintext request: status %d, want 401", rec.Code)
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	c, _, _ := startServer(t, New(WithAuthorizer(func(client *x509.Certificate, op string) error {
+		if op == OpCombine {
+			return errors.New("combine requires a ceremony")
+		}
+		return nil
+	})))
+	ctx := context.Background()
+
+	shares, err := c.Split(ctx, SplitRequest{Secret: []byte("x"), Parts: 2, Threshold: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var serr *Error
+	if _, err := c.Combine(ctx, shares, false); !errors.As(err, &serr) || serr.StatusCode != http.StatusForbidden {
+		t.Fatalf("Combine: got %v, want 403", err)
+	}
+}
+
+func TestMaxRequestSize(t *testing.T) {
+	c, _, _ := startServer(t, New(WithMaxRequestSize(64)))
+	var serr *Error
+	_, err := c.Split(context.Background(), SplitRequest{Secret: make([]byte, 100), Parts: 2, Threshold: 2})
+	if !errors.As(err, &serr) || serr.Code != "too-large" {
+		t.Fatalf("got %v, want too-large", err)
+	}
+}
+
+func TestGRPC(t *testing.T) {
+	var mu sync.Mutex
+	var events []Event
+	c := startGRPCServer(t, New(WithAuditHook(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})))
+	ctx := context.Background()
+	secret := []byte("dealt over gRPC")
+
+	shares, err := c.Split(ctx, SplitRequest{Secret: secret, Parts: 4, Threshold: 3, Labels: []string{"a", "b", "", "d"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 4 {
+		t.Fatalf("got %d shares", len(shares))
+	}
+
+	shares[3] = []byte{1}
+	verified, err := c.Verify(ctx, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := shamir.InspectShare(shares[1])
+	if verified.Valid || len(verified.Shares) != 4 || verified.Shares[1] != want || verified.Errors[1] != "" || verified.Errors[3] == "" {
+		t.Fatalf("Verify = %+v", verified)
+	}
+
+	got, err := c.Combine(ctx, shares[:3], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(secret) {
+		t.Fatalf("Combine = %q", got)
+	}
+
+	refreshed, err := c.Refresh(ctx, shares[:3], 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := shamir.CombineWithOptions(refreshed); err != nil || string(got) != string(secret) {
+		t.Fatalf("Combine of refreshed shares = %q, %v", got, err)
+	}
+
+	_, err = c.Split(ctx, SplitRequest{Secret: []byte("x"), Parts: 3, Threshold: 4})
+	var serr *Error
+	if !errors.Is(err, shamir.ErrInvalidThreshold) || !errors.As(err, &serr) || serr.GRPCCode != grpcInvalidArgument {
+		t.Errorf("bad threshold: got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 5 {
+		t.Fatalf("got %d audit events, want 5", len(events))
+	}
+	if e := events[0]; e.Op != OpSplit || e.Client != "CN=ops-tool" || e.Shares != 4 || e.Threshold != 3 {
+		t.Errorf("split event %+v", e)
+	}
+}
+
+func TestGRPCAuthorizerAndLimits(t *testing.T) {
+	c := startGRPCServer(t, New(WithMaxRequestSize(64), WithAuthorizer(func(client *x509.Certificate, op string) error {
+		if op == OpCombine {
+			return errors.New("combine requires a ceremony")
+		}
+		return nil
+	})))
+	ctx := context.Background()
+
+	var serr *Error
+	if _, err := c.Combine(ctx, [][]byte{{1, 2}, {2, 3}}, false); !errors.As(err, &serr) || serr.GRPCCode != grpcPermissionDenied || serr.Message != "combine requires a ceremony" {
+		t.Errorf("Combine: got %v, want permission denied", err)
+	}
+	if _, err := c.Split(ctx, SplitRequest{Secret: make([]byte, 100), Parts: 2, Threshold: 2}); !errors.As(err, &serr) || serr.Code != "too-large" || serr.GRPCCode != grpcResourceExhausted {
+		t.Errorf("Split: got %v, want too-large", err)
+	}
+}
+
+func TestProtoEncoding(t *testing.T) {
+	req := &SplitRequest{Secret: []byte("x"), Parts: 3, Threshold: 2, Labels: []string{"a", ""}}
+	want := []byte{0x0a, 0x01, 'x', 0x10, 0x03, 0x18, 0x02, 0x2a, 0x01, 'a', 0x2a, 0x00}
+	got := req.marshalProto()
+	if !bytes.Equal(got, want) {
+		t.Fatalf("marshalProto = % x, want % x", got, want)
+	}
+
+	// Unknown fields of every wire type are skipped.
+	unknown := append([]byte{0x78, 0x96, 0x01, 0x81, 0x01, 1, 2, 3, 4, 5, 6, 7, 8, 0x8d, 0x01, 1, 2, 3, 4}, got...)
+	var decoded SplitRequest
+	if err := decoded.unmarshalProto(unknown); err != nil {
+		t.Fatal(err)
+	}
+	if string(decoded.Secret) != "x" || decoded.Parts != 3 || decoded.Threshold != 2 || len(decoded.Labels) != 2 || decoded.Labels[1] != "" {
+		t.Fatalf("unmarshalProto = %+v", decoded)
+	}
+
+	for _, bad := range [][]byte{{0x0a, 0x05, 'x'}, {0x10}, {0x0b}, {0x12, 0x00}} {
+		if err := new(SplitRequest).unmarshalProto(bad); !errors.Is(err, errMalformed) {
+			t.Errorf("% x: got %v, want errMalformed", bad, err)
+		}
+	}
+}
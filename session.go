@@ -0,0 +1,175 @@
+package shamir
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// sessionDomain separates combine-session signatures from any other use of a
+// custodian's signing key.
+const sessionDomain = "go-shamir combine session v1"
+
+// SessionNonce identifies a single combine session. Custodians sign it together
+// with their share fingerprint, so a submission is only valid in the session it
+// was made for.
+type SessionNonce [32]byte
+
+// String returns the nonce in hexadecimal.
+func (n SessionNonce) String() string {
+	return hex.EncodeToString(n[:])
+}
+
+// MarshalText encodes the nonce in hexadecimal.
+func (n SessionNonce) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+// UnmarshalText decodes a hexadecimal nonce.
+func (n *SessionNonce) UnmarshalText(text []byte) error {
+	if hex.DecodedLen(len(text)) != len(n) {
+		return fmt.Errorf("shamir: session nonce must be %d hex characters", 2*len(n))
+	}
+	_, err := hex.Decode(n[:], text)
+	return err
+}
+
+// CombineSession collects shares submitted over an untrusted channel for one
+// reconstruction attempt. Every submission must carry the holding custodian's
+// signature over the share fingerprint and the session nonce, so a captured
+// submission cannot be replayed into a later session.
+//
+// A session reconstructs at most once; afterwards it rejects all calls. It is
+// safe for concurrent use.
+type CombineSession struct {
+	mu        sync.Mutex
+	manifest  *Manifest
+	keys      map[string]ed25519.PublicKey
+	nonce     SessionNonce
+	submitted map[string][]byte // Accepted shares by custodian
+	closed    bool
+}
+
+// NewCombineSession starts a session for the split described by manifest. keys
+// maps each custodian named in the manifest to the public key their
+// submissions are verified with; custodians without a key cannot submit.
+func NewCombineSession(manifest *Manifest, keys map[string]ed25519.PublicKey) (*CombineSession, error) {
+	if manifest == nil {
+		return nil, fmt.Errorf("shamir: combine session requires a manifest")
+	}
+	for custodian, key := range keys {
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("shamir: public key for custodian %q must be %d bytes", custodian, ed25519.PublicKeySize)
+		}
+	}
+
+	s := &CombineSession{
+		manifest:  manifest,
+		keys:      keys,
+		submitted: make(map[string][]byte),
+	}
+	if _, err := rand.Read(s.nonce[:]); err != nil {
+		return nil, fmt.Errorf("shamir: failed to generate session nonce: %w", err)
+	}
+	return s, nil
+}
+
+// Nonce returns the value custodians must sign for this session.
+func (s *CombineSession) Nonce() SessionNonce {
+	return s.nonce
+}
+
+// Submit verifies a custodian's submission and records the share. It returns
+// ErrUnknownShare if the manifest does not list the share,
+// ErrAuthenticationFailed if the signature does not cover this share and
+// session, and ErrDuplicatePart if the custodian has already submitted.
+func (s *CombineSession) Submit(share, signature []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSessionClosed
+	}
+	custodian, err := s.manifest.Custodian(share)
+	if err != nil {
+		return err
+	}
+	key, ok := s.keys[custodian]
+	if !ok {
+		return fmt.Errorf("%w: no key for custodian %q", ErrAuthenticationFailed, custodian)
+	}
+	if !ed25519.Verify(key, sessionMessage(s.nonce, s.manifest.SetID, FingerprintShare(share)), signature) {
+		return fmt.Errorf("%w: bad signature from custodian %q", ErrAuthenticationFailed, custodian)
+	}
+	if _, ok := s.submitted[custodian]; ok {
+		return fmt.Errorf("%w: custodian %q already submitted", ErrDuplicatePart, custodian)
+	}
+
+	s.submitted[custodian] = append([]byte(nil), share...)
+	return nil
+}
+
+// Submitted returns the number of accepted submissions.
+func (s *CombineSession) Submitted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.submitted)
+}
+
+// Combine reconstructs the secret from the accepted submissions and closes the
+// session, wiping the collected shares whether or not reconstruction succeeds.
+func (s *CombineSession) Combine() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrSessionClosed
+	}
+	if len(s.submitted) < s.manifest.Threshold {
+		return nil, fmt.Errorf("%w: %d of %d shares submitted", ErrInsufficientShares, len(s.submitted), s.manifest.Threshold)
+	}
+
+	s.closed = true
+	parts := make([][]byte, 0, len(s.submitted))
+	for _, share := range s.submitted {
+		parts = append(parts, share)
+	}
+	defer func() {
+		for _, share := range parts {
+			secureZeroBytes(share)
+		}
+		s.submitted = nil
+	}()
+
+	return CombineEscrow(s.manifest, parts)
+}
+
+// Close abandons the session and wipes any collected shares.
+func (s *CombineSession) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, share := range s.submitted {
+		secureZeroBytes(share)
+	}
+	s.submitted = nil
+	s.closed = true
+}
+
+// SignSubmission is the custodian side of a combine session: it signs the
+// share's fingerprint for the session identified by nonce and the split
+// identified by setID.
+func SignSubmission(key ed25519.PrivateKey, share []byte, setID SetID, nonce SessionNonce) []byte {
+	return ed25519.Sign(key, sessionMessage(nonce, setID, FingerprintShare(share)))
+}
+
+// sessionMessage builds the byte string signed for a submission.
+func sessionMessage(nonce SessionNonce, setID SetID, fp ShareFingerprint) []byte {
+	msg := make([]byte, 0, len(sessionDomain)+len(nonce)+len(setID)+len(fp))
+	msg = append(msg, sessionDomain...)
+	msg = append(msg, nonce[:]...)
+	msg = append(msg, setID[:]...)
+	return append(msg, fp[:]...)
+}
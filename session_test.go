@@ -0,0 +1,160 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+func newTestSession(t *testing.T, secret []byte) (*EscrowSharing, map[string]ed25519.PrivateKey, *CombineSession) {
+	t.Helper()
+
+	policy := EscrowPolicy{Name: "ops", Threshold: 2, Custodians: []string{"alice", "bob", "carol"}}
+	sharing, err := splitEscrow(secret, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	private := make(map[string]ed25519.PrivateKey)
+	public := make(map[string]ed25519.PublicKey)
+	for _, c := range policy.Custodians {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		private[c], public[c] = priv, pub
+	}
+
+	session, err := NewCombineSession(sharing.Manifest, public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sharing, private, session
+}
+
+func TestCombineSession(t *testing.T) {
+	secret := []byte("signing key material")
+	sharing, keys, session := newTestSession(t, secret)
+	setID := sharing.Manifest.SetID
+
+	alice, bob := sharing.Shares[0], sharing.Shares[1]
+	if err := session.Submit(alice, SignSubmission(keys["alice"], alice, setID, session.Nonce())); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("below threshold", func(t *testing.T) {
+		if _, err := session.Combine(); !errors.Is(err, ErrInsufficientShares) {
+			t.Errorf("expected ErrInsufficientShares, got %v", err)
+		}
+	})
+
+	t.Run("duplicate submission", func(t *testing.T) {
+		err := session.Submit(alice, SignSubmission(keys["alice"], alice, setID, session.Nonce()))
+		if !errors.Is(err, ErrDuplicatePart) {
+			t.Errorf("expected ErrDuplicatePart, got %v", err)
+		}
+	})
+
+	t.Run("wrong signer", func(t *testing.T) {
+		err := session.Submit(bob, SignSubmission(keys["alice"], bob, setID, session.Nonce()))
+		if !errors.Is(err, ErrAuthenticationFailed) {
+			t.Errorf("expected ErrAuthenticationFailed, got %v", err)
+		}
+	})
+
+	if err := session.Submit(bob, SignSubmission(keys["bob"], bob, setID, session.Nonce())); err != nil {
+		t.Fatal(err)
+	}
+	if n := session.Submitted(); n != 2 {
+		t.Fatalf("Submitted() = %d, want 2", n)
+	}
+
+	reconstructed, err := session.Combine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("session reconstructed the wrong secret")
+	}
+
+	t.Run("closed after combine", func(t *testing.T) {
+		if _, err := session.Combine(); !errors.Is(err, ErrSessionClosed) {
+			t.Errorf("expected ErrSessionClosed, got %v", err)
+		}
+		carol := sharing.Shares[2]
+		err := session.Submit(carol, SignSubmission(keys["carol"], carol, setID, session.Nonce()))
+		if !errors.Is(err, ErrSessionClosed) {
+			t.Errorf("expected ErrSessionClosed, got %v", err)
+		}
+	})
+}
+
+func TestCombineSessionReplay(t *testing.T) {
+	sharing, keys, first := newTestSession(t, []byte("secret"))
+	share := sharing.Shares[0]
+	captured := SignSubmission(keys["alice"], share, sharing.Manifest.SetID, first.Nonce())
+
+	public := map[string]ed25519.PublicKey{"alice": keys["alice"].Public().(ed25519.PublicKey)}
+	second, err := NewCombineSession(sharing.Manifest, public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Nonce() == second.Nonce() {
+		t.Fatal("sessions must use distinct nonces")
+	}
+
+	if err := second.Submit(share, captured); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("replayed submission: expected ErrAuthenticationFailed, got %v", err)
+	}
+}
+
+func TestCombineSessionRejects(t *testing.T) {
+	sharing, keys, session := newTestSession(t, []byte("secret"))
+	nonce := session.Nonce()
+
+	t.Run("unknown share", func(t *testing.T) {
+		other, _, _ := newTestSession(t, []byte("secret"))
+		share := other.Shares[0]
+		err := session.Submit(share, SignSubmission(keys["alice"], share, other.Manifest.SetID, nonce))
+		if !errors.Is(err, ErrUnknownShare) {
+			t.Errorf("expected ErrUnknownShare, got %v", err)
+		}
+	})
+
+	t.Run("wrong set ID", func(t *testing.T) {
+		share := sharing.Shares[0]
+		err := session.Submit(share, SignSubmission(keys["alice"], share, SetID{1}, nonce))
+		if !errors.Is(err, ErrAuthenticationFailed) {
+			t.Errorf("expected ErrAuthenticationFailed, got %v", err)
+		}
+	})
+
+	t.Run("custodian without key", func(t *testing.T) {
+		limited, err := NewCombineSession(sharing.Manifest, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		share := sharing.Shares[0]
+		err = limited.Submit(share, SignSubmission(keys["alice"], share, sharing.Manifest.SetID, limited.Nonce()))
+		if !errors.Is(err, ErrAuthenticationFailed) {
+			t.Errorf("expected ErrAuthenticationFailed, got %v", err)
+		}
+	})
+
+	t.Run("bad public key", func(t *testing.T) {
+		_, err := NewCombineSession(sharing.Manifest, map[string]ed25519.PublicKey{"alice": {1, 2, 3}})
+		if err == nil {
+			t.Error("expected error for short public key")
+		}
+	})
+
+	t.Run("closed session", func(t *testing.T) {
+		session.Close()
+		share := sharing.Shares[0]
+		err := session.Submit(share, SignSubmission(keys["alice"], share, sharing.Manifest.SetID, nonce))
+		if !errors.Is(err, ErrSessionClosed) {
+			t.Errorf("expected ErrSessionClosed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package shamir
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ShareSetID returns the set ID of the split a share belongs to. Every share
+// of a split carries the same ID, and shares of different splits carry
+// different ones, so the ID tells which secret a share is for without
+// revealing anything about it.
+//
+// Enveloped shares (see ParseShare) and multi-secret shares carry a set ID.
+// ShareSetID returns ErrNoSetID for raw shares from Split, which have no room
+// for one, and for envelopes written before set IDs were always recorded.
+func ShareSetID(share []byte) (SetID, error) {
+	set, _, err := shareIdentity(share)
+	return set, err
+}
+
+// shareIdentity returns the set ID and index recorded in a share.
+func shareIdentity(share []byte) (SetID, byte, error) {
+	switch {
+	case IsEnvelope(share):
+		s, err := ParseShare(share)
+		if err != nil {
+			return SetID{}, 0, err
+		}
+		secureZeroBytes(s.Payload)
+		if s.SetID.IsZero() {
+			return SetID{}, 0, ErrNoSetID
+		}
+		return s.SetID, s.Index, nil
+	case bytes.HasPrefix(share, multiMagic[:]):
+		s, err := parseMultiShare(share)
+		if err != nil {
+			return SetID{}, 0, err
+		}
+		return s.setID, s.index, nil
+	}
+	return SetID{}, 0, ErrNoSetID
+}
+
+// SameSet checks that all shares carry the same set ID, that is that they
+// come from the same split and may be combined. It returns ErrMismatchedShares
+// naming the first share from another split, or ErrNoSetID if a share has no
+// set ID.
+func SameSet(shares [][]byte) error {
+	if len(shares) == 0 {
+		return ErrNilShares
+	}
+	var first SetID
+	for i, share := range shares {
+		id, err := ShareSetID(share)
+		if err != nil {
+			return fmt.Errorf("share %d: %w", i, err)
+		}
+		if i == 0 {
+			first = id
+		} else if id != first {
+			return fmt.Errorf("share %d: %w: set %s, not %s", i, ErrMismatchedShares, id, first)
+		}
+	}
+	return nil
+}
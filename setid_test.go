@@ -0,0 +1,88 @@
+package shamir
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestShareSetID(t *testing.T) {
+	secret := []byte("set id secret")
+	a, err := SplitKey(make([]byte, 32), AlgAES256GCM, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithLabels("a", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idA, err := ShareSetID(a[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	idB, err := ShareSetID(b[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idA.IsZero() || idA == idB {
+		t.Fatalf("expected distinct non-zero set IDs, got %s and %s", idA, idB)
+	}
+	if err := SameSet(a); err != nil {
+		t.Fatal(err)
+	}
+
+	multi, err := SplitMulti(map[string][]byte{"k": secret}, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SameSet(multi); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := Split(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ShareSetID(raw[0]); !errors.Is(err, ErrNoSetID) {
+		t.Fatalf("raw share: expected ErrNoSetID, got %v", err)
+	}
+	if err := SameSet([][]byte{b[0], raw[1]}); !errors.Is(err, ErrNoSetID) {
+		t.Fatalf("expected ErrNoSetID, got %v", err)
+	}
+	if err := SameSet(nil); !errors.Is(err, ErrNilShares) {
+		t.Fatalf("expected ErrNilShares, got %v", err)
+	}
+}
+
+func TestCombineDifferentSplits(t *testing.T) {
+	secret := []byte("same secret, two splits")
+	opts := []Option{WithParts(3), WithThreshold(2), WithLabels("a", "b", "c")}
+	first, err := SplitWithOptions(secret, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := SplitWithOptions(secret, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mixed := [][]byte{first[0], second[1]}
+	if err := SameSet(mixed); !errors.Is(err, ErrMismatchedShares) {
+		t.Fatalf("SameSet: expected ErrMismatchedShares, got %v", err)
+	}
+	if _, err := Combine(mixed); !errors.Is(err, ErrMismatchedShares) {
+		t.Fatalf("Combine: expected ErrMismatchedShares, got %v", err)
+	}
+
+	// Refreshed shares form a new set and cannot be mixed with the old one.
+	refreshed, err := Refresh(first, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SameSet(refreshed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Combine([][]byte{first[0], refreshed[1]}); !errors.Is(err, ErrMismatchedShares) {
+		t.Fatalf("old and refreshed: expected ErrMismatchedShares, got %v", err)
+	}
+}
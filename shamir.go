@@ -2,7 +2,10 @@ package shamir
 
 import (
 	"crypto/rand"
-	"fmt"
+	"io"
+	"sync"
+
+	"github.com/morizta/go-shamir/gf256"
 )
 
 // ShareOverhead represents the byte overhead added to each share.
@@ -23,48 +26,162 @@ const ShareOverhead = 1
 //
 // Each share is len(secret)+1 bytes: [x-coordinate][y-values...]
 // The x-coordinate uniquely identifies each share (1-based indexing).
+// The evaluation strategy is chosen automatically from the secret size and
+// CPU (see SelectStrategy).
 func Split(secret []byte, parts, threshold int) ([][]byte, error) {
+	return split(secret, parts, threshold, rand.Reader, engine{})
+}
+
+// split implements Split with an explicit randomness source and execution engine.
+func split(secret []byte, parts, threshold int, rng io.Reader, eng engine) ([][]byte, error) {
 	// Validate all input parameters
 	if err := validateSplitParams(secret, parts, threshold); err != nil {
 		return nil, err
 	}
+	if err := checkSecretSize(secret); err != nil {
+		return nil, err
+	}
+
+	xCoords := make([]byte, parts)
+	for i := range xCoords {
+		xCoords[i] = byte(i + 1) // x-coordinates are 1-based (never 0)
+	}
+
+	return splitAt(secret, xCoords, threshold, rng, eng)
+}
+
+// splitAt evaluates the sharing polynomial at the given distinct, non-zero
+// x-coordinates using the strategy selected by eng. Parameters must already
+// be validated.
+func splitAt(secret, xCoords []byte, threshold int, rng io.Reader, eng engine) ([][]byte, error) {
+	strategy, workers := eng.resolve(len(secret))
+	if strategy == StrategyStreaming || eng.ctx != nil {
+		// Cancellable splits work window by window so ctx is checked regularly.
+		return splitStreaming(secret, xCoords, threshold, rng, eng, workers)
+	}
+
+	coeffs, err := randomCoefficients(secret, threshold, rng, eng)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeCoefficients(coeffs)
 
+	shares := make([][]byte, len(xCoords))
+	if strategy == StrategyScalar {
+		evalSharesScalar(shares, coeffs, xCoords)
+		return shares, nil
+	}
+
+	// Evaluate polynomial at each point x=1,2,...,parts to generate shares
+	// Each share: [x-coordinate][polynomial(x) for each secret byte]
+	parallelFor(len(xCoords), workers, func(i int) {
+		x := xCoords[i]
+		shares[i] = make([]byte, len(secret)+ShareOverhead)
+		shares[i][0] = x // Store x-coordinate as first byte
+		
+		// Evaluate polynomial at point x for all secret bytes simultaneously
+		gfPolyEvalSlice(shares[i][1:], coeffs, x)
+	})
+
+	return shares, nil
+}
+
+// randomCoefficients builds the sharing polynomial for every secret byte: the
+// secret is the constant term and the remaining threshold-1 coefficient rows are
+// read from rng, all before any share is computed (see readEntropy). The result
+// must be released with wipeCoefficients.
+func randomCoefficients(secret []byte, threshold int, rng io.Reader, eng engine) ([][]byte, error) {
 	secretLen := len(secret)
-	shares := make([][]byte, parts)
-	
+
 	// Create polynomial coefficients: secret is constant term (degree 0)
 	// Generate (threshold-1) random coefficients for higher degree terms
 	coeffs := make([][]byte, threshold)
-	coeffs[0] = make([]byte, secretLen)
+	coeffs[0] = allocSecret(secretLen)
 	copy(coeffs[0], secret) // Constant term = secret
+	auditTrack("split.coefficient", coeffs[0])
 	
 	// Generate random coefficients for polynomial terms of degree 1 to threshold-1
 	for i := 1; i < threshold; i++ {
-		coeffs[i] = make([]byte, secretLen)
-		if _, err := rand.Read(coeffs[i]); err != nil {
-			// Clean up any allocated coefficients on error
-			for j := 0; j < i; j++ {
-				secureZeroBytes(coeffs[j])
-			}
-			return nil, fmt.Errorf("shamir: failed to generate random coefficients: %w", err)
-		}
+		coeffs[i] = allocSecret(secretLen)
+		auditTrack("split.coefficient", coeffs[i])
 	}
-
-	// Evaluate polynomial at each point x=1,2,...,parts to generate shares
-	// Each share: [x-coordinate][polynomial(x) for each secret byte]
-	for i := 0; i < parts; i++ {
-		x := byte(i + 1) // x-coordinates are 1-based (never 0)
-		shares[i] = make([]byte, secretLen+ShareOverhead)
-		shares[i][0] = x // Store x-coordinate as first byte
-		
-		// Evaluate polynomial at point x for all secret bytes simultaneously
-		gfPolyEvalSlice(shares[i][1:], coeffs, x)
+	release := func() { wipeCoefficients(coeffs) }
+	if err := readEntropy(eng.ctx, rng, eng.entropyTimeout, coeffs[1:], release); err != nil {
+		return nil, err
 	}
 
-	// Securely clear polynomial coefficients from memory
+	return coeffs, nil
+}
+
+// wipeCoefficients securely clears polynomial coefficients from memory and
+// releases any locked pages holding them.
+func wipeCoefficients(coeffs [][]byte) {
 	for i := range coeffs {
 		if coeffs[i] != nil {
 			secureZeroBytes(coeffs[i])
+			auditRelease(coeffs[i])
+			freeSecret(coeffs[i])
+		}
+	}
+}
+
+// evalSharesScalar evaluates the polynomial of each secret byte separately with
+// table lookups. It avoids the per-call overhead of the slice kernels, which
+// only pays off for secrets shorter than one vector.
+func evalSharesScalar(shares [][]byte, coeffs [][]byte, xCoords []byte) {
+	secretLen := len(coeffs[0])
+	column := make([]byte, len(coeffs))
+	auditTrack("split.column", column)
+
+	for i, x := range xCoords {
+		shares[i] = make([]byte, secretLen+ShareOverhead)
+		shares[i][0] = x
+	}
+	for byteIdx := 0; byteIdx < secretLen; byteIdx++ {
+		for d := range coeffs {
+			column[d] = coeffs[d][byteIdx]
+		}
+		for i, x := range xCoords {
+			shares[i][byteIdx+1] = gfPolyEval(column, x)
+		}
+	}
+
+	secureZeroBytes(column)
+	auditRelease(column)
+}
+
+// splitStreaming splits the secret in windows of strategyWindowSize bytes, so
+// the coefficient buffers never exceed threshold windows however large the
+// secret is. Each window uses fresh randomness, as NewSplitter does. A non-nil
+// ctx is checked before every window; on cancellation the partial shares are
+// wiped and ctx.Err() is returned.
+func splitStreaming(secret, xCoords []byte, threshold int, rng io.Reader, eng engine, workers int) ([][]byte, error) {
+	shares := make([][]byte, len(xCoords))
+	for i, x := range xCoords {
+		shares[i] = make([]byte, len(secret)+ShareOverhead)
+		shares[i][0] = x
+	}
+
+	window := engine{strategy: StrategyParallel, parallelism: workers, entropyTimeout: eng.entropyTimeout}
+	for start := 0; start < len(secret); start += strategyWindowSize {
+		end := start + strategyWindowSize
+		if end > len(secret) {
+			end = len(secret)
+		}
+		var chunk [][]byte
+		err := contextErr(eng.ctx)
+		if err == nil {
+			chunk, err = splitAt(secret[start:end], xCoords, threshold, rng, window)
+		}
+		if err != nil {
+			for _, share := range shares {
+				secureZeroBytes(share)
+			}
+			return nil, err
+		}
+		for i, c := range chunk {
+			copy(shares[i][ShareOverhead+start:], c[ShareOverhead:])
+			secureZeroBytes(c)
 		}
 	}
 
@@ -83,9 +200,47 @@ func Split(secret []byte, parts, threshold int) ([][]byte, error) {
 //
 // The reconstruction uses Lagrange interpolation to evaluate the polynomial at x=0,
 // which gives the original secret (the constant term of the polynomial).
+// The interpolation strategy is chosen automatically from the secret size and
+// CPU (see SelectStrategy).
+//
+// Shares in a newer format are recognised: enveloped shares are combined as
+// CombineWithOptions would, chunked shares (see WithChunkSize) are reassembled
+// as CombineStream would, other formats yield a *MigrationError (see
+// SetStrictMigration). So do shares from SplitWithIntegrity and, when their
+// leading bytes are not valid x-coordinates, Vault-format shares.
+//
+// Enveloped shares record their threshold, and fewer of them than that fail
+// with an *InsufficientSharesError giving the count required. Raw shares do
+// not record it: too few of them interpolate a wrong secret without error.
 func Combine(parts [][]byte) ([]byte, error) {
+	if isChunked(parts) {
+		return combineChunked(parts, engine{})
+	}
+	enveloped, err := checkLegacyFormat("Combine", parts, "CombineWithOptions")
+	if err != nil {
+		return nil, err
+	}
+	if enveloped {
+		return combinePurposeBound(parts, newOptions(nil))
+	}
+	if err := checkRawLayout("Combine", parts); err != nil {
+		return nil, err
+	}
+	return combine(parts, engine{})
+}
+
+// combine implements Combine using the strategy selected by eng.
+func combine(parts [][]byte, eng engine) ([]byte, error) {
 	// Validate share format and consistency
-	if err := validateCombineParams(parts); err != nil {
+	if len(parts) == 1 && eng.allowTrivial {
+		if err := validateTrivialShare(parts[0]); err != nil {
+			return nil, err
+		}
+	} else if eng.constantTime {
+		if err := validateCombineParamsConstantTime(parts); err != nil {
+			return nil, err
+		}
+	} else if err := validateCombineParams(parts); err != nil {
 		return nil, err
 	}
 
@@ -97,21 +252,50 @@ func Combine(parts [][]byte) ([]byte, error) {
 		xCoords[i] = part[0]
 	}
 
+	if err := eng.checkPolicy(xCoords, secretLen); err != nil {
+		return nil, err
+	}
+	if eng.strict > 0 {
+		if err := checkConsistency(parts, xCoords, eng.strict); err != nil {
+			return nil, err
+		}
+	}
+
 	// Reconstruct secret by interpolating polynomial at x=0 for each byte position
-	secret := make([]byte, secretLen)
-	
-	for byteIdx := 0; byteIdx < secretLen; byteIdx++ {
-		// Extract y-coordinates for this byte position across all shares
-		yCoords := make([]byte, len(parts))
-		for i, part := range parts {
-			yCoords[i] = part[byteIdx+1]
+	secret := allocSecret(secretLen)
+
+	strategy, workers := eng.resolve(secretLen)
+	if eng.ctx != nil {
+		// Cancellable combines work window by window so ctx is checked regularly.
+		strategy = StrategyStreaming
+	}
+	// The Lagrange weights depend only on the x-coordinates, so every strategy
+	// computes them once and reduces reconstruction to a weighted sum of payloads.
+	weights, mulAdd := lagrangeBasis(xCoords, 0), gfMulAddSlice
+	if eng.constantTime {
+		weights, mulAdd = lagrangeBasisConstantTime(xCoords, 0), gf256.MulAddSliceConstantTime
+		if strategy == StrategyScalar {
+			strategy = StrategySIMD
 		}
-		
-		// Use Lagrange interpolation to find polynomial value at x=0
-		secret[byteIdx] = lagrangeInterpolate(xCoords, yCoords, 0)
-		
-		// Clear temporary y-coordinates from memory
-		secureZeroBytes(yCoords)
+	}
+	switch strategy {
+	case StrategyScalar:
+		interpolateScalar(secret, parts, weights, 0, secretLen)
+	case StrategyStreaming:
+		// Bound the scratch memory to one window regardless of secret size
+		for start := 0; start < secretLen; start += strategyWindowSize {
+			if err := contextErr(eng.ctx); err != nil {
+				freeSecret(secret)
+				return nil, err
+			}
+			end := start + strategyWindowSize
+			if end > secretLen {
+				end = secretLen
+			}
+			interpolateRange(secret, parts, weights, start, end, workers, mulAdd)
+		}
+	default:
+		interpolateRange(secret, parts, weights, 0, secretLen, workers, mulAdd)
 	}
 
 	// Clear x-coordinates from memory
@@ -120,9 +304,87 @@ func Combine(parts [][]byte) ([]byte, error) {
 	return secret, nil
 }
 
+// interpolateScalar reconstructs secret[start:end] one byte at a time as the
+// weighted sum of the shares' y-values, using table lookups. It avoids the
+// per-call overhead of the slice kernels for secrets shorter than one vector.
+func interpolateScalar(secret []byte, parts [][]byte, weights []byte, start, end int) {
+	for byteIdx := start; byteIdx < end; byteIdx++ {
+		var acc byte
+		for i, part := range parts {
+			acc = gfAdd(acc, gfMult(weights[i], part[byteIdx+ShareOverhead]))
+		}
+		secret[byteIdx] = acc
+	}
+}
+
+// interpolateRange reconstructs secret[start:end] as the weighted sum of the
+// share payloads using mulAdd, one of the slice kernels, splitting the range
+// across up to workers goroutines.
+func interpolateRange(secret []byte, parts [][]byte, weights []byte, start, end, workers int, mulAdd func(dst, src []byte, c byte)) {
+	n := end - start
+	chunks := chunkCount(n, workers)
+	parallelFor(chunks, workers, func(c int) {
+		lo, hi := chunkBounds(n, chunks, c)
+		lo, hi = lo+start, hi+start
+
+		dst := secret[lo:hi]
+		for i, part := range parts {
+			mulAdd(dst, part[ShareOverhead+lo:ShareOverhead+hi], weights[i])
+		}
+	})
+}
+
+// minParallelChunk is the smallest number of secret bytes worth handing to a separate goroutine.
+const minParallelChunk = 4096
+
+// chunkCount returns how many ranges a secret of length n is divided into for
+// parallel processing: at most parallelism, and no range shorter than minParallelChunk.
+func chunkCount(n, parallelism int) int {
+	chunks := n / minParallelChunk
+	if chunks > parallelism {
+		chunks = parallelism
+	}
+	if chunks < 1 {
+		chunks = 1
+	}
+	return chunks
+}
+
+// chunkBounds returns the [start, end) byte range of chunk c out of chunks.
+func chunkBounds(n, chunks, c int) (int, int) {
+	return n * c / chunks, n * (c + 1) / chunks
+}
+
+// parallelFor calls fn(i) for every i in [0, n) using up to parallelism goroutines.
+// With parallelism <= 1 the calls run sequentially on the calling goroutine.
+func parallelFor(n, parallelism int, fn func(i int)) {
+	if parallelism <= 1 || n <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	if parallelism > n {
+		parallelism = n
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < n; i += parallelism {
+				fn(i)
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
 // lagrangeInterpolate performs Lagrange interpolation to evaluate a polynomial at point x.
 // Given points (xCoords[i], yCoords[i]), reconstructs the polynomial value at x.
-// This is the core mathematical operation for secret reconstruction.
+// Combine uses precomputed weights instead; this is the textbook reference the
+// weighted and vectorized paths are tested against.
 func lagrangeInterpolate(xCoords, yCoords []byte, x byte) byte {
 	var result byte
 	n := len(xCoords)
@@ -158,40 +420,24 @@ func lagrangeInterpolate(xCoords, yCoords []byte, x byte) byte {
 	return result
 }
 
-// lagrangeInterpolateSlice performs vectorized Lagrange interpolation for multiple polynomials.
-// This is an optimized version that processes multiple byte positions simultaneously.
-// Currently unused but kept for potential future performance optimizations.
+// lagrangeBasis returns the Lagrange basis coefficients L_i(x) for the given x-coordinates,
+// so that the polynomial value at x is the dot product of the coefficients with the y-values.
+func lagrangeBasis(xCoords []byte, x byte) []byte {
+	basis := make([]byte, len(xCoords))
+	gf256.LagrangeBasis(basis, xCoords, x)
+	return basis
+}
+
+// lagrangeInterpolateSlice performs vectorized Lagrange interpolation for multiple polynomials,
+// evaluating all of them at x with the slice kernels.
+// Used by the stream joiner and InterpolateShare.
 func lagrangeInterpolateSlice(dst []byte, xCoords []byte, yCoords [][]byte, x byte) {
-	n := len(xCoords)
-	if n == 0 || len(dst) == 0 {
+	if len(xCoords) == 0 || len(dst) == 0 {
 		return
 	}
 
-	for i := range dst {
-		dst[i] = 0
-	}
-
-	temp := make([]byte, len(dst))
-	
-	for i := 0; i < n; i++ {
-		numerator := byte(1)
-		denominator := byte(1)
-
-		for j := 0; j < n; j++ {
-			if i == j {
-				continue
-			}
-			
-			numerator = gfMult(numerator, gfAdd(x, xCoords[j]))
-			denominator = gfMult(denominator, gfAdd(xCoords[i], xCoords[j]))
-		}
-
-		if denominator == 0 {
-			continue
-		}
-
-		basis := gfDiv(numerator, denominator)
-		gfMultSlice(temp, yCoords[i], basis)
-		gfAddSlice(dst, dst, temp)
+	clear(dst)
+	for i, w := range lagrangeBasis(xCoords, x) {
+		gfMulAddSlice(dst, yCoords[i], w)
 	}
-}
\ No newline at end of file
+}
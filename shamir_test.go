@@ -233,4 +233,56 @@ func TestGFOperations(t *testing.T) {
 			t.Errorf("gfMultSlice failed: expected %v, got %v", expected, dst)
 		}
 	})
-}
\ No newline at end of file
+}
+
+// benchThresholds are the thresholds swept by the policy-scaling benchmarks.
+var benchThresholds = []int{2, 3, 5, 8, 16, 32, 64}
+
+// BenchmarkSplitThreshold reports how Split scales with the threshold (the
+// number of polynomial coefficients) and the number of parts.
+func BenchmarkSplitThreshold(b *testing.B) {
+	secret := make([]byte, 1024)
+	for i := range secret {
+		secret[i] = byte(i)
+	}
+
+	for _, k := range benchThresholds {
+		for _, n := range []int{k, 2 * k, 255} {
+			if n > 255 {
+				continue
+			}
+			b.Run(fmt.Sprintf("k=%d/n=%d", k, n), func(b *testing.B) {
+				b.SetBytes(int64(len(secret)))
+				for i := 0; i < b.N; i++ {
+					if _, err := Split(secret, n, k); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkCombineQuorum reports how Combine scales with the number of shares
+// supplied for reconstruction.
+func BenchmarkCombineQuorum(b *testing.B) {
+	secret := make([]byte, 1024)
+	for i := range secret {
+		secret[i] = byte(i)
+	}
+
+	for _, k := range benchThresholds {
+		shares, err := Split(secret, 2*k, k)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("quorum=%d", k), func(b *testing.B) {
+			b.SetBytes(int64(len(secret)))
+			for i := 0; i < b.N; i++ {
+				if _, err := Combine(shares[:k]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
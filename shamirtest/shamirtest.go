@@ -0,0 +1,317 @@
+// Package shamirtest provides differential checks of the shamir package
+// against a deliberately naive reference implementation, for fuzzing and
+// property tests in this module and in projects that integrate it.
+//
+// The reference shares nothing with the production code: it multiplies in
+// GF(256) (polynomial 0x11d) by shift-and-add rather than with lookup tables
+// or vector kernels, and interpolates one byte at a time with textbook
+// Lagrange formulas. Agreement between the two is strong evidence that
+// neither has an interpolation edge case wrong.
+//
+// A typical downstream fuzz target:
+//
+//	func FuzzShamir(f *testing.F) {
+//		f.Add([]byte("secret"), 5, 3)
+//		f.Fuzz(func(t *testing.T, secret []byte, parts, threshold int) {
+//			parts, threshold = shamirtest.Params(parts, threshold)
+//			if err := shamirtest.RoundTrip(secret, parts, threshold); err != nil {
+//				t.Fatal(err)
+//			}
+//		})
+//	}
+package shamirtest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// Errors returned by the reference implementation.
+var (
+	ErrTooFewShares     = errors.New("shamirtest: at least 2 shares required")
+	ErrDifferentLengths = errors.New("shamirtest: shares have different lengths")
+	ErrDuplicateX       = errors.New("shamirtest: duplicate x-coordinate")
+	ErrEmptyShare       = errors.New("shamirtest: share holds no y-values")
+)
+
+// mul multiplies in GF(256) modulo 0x11d by shift-and-add.
+func mul(a, b byte) byte {
+	var p byte
+	for b != 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1d // Low byte of 0x11d
+		}
+		b >>= 1
+	}
+	return p
+}
+
+// inv returns a⁻¹ = a²⁵⁴ by square-and-multiply.
+func inv(a byte) byte {
+	r := byte(1)
+	for e := 254; e > 0; e >>= 1 {
+		if e&1 != 0 {
+			r = mul(r, a)
+		}
+		a = mul(a, a)
+	}
+	return r
+}
+
+// weights returns the Lagrange basis polynomials for xs evaluated at x.
+func weights(xs []byte, x byte) []byte {
+	w := make([]byte, len(xs))
+	for i := range xs {
+		num, den := byte(1), byte(1)
+		for j := range xs {
+			if i != j {
+				num = mul(num, x^xs[j])
+				den = mul(den, xs[i]^xs[j])
+			}
+		}
+		w[i] = mul(num, inv(den))
+	}
+	return w
+}
+
+// dot returns Σ w[i]·ys[i].
+func dot(w, ys []byte) byte {
+	var result byte
+	for i := range w {
+		result ^= mul(w[i], ys[i])
+	}
+	return result
+}
+
+// Interpolate returns the value at x of the polynomial through (xs[i], ys[i]),
+// computed with the reference arithmetic. The xs must be distinct.
+func Interpolate(xs, ys []byte, x byte) byte {
+	return dot(weights(xs, x), ys)
+}
+
+// ReferenceSplit splits secret into raw shares at x = 1 through parts, as
+// Split does, reading the threshold-1 coefficients of each secret byte from
+// rng. The shares combine with shamir.Combine.
+func ReferenceSplit(secret []byte, parts, threshold int, rng io.Reader) ([][]byte, error) {
+	if len(secret) == 0 || parts < 2 || parts > 255 || threshold < 2 || threshold > parts {
+		return nil, fmt.Errorf("shamirtest: invalid parameters: %d bytes, %d parts, threshold %d", len(secret), parts, threshold)
+	}
+
+	shares := make([][]byte, parts)
+	for i := range shares {
+		shares[i] = make([]byte, 1+len(secret))
+		shares[i][0] = byte(i + 1)
+	}
+	coeffs := make([]byte, threshold)
+	for pos, b := range secret {
+		coeffs[0] = b
+		if _, err := io.ReadFull(rng, coeffs[1:]); err != nil {
+			return nil, err
+		}
+		for i, share := range shares {
+			// Horner's method, highest degree first.
+			var y byte
+			for d := threshold - 1; d >= 0; d-- {
+				y = mul(y, share[0]) ^ coeffs[d]
+			}
+			shares[i][1+pos] = y
+		}
+	}
+	return shares, nil
+}
+
+// ReferenceCombine reconstructs the secret from raw shares with the reference
+// arithmetic. It accepts any distinct x-coordinates, including 0, and makes no
+// attempt to recognise other share formats.
+func ReferenceCombine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrTooFewShares
+	}
+	n := len(shares[0])
+	if n < 2 {
+		return nil, ErrEmptyShare
+	}
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool)
+	for i, share := range shares {
+		if len(share) != n {
+			return nil, ErrDifferentLengths
+		}
+		if seen[share[0]] {
+			return nil, ErrDuplicateX
+		}
+		seen[share[0]] = true
+		xs[i] = share[0]
+	}
+
+	secret := make([]byte, n-1)
+	w := weights(xs, 0)
+	ys := make([]byte, len(shares))
+	for pos := range secret {
+		for i, share := range shares {
+			ys[i] = share[1+pos]
+		}
+		secret[pos] = dot(w, ys)
+	}
+	return secret, nil
+}
+
+// Params maps arbitrary fuzzer integers onto valid split parameters: parts in
+// [2, 255] and threshold in [2, parts].
+func Params(parts, threshold int) (int, int) {
+	parts = 2 + abs(parts)%254
+	threshold = 2 + abs(threshold)%(parts-1)
+	return parts, threshold
+}
+
+func abs(n int) int {
+	if n < 0 {
+		// -MinInt overflows; any non-negative value will do.
+		return -(n + 1)
+	}
+	return n
+}
+
+// RoundTrip checks Split and Combine for one secret and parameter set:
+//
+//   - Split returns parts shares of len(secret)+1 bytes with the distinct
+//     x-coordinates 1 through parts;
+//   - a sample of the remaining shares lies on the polynomial defined by the
+//     first threshold;
+//   - Combine and ReferenceCombine both recover the secret from several
+//     quorums of exactly threshold shares and from all shares;
+//   - Combine recovers the secret from shares made by ReferenceSplit.
+//
+// Empty secrets are expected to fail with shamir.ErrEmptySecret. The returned
+// error describes the first violation.
+func RoundTrip(secret []byte, parts, threshold int) error {
+	shares, err := shamir.Split(secret, parts, threshold)
+	if len(secret) == 0 {
+		if !errors.Is(err, shamir.ErrEmptySecret) {
+			return fmt.Errorf("shamirtest: Split of empty secret returned %v, want ErrEmptySecret", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("shamirtest: Split(%d bytes, %d, %d): %w", len(secret), parts, threshold, err)
+	}
+
+	if len(shares) != parts {
+		return fmt.Errorf("shamirtest: Split returned %d shares, want %d", len(shares), parts)
+	}
+	for i, share := range shares {
+		if len(share) != len(secret)+shamir.ShareOverhead {
+			return fmt.Errorf("shamirtest: share %d is %d bytes, want %d", i, len(share), len(secret)+shamir.ShareOverhead)
+		}
+		if share[0] != byte(i+1) {
+			return fmt.Errorf("shamirtest: share %d has x-coordinate %d, want %d", i, share[0], i+1)
+		}
+	}
+
+	// Shares beyond the first threshold must lie on the same polynomial. A
+	// sample of at most 16, always including the last, keeps 255-part splits
+	// fast enough to fuzz.
+	xs := make([]byte, threshold)
+	ys := make([]byte, threshold)
+	for i := range xs {
+		xs[i] = shares[i][0]
+	}
+	extra := shares[threshold:]
+	stride := 1 + len(extra)/16
+	for k := len(extra) - 1; k >= 0; k -= stride {
+		share := extra[k]
+		w := weights(xs, share[0])
+		for pos := range secret {
+			for i := range ys {
+				ys[i] = shares[i][1+pos]
+			}
+			if want := dot(w, ys); share[1+pos] != want {
+				return fmt.Errorf("shamirtest: share at x=%d byte %d is %d, but the first %d shares give %d", share[0], pos, share[1+pos], threshold, want)
+			}
+		}
+	}
+
+	for _, quorum := range quorums(shares, threshold) {
+		if err := combineBoth(quorum, secret); err != nil {
+			return err
+		}
+	}
+
+	refShares, err := ReferenceSplit(secret, parts, threshold, rand.Reader)
+	if err != nil {
+		return err
+	}
+	got, err := shamir.Combine(refShares[parts-threshold:])
+	if err != nil {
+		return fmt.Errorf("shamirtest: Combine of reference shares: %w", err)
+	}
+	if !bytes.Equal(got, secret) {
+		return fmt.Errorf("shamirtest: Combine of reference shares = %x, want %x", got, secret)
+	}
+	return nil
+}
+
+// quorums returns the first and last threshold shares, every other share
+// from the end, and all shares.
+func quorums(shares [][]byte, threshold int) [][][]byte {
+	var spread [][]byte
+	for i := len(shares) - 1; i >= 0 && len(spread) < threshold; i -= 2 {
+		spread = append(spread, shares[i])
+	}
+	out := [][][]byte{shares[:threshold], shares[len(shares)-threshold:], shares}
+	if len(spread) == threshold {
+		out = append(out, spread)
+	}
+	return out
+}
+
+// combineBoth checks that Combine and ReferenceCombine both recover want.
+func combineBoth(quorum [][]byte, want []byte) error {
+	got, err := shamir.Combine(quorum)
+	if err != nil {
+		return fmt.Errorf("shamirtest: Combine of %d shares: %w", len(quorum), err)
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("shamirtest: Combine of %d shares = %x, want %x", len(quorum), got, want)
+	}
+	ref, err := ReferenceCombine(quorum)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(ref, want) {
+		return fmt.Errorf("shamirtest: ReferenceCombine of %d shares = %x, want %x", len(quorum), ref, want)
+	}
+	return nil
+}
+
+// DiffCombine runs shamir.Combine and ReferenceCombine on the same, possibly
+// malformed, raw shares and reports a disagreement: Combine accepting shares
+// the reference rejects, or returning a different secret. Combine may reject
+// shares the reference accepts, since it recognises other share formats and
+// refuses layouts that are likely mistakes. Enveloped shares are skipped.
+func DiffCombine(shares [][]byte) error {
+	if len(shares) > 0 && shamir.IsEnvelope(shares[0]) {
+		return nil
+	}
+	got, err := shamir.Combine(shares)
+	ref, refErr := ReferenceCombine(shares)
+	switch {
+	case err != nil:
+		return nil
+	case refErr != nil:
+		return fmt.Errorf("shamirtest: Combine accepted shares the reference rejects (%v)", refErr)
+	case !bytes.Equal(got, ref):
+		return fmt.Errorf("shamirtest: Combine = %x, reference = %x", got, ref)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package shamirtest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+	"github.com/morizta/go-shamir/gf256"
+)
+
+func TestReferenceFieldMatchesGF256(t *testing.T) {
+	for a := 0; a < 256; a++ {
+		for b := 0; b < 256; b++ {
+			if got, want := mul(byte(a), byte(b)), gf256.Mul(byte(a), byte(b)); got != want {
+				t.Fatalf("mul(%d, %d) = %d, gf256.Mul = %d", a, b, got, want)
+			}
+		}
+		if a != 0 && mul(byte(a), inv(byte(a))) != 1 {
+			t.Fatalf("inv(%d) is not an inverse", a)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tc := range []struct{ size, parts, threshold int }{
+		{0, 3, 2},
+		{1, 2, 2},
+		{32, 5, 3},
+		{17, 255, 2},
+		{3, 255, 255},
+		{100, 20, 20},
+	} {
+		secret := make([]byte, tc.size)
+		rand.Read(secret)
+		if err := RoundTrip(secret, tc.parts, tc.threshold); err != nil {
+			t.Errorf("%+v: %v", tc, err)
+		}
+	}
+}
+
+func TestReferenceCombineZeroX(t *testing.T) {
+	// A share at x = 0 holds the secret itself.
+	secret := []byte("origin")
+	shares, err := ReferenceSplit(secret, 3, 2, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zero := append([]byte{0}, secret...)
+	got, err := ReferenceCombine([][]byte{shares[2], zero})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatalf("ReferenceCombine = %q, want %q", got, secret)
+	}
+	if err := DiffCombine([][]byte{shares[2], zero}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReferenceCombineValidation(t *testing.T) {
+	for name, tc := range map[string]struct {
+		shares [][]byte
+		want   error
+	}{
+		"one share":   {[][]byte{{1, 2}}, ErrTooFewShares},
+		"empty":       {[][]byte{{1}, {2}}, ErrEmptyShare},
+		"lengths":     {[][]byte{{1, 2}, {2, 3, 4}}, ErrDifferentLengths},
+		"duplicate x": {[][]byte{{1, 2}, {1, 3}}, ErrDuplicateX},
+	} {
+		if _, err := ReferenceCombine(tc.shares); err != tc.want {
+			t.Errorf("%s: got %v, want %v", name, err, tc.want)
+		}
+		if err := DiffCombine(tc.shares); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestDiffCombineSkipsEnvelopes(t *testing.T) {
+	shares, err := shamir.SplitWithOptions([]byte("secret"), shamir.WithParts(3), shamir.WithThreshold(2), shamir.WithPurpose("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DiffCombine(shares[:2]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParams(t *testing.T) {
+	for _, in := range [][2]int{{0, 0}, {-1, -1}, {253, math.MaxInt}, {math.MinInt, math.MinInt}, {math.MaxInt, 7}} {
+		parts, threshold := Params(in[0], in[1])
+		if parts < 2 || parts > 255 || threshold < 2 || threshold > parts {
+			t.Errorf("Params(%d, %d) = %d, %d", in[0], in[1], parts, threshold)
+		}
+	}
+	if parts, _ := Params(253, 0); parts != 255 {
+		t.Errorf("Params(253, _) = %d parts, want 255", parts)
+	}
+}
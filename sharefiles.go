@@ -0,0 +1,220 @@
+package shamir
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Share files.
+//
+// WriteShareFiles gives every share a canonical file name derived from the
+// share itself,
+//
+//	<set ID in hex>-<index>-of-<n>.share
+//
+// where index is the share's x-coordinate and n the number of shares written,
+// so that provisioning tools such as Terraform see the same names on every
+// run and operators can tell at a glance which files belong together.
+// ReadShareFiles checks those names against the set ID and index recorded in
+// the shares, which catches files renamed or copied over one another.
+
+// ShareFileExt is the file name extension of files written by WriteShareFiles.
+const ShareFileExt = ".share"
+
+// ShareFileOptions configures WriteShareFiles. The zero value writes binary
+// shares and refuses to replace existing files.
+type ShareFileOptions struct {
+	PEM       bool // Write shares as PEM text (see EncodeSharePEM) instead of binary
+	Overwrite bool // Replace existing files of the same name
+}
+
+// ShareFileName returns the canonical file name of share, one of n shares
+// written together. The share must carry a set ID: enveloped shares (see
+// SplitWithOptions) and SplitMulti shares do, raw shares from Split do not and
+// fail with ErrNoSetID.
+func ShareFileName(share []byte, n int) (string, error) {
+	if n < 1 || n > 255 {
+		return "", NewValidationError("parts", n, "shamir: share count must be between 1 and 255")
+	}
+	set, index, err := shareIdentity(share)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%d-of-%d%s", set, index, n, ShareFileExt), nil
+}
+
+// WriteShareFiles writes each share to its own file in dir under its
+// canonical name (see ShareFileName) and returns the paths in the order of
+// shares. Files are created with mode 0600 and written atomically: each share
+// goes to a temporary file that is synced and then renamed into place, so a
+// crash never leaves a partly written share behind.
+//
+// Unless opts.Overwrite is set, WriteShareFiles fails before writing anything
+// if any of the files exists. If writing fails part way, the files already
+// written are removed; files that were overwritten cannot be restored.
+func WriteShareFiles(dir string, shares [][]byte, opts ShareFileOptions) ([]string, error) {
+	paths := make([]string, len(shares))
+	for i, share := range shares {
+		name, err := ShareFileName(share, len(shares))
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", i, err)
+		}
+		paths[i] = filepath.Join(dir, name)
+		if _, err := os.Lstat(paths[i]); err == nil && !opts.Overwrite {
+			return nil, fmt.Errorf("shamir: %s: %w", paths[i], fs.ErrExist)
+		}
+	}
+
+	for i, share := range shares {
+		data := share
+		if opts.PEM {
+			var err error
+			if data, err = EncodeSharePEM(share); err != nil {
+				removeFiles(paths[:i])
+				return nil, err
+			}
+		}
+		err := writeFileAtomic(paths[i], data)
+		if opts.PEM {
+			secureZeroBytes(data)
+		}
+		if err != nil {
+			removeFiles(paths[:i])
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// ReadShareFiles reads the share files in dir, that is the files named with
+// ShareFileExt, and returns their shares in increasing index order. Files may
+// hold binary or PEM shares. Every name must be canonical and match the set ID
+// and index recorded in its share, or ReadShareFiles fails with
+// ErrShareFileName; files of different splits, or that disagree on the number
+// of shares, fail with ErrMismatchedShares. Other files are ignored.
+func ReadShareFiles(dir string) ([][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type shareFile struct {
+		index byte
+		share []byte
+	}
+	var files []shareFile
+	wipe := func() {
+		for _, f := range files {
+			secureZeroBytes(f.share)
+		}
+	}
+	var first string
+	var firstSet SetID
+	var firstN int
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ShareFileExt) || !entry.Type().IsRegular() {
+			continue
+		}
+		set, index, n, ok := parseShareFileName(name)
+		if !ok {
+			wipe()
+			return nil, fmt.Errorf("%w: %s is not a canonical share file name", ErrShareFileName, name)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			wipe()
+			return nil, err
+		}
+		share, err := decodeShareFile(data)
+		secureZeroBytes(data)
+		if err != nil {
+			wipe()
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		files = append(files, shareFile{index, share})
+
+		gotSet, gotIndex, err := shareIdentity(share)
+		if err != nil {
+			wipe()
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if gotSet != set || gotIndex != index {
+			wipe()
+			return nil, fmt.Errorf("%w: %s holds share %d of set %s", ErrShareFileName, name, gotIndex, gotSet)
+		}
+		if first == "" {
+			first, firstSet, firstN = name, set, n
+		} else if set != firstSet || n != firstN {
+			wipe()
+			return nil, fmt.Errorf("%w: %s and %s", ErrMismatchedShares, first, name)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no share files in %s", ErrTooFewParts, dir)
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].index < files[j].index })
+	shares := make([][]byte, len(files))
+	for i, f := range files {
+		shares[i] = f.share
+	}
+	return shares, nil
+}
+
+// parseShareFileName parses a name written by ShareFileName, reporting false
+// for anything else. Numbers must be in canonical decimal form.
+func parseShareFileName(name string) (set SetID, index byte, n int, ok bool) {
+	stem, _ := strings.CutSuffix(name, ShareFileExt)
+	id, rest, ok := strings.Cut(stem, "-")
+	if !ok || set.UnmarshalText([]byte(id)) != nil || strings.ToLower(id) != id {
+		return SetID{}, 0, 0, false
+	}
+	i, count, ok := strings.Cut(rest, "-of-")
+	if !ok {
+		return SetID{}, 0, 0, false
+	}
+	idx, err1 := strconv.Atoi(i)
+	n, err2 := strconv.Atoi(count)
+	if err1 != nil || err2 != nil || idx < 1 || idx > 255 || n < 1 || n > 255 ||
+		strconv.Itoa(idx) != i || strconv.Itoa(n) != count {
+		return SetID{}, 0, 0, false
+	}
+	return set, byte(idx), n, true
+}
+
+// writeFileAtomic writes data to path through a synced temporary file in the
+// same directory, renamed into place. The file has mode 0600.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".share-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// removeFiles removes the files at paths, ignoring errors.
+func removeFiles(paths []string) {
+	for _, path := range paths {
+		os.Remove(path)
+	}
+}
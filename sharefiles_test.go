@@ -0,0 +1,150 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteReadShareFiles(t *testing.T) {
+	secret := []byte("operator secret")
+	shares, err := SplitWithOptions(secret, WithParts(5), WithThreshold(3), WithPurpose("ops"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, _ := ShareSetID(shares[0])
+
+	for _, pem := range []bool{false, true} {
+		dir := t.TempDir()
+		paths, err := WriteShareFiles(dir, shares, ShareFileOptions{PEM: pem})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := filepath.Join(dir, set.String()+"-3-of-5.share"); paths[2] != want {
+			t.Fatalf("path = %s, want %s", paths[2], want)
+		}
+		info, err := os.Stat(paths[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+			t.Fatalf("mode = %v, want 0600", info.Mode().Perm())
+		}
+
+		// Only shares and unrelated files remain; no temporary files.
+		os.WriteFile(filepath.Join(dir, "README.txt"), []byte("custody notes"), 0o644)
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 6 {
+			t.Fatalf("%d directory entries, want 6", len(entries))
+		}
+
+		got, err := ReadShareFiles(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range shares {
+			if !bytes.Equal(got[i], shares[i]) {
+				t.Fatalf("pem=%v: share %d differs", pem, i)
+			}
+		}
+		combined, err := CombineWithOptions(got[1:4], WithPurpose("ops"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(combined, secret) {
+			t.Fatal("reconstructed secret mismatch")
+		}
+	}
+}
+
+func TestWriteShareFilesExisting(t *testing.T) {
+	shares, _ := SplitWithOptions([]byte("secret"), WithParts(3), WithThreshold(2), WithPurpose("p"))
+	dir := t.TempDir()
+	if _, err := WriteShareFiles(dir, shares, ShareFileOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteShareFiles(dir, shares, ShareFileOptions{}); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("expected fs.ErrExist, got %v", err)
+	}
+	if _, err := WriteShareFiles(dir, shares, ShareFileOptions{Overwrite: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := Split([]byte("secret"), 3, 2)
+	if _, err := WriteShareFiles(t.TempDir(), raw, ShareFileOptions{}); !errors.Is(err, ErrNoSetID) {
+		t.Fatalf("expected ErrNoSetID, got %v", err)
+	}
+}
+
+func TestReadShareFilesErrors(t *testing.T) {
+	shares, _ := SplitWithOptions([]byte("secret"), WithParts(3), WithThreshold(2), WithPurpose("p"))
+	other, _ := SplitWithOptions([]byte("secret"), WithParts(3), WithThreshold(2), WithPurpose("p"))
+
+	write := func(t *testing.T, shares [][]byte) (string, []string) {
+		dir := t.TempDir()
+		paths, err := WriteShareFiles(dir, shares, ShareFileOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dir, paths
+	}
+
+	t.Run("swapped", func(t *testing.T) {
+		dir, paths := write(t, shares)
+		os.WriteFile(paths[0], shares[1], 0o600)
+		if _, err := ReadShareFiles(dir); !errors.Is(err, ErrShareFileName) {
+			t.Fatalf("expected ErrShareFileName, got %v", err)
+		}
+	})
+	t.Run("renamed", func(t *testing.T) {
+		dir, paths := write(t, shares)
+		os.Rename(paths[0], filepath.Join(dir, "share-1.share"))
+		if _, err := ReadShareFiles(dir); !errors.Is(err, ErrShareFileName) {
+			t.Fatalf("expected ErrShareFileName, got %v", err)
+		}
+	})
+	t.Run("mixed sets", func(t *testing.T) {
+		dir, _ := write(t, shares[:2])
+		if _, err := WriteShareFiles(dir, other[2:], ShareFileOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadShareFiles(dir); !errors.Is(err, ErrMismatchedShares) {
+			t.Fatalf("expected ErrMismatchedShares, got %v", err)
+		}
+	})
+	t.Run("empty", func(t *testing.T) {
+		if _, err := ReadShareFiles(t.TempDir()); !errors.Is(err, ErrTooFewParts) {
+			t.Fatalf("expected ErrTooFewParts, got %v", err)
+		}
+	})
+}
+
+func TestParseShareFileName(t *testing.T) {
+	const id = "00112233445566778899aabbccddeeff"
+	for _, name := range []string{
+		id + "-1-of-3.share",
+		id + "-255-of-255.share",
+	} {
+		if _, _, _, ok := parseShareFileName(name); !ok {
+			t.Errorf("%s: rejected", name)
+		}
+	}
+	for _, name := range []string{
+		id + "-01-of-3.share",
+		id + "-0-of-3.share",
+		id + "-256-of-3.share",
+		id + "-1-of-0.share",
+		id + "-1of3.share",
+		"00112233445566778899AABBCCDDEEFF-1-of-3.share",
+		"0011-1-of-3.share",
+		"share-1.share",
+	} {
+		if _, _, _, ok := parseShareFileName(name); ok {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package shamir
+
+// Low-level share arithmetic.
+//
+// Shamir sharing is linear: applying the same operation to every share of a
+// set applies it to the secret. These functions expose that structure for
+// protocols built on top of threshold sharing, such as threshold OPRFs and
+// PAKEs, so they can reuse this package's field arithmetic instead of copying
+// it. They operate on raw shares from Split and return new shares, leaving
+// their inputs untouched.
+//
+// The operations are guarded against the ways they could leak or destroy a
+// secret: scaling by zero and evaluating the polynomial at x = 0 are refused.
+
+// ScaleShare multiplies every y-value of a share by scalar. Scaling all shares
+// of a set by the same scalar yields a sharing of secret·scalar, which is the
+// blinding step of most threshold OPRF constructions. Undo it with UnscaleShare.
+func ScaleShare(share []byte, scalar byte) ([]byte, error) {
+	if err := validateShare(share); err != nil {
+		return nil, err
+	}
+	if scalar == 0 {
+		return nil, NewValidationError("scalar", 0, "shamir: scaling by zero would destroy the share")
+	}
+
+	out := make([]byte, len(share))
+	out[0] = share[0]
+	gfMultSlice(out[ShareOverhead:], share[ShareOverhead:], scalar)
+	return out, nil
+}
+
+// UnscaleShare divides every y-value of a share by scalar, reversing ScaleShare.
+func UnscaleShare(share []byte, scalar byte) ([]byte, error) {
+	if scalar == 0 {
+		return nil, NewValidationError("scalar", 0, "shamir: cannot divide by zero")
+	}
+	return ScaleShare(share, gfInv(scalar))
+}
+
+// AddConstant adds constant to a share's y-values. Adding the same constant
+// to every share of a set yields a sharing of secret+constant (XOR in GF(256)).
+// The constant must have the same length as the secret.
+func AddConstant(share, constant []byte) ([]byte, error) {
+	if err := validateShare(share); err != nil {
+		return nil, err
+	}
+	if len(constant) != len(share)-ShareOverhead {
+		return nil, ErrDifferentLengths
+	}
+
+	out := make([]byte, len(share))
+	out[0] = share[0]
+	gfAddSlice(out[ShareOverhead:], share[ShareOverhead:], constant)
+	return out, nil
+}
+
+// AddShares adds two shares with the same x-coordinate taken from different
+// sharings. Doing so for every x yields a sharing of the sum of both secrets
+// with the larger of the two thresholds.
+func AddShares(a, b []byte) ([]byte, error) {
+	if err := validateShare(a); err != nil {
+		return nil, err
+	}
+	if err := validateShare(b); err != nil {
+		return nil, err
+	}
+	if len(a) != len(b) {
+		return nil, ErrDifferentLengths
+	}
+	if a[0] != b[0] {
+		return nil, NewValidationError("share", int(b[0]), "shamir: shares must have the same x-coordinate")
+	}
+
+	out := make([]byte, len(a))
+	out[0] = a[0]
+	gfAddSlice(out[ShareOverhead:], a[ShareOverhead:], b[ShareOverhead:])
+	return out, nil
+}
+
+// InterpolateShare derives the share at x-coordinate x from at least threshold
+// shares of the same set, without reconstructing the secret. x must not be
+// zero, since the polynomial's value there is the secret itself; use Combine
+// for reconstruction.
+func InterpolateShare(parts [][]byte, x byte) ([]byte, error) {
+	if x == 0 {
+		return nil, NewValidationError("x-coordinate", 0, "shamir: evaluating at x = 0 would reveal the secret")
+	}
+	if err := validateCombineParams(parts); err != nil {
+		return nil, err
+	}
+
+	xCoords := make([]byte, len(parts))
+	yCoords := make([][]byte, len(parts))
+	for i, part := range parts {
+		if part[0] == 0 {
+			return nil, zeroXError(i)
+		}
+		xCoords[i] = part[0]
+		yCoords[i] = part[ShareOverhead:]
+	}
+
+	out := make([]byte, len(parts[0]))
+	out[0] = x
+	lagrangeInterpolateSlice(out[ShareOverhead:], xCoords, yCoords, x)
+	return out, nil
+}
+
+// ShareIndex returns the x-coordinate of a raw or enveloped share, for
+// encoders that label shares without reconstructing anything. Enveloped shares
+// are parsed and verified in full; raw shares, including those from
+// SplitWithIntegrity, are only checked for length and a non-zero index.
+func ShareIndex(share []byte) (byte, error) {
+	if IsEnvelope(share) {
+		var s Share
+		if err := s.UnmarshalBinary(share); err != nil {
+			return 0, err
+		}
+		secureZeroBytes(s.Payload)
+		return s.Index, nil
+	}
+	if err := validateShare(share); err != nil {
+		return 0, err
+	}
+	return share[0], nil
+}
+
+// validateShare checks that share is a well-formed raw share.
+func validateShare(share []byte) error {
+	if len(share) < ShareOverhead+1 {
+		return ErrTooShort
+	}
+	if share[0] == 0 {
+		return zeroXError(0)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestShareMathLinearity(t *testing.T) {
+	secret := []byte("blinded input")
+	other := []byte("second secret")
+
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherShares, err := Split(other, 5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apply := func(fn func(i int, share []byte) ([]byte, error)) [][]byte {
+		t.Helper()
+		out := make([][]byte, len(shares))
+		for i, share := range shares {
+			if out[i], err = fn(i, share); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return out
+	}
+
+	t.Run("scale and unscale", func(t *testing.T) {
+		const r = 0x8e
+		blinded := apply(func(_ int, s []byte) ([]byte, error) { return ScaleShare(s, r) })
+
+		got, err := Combine(blinded[:3])
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := make([]byte, len(secret))
+		gfMultSlice(want, secret, r)
+		if !bytes.Equal(got, want) {
+			t.Fatal("scaled shares did not combine to secret·r")
+		}
+
+		for i := range blinded {
+			if blinded[i], err = UnscaleShare(blinded[i], r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got, _ := Combine(blinded[2:]); !bytes.Equal(got, secret) {
+			t.Fatal("unscaling did not restore the secret")
+		}
+	})
+
+	t.Run("add constant", func(t *testing.T) {
+		constant := bytes.Repeat([]byte{0x5a}, len(secret))
+		shifted := apply(func(_ int, s []byte) ([]byte, error) { return AddConstant(s, constant) })
+
+		got, err := Combine(shifted[1:4])
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := make([]byte, len(secret))
+		gfAddSlice(want, secret, constant)
+		if !bytes.Equal(got, want) {
+			t.Fatal("shifted shares did not combine to secret+constant")
+		}
+	})
+
+	t.Run("add shares", func(t *testing.T) {
+		sums := apply(func(i int, s []byte) ([]byte, error) { return AddShares(s, otherShares[i]) })
+
+		got, err := Combine(sums[:3])
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := make([]byte, len(secret))
+		gfAddSlice(want, secret, other)
+		if !bytes.Equal(got, want) {
+			t.Fatal("summed shares did not combine to the sum of the secrets")
+		}
+	})
+
+	t.Run("interpolate share", func(t *testing.T) {
+		derived, err := InterpolateShare(shares[:3], 200)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if derived[0] != 200 {
+			t.Fatalf("expected x-coordinate 200, got %d", derived[0])
+		}
+		// The derived share is interchangeable with the originals.
+		if got, _ := Combine([][]byte{shares[3], derived, shares[4]}); !bytes.Equal(got, secret) {
+			t.Fatal("derived share does not combine with the originals")
+		}
+		// Deriving an existing x-coordinate reproduces that share.
+		if again, _ := InterpolateShare(shares[1:4], shares[0][0]); !bytes.Equal(again, shares[0]) {
+			t.Fatal("interpolating at an existing x-coordinate changed the share")
+		}
+	})
+}
+
+func TestShareMathGuards(t *testing.T) {
+	shares, err := Split([]byte("guarded"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ScaleShare(shares[0], 0); err == nil {
+		t.Error("expected error scaling by zero")
+	}
+	if _, err := UnscaleShare(shares[0], 0); err == nil {
+		t.Error("expected error dividing by zero")
+	}
+	if _, err := InterpolateShare(shares, 0); err == nil {
+		t.Error("expected error evaluating at x = 0")
+	}
+	if _, err := AddShares(shares[0], shares[1]); err == nil {
+		t.Error("expected error adding shares with different x-coordinates")
+	}
+	if _, err := AddConstant(shares[0], []byte{1}); err != ErrDifferentLengths {
+		t.Errorf("expected ErrDifferentLengths, got %v", err)
+	}
+	if _, err := ScaleShare([]byte{1}, 2); err != ErrTooShort {
+		t.Errorf("expected ErrTooShort, got %v", err)
+	}
+
+	// Inputs are never modified.
+	before := append([]byte(nil), shares[0]...)
+	if _, err := ScaleShare(shares[0], 3); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, shares[0]) {
+		t.Error("ScaleShare modified its input")
+	}
+}
+
+func TestShareIndex(t *testing.T) {
+	raw, err := SplitWithIntegrity([]byte("indexed"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enveloped, err := SplitWithOptions([]byte("indexed"), WithParts(3), WithThreshold(2), WithLabels("a", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range raw {
+		if x, err := ShareIndex(raw[i]); err != nil || x != byte(i+1) {
+			t.Errorf("raw share %d: ShareIndex = %d, %v", i, x, err)
+		}
+		if x, err := ShareIndex(enveloped[i]); err != nil || x != byte(i+1) {
+			t.Errorf("enveloped share %d: ShareIndex = %d, %v", i, x, err)
+		}
+	}
+
+	if _, err := ShareIndex([]byte{1}); err != ErrTooShort {
+		t.Errorf("short share: expected ErrTooShort, got %v", err)
+	}
+	var ve *ValidationError
+	if _, err := ShareIndex([]byte{0, 1}); !errors.As(err, &ve) {
+		t.Errorf("zero index: expected ValidationError, got %v", err)
+	}
+	if _, err := ShareIndex(enveloped[0][:len(enveloped[0])-1]); !errors.Is(err, ErrIntegrityCheckFailed) {
+		t.Errorf("truncated envelope: expected ErrIntegrityCheckFailed, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+// Package sidechannel measures whether Split and Combine take time that
+// depends on the secret, in the style of dudect (Reparaz, Balasch and
+// Verbauwhede, "Dude, is my code constant time?", 2017).
+//
+// An operation is timed on many inputs of two classes, interleaved at random:
+// a fixed secret and fresh random secrets. If its running time does not
+// depend on the secret, the two timing distributions are the same, and
+// Welch's t-test finds no difference. Measurements are also cropped at
+// several percentiles, which removes the long tail of interruptions and
+// makes smaller differences visible; the largest |t| over all crops is
+// reported. A |t| above LeakThreshold is strong evidence of a leak; a small
+// |t| only means none was found with this many measurements.
+//
+// The default backend multiplies with log/exp table lookups and is not
+// constant-time, so these measurements document its behaviour and give
+// constant-time work a yardstick rather than a pass/fail gate; set
+// Config.ConstantTime to measure the hardened combine path instead. Timing is
+// noisy on shared machines: run on an idle one, with many measurements.
+package sidechannel
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	mrand "math/rand/v2"
+	"runtime"
+	"slices"
+	"time"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// LeakThreshold is the |t| above which a measurement indicates a timing
+// leak, as in dudect.
+const LeakThreshold = 4.5
+
+// Class identifies the inputs of one of the two measured classes.
+type Class int
+
+const (
+	Fixed  Class = iota // The same secret every time
+	Random              // A fresh random secret every time
+)
+
+// Result is the outcome of a measurement.
+type Result struct {
+	// Measurements is the number of timed runs, of both classes.
+	Measurements int
+
+	// T is Welch's t-statistic of the fixed class against the random class,
+	// for the crop with the largest |t|. A positive T means the fixed class
+	// was slower.
+	T float64
+
+	// Crop is the percentile the measurements were cropped at for T, or 1
+	// for none.
+	Crop float64
+
+	// FixedMean and RandomMean are the mean durations of each class after
+	// that crop.
+	FixedMean, RandomMean time.Duration
+}
+
+// Leaks reports whether |T| exceeds LeakThreshold.
+func (r Result) Leaks() bool {
+	return math.Abs(r.T) > LeakThreshold
+}
+
+// Config selects the operation parameters and measurement count for
+// MeasureSplit and MeasureCombine. The zero value selects the defaults.
+type Config struct {
+	Measurements int // Timed runs of both classes; 0 means 100000
+	SecretSize   int // Secret length in bytes; 0 means 32
+	Parts        int // 0 means 5
+	Threshold    int // 0 means 3
+
+	// ConstantTime measures MeasureCombine with shamir.WithConstantTime.
+	ConstantTime bool
+}
+
+// withDefaults fills in the defaults and checks that the configuration
+// describes a valid split, so that no error path is ever timed.
+func (c Config) withDefaults() (Config, error) {
+	if c.Measurements <= 0 {
+		c.Measurements = 100000
+	}
+	if c.SecretSize <= 0 {
+		c.SecretSize = 32
+	}
+	if c.Parts <= 0 {
+		c.Parts = 5
+	}
+	if c.Threshold <= 0 {
+		c.Threshold = 3
+	}
+	if _, err := shamir.Split(make([]byte, c.SecretSize), c.Parts, c.Threshold); err != nil {
+		return c, fmt.Errorf("sidechannel: invalid config: %w", err)
+	}
+	return c, nil
+}
+
+// MeasureSplit times Split on the all-zero secret against random secrets of
+// the same length. It fails if cfg does not describe a valid split.
+func MeasureSplit(cfg Config) (Result, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return Result{}, err
+	}
+	fixed := make([]byte, cfg.SecretSize)
+	return Measure(cfg.Measurements,
+		func(c Class) ([]byte, error) {
+			if c == Fixed {
+				return fixed, nil
+			}
+			return randomBytes(cfg.SecretSize)
+		},
+		func(secret []byte) error {
+			_, err := shamir.Split(secret, cfg.Parts, cfg.Threshold)
+			return err
+		})
+}
+
+// MeasureCombine times Combine on threshold shares of the all-zero secret
+// against shares of random secrets. Every input is a fresh split, so only
+// the secret differs between the classes, not the randomness of the shares.
+// It fails if cfg does not describe a valid split.
+func MeasureCombine(cfg Config) (Result, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return Result{}, err
+	}
+	fixed := make([]byte, cfg.SecretSize)
+	return Measure(cfg.Measurements,
+		func(c Class) ([][]byte, error) {
+			secret := fixed
+			if c == Random {
+				var err error
+				if secret, err = randomBytes(cfg.SecretSize); err != nil {
+					return nil, err
+				}
+			}
+			shares, err := shamir.Split(secret, cfg.Parts, cfg.Threshold)
+			if err != nil {
+				return nil, err
+			}
+			return shares[:cfg.Threshold], nil
+		},
+		func(shares [][]byte) error {
+			var err error
+			if cfg.ConstantTime {
+				_, err = shamir.CombineWithOptions(shares, shamir.WithConstantTime(true))
+			} else {
+				_, err = shamir.Combine(shares)
+			}
+			return err
+		})
+}
+
+// batchSize is the number of inputs prepared ahead of each timed batch, so
+// that preparing them stays out of the measurements.
+const batchSize = 1000
+
+// crops are the percentiles Measure crops the measurements at, besides none.
+var crops = []float64{0.5, 0.75, 0.9, 0.95, 0.99}
+
+// Measure times op on measurements inputs returned by input, half of each
+// class in a random order, and returns the t-test of the two classes. input
+// runs outside the timed region. The first error from input or op ends the
+// measurement and is returned, since the timing of a failing operation says
+// nothing about the successful one.
+func Measure[T any](measurements int, input func(Class) (T, error), op func(T) error) (Result, error) {
+	durations := make([]int64, 0, measurements)
+	classes := make([]Class, 0, measurements)
+	inputs := make([]T, 0, batchSize)
+	batchClasses := make([]Class, 0, batchSize)
+
+	for len(durations) < measurements {
+		n := min(batchSize, measurements-len(durations))
+		inputs, batchClasses = inputs[:0], batchClasses[:0]
+		for i := 0; i < n; i++ {
+			c := Class(mrand.IntN(2))
+			in, err := input(c)
+			if err != nil {
+				return Result{}, err
+			}
+			batchClasses = append(batchClasses, c)
+			inputs = append(inputs, in)
+		}
+		runtime.GC() // Keep collections of the prepared inputs out of the batch
+
+		for i, in := range inputs {
+			start := time.Now()
+			err := op(in)
+			elapsed := time.Since(start)
+			if err != nil {
+				return Result{}, err
+			}
+			durations = append(durations, int64(elapsed))
+			classes = append(classes, batchClasses[i])
+		}
+	}
+	return analyze(durations, classes), nil
+}
+
+// analyze runs Welch's t-test on the durations of the two classes, uncropped
+// and cropped at each percentile, and returns the result with the largest |t|.
+func analyze(durations []int64, classes []Class) Result {
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+
+	best := Result{Measurements: len(durations)}
+	for _, p := range append([]float64{1}, crops...) {
+		limit := sorted[len(sorted)-1]
+		if p < 1 {
+			limit = sorted[int(p*float64(len(sorted)-1))]
+		}
+		var fixed, random welford
+		for i, d := range durations {
+			if d > limit {
+				continue
+			}
+			if classes[i] == Fixed {
+				fixed.add(float64(d))
+			} else {
+				random.add(float64(d))
+			}
+		}
+		t := welchT(fixed, random)
+		if p == 1 || math.Abs(t) > math.Abs(best.T) {
+			best.T, best.Crop = t, p
+			best.FixedMean, best.RandomMean = time.Duration(fixed.mean), time.Duration(random.mean)
+		}
+	}
+	return best
+}
+
+// welford accumulates a mean and variance online (Welford's algorithm).
+type welford struct {
+	n        int
+	mean, m2 float64
+}
+
+func (w *welford) add(x float64) {
+	w.n++
+	d := x - w.mean
+	w.mean += d / float64(w.n)
+	w.m2 += d * (x - w.mean)
+}
+
+func (w welford) variance() float64 {
+	if w.n < 2 {
+		return 0
+	}
+	return w.m2 / float64(w.n-1)
+}
+
+// welchT returns Welch's t-statistic for the difference of the means of a
+// and b, or 0 if either has too few samples or there is no variance.
+func welchT(a, b welford) float64 {
+	if a.n < 2 || b.n < 2 {
+		return 0
+	}
+	se := math.Sqrt(a.variance()/float64(a.n) + b.variance()/float64(b.n))
+	if se == 0 {
+		return 0
+	}
+	return (a.mean - b.mean) / se
+}
+
+// randomBytes returns n random bytes.
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
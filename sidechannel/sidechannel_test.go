@@ -0,0 +1,106 @@
+package sidechannel
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+func TestWelchT(t *testing.T) {
+	var a, b welford
+	for _, x := range []float64{1, 2, 3, 4, 5} {
+		a.add(x)
+	}
+	for _, x := range []float64{3, 4, 5, 6, 7} {
+		b.add(x)
+	}
+	if a.mean != 3 || a.variance() != 2.5 {
+		t.Fatalf("mean %v, variance %v; want 3 and 2.5", a.mean, a.variance())
+	}
+	// (3 - 5) / sqrt(2.5/5 + 2.5/5) = -2
+	if got := welchT(a, b); math.Abs(got+2) > 1e-12 {
+		t.Errorf("welchT = %v, want -2", got)
+	}
+	if got := welchT(a, welford{}); got != 0 {
+		t.Errorf("welchT with an empty class = %v, want 0", got)
+	}
+}
+
+// sink keeps the leaky operation from being optimised away.
+var sink int
+
+func TestMeasureDetectsLeak(t *testing.T) {
+	// Work proportional to the number of zero bytes: the all-zero fixed
+	// class is many times slower.
+	leaky := func(in []byte) error {
+		for _, b := range in {
+			if b == 0 {
+				for i := 0; i < 200; i++ {
+					sink += i
+				}
+			}
+		}
+		return nil
+	}
+	r, err := Measure(4000, func(c Class) ([]byte, error) {
+		if c == Fixed {
+			return make([]byte, 32), nil
+		}
+		return randomBytes(32)
+	}, leaky)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Measurements != 4000 {
+		t.Errorf("Measurements = %d, want 4000", r.Measurements)
+	}
+	if !r.Leaks() || r.T <= 0 || r.FixedMean <= r.RandomMean {
+		t.Errorf("leaky operation not detected: %+v", r)
+	}
+}
+
+func TestMeasureSplitCombine(t *testing.T) {
+	cfg := Config{Measurements: 5000}
+	if testing.Short() {
+		cfg.Measurements = 1000
+	}
+	// The table-based backend is not constant-time, so this reports rather
+	// than asserts; run with -v to see the statistics.
+	for name, measure := range map[string]func(Config) (Result, error){"Split": MeasureSplit, "Combine": MeasureCombine} {
+		r, err := measure(cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if r.Measurements != cfg.Measurements || r.Crop <= 0 || r.Crop > 1 {
+			t.Errorf("%s: malformed result %+v", name, r)
+		}
+		t.Logf("%s: t = %.2f at crop %.2f (fixed %v, random %v), leak indicated: %v",
+			name, r.T, r.Crop, r.FixedMean, r.RandomMean, r.Leaks())
+	}
+	ct := cfg
+	ct.ConstantTime = true
+	r, err := MeasureCombine(ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("Combine (constant-time): t = %.2f at crop %.2f (fixed %v, random %v), leak indicated: %v",
+		r.T, r.Crop, r.FixedMean, r.RandomMean, r.Leaks())
+}
+
+func TestMeasureRejectsInvalidConfig(t *testing.T) {
+	for name, measure := range map[string]func(Config) (Result, error){"Split": MeasureSplit, "Combine": MeasureCombine} {
+		for _, cfg := range []Config{{Measurements: 1000, Parts: 2}, {Measurements: 1000, Parts: 300}} {
+			var ve *shamir.ValidationError
+			if _, err := measure(cfg); !errors.As(err, &ve) {
+				t.Errorf("%s with %+v: got %v, want a ValidationError", name, cfg, err)
+			}
+		}
+	}
+
+	failed := errors.New("op failed")
+	if _, err := Measure(10, func(Class) (int, error) { return 0, nil }, func(int) error { return failed }); err != failed {
+		t.Errorf("Measure with a failing op: got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package slip39
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+const (
+	baseIterationCount = 10000
+	roundCount         = 4
+)
+
+// encrypt applies the SLIP-0039 four-round Feistel cipher keyed by the passphrase.
+func encrypt(masterSecret []byte, passphrase string, exponent int, id uint16, extendable bool) ([]byte, error) {
+	half := len(masterSecret) / 2
+	l := append([]byte(nil), masterSecret[:half]...)
+	r := append([]byte(nil), masterSecret[half:]...)
+	salt := cipherSalt(id, extendable)
+
+	for i := 0; i < roundCount; i++ {
+		f, err := roundFunction(i, passphrase, exponent, salt, r)
+		if err != nil {
+			return nil, err
+		}
+		l, r = r, xor(l, f)
+	}
+	return append(r, l...), nil
+}
+
+// decrypt reverses encrypt.
+func decrypt(encrypted []byte, passphrase string, exponent int, id uint16, extendable bool) ([]byte, error) {
+	half := len(encrypted) / 2
+	l := append([]byte(nil), encrypted[:half]...)
+	r := append([]byte(nil), encrypted[half:]...)
+	salt := cipherSalt(id, extendable)
+
+	for i := roundCount - 1; i >= 0; i-- {
+		f, err := roundFunction(i, passphrase, exponent, salt, r)
+		if err != nil {
+			return nil, err
+		}
+		l, r = r, xor(l, f)
+	}
+	return append(r, l...), nil
+}
+
+// roundFunction is PBKDF2-HMAC-SHA256 keyed by the round number and passphrase.
+func roundFunction(i int, passphrase string, exponent int, salt, r []byte) ([]byte, error) {
+	password := string(rune(i)) + passphrase
+	iterations := (baseIterationCount << exponent) / roundCount
+	return pbkdf2.Key(sha256.New, password, append(append([]byte(nil), salt...), r...), iterations, len(r))
+}
+
+// cipherSalt binds non-extendable shares to their identifier.
+func cipherSalt(id uint16, extendable bool) []byte {
+	if extendable {
+		return nil
+	}
+	return binary.BigEndian.AppendUint16([]byte(customizationString), id)
+}
+
+func xor(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
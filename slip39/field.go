@@ -0,0 +1,30 @@
+package slip39
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/morizta/go-shamir/internal/bcshamir"
+)
+
+// point is one evaluation of a sharing polynomial.
+type point = bcshamir.Point
+
+// splitSecret shares secret among count participants with the given threshold
+// as SLIP-0039 specifies: the polynomial also passes through a digest share at
+// x = 254 that lets recovery detect a wrong set of shares.
+func splitSecret(threshold, count int, secret []byte) ([]point, error) {
+	return bcshamir.Split(threshold, count, secret)
+}
+
+// recoverSecret interpolates the secret from threshold points and checks the digest.
+func recoverSecret(threshold int, points []point) ([]byte, error) {
+	secret, err := bcshamir.Recover(threshold, points)
+	switch {
+	case errors.Is(err, bcshamir.ErrDigestMismatch):
+		return nil, ErrDigestMismatch
+	case err != nil:
+		return nil, fmt.Errorf("%w: %w", ErrInvalidShares, err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,190 @@
+package slip39
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	radixBits           = 10
+	idBits              = 15
+	iterationExpBits    = 4
+	checksumWords       = 3
+	metadataWords       = 4 + checksumWords // id/exponent, share parameters, checksum
+	minStrengthBits     = 128
+	customizationString = "shamir"
+	extendableString    = "shamir_extendable"
+)
+
+// Share is a decoded SLIP-0039 mnemonic.
+type Share struct {
+	Identifier        uint16 // Random 15-bit identifier shared by all shares of a secret
+	Extendable        bool   // Whether shares can be added later without changing the identifier
+	IterationExponent int    // PBKDF2 cost: 10000 << IterationExponent iterations in total
+	GroupIndex        int    // Index of the group this share belongs to (0-15)
+	GroupThreshold    int    // Number of groups needed to recover the secret
+	GroupCount        int    // Total number of groups
+	MemberIndex       int    // Index of the share within its group (0-15)
+	MemberThreshold   int    // Number of shares of this group needed to recover the group secret
+	Value             []byte // Share value
+}
+
+// Words returns the mnemonic words of the share.
+func (s *Share) Words() []string {
+	var data []int
+	idExp := int(s.Identifier)<<(iterationExpBits+1) | boolInt(s.Extendable)<<iterationExpBits | s.IterationExponent
+	data = append(data, intToIndices(big.NewInt(int64(idExp)), 2)...)
+
+	params := s.GroupIndex
+	params = params<<4 | (s.GroupThreshold - 1)
+	params = params<<4 | (s.GroupCount - 1)
+	params = params<<4 | s.MemberIndex
+	params = params<<4 | (s.MemberThreshold - 1)
+	data = append(data, intToIndices(big.NewInt(int64(params)), 2)...)
+
+	valueWords := (len(s.Value)*8 + radixBits - 1) / radixBits
+	data = append(data, intToIndices(new(big.Int).SetBytes(s.Value), valueWords)...)
+	data = append(data, rs1024CreateChecksum(data, s.Extendable)...)
+
+	words := make([]string, len(data))
+	for i, d := range data {
+		words[i] = wordlist[d]
+	}
+	return words
+}
+
+// Mnemonic returns the share as a space-separated mnemonic.
+func (s *Share) Mnemonic() string {
+	return strings.Join(s.Words(), " ")
+}
+
+// ParseShare decodes and verifies a SLIP-0039 mnemonic. Words may be given in
+// any case and abbreviated to their first four letters.
+func ParseShare(mnemonic string) (*Share, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) < metadataWords+(minStrengthBits+radixBits-1)/radixBits {
+		return nil, fmt.Errorf("%w: too few words", ErrInvalidMnemonic)
+	}
+
+	data := make([]int, len(words))
+	for i, word := range words {
+		index, ok := wordIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("%w %d: %q", ErrUnknownWord, i+1, word)
+		}
+		data[i] = index
+	}
+
+	paddingBits := (radixBits * (len(data) - metadataWords)) % 16
+	if paddingBits > 8 {
+		return nil, fmt.Errorf("%w: invalid length", ErrInvalidMnemonic)
+	}
+
+	idExp := data[0]<<radixBits | data[1]
+	s := &Share{
+		Identifier:        uint16(idExp >> (iterationExpBits + 1)),
+		Extendable:        idExp>>iterationExpBits&1 == 1,
+		IterationExponent: idExp & (1<<iterationExpBits - 1),
+	}
+	if !rs1024VerifyChecksum(data, s.Extendable) {
+		return nil, ErrInvalidChecksum
+	}
+
+	params := data[2]<<radixBits | data[3]
+	s.GroupIndex = params >> 16
+	s.GroupThreshold = params>>12&0xf + 1
+	s.GroupCount = params>>8&0xf + 1
+	s.MemberIndex = params >> 4 & 0xf
+	s.MemberThreshold = params&0xf + 1
+	if s.GroupThreshold > s.GroupCount {
+		return nil, fmt.Errorf("%w: group threshold exceeds group count", ErrInvalidMnemonic)
+	}
+
+	value := indicesToInt(data[4 : len(data)-checksumWords])
+	valueBytes := (radixBits*(len(data)-metadataWords) - paddingBits) / 8
+	if value.BitLen() > valueBytes*8 {
+		return nil, fmt.Errorf("%w: non-zero padding", ErrInvalidMnemonic)
+	}
+	s.Value = value.FillBytes(make([]byte, valueBytes))
+
+	return s, nil
+}
+
+// commonParameters returns the fields every share of one secret must agree on.
+func (s *Share) commonParameters() [5]int {
+	return [5]int{int(s.Identifier), boolInt(s.Extendable), s.IterationExponent, s.GroupThreshold, s.GroupCount}
+}
+
+// intToIndices splits v into n big-endian 10-bit word indices.
+func intToIndices(v *big.Int, n int) []int {
+	out := make([]int, n)
+	mask := big.NewInt(1<<radixBits - 1)
+	t := new(big.Int).Set(v)
+	for i := n - 1; i >= 0; i-- {
+		out[i] = int(new(big.Int).And(t, mask).Int64())
+		t.Rsh(t, radixBits)
+	}
+	return out
+}
+
+// indicesToInt joins big-endian 10-bit word indices into an integer.
+func indicesToInt(indices []int) *big.Int {
+	v := new(big.Int)
+	for _, index := range indices {
+		v.Lsh(v, radixBits)
+		v.Or(v, big.NewInt(int64(index)))
+	}
+	return v
+}
+
+// rs1024Generator holds the generator coefficients of the RS1024 checksum.
+var rs1024Generator = [10]uint32{
+	0xe0e040, 0x1c1c080, 0x3838100, 0x7070200, 0xe0e0009,
+	0x1c0c2412, 0x38086c24, 0x3090fc48, 0x21b1f890, 0x3f3f120,
+}
+
+func rs1024Polymod(values []int) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 20
+		chk = (chk&0xfffff)<<10 ^ uint32(v)
+		for i, g := range rs1024Generator {
+			if (b>>i)&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
+
+// rs1024Customization returns the checksum customization string as word values.
+func rs1024Customization(extendable bool) []int {
+	cs := customizationString
+	if extendable {
+		cs = extendableString
+	}
+	out := make([]int, len(cs))
+	for i := range cs {
+		out[i] = int(cs[i])
+	}
+	return out
+}
+
+func rs1024CreateChecksum(data []int, extendable bool) []int {
+	values := append(rs1024Customization(extendable), data...)
+	values = append(values, 0, 0, 0)
+	polymod := rs1024Polymod(values) ^ 1
+	return []int{int(polymod >> 20 & 1023), int(polymod >> 10 & 1023), int(polymod & 1023)}
+}
+
+func rs1024VerifyChecksum(data []int, extendable bool) bool {
+	return rs1024Polymod(append(rs1024Customization(extendable), data...)) == 1
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
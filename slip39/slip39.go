@@ -0,0 +1,235 @@
+// Package slip39 implements the SLIP-0039 share format used by Trezor and other
+// hardware wallets, so secrets split here can be recovered by SLIP-39 tooling
+// and vice versa.
+//
+// SLIP-0039 is a two-level scheme: the master secret is encrypted with a
+// passphrase, split among groups, and each group's share is split again among
+// its members. Recovery needs the member threshold of shares from each of
+// group-threshold groups. Every share is a mnemonic of words from the SLIP-0039
+// word list with an RS1024 checksum.
+//
+// The scheme uses its own field (GF(256) with the Rijndael polynomial) and
+// digest share, so its shares cannot be mixed with those of the parent package.
+package slip39
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrInvalidMnemonic indicates that a mnemonic is malformed.
+	ErrInvalidMnemonic = errors.New("slip39: invalid mnemonic")
+
+	// ErrUnknownWord indicates that a word is not in the SLIP-0039 word list.
+	ErrUnknownWord = errors.New("slip39: unknown word")
+
+	// ErrInvalidChecksum indicates that a mnemonic's RS1024 checksum does not verify.
+	ErrInvalidChecksum = errors.New("slip39: invalid mnemonic checksum")
+
+	// ErrInvalidShares indicates that the mnemonics do not belong to the same secret
+	// or carry conflicting parameters.
+	ErrInvalidShares = errors.New("slip39: inconsistent set of shares")
+
+	// ErrInsufficientShares indicates that too few groups or members were supplied.
+	ErrInsufficientShares = errors.New("slip39: insufficient shares for recovery")
+
+	// ErrDigestMismatch indicates that the recovered secret failed its digest check,
+	// usually because shares from different splits were mixed.
+	ErrDigestMismatch = errors.New("slip39: invalid digest of the shared secret")
+)
+
+// Group describes how one group's share is split among its members.
+type Group struct {
+	MemberThreshold int // Members needed to recover the group share (1-16)
+	MemberCount     int // Members in the group (1-16); must be 1 if MemberThreshold is 1
+}
+
+// Option configures Split.
+type Option func(*options)
+
+type options struct {
+	iterationExponent int
+	extendable        bool
+}
+
+// WithIterationExponent sets the PBKDF2 cost to 10000 << e iterations (0-15, default 1).
+func WithIterationExponent(e int) Option {
+	return func(o *options) { o.iterationExponent = e }
+}
+
+// WithExtendable sets the extendable flag (default true). Extendable shares do
+// not bind the encryption to the identifier, so more shares can be issued later.
+func WithExtendable(extendable bool) Option {
+	return func(o *options) { o.extendable = extendable }
+}
+
+// Split encrypts masterSecret with passphrase and splits it into SLIP-0039
+// mnemonics: one slice per group, one mnemonic per member.
+//
+// The master secret must be at least 16 bytes and of even length. The
+// passphrase may be empty and must consist of printable ASCII characters.
+func Split(masterSecret []byte, passphrase string, groupThreshold int, groups []Group, opts ...Option) ([][]string, error) {
+	o := &options{iterationExponent: 1, extendable: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if len(masterSecret)*8 < minStrengthBits || len(masterSecret)%2 != 0 {
+		return nil, fmt.Errorf("slip39: master secret must be at least %d bytes and of even length", minStrengthBits/8)
+	}
+	if err := validatePassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	if o.iterationExponent < 0 || o.iterationExponent >= 1<<iterationExpBits {
+		return nil, fmt.Errorf("slip39: iteration exponent must be between 0 and %d", 1<<iterationExpBits-1)
+	}
+	if len(groups) < 1 || len(groups) > 16 {
+		return nil, fmt.Errorf("slip39: group count must be between 1 and 16")
+	}
+	if groupThreshold < 1 || groupThreshold > len(groups) {
+		return nil, fmt.Errorf("slip39: group threshold must be between 1 and the group count")
+	}
+	for i, g := range groups {
+		if g.MemberThreshold < 1 || g.MemberThreshold > g.MemberCount || g.MemberCount > 16 {
+			return nil, fmt.Errorf("slip39: group %d: member threshold must be between 1 and the member count (at most 16)", i)
+		}
+		if g.MemberThreshold == 1 && g.MemberCount > 1 {
+			return nil, fmt.Errorf("slip39: group %d: a member threshold of 1 requires a single member", i)
+		}
+	}
+
+	var idBytes [2]byte
+	if _, err := rand.Read(idBytes[:]); err != nil {
+		return nil, err
+	}
+	id := binary.BigEndian.Uint16(idBytes[:]) & (1<<idBits - 1)
+
+	encrypted, err := encrypt(masterSecret, passphrase, o.iterationExponent, id, o.extendable)
+	if err != nil {
+		return nil, err
+	}
+	groupPoints, err := splitSecret(groupThreshold, len(groups), encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([][]string, len(groups))
+	for gi, g := range groups {
+		members, err := splitSecret(g.MemberThreshold, g.MemberCount, groupPoints[gi].Y)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			s := Share{
+				Identifier:        id,
+				Extendable:        o.extendable,
+				IterationExponent: o.iterationExponent,
+				GroupIndex:        gi,
+				GroupThreshold:    groupThreshold,
+				GroupCount:        len(groups),
+				MemberIndex:       int(m.X),
+				MemberThreshold:   g.MemberThreshold,
+				Value:             m.Y,
+			}
+			out[gi] = append(out[gi], s.Mnemonic())
+		}
+	}
+	return out, nil
+}
+
+// Combine recovers and decrypts the master secret from SLIP-0039 mnemonics.
+// Mnemonics may be supplied in any order and may include more than the
+// required number of groups or members. A wrong passphrase cannot be detected
+// and yields a different secret, as SLIP-0039 intends.
+func Combine(mnemonics []string, passphrase string) ([]byte, error) {
+	if err := validatePassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	if len(mnemonics) == 0 {
+		return nil, ErrInsufficientShares
+	}
+
+	var first *Share
+	groups := make(map[int]map[int]*Share)
+	for i, mnemonic := range mnemonics {
+		s, err := ParseShare(mnemonic)
+		if err != nil {
+			return nil, fmt.Errorf("mnemonic %d: %w", i+1, err)
+		}
+		if first == nil {
+			first = s
+		}
+		if s.commonParameters() != first.commonParameters() || len(s.Value) != len(first.Value) {
+			return nil, fmt.Errorf("mnemonic %d: %w", i+1, ErrInvalidShares)
+		}
+
+		members := groups[s.GroupIndex]
+		if members == nil {
+			members = make(map[int]*Share)
+			groups[s.GroupIndex] = members
+		}
+		for _, other := range members {
+			if other.MemberThreshold != s.MemberThreshold {
+				return nil, fmt.Errorf("mnemonic %d: %w: member thresholds differ within group %d", i+1, ErrInvalidShares, s.GroupIndex)
+			}
+		}
+		if dup, ok := members[s.MemberIndex]; ok && string(dup.Value) != string(s.Value) {
+			return nil, fmt.Errorf("mnemonic %d: %w: conflicting member %d in group %d", i+1, ErrInvalidShares, s.MemberIndex, s.GroupIndex)
+		}
+		members[s.MemberIndex] = s
+	}
+
+	// Recover the share of every group with enough members, in index order.
+	groupIndices := make([]int, 0, len(groups))
+	for gi := range groups {
+		groupIndices = append(groupIndices, gi)
+	}
+	sort.Ints(groupIndices)
+
+	var groupPoints []point
+	for _, gi := range groupIndices {
+		members := groups[gi]
+		var points []point
+		var threshold int
+		for _, s := range members {
+			threshold = s.MemberThreshold
+			points = append(points, point{X: byte(s.MemberIndex), Y: s.Value})
+		}
+		if len(points) < threshold {
+			continue
+		}
+		sort.Slice(points, func(a, b int) bool { return points[a].X < points[b].X })
+
+		secret, err := recoverSecret(threshold, points[:threshold])
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %w", gi, err)
+		}
+		groupPoints = append(groupPoints, point{X: byte(gi), Y: secret})
+		if len(groupPoints) == first.GroupThreshold {
+			break
+		}
+	}
+	if len(groupPoints) < first.GroupThreshold {
+		return nil, ErrInsufficientShares
+	}
+
+	encrypted, err := recoverSecret(first.GroupThreshold, groupPoints)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(encrypted, passphrase, first.IterationExponent, first.Identifier, first.Extendable)
+}
+
+// validatePassphrase checks that the passphrase is printable ASCII, as SLIP-0039 requires.
+func validatePassphrase(passphrase string) error {
+	for i := 0; i < len(passphrase); i++ {
+		if passphrase[i] < 32 || passphrase[i] > 126 {
+			return fmt.Errorf("slip39: passphrase must consist of printable ASCII characters")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package slip39
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Test vectors from the SLIP-0039 specification (passphrase "TREZOR").
+var vectors = []struct {
+	name      string
+	mnemonics []string
+	secret    string
+}{
+	{
+		"valid mnemonic without sharing",
+		[]string{"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard"},
+		"bb54aac4b89dc868ba37d9cc21b2cece",
+	},
+	{
+		"basic sharing 2-of-3",
+		[]string{
+			"shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed",
+			"shadow pistol academic acid actress prayer class unknown daughter sweater depict flip twice unkind craft early superior advocate guest smoking",
+		},
+		"b43ceb7e57a0ea8766221624d01b0864",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			got, err := Combine(v.mnemonics, "TREZOR")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(got) != v.secret {
+				t.Fatalf("expected %s, got %x", v.secret, got)
+			}
+
+			// Re-encoding a parsed share must reproduce the mnemonic exactly.
+			for _, m := range v.mnemonics {
+				s, err := ParseShare(m)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if s.Mnemonic() != m {
+					t.Fatalf("re-encoded mnemonic differs:\n got %s\nwant %s", s.Mnemonic(), m)
+				}
+			}
+		})
+	}
+}
+
+func TestWordlist(t *testing.T) {
+	if !sort.StringsAreSorted(wordlist[:]) {
+		t.Error("word list is not sorted")
+	}
+	prefixes := make(map[string]bool, len(wordlist))
+	for _, word := range wordlist {
+		if len(word) < 4 || len(word) > 8 {
+			t.Errorf("word %q has invalid length", word)
+		}
+		if prefixes[word[:4]] {
+			t.Errorf("prefix %q is ambiguous", word[:4])
+		}
+		prefixes[word[:4]] = true
+	}
+}
+
+func TestSplitCombine(t *testing.T) {
+	secret := bytes.Repeat([]byte{0xa5, 0x3c}, 16)
+	groups := []Group{{1, 1}, {2, 3}, {3, 5}}
+
+	for _, extendable := range []bool{true, false} {
+		mnemonics, err := Split(secret, "passphrase", 2, groups,
+			WithExtendable(extendable), WithIterationExponent(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mnemonics) != 3 || len(mnemonics[2]) != 5 {
+			t.Fatalf("unexpected share layout %d groups", len(mnemonics))
+		}
+
+		// Group 0 alone plus two members of group 1, in arbitrary order.
+		recovered, err := Combine([]string{mnemonics[1][2], mnemonics[0][0], mnemonics[1][0]}, "passphrase")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(recovered, secret) {
+			t.Fatal("recovered secret mismatch")
+		}
+
+		// Extra members and an incomplete group are tolerated.
+		all := append(append([]string{}, mnemonics[2][:4]...), mnemonics[1][1], mnemonics[0][0])
+		if recovered, err = Combine(all, "passphrase"); err != nil || !bytes.Equal(recovered, secret) {
+			t.Fatalf("recovery with surplus shares failed: %v", err)
+		}
+
+		// A wrong passphrase silently yields a different secret.
+		if other, err := Combine([]string{mnemonics[0][0], mnemonics[1][0], mnemonics[1][1]}, "wrong"); err != nil || bytes.Equal(other, secret) {
+			t.Fatalf("expected a different secret for a wrong passphrase, err %v", err)
+		}
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	secret := make([]byte, 16)
+	a, err := Split(secret, "", 1, []Group{{2, 3}}, WithIterationExponent(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Split(secret, "", 1, []Group{{2, 3}}, WithIterationExponent(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Combine(a[0][:1], ""); !errors.Is(err, ErrInsufficientShares) {
+		t.Errorf("expected ErrInsufficientShares, got %v", err)
+	}
+	if _, err := Combine([]string{a[0][0], b[0][1]}, ""); !errors.Is(err, ErrInvalidShares) {
+		t.Errorf("expected ErrInvalidShares for mixed splits, got %v", err)
+	}
+
+	words := strings.Fields(a[0][0])
+	words[5] = wordlist[(wordIndex[words[5]]+1)%len(wordlist)]
+	if _, err := ParseShare(strings.Join(words, " ")); !errors.Is(err, ErrInvalidChecksum) {
+		t.Errorf("expected ErrInvalidChecksum, got %v", err)
+	}
+	words[5] = "bitcoin"
+	if _, err := ParseShare(strings.Join(words, " ")); !errors.Is(err, ErrUnknownWord) {
+		t.Errorf("expected ErrUnknownWord, got %v", err)
+	}
+}
+
+func TestSplitValidation(t *testing.T) {
+	secret := make([]byte, 16)
+	tests := []struct {
+		name   string
+		secret []byte
+		pass   string
+		gt     int
+		groups []Group
+	}{
+		{"short secret", make([]byte, 14), "", 1, []Group{{2, 3}}},
+		{"odd length", make([]byte, 17), "", 1, []Group{{2, 3}}},
+		{"non-ascii passphrase", secret, "pässword", 1, []Group{{2, 3}}},
+		{"group threshold too high", secret, "", 2, []Group{{2, 3}}},
+		{"threshold 1 with several members", secret, "", 1, []Group{{1, 2}}},
+		{"too many members", secret, "", 1, []Group{{2, 17}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Split(tt.secret, tt.pass, tt.gt, tt.groups); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
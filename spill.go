@@ -0,0 +1,236 @@
+package shamir
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+// WithSpillDir sets the directory SplitSpilled writes its encrypted temporary
+// files to. The default is os.TempDir.
+func WithSpillDir(dir string) Option {
+	return func(o *options) { o.spillDir = dir }
+}
+
+// SpilledShares holds shares that SplitSpilled wrote to disk instead of
+// memory. Each share lives in its own temporary file, encrypted under a key
+// that exists only in this value, so the files are useless once Close has run
+// or the process has exited.
+type SpilledShares struct {
+	mu        sync.Mutex
+	xCoords   []byte
+	files     []*os.File
+	aead      cipher.AEAD
+	key       []byte
+	secretLen int
+	integrity bool
+}
+
+// SplitSpilled splits a secret whose shares would not fit in memory. The secret
+// is processed in windows; each window's share bytes are encrypted with
+// AES-256-GCM under an ephemeral key and appended to one temporary file per
+// share, so memory use is bounded by threshold+parts windows (1 MiB each)
+// whatever the secret size.
+//
+// WithParts, WithThreshold, WithRand, WithParallelism, WithIntegrity and
+// WithSpillDir are honoured; purpose binding is not supported. The shares read
+// back are identical to those SplitWithOptions would produce with
+// WithStrategy(StrategyStreaming) and the same randomness. Call Close to remove
+// the temporary files.
+func SplitSpilled(secret []byte, opts ...Option) (*SpilledShares, error) {
+	o := newOptions(opts)
+	if o.purpose != "" {
+		return nil, errors.New("shamir: purpose binding is not supported for spilled shares")
+	}
+	xCoords, err := o.coordinates(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SpilledShares{
+		xCoords:   append([]byte(nil), xCoords...),
+		files:     make([]*os.File, len(xCoords)),
+		key:       make([]byte, 32),
+		secretLen: len(secret),
+		integrity: o.integrity,
+	}
+	if _, err := rand.Read(s.key); err != nil {
+		return nil, fmt.Errorf("shamir: failed to generate spill key: %w", err)
+	}
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+	if s.aead, err = cipher.NewGCM(block); err != nil {
+		return nil, err
+	}
+
+	if err := s.spill(secret, o); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// spill generates the shares window by window and writes them encrypted.
+func (s *SpilledShares) spill(secret []byte, o *options) error {
+	for i := range s.files {
+		f, err := os.CreateTemp(o.spillDir, "shamir-spill-*")
+		if err != nil {
+			return fmt.Errorf("shamir: failed to create spill file: %w", err)
+		}
+		s.files[i] = f
+	}
+
+	_, workers := o.engine().resolve(len(secret))
+	window := engine{strategy: StrategyParallel, parallelism: workers, entropyTimeout: o.entropyTimeout}
+	sealed := make([]byte, 0, strategyWindowSize+s.aead.Overhead())
+	for w, start := 0, 0; start < len(secret); w, start = w+1, start+strategyWindowSize {
+		end := min(start+strategyWindowSize, len(secret))
+		chunk, err := splitAt(secret[start:end], s.xCoords, o.threshold, o.rand, window)
+		if err != nil {
+			return err
+		}
+		for i, c := range chunk {
+			sealed = s.aead.Seal(sealed[:0], s.nonce(i, w), c[ShareOverhead:], nil)
+			secureZeroBytes(c)
+			if _, err := s.files[i].Write(sealed); err != nil {
+				for _, c := range chunk {
+					secureZeroBytes(c)
+				}
+				return fmt.Errorf("shamir: failed to write spill file: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// nonce derives the GCM nonce for a share's window, so windows cannot be
+// reordered or moved between share files undetected.
+func (s *SpilledShares) nonce(share, window int) []byte {
+	nonce := make([]byte, s.aead.NonceSize())
+	binary.BigEndian.PutUint32(nonce, uint32(share))
+	binary.BigEndian.PutUint64(nonce[4:], uint64(window))
+	return nonce
+}
+
+// Len returns the number of shares.
+func (s *SpilledShares) Len() int {
+	return len(s.xCoords)
+}
+
+// ShareSize returns the size in bytes of each share as read from Open.
+func (s *SpilledShares) ShareSize() int64 {
+	n := int64(s.secretLen) + ShareOverhead
+	if s.integrity {
+		n += 4
+	}
+	return n
+}
+
+// Open returns a reader for share i in the format Split (or SplitWithIntegrity)
+// produces. Readers decrypt one window at a time and may be used concurrently;
+// a reader fails with ErrAuthenticationFailed if a spill file was modified.
+func (s *SpilledShares) Open(i int) (io.Reader, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.files == nil {
+		return nil, errors.New("shamir: spilled shares are closed")
+	}
+	if i < 0 || i >= len(s.files) {
+		return nil, fmt.Errorf("shamir: share %d out of range [0, %d)", i, len(s.files))
+	}
+	return &spillReader{s: s, share: i, pending: []byte{s.xCoords[i]}}, nil
+}
+
+// Close removes the temporary files and wipes the key.
+func (s *SpilledShares) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, f := range s.files {
+		if f == nil {
+			continue
+		}
+		errs = append(errs, f.Close(), os.Remove(f.Name()))
+	}
+	s.files = nil
+	secureZeroBytes(s.key)
+	return errors.Join(errs...)
+}
+
+// spillReader decrypts one spilled share window by window.
+type spillReader struct {
+	s       *SpilledShares
+	share   int
+	window  int
+	pending []byte // Decrypted bytes not yet returned
+	buf     []byte // Scratch space for ciphertext and plaintext
+	crc     uint32
+	done    bool
+}
+
+func (r *spillReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done {
+			secureZeroBytes(r.buf)
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// next decrypts the following window, or produces the checksum trailer once
+// every window has been read.
+func (r *spillReader) next() error {
+	s := r.s
+	start := r.window * strategyWindowSize
+	if start >= s.secretLen {
+		r.done = true
+		if s.integrity {
+			r.pending = binary.LittleEndian.AppendUint32(nil, r.crc)
+		}
+		return nil
+	}
+
+	plainLen := min(strategyWindowSize, s.secretLen-start)
+	sealedLen := plainLen + s.aead.Overhead()
+	offset := int64(r.window) * int64(strategyWindowSize+s.aead.Overhead())
+	if cap(r.buf) < sealedLen {
+		r.buf = make([]byte, sealedLen)
+	}
+
+	s.mu.Lock()
+	if s.files == nil {
+		s.mu.Unlock()
+		return errors.New("shamir: spilled shares are closed")
+	}
+	_, err := s.files[r.share].ReadAt(r.buf[:sealedLen], offset)
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("shamir: failed to read spill file: %w", err)
+	}
+
+	plain, err := s.aead.Open(r.buf[:0], s.nonce(r.share, r.window), r.buf[:sealedLen], nil)
+	if err != nil {
+		return fmt.Errorf("%w: spill file for share %d was modified", ErrAuthenticationFailed, r.share)
+	}
+	r.crc = crc32.Update(r.crc, crc32.IEEETable, plain)
+	r.pending = plain
+	r.window++
+	return nil
+}
@@ -0,0 +1,162 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readSpilled(t *testing.T, s *SpilledShares) [][]byte {
+	t.Helper()
+	shares := make([][]byte, s.Len())
+	for i := range shares {
+		r, err := s.Open(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if shares[i], err = io.ReadAll(r); err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(shares[i])) != s.ShareSize() {
+			t.Fatalf("share %d is %d bytes, ShareSize reports %d", i, len(shares[i]), s.ShareSize())
+		}
+	}
+	return shares
+}
+
+func TestSplitSpilled(t *testing.T) {
+	for _, size := range []int{1, 1000, strategyWindowSize, 2*strategyWindowSize + 33} {
+		secret := make([]byte, size)
+		rand.New(rand.NewSource(int64(size))).Read(secret)
+		dir := t.TempDir()
+
+		spilled, err := SplitSpilled(secret, WithParts(4), WithThreshold(3), WithSpillDir(dir),
+			WithRand(rand.New(rand.NewSource(7))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares := readSpilled(t, spilled)
+
+		// Spilling must not change the shares themselves
+		want, err := SplitWithOptions(secret, WithParts(4), WithThreshold(3),
+			WithStrategy(StrategyStreaming), WithRand(rand.New(rand.NewSource(7))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range want {
+			if !bytes.Equal(shares[i], want[i]) {
+				t.Fatalf("size %d: share %d differs from SplitWithOptions", size, i)
+			}
+		}
+
+		reconstructed, err := Combine(shares[1:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(reconstructed, secret) {
+			t.Fatalf("size %d: reconstruction failed", size)
+		}
+
+		if err := spilled.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("size %d: %d spill files left after Close", size, len(entries))
+		}
+		if _, err := spilled.Open(0); err == nil {
+			t.Error("Open after Close should fail")
+		}
+	}
+}
+
+func TestSplitSpilledIntegrity(t *testing.T) {
+	secret := []byte("spilled with checksums")
+	spilled, err := SplitSpilled(secret, WithParts(3), WithThreshold(2), WithIntegrity(true), WithSpillDir(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spilled.Close()
+
+	shares := readSpilled(t, spilled)
+	for _, share := range shares {
+		if err := VerifyIntegrity(share); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reconstructed, err := CombineWithIntegrity(shares[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("reconstruction failed")
+	}
+}
+
+func TestSplitSpilledEncrypted(t *testing.T) {
+	secret := bytes.Repeat([]byte("confidential "), 1000)
+	dir := t.TempDir()
+	spilled, err := SplitSpilled(secret, WithParts(2), WithThreshold(2), WithSpillDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spilled.Close()
+	shares := readSpilled(t, spilled)
+
+	files, err := filepath.Glob(filepath.Join(dir, "shamir-spill-*"))
+	if err != nil || len(files) != 2 {
+		t.Fatalf("expected 2 spill files, got %v (%v)", files, err)
+	}
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, share := range shares {
+			if bytes.Contains(data, share[1:65]) {
+				t.Fatal("spill file contains plaintext share bytes")
+			}
+		}
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		// Rewrite the file with a flipped bit through a fresh handle
+		var target string
+		for _, name := range files {
+			if name == spilled.files[0].Name() {
+				target = name
+			}
+		}
+		data, _ := os.ReadFile(target)
+		data[10] ^= 1
+		if err := os.WriteFile(target, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := spilled.Open(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(r); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Errorf("expected ErrAuthenticationFailed, got %v", err)
+		}
+	})
+}
+
+func TestSplitSpilledValidation(t *testing.T) {
+	if _, err := SplitSpilled(nil, WithParts(3), WithThreshold(2)); !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("expected ErrEmptySecret, got %v", err)
+	}
+	if _, err := SplitSpilled([]byte("x"), WithParts(3), WithThreshold(4)); err == nil {
+		t.Error("expected error for threshold above parts")
+	}
+	if _, err := SplitSpilled([]byte("x"), WithParts(3), WithThreshold(2), WithPurpose("p")); err == nil {
+		t.Error("expected error for purpose binding")
+	}
+	if _, err := SplitSpilled([]byte("x"), WithParts(3), WithThreshold(2), WithSpillDir("/nonexistent/dir")); err == nil {
+		t.Error("expected error for missing spill directory")
+	}
+}
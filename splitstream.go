@@ -0,0 +1,266 @@
+package shamir
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// Framed share stream layout, as written by SplitStream:
+//
+//	offset  size  field
+//	0       3     magic 0x00 'S' 'S'
+//	3       1     format version (1)
+//	4       1     threshold
+//	5       1     x-coordinate
+//	6       4     chunk size (big-endian)
+//
+// followed by frames, each holding the y-values of one secret chunk:
+//
+//	0       4     sequence number, from 0 (big-endian)
+//	4       4     payload length n, at most the chunk size (big-endian)
+//	8       n     payload
+//	8+n     4     CRC32 (IEEE) of the sequence number, length and payload
+//
+// A frame with length 0 ends the stream, so truncation is detected.
+
+const (
+	streamFormatVersion = 1
+	streamHeaderSize    = 10
+	frameHeaderSize     = 8
+
+	// maxStreamChunkSize bounds the chunk size accepted from a stream header,
+	// and therefore the memory CombineStream allocates per share.
+	maxStreamChunkSize = 16 << 20
+)
+
+// streamMagic identifies a framed share stream.
+var streamMagic = [3]byte{0x00, 'S', 'S'}
+
+// SplitStream splits the secret read from src into one framed share stream per
+// writer in dsts, processing chunkSize bytes at a time (32 KiB if chunkSize is
+// zero or negative), so memory stays bounded however large the input. Each
+// chunk uses fresh randomness. Streams are reassembled with CombineStream;
+// framing lets it detect truncated, reordered or corrupted chunks.
+//
+// The x-coordinate of dsts[i] is i+1. Writers are not closed.
+func SplitStream(src io.Reader, dsts []io.Writer, threshold int, chunkSize int) error {
+	if src == nil {
+		return ErrEmptySecret
+	}
+	if err := validateSplitParams([]byte{0}, len(dsts), threshold); err != nil {
+		return err
+	}
+	if chunkSize <= 0 {
+		chunkSize = streamChunkSize
+	}
+	if chunkSize > maxStreamChunkSize {
+		return NewValidationError("chunkSize", chunkSize, "shamir: chunk size must not exceed 16 MiB")
+	}
+
+	xCoords := make([]byte, len(dsts))
+	for i, dst := range dsts {
+		if dst == nil {
+			return fmt.Errorf("shamir: share writer %d is nil", i)
+		}
+		xCoords[i] = byte(i + 1)
+	}
+	return splitFramed(src, dsts, xCoords, threshold, chunkSize, rand.Reader, engine{})
+}
+
+// splitFramed implements SplitStream for validated parameters, evaluating
+// dsts[i]'s share at xCoords[i] with randomness from rng.
+func splitFramed(src io.Reader, dsts []io.Writer, xCoords []byte, threshold, chunkSize int, rng io.Reader, eng engine) error {
+	writers := make([]*bufio.Writer, len(dsts))
+	for i, dst := range dsts {
+		writers[i] = bufio.NewWriter(dst)
+
+		header := append(streamMagic[:], streamFormatVersion, byte(threshold), xCoords[i])
+		header = binary.BigEndian.AppendUint32(header, uint32(chunkSize))
+		writers[i].Write(header)
+	}
+
+	chunk := make([]byte, chunkSize)
+	defer secureZeroBytes(chunk)
+	frame := make([]byte, 0, frameHeaderSize+chunkSize+4)
+	var seq uint32
+	var total int64
+	for {
+		n, err := io.ReadFull(src, chunk)
+		if n > 0 {
+			shares, splitErr := splitAt(chunk[:n], xCoords, threshold, rng, eng)
+			if splitErr != nil {
+				return splitErr
+			}
+			for i, share := range shares {
+				frame = appendFrame(frame[:0], seq, share[ShareOverhead:])
+				secureZeroBytes(share)
+				if _, err := writers[i].Write(frame); err != nil {
+					return fmt.Errorf("shamir: failed to write share %d: %w", i, err)
+				}
+			}
+			seq++
+			total += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("shamir: failed to read secret: %w", err)
+		}
+	}
+	if total == 0 {
+		return ErrEmptySecret
+	}
+
+	frame = appendFrame(frame[:0], seq, nil)
+	for i, w := range writers {
+		w.Write(frame)
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("shamir: failed to write share %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// appendFrame appends one frame carrying payload to dst.
+func appendFrame(dst []byte, seq uint32, payload []byte) []byte {
+	start := len(dst)
+	dst = binary.BigEndian.AppendUint32(dst, seq)
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(payload)))
+	dst = append(dst, payload...)
+	return binary.BigEndian.AppendUint32(dst, crc32.ChecksumIEEE(dst[start:]))
+}
+
+// CombineStream reassembles the secret from framed share streams produced by
+// SplitStream and writes it to dst chunk by chunk. At least threshold streams
+// are required. It returns ErrIntegrityCheckFailed for corrupted frames and
+// ErrInvalidStream for malformed, truncated or mismatched streams; dst may
+// have received earlier chunks by then.
+func CombineStream(dst io.Writer, srcs []io.Reader) error {
+	return combineFramed(dst, srcs, engine{})
+}
+
+// combineFramed implements CombineStream, applying the per-call policy hooks
+// and context in eng.
+func combineFramed(dst io.Writer, srcs []io.Reader, eng engine) error {
+	if srcs == nil {
+		return ErrNilShares
+	}
+	if len(srcs) < 2 {
+		return ErrTooFewParts
+	}
+
+	readers := make([]*bufio.Reader, len(srcs))
+	xCoords := make([]byte, len(srcs))
+	var threshold, chunkSize int
+	for i, src := range srcs {
+		readers[i] = bufio.NewReader(src)
+		var header [streamHeaderSize]byte
+		if _, err := io.ReadFull(readers[i], header[:]); err != nil {
+			return fmt.Errorf("%w: share %d: header: %v", ErrInvalidStream, i, err)
+		}
+		if [3]byte(header[:3]) != streamMagic {
+			return fmt.Errorf("%w: share %d: bad magic", ErrInvalidStream, i)
+		}
+		if header[3] != streamFormatVersion {
+			return fmt.Errorf("%w: version %d", ErrUnsupportedVersion, header[3])
+		}
+		t, x, size := int(header[4]), header[5], int(binary.BigEndian.Uint32(header[6:]))
+		if x == 0 || t < 2 || size == 0 || size > maxStreamChunkSize {
+			return fmt.Errorf("%w: share %d: bad header", ErrInvalidStream, i)
+		}
+		if i == 0 {
+			threshold, chunkSize = t, size
+		} else if t != threshold || size != chunkSize {
+			return fmt.Errorf("%w: share %d", ErrMismatchedShares, i)
+		}
+		for _, seen := range xCoords[:i] {
+			if seen == x {
+				return ErrDuplicatePart
+			}
+		}
+		xCoords[i] = x
+	}
+	if len(srcs) < threshold {
+		return fmt.Errorf("%w: %d streams, threshold is %d", ErrInsufficientShares, len(srcs), threshold)
+	}
+
+	meta := CombineMetadata{Shares: len(srcs), Indices: xCoords, SecretSize: -1, Threshold: threshold, Context: eng.ctx}
+	if err := checkPolicy(meta, eng.hooks); err != nil {
+		return err
+	}
+
+	weights := lagrangeBasis(xCoords, 0)
+	frames := make([][]byte, len(srcs))
+	for i := range frames {
+		frames[i] = make([]byte, frameHeaderSize+chunkSize+4)
+	}
+	out := make([]byte, chunkSize)
+	defer func() {
+		for _, f := range frames {
+			secureZeroBytes(f)
+		}
+		secureZeroBytes(out)
+	}()
+
+	for seq := uint32(0); ; seq++ {
+		n := -1
+		for i, r := range readers {
+			payload, err := readFrame(r, frames[i], seq, chunkSize)
+			if err != nil {
+				return fmt.Errorf("share %d, chunk %d: %w", i, seq, err)
+			}
+			if n >= 0 && len(payload) != n {
+				return fmt.Errorf("%w: chunk %d", ErrDifferentLengths, seq)
+			}
+			n = len(payload)
+		}
+		if n == 0 {
+			return nil
+		}
+
+		clear(out[:n])
+		for i, f := range frames {
+			gfMulAddSlice(out[:n], f[frameHeaderSize:frameHeaderSize+n], weights[i])
+		}
+		if _, err := dst.Write(out[:n]); err != nil {
+			return err
+		}
+	}
+}
+
+// readFrame reads the frame with the expected sequence number into buf and
+// returns its payload.
+func readFrame(r io.Reader, buf []byte, seq uint32, chunkSize int) ([]byte, error) {
+	if _, err := io.ReadFull(r, buf[:frameHeaderSize]); err != nil {
+		return nil, truncated(err)
+	}
+	if got := binary.BigEndian.Uint32(buf); got != seq {
+		return nil, fmt.Errorf("%w: sequence %d, want %d", ErrInvalidStream, got, seq)
+	}
+	n := int(binary.BigEndian.Uint32(buf[4:]))
+	if n > chunkSize {
+		return nil, fmt.Errorf("%w: frame length %d exceeds chunk size", ErrInvalidStream, n)
+	}
+	end := frameHeaderSize + n
+	if _, err := io.ReadFull(r, buf[frameHeaderSize:end+4]); err != nil {
+		return nil, truncated(err)
+	}
+	if crc32.ChecksumIEEE(buf[:end]) != binary.BigEndian.Uint32(buf[end:]) {
+		return nil, ErrIntegrityCheckFailed
+	}
+	return buf[frameHeaderSize:end], nil
+}
+
+// truncated reports an unexpected end of stream as ErrInvalidStream.
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: stream truncated", ErrInvalidStream)
+	}
+	return err
+}
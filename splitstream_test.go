@@ -0,0 +1,127 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func splitToBuffers(t *testing.T, secret []byte, parts, threshold, chunkSize int) []*bytes.Buffer {
+	t.Helper()
+	bufs := make([]*bytes.Buffer, parts)
+	dsts := make([]io.Writer, parts)
+	for i := range bufs {
+		bufs[i] = new(bytes.Buffer)
+		dsts[i] = bufs[i]
+	}
+	if err := SplitStream(bytes.NewReader(secret), dsts, threshold, chunkSize); err != nil {
+		t.Fatal(err)
+	}
+	return bufs
+}
+
+func streamReaders(bufs ...[]byte) []io.Reader {
+	readers := make([]io.Reader, len(bufs))
+	for i, b := range bufs {
+		readers[i] = bytes.NewReader(b)
+	}
+	return readers
+}
+
+func TestSplitCombineStream(t *testing.T) {
+	for _, size := range []int{1, 100, 4096, 10000} {
+		for _, chunkSize := range []int{0, 1, 100, 4096} {
+			secret := make([]byte, size)
+			rand.New(rand.NewSource(int64(size))).Read(secret)
+			bufs := splitToBuffers(t, secret, 5, 3, chunkSize)
+
+			var out bytes.Buffer
+			if err := CombineStream(&out, streamReaders(bufs[4].Bytes(), bufs[0].Bytes(), bufs[2].Bytes())); err != nil {
+				t.Fatalf("size %d, chunk %d: %v", size, chunkSize, err)
+			}
+			if !bytes.Equal(out.Bytes(), secret) {
+				t.Fatalf("size %d, chunk %d: reconstruction failed", size, chunkSize)
+			}
+		}
+	}
+}
+
+func TestCombineStreamRejects(t *testing.T) {
+	secret := bytes.Repeat([]byte("backup archive "), 50)
+	bufs := splitToBuffers(t, secret, 3, 2, 64)
+	a, b := bufs[0].Bytes(), bufs[1].Bytes()
+
+	tests := []struct {
+		name string
+		srcs []io.Reader
+		want error
+	}{
+		{"truncated", streamReaders(a[:len(a)-20], b), ErrInvalidStream},
+		{"missing terminator", streamReaders(a[:len(a)-12], b[:len(b)-12]), ErrInvalidStream},
+		{"corrupted payload", streamReaders(flipByte(a, streamHeaderSize+frameHeaderSize+3), b), ErrIntegrityCheckFailed},
+		{"reordered frames", streamReaders(swapFrames(a, 64), b), ErrInvalidStream},
+		{"bad magic", streamReaders(flipByte(a, 1), b), ErrInvalidStream},
+		{"duplicate", streamReaders(a, a), ErrDuplicatePart},
+		{"below threshold", streamReaders(a), ErrTooFewParts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CombineStream(io.Discard, tt.srcs)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("mismatched chunk size", func(t *testing.T) {
+		other := splitToBuffers(t, secret, 3, 2, 32)
+		err := CombineStream(io.Discard, streamReaders(a, other[1].Bytes()))
+		if !errors.Is(err, ErrMismatchedShares) {
+			t.Errorf("expected ErrMismatchedShares, got %v", err)
+		}
+	})
+
+	t.Run("insufficient", func(t *testing.T) {
+		high := splitToBuffers(t, secret, 4, 3, 64)
+		err := CombineStream(io.Discard, streamReaders(high[0].Bytes(), high[1].Bytes()))
+		if !errors.Is(err, ErrInsufficientShares) {
+			t.Errorf("expected ErrInsufficientShares, got %v", err)
+		}
+	})
+}
+
+func TestSplitStreamValidation(t *testing.T) {
+	dsts := []io.Writer{io.Discard, io.Discard, io.Discard}
+	if err := SplitStream(bytes.NewReader(nil), dsts, 2, 0); err != ErrEmptySecret {
+		t.Errorf("expected ErrEmptySecret, got %v", err)
+	}
+	if err := SplitStream(bytes.NewReader([]byte("x")), dsts, 4, 0); err == nil {
+		t.Error("expected error for threshold above parts")
+	}
+	if err := SplitStream(bytes.NewReader([]byte("x")), dsts, 2, maxStreamChunkSize+1); err == nil {
+		t.Error("expected error for oversized chunks")
+	}
+	if err := SplitStream(bytes.NewReader([]byte("x")), []io.Writer{io.Discard, nil}, 2, 0); err == nil {
+		t.Error("expected error for nil writer")
+	}
+}
+
+func flipByte(b []byte, i int) []byte {
+	out := append([]byte(nil), b...)
+	out[i] ^= 0x40
+	return out
+}
+
+// swapFrames exchanges the first two full frames of a stream.
+func swapFrames(b []byte, chunkSize int) []byte {
+	frameLen := frameHeaderSize + chunkSize + 4
+	out := append([]byte(nil), b...)
+	first := out[streamHeaderSize : streamHeaderSize+frameLen]
+	second := out[streamHeaderSize+frameLen : streamHeaderSize+2*frameLen]
+	tmp := append([]byte(nil), first...)
+	copy(first, second)
+	copy(second, tmp)
+	return out
+}
@@ -0,0 +1,246 @@
+// Package sskr implements Blockchain Commons' Sharded Secret Key
+// Reconstruction (SSKR, BCR-2020-011), the share format understood by
+// airgapped signers such as Keystone and SeedSigner, and its crypto-sskr UR
+// encoding for transfer over (animated) QR codes.
+//
+// Like SLIP-0039, SSKR is a two-level scheme: the secret is split among
+// groups, and each group's share is split again among its members. Recovery
+// needs the member threshold of shares from each of group-threshold groups.
+// SSKR uses the SLIP-0039 field and digest share but no passphrase or word
+// list; a share is a 5-byte header followed by the share value:
+//
+//	[2 bytes] identifier, common to every share of a split
+//	[1 byte]  group threshold - 1 (high nibble), group count - 1 (low nibble)
+//	[1 byte]  group index (high nibble), member threshold - 1 (low nibble)
+//	[1 byte]  member index (low nibble; the high nibble is reserved and zero)
+//
+// The shares cannot be mixed with those of the parent package.
+package sskr
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/morizta/go-shamir/internal/bcshamir"
+)
+
+const (
+	headerSize    = 5
+	minSecretSize = 16
+	maxSecretSize = 32
+	maxGroups     = 16
+	maxMembers    = 16
+)
+
+var (
+	// ErrInvalidShare indicates that a share is malformed.
+	ErrInvalidShare = errors.New("sskr: invalid share")
+
+	// ErrInvalidShares indicates that the shares do not belong to the same
+	// secret or carry conflicting parameters.
+	ErrInvalidShares = errors.New("sskr: inconsistent set of shares")
+
+	// ErrInsufficientShares indicates that too few groups or members were supplied.
+	ErrInsufficientShares = errors.New("sskr: insufficient shares for recovery")
+
+	// ErrDigestMismatch indicates that the recovered secret failed its digest
+	// check, usually because shares from different splits were mixed.
+	ErrDigestMismatch = errors.New("sskr: invalid digest of the shared secret")
+)
+
+// Group describes how one group's share is split among its members.
+type Group struct {
+	MemberThreshold int // Members needed to recover the group share (1-16)
+	MemberCount     int // Members in the group (1-16)
+}
+
+// Share is a decoded SSKR share.
+type Share struct {
+	Identifier      uint16
+	GroupIndex      int
+	GroupThreshold  int
+	GroupCount      int
+	MemberIndex     int
+	MemberThreshold int
+	Value           []byte
+}
+
+// Split splits secret into SSKR shares: one slice per group, one share per
+// member. The secret must be 16 to 32 bytes and of even length, such as the
+// entropy of a BIP-0039 mnemonic.
+func Split(secret []byte, groupThreshold int, groups []Group) ([][][]byte, error) {
+	if len(secret) < minSecretSize || len(secret) > maxSecretSize || len(secret)%2 != 0 {
+		return nil, fmt.Errorf("sskr: secret must be %d to %d bytes and of even length", minSecretSize, maxSecretSize)
+	}
+	if len(groups) < 1 || len(groups) > maxGroups {
+		return nil, fmt.Errorf("sskr: group count must be between 1 and %d", maxGroups)
+	}
+	if groupThreshold < 1 || groupThreshold > len(groups) {
+		return nil, fmt.Errorf("sskr: group threshold must be between 1 and the group count")
+	}
+	for i, g := range groups {
+		if g.MemberThreshold < 1 || g.MemberThreshold > g.MemberCount || g.MemberCount > maxMembers {
+			return nil, fmt.Errorf("sskr: group %d: member threshold must be between 1 and the member count (at most %d)", i, maxMembers)
+		}
+	}
+
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+
+	groupPoints, err := bcshamir.Split(groupThreshold, len(groups), secret)
+	if err != nil {
+		return nil, err
+	}
+	out := make([][][]byte, len(groups))
+	for gi, g := range groups {
+		members, err := bcshamir.Split(g.MemberThreshold, g.MemberCount, groupPoints[gi].Y)
+		if err != nil {
+			return nil, err
+		}
+		clear(groupPoints[gi].Y)
+		for _, m := range members {
+			s := Share{
+				Identifier:      uint16(id[0])<<8 | uint16(id[1]),
+				GroupIndex:      gi,
+				GroupThreshold:  groupThreshold,
+				GroupCount:      len(groups),
+				MemberIndex:     int(m.X),
+				MemberThreshold: g.MemberThreshold,
+				Value:           m.Y,
+			}
+			out[gi] = append(out[gi], s.Bytes())
+			clear(m.Y)
+		}
+	}
+	return out, nil
+}
+
+// Combine recovers the secret from SSKR shares. Shares may be supplied in any
+// order and may include more than the required number of groups or members.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrInsufficientShares
+	}
+
+	var first *Share
+	groups := make(map[int]map[int]*Share)
+	for i, data := range shares {
+		s, err := ParseShare(data)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", i+1, err)
+		}
+		if first == nil {
+			first = s
+		}
+		if s.Identifier != first.Identifier || s.GroupThreshold != first.GroupThreshold ||
+			s.GroupCount != first.GroupCount || len(s.Value) != len(first.Value) {
+			return nil, fmt.Errorf("share %d: %w", i+1, ErrInvalidShares)
+		}
+
+		members := groups[s.GroupIndex]
+		if members == nil {
+			members = make(map[int]*Share)
+			groups[s.GroupIndex] = members
+		}
+		for _, other := range members {
+			if other.MemberThreshold != s.MemberThreshold {
+				return nil, fmt.Errorf("share %d: %w: member thresholds differ within group %d", i+1, ErrInvalidShares, s.GroupIndex)
+			}
+		}
+		if dup, ok := members[s.MemberIndex]; ok && string(dup.Value) != string(s.Value) {
+			return nil, fmt.Errorf("share %d: %w: conflicting member %d in group %d", i+1, ErrInvalidShares, s.MemberIndex, s.GroupIndex)
+		}
+		members[s.MemberIndex] = s
+	}
+
+	// Recover the share of every group with enough members, in index order.
+	groupIndices := make([]int, 0, len(groups))
+	for gi := range groups {
+		groupIndices = append(groupIndices, gi)
+	}
+	sort.Ints(groupIndices)
+
+	var groupPoints []bcshamir.Point
+	defer func() {
+		for _, p := range groupPoints {
+			clear(p.Y)
+		}
+	}()
+	for _, gi := range groupIndices {
+		members := groups[gi]
+		var points []bcshamir.Point
+		var threshold int
+		for _, s := range members {
+			threshold = s.MemberThreshold
+			points = append(points, bcshamir.Point{X: byte(s.MemberIndex), Y: s.Value})
+		}
+		if len(points) < threshold {
+			continue
+		}
+		sort.Slice(points, func(a, b int) bool { return points[a].X < points[b].X })
+
+		secret, err := recoverSecret(threshold, points[:threshold])
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %w", gi, err)
+		}
+		groupPoints = append(groupPoints, bcshamir.Point{X: byte(gi), Y: secret})
+		if len(groupPoints) == first.GroupThreshold {
+			break
+		}
+	}
+	if len(groupPoints) < first.GroupThreshold {
+		return nil, ErrInsufficientShares
+	}
+	return recoverSecret(first.GroupThreshold, groupPoints)
+}
+
+// ParseShare decodes a share produced by Split. The value aliases data.
+func ParseShare(data []byte) (*Share, error) {
+	if len(data) < headerSize+minSecretSize || len(data) > headerSize+maxSecretSize || (len(data)-headerSize)%2 != 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidShare, len(data))
+	}
+	if data[4]&0xf0 != 0 {
+		return nil, fmt.Errorf("%w: reserved bits set", ErrInvalidShare)
+	}
+	s := &Share{
+		Identifier:      uint16(data[0])<<8 | uint16(data[1]),
+		GroupThreshold:  int(data[2]>>4) + 1,
+		GroupCount:      int(data[2]&0x0f) + 1,
+		GroupIndex:      int(data[3] >> 4),
+		MemberThreshold: int(data[3]&0x0f) + 1,
+		MemberIndex:     int(data[4] & 0x0f),
+		Value:           data[headerSize:],
+	}
+	if s.GroupThreshold > s.GroupCount || s.GroupIndex >= s.GroupCount {
+		return nil, fmt.Errorf("%w: group %d of %d with threshold %d", ErrInvalidShare, s.GroupIndex, s.GroupCount, s.GroupThreshold)
+	}
+	return s, nil
+}
+
+// Bytes encodes the share.
+func (s *Share) Bytes() []byte {
+	out := make([]byte, 0, headerSize+len(s.Value))
+	out = append(out,
+		byte(s.Identifier>>8), byte(s.Identifier),
+		byte(s.GroupThreshold-1)<<4|byte(s.GroupCount-1),
+		byte(s.GroupIndex)<<4|byte(s.MemberThreshold-1),
+		byte(s.MemberIndex)&0x0f,
+	)
+	return append(out, s.Value...)
+}
+
+// recoverSecret interpolates the secret from threshold points and checks the digest.
+func recoverSecret(threshold int, points []bcshamir.Point) ([]byte, error) {
+	secret, err := bcshamir.Recover(threshold, points)
+	switch {
+	case errors.Is(err, bcshamir.ErrDigestMismatch):
+		return nil, ErrDigestMismatch
+	case err != nil:
+		return nil, fmt.Errorf("%w: %w", ErrInvalidShares, err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,158 @@
+package sskr
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var secret = []byte("0123456789abcdef")
+
+func TestSplitCombine(t *testing.T) {
+	shares, err := Split(secret, 2, []Group{
+		{MemberThreshold: 1, MemberCount: 1},
+		{MemberThreshold: 2, MemberCount: 3},
+		{MemberThreshold: 3, MemberCount: 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 3 || len(shares[1]) != 3 || len(shares[2]) != 5 {
+		t.Fatalf("unexpected share layout %d/%d/%d", len(shares[0]), len(shares[1]), len(shares[2]))
+	}
+	if len(shares[0][0]) != headerSize+len(secret) {
+		t.Fatalf("share of %d bytes", len(shares[0][0]))
+	}
+
+	tests := []struct {
+		name   string
+		shares [][]byte
+		want   error
+	}{
+		{"groups 0 and 1", [][]byte{shares[1][2], shares[0][0], shares[1][0]}, nil},
+		{"groups 1 and 2, extra member", [][]byte{shares[2][4], shares[1][1], shares[2][0], shares[2][2], shares[1][2], shares[2][1]}, nil},
+		{"duplicate share", [][]byte{shares[0][0], shares[1][0], shares[1][0]}, ErrInsufficientShares},
+		{"one group", [][]byte{shares[2][0], shares[2][1], shares[2][2]}, ErrInsufficientShares},
+		{"none", nil, ErrInsufficientShares},
+	}
+	for _, tt := range tests {
+		got, err := Combine(tt.shares)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+			continue
+		}
+		if tt.want == nil && !bytes.Equal(got, secret) {
+			t.Errorf("%s: recovered %x", tt.name, got)
+		}
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	a, err := Split(secret, 1, []Group{{MemberThreshold: 2, MemberCount: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Split(secret, 1, []Group{{MemberThreshold: 2, MemberCount: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Combine([][]byte{a[0][0], b[0][1]}); !errors.Is(err, ErrInvalidShares) {
+		t.Fatalf("mixed splits: expected ErrInvalidShares, got %v", err)
+	}
+
+	corrupt := append([]byte(nil), a[0][1]...)
+	corrupt[headerSize] ^= 1
+	if _, err := Combine([][]byte{a[0][0], corrupt}); !errors.Is(err, ErrDigestMismatch) {
+		t.Fatalf("corrupt share: expected ErrDigestMismatch, got %v", err)
+	}
+
+	reserved := append([]byte(nil), a[0][1]...)
+	reserved[4] |= 0x10
+	if _, err := Combine([][]byte{a[0][0], reserved}); !errors.Is(err, ErrInvalidShare) {
+		t.Fatalf("reserved bits: expected ErrInvalidShare, got %v", err)
+	}
+	if _, err := Combine([][]byte{a[0][0][:headerSize+15]}); !errors.Is(err, ErrInvalidShare) {
+		t.Fatalf("odd length: expected ErrInvalidShare, got %v", err)
+	}
+}
+
+func TestSplitValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    []byte
+		threshold int
+		groups    []Group
+	}{
+		{"short secret", make([]byte, 14), 1, []Group{{1, 1}}},
+		{"long secret", make([]byte, 34), 1, []Group{{1, 1}}},
+		{"odd secret", make([]byte, 17), 1, []Group{{1, 1}}},
+		{"no groups", secret, 1, nil},
+		{"group threshold", secret, 2, []Group{{1, 1}}},
+		{"member threshold", secret, 1, []Group{{3, 2}}},
+		{"too many members", secret, 1, []Group{{2, 17}}},
+	}
+	for _, tt := range tests {
+		if _, err := Split(tt.secret, tt.threshold, tt.groups); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestUR(t *testing.T) {
+	shares, err := Split(secret, 1, []Group{{MemberThreshold: 2, MemberCount: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	share := shares[0][1]
+
+	ur, err := EncodeUR(share)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Tag 309 (d9 01 35) and a 21-byte string (55), as seedtool writes them.
+	if !strings.HasPrefix(ur, "ur:crypto-sskr/taadecgo") {
+		t.Fatalf("unexpected UR %q", ur)
+	}
+	for _, s := range []string{ur, strings.ToUpper(ur)} {
+		got, err := DecodeUR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, share) {
+			t.Fatalf("decoded %x, want %x", got, share)
+		}
+	}
+
+	parts, err := EncodeURParts(share, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "ur:crypto-sskr/1-3/") {
+		t.Fatalf("unexpected parts %q", parts)
+	}
+	got, err := DecodeUR(parts[2], parts[0], parts[2], parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, share) {
+		t.Fatalf("decoded %x, want %x", got, share)
+	}
+	if _, err := DecodeUR(parts[0], parts[2]); !errors.Is(err, ErrInvalidUR) {
+		t.Fatalf("missing part: expected ErrInvalidUR, got %v", err)
+	}
+
+	single, err := EncodeURParts(share, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(single) != 1 || single[0] != ur {
+		t.Fatalf("expected a single-part UR, got %q", single)
+	}
+
+	for _, bad := range []string{"ur:bytes/" + ur[len("ur:crypto-sskr/"):], ur[:len(ur)-2], "crypto-sskr"} {
+		if _, err := DecodeUR(bad); !errors.Is(err, ErrInvalidUR) {
+			t.Errorf("%q: expected ErrInvalidUR, got %v", bad, err)
+		}
+	}
+}
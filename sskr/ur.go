@@ -0,0 +1,247 @@
+package sskr
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"github.com/morizta/go-shamir/internal/bytewords"
+)
+
+// Uniform Resources (UR, BCR-2020-005) carry CBOR over QR codes as
+// "ur:<type>/<minimal bytewords>". A crypto-sskr UR holds a share as a CBOR
+// byte string with tag 309, as written by Blockchain Commons' seedtool and
+// read by Keystone and SeedSigner.
+//
+// Messages too large for one QR code are split into parts
+// "ur:<type>/<seq>-<count>/<bytewords>" shown as an animated QR code. Each part
+// holds the CBOR array [seq, count, message length, message CRC32, fragment].
+// EncodeURParts emits only the first count parts, each carrying a single
+// fragment; DecodeUR skips fountain-coded parts (seq > count) that mix
+// several fragments, so scanners should keep feeding it frames until it
+// succeeds.
+
+const (
+	urType       = "crypto-sskr"
+	urTypeLegacy = "sskr"
+
+	tagSSKR       = 309
+	tagSSKRLegacy = 40309
+
+	minFragmentLen = 10
+)
+
+// ErrInvalidUR indicates a malformed or incomplete UR.
+var ErrInvalidUR = errors.New("sskr: invalid UR")
+
+// EncodeUR returns share as a single-part crypto-sskr UR.
+func EncodeUR(share []byte) (string, error) {
+	if _, err := ParseShare(share); err != nil {
+		return "", err
+	}
+	return "ur:" + urType + "/" + bytewords.EncodeMinimal(urMessage(share)), nil
+}
+
+// EncodeURParts returns share as a multi-part crypto-sskr UR for display as an
+// animated QR code, with fragments of at most maxFragmentLen bytes (at least
+// 10). A share that fits in one fragment is returned as a single-part UR.
+func EncodeURParts(share []byte, maxFragmentLen int) ([]string, error) {
+	if maxFragmentLen < minFragmentLen {
+		return nil, fmt.Errorf("sskr: fragment length must be at least %d", minFragmentLen)
+	}
+	if _, err := ParseShare(share); err != nil {
+		return nil, err
+	}
+	message := urMessage(share)
+	if len(message) <= maxFragmentLen {
+		single, err := EncodeUR(share)
+		return []string{single}, err
+	}
+
+	count := (len(message) + maxFragmentLen - 1) / maxFragmentLen
+	fragmentLen := (len(message) + count - 1) / count
+	padded := make([]byte, count*fragmentLen)
+	copy(padded, message)
+	checksum := crc32.ChecksumIEEE(message)
+
+	parts := make([]string, count)
+	for i := range parts {
+		part := cborHead(nil, cborArray, 5)
+		part = cborHead(part, cborUint, uint64(i+1))
+		part = cborHead(part, cborUint, uint64(count))
+		part = cborHead(part, cborUint, uint64(len(message)))
+		part = cborHead(part, cborUint, uint64(checksum))
+		part = cborHead(part, cborBytes, uint64(fragmentLen))
+		part = append(part, padded[i*fragmentLen:(i+1)*fragmentLen]...)
+		parts[i] = fmt.Sprintf("ur:%s/%d-%d/%s", urType, i+1, count, bytewords.EncodeMinimal(part))
+	}
+	return parts, nil
+}
+
+// DecodeUR decodes a crypto-sskr UR, either a single part or the frames of an
+// animated QR code in any order; repeated frames are ignored. The legacy
+// "sskr" type and untagged byte strings are also accepted.
+func DecodeUR(parts ...string) ([]byte, error) {
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("%w: no parts", ErrInvalidUR)
+	}
+
+	var (
+		count, messageLen int
+		checksum          uint32
+		fragments         map[int][]byte
+	)
+	for _, part := range parts {
+		fields := strings.Split(strings.ToLower(strings.TrimSpace(part)), "/")
+		if len(fields) < 2 || len(fields) > 3 || (fields[0] != "ur:"+urType && fields[0] != "ur:"+urTypeLegacy) {
+			return nil, fmt.Errorf("%w: expected ur:%s", ErrInvalidUR, urType)
+		}
+		body, err := bytewords.DecodeMinimal(fields[len(fields)-1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidUR, err)
+		}
+		if len(fields) == 2 {
+			if len(parts) != 1 {
+				return nil, fmt.Errorf("%w: single-part UR among %d parts", ErrInvalidUR, len(parts))
+			}
+			return parseURMessage(body)
+		}
+
+		seq, n, ml, sum, fragment, err := parseURPart(body)
+		if err != nil {
+			return nil, err
+		}
+		if fields[1] != fmt.Sprintf("%d-%d", seq, n) {
+			return nil, fmt.Errorf("%w: sequence %q does not match part %d-%d", ErrInvalidUR, fields[1], seq, n)
+		}
+		if fragments == nil {
+			count, messageLen, checksum = n, ml, sum
+			fragments = make(map[int][]byte, count)
+		} else if n != count || ml != messageLen || sum != checksum {
+			return nil, fmt.Errorf("%w: parts of different messages", ErrInvalidUR)
+		}
+		if seq <= count {
+			fragments[seq] = fragment
+		}
+	}
+
+	if len(fragments) < count {
+		return nil, fmt.Errorf("%w: %d of %d parts received", ErrInvalidUR, len(fragments), count)
+	}
+	var message []byte
+	for seq := 1; seq <= count; seq++ {
+		message = append(message, fragments[seq]...)
+	}
+	if len(message) < messageLen {
+		return nil, fmt.Errorf("%w: fragments shorter than message", ErrInvalidUR)
+	}
+	message = message[:messageLen]
+	if crc32.ChecksumIEEE(message) != checksum {
+		return nil, fmt.Errorf("%w: message checksum mismatch", ErrInvalidUR)
+	}
+	return parseURMessage(message)
+}
+
+// urMessage returns share as a tagged CBOR byte string.
+func urMessage(share []byte) []byte {
+	msg := cborHead(nil, cborTag, tagSSKR)
+	msg = cborHead(msg, cborBytes, uint64(len(share)))
+	return append(msg, share...)
+}
+
+// parseURMessage extracts the share from a CBOR byte string, tagged or not.
+func parseURMessage(msg []byte) ([]byte, error) {
+	major, v, rest, err := cborReadHead(msg)
+	if err != nil {
+		return nil, err
+	}
+	if major == cborTag {
+		if v != tagSSKR && v != tagSSKRLegacy {
+			return nil, fmt.Errorf("%w: unexpected tag %d", ErrInvalidUR, v)
+		}
+		if major, v, rest, err = cborReadHead(rest); err != nil {
+			return nil, err
+		}
+	}
+	if major != cborBytes || v != uint64(len(rest)) {
+		return nil, fmt.Errorf("%w: expected a byte string", ErrInvalidUR)
+	}
+	share := append([]byte(nil), rest...)
+	if _, err := ParseShare(share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// parseURPart decodes the CBOR array of a multi-part UR.
+func parseURPart(part []byte) (seq, count, messageLen int, checksum uint32, fragment []byte, err error) {
+	major, n, rest, err := cborReadHead(part)
+	if err != nil || major != cborArray || n != 5 {
+		return 0, 0, 0, 0, nil, fmt.Errorf("%w: malformed part", ErrInvalidUR)
+	}
+	var fields [4]uint64
+	for i := range fields {
+		if major, fields[i], rest, err = cborReadHead(rest); err != nil || major != cborUint {
+			return 0, 0, 0, 0, nil, fmt.Errorf("%w: malformed part", ErrInvalidUR)
+		}
+	}
+	major, n, rest, err = cborReadHead(rest)
+	if err != nil || major != cborBytes || n != uint64(len(rest)) || n == 0 {
+		return 0, 0, 0, 0, nil, fmt.Errorf("%w: malformed part", ErrInvalidUR)
+	}
+	if fields[0] == 0 || fields[1] == 0 || fields[1] > 0xffff || fields[2] == 0 ||
+		fields[2] > fields[1]*uint64(len(rest)) || fields[3] > 0xffffffff {
+		return 0, 0, 0, 0, nil, fmt.Errorf("%w: malformed part", ErrInvalidUR)
+	}
+	return int(fields[0]), int(fields[1]), int(fields[2]), uint32(fields[3]), rest, nil
+}
+
+// CBOR major types used by URs.
+const (
+	cborUint  = 0
+	cborBytes = 2
+	cborArray = 4
+	cborTag   = 6
+)
+
+// cborHead appends the shortest CBOR head for a major type and argument.
+func cborHead(dst []byte, major byte, v uint64) []byte {
+	m := major << 5
+	switch {
+	case v < 24:
+		return append(dst, m|byte(v))
+	case v <= 0xff:
+		return append(dst, m|24, byte(v))
+	case v <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(dst, m|25), uint16(v))
+	case v <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(dst, m|26), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(dst, m|27), v)
+}
+
+// cborReadHead decodes a CBOR head, returning its major type, argument and the
+// bytes that follow it.
+func cborReadHead(data []byte) (major byte, v uint64, rest []byte, err error) {
+	if len(data) == 0 {
+		return 0, 0, nil, fmt.Errorf("%w: truncated CBOR", ErrInvalidUR)
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+	if info < 24 {
+		return major, uint64(info), data, nil
+	}
+	if info > 27 {
+		return 0, 0, nil, fmt.Errorf("%w: unsupported CBOR item 0x%02x", ErrInvalidUR, major<<5|info)
+	}
+	size := 1 << (info - 24)
+	if len(data) < size {
+		return 0, 0, nil, fmt.Errorf("%w: truncated CBOR", ErrInvalidUR)
+	}
+	for _, b := range data[:size] {
+		v = v<<8 | uint64(b)
+	}
+	return major, v, data[size:], nil
+}
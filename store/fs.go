@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// FS is a Store that keeps each share in its own file under a root
+// directory, at <root>/<set ID>/<index>.share. Directories are created with
+// mode 0700 and files with mode 0600.
+type FS struct {
+	root string
+}
+
+// shareExt is the file name extension of stored shares.
+const shareExt = ".share"
+
+// NewFS returns a store rooted at dir, which is created if it does not exist.
+func NewFS(dir string) (*FS, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("%w: directory required", ErrInvalidConfig)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return &FS{root: dir}, nil
+}
+
+func (f *FS) path(set shamir.SetID, index byte) string {
+	return filepath.Join(f.root, filepath.FromSlash(shareKey(set, index))+shareExt)
+}
+
+// Put implements Store. The share is written to a temporary file and renamed
+// into place, so a reader never sees a partly written share.
+func (f *FS) Put(ctx context.Context, set shamir.SetID, index byte, share []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path := f.path(set, index)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".put-*")
+	if err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+
+	if _, err := tmp.Write(share); err != nil {
+		tmp.Close()
+		return fmt.Errorf("store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	return nil
+}
+
+// Get implements Store.
+func (f *FS) Get(ctx context.Context, set shamir.SetID, index byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	share, err := os.ReadFile(f.path(set, index))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, shareKey(set, index))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return share, nil
+}
+
+// List implements Store. Files other than stored shares are ignored.
+func (f *FS) List(ctx context.Context, set shamir.SetID) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(f.root, set.String()))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), shareExt); ok && entry.Type().IsRegular() {
+			names = append(names, name)
+		}
+	}
+	return sortedIndices(names), nil
+}
+
+// Delete implements Store. The set's directory is removed with its last share.
+func (f *FS) Delete(ctx context.Context, set shamir.SetID, index byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path := f.path(set, index)
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("store: %w", err)
+	}
+	os.Remove(filepath.Dir(path)) // Only succeeds once the directory is empty
+	return nil
+}
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	shamir "github.com/morizta/go-shamir"
+	"github.com/morizta/go-shamir/internal/sigv4"
+)
+
+// S3Config configures an S3 store. Endpoint, Region, Bucket and the access
+// key are required.
+type S3Config struct {
+	// Endpoint is the base URL of the service, such as
+	// "https://s3.eu-west-1.amazonaws.com" or a MinIO server. Buckets are
+	// addressed path-style, <Endpoint>/<Bucket>/<key>.
+	Endpoint string
+	Region   string
+	Bucket   string
+
+	// Prefix is prepended to every object key, e.g. "shares/". It should end
+	// in a slash.
+	Prefix string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // For temporary credentials; optional
+
+	// ServerSideEncryption, if set, is sent as x-amz-server-side-encryption
+	// when storing shares, e.g. "AES256" or "aws:kms".
+	ServerSideEncryption string
+
+	// Client sends the requests; nil means http.DefaultClient.
+	Client *http.Client
+}
+
+// S3 is a Store that keeps each share in an object of an S3 bucket. Requests
+// are signed with AWS Signature Version 4.
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3 returns a store for the bucket described by cfg. It does not contact
+// the service.
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Region == "" || cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%w: S3 endpoint, region, bucket and access key are required", ErrInvalidConfig)
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: S3 endpoint %q", ErrInvalidConfig, cfg.Endpoint)
+	}
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &S3{cfg: cfg, endpoint: endpoint, client: client, now: time.Now}, nil
+}
+
+// Put implements Store.
+func (s *S3) Put(ctx context.Context, set shamir.SetID, index byte, share []byte) error {
+	header := http.Header{}
+	if s.cfg.ServerSideEncryption != "" {
+		header.Set("X-Amz-Server-Side-Encryption", s.cfg.ServerSideEncryption)
+	}
+	resp, err := s.do(ctx, http.MethodPut, s.cfg.Prefix+shareKey(set, index), nil, header, share)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get implements Store.
+func (s *S3) Get(ctx context.Context, set shamir.SetID, index byte) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.cfg.Prefix+shareKey(set, index), nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	share, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("store: S3: %w", err)
+	}
+	return share, nil
+}
+
+// List implements Store.
+func (s *S3) List(ctx context.Context, set shamir.SetID) ([]byte, error) {
+	prefix := s.cfg.Prefix + set.String() + "/"
+	var names []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Contents []struct {
+				Key string
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("store: S3: decoding object list: %w", err)
+		}
+		for _, object := range result.Contents {
+			names = append(names, strings.TrimPrefix(object.Key, prefix))
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return sortedIndices(names), nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Delete implements Store.
+func (s *S3) Delete(ctx context.Context, set shamir.SetID, index byte) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.cfg.Prefix+shareKey(set, index), nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a signed request for an object key, or for the bucket if key is
+// empty. A response other than 2xx is returned as an error, matching
+// ErrNotFound for 404.
+func (s *S3) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	// Encode the path as SigV4 canonicalizes it, so the signed and the sent
+	// paths agree.
+	u := *s.endpoint
+	u.RawPath = s.endpoint.EscapedPath() + "/" + sigv4.Escape(s.cfg.Bucket, true)
+	if key != "" {
+		u.RawPath += "/" + sigv4.Escape(key, false)
+	}
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	u.RawQuery = sigv4.Query(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("store: S3: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	sigv4.Sign(req, body, s.now(), "s3", s.cfg.Region, sigv4.Credentials{
+		AccessKeyID:     s.cfg.AccessKeyID,
+		SecretAccessKey: s.cfg.SecretAccessKey,
+		SessionToken:    s.cfg.SessionToken,
+	})
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("store: S3: %w", err)
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Code    string
+		Message string
+	}
+	xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result)
+	if resp.StatusCode == http.StatusNotFound && method != http.MethodDelete {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return nil, fmt.Errorf("store: S3 %s %s: %s: %s %s", method, key, resp.Status, result.Code, result.Message)
+}
@@ -0,0 +1,240 @@
+// Package store persists shares of the parent shamir package, so that
+// applications do not each reimplement where shares live between a split and
+// a recovery.
+//
+// A Store holds shares keyed by the set ID of their split (shamir.SetID) and
+// their index, the x-coordinate. Implementations are provided for a local
+// directory (NewFS), Amazon S3 and compatible object stores (NewS3), the
+// HashiCorp Vault KV version 2 secrets engine (NewVault), and memory
+// (NewMemory). Every backend lays shares out under the same keys,
+//
+//	<set ID in hex>/<index as three decimal digits>
+//
+// so a set can be copied between backends with a plain List, Get and Put.
+//
+// SplitAndStore and LoadAndCombine tie a store to SplitWithOptions and
+// CombineWithOptions. A store holding every share of a split can reconstruct
+// the secret on its own: it is a convenience for custody arrangements where
+// each store holds only the shares of one custodian, or for staging shares
+// before they are handed out.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+var (
+	// ErrNotFound indicates that no share is stored under a set ID and index.
+	ErrNotFound = errors.New("store: share not found")
+
+	// ErrInvalidConfig indicates a backend configuration missing a required field.
+	ErrInvalidConfig = errors.New("store: invalid configuration")
+)
+
+// Store persists shares by set ID and index. Implementations are safe for
+// concurrent use.
+type Store interface {
+	// Put stores a share, replacing any share stored under the same key.
+	Put(ctx context.Context, set shamir.SetID, index byte, share []byte) error
+
+	// Get returns a stored share, or an error matching ErrNotFound.
+	Get(ctx context.Context, set shamir.SetID, index byte) ([]byte, error)
+
+	// List returns the indices of the shares stored for a set in increasing
+	// order, and no error if there are none.
+	List(ctx context.Context, set shamir.SetID) ([]byte, error)
+
+	// Delete removes a stored share. Deleting a share that is not stored is
+	// not an error.
+	Delete(ctx context.Context, set shamir.SetID, index byte) error
+}
+
+// shareKey returns the key a share is stored under, relative to a backend's
+// root or prefix.
+func shareKey(set shamir.SetID, index byte) string {
+	return fmt.Sprintf("%s/%03d", set, index)
+}
+
+// parseIndex parses the last element of a share key, reporting false for
+// anything not written by shareKey.
+func parseIndex(name string) (byte, bool) {
+	if len(name) != 3 {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(name, 10, 8)
+	if err != nil || n == 0 {
+		return 0, false
+	}
+	return byte(n), true
+}
+
+// sortedIndices parses the names of a set's entries, ignoring foreign ones.
+func sortedIndices(names []string) []byte {
+	indices := make([]byte, 0, len(names))
+	for _, name := range names {
+		if index, ok := parseIndex(strings.TrimSuffix(name, "/")); ok {
+			indices = append(indices, index)
+		}
+	}
+	slices.Sort(indices)
+	return slices.Compact(indices)
+}
+
+// SplitAndStore splits secret into parts shares with the given threshold and
+// stores each under its set ID and index, returning the set ID. opts are
+// passed to shamir.SplitWithOptions after the parts and threshold. The shares
+// are always enveloped, since raw shares carry no set ID; WithLabels may be
+// used to label them.
+//
+// If a share cannot be stored, the shares stored so far are deleted again and
+// the error is returned.
+func SplitAndStore(ctx context.Context, st Store, secret []byte, parts, threshold int, opts ...shamir.Option) (shamir.SetID, error) {
+	base := []shamir.Option{shamir.WithParts(parts), shamir.WithThreshold(threshold)}
+	if parts > 0 {
+		// Empty labels request envelopes; labels from opts replace them.
+		base = append(base, shamir.WithLabels(make([]string, parts)...))
+	}
+	shares, err := shamir.SplitWithOptions(secret, append(base, opts...)...)
+	if err != nil {
+		return shamir.SetID{}, err
+	}
+	defer func() {
+		for _, share := range shares {
+			clear(share)
+		}
+	}()
+
+	var set shamir.SetID
+	stored := make([]byte, 0, len(shares))
+	for _, share := range shares {
+		info, err := shamir.InspectShare(share)
+		if err == nil && info.SetID.IsZero() {
+			err = fmt.Errorf("store: share has no set ID")
+		}
+		if err == nil {
+			set = info.SetID
+			err = st.Put(ctx, set, info.Index, share)
+		}
+		if err != nil {
+			for _, index := range stored {
+				// Best effort; the original error is what the caller needs.
+				_ = st.Delete(context.WithoutCancel(ctx), set, index)
+			}
+			return shamir.SetID{}, err
+		}
+		stored = append(stored, info.Index)
+	}
+	return set, nil
+}
+
+// LoadAndCombine loads every share stored for a set and reconstructs the
+// secret with shamir.CombineWithOptions and opts. A stored share from another
+// split fails with shamir.ErrMismatchedShares rather than being combined. If
+// no share is stored for the set, the error matches ErrNotFound.
+func LoadAndCombine(ctx context.Context, st Store, set shamir.SetID, opts ...shamir.Option) ([]byte, error) {
+	indices, err := st.List(ctx, set)
+	if err != nil {
+		return nil, err
+	}
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("%w: set %s", ErrNotFound, set)
+	}
+
+	shares := make([][]byte, 0, len(indices))
+	defer func() {
+		for _, share := range shares {
+			clear(share)
+		}
+	}()
+	for _, index := range indices {
+		share, err := st.Get(ctx, set, index)
+		if errors.Is(err, ErrNotFound) {
+			continue // Deleted since List
+		}
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+		if id, err := shamir.ShareSetID(share); err != nil || id != set {
+			return nil, fmt.Errorf("%w: share %d stored for set %s belongs to another split", shamir.ErrMismatchedShares, index, set)
+		}
+	}
+	return shamir.CombineWithOptions(shares, opts...)
+}
+
+// Memory is a Store that keeps shares in memory, for tests and for staging
+// shares within a process.
+type Memory struct {
+	mu     sync.Mutex
+	shares map[string][]byte
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{shares: make(map[string][]byte)}
+}
+
+// Put implements Store.
+func (m *Memory) Put(ctx context.Context, set shamir.SetID, index byte, share []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := shareKey(set, index)
+	clear(m.shares[key])
+	m.shares[key] = slices.Clone(share)
+	return nil
+}
+
+// Get implements Store.
+func (m *Memory) Get(ctx context.Context, set shamir.SetID, index byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	share, ok := m.shares[shareKey(set, index)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, shareKey(set, index))
+	}
+	return slices.Clone(share), nil
+}
+
+// List implements Store.
+func (m *Memory) List(ctx context.Context, set shamir.SetID) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := set.String() + "/"
+	var names []string
+	for key := range m.shares {
+		if name, ok := strings.CutPrefix(key, prefix); ok {
+			names = append(names, name)
+		}
+	}
+	return sortedIndices(names), nil
+}
+
+// Delete implements Store.
+func (m *Memory) Delete(ctx context.Context, set shamir.SetID, index byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := shareKey(set, index)
+	clear(m.shares[key])
+	delete(m.shares, key)
+	return nil
+}
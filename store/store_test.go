@@ -0,0 +1,356 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// testStore exercises the Store contract on st.
+func testStore(t *testing.T, st Store) {
+	t.Helper()
+	ctx := context.Background()
+	set := shamir.SetID{1, 2, 3}
+	other := shamir.SetID{4, 5, 6}
+
+	if indices, err := st.List(ctx, set); err != nil || len(indices) != 0 {
+		t.Fatalf("List of an empty store: %v, %v", indices, err)
+	}
+	if _, err := st.Get(ctx, set, 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get of a missing share: expected ErrNotFound, got %v", err)
+	}
+
+	for _, index := range []byte{12, 1, 255} {
+		if err := st.Put(ctx, set, index, []byte{index, 0xaa, 0xbb}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.Put(ctx, other, 7, []byte("other")); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Put(ctx, set, 12, []byte{12, 0xcc}); err != nil {
+		t.Fatal(err)
+	}
+
+	indices, err := st.List(ctx, set)
+	if err != nil || !bytes.Equal(indices, []byte{1, 12, 255}) {
+		t.Fatalf("List: %v, %v", indices, err)
+	}
+	if share, err := st.Get(ctx, set, 12); err != nil || !bytes.Equal(share, []byte{12, 0xcc}) {
+		t.Fatalf("Get after replace: %x, %v", share, err)
+	}
+
+	if err := st.Delete(ctx, set, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Delete(ctx, set, 1); err != nil {
+		t.Fatalf("Delete of a missing share: %v", err)
+	}
+	if _, err := st.Get(ctx, set, 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete: expected ErrNotFound, got %v", err)
+	}
+	if indices, err := st.List(ctx, set); err != nil || !bytes.Equal(indices, []byte{12, 255}) {
+		t.Fatalf("List after Delete: %v, %v", indices, err)
+	}
+	if indices, err := st.List(ctx, other); err != nil || !bytes.Equal(indices, []byte{7}) {
+		t.Fatalf("List of another set: %v, %v", indices, err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testStore(t, NewMemory())
+}
+
+func TestFS(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "shares")
+	st, err := NewFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, st)
+
+	set := shamir.SetID{9}
+	if err := st.Put(context.Background(), set, 3, []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(dir, set.String(), "003.share"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("share file mode %v, want 0600", info.Mode().Perm())
+	}
+	os.WriteFile(filepath.Join(dir, set.String(), "notes.txt"), nil, 0o600)
+	if indices, err := st.List(context.Background(), set); err != nil || !bytes.Equal(indices, []byte{3}) {
+		t.Errorf("List with a foreign file: %v, %v", indices, err)
+	}
+
+	if _, err := NewFS(""); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("NewFS(\"\"): expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// fakeS3 is an in-memory S3 bucket serving path-style requests for one
+// bucket. It checks that requests are signed and that the signed payload hash
+// matches the body, and pages object lists two keys at a time.
+type fakeS3 struct {
+	bucket  string
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	sum := sha256.Sum256(body)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket)
+	if !ok {
+		http.Error(w, "<Error><Code>NoSuchBucket</Code></Error>", http.StatusNotFound)
+		return
+	}
+	key = strings.TrimPrefix(key, "/")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w, r)
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+	case r.Method == http.MethodGet:
+		object, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		w.Write(object)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) && key > r.URL.Query().Get("continuation-token") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	type object struct{ Key string }
+	var result struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Contents              []object
+		IsTruncated           bool
+		NextContinuationToken string `xml:",omitempty"`
+	}
+	for i, key := range keys {
+		if i == 2 {
+			result.IsTruncated, result.NextContinuationToken = true, keys[1]
+			break
+		}
+		result.Contents = append(result.Contents, object{key})
+	}
+	xml.NewEncoder(w).Encode(result)
+}
+
+func TestS3(t *testing.T) {
+	fake := &fakeS3{bucket: "escrow", objects: make(map[string][]byte)}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	st, err := NewS3(S3Config{
+		Endpoint:        srv.URL,
+		Region:          "eu-west-1",
+		Bucket:          "escrow",
+		Prefix:          "prod/",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, st)
+	if _, ok := fake.objects["prod/"+shareKey(shamir.SetID{1, 2, 3}, 12)]; !ok {
+		t.Error("objects not stored under the prefix")
+	}
+
+	bad, _ := NewS3(S3Config{Endpoint: srv.URL, Region: "eu-west-1", Bucket: "escrow", AccessKeyID: "other", SecretAccessKey: "secret"})
+	if _, err := bad.Get(context.Background(), shamir.SetID{1}, 1); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("rejected request: expected an access error, got %v", err)
+	}
+	if _, err := NewS3(S3Config{Endpoint: srv.URL}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("incomplete config: expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// fakeVault is an in-memory Vault KV version 2 engine mounted at "kv".
+type fakeVault struct {
+	mu      sync.Mutex
+	secrets map[string]map[string]string
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != "s.token" {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if path, ok := strings.CutPrefix(r.URL.Path, "/v1/kv/data/"); ok {
+		switch r.Method {
+		case http.MethodPost:
+			var req struct{ Data map[string]string }
+			json.NewDecoder(r.Body).Decode(&req)
+			f.secrets[path] = req.Data
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]int{"version": 1}})
+		case http.MethodGet:
+			data, ok := f.secrets[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"errors":[]}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": data}})
+		}
+		return
+	}
+	path, ok := strings.CutPrefix(r.URL.Path, "/v1/kv/metadata/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("list") == "true":
+		var keys []string
+		for key := range f.secrets {
+			if name, ok := strings.CutPrefix(key, path+"/"); ok {
+				keys = append(keys, name)
+			}
+		}
+		if keys == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"keys": keys}})
+	case r.Method == http.MethodDelete:
+		delete(f.secrets, path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestVault(t *testing.T) {
+	fake := &fakeVault{secrets: make(map[string]map[string]string)}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	st, err := NewVault(VaultConfig{Address: srv.URL + "/", Token: "s.token", Mount: "/kv/", Prefix: "shamir/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, st)
+	if _, ok := fake.secrets["shamir/"+shareKey(shamir.SetID{1, 2, 3}, 12)]; !ok {
+		t.Error("secrets not stored under the prefix")
+	}
+
+	bad, _ := NewVault(VaultConfig{Address: srv.URL, Token: "wrong", Mount: "kv"})
+	if err := bad.Put(context.Background(), shamir.SetID{1}, 1, []byte("x")); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("wrong token: expected permission denied, got %v", err)
+	}
+	if _, err := NewVault(VaultConfig{Address: srv.URL}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("missing token: expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestSplitAndStore(t *testing.T) {
+	ctx := context.Background()
+	st := NewMemory()
+	secret := []byte("database master key")
+
+	set, err := SplitAndStore(ctx, st, secret, 5, 3, shamir.WithLabels("a", "b", "c", "d", "e"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	indices, err := st.List(ctx, set)
+	if err != nil || len(indices) != 5 {
+		t.Fatalf("List: %v, %v", indices, err)
+	}
+	share, _ := st.Get(ctx, set, indices[1])
+	if s, err := shamir.ParseShare(share); err != nil || s.Label != "b" || s.Threshold != 3 {
+		t.Fatalf("stored share: %+v, %v", s, err)
+	}
+
+	for _, index := range indices[:2] {
+		st.Delete(ctx, set, index)
+	}
+	got, err := LoadAndCombine(ctx, st, set)
+	if err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("LoadAndCombine: %q, %v", got, err)
+	}
+
+	// A share of another split filed under this set is refused.
+	otherSet, err := SplitAndStore(ctx, st, []byte("another secret....."), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stray, _ := st.Get(ctx, otherSet, 1)
+	st.Put(ctx, set, 1, stray)
+	if _, err := LoadAndCombine(ctx, st, set); !errors.Is(err, shamir.ErrMismatchedShares) {
+		t.Errorf("stray share: expected ErrMismatchedShares, got %v", err)
+	}
+
+	if _, err := LoadAndCombine(ctx, st, shamir.SetID{0xff}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown set: expected ErrNotFound, got %v", err)
+	}
+	var verr *shamir.ValidationError
+	if _, err := SplitAndStore(ctx, st, secret, 2, 3); !errors.As(err, &verr) || verr.Field != "threshold" {
+		t.Errorf("invalid threshold: expected a threshold ValidationError, got %v", err)
+	}
+}
+
+// failingStore fails every Put after the first n.
+type failingStore struct {
+	*Memory
+	n int
+}
+
+func (f *failingStore) Put(ctx context.Context, set shamir.SetID, index byte, share []byte) error {
+	if f.n == 0 {
+		return errors.New("disk full")
+	}
+	f.n--
+	return f.Memory.Put(ctx, set, index, share)
+}
+
+func TestSplitAndStoreRollsBack(t *testing.T) {
+	st := &failingStore{Memory: NewMemory(), n: 2}
+	if _, err := SplitAndStore(context.Background(), st, []byte("secret"), 4, 2); err == nil || err.Error() != "disk full" {
+		t.Fatalf("expected the store error, got %v", err)
+	}
+	if len(st.shares) != 0 {
+		t.Errorf("%d shares left behind after a failed split", len(st.shares))
+	}
+}
@@ -0,0 +1,171 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// VaultConfig configures a store on a HashiCorp Vault KV version 2 secrets
+// engine. Address and Token are required.
+type VaultConfig struct {
+	Address   string // Base URL of the Vault server, e.g. "https://vault:8200"
+	Token     string // Token with create, read, list and delete on the paths used
+	Namespace string // Vault Enterprise namespace; optional
+
+	// Mount is the path the KV engine is mounted at; "" means "secret".
+	Mount string
+
+	// Prefix is prepended to every secret path, e.g. "shamir/". It should end
+	// in a slash.
+	Prefix string
+
+	// Client sends the requests; nil means http.DefaultClient.
+	Client *http.Client
+}
+
+// Vault is a Store that keeps each share in a secret of a Vault KV version 2
+// engine, base64 encoded under the key "share". Delete destroys all versions
+// of the secret along with its metadata.
+type Vault struct {
+	cfg    VaultConfig
+	client *http.Client
+}
+
+// vaultField is the key of the share within a stored secret.
+const vaultField = "share"
+
+// NewVault returns a store for the KV engine described by cfg. It does not
+// contact the server.
+func NewVault(cfg VaultConfig) (*Vault, error) {
+	if cfg.Address == "" || cfg.Token == "" {
+		return nil, fmt.Errorf("%w: Vault address and token are required", ErrInvalidConfig)
+	}
+	if u, err := url.Parse(cfg.Address); err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%w: Vault address %q", ErrInvalidConfig, cfg.Address)
+	}
+	cfg.Address = strings.TrimSuffix(cfg.Address, "/")
+	if cfg.Mount == "" {
+		cfg.Mount = "secret"
+	}
+	cfg.Mount = strings.Trim(cfg.Mount, "/")
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Vault{cfg: cfg, client: client}, nil
+}
+
+// Put implements Store.
+func (v *Vault) Put(ctx context.Context, set shamir.SetID, index byte, share []byte) error {
+	body, err := json.Marshal(map[string]any{
+		"data": map[string]string{vaultField: base64.StdEncoding.EncodeToString(share)},
+	})
+	if err != nil {
+		return fmt.Errorf("store: Vault: %w", err)
+	}
+	return v.do(ctx, http.MethodPost, "data", shareKey(set, index), nil, body, nil)
+}
+
+// Get implements Store.
+func (v *Vault) Get(ctx context.Context, set shamir.SetID, index byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+	key := shareKey(set, index)
+	if err := v.do(ctx, http.MethodGet, "data", key, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	encoded, ok := resp.Data.Data[vaultField]
+	if !ok {
+		return nil, fmt.Errorf("store: Vault secret %s has no %q key", key, vaultField)
+	}
+	share, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("store: Vault secret %s: %w", key, err)
+	}
+	return share, nil
+}
+
+// List implements Store.
+func (v *Vault) List(ctx context.Context, set shamir.SetID) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}
+	err := v.do(ctx, http.MethodGet, "metadata", set.String(), url.Values{"list": {"true"}}, nil, &resp)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sortedIndices(resp.Data.Keys), nil
+}
+
+// Delete implements Store.
+func (v *Vault) Delete(ctx context.Context, set shamir.SetID, index byte) error {
+	err := v.do(ctx, http.MethodDelete, "metadata", shareKey(set, index), nil, nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// do sends a request to the KV engine API under section ("data" or
+// "metadata") for path, decoding a JSON response into out if it is not nil.
+// A 404 is returned as an error matching ErrNotFound.
+func (v *Vault) do(ctx context.Context, method, section, path string, query url.Values, body []byte, out any) error {
+	u := v.cfg.Address + "/v1/" + v.cfg.Mount + "/" + section + "/" + v.cfg.Prefix + path
+	if query != nil {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("store: Vault: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.cfg.Token)
+	req.Header.Set("X-Vault-Request", "true")
+	if v.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.cfg.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("store: Vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	if resp.StatusCode/100 != 2 {
+		var verr struct {
+			Errors []string `json:"errors"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&verr)
+		return fmt.Errorf("store: Vault %s %s: %s: %s", method, path, resp.Status, strings.Join(verr.Errors, "; "))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("store: Vault: decoding response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package shamir
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/morizta/go-shamir/gf256"
+)
+
+// Strategy selects how Split and Combine evaluate the sharing polynomials.
+// All strategies produce interchangeable shares; they differ only in speed and
+// memory use.
+type Strategy int
+
+const (
+	// StrategyAuto picks a strategy from the secret size and the CPU, see SelectStrategy.
+	StrategyAuto Strategy = iota
+
+	// StrategyScalar processes one secret byte at a time with table lookups.
+	// It has the lowest fixed cost and suits secrets shorter than one vector.
+	StrategyScalar
+
+	// StrategySIMD processes whole share payloads with the slice kernels,
+	// using AVX2, SSSE3 or NEON where the CPU supports them, on a single goroutine.
+	StrategySIMD
+
+	// StrategyParallel runs the slice kernels on several goroutines, see WithParallelism.
+	StrategyParallel
+
+	// StrategyStreaming processes the secret in fixed-size windows, bounding
+	// the working memory of very large secrets. Windows use the parallel path.
+	StrategyStreaming
+)
+
+// Size thresholds used by StrategyAuto.
+const (
+	// parallelMinSize is the smallest secret worth spreading across goroutines.
+	parallelMinSize = 256 << 10
+
+	// streamingMinSize is the smallest secret processed in windows. Above it the
+	// threshold coefficient rows of the parallel path would dwarf the secret itself.
+	streamingMinSize = 64 << 20
+
+	// strategyWindowSize is the window length of StrategyStreaming.
+	strategyWindowSize = 1 << 20
+)
+
+// String returns the name of the strategy.
+func (s Strategy) String() string {
+	switch s {
+	case StrategyAuto:
+		return "auto"
+	case StrategyScalar:
+		return "scalar"
+	case StrategySIMD:
+		return "simd"
+	case StrategyParallel:
+		return "parallel"
+	case StrategyStreaming:
+		return "streaming"
+	default:
+		return fmt.Sprintf("Strategy(%d)", int(s))
+	}
+}
+
+// WithStrategy pins the evaluation strategy used by SplitWithOptions and
+// CombineWithOptions instead of selecting one automatically. Unknown values
+// are treated as StrategyAuto.
+func WithStrategy(s Strategy) Option {
+	return func(o *options) { o.strategy = s }
+}
+
+// SelectStrategy reports the strategy StrategyAuto uses for a secret of the
+// given length with the default parallelism (runtime.GOMAXPROCS).
+//
+// Secrets that fit in a single vector register use the scalar path, since the
+// SIMD kernels would not process any of their bytes. Larger secrets use the
+// SIMD path, switch to the parallel path once they are large enough to amortize
+// goroutine start-up on a multi-core machine, and to the streaming path once
+// the coefficient buffers would grow to many times the secret size.
+func SelectStrategy(secretLen int) Strategy {
+	strategy, _ := engine{}.resolve(secretLen)
+	return strategy
+}
+
+// engine carries the execution settings threaded through split and combine.
+type engine struct {
+	strategy    Strategy
+	parallelism int             // Maximum goroutines; 0 means runtime.GOMAXPROCS
+	ctx         context.Context // Checked between windows when set, see SplitContext
+
+	hooks  []PolicyHook     // Per-call policy hooks, see WithPolicyHook
+	meta   *CombineMetadata // Envelope metadata for policy hooks; nil for raw shares
+	strict int              // Threshold to cross-check surplus shares against; 0 disables
+
+	allowExpired bool // Skip the envelope validity check, see WithAllowExpired
+	allowTrivial bool // Accept a threshold of 1, see WithAllowTrivialThreshold
+
+	entropyTimeout time.Duration // See WithEntropyTimeout; 0 means the default, negative never
+
+	constantTime bool // Combine without data-dependent branches or lookups, see WithConstantTime
+}
+
+// engine returns the execution settings configured by o.
+func (o *options) engine() engine {
+	return engine{strategy: o.strategy, parallelism: o.parallelism, ctx: o.ctx, hooks: o.policyHooks, strict: o.strict, allowExpired: o.allowExpired, allowTrivial: o.allowTrivial, entropyTimeout: o.entropyTimeout, constantTime: o.constantTime}
+}
+
+// resolve returns the concrete strategy for a secret of n bytes and the number
+// of goroutines it may use.
+func (e engine) resolve(n int) (Strategy, int) {
+	workers := e.parallelism
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	switch e.strategy {
+	case StrategyScalar, StrategySIMD:
+		return e.strategy, 1
+	case StrategyParallel, StrategyStreaming:
+		return e.strategy, workers
+	}
+
+	switch {
+	case n <= scalarMaxSize():
+		return StrategyScalar, 1
+	case n >= streamingMinSize:
+		return StrategyStreaming, workers
+	case n >= parallelMinSize && workers > 1:
+		return StrategyParallel, workers
+	default:
+		return StrategySIMD, 1
+	}
+}
+
+// scalarMaxSize returns the largest secret for which the scalar path beats the
+// slice kernels: one SIMD vector where the CPU has a kernel, otherwise one
+// 64-bit word of gfAddSlice.
+func scalarMaxSize() int {
+	if gf256.Accelerated() {
+		return 16
+	}
+	return 8
+}
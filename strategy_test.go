@@ -0,0 +1,110 @@
+package shamir
+
+import (
+	"bytes"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+var allStrategies = []Strategy{StrategyAuto, StrategyScalar, StrategySIMD, StrategyParallel, StrategyStreaming}
+
+func TestStrategiesRoundTrip(t *testing.T) {
+	sizes := []int{1, 15, 16, 17, 100, 4097, 2*strategyWindowSize + 33}
+
+	for _, size := range sizes {
+		secret := make([]byte, size)
+		rand.New(rand.NewSource(int64(size))).Read(secret)
+
+		for _, splitWith := range allStrategies {
+			shares, err := SplitWithOptions(secret,
+				WithParts(5), WithThreshold(3), WithStrategy(splitWith), WithParallelism(4))
+			if err != nil {
+				t.Fatalf("size %d, split %v: %v", size, splitWith, err)
+			}
+
+			// Shares from any strategy must combine with any other strategy.
+			for _, combineWith := range allStrategies {
+				reconstructed, err := CombineWithOptions(shares[1:4],
+					WithStrategy(combineWith), WithParallelism(4))
+				if err != nil {
+					t.Fatalf("size %d, split %v, combine %v: %v", size, splitWith, combineWith, err)
+				}
+				if !bytes.Equal(reconstructed, secret) {
+					t.Fatalf("size %d, split %v, combine %v: reconstruction failed", size, splitWith, combineWith)
+				}
+			}
+		}
+	}
+}
+
+func TestStrategiesDeterministic(t *testing.T) {
+	secret := make([]byte, 1000)
+	for i := range secret {
+		secret[i] = byte(i * 13)
+	}
+
+	// The non-windowed strategies consume randomness identically, so with the
+	// same source they must produce identical shares.
+	var reference [][]byte
+	for _, s := range []Strategy{StrategyScalar, StrategySIMD, StrategyParallel} {
+		shares, err := SplitWithOptions(secret, WithParts(4), WithThreshold(3),
+			WithStrategy(s), WithParallelism(3), WithRand(rand.New(rand.NewSource(1))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reference == nil {
+			reference = shares
+			continue
+		}
+		for i := range shares {
+			if !bytes.Equal(shares[i], reference[i]) {
+				t.Fatalf("strategy %v: share %d differs from scalar path", s, i)
+			}
+		}
+	}
+}
+
+func TestSelectStrategy(t *testing.T) {
+	tests := []struct {
+		size int
+		want Strategy
+	}{
+		{1, StrategyScalar},
+		{scalarMaxSize(), StrategyScalar},
+		{scalarMaxSize() + 1, StrategySIMD},
+		{parallelMinSize - 1, StrategySIMD},
+		{streamingMinSize, StrategyStreaming},
+	}
+	if runtime.GOMAXPROCS(0) > 1 {
+		tests = append(tests, struct {
+			size int
+			want Strategy
+		}{parallelMinSize, StrategyParallel})
+	}
+
+	for _, tt := range tests {
+		if got := SelectStrategy(tt.size); got != tt.want {
+			t.Errorf("SelectStrategy(%d) = %v, expected %v", tt.size, got, tt.want)
+		}
+	}
+
+	t.Run("sequential parallelism", func(t *testing.T) {
+		if got, _ := (engine{parallelism: 1}).resolve(parallelMinSize); got != StrategySIMD {
+			t.Errorf("expected simd with parallelism 1, got %v", got)
+		}
+	})
+
+	t.Run("pinned", func(t *testing.T) {
+		got, workers := (engine{strategy: StrategyScalar, parallelism: 8}).resolve(streamingMinSize)
+		if got != StrategyScalar || workers != 1 {
+			t.Errorf("expected pinned scalar on one goroutine, got %v on %d", got, workers)
+		}
+	})
+}
+
+func TestStrategyString(t *testing.T) {
+	if StrategyStreaming.String() != "streaming" || Strategy(42).String() != "Strategy(42)" {
+		t.Fatal("unexpected strategy names")
+	}
+}
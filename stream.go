@@ -0,0 +1,313 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// streamChunkSize is the number of secret bytes processed per step when streaming.
+const streamChunkSize = 32 * 1024
+
+// NewSplitter splits the secret read from r into parts share streams without
+// buffering the whole secret in memory.
+//
+// Each returned reader yields a share in exactly the format produced by Split:
+// one x-coordinate byte followed by one y-value per secret byte. A streamed
+// share can therefore be combined with Combine or NewJoiner interchangeably.
+//
+// The secret is consumed from r in chunks as the share readers are read.
+// Memory use is bounded by how far the fastest reader runs ahead of the
+// slowest one, so readers should be drained concurrently (or in lock-step)
+// when splitting very large inputs.
+func NewSplitter(r io.Reader, parts, threshold int) ([]io.Reader, error) {
+	if r == nil {
+		return nil, ErrEmptySecret
+	}
+	// A one-byte placeholder lets us reuse the common validation rules.
+	if err := validateSplitParams([]byte{0}, parts, threshold); err != nil {
+		return nil, err
+	}
+
+	s := &splitter{
+		src:       r,
+		threshold: threshold,
+		pending:   make([]bytes.Buffer, parts),
+		chunk:     make([]byte, streamChunkSize),
+	}
+
+	readers := make([]io.Reader, parts)
+	for i := 0; i < parts; i++ {
+		s.pending[i].WriteByte(byte(i + 1)) // x-coordinates are 1-based
+		readers[i] = &shareReader{s: s, idx: i}
+	}
+
+	return readers, nil
+}
+
+// splitter holds the state shared by all share readers of one streaming split.
+type splitter struct {
+	mu        sync.Mutex
+	src       io.Reader
+	threshold int
+	pending   []bytes.Buffer // Generated but not yet consumed share bytes per reader
+	chunk     []byte         // Scratch buffer for the current secret chunk
+	total     int64          // Number of secret bytes consumed so far
+	err       error          // Terminal state: io.EOF once the source is exhausted
+}
+
+// fill reads the next chunk of the secret and appends the matching share bytes
+// to every reader's pending buffer. Must be called with s.mu held.
+func (s *splitter) fill() {
+	n, err := io.ReadFull(s.src, s.chunk)
+	if n > 0 {
+		if genErr := s.emit(s.chunk[:n]); genErr != nil {
+			s.err = genErr
+			return
+		}
+		s.total += int64(n)
+	}
+
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		if s.total == 0 {
+			s.err = ErrEmptySecret
+		} else {
+			s.err = io.EOF
+		}
+		secureZeroBytes(s.chunk)
+	case err != nil:
+		s.err = fmt.Errorf("shamir: failed to read secret: %w", err)
+		secureZeroBytes(s.chunk)
+	}
+}
+
+// emit evaluates a fresh random polynomial for every byte of chunk at each share's x-coordinate.
+func (s *splitter) emit(chunk []byte) error {
+	coeffs := make([][]byte, s.threshold)
+	coeffs[0] = chunk
+	defer func() {
+		for i := 1; i < len(coeffs); i++ {
+			secureZeroBytes(coeffs[i])
+			auditRelease(coeffs[i])
+		}
+		secureZeroBytes(chunk)
+	}()
+
+	for i := 1; i < s.threshold; i++ {
+		coeffs[i] = make([]byte, len(chunk))
+		auditTrack("stream.coefficient", coeffs[i])
+		if _, err := rand.Read(coeffs[i]); err != nil {
+			return fmt.Errorf("%w: %w", ErrEntropyUnavailable, err)
+		}
+	}
+
+	out := make([]byte, len(chunk))
+	for i := range s.pending {
+		gfPolyEvalSlice(out, coeffs, byte(i+1))
+		s.pending[i].Write(out)
+	}
+
+	return nil
+}
+
+// shareReader is one share stream returned by NewSplitter.
+type shareReader struct {
+	s   *splitter
+	idx int
+}
+
+func (r *shareReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf := &s.pending[r.idx]
+	for buf.Len() == 0 && s.err == nil {
+		s.fill()
+	}
+	if buf.Len() == 0 {
+		return 0, s.err
+	}
+
+	return buf.Read(p)
+}
+
+// NewJoiner returns a reader that reconstructs the secret from share streams
+// as it is read, processing the shares chunk by chunk.
+//
+// The share streams must use the Split format (x-coordinate followed by
+// y-values), such as those produced by NewSplitter. At least two streams
+// are required, and all streams must have the same length.
+func NewJoiner(shares []io.Reader) (io.Reader, error) {
+	if shares == nil {
+		return nil, ErrNilShares
+	}
+	if len(shares) < 2 {
+		return nil, ErrTooFewParts
+	}
+	for i, share := range shares {
+		if share == nil {
+			return nil, NewValidationError("share", i, "shamir: share cannot be nil")
+		}
+	}
+
+	return &joiner{
+		shares:  shares,
+		xCoords: make([]byte, len(shares)),
+		yCoords: make([][]byte, len(shares)),
+	}, nil
+}
+
+// joiner is the reader returned by NewJoiner.
+type joiner struct {
+	shares  []io.Reader
+	xCoords []byte
+	yCoords [][]byte
+	out     []byte // Reconstructed secret bytes not yet returned to the caller
+	off     int
+	started bool
+	err     error
+}
+
+func (j *joiner) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for j.off == len(j.out) && j.err == nil {
+		j.err = j.next()
+	}
+	if j.off == len(j.out) {
+		return 0, j.err
+	}
+
+	n := copy(p, j.out[j.off:])
+	j.off += n
+	return n, nil
+}
+
+// start reads and validates the x-coordinate of every share stream.
+func (j *joiner) start() error {
+	seen := make(map[byte]bool, len(j.shares))
+	for i, share := range j.shares {
+		var x [1]byte
+		if _, err := io.ReadFull(share, x[:]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return ErrTooShort
+			}
+			return fmt.Errorf("shamir: failed to read share %d: %w", i, err)
+		}
+		if x[0] == 0 {
+			return zeroXError(i)
+		}
+		if seen[x[0]] {
+			return NewValidationError("share", i, "shamir: duplicate share identifier detected")
+		}
+		seen[x[0]] = true
+		j.xCoords[i] = x[0]
+		j.yCoords[i] = make([]byte, streamChunkSize)
+	}
+	meta := CombineMetadata{Shares: len(j.shares), Indices: j.xCoords, SecretSize: -1}
+	if err := checkPolicy(meta, nil); err != nil {
+		return err
+	}
+	j.started = true
+	return nil
+}
+
+// next reconstructs the next chunk of the secret into j.out.
+func (j *joiner) next() error {
+	if !j.started {
+		if err := j.start(); err != nil {
+			return err
+		}
+	}
+
+	n := -1
+	for i, share := range j.shares {
+		m, err := io.ReadFull(share, j.yCoords[i])
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			j.wipe()
+			return fmt.Errorf("shamir: failed to read share %d: %w", i, err)
+		}
+		if n >= 0 && m != n {
+			j.wipe()
+			return ErrDifferentLengths
+		}
+		n = m
+	}
+
+	if n == 0 {
+		j.wipe()
+		return io.EOF
+	}
+
+	ys := make([][]byte, len(j.yCoords))
+	for i := range j.yCoords {
+		ys[i] = j.yCoords[i][:n]
+	}
+
+	secureZeroBytes(j.out)
+	if j.out == nil {
+		j.out = make([]byte, streamChunkSize)
+	}
+	j.out = j.out[:n]
+	j.off = 0
+	lagrangeInterpolateSlice(j.out, j.xCoords, ys, 0)
+
+	return nil
+}
+
+// wipe clears the per-share scratch buffers once the stream has ended.
+func (j *joiner) wipe() {
+	for _, y := range j.yCoords {
+		secureZeroBytes(y)
+	}
+	secureZeroBytes(j.out[:cap(j.out)])
+}
+
+// ShareSection locates a share stored inside a larger file, archive, or block device.
+type ShareSection struct {
+	ReaderAt io.ReaderAt // Underlying storage
+	Offset   int64       // Position of the share's x-coordinate byte
+	Length   int64       // Total share length, including the x-coordinate byte
+}
+
+// NewJoinerAt is like NewJoiner but reads each share directly from its section
+// of an io.ReaderAt, so shares kept inside archives or on block devices can be
+// combined without extracting them first. Section lengths are validated before
+// any share data is read.
+func NewJoinerAt(sections []ShareSection) (io.Reader, error) {
+	if sections == nil {
+		return nil, ErrNilShares
+	}
+	if len(sections) < 2 {
+		return nil, ErrTooFewParts
+	}
+
+	readers := make([]io.Reader, len(sections))
+	for i, section := range sections {
+		if section.ReaderAt == nil {
+			return nil, NewValidationError("share", i, "shamir: share cannot be nil")
+		}
+		if section.Offset < 0 {
+			return nil, NewValidationError("offset", i, "shamir: share offset cannot be negative")
+		}
+		if section.Length < 2 {
+			return nil, ErrTooShort
+		}
+		if section.Length != sections[0].Length {
+			return nil, ErrDifferentLengths
+		}
+		readers[i] = io.NewSectionReader(section.ReaderAt, section.Offset, section.Length)
+	}
+
+	return NewJoiner(readers)
+}
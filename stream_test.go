@@ -0,0 +1,209 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestStreamSplitJoin(t *testing.T) {
+	secret := make([]byte, 3*streamChunkSize+123)
+	for i := range secret {
+		secret[i] = byte(i * 7)
+	}
+
+	readers, err := NewSplitter(bytes.NewReader(secret), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Drain the share streams concurrently, as recommended for large inputs.
+	shares := make([][]byte, len(readers))
+	var wg sync.WaitGroup
+	for i, r := range readers {
+		wg.Add(1)
+		go func(i int, r io.Reader) {
+			defer wg.Done()
+			shares[i], _ = io.ReadAll(r)
+		}(i, r)
+	}
+	wg.Wait()
+
+	for i, share := range shares {
+		if len(share) != len(secret)+ShareOverhead {
+			t.Fatalf("share %d has length %d, expected %d", i, len(share), len(secret)+ShareOverhead)
+		}
+	}
+
+	t.Run("combine", func(t *testing.T) {
+		reconstructed, err := Combine([][]byte{shares[0], shares[2], shares[4]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(reconstructed, secret) {
+			t.Fatal("streamed shares did not combine to the original secret")
+		}
+	})
+
+	t.Run("joiner", func(t *testing.T) {
+		joiner, err := NewJoiner([]io.Reader{
+			bytes.NewReader(shares[1]),
+			bytes.NewReader(shares[3]),
+			bytes.NewReader(shares[4]),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		reconstructed, err := io.ReadAll(joiner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(reconstructed, secret) {
+			t.Fatal("joiner did not reconstruct the original secret")
+		}
+	})
+}
+
+func TestStreamSequentialReaders(t *testing.T) {
+	secret := []byte("sequentially drained secret")
+
+	readers, err := NewSplitter(bytes.NewReader(secret), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var shares []io.Reader
+	for _, r := range readers {
+		share, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares = append(shares, bytes.NewReader(share))
+	}
+
+	joiner, err := NewJoiner(shares[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	reconstructed, err := io.ReadAll(joiner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatalf("expected %q, got %q", secret, reconstructed)
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	t.Run("invalid params", func(t *testing.T) {
+		if _, err := NewSplitter(bytes.NewReader([]byte("x")), 3, 4); err == nil {
+			t.Fatal("expected error for threshold > parts")
+		}
+	})
+
+	t.Run("empty secret", func(t *testing.T) {
+		readers, err := NewSplitter(bytes.NewReader(nil), 3, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(readers[0]); err != ErrEmptySecret {
+			t.Fatalf("expected ErrEmptySecret, got %v", err)
+		}
+	})
+
+	t.Run("too few shares", func(t *testing.T) {
+		if _, err := NewJoiner([]io.Reader{bytes.NewReader([]byte{1, 2})}); err != ErrTooFewParts {
+			t.Fatalf("expected ErrTooFewParts, got %v", err)
+		}
+	})
+
+	t.Run("different lengths", func(t *testing.T) {
+		joiner, err := NewJoiner([]io.Reader{
+			bytes.NewReader([]byte{1, 2, 3}),
+			bytes.NewReader([]byte{2, 3}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(joiner); err != ErrDifferentLengths {
+			t.Fatalf("expected ErrDifferentLengths, got %v", err)
+		}
+	})
+
+	t.Run("duplicate shares", func(t *testing.T) {
+		joiner, err := NewJoiner([]io.Reader{
+			bytes.NewReader([]byte{1, 2, 3}),
+			bytes.NewReader([]byte{1, 4, 5}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(joiner); err == nil {
+			t.Fatal("expected error for duplicate shares")
+		}
+	})
+
+	t.Run("zero x-coordinate", func(t *testing.T) {
+		joiner, err := NewJoiner([]io.Reader{
+			bytes.NewReader([]byte{0, 2, 3}),
+			bytes.NewReader([]byte{1, 4, 5}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ve *ValidationError
+		if out, err := io.ReadAll(joiner); !errors.As(err, &ve) || ve.Field != "x-coordinate" || len(out) != 0 {
+			t.Fatalf("expected x-coordinate ValidationError and no output, got %q, %v", out, err)
+		}
+	})
+}
+
+func TestJoinerAt(t *testing.T) {
+	secret := []byte("archived secret stored inside a larger blob")
+
+	shares, err := Split(secret, 4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lay the shares out inside one "archive" with unrelated data in between.
+	var archive bytes.Buffer
+	sections := make([]ShareSection, 0, len(shares))
+	for _, share := range shares {
+		archive.WriteString("header padding")
+		sections = append(sections, ShareSection{Offset: int64(archive.Len()), Length: int64(len(share))})
+		archive.Write(share)
+	}
+	blob := bytes.NewReader(archive.Bytes())
+	for i := range sections {
+		sections[i].ReaderAt = blob
+	}
+
+	joiner, err := NewJoinerAt(sections[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	reconstructed, err := io.ReadAll(joiner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatalf("expected %q, got %q", secret, reconstructed)
+	}
+
+	t.Run("mismatched lengths", func(t *testing.T) {
+		bad := append([]ShareSection(nil), sections[:2]...)
+		bad[1].Length--
+		if _, err := NewJoinerAt(bad); err != ErrDifferentLengths {
+			t.Fatalf("expected ErrDifferentLengths, got %v", err)
+		}
+	})
+
+	t.Run("nil reader", func(t *testing.T) {
+		if _, err := NewJoinerAt([]ShareSection{sections[0], {Length: sections[0].Length}}); err == nil {
+			t.Fatal("expected error for nil ReaderAt")
+		}
+	})
+}
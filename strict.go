@@ -0,0 +1,93 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+)
+
+// CombineStrict reconstructs the secret like CombineWithOptions, but refuses
+// to trust an arbitrary subset of the shares. Combine interpolates whatever
+// it is given, so a corrupted or foreign share silently yields a wrong
+// secret; CombineStrict first checks that every share beyond the first
+// threshold lies on the polynomial the others define.
+//
+// If the shares disagree it returns an *InconsistentSharesError and no
+// secret. With at least two surplus shares the error lists the outliers
+// (up to half the surplus); with one the disagreement is detected but the
+// culprit cannot be identified. With exactly threshold shares there is
+// nothing to cross-check and CombineStrict behaves like CombineWithOptions.
+//
+// threshold is the threshold the shares were split with; enveloped shares
+// must record the same one. Unlike CombineWithCorrection, CombineStrict never
+// repairs shares: a mismatch always means some custodian's share needs
+// attention.
+func CombineStrict(parts [][]byte, threshold int, opts ...Option) ([]byte, error) {
+	if threshold < 2 || threshold > 255 {
+		return nil, NewValidationError("threshold", threshold, "shamir: threshold must be between 2 and 255")
+	}
+	o := newOptions(opts)
+	o.strict = threshold
+
+	if len(parts) > 0 && IsEnvelope(parts[0]) {
+		return combinePurposeBound(parts, o)
+	}
+	if _, err := checkLegacyFormat("CombineStrict", parts, "CombineStrict"); err != nil {
+		return nil, err
+	}
+	if err := checkRawLayout("CombineStrict", parts); err != nil {
+		return nil, err
+	}
+	return combine(parts, o.engine())
+}
+
+// checkConsistency checks that the shares beyond the first k agree with the
+// polynomial interpolated from the first k, and locates the outliers if not.
+func checkConsistency(parts [][]byte, xCoords []byte, k int) error {
+	n := len(parts)
+	if n < k {
+		return ErrInsufficientShares
+	}
+	if n == k {
+		return nil
+	}
+
+	secretLen := len(parts[0]) - ShareOverhead
+	weights := make([][]byte, n-k)
+	for j := range weights {
+		weights[j] = lagrangeBasis(xCoords[:k], xCoords[k+j])
+	}
+
+	// Predict each surplus share from the first k, one window at a time so
+	// the scratch memory stays bounded.
+	window := min(secretLen, strategyWindowSize)
+	predicted := make([]byte, window)
+	defer secureZeroBytes(predicted)
+	consistent := true
+	for start := 0; start < secretLen && consistent; start += window {
+		end := min(start+window, secretLen)
+		dst := predicted[:end-start]
+		for j := range weights {
+			clear(dst)
+			for i := 0; i < k; i++ {
+				gfMulAddSlice(dst, parts[i][ShareOverhead+start:ShareOverhead+end], weights[j][i])
+			}
+			if !bytes.Equal(dst, parts[k+j][ShareOverhead+start:ShareOverhead+end]) {
+				consistent = false
+				break
+			}
+		}
+	}
+	if consistent {
+		return nil
+	}
+
+	secret, outliers, err := correctErrors(parts, xCoords, k)
+	if errors.Is(err, ErrUncorrectable) {
+		return &InconsistentSharesError{}
+	}
+	if err != nil {
+		return err
+	}
+	secureZeroBytes(secret)
+	return &InconsistentSharesError{Outliers: outliers}
+}
@@ -0,0 +1,111 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCombineStrict(t *testing.T) {
+	secret := []byte("strictly reconstructed secret")
+	shares, err := Split(secret, 6, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{3, 4, 6} {
+		got, err := CombineStrict(shares[:n], 3)
+		if err != nil {
+			t.Fatalf("%d shares: %v", n, err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Fatalf("%d shares: reconstructed secret mismatch", n)
+		}
+	}
+
+	if _, err := CombineStrict(shares[:2], 3); !errors.Is(err, ErrInsufficientShares) {
+		t.Fatalf("expected ErrInsufficientShares, got %v", err)
+	}
+	if _, err := CombineStrict(shares, 1); err == nil {
+		t.Fatal("expected an error for threshold 1")
+	}
+}
+
+func TestCombineStrictOutliers(t *testing.T) {
+	secret := []byte("strictly reconstructed secret")
+	shares, err := Split(secret, 6, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := Split(bytes.Repeat([]byte{'x'}, len(secret)), 6, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		parts    [][]byte
+		outliers []int
+	}{
+		// A corrupted share among the first threshold is caught too.
+		{"corrupted first share", [][]byte{corruptShare(shares[0]), shares[1], shares[2], shares[3], shares[4]}, []int{0}},
+		{"foreign share", [][]byte{shares[0], shares[1], shares[2], shares[3], other[4]}, []int{4}},
+		{"two outliers", [][]byte{shares[0], other[1], shares[2], shares[3], shares[4], corruptShare(shares[5])}, nil},
+		{"one surplus share", [][]byte{shares[0], shares[1], shares[2], other[3]}, nil},
+	}
+	for _, tt := range tests {
+		got, err := CombineStrict(tt.parts, 3)
+		if got != nil {
+			t.Errorf("%s: returned a secret despite inconsistent shares", tt.name)
+		}
+		var ierr *InconsistentSharesError
+		if !errors.As(err, &ierr) || !errors.Is(err, ErrInconsistentShares) {
+			t.Errorf("%s: expected InconsistentSharesError, got %v", tt.name, err)
+			continue
+		}
+		if !slices.Equal(ierr.Outliers, tt.outliers) {
+			t.Errorf("%s: outliers %v, want %v", tt.name, ierr.Outliers, tt.outliers)
+		}
+	}
+}
+
+func TestCombineStrictEnvelopes(t *testing.T) {
+	secret := []byte("enveloped strict secret")
+	shares, err := SplitWithOptions(secret, WithParts(4), WithThreshold(2), WithPurpose("backup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CombineStrict(shares, 2, WithPurpose("backup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatal("reconstructed secret mismatch")
+	}
+	if _, err := CombineStrict(shares, 3); !errors.Is(err, ErrMismatchedShares) {
+		t.Fatalf("wrong threshold: expected ErrMismatchedShares, got %v", err)
+	}
+
+	s, err := ParseShare(shares[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Payload[0] ^= 1
+	tampered, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = CombineStrict([][]byte{shares[0], shares[1], shares[2], tampered}, 2)
+	var ierr *InconsistentSharesError
+	if !errors.As(err, &ierr) || !slices.Equal(ierr.Outliers, []int{3}) {
+		t.Fatalf("expected share 3 reported as outlier, got %v", err)
+	}
+}
+
+// corruptShare returns a copy of share with one payload byte changed.
+func corruptShare(share []byte) []byte {
+	out := bytes.Clone(share)
+	out[len(out)/2] ^= 0x5a
+	return out
+}
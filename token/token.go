@@ -0,0 +1,454 @@
+// Package token wraps shares with keys held on hardware tokens, such as a
+// YubiKey's PIV key management slot or a PKCS#11 key on a smart card or HSM.
+// Each share is encrypted to its custodian's token public key as soon as it
+// is generated, and can only be decrypted through the token, so a custodian
+// needs both the share file and the token (and its PIN) to contribute: two
+// factors per share holder.
+//
+// The dealer only needs the tokens' public keys. At combine time the private
+// operation runs on the token through a small interface, ECDHKey for
+// elliptic-curve keys or crypto.Decrypter for RSA keys, which the PIV and
+// PKCS#11 libraries implement or can be adapted to in a few lines (see the
+// README); this package depends on none of them.
+//
+// Wrapped share format, in the versioned layout of the parent package:
+//
+//	[3 bytes]  magic 0x00 'S' 'Y'
+//	[1 byte]   format version (1)
+//	[16 bytes] set ID of the split
+//	[1 byte]   share index
+//	[32 bytes] key fingerprint, SHA-256 of the PKIX public key
+//	[1 byte]   key type: 1 ECDH, 2 RSA-OAEP
+//	[2 bytes]  wrapped key length, big-endian
+//	[n bytes]  ephemeral public key (ECDH) or encrypted share key (RSA-OAEP)
+//	[m bytes]  AES-256-GCM ciphertext and tag of the enveloped share
+//
+// For ECDH keys the share key is derived with HKDF-SHA256 from the shared
+// secret, salted with the ephemeral and token public keys. For RSA keys a
+// random share key is encrypted with RSA-OAEP and SHA-256. Each share key
+// encrypts a single share, so a fixed nonce is used; the header is
+// authenticated.
+package token
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+var (
+	// ErrInvalidShare indicates a wrapped share that is malformed, tampered
+	// with, or whose unwrapped contents do not match its header.
+	ErrInvalidShare = errors.New("token: invalid wrapped share")
+
+	// ErrNoKey indicates that no key was given for a wrapped share's key
+	// fingerprint.
+	ErrNoKey = errors.New("token: no key for wrapped share")
+
+	// ErrUnsupportedKey indicates a public key of a type or size this package
+	// cannot wrap shares to, or a key that implements neither ECDHKey nor
+	// crypto.Decrypter.
+	ErrUnsupportedKey = errors.New("token: unsupported key")
+)
+
+// Key is a private key held on a hardware token. Elliptic-curve keys must
+// also implement ECDHKey, and RSA keys crypto.Decrypter with RSA-OAEP.
+type Key interface {
+	Public() crypto.PublicKey
+}
+
+// ECDHKey is an elliptic-curve key on a token that can compute ECDH shared
+// secrets, such as a PIV key management slot (9d). SharedKey returns the
+// x-coordinate of the shared point, as PIV and PKCS#11's CKM_ECDH1_DERIVE do.
+type ECDHKey interface {
+	Key
+	SharedKey(peer *ecdsa.PublicKey) ([]byte, error)
+}
+
+// wrappedMagic identifies a token-wrapped share.
+var wrappedMagic = [3]byte{0x00, 'S', 'Y'}
+
+const (
+	formatVersion   = 1
+	fingerprintSize = sha256.Size
+	headerSize      = len(wrappedMagic) + 1 + len(shamir.SetID{}) + 1 + fingerprintSize + 1 + 2
+	shareKeySize    = 32
+	hkdfInfo        = "go-shamir token share v1"
+	minRSABits      = 2048
+)
+
+// Key types.
+const (
+	keyECDH = 1
+	keyRSA  = 2
+)
+
+// header is the decoded header of a wrapped share.
+type header struct {
+	set         shamir.SetID
+	index       byte
+	fingerprint [fingerprintSize]byte
+	keyType     byte
+	wrappedKey  []byte
+	size        int // Encoded length
+}
+
+func (h *header) marshal() []byte {
+	out := make([]byte, 0, headerSize+len(h.wrappedKey))
+	out = append(out, wrappedMagic[:]...)
+	out = append(out, formatVersion)
+	out = append(out, h.set[:]...)
+	out = append(out, h.index)
+	out = append(out, h.fingerprint[:]...)
+	out = append(out, h.keyType)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(h.wrappedKey)))
+	return append(out, h.wrappedKey...)
+}
+
+func parseHeader(wrapped []byte) (*header, error) {
+	if len(wrapped) < headerSize || !bytes.HasPrefix(wrapped, wrappedMagic[:]) {
+		return nil, ErrInvalidShare
+	}
+	if v := wrapped[len(wrappedMagic)]; v != formatVersion {
+		return nil, fmt.Errorf("%w: wrapped share version %d", shamir.ErrUnsupportedVersion, v)
+	}
+	h := &header{}
+	p := len(wrappedMagic) + 1
+	p += copy(h.set[:], wrapped[p:])
+	h.index = wrapped[p]
+	p++
+	p += copy(h.fingerprint[:], wrapped[p:])
+	h.keyType = wrapped[p]
+	n := int(binary.BigEndian.Uint16(wrapped[p+1:]))
+	p += 3
+	if n == 0 || len(wrapped) <= p+n {
+		return nil, ErrInvalidShare
+	}
+	h.wrappedKey = wrapped[p : p+n]
+	h.size = p + n
+	return h, nil
+}
+
+// publicKey is a token public key this package can wrap shares to.
+type publicKey struct {
+	keyType     byte
+	ecdh        *ecdh.PublicKey
+	rsa         *rsa.PublicKey
+	fingerprint [fingerprintSize]byte
+}
+
+// parsePublicKey accepts NIST P-256, P-384 and P-521 keys, as *ecdsa.PublicKey
+// or *ecdh.PublicKey, and RSA keys of at least 2048 bits.
+func parsePublicKey(pub crypto.PublicKey) (*publicKey, error) {
+	k := &publicKey{}
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		key, err := pub.ECDH()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUnsupportedKey, err)
+		}
+		k.keyType, k.ecdh = keyECDH, key
+	case *ecdh.PublicKey:
+		if pub.Curve() == ecdh.X25519() {
+			return nil, fmt.Errorf("%w: X25519 keys are not token keys; use shamir.X25519Recipient", ErrUnsupportedKey)
+		}
+		k.keyType, k.ecdh = keyECDH, pub
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("%w: RSA key of %d bits, need at least %d", ErrUnsupportedKey, pub.N.BitLen(), minRSABits)
+		}
+		k.keyType, k.rsa = keyRSA, pub
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, pub)
+	}
+
+	// ECDSA and ECDH forms of the same key marshal, and so fingerprint, alike.
+	var der []byte
+	var err error
+	if k.ecdh != nil {
+		der, err = x509.MarshalPKIXPublicKey(k.ecdh)
+	} else {
+		der, err = x509.MarshalPKIXPublicKey(k.rsa)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedKey, err)
+	}
+	k.fingerprint = sha256.Sum256(der)
+	return k, nil
+}
+
+// Fingerprint returns the hex SHA-256 fingerprint of a token public key's
+// PKIX encoding, as recorded in the shares wrapped to it.
+func Fingerprint(pub crypto.PublicKey) (string, error) {
+	k, err := parsePublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(k.fingerprint[:]), nil
+}
+
+// KeyID returns the fingerprint of the key that wrapped a share, so that it
+// can be routed to the custodian holding that token.
+func KeyID(wrapped []byte) (string, error) {
+	h, err := parseHeader(wrapped)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.fingerprint[:]), nil
+}
+
+// recipient adapts a token public key to shamir.Recipient for SplitEncrypted.
+type recipient struct {
+	key *publicKey
+}
+
+// NewRecipient returns a shamir.Recipient that wraps shares to a token public
+// key, for use with shamir.SplitEncrypted alongside other recipients.
+func NewRecipient(pub crypto.PublicKey) (shamir.Recipient, error) {
+	key, err := parsePublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return recipient{key: key}, nil
+}
+
+func (r recipient) EncryptShare(share []byte) ([]byte, error) {
+	info, err := shamir.InspectShare(share)
+	if err != nil {
+		return nil, err
+	}
+	h := &header{set: info.SetID, index: info.Index, fingerprint: r.key.fingerprint, keyType: r.key.keyType}
+
+	var aead cipher.AEAD
+	switch r.key.keyType {
+	case keyECDH:
+		ephemeral, err := r.key.ecdh.Curve().GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("token: failed to generate ephemeral key: %w", err)
+		}
+		shared, err := ephemeral.ECDH(r.key.ecdh)
+		if err != nil {
+			return nil, err
+		}
+		defer clear(shared)
+		h.wrappedKey = ephemeral.PublicKey().Bytes()
+		aead, err = ecdhAEAD(shared, h.wrappedKey, r.key.ecdh.Bytes())
+		if err != nil {
+			return nil, err
+		}
+	case keyRSA:
+		key := make([]byte, shareKeySize)
+		defer clear(key)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("token: failed to generate share key: %w", err)
+		}
+		if h.wrappedKey, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, r.key.rsa, key, nil); err != nil {
+			return nil, fmt.Errorf("token: RSA-OAEP encryption failed: %w", err)
+		}
+		if aead, err = shareAEAD(key); err != nil {
+			return nil, err
+		}
+	}
+
+	out := h.marshal()
+	return aead.Seal(out, make([]byte, aead.NonceSize()), share, out), nil
+}
+
+// Split splits secret into one share per token public key, threshold of
+// which recover it, and returns each share wrapped to its key: the i-th
+// wrapped share is for pubs[i]. Each plaintext share is wiped as soon as it
+// is wrapped.
+func Split(secret []byte, threshold int, pubs []crypto.PublicKey) ([][]byte, error) {
+	recipients := make([]shamir.Recipient, len(pubs))
+	for i, pub := range pubs {
+		r, err := NewRecipient(pub)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+		recipients[i] = r
+	}
+	return shamir.SplitEncrypted(secret, len(pubs), threshold, recipients)
+}
+
+// Unwrap decrypts one wrapped share through the token holding key, returning
+// the enveloped share. The key's fingerprint must match the one recorded in
+// the share. Errors from the token, such as a wrong or blocked PIN, are
+// returned as they are.
+func Unwrap(wrapped []byte, key Key) ([]byte, error) {
+	h, err := parseHeader(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := parsePublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	if pub.fingerprint != h.fingerprint {
+		return nil, fmt.Errorf("%w: share is wrapped to %x", ErrNoKey, h.fingerprint)
+	}
+	if pub.keyType != h.keyType {
+		return nil, ErrInvalidShare
+	}
+
+	var aead cipher.AEAD
+	switch h.keyType {
+	case keyECDH:
+		k, ok := key.(ECDHKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T does not implement ECDHKey", ErrUnsupportedKey, key)
+		}
+		peer, err := ecdsaPublicKey(pub.ecdh.Curve(), h.wrappedKey)
+		if err != nil {
+			return nil, err
+		}
+		shared, err := k.SharedKey(peer)
+		if err != nil {
+			return nil, err
+		}
+		defer clear(shared)
+		if aead, err = ecdhAEAD(shared, h.wrappedKey, pub.ecdh.Bytes()); err != nil {
+			return nil, err
+		}
+	case keyRSA:
+		k, ok := key.(crypto.Decrypter)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T does not implement crypto.Decrypter", ErrUnsupportedKey, key)
+		}
+		shareKey, err := k.Decrypt(rand.Reader, h.wrappedKey, &rsa.OAEPOptions{Hash: crypto.SHA256})
+		if err != nil {
+			return nil, err
+		}
+		defer clear(shareKey)
+		if len(shareKey) != shareKeySize {
+			return nil, ErrInvalidShare
+		}
+		if aead, err = shareAEAD(shareKey); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidShare
+	}
+
+	share, err := aead.Open(nil, make([]byte, aead.NonceSize()), wrapped[h.size:], wrapped[:h.size])
+	if err != nil {
+		return nil, ErrInvalidShare
+	}
+	if s, err := shamir.ParseShare(share); err != nil || s.SetID != h.set || s.Index != h.index {
+		clear(share)
+		return nil, fmt.Errorf("%w: unwrapped share does not match its header", ErrInvalidShare)
+	}
+	return share, nil
+}
+
+// Combine unwraps shares with the given token keys, matched by fingerprint,
+// until it has as many as the threshold, and reconstructs the secret. Shares
+// whose token is not given, or whose token refuses, are skipped, so the
+// secret is recovered as long as a threshold of tokens cooperate. If too few
+// shares can be unwrapped, the error matches shamir.ErrInsufficientShares and
+// includes the reason for each skipped share.
+func Combine(wrapped [][]byte, keys []Key) ([]byte, error) {
+	byID := make(map[string]Key, len(keys))
+	for i, key := range keys {
+		id, err := Fingerprint(key.Public())
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+		byID[id] = key
+	}
+
+	var shares [][]byte
+	defer func() {
+		for _, share := range shares {
+			clear(share)
+		}
+	}()
+	var failures []error
+	threshold := 0
+	for i, w := range wrapped {
+		if threshold > 0 && len(shares) >= threshold {
+			break
+		}
+		id, err := KeyID(w)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("share %d: %w", i, err))
+			continue
+		}
+		key, ok := byID[id]
+		if !ok {
+			failures = append(failures, fmt.Errorf("share %d: %w: %s", i, ErrNoKey, id))
+			continue
+		}
+		share, err := Unwrap(w, key)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("share %d: %w", i, err))
+			continue
+		}
+		shares = append(shares, share)
+		if threshold == 0 {
+			if s, err := shamir.ParseShare(share); err == nil {
+				threshold = s.Threshold
+			}
+		}
+	}
+
+	if need := max(threshold, 2); len(shares) < need {
+		err := fmt.Errorf("%w: unwrapped %d of %d shares, need %d", shamir.ErrInsufficientShares, len(shares), len(wrapped), need)
+		if len(failures) > 0 {
+			err = fmt.Errorf("%w: %w", err, errors.Join(failures...))
+		}
+		return nil, err
+	}
+	return shamir.CombineWithOptions(shares)
+}
+
+// ecdsaPublicKey converts an uncompressed point on curve, validated by
+// crypto/ecdh, to the *ecdsa.PublicKey ECDHKey takes.
+func ecdsaPublicKey(curve ecdh.Curve, point []byte) (*ecdsa.PublicKey, error) {
+	key, err := curve.NewPublicKey(point)
+	if err != nil {
+		return nil, ErrInvalidShare
+	}
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, ErrInvalidShare
+	}
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, ErrInvalidShare
+	}
+	return pub.(*ecdsa.PublicKey), nil
+}
+
+// ecdhAEAD derives the AES-256-GCM instance for one share wrapped with ECDH.
+func ecdhAEAD(shared, ephemeralKey, tokenKey []byte) (cipher.AEAD, error) {
+	salt := append(append([]byte(nil), ephemeralKey...), tokenKey...)
+	key, err := hkdf.Key(sha256.New, shared, salt, hkdfInfo, shareKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("token: failed to derive share key: %w", err)
+	}
+	defer clear(key)
+	return shareAEAD(key)
+}
+
+// shareAEAD returns AES-256-GCM under a share key.
+func shareAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,174 @@
+package token
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// softKey is an in-process ECDSA key standing in for a PIV slot.
+type softKey struct {
+	key     *ecdsa.PrivateKey
+	blocked bool // Fail every call, like a token whose PIN is blocked
+}
+
+var errPINBlocked = errors.New("PIN blocked")
+
+func newSoftKey(t *testing.T, curve elliptic.Curve) *softKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &softKey{key: key}
+}
+
+func (k *softKey) Public() crypto.PublicKey { return &k.key.PublicKey }
+
+func (k *softKey) SharedKey(peer *ecdsa.PublicKey) ([]byte, error) {
+	if k.blocked {
+		return nil, errPINBlocked
+	}
+	priv, err := k.key.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := peer.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	return priv.ECDH(pub)
+}
+
+func custodianKeys(t *testing.T) []Key {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []Key{
+		newSoftKey(t, elliptic.P256()),
+		newSoftKey(t, elliptic.P384()),
+		rsaKey, // *rsa.PrivateKey is a crypto.Decrypter, like a PKCS#11 RSA key
+		newSoftKey(t, elliptic.P256()),
+	}
+}
+
+func publicKeys(keys []Key) []crypto.PublicKey {
+	pubs := make([]crypto.PublicKey, len(keys))
+	for i, key := range keys {
+		pubs[i] = key.Public()
+	}
+	return pubs
+}
+
+func TestSplitCombine(t *testing.T) {
+	keys := custodianKeys(t)
+	secret := []byte("two factors per custodian")
+
+	wrapped, err := Split(secret, 3, publicKeys(keys))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, w := range wrapped {
+		want, _ := Fingerprint(keys[i].Public())
+		if id, err := KeyID(w); err != nil || id != want {
+			t.Fatalf("share %d: KeyID %q, %v", i, id, err)
+		}
+		if bytes.Contains(w, secret) {
+			t.Fatalf("share %d contains the secret", i)
+		}
+		if info, err := shamir.InspectShare(w); err != nil || info.Format != "token-wrapped" {
+			t.Fatalf("share %d: format %q, %v", i, info.Format, err)
+		}
+	}
+
+	for _, available := range [][]Key{keys, keys[1:], {keys[3], keys[0], keys[2]}} {
+		got, err := Combine(wrapped, available)
+		if err != nil || !bytes.Equal(got, secret) {
+			t.Fatalf("combine with %d tokens: %v", len(available), err)
+		}
+	}
+
+	share, err := Unwrap(wrapped[2], keys[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := shamir.ParseShare(share); err != nil || s.Index != 3 {
+		t.Fatalf("unwrapped share: %v", err)
+	}
+}
+
+func TestCombineMissingTokens(t *testing.T) {
+	keys := custodianKeys(t)
+	wrapped, err := Split([]byte("secret"), 3, publicKeys(keys))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One custodian mistyped their PIN too often; another left their token
+	// at home. Two tokens are not enough.
+	keys[0].(*softKey).blocked = true
+	_, err = Combine(wrapped, keys[:3])
+	if !errors.Is(err, shamir.ErrInsufficientShares) || !errors.Is(err, errPINBlocked) || !errors.Is(err, ErrNoKey) {
+		t.Fatalf("expected ErrInsufficientShares naming both failures, got %v", err)
+	}
+}
+
+func TestUnwrapErrors(t *testing.T) {
+	keys := custodianKeys(t)
+	wrapped, err := Split([]byte("secret"), 2, publicKeys(keys))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Unwrap(wrapped[0], keys[3]); !errors.Is(err, ErrNoKey) {
+		t.Errorf("wrong token: expected ErrNoKey, got %v", err)
+	}
+	for _, i := range []int{0, 2} {
+		for _, offset := range []int{20, headerSize + 5, len(wrapped[i]) - 1} {
+			tampered := bytes.Clone(wrapped[i])
+			tampered[offset] ^= 1
+			_, err := Unwrap(tampered, keys[i])
+			// A tampered RSA-OAEP key is refused by the token itself.
+			if err == nil || i == 0 && !errors.Is(err, ErrInvalidShare) {
+				t.Errorf("share %d, offset %d: expected ErrInvalidShare, got %v", i, offset, err)
+			}
+		}
+	}
+	if _, err := Unwrap(wrapped[0][:headerSize], keys[0]); !errors.Is(err, ErrInvalidShare) {
+		t.Errorf("truncated: expected ErrInvalidShare, got %v", err)
+	}
+
+	small, _ := rsa.GenerateKey(rand.Reader, 1024)
+	x25519, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	for name, pub := range map[string]crypto.PublicKey{"RSA-1024": &small.PublicKey, "X25519": x25519.PublicKey(), "string": "key"} {
+		if _, err := NewRecipient(pub); !errors.Is(err, ErrUnsupportedKey) {
+			t.Errorf("%s: expected ErrUnsupportedKey, got %v", name, err)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	key := newSoftKey(t, elliptic.P256())
+	fromECDSA, err := Fingerprint(&key.key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, _ := key.key.PublicKey.ECDH()
+	fromECDH, err := Fingerprint(pub)
+	if err != nil || fromECDH != fromECDSA {
+		t.Errorf("fingerprints differ: %s and %s (%v)", fromECDSA, fromECDH, err)
+	}
+	if len(fromECDSA) != 64 || strings.ToLower(fromECDSA) != fromECDSA {
+		t.Errorf("unexpected fingerprint %q", fromECDSA)
+	}
+}
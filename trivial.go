@@ -0,0 +1,67 @@
+package shamir
+
+// Trivial thresholds.
+//
+// A threshold of 1 is not secret sharing: the polynomial is the constant
+// secret, so every share's payload is the secret itself in the clear. It is
+// still useful to callers that drive every policy, including "any one of n
+// custodians" and "a single custodian", through the same split, envelope and
+// combine code, so it is available behind an explicit opt-in on both sides.
+
+// WithAllowTrivialThreshold permits a threshold of 1 in SplitWithOptions and
+// the other option-taking splits, with any number of parts from 1 to 255, and
+// lets CombineWithOptions and CombineContext reconstruct from a single share.
+//
+// Each share of such a split IS the secret: whoever holds any one of them
+// holds the secret, and storing or sending a share needs the same care as the
+// secret. Enveloped shares record their threshold of 1, and every combine
+// refuses them unless this option is set. Raw shares record no threshold, so
+// with this option a lone raw share is taken to be from a threshold-1 split;
+// a share of any other split then yields a wrong secret rather than an error.
+func WithAllowTrivialThreshold(allow bool) Option {
+	return func(o *options) { o.allowTrivial = allow }
+}
+
+// validateSplitParams validates the split parameters in o for parts shares,
+// accepting a threshold of 1 when WithAllowTrivialThreshold was given.
+func (o *options) validateSplitParams(secret []byte, parts int) error {
+	if !o.allowTrivial || o.threshold != 1 {
+		return validateSplitParams(secret, parts, o.threshold)
+	}
+	if len(secret) == 0 {
+		return ErrEmptySecret
+	}
+	if parts < 1 || parts > 255 {
+		return NewValidationError("parts", parts, "shamir: parts must be between 1 and 255")
+	}
+	return nil
+}
+
+// minParts returns the fewest shares a combine accepts.
+func (e engine) minParts() int {
+	if e.allowTrivial {
+		return 1
+	}
+	return 2
+}
+
+// validateTrivialShare validates a lone share combined under
+// WithAllowTrivialThreshold.
+func validateTrivialShare(share []byte) error {
+	if share == nil {
+		return NewValidationError("share", 0, "shamir: share cannot be nil")
+	}
+	if len(share) < 2 {
+		return ErrTooShort
+	}
+	return nil
+}
+
+// checkTrivialThreshold refuses enveloped shares of a threshold-1 split
+// unless WithAllowTrivialThreshold was given.
+func checkTrivialThreshold(shares []*Share, eng engine) error {
+	if shares[0].Threshold == 1 && !eng.allowTrivial {
+		return NewValidationError("threshold", 1, "shamir: shares of a threshold-1 split need WithAllowTrivialThreshold")
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestTrivialThreshold(t *testing.T) {
+	secret := []byte("one custodian is enough")
+	trivial := WithAllowTrivialThreshold(true)
+
+	for _, parts := range []int{1, 3} {
+		shares, err := SplitWithOptions(secret, WithParts(parts), WithThreshold(1), trivial)
+		if err != nil {
+			t.Fatalf("(%d,1): %v", parts, err)
+		}
+		if len(shares) != parts {
+			t.Fatalf("(%d,1): got %d shares", parts, len(shares))
+		}
+		for i, share := range shares {
+			// Documented: every share carries the secret itself.
+			if !bytes.Equal(share[ShareOverhead:], secret) {
+				t.Errorf("(%d,1): share %d does not hold the secret", parts, i)
+			}
+			got, err := CombineWithOptions([][]byte{share}, trivial)
+			if err != nil || !bytes.Equal(got, secret) {
+				t.Errorf("(%d,1): combine share %d alone: %v", parts, i, err)
+			}
+		}
+		if parts > 1 {
+			if got, err := Combine(shares); err != nil || !bytes.Equal(got, secret) {
+				t.Errorf("(%d,1): combine all: %v", parts, err)
+			}
+		}
+	}
+
+	integrity, err := SplitWithOptions(secret, WithParts(2), WithThreshold(1), WithIntegrity(true), trivial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := CombineWithOptions(integrity[1:], WithIntegrity(true), trivial); err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("integrity share alone: %v", err)
+	}
+}
+
+func TestTrivialThresholdEnveloped(t *testing.T) {
+	secret := []byte("break-glass key")
+	trivial := WithAllowTrivialThreshold(true)
+	shares, err := SplitWithOptions(secret, WithParts(3), WithThreshold(1), WithLabels("alice", "bob", "carol"), trivial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := ParseShare(shares[1]); err != nil || s.Threshold != 1 || s.Label != "bob" {
+		t.Fatalf("envelope: %v", err)
+	}
+	if got, err := CombineWithOptions(shares[2:], trivial); err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("combine one enveloped share: %v", err)
+	}
+
+	// Combining threshold-1 envelopes needs the opt-in too.
+	var verr *ValidationError
+	if _, err := CombineWithOptions(shares[2:]); !errors.As(err, &verr) || verr.Field != "threshold" {
+		t.Errorf("one share without opt-in: expected a ValidationError, got %v", err)
+	}
+	if _, err := Combine(shares); !errors.As(err, &verr) || verr.Field != "threshold" {
+		t.Errorf("Combine: expected a ValidationError, got %v", err)
+	}
+}
+
+func TestTrivialThresholdOptIn(t *testing.T) {
+	var verr *ValidationError
+	if _, err := SplitWithOptions([]byte("s"), WithParts(3), WithThreshold(1)); !errors.As(err, &verr) || verr.Field != "threshold" {
+		t.Errorf("threshold 1 without opt-in: expected a ValidationError, got %v", err)
+	}
+	if _, err := SplitWithOptions([]byte("s"), WithParts(1), WithThreshold(2), WithAllowTrivialThreshold(true)); !errors.As(err, &verr) {
+		t.Errorf("threshold 2 of 1 part: expected a ValidationError, got %v", err)
+	}
+	if _, err := SplitWithOptions([]byte("s"), WithParts(0), WithThreshold(1), WithAllowTrivialThreshold(true)); !errors.As(err, &verr) || verr.Field != "parts" {
+		t.Errorf("no parts: expected a ValidationError, got %v", err)
+	}
+
+	// A lone raw share still needs the opt-in.
+	shares, _ := Split([]byte("s"), 3, 2)
+	if _, err := CombineWithOptions(shares[:1]); !errors.Is(err, ErrTooFewParts) {
+		t.Errorf("lone share without opt-in: expected ErrTooFewParts, got %v", err)
+	}
+}
@@ -29,6 +29,36 @@ func validateSplitParams(secret []byte, parts, threshold int) error {
 // validateCombineParams validates the parameters for combining shares.
 // Performs comprehensive validation of share format and consistency.
 func validateCombineParams(shares [][]byte) error {
+	if err := validateCombineLayout(shares); err != nil {
+		return err
+	}
+	
+	// Check for zero and duplicate x-coordinates (share identifiers)
+	var xCoords [256]bool
+	for i, share := range shares {
+		xCoord := share[0]
+		if xCoord == 0 {
+			return zeroXError(i)
+		}
+		if xCoords[xCoord] {
+			return NewValidationError("share", i, "shamir: duplicate share identifier detected")
+		}
+		xCoords[xCoord] = true
+	}
+	
+	return nil
+}
+
+// zeroXError reports share i at x = 0, where the polynomial holds the secret:
+// its payload would be returned as the secret instead of being interpolated.
+func zeroXError(i int) error {
+	return NewValidationError("x-coordinate", i, "shamir: x-coordinate cannot be zero")
+}
+
+// validateCombineLayout checks the share count and lengths for combining,
+// everything validateCombineParams checks except that the x-coordinates are
+// distinct.
+func validateCombineLayout(shares [][]byte) error {
 	if shares == nil {
 		return ErrNilShares
 	}
@@ -53,16 +83,6 @@ func validateCombineParams(shares [][]byte) error {
 		}
 	}
 	
-	// Check for duplicate x-coordinates (share identifiers)
-	xCoords := make(map[byte]bool, len(shares))
-	for i, share := range shares {
-		xCoord := share[0]
-		if xCoords[xCoord] {
-			return NewValidationError("share", i, "shamir: duplicate share identifier detected")
-		}
-		xCoords[xCoord] = true
-	}
-	
 	return nil
 }
 
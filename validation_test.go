@@ -121,6 +121,14 @@ func TestValidateCombineParams(t *testing.T) {
 			},
 			wantErr: &ValidationError{},
 		},
+		{
+			name: "zero x-coordinate",
+			shares: [][]byte{
+				{0, 10, 20, 30},
+				{1, 15, 25, 35},
+			},
+			wantErr: &ValidationError{},
+		},
 		{
 			name: "nil share",
 			shares: [][]byte{
@@ -0,0 +1,65 @@
+package shamir
+
+import (
+	"fmt"
+	"time"
+)
+
+// Share validity windows.
+//
+// Rotation policies often require that shares from a retired split are no
+// longer honoured, even by a quorum of custodians who kept theirs. A split can
+// record a validity window in every share's envelope, and every combine of
+// enveloped shares in this package refuses shares outside it unless the caller
+// explicitly overrides the check with WithAllowExpired.
+
+// WithValidity records a validity window in every share's envelope: the shares
+// may be combined from notBefore until notAfter inclusive, to the second. A
+// zero time leaves that side of the window open. SplitWithOptions emits
+// enveloped shares when a window is set, and the other option-taking splits
+// that emit envelopes (SealSplit, SplitEncrypted, SplitPlanned, ...) record it
+// too.
+//
+// The window is enforced against the local clock of whoever combines, so it
+// limits honest use of stale shares; it cannot stop custodians who collude
+// and edit their shares.
+func WithValidity(notBefore, notAfter time.Time) Option {
+	return func(o *options) {
+		o.notBefore = notBefore.UTC().Truncate(time.Second)
+		o.notAfter = notAfter.UTC().Truncate(time.Second)
+	}
+}
+
+// WithAllowExpired makes CombineWithOptions and CombineContext combine shares
+// outside their validity window instead of failing with ErrShareExpired or
+// ErrShareNotYetValid, as break-glass recovery of a retired split may need.
+// Policy hooks still see the window in CombineMetadata.
+func WithAllowExpired(allow bool) Option {
+	return func(o *options) { o.allowExpired = allow }
+}
+
+// hasValidity reports whether WithValidity set a window.
+func (o *options) hasValidity() bool {
+	return !o.notBefore.IsZero() || !o.notAfter.IsZero()
+}
+
+// checkValidityWindow validates the window configured by WithValidity.
+func (o *options) checkValidityWindow() error {
+	if !o.notBefore.IsZero() && !o.notAfter.IsZero() && o.notAfter.Before(o.notBefore) {
+		return NewValidationError("validity", int(o.notAfter.Sub(o.notBefore)/time.Second), "shamir: validity window ends before it starts")
+	}
+	return nil
+}
+
+// checkValidity checks that now lies within every share's validity window.
+func checkValidity(shares []*Share, now time.Time) error {
+	for i, s := range shares {
+		if !s.NotBefore.IsZero() && now.Before(s.NotBefore) {
+			return fmt.Errorf("share %d: %w until %s", i, ErrShareNotYetValid, s.NotBefore.Format(time.RFC3339))
+		}
+		if !s.NotAfter.IsZero() && now.After(s.NotAfter.Add(time.Second-1)) {
+			return fmt.Errorf("share %d: %w at %s", i, ErrShareExpired, s.NotAfter.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
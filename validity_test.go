@@ -0,0 +1,109 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidityWindow(t *testing.T) {
+	secret := []byte("rotated quarterly")
+	now := time.Now()
+
+	split := func(notBefore, notAfter time.Time) [][]byte {
+		t.Helper()
+		shares, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithValidity(notBefore, notAfter))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return shares
+	}
+
+	current := split(now.Add(-time.Hour), now.Add(time.Hour))
+	s, err := ParseShare(current[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.NotBefore.Equal(now.Add(-time.Hour).Truncate(time.Second)) || !s.NotAfter.Equal(now.Add(time.Hour).Truncate(time.Second)) {
+		t.Fatalf("window not recorded: %v to %v", s.NotBefore, s.NotAfter)
+	}
+	if got, err := Combine(current[:2]); err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("current shares: %v", err)
+	}
+
+	expired := split(time.Time{}, now.Add(-time.Hour))
+	if _, err := Combine(expired[:2]); !errors.Is(err, ErrShareExpired) {
+		t.Errorf("expired shares: expected ErrShareExpired, got %v", err)
+	}
+	if _, err := CombineWithOptions(expired[:2]); !errors.Is(err, ErrShareExpired) {
+		t.Errorf("CombineWithOptions: expected ErrShareExpired, got %v", err)
+	}
+	if got, err := CombineWithOptions(expired[:2], WithAllowExpired(true)); err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("override: %v", err)
+	}
+
+	future := split(now.Add(time.Hour), time.Time{})
+	if _, err := Combine(future[1:]); !errors.Is(err, ErrShareNotYetValid) {
+		t.Errorf("future shares: expected ErrShareNotYetValid, got %v", err)
+	}
+
+	// One stale share spoils the combine even if the others are current.
+	if _, err := Combine([][]byte{current[0], mustRelabelWindow(t, current[1], now.Add(-time.Minute))}); !errors.Is(err, ErrShareExpired) {
+		t.Errorf("one expired share: expected ErrShareExpired, got %v", err)
+	}
+
+	var verr *ValidationError
+	if _, err := SplitWithOptions(secret, WithParts(3), WithThreshold(2), WithValidity(now, now.Add(-time.Hour))); !errors.As(err, &verr) || verr.Field != "validity" {
+		t.Errorf("inverted window: expected a ValidationError, got %v", err)
+	}
+}
+
+// mustRelabelWindow returns share with its validity window ending at notAfter.
+func mustRelabelWindow(t *testing.T, share []byte, notAfter time.Time) []byte {
+	t.Helper()
+	s, err := ParseShare(share)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.NotAfter = notAfter
+	out, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestValidityMetadata(t *testing.T) {
+	notBefore := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	shares, err := SplitWithOptions([]byte("k"), WithParts(2), WithThreshold(2), WithValidity(notBefore, notAfter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s Share
+	if err := s.UnmarshalBinary(shares[0]); err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Share
+	if err := decoded.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.NotBefore.Equal(notBefore) || !decoded.NotAfter.Equal(notAfter) {
+		t.Errorf("JSON round trip: %v to %v", decoded.NotBefore, decoded.NotAfter)
+	}
+
+	var seen CombineMetadata
+	hook := func(meta CombineMetadata) error { seen = meta; return nil }
+	if _, err := CombineWithOptions(shares, WithPolicyHook(hook)); err != nil {
+		t.Fatal(err)
+	}
+	if !seen.NotBefore.Equal(notBefore) || !seen.NotAfter.Equal(notAfter) {
+		t.Errorf("policy hook saw %v to %v", seen.NotBefore, seen.NotAfter)
+	}
+}
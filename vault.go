@@ -0,0 +1,176 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"io"
+)
+
+// HashiCorp Vault compatibility.
+//
+// Vault's shamir package differs from this package in two ways: the
+// x-coordinate is appended to each share instead of prefixed, and the
+// arithmetic is done in GF(256) with the AES polynomial x⁸+x⁴+x³+x+1 (0x11b)
+// instead of 0x11d. Vault also draws the x-coordinates at random rather than
+// numbering them 1..n. SplitVault and CombineVault reproduce that format byte
+// for byte, so existing Vault unseal keys can be combined here and vice versa.
+// Vault shares cannot be mixed with shares from Split.
+
+// SplitVault splits a secret into shares in HashiCorp Vault's format: each
+// share is len(secret)+1 bytes, [y-values...][x-coordinate].
+func SplitVault(secret []byte, parts, threshold int) ([][]byte, error) {
+	return splitVault(secret, parts, threshold, rand.Reader)
+}
+
+// splitVault implements SplitVault with an explicit randomness source.
+func splitVault(secret []byte, parts, threshold int, rng io.Reader) ([][]byte, error) {
+	if err := validateSplitParams(secret, parts, threshold); err != nil {
+		return nil, err
+	}
+
+	xCoords, err := randomXCoords(parts, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read every byte's threshold-1 random coefficients upfront, in the order
+	// Vault draws them, so no share is computed before the randomness is in.
+	random := make([]byte, len(secret)*(threshold-1))
+	auditTrack("vault.random", random)
+	release := func() {
+		secureZeroBytes(random)
+		auditRelease(random)
+	}
+	if err := readEntropy(nil, rng, 0, [][]byte{random}, release); err != nil {
+		return nil, err
+	}
+	defer release()
+
+	coeffs := make([]byte, threshold)
+	auditTrack("vault.coefficients", coeffs)
+	defer func() {
+		secureZeroBytes(coeffs)
+		auditRelease(coeffs)
+	}()
+
+	shares := make([][]byte, parts)
+	for i, x := range xCoords {
+		shares[i] = make([]byte, len(secret)+ShareOverhead)
+		shares[i][len(secret)] = x
+	}
+
+	for byteIdx, b := range secret {
+		coeffs[0] = b
+		copy(coeffs[1:], random[byteIdx*(threshold-1):])
+		for i, x := range xCoords {
+			shares[i][byteIdx] = vaultPolyEval(coeffs, x)
+		}
+	}
+
+	return shares, nil
+}
+
+// CombineVault reconstructs a secret from shares in HashiCorp Vault's format,
+// such as Vault unseal keys (after decoding them from base64 or hex).
+func CombineVault(parts [][]byte) ([]byte, error) {
+	// validateCombineParams would reject shares whose first y-values happen to
+	// coincide, since it expects the x-coordinate first; check the shape only.
+	if parts == nil {
+		return nil, ErrNilShares
+	}
+	if len(parts) < 2 {
+		return nil, ErrTooFewParts
+	}
+	if len(parts[0]) < 2 {
+		return nil, ErrTooShort
+	}
+	for i, part := range parts {
+		if part == nil {
+			return nil, NewValidationError("share", i, "shamir: share cannot be nil")
+		}
+		if len(part) != len(parts[0]) {
+			return nil, ErrDifferentLengths
+		}
+	}
+
+	secretLen := len(parts[0]) - ShareOverhead
+	xCoords := make([]byte, len(parts))
+	seen := make(map[byte]bool, len(parts))
+	for i, part := range parts {
+		x := part[secretLen]
+		if x == 0 {
+			return nil, zeroXError(i)
+		}
+		if seen[x] {
+			return nil, NewValidationError("share", i, "shamir: duplicate share identifier detected")
+		}
+		seen[x] = true
+		xCoords[i] = x
+	}
+	meta := CombineMetadata{Shares: len(parts), Indices: xCoords, SecretSize: secretLen}
+	if err := checkPolicy(meta, nil); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, secretLen)
+	yCoords := make([]byte, len(parts))
+	auditTrack("vault.ycoords", yCoords)
+	for byteIdx := range secret {
+		for i, part := range parts {
+			yCoords[i] = part[byteIdx]
+		}
+		secret[byteIdx] = vaultInterpolate(xCoords, yCoords)
+	}
+	secureZeroBytes(yCoords)
+	auditRelease(yCoords)
+
+	return secret, nil
+}
+
+// vaultInterpolate evaluates at x = 0 the polynomial through the given points
+// in the AES field.
+func vaultInterpolate(xCoords, yCoords []byte) byte {
+	var result byte
+	for i, xi := range xCoords {
+		basis := byte(1)
+		for j, xj := range xCoords {
+			if i == j {
+				continue
+			}
+			// L_i(0) = Π x_j / (x_i - x_j)
+			basis = vaultMult(basis, vaultMult(xj, vaultInverse(xi^xj)))
+		}
+		result ^= vaultMult(yCoords[i], basis)
+	}
+	return result
+}
+
+// vaultPolyEval evaluates a polynomial at x with Horner's method in the AES field.
+func vaultPolyEval(coeffs []byte, x byte) byte {
+	result := coeffs[len(coeffs)-1]
+	for i := len(coeffs) - 2; i >= 0; i-- {
+		result = vaultMult(result, x) ^ coeffs[i]
+	}
+	return result
+}
+
+// vaultMult multiplies in GF(256) modulo 0x11b without table lookups or
+// data-dependent branches, as Vault does.
+func vaultMult(a, b byte) byte {
+	var r byte
+	for i := 7; i >= 0; i-- {
+		r = (-(b >> i & 1) & a) ^ (-(r >> 7) & 0x1b) ^ (r + r)
+	}
+	return r
+}
+
+// vaultInverse returns the multiplicative inverse a²⁵⁴ in the AES field (0 for 0).
+func vaultInverse(a byte) byte {
+	result := byte(1)
+	for e := 254; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			result = vaultMult(result, a)
+		}
+		a = vaultMult(a, a)
+	}
+	return result
+}
@@ -0,0 +1,272 @@
+// Package vault packages the encrypt-then-split workflow for files: CreateVault
+// encrypts a file into a vault file and splits the key into share files, one
+// per custodian, and OpenVault recovers the file from the vault and a
+// threshold of share files.
+//
+// For a file report.pdf split into three shares, CreateVault writes
+//
+//	report.pdf.vault      the encrypted file
+//	report.pdf.share-1    key share 1, PEM encoded
+//	report.pdf.share-2
+//	report.pdf.share-3
+//
+// The vault file is a sealed ciphertext of the parent package (see
+// shamir.SealSplit) and the share files are its key shares in the
+// "SHAMIR SHARE" PEM format (see shamir.EncodeSharePEM), so both can also be
+// handled with the shamir command and library directly. The file's name,
+// permissions and modification time are encrypted along with its contents:
+//
+//	[1 byte]   record version (1)
+//	[2 bytes]  name length, big-endian
+//	[n bytes]  base name
+//	[4 bytes]  permission bits, big-endian
+//	[8 bytes]  modification time, Unix seconds, big-endian
+//	[m bytes]  contents
+//
+// The file is processed in memory; for files too large for that, use
+// shamir.SplitStream or encrypt the file separately and vault its key.
+package vault
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// ErrInvalidVault indicates a vault whose decrypted contents are malformed.
+var ErrInvalidVault = errors.New("vault: invalid vault contents")
+
+const (
+	recordVersion = 1
+
+	// VaultExt is the file name extension of vault files.
+	VaultExt = ".vault"
+
+	// ShareExt precedes the share number in the names of share files.
+	ShareExt = ".share-"
+)
+
+// File is a file recovered by OpenVault.
+type File struct {
+	Name    string      // Base name of the original file
+	Mode    fs.FileMode // Permission bits of the original file
+	ModTime time.Time   // Modification time of the original file, to the second
+	Data    []byte
+}
+
+// Created lists the files written by CreateVault.
+type Created struct {
+	Vault  string   // Path of the vault file
+	Shares []string // Paths of the share files, in share order
+}
+
+// Option configures CreateVault.
+type Option func(*options)
+
+type options struct {
+	dir string
+}
+
+// WithDir writes the vault and share files to dir instead of next to the
+// original file.
+func WithDir(dir string) Option {
+	return func(o *options) { o.dir = dir }
+}
+
+// CreateVault encrypts file into a vault file and splits the key into parts
+// share files, threshold of which are needed to open the vault. Existing
+// files are never overwritten: if any output file exists, or writing fails,
+// CreateVault removes what it wrote and returns an error. The original file
+// is left in place; delete it once the shares are distributed.
+func CreateVault(file string, parts, threshold int, opts ...Option) (*Created, error) {
+	o := &options{dir: filepath.Dir(file)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("vault: %s is not a regular file", file)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(data)
+
+	name := filepath.Base(file)
+	record := marshalRecord(&File{Name: name, Mode: info.Mode().Perm(), ModTime: info.ModTime(), Data: data})
+	defer clear(record)
+	ciphertext, keyShares, err := shamir.SealSplit(record, parts, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Join(o.dir, name)
+	created := &Created{Vault: base + VaultExt}
+	var written []string
+	write := func(path string, data []byte) error {
+		if err := writeNew(path, data); err != nil {
+			for _, p := range written {
+				os.Remove(p)
+			}
+			return err
+		}
+		written = append(written, path)
+		return nil
+	}
+
+	if err := write(created.Vault, ciphertext); err != nil {
+		return nil, err
+	}
+	for i, share := range keyShares {
+		encoded, err := shamir.EncodeSharePEM(share)
+		clear(share)
+		if err == nil {
+			path := base + ShareExt + strconv.Itoa(i+1)
+			if err = write(path, encoded); err == nil {
+				created.Shares = append(created.Shares, path)
+			}
+			clear(encoded)
+		}
+		if err != nil {
+			for _, share := range keyShares[i+1:] {
+				clear(share)
+			}
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+// OpenVault decrypts a vault file with the key shares in shareFiles. Share
+// files may hold PEM or binary shares. Shares from another vault fail with
+// shamir.ErrMismatchedShares, and a damaged vault with
+// shamir.ErrInvalidSealed.
+func OpenVault(vault string, shareFiles ...string) (*File, error) {
+	ciphertext, err := os.ReadFile(vault)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([][]byte, 0, len(shareFiles))
+	defer func() {
+		for _, share := range shares {
+			clear(share)
+		}
+	}()
+	for _, path := range shareFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		share, _, err := shamir.DecodeSharePEM(data)
+		if err == nil {
+			clear(data)
+		} else if shamir.IsEnvelope(data) {
+			share = data
+		} else {
+			clear(data)
+			return nil, fmt.Errorf("vault: %s: %w", path, err)
+		}
+		shares = append(shares, share)
+	}
+
+	record, err := shamir.SealCombine(ciphertext, shares)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalRecord(record)
+}
+
+// WriteFile writes the recovered file into dir under its original name,
+// permissions and modification time, and returns its path. It fails if the
+// file already exists.
+func (f *File) WriteFile(dir string) (string, error) {
+	path := filepath.Join(dir, f.Name)
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, f.Mode)
+	if err != nil {
+		return "", err
+	}
+	if _, err := out.Write(f.Data); err != nil {
+		out.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	// The umask may have narrowed the permissions; restore them exactly.
+	if err := os.Chmod(path, f.Mode); err != nil {
+		return "", err
+	}
+	if err := os.Chtimes(path, f.ModTime, f.ModTime); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// writeNew writes data to a new file readable only by its owner.
+func writeNew(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+func marshalRecord(f *File) []byte {
+	out := make([]byte, 0, 1+2+len(f.Name)+4+8+len(f.Data))
+	out = append(out, recordVersion)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(f.Name)))
+	out = append(out, f.Name...)
+	out = binary.BigEndian.AppendUint32(out, uint32(f.Mode.Perm()))
+	out = binary.BigEndian.AppendUint64(out, uint64(f.ModTime.Unix()))
+	return append(out, f.Data...)
+}
+
+func unmarshalRecord(record []byte) (*File, error) {
+	if len(record) < 3 {
+		return nil, ErrInvalidVault
+	}
+	if record[0] != recordVersion {
+		return nil, fmt.Errorf("%w: vault record version %d", shamir.ErrUnsupportedVersion, record[0])
+	}
+	n := int(binary.BigEndian.Uint16(record[1:]))
+	p := 3
+	if len(record) < p+n+12 {
+		return nil, ErrInvalidVault
+	}
+	name := string(record[p : p+n])
+	p += n
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return nil, fmt.Errorf("%w: file name %q", ErrInvalidVault, name)
+	}
+	mode := fs.FileMode(binary.BigEndian.Uint32(record[p:])).Perm()
+	modTime := time.Unix(int64(binary.BigEndian.Uint64(record[p+4:])), 0)
+	return &File{Name: name, Mode: mode, ModTime: modTime, Data: record[p+12:]}, nil
+}
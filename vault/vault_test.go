@@ -0,0 +1,190 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	shamir "github.com/morizta/go-shamir"
+)
+
+// writeOriginal creates the file to be vaulted.
+func writeOriginal(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCreateOpen(t *testing.T) {
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("quarterly figures\n"), 1000)
+	original := writeOriginal(t, dir, "report.txt", data)
+
+	created, err := CreateVault(original, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Vault != original+".vault" || len(created.Shares) != 5 || created.Shares[4] != original+".share-5" {
+		t.Fatalf("unexpected outputs: %+v", created)
+	}
+	for _, path := range append([]string{created.Vault}, created.Shares...) {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("%s: mode %v, want 0600", path, info.Mode().Perm())
+		}
+	}
+	blob, _ := os.ReadFile(created.Vault)
+	if bytes.Contains(blob, []byte("quarterly")) || bytes.Contains(blob, []byte("report.txt")) {
+		t.Fatal("vault file leaks the contents or name")
+	}
+
+	f, err := OpenVault(created.Vault, created.Shares[4], created.Shares[0], created.Shares[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "report.txt" || f.Mode != 0o640 || !bytes.Equal(f.Data, data) || f.ModTime.Unix() != time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).Unix() {
+		t.Fatalf("recovered %q %v %v, %d bytes", f.Name, f.Mode, f.ModTime, len(f.Data))
+	}
+
+	restoreDir := t.TempDir()
+	path, err := f.WriteFile(restoreDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o640 || !info.ModTime().Equal(f.ModTime) {
+		t.Errorf("restored file: mode %v, modified %v", info.Mode().Perm(), info.ModTime())
+	}
+	if restored, _ := os.ReadFile(path); !bytes.Equal(restored, data) {
+		t.Error("restored contents differ")
+	}
+	if _, err := f.WriteFile(restoreDir); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("WriteFile over an existing file: expected fs.ErrExist, got %v", err)
+	}
+
+	if _, err := OpenVault(created.Vault, created.Shares[:2]...); !errors.Is(err, shamir.ErrInsufficientShares) {
+		t.Errorf("two shares: expected ErrInsufficientShares, got %v", err)
+	}
+}
+
+func TestOpenRejectsForeignShares(t *testing.T) {
+	dir := t.TempDir()
+	a, err := CreateVault(writeOriginal(t, dir, "a", []byte("alpha")), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := CreateVault(writeOriginal(t, dir, "b", []byte("bravo")), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenVault(a.Vault, a.Shares[0], b.Shares[1]); !errors.Is(err, shamir.ErrMismatchedShares) {
+		t.Errorf("foreign share: expected ErrMismatchedShares, got %v", err)
+	}
+
+	blob, _ := os.ReadFile(a.Vault)
+	blob[len(blob)-1] ^= 1
+	os.WriteFile(a.Vault, blob, 0o600)
+	if _, err := OpenVault(a.Vault, a.Shares[:2]...); !errors.Is(err, shamir.ErrInvalidSealed) {
+		t.Errorf("tampered vault: expected ErrInvalidSealed, got %v", err)
+	}
+}
+
+func TestOpenAcceptsBinaryShares(t *testing.T) {
+	dir := t.TempDir()
+	created, err := CreateVault(writeOriginal(t, dir, "key.bin", []byte{1, 2, 3}), 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemData, _ := os.ReadFile(created.Shares[1])
+	share, _, err := shamir.DecodeSharePEM(pemData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(dir, "share.bin")
+	os.WriteFile(binary, share, 0o600)
+
+	f, err := OpenVault(created.Vault, created.Shares[0], binary)
+	if err != nil || !bytes.Equal(f.Data, []byte{1, 2, 3}) {
+		t.Fatalf("OpenVault with a binary share: %v, %v", f, err)
+	}
+
+	garbage := filepath.Join(dir, "garbage")
+	os.WriteFile(garbage, []byte("not a share"), 0o600)
+	if _, err := OpenVault(created.Vault, created.Shares[0], garbage); err == nil {
+		t.Error("OpenVault accepted a file that is not a share")
+	}
+}
+
+func TestCreateNeverOverwrites(t *testing.T) {
+	dir := t.TempDir()
+	out := t.TempDir()
+	original := writeOriginal(t, dir, "db.key", []byte("secret"))
+
+	// A stale share file in the output directory aborts the whole vault.
+	stale := filepath.Join(out, "db.key.share-2")
+	os.WriteFile(stale, []byte("keep me"), 0o600)
+	if _, err := CreateVault(original, 3, 2, WithDir(out)); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("expected fs.ErrExist, got %v", err)
+	}
+	entries, _ := os.ReadDir(out)
+	if len(entries) != 1 {
+		t.Errorf("%d files left in the output directory, want only the stale one", len(entries))
+	}
+	if data, _ := os.ReadFile(stale); string(data) != "keep me" {
+		t.Error("existing file was overwritten")
+	}
+
+	os.Remove(stale)
+	created, err := CreateVault(original, 3, 2, WithDir(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(created.Vault) != out {
+		t.Errorf("vault written to %s, want %s", created.Vault, out)
+	}
+
+	if _, err := CreateVault(dir, 3, 2); err == nil {
+		t.Error("CreateVault accepted a directory")
+	}
+	var verr *shamir.ValidationError
+	if _, err := CreateVault(original, 2, 3, WithDir(t.TempDir())); !errors.As(err, &verr) {
+		t.Errorf("invalid threshold: expected a ValidationError, got %v", err)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	f := &File{Name: "x", Mode: 0o600, ModTime: time.Unix(1700000000, 0), Data: []byte("data")}
+	got, err := unmarshalRecord(marshalRecord(f))
+	if err != nil || got.Name != f.Name || got.Mode != f.Mode || !got.ModTime.Equal(f.ModTime) || string(got.Data) != "data" {
+		t.Fatalf("round trip: %+v, %v", got, err)
+	}
+	for _, name := range []string{"", "../x", "a/b", ".."} {
+		f.Name = name
+		if _, err := unmarshalRecord(marshalRecord(f)); !errors.Is(err, ErrInvalidVault) {
+			t.Errorf("name %q: expected ErrInvalidVault, got %v", name, err)
+		}
+	}
+	if _, err := unmarshalRecord([]byte{2, 0, 0}); !errors.Is(err, shamir.ErrUnsupportedVersion) {
+		t.Errorf("version: expected ErrUnsupportedVersion, got %v", err)
+	}
+}
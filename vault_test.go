@@ -0,0 +1,129 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestVaultField(t *testing.T) {
+	// Multiplication examples from FIPS-197, section 4.2.
+	tests := []struct{ a, b, want byte }{
+		{0x57, 0x83, 0xc1},
+		{0x57, 0x13, 0xfe},
+		{0x57, 0x02, 0xae},
+		{0x01, 0xff, 0xff},
+		{0x00, 0x42, 0x00},
+	}
+	for _, tt := range tests {
+		if got := vaultMult(tt.a, tt.b); got != tt.want {
+			t.Errorf("vaultMult(%#x, %#x) = %#x, expected %#x", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	for a := 1; a < 256; a++ {
+		if vaultMult(byte(a), vaultInverse(byte(a))) != 1 {
+			t.Fatalf("%#x has no inverse", a)
+		}
+	}
+}
+
+func TestSplitCombineVault(t *testing.T) {
+	secret := []byte("vault unseal key material")
+
+	shares, err := SplitVault(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xs := make(map[byte]bool)
+	for _, share := range shares {
+		if len(share) != len(secret)+ShareOverhead {
+			t.Fatalf("unexpected share length %d", len(share))
+		}
+		x := share[len(share)-1]
+		if x == 0 || xs[x] {
+			t.Fatalf("invalid or duplicate trailing x-coordinate %d", x)
+		}
+		xs[x] = true
+	}
+
+	reconstructed, err := CombineVault(shares[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reconstructed, secret) {
+		t.Fatal("reconstruction failed")
+	}
+
+	t.Run("hand-built shares", func(t *testing.T) {
+		// f(x) = s + c·x in the AES field, with x appended as Vault does.
+		s, c := byte(0x42), byte(0x99)
+		share := func(x byte) []byte { return []byte{s ^ vaultMult(c, x), x} }
+		got, err := CombineVault([][]byte{share(0x57), share(0x13)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[0] != s {
+			t.Fatalf("expected %#x, got %#x", s, got[0])
+		}
+	})
+
+	t.Run("duplicate x-coordinates", func(t *testing.T) {
+		dup := append([]byte(nil), shares[1]...)
+		dup[0] ^= 1
+		if _, err := CombineVault([][]byte{shares[1], dup}); err == nil {
+			t.Fatal("expected error for duplicate x-coordinates")
+		}
+	})
+
+	t.Run("zero x-coordinate", func(t *testing.T) {
+		zero := append([]byte(nil), shares[1]...)
+		zero[len(zero)-1] = 0
+		var ve *ValidationError
+		if _, err := CombineVault([][]byte{shares[0], zero}); !errors.As(err, &ve) || ve.Field != "x-coordinate" {
+			t.Fatalf("expected x-coordinate ValidationError, got %v", err)
+		}
+	})
+
+	t.Run("not interchangeable with Split", func(t *testing.T) {
+		got, err := Combine(shares[:3])
+		if err == nil && bytes.Equal(got, secret) {
+			t.Fatal("Vault shares unexpectedly combined with Combine")
+		}
+	})
+}
+
+func TestCombineVaultUpstreamVector(t *testing.T) {
+	// A 3-of-5 split of "vault unseal key" by shamir.Split from
+	// github.com/hashicorp/vault v1.20.1.
+	vector := []string{
+		"eb53782f5a71e94e1eda43c39e47f4e598",
+		"b2152dacd73c4445f24dd87c33f722fed2",
+		"4b17907ab1da680ee61e4096bb2ea6967d",
+		"306f3089124850faf467ca8e2da787c2cc",
+		"8ffac10bd973c6ab9ee84471eb5e702d8e",
+	}
+	shares := make([][]byte, len(vector))
+	for i, h := range vector {
+		var err error
+		if shares[i], err = hex.DecodeString(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, quorum := range [][]int{{0, 1, 2}, {2, 3, 4}, {4, 0, 3}, {0, 1, 2, 3, 4}} {
+		parts := make([][]byte, len(quorum))
+		for i, j := range quorum {
+			parts[i] = shares[j]
+		}
+		got, err := CombineVault(parts)
+		if err != nil {
+			t.Fatalf("quorum %v: %v", quorum, err)
+		}
+		if string(got) != "vault unseal key" {
+			t.Fatalf("quorum %v: got %q", quorum, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package shamir
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"testing"
+)
+
+// resealEnvelope recomputes the CRC32 trailer of a modified envelope.
+func resealEnvelope(share []byte) {
+	body := share[:len(share)-envelopeChecksumSize]
+	binary.BigEndian.PutUint32(share[len(body):], crc32.ChecksumIEEE(body))
+}
+
+func TestWireFormatKinds(t *testing.T) {
+	for kind, name := range wireKinds {
+		share := []byte{0x00, 'S', kind, 1, 2, 3}
+		if got := shareFormat(share); got != name {
+			t.Errorf("kind %q: shareFormat = %q, want %q", kind, got, name)
+		}
+	}
+
+	// A kind from a later release is refused, not read as x = 0.
+	future := [][]byte{{0x00, 'S', 'Z', 1, 5, 6, 7}, {0x00, 'S', 'Z', 1, 8, 9, 10}}
+	var merr *MigrationError
+	if _, err := Combine(future); !errors.As(err, &merr) || merr.Format != newerFormat {
+		t.Fatalf("Combine of unknown kind: got %v, want MigrationError", err)
+	}
+	if info, err := InspectShare(future[0]); err != nil || info.Format != newerFormat {
+		t.Errorf("InspectShare of unknown kind: %+v, %v", info, err)
+	}
+
+	// Legacy raw shares are still detected and combined.
+	raw, err := Split([]byte("legacy"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shareFormat(raw[0]) != "" {
+		t.Errorf("raw share detected as %q", shareFormat(raw[0]))
+	}
+	if got, err := Combine(raw[1:]); err != nil || string(got) != "legacy" {
+		t.Errorf("Combine of raw shares = %q, %v", got, err)
+	}
+}
+
+func TestEnvelopeFlags(t *testing.T) {
+	shares, err := SplitWithOptions([]byte("flagged"), WithParts(3), WithThreshold(2), WithLabels("a", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range shares {
+		shares[i][4] = 0x20 // A non-critical flag from a later release
+		resealEnvelope(shares[i])
+	}
+
+	got, err := CombineWithOptions(shares[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "flagged" {
+		t.Fatalf("CombineWithOptions = %q", got)
+	}
+
+	// Re-encoding, including through JSON, keeps the flag.
+	relabelled, err := LabelShare(shares[0], "z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if relabelled[4] != 0x20 {
+		t.Errorf("LabelShare dropped flags: %#x", relabelled[4])
+	}
+	s, err := ParseShare(shares[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Share
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.flags != 0x20 {
+		t.Errorf("JSON round trip flags = %#x", decoded.flags)
+	}
+
+	// A critical flag means the payload cannot be read by this release.
+	shares[2][4] = 0x01
+	resealEnvelope(shares[2])
+	if _, err := ParseShare(shares[2]); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("critical flag: got %v, want ErrUnsupportedVersion", err)
+	}
+}